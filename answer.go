@@ -427,6 +427,36 @@ func (f *Future) List() (List, error) {
 	return p.List(), err
 }
 
+// CopyResults waits until the answer is resolved and copies the
+// pointer this future represents into a new message, as its root.
+// The message is allocated in dst, which must be empty; if dst is nil,
+// a new single-segment arena is used.
+//
+// Unlike the values returned by Ptr and Struct, which refer to the
+// answer's message and must not be used after the answer is released,
+// the returned message is owned by the caller and stays valid until it
+// is released.  Capabilities in the results are added to the new
+// message's capability table, so the caller must call Release on the
+// message once it is done with it.
+func (f *Future) CopyResults(dst Arena) (*Message, error) {
+	p, err := f.Ptr()
+	if err != nil {
+		return nil, err
+	}
+	if dst == nil {
+		dst = SingleSegment(nil)
+	}
+	msg, _, err := NewMessage(dst)
+	if err != nil {
+		return nil, exc.WrapError("copy results", err)
+	}
+	if err := msg.SetRoot(p); err != nil {
+		msg.Release()
+		return nil, exc.WrapError("copy results", err)
+	}
+	return msg, nil
+}
+
 // Client returns the future as a client.  If the answer's originating
 // call has not completed, then calls will be queued until the original
 // call's completion.  The client reference is borrowed: the caller
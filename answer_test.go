@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 var dummyMethod = Method{
@@ -137,3 +138,134 @@ func (dummyPipelineCaller) PipelineRecv(ctx context.Context, transform []Pipelin
 func (dummyPipelineCaller) PipelineSend(ctx context.Context, transform []PipelineOp, s Send) (*Answer, ReleaseFunc) {
 	return ErrorAnswer(s.Method, errors.New("dummy call")), func() {}
 }
+
+func TestFutureCopyResults(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Struct", func(t *testing.T) {
+		h := new(dummyHook)
+		c := NewClient(h)
+		p := NewPromise(dummyMethod, dummyPipelineCaller{}, nil)
+		msg, seg := NewSingleSegmentMessage(nil)
+		res, _ := NewStruct(seg, ObjectSize{DataSize: 8, PointerCount: 2})
+		res.SetUint32(0, 0xdeadbeef)
+		if err := res.SetNewText(0, "hello"); err != nil {
+			t.Fatal(err)
+		}
+		res.SetPtr(1, NewInterface(seg, msg.CapTable().Add(c.AddRef())).ToPtr())
+		p.Fulfill(res.ToPtr())
+
+		cp, err := p.Answer().Future().CopyResults(nil)
+		if err != nil {
+			t.Fatal("CopyResults:", err)
+		}
+		defer cp.Release()
+
+		// Release the answer and its message.
+		p.ReleaseClients()
+		msg.Release()
+
+		root, err := cp.Root()
+		if err != nil {
+			t.Fatal("cp.Root():", err)
+		}
+		s := root.Struct()
+		if got := s.Uint32(0); got != 0xdeadbeef {
+			t.Errorf("copied Uint32(0) = %#x; want 0xdeadbeef", got)
+		}
+		text, err := s.Ptr(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := text.Text(); got != "hello" {
+			t.Errorf("copied text = %q; want \"hello\"", got)
+		}
+		iface, err := s.Ptr(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := iface.Interface().Client(); !got.IsSame(c) {
+			t.Errorf("copied client = %v; want %v", got, c)
+		}
+
+		c.Release()
+		if h.shutdowns != 0 {
+			t.Error("client shut down while copied results hold a reference")
+		}
+		cp.Release()
+		if h.shutdowns != 1 {
+			t.Errorf("client shut down %d times after releasing copy; want 1", h.shutdowns)
+		}
+	})
+	t.Run("Field", func(t *testing.T) {
+		p := NewPromise(dummyMethod, dummyPipelineCaller{}, nil)
+		defer p.ReleaseClients()
+		msg, seg := NewSingleSegmentMessage(nil)
+		defer msg.Release()
+		res, _ := NewStruct(seg, ObjectSize{PointerCount: 1})
+		inner, _ := NewStruct(seg, ObjectSize{DataSize: 8})
+		inner.SetUint64(0, 42)
+		res.SetPtr(0, inner.ToPtr())
+		p.Fulfill(res.ToPtr())
+
+		cp, err := p.Answer().Field(0, nil).CopyResults(SingleSegment(nil))
+		if err != nil {
+			t.Fatal("CopyResults:", err)
+		}
+		defer cp.Release()
+		root, err := cp.Root()
+		if err != nil {
+			t.Fatal("cp.Root():", err)
+		}
+		if got := root.Struct().Uint64(0); got != 42 {
+			t.Errorf("copied field Uint64(0) = %d; want 42", got)
+		}
+	})
+	t.Run("Error", func(t *testing.T) {
+		p := NewPromise(dummyMethod, dummyPipelineCaller{}, nil)
+		defer p.ReleaseClients()
+		p.Reject(errors.New("omg bbq"))
+		if _, err := p.Answer().Future().CopyResults(nil); err == nil || !strings.Contains(err.Error(), "omg bbq") {
+			t.Errorf("CopyResults error = %v; want message containing \"omg bbq\"", err)
+		}
+	})
+}
+
+func TestAnswerQueueStats(t *testing.T) {
+	t.Run("Queue", func(t *testing.T) {
+		aq := NewAnswerQueue(dummyMethod)
+		if s := aq.Stats(); s != (AnswerQueueStats{}) {
+			t.Errorf("empty queue: Stats() = %+v; want zero", s)
+		}
+		ctx := context.Background()
+		_, release1 := aq.PipelineSend(ctx, nil, Send{Method: dummyMethod})
+		defer release1()
+		_, release2 := aq.PipelineSend(ctx, nil, Send{Method: dummyMethod})
+		defer release2()
+		time.Sleep(time.Millisecond)
+
+		s := aq.Stats()
+		if s.Queues != 1 || s.Depth != 2 || s.Oldest <= 0 {
+			t.Errorf("Stats() = %+v; want 1 queue with 2 calls", s)
+		}
+		if s := QueuedCalls(); s.Depth < 2 {
+			t.Errorf("QueuedCalls() = %+v; want at least 2 calls", s)
+		}
+		aq.Reject(errors.New("rejected"))
+		if s := aq.Stats(); s != (AnswerQueueStats{}) {
+			t.Errorf("after Reject: Stats() = %+v; want zero", s)
+		}
+	})
+	t.Run("LocalPromise", func(t *testing.T) {
+		p, r := NewLocalPromise[Client]()
+		defer p.Release()
+		before := QueuedCalls()
+		ans, release := p.SendCall(context.Background(), Send{Method: dummyMethod})
+		defer release()
+		if s := QueuedCalls(); s.Depth <= before.Depth {
+			t.Errorf("after call on local promise: QueuedCalls() = %+v; want more than %d calls", s, before.Depth)
+		}
+		r.Reject(errors.New("rejected"))
+		<-ans.Done()
+	})
+}
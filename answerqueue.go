@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"capnproto.org/go/capnp/v3/exc"
 )
@@ -31,9 +32,10 @@ type AnswerQueue struct {
 
 // qent is a single entry in an AnswerQueue.
 type qent struct {
-	ctx   context.Context
-	basis int // index in bases
-	path  []PipelineOp
+	ctx      context.Context
+	basis    int // index in bases
+	path     []PipelineOp
+	enqueued time.Time
 	Recv
 }
 
@@ -63,6 +65,9 @@ func (aq *AnswerQueue) Fulfill(ptr Ptr) {
 	aq.mu.Lock()
 	q := aq.q
 	aq.q = nil
+	if len(q) > 0 {
+		queueing.remove(aq)
+	}
 	aq.bases = make([]base, len(q)+1)
 	ready := make(chan struct{}) // TODO(soon): use more fine-grained signals
 	defer close(ready)
@@ -91,6 +96,9 @@ func (aq *AnswerQueue) Reject(e error) {
 	aq.mu.Lock()
 	q := aq.q
 	aq.q = nil
+	if len(q) > 0 {
+		queueing.remove(aq)
+	}
 	aq.bases = make([]base, len(q)+1)
 	ready := make(chan struct{})
 	close(ready)
@@ -111,6 +119,91 @@ func (aq *AnswerQueue) Reject(e error) {
 	}
 }
 
+// AnswerQueueStats describes the calls waiting in one or more
+// AnswerQueues.
+type AnswerQueueStats struct {
+	// Queues is the number of queues that have calls waiting.
+	Queues int
+
+	// Depth is the number of calls waiting.
+	Depth int
+
+	// Oldest is how long the call that has waited longest has been
+	// waiting, or zero if no calls are waiting.
+	Oldest time.Duration
+}
+
+// add adds the stats of another set of queues to s.
+func (s *AnswerQueueStats) add(t AnswerQueueStats) {
+	s.Queues += t.Queues
+	s.Depth += t.Depth
+	if t.Oldest > s.Oldest {
+		s.Oldest = t.Oldest
+	}
+}
+
+// Stats reports the calls waiting for aq to be fulfilled or rejected.
+// Once aq starts draining, no calls are reported as waiting.
+func (aq *AnswerQueue) Stats() AnswerQueueStats {
+	aq.mu.Lock()
+	defer aq.mu.Unlock()
+	if len(aq.q) == 0 {
+		return AnswerQueueStats{}
+	}
+	return AnswerQueueStats{
+		Queues: 1,
+		Depth:  len(aq.q),
+		Oldest: time.Since(aq.q[0].enqueued),
+	}
+}
+
+// QueuedCalls reports the calls waiting in every AnswerQueue in the
+// process, such as calls pipelined on local promises and on calls to
+// local servers that have not returned yet.  A large or old backlog
+// usually means an unresolved promise is holding up pipelined traffic.
+func QueuedCalls() AnswerQueueStats {
+	var s AnswerQueueStats
+	for _, aq := range queueing.list() {
+		s.add(aq.Stats())
+	}
+	return s
+}
+
+// queueing is the set of AnswerQueues that have calls waiting.  A
+// queue's lock may be held while queueing's lock is acquired, but not
+// the other way around.
+var queueing answerQueueSet
+
+type answerQueueSet struct {
+	mu sync.Mutex
+	m  map[*AnswerQueue]struct{}
+}
+
+func (s *answerQueueSet) add(aq *AnswerQueue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[*AnswerQueue]struct{})
+	}
+	s.m[aq] = struct{}{}
+}
+
+func (s *answerQueueSet) remove(aq *AnswerQueue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, aq)
+}
+
+func (s *answerQueueSet) list() []*AnswerQueue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := make([]*AnswerQueue, 0, len(s.m))
+	for aq := range s.m {
+		l = append(l, aq)
+	}
+	return l
+}
+
 func (aq *AnswerQueue) PipelineRecv(ctx context.Context, transform []PipelineOp, r Recv) PipelineCaller {
 	return queueCaller{aq, 0}.PipelineRecv(ctx, transform, r)
 }
@@ -140,11 +233,15 @@ func (qc queueCaller) PipelineRecv(ctx context.Context, transform []PipelineOp,
 		return b.recv(ctx, transform, r)
 	}
 	// Enqueue.
+	if len(qc.aq.q) == 0 {
+		queueing.add(qc.aq)
+	}
 	qc.aq.q = append(qc.aq.q, qent{
-		ctx:   ctx,
-		basis: qc.basis,
-		path:  transform,
-		Recv:  r,
+		ctx:      ctx,
+		basis:    qc.basis,
+		path:     transform,
+		enqueued: time.Now(),
+		Recv:     r,
 	})
 	basis := len(qc.aq.q) - 1
 	qc.aq.mu.Unlock()
@@ -179,7 +276,13 @@ func (qc queueCaller) PipelineSend(ctx context.Context, transform []PipelineOp,
 // message.  It is safe to use from multiple goroutines.  The zero value
 // is a Returner in its initial state.
 type StructReturner struct {
-	mu       sync.Mutex // guards all fields
+	// NewArena, if not nil, is called to create the arena of the
+	// results message, which is released along with the results.  If
+	// nil, a MultiSegment arena is used.  It must be set before the
+	// StructReturner is used.
+	NewArena func() Arena
+
+	mu       sync.Mutex // guards all fields below
 	p        *Promise   // assigned at most once
 	alloced  bool
 	released bool
@@ -198,7 +301,14 @@ func (sr *StructReturner) AllocResults(sz ObjectSize) (Struct, error) {
 		return Struct{}, errors.New("StructReturner: multiple calls to AllocResults")
 	}
 	sr.alloced = true
-	_, seg := NewMultiSegmentMessage(nil)
+	arena := Arena(MultiSegment(nil))
+	if sr.NewArena != nil {
+		arena = sr.NewArena()
+	}
+	_, seg, err := NewMessage(arena)
+	if err != nil {
+		return Struct{}, exc.WrapError("alloc results", err)
+	}
 	s, err := NewRootStruct(seg, sz)
 	if err != nil {
 		return Struct{}, exc.WrapError("alloc results", err)
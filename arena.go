@@ -65,6 +65,10 @@ type SingleSegmentArena struct {
 
 	// fromPool determines if this should return to the pool when released.
 	fromPool bool
+
+	// pool is the ArenaPool the arena came from, if any.  The arena
+	// returns to it when released.
+	pool *ArenaPool
 }
 
 func zeroSlice(b []byte) {
@@ -151,6 +155,10 @@ func (ssa *SingleSegmentArena) Release() {
 		ssa.fromPool = false // Prevent double return
 		singleSegmentPool.Put(ssa)
 	}
+	if p := ssa.pool; p != nil {
+		ssa.pool = nil // Prevent double return
+		p.put(ssa)
+	}
 }
 
 // MultiSegment is an arena that stores object data across multiple []byte
@@ -0,0 +1,73 @@
+package capnp
+
+import (
+	"sync"
+
+	"capnproto.org/go/capnp/v3/exp/bufferpool"
+)
+
+// An ArenaPool creates single-segment arenas whose buffers are recycled
+// across messages: releasing a message built on one of its arenas,
+// with Message.Release or Message.Reset, returns the arena and its
+// segment to the pool, to be handed out by a later call to Arena.
+// Unlike SingleSegment(nil), which shares buffers with the rest of the
+// process, the pool keeps its own buffers and reserves segmentSize
+// bytes up front, so a server whose messages are of a similar size
+// never grows a segment in the common case.  For example, a busy
+// server can draw the arenas returned by server.Options.NewResultsArena
+// from a pool.
+//
+// An ArenaPool is safe to use from multiple goroutines.
+type ArenaPool struct {
+	segmentSize int
+	bufs        bufferpool.Pool
+	arenas      sync.Pool
+}
+
+// NewArenaPool returns a pool of arenas whose segments start out with
+// a capacity of segmentSize bytes.  segmentSize is rounded up to a
+// whole number of words.  If it is not positive, segments start out
+// empty, and are allocated when the message needs them.
+func NewArenaPool(segmentSize int) *ArenaPool {
+	if segmentSize < 0 {
+		segmentSize = 0
+	}
+	segmentSize = int(Size(segmentSize).padToWord())
+	p := &ArenaPool{segmentSize: segmentSize}
+	if segmentSize > 0 {
+		// The largest bucket must hold a segment; 20 is the
+		// bufferpool default.
+		p.bufs.MinAlloc = segmentSize
+		p.bufs.BucketCount = 20
+		for 1<<p.bufs.BucketCount < segmentSize {
+			p.bufs.BucketCount++
+		}
+	}
+	p.arenas.New = func() any {
+		return &SingleSegmentArena{bp: &p.bufs, pool: p}
+	}
+	return p
+}
+
+// Arena returns an empty arena from the pool.  The arena goes back to
+// the pool when it is released.
+func (p *ArenaPool) Arena() Arena {
+	ssa := p.arenas.Get().(*SingleSegmentArena)
+	ssa.bp = &p.bufs
+	ssa.pool = p
+	if p.segmentSize > 0 {
+		ssa.seg.data = p.bufs.Get(p.segmentSize)[:0]
+	}
+	return ssa
+}
+
+// NewMessage returns a new message whose arena is drawn from the pool,
+// along with its first segment.
+func (p *ArenaPool) NewMessage() (*Message, *Segment, error) {
+	return NewMessage(p.Arena())
+}
+
+// put returns ssa, which has been released, to the pool.
+func (p *ArenaPool) put(ssa *SingleSegmentArena) {
+	p.arenas.Put(ssa)
+}
@@ -0,0 +1,50 @@
+package capnp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaPool(t *testing.T) {
+	t.Parallel()
+
+	p := NewArenaPool(100)
+	for i := 0; i < 10; i++ {
+		msg, seg, err := p.NewMessage()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, cap(seg.Data()), 104, "segment should be reserved up front")
+
+		s, err := NewRootStruct(seg, ObjectSize{DataSize: 16})
+		require.NoError(t, err)
+		assert.Zero(t, s.Uint64(0), "recycled segment should be zeroed")
+		s.SetUint64(0, uint64(i)+1)
+
+		// Growing past the reserved capacity still works.
+		if i%2 == 0 {
+			_, err = NewData(seg, make([]byte, 1000))
+			require.NoError(t, err)
+		}
+
+		data, err := msg.Marshal()
+		require.NoError(t, err)
+		msg.Release()
+
+		msg2, err := Unmarshal(data)
+		require.NoError(t, err)
+		root, err := msg2.Root()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i)+1, root.Struct().Uint64(0))
+	}
+}
+
+func TestArenaPoolLargeSegments(t *testing.T) {
+	t.Parallel()
+
+	p := NewArenaPool(4 << 20)
+	msg, seg, err := p.NewMessage()
+	require.NoError(t, err)
+	defer msg.Release()
+	assert.GreaterOrEqual(t, cap(seg.Data()), 4<<20)
+}
@@ -0,0 +1,157 @@
+// Package authz attaches authorization policies to capabilities.
+//
+// Rather than checking who may call what inside each method, or in
+// interceptors spread across servers, a vat exports a capability
+// wrapped with Export and a Policy that states which peers may call it,
+// which methods they may call, and until when.  The policy is enforced
+// on every call delivered to the wrapped capability, including calls
+// that the Conn delivers from the remote vat, and can be inspected with
+// PolicyOf, so a security review can find all the rules in one place.
+package authz // import "capnproto.org/go/capnp/v3/authz"
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/rpc"
+)
+
+var (
+	// ErrPeerDenied is the cause of the error returned for calls from a
+	// peer that a Policy does not allow.
+	ErrPeerDenied = errors.New("peer not allowed")
+
+	// ErrMethodDenied is the cause of the error returned for calls to a
+	// method that a Policy does not allow.
+	ErrMethodDenied = errors.New("method not allowed")
+
+	// ErrExpired is the cause of the error returned for calls made
+	// after a Policy has expired.
+	ErrExpired = errors.New("capability expired")
+)
+
+// A Policy states who may call a capability, what and until when.  The
+// zero value allows every call.
+type Policy struct {
+	// Name identifies the policy in the errors returned for denied
+	// calls.
+	Name string
+
+	// Peers, if not nil, reports whether calls from peer are allowed.
+	// peer is the RemotePeerID of the Conn that delivered the call, or
+	// the zero PeerID if the call was made locally.  It is called for
+	// every call, and must be safe to call from multiple goroutines.
+	Peers func(peer rpc.PeerID) bool
+
+	// Methods, if not empty, lists the methods that may be called.
+	// Only InterfaceID and MethodID are compared.
+	Methods []capnp.Method
+
+	// Expires, if not zero, is the time after which all calls are
+	// denied.
+	Expires time.Time
+
+	// Clock is used to check Expires.  If nil, clock.System is used.
+	Clock clock.Clock
+}
+
+// Check returns an error if p does not allow a call to m, made with
+// ctx.  The error is a failed exception wrapping ErrPeerDenied,
+// ErrMethodDenied or ErrExpired.
+func (p *Policy) Check(ctx context.Context, m capnp.Method) error {
+	if !p.Expires.IsZero() {
+		clk := p.Clock
+		if clk == nil {
+			clk = clock.System
+		}
+		if !clk.Now().Before(p.Expires) {
+			return p.deny(ErrExpired)
+		}
+	}
+	if len(p.Methods) > 0 && !p.allowsMethod(m) {
+		return p.deny(ErrMethodDenied)
+	}
+	if p.Peers != nil {
+		peer, _ := rpc.PeerIDFromContext(ctx)
+		if !p.Peers(peer) {
+			return p.deny(ErrPeerDenied)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) allowsMethod(m capnp.Method) bool {
+	for _, allowed := range p.Methods {
+		if allowed.InterfaceID == m.InterfaceID && allowed.MethodID == m.MethodID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) deny(err error) error {
+	prefix := "authz"
+	if p.Name != "" {
+		prefix += " " + p.Name
+	}
+	return exc.WrapError(prefix, err)
+}
+
+// Export returns a client that forwards calls to target if policy
+// allows them, and fails them otherwise.  Export takes ownership of
+// target: it is released when the returned client is shut down.  The
+// policy is copied, so later changes to it have no effect.
+func Export(target capnp.Client, policy Policy) capnp.Client {
+	policy.Methods = append([]capnp.Method(nil), policy.Methods...)
+	return capnp.NewClient(&guard{target: target, policy: policy})
+}
+
+// PolicyOf returns the policy c was exported with, if c was returned
+// by Export.  Policies of capabilities imported from other vats are not
+// visible.
+func PolicyOf(c capnp.Client) (Policy, bool) {
+	s := c.Snapshot()
+	defer s.Release()
+	g, ok := s.Brand().Value.(*guard)
+	if !ok {
+		return Policy{}, false
+	}
+	return g.policy, true
+}
+
+// A guard is the capnp.ClientHook behind a client returned by Export.
+type guard struct {
+	target capnp.Client
+	policy Policy
+}
+
+func (g *guard) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	if err := g.policy.Check(ctx, s.Method); err != nil {
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+	return g.target.SendCall(ctx, s)
+}
+
+func (g *guard) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	if err := g.policy.Check(ctx, r.Method); err != nil {
+		r.Reject(err)
+		return nil
+	}
+	return g.target.RecvCall(ctx, r)
+}
+
+func (g *guard) Brand() capnp.Brand {
+	return capnp.Brand{Value: g}
+}
+
+func (g *guard) Shutdown() {
+	g.target.Release()
+}
+
+func (g *guard) String() string {
+	return "authz(" + g.target.String() + ")"
+}
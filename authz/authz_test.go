@@ -0,0 +1,121 @@
+package authz_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/authz"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+)
+
+type echoImpl struct{}
+
+func (echoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + in)
+}
+
+func echo(ctx context.Context, e air.Echo, in string) (string, error) {
+	ans, release := e.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn(in)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+	return res.Out()
+}
+
+// dial exports c over a pair of Conns whose server side sees the
+// remote peer as having AuthInfo auth, and returns the imported client.
+func dial(t *testing.T, c capnp.Client, auth rpc.AuthInfo) air.Echo {
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: c,
+		RemotePeerID:    rpc.PeerID{Auth: auth},
+	}, nil)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return air.Echo(client.Bootstrap(context.Background()))
+}
+
+func TestPolicyPeers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	policy := authz.Policy{
+		Name: "echo",
+		Peers: func(peer rpc.PeerID) bool {
+			return peer.Auth == "alice"
+		},
+	}
+
+	alice := dial(t, authz.Export(capnp.Client(air.Echo_ServerToClient(echoImpl{})), policy), "alice")
+	defer alice.Release()
+	out, err := echo(ctx, alice, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foofoo", out)
+
+	bob := dial(t, authz.Export(capnp.Client(air.Echo_ServerToClient(echoImpl{})), policy), "bob")
+	defer bob.Release()
+	_, err = echo(ctx, bob, "foo")
+	assert.ErrorContains(t, err, "authz echo: "+authz.ErrPeerDenied.Error())
+}
+
+func TestPolicyMethods(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	e := air.Echo(authz.Export(capnp.Client(air.Echo_ServerToClient(echoImpl{})), authz.Policy{
+		Methods: []capnp.Method{{InterfaceID: air.Echo_TypeID, MethodID: 1}},
+	}))
+	defer e.Release()
+
+	_, err := echo(ctx, e, "foo")
+	assert.ErrorIs(t, err, authz.ErrMethodDenied)
+}
+
+func TestPolicyExpires(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clk := clock.NewManual(time.Unix(1000, 0))
+	policy := authz.Policy{
+		Expires: clk.Now().Add(time.Minute),
+		Clock:   clk,
+	}
+	e := air.Echo(authz.Export(capnp.Client(air.Echo_ServerToClient(echoImpl{})), policy))
+	defer e.Release()
+
+	_, err := echo(ctx, e, "foo")
+	require.NoError(t, err)
+
+	clk.Advance(time.Minute)
+	_, err = echo(ctx, e, "foo")
+	assert.ErrorIs(t, err, authz.ErrExpired)
+
+	got, ok := authz.PolicyOf(capnp.Client(e))
+	require.True(t, ok)
+	assert.Equal(t, policy.Expires, got.Expires)
+
+	plain := air.Echo_ServerToClient(echoImpl{})
+	defer plain.Release()
+	_, ok = authz.PolicyOf(capnp.Client(plain))
+	assert.False(t, ok)
+}
@@ -1,6 +1,12 @@
 package capnp
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
 	"capnproto.org/go/capnp/v3/exc"
 	"capnproto.org/go/capnp/v3/internal/str"
 )
@@ -8,7 +14,8 @@ import (
 // Canonicalize encodes a struct into its canonical form: a single-
 // segment blob without a segment table.  The result will be identical
 // for equivalent structs, even as the schema evolves.  The blob is
-// suitable for hashing or signing.
+// suitable for hashing or signing.  CanonicalizeInto writes the same
+// blob to an io.Writer without building it in memory.
 func Canonicalize(s Struct) ([]byte, error) {
 	msg, seg := NewSingleSegmentMessage(nil)
 	if !s.IsValid() {
@@ -105,7 +112,7 @@ func canonicalList(dst *Segment, l List) (List, error) {
 	if !l.IsValid() {
 		return List{}, nil
 	}
-	if l.size.PointerCount == 0 {
+	if l.size.PointerCount == 0 && l.flags&isCompositeList == 0 {
 		// Data only, just copy over.
 		sz := l.allocSize()
 		_, newAddr, err := alloc(dst, sz)
@@ -146,16 +153,7 @@ func canonicalList(dst *Segment, l List) (List, error) {
 	}
 
 	// Struct/composite list
-	var elemSize ObjectSize
-	for i := 0; i < l.Len(); i++ {
-		sz := canonicalStructSize(l.Struct(i))
-		if sz.DataSize > elemSize.DataSize {
-			elemSize.DataSize = sz.DataSize
-		}
-		if sz.PointerCount > elemSize.PointerCount {
-			elemSize.PointerCount = sz.PointerCount
-		}
-	}
+	elemSize := canonicalElemSize(l)
 	cl, err := NewCompositeList(dst, elemSize, l.length)
 	if err != nil {
 		return List{}, exc.WrapError("list", err)
@@ -167,3 +165,344 @@ func canonicalList(dst *Segment, l List) (List, error) {
 	}
 	return cl, nil
 }
+
+// CanonicalizeInto writes the canonical form of s, as returned by
+// Canonicalize, to w.  Rather than building a copy of s in memory, it
+// reads s twice: once to measure the objects that s points to, so that
+// it can compute the pointers to them, and once to write them.  This
+// makes it suitable for hashing or signing large structs by passing a
+// hash.Hash as w.
+//
+// CanonicalizeInto keeps the size of each object pointed to by s while
+// writing, which takes far less memory than the objects themselves.
+// Only the first pass counts against the message's read limit.
+func CanonicalizeInto(w io.Writer, s Struct) error {
+	return canonicalizeInto(w, s.ToPtr())
+}
+
+func canonicalizeInto(w io.Writer, root Ptr) error {
+	c := &canonicalWriter{w: bufio.NewWriter(w)}
+	size, err := c.measure(root)
+	if err != nil {
+		return exc.WrapError("canonicalize", err)
+	}
+	if size > maxSegmentSize-wordSize {
+		return errors.New("canonicalize: canonical form too large")
+	}
+	c.writeWord(canonicalPointer(root, 0))
+	if err := c.writeObject(root); err != nil {
+		return exc.WrapError("canonicalize", err)
+	}
+	if err := c.w.Flush(); err != nil {
+		return exc.WrapError("canonicalize", err)
+	}
+	return nil
+}
+
+// IsCanonical reports whether msg is in canonical form: a single
+// segment that starts with the root pointer, followed by the objects
+// that it points to in preorder, with no gaps and with structs truncated
+// to their last non-zero data word and last non-null pointer.  A message
+// is canonical if and only if its segment holds what Canonicalize would
+// return for its root.  Receivers of signed messages can use IsCanonical
+// to reject other encodings of the same content before checking the
+// signature.
+//
+// IsCanonical returns an error if the root cannot be read.
+func IsCanonical(msg *Message) (bool, error) {
+	if msg.NumSegments() != 1 {
+		return false, nil
+	}
+	seg, err := msg.Segment(0)
+	if err != nil {
+		return false, exc.WrapError("is canonical", err)
+	}
+	if len(seg.Data()) == 0 || len(seg.Data())%int(wordSize) != 0 {
+		return false, nil
+	}
+	root, err := msg.Root()
+	if err != nil {
+		return false, exc.WrapError("is canonical", err)
+	}
+	cmp := &canonicalComparer{data: seg.Data()}
+	if err := canonicalizeInto(cmp, root); err != nil && !cmp.differs {
+		return false, exc.WrapError("is canonical", err)
+	}
+	return !cmp.differs && len(cmp.data) == 0, nil
+}
+
+// canonicalComparer is an io.Writer that compares what is written to
+// it with data, failing as soon as they differ.
+type canonicalComparer struct {
+	data    []byte // the part of the segment that has not been written
+	differs bool
+}
+
+var errNotCanonical = errors.New("not canonical")
+
+func (cmp *canonicalComparer) Write(b []byte) (int, error) {
+	if len(b) > len(cmp.data) || !bytes.Equal(b, cmp.data[:len(b)]) {
+		cmp.differs = true
+		return 0, errNotCanonical
+	}
+	cmp.data = cmp.data[len(b):]
+	return len(b), nil
+}
+
+// canonicalWriter writes objects in canonical order: each object is
+// followed by the objects that it points to, in pointer order, each
+// followed in turn by the objects that it points to.
+type canonicalWriter struct {
+	w    *bufio.Writer
+	err  error // first error from w
+	pos  Size  // number of bytes written
+	buf  [wordSize]byte
+	zero [wordSize]byte
+
+	// sizes holds the canonical size of the objects pointed to by each
+	// pointer section, including the objects that they point to, in
+	// the order that the sections are written.
+	sizes []Size
+	next  int // index in sizes of the next pointer to write
+}
+
+// canonicalPtrs is a pointer section of n pointers starting at addr.
+type canonicalPtrs struct {
+	seg        *Segment
+	addr       address
+	n          int
+	depthLimit uint
+}
+
+func structPtrs(s Struct, n uint16) canonicalPtrs {
+	return canonicalPtrs{
+		seg:        s.seg,
+		addr:       s.pointerAddress(0),
+		n:          int(n),
+		depthLimit: s.depthLimit,
+	}
+}
+
+// at reads the i'th pointer.  Only the measuring pass is charged to the
+// read limit, since the writing pass reads the same objects.
+func (ptrs canonicalPtrs) at(i int, charge bool) (Ptr, error) {
+	addr := ptrs.addr.addSizeUnchecked(wordSize.timesUnchecked(int32(i)))
+	if charge {
+		return ptrs.seg.readPtr(addr, ptrs.depthLimit)
+	}
+	return ptrs.seg.readPtrUnaccounted(addr, ptrs.depthLimit)
+}
+
+// measure returns the canonical size of the object that p points to,
+// including the objects that it points to, and records the sizes of
+// the objects in its pointer sections.
+func (c *canonicalWriter) measure(p Ptr) (Size, error) {
+	if !p.IsValid() {
+		return 0, nil
+	}
+	switch p.flags.ptrType() {
+	case structPtrType:
+		s := p.Struct()
+		sz := canonicalStructSize(s)
+		return c.measurePtrs(sz.totalSize(), structPtrs(s, sz.PointerCount), c.reserve(int(sz.PointerCount)))
+	case listPtrType:
+		l := p.List()
+		if l.flags&isCompositeList != 0 {
+			elemSize := canonicalElemSize(l)
+			total, ok := elemSize.totalSize().times(l.length)
+			if !ok {
+				return 0, errors.New("list too large")
+			}
+			total += wordSize
+			n := int(elemSize.PointerCount)
+			base := c.reserve(l.Len() * n)
+			for i := 0; i < l.Len(); i++ {
+				var err error
+				total, err = c.measurePtrs(total, structPtrs(l.Struct(i), elemSize.PointerCount), base+i*n)
+				if err != nil {
+					return 0, exc.WrapError("list element "+str.Itod(i), err)
+				}
+			}
+			return total, nil
+		}
+		if l.size.PointerCount == 0 {
+			return l.allocSize().padToWord(), nil
+		}
+		ptrs := canonicalPtrs{seg: l.seg, addr: l.off, n: l.Len(), depthLimit: l.depthLimit}
+		return c.measurePtrs(l.allocSize(), ptrs, c.reserve(ptrs.n))
+	default:
+		// Interfaces have no object.
+		return 0, nil
+	}
+}
+
+// reserve adds n sizes to c.sizes and returns the index of the first.
+func (c *canonicalWriter) reserve(n int) int {
+	base := len(c.sizes)
+	for i := 0; i < n; i++ {
+		c.sizes = append(c.sizes, 0)
+	}
+	return base
+}
+
+// measurePtrs records the sizes of the objects that ptrs point to in
+// c.sizes, starting at base, and returns their total plus total.
+func (c *canonicalWriter) measurePtrs(total Size, ptrs canonicalPtrs, base int) (Size, error) {
+	for i := 0; i < ptrs.n; i++ {
+		p, err := ptrs.at(i, true)
+		if err != nil {
+			return 0, exc.WrapError("pointer "+str.Itod(i), err)
+		}
+		sz, err := c.measure(p)
+		if err != nil {
+			return 0, exc.WrapError("pointer "+str.Itod(i), err)
+		}
+		c.sizes[base+i] = sz
+		if sz > maxSegmentSize-total {
+			return 0, errors.New("canonical form too large")
+		}
+		total += sz
+	}
+	return total, nil
+}
+
+// writeObject writes the object that p points to, followed by the
+// objects that it points to.
+func (c *canonicalWriter) writeObject(p Ptr) error {
+	if !p.IsValid() {
+		return c.err
+	}
+	switch p.flags.ptrType() {
+	case structPtrType:
+		s := p.Struct()
+		sz := canonicalStructSize(s)
+		ptrs := structPtrs(s, sz.PointerCount)
+		c.write(s.seg.slice(s.off, sz.DataSize))
+		if _, err := c.writePtrs(ptrs, c.pos+sz.pointerSize()); err != nil {
+			return err
+		}
+		return c.writeTargets(ptrs)
+	case listPtrType:
+		l := p.List()
+		if l.flags&isCompositeList != 0 {
+			elemSize := canonicalElemSize(l)
+			c.writeWord(rawStructPointer(pointerOffset(l.length), elemSize))
+			// All elements come before the objects that they point to.
+			target := c.pos + elemSize.totalSize().timesUnchecked(l.length)
+			for i := 0; i < l.Len(); i++ {
+				e := l.Struct(i)
+				c.write(e.seg.slice(e.off, elemSize.DataSize))
+				var err error
+				target, err = c.writePtrs(structPtrs(e, elemSize.PointerCount), target)
+				if err != nil {
+					return exc.WrapError("list element "+str.Itod(i), err)
+				}
+			}
+			for i := 0; i < l.Len(); i++ {
+				if err := c.writeTargets(structPtrs(l.Struct(i), elemSize.PointerCount)); err != nil {
+					return exc.WrapError("list element "+str.Itod(i), err)
+				}
+			}
+			return c.err
+		}
+		if l.size.PointerCount == 0 {
+			sz := l.allocSize()
+			c.write(l.seg.slice(l.off, sz))
+			c.write(c.zero[:sz.padToWord()-sz])
+			return c.err
+		}
+		ptrs := canonicalPtrs{seg: l.seg, addr: l.off, n: l.Len(), depthLimit: l.depthLimit}
+		if _, err := c.writePtrs(ptrs, c.pos+l.allocSize()); err != nil {
+			return err
+		}
+		return c.writeTargets(ptrs)
+	default:
+		return c.err
+	}
+}
+
+// writePtrs writes the pointers in ptrs, pointing the first at target
+// and each of the others after the objects that precede it.  It returns
+// the position after the objects.
+func (c *canonicalWriter) writePtrs(ptrs canonicalPtrs, target Size) (Size, error) {
+	for i := 0; i < ptrs.n; i++ {
+		p, err := ptrs.at(i, false)
+		if err != nil {
+			return 0, exc.WrapError("pointer "+str.Itod(i), err)
+		}
+		off := pointerOffset((target - c.pos - wordSize) / wordSize)
+		c.writeWord(canonicalPointer(p, off))
+		target += c.sizes[c.next]
+		c.next++
+	}
+	return target, c.err
+}
+
+// writeTargets writes the objects that ptrs point to.
+func (c *canonicalWriter) writeTargets(ptrs canonicalPtrs) error {
+	for i := 0; i < ptrs.n; i++ {
+		p, err := ptrs.at(i, false)
+		if err != nil {
+			return exc.WrapError("pointer "+str.Itod(i), err)
+		}
+		if err := c.writeObject(p); err != nil {
+			return exc.WrapError("pointer "+str.Itod(i), err)
+		}
+	}
+	return c.err
+}
+
+func (c *canonicalWriter) write(b []byte) {
+	if c.err != nil {
+		return
+	}
+	_, c.err = c.w.Write(b)
+	c.pos += Size(len(b))
+}
+
+func (c *canonicalWriter) writeWord(p rawPointer) {
+	binary.LittleEndian.PutUint64(c.buf[:], uint64(p))
+	c.write(c.buf[:])
+}
+
+// canonicalPointer returns the pointer to the canonical form of the
+// object that p points to, which starts off words after the pointer.
+func canonicalPointer(p Ptr, off pointerOffset) rawPointer {
+	if !p.IsValid() {
+		return 0
+	}
+	switch p.flags.ptrType() {
+	case structPtrType:
+		sz := canonicalStructSize(p.Struct())
+		if sz.isZero() {
+			return rawStructPointer(-1, sz)
+		}
+		return rawStructPointer(off, sz)
+	case listPtrType:
+		l := p.List()
+		if l.flags&isCompositeList != 0 {
+			return rawListPointer(off, compositeList, l.length*canonicalElemSize(l).totalWordCount())
+		}
+		return l.raw().withOffset(off)
+	case interfacePtrType:
+		return rawInterfacePointer(p.Interface().Capability())
+	default:
+		panic("unreachable")
+	}
+}
+
+// canonicalElemSize returns the element size of the canonical form of
+// a composite list: the largest canonical size of its elements.
+func canonicalElemSize(l List) ObjectSize {
+	var elemSize ObjectSize
+	for i := 0; i < l.Len(); i++ {
+		sz := canonicalStructSize(l.Struct(i))
+		if sz.DataSize > elemSize.DataSize {
+			elemSize.DataSize = sz.DataSize
+		}
+		if sz.PointerCount > elemSize.PointerCount {
+			elemSize.PointerCount = sz.PointerCount
+		}
+	}
+	return elemSize
+}
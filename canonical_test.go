@@ -1,8 +1,11 @@
 package capnp
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -160,6 +163,23 @@ func TestCanonicalize(t *testing.T) {
 			0x01, 0, 0, 0, 0x07, 0, 0, 0,
 			0, 0, 0, 0, 0, 0, 0, 0,
 		},
+	}, {
+		name: "data-only struct list",
+		f: func() Struct {
+			_, seg := NewSingleSegmentMessage(nil)
+			s, _ := NewStruct(seg, ObjectSize{PointerCount: 1})
+			l, _ := NewCompositeList(seg, ObjectSize{DataSize: 16}, 2)
+			s.SetPtr(0, l.ToPtr())
+			l.Struct(0).SetUint8(0, 7)
+			return s
+		},
+		want: []byte{
+			0, 0, 0, 0, 0, 0, 1, 0,
+			0x01, 0, 0, 0, 0x17, 0, 0, 0,
+			0x08, 0, 0, 0, 1, 0, 0, 0,
+			7, 0, 0, 0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0, 0, 0, 0,
+		},
 	}}
 
 	for i := range tests {
@@ -168,7 +188,194 @@ func TestCanonicalize(t *testing.T) {
 			b, err := Canonicalize(tc.f())
 			require.NoError(t, err)
 			require.Equal(t, tc.want, b)
+
+			var buf bytes.Buffer
+			require.NoError(t, CanonicalizeInto(&buf, tc.f()))
+			require.Equal(t, tc.want, buf.Bytes())
+
+			msg, _, err := NewMessage(SingleSegment(tc.want))
+			require.NoError(t, err)
+			ok, err := IsCanonical(msg)
+			require.NoError(t, err)
+			require.True(t, ok, "IsCanonical")
 		})
 	}
 
 }
+
+func TestCanonicalizeInto(t *testing.T) {
+	t.Parallel()
+
+	// Build a struct spread over several segments, with objects of
+	// every kind nested in each other, including empty ones between
+	// siblings.
+	msg, seg, err := NewMessage(MultiSegment(nil))
+	require.NoError(t, err)
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 16, PointerCount: 4})
+	require.NoError(t, err)
+	root.SetUint64(0, 0xfeed)
+	ptrs, err := NewPointerList(seg, 4)
+	require.NoError(t, err)
+	require.NoError(t, root.SetPtr(0, ptrs.ToPtr()))
+	empty, err := NewInt8List(seg, 0)
+	require.NoError(t, err)
+	require.NoError(t, ptrs.Set(0, empty.ToPtr()))
+	txt, err := NewText(seg, "hello")
+	require.NoError(t, err)
+	require.NoError(t, ptrs.Set(2, txt.ToPtr()))
+	require.NoError(t, ptrs.Set(3, NewInterface(seg, 3).ToPtr()))
+	bits, err := NewBitList(seg, 70)
+	require.NoError(t, err)
+	bits.Set(69, true)
+	require.NoError(t, root.SetPtr(1, bits.ToPtr()))
+	structs, err := NewCompositeList(seg, ObjectSize{DataSize: 24, PointerCount: 3}, 3)
+	require.NoError(t, err)
+	require.NoError(t, root.SetPtr(2, structs.ToPtr()))
+	for i := 0; i < structs.Len(); i++ {
+		e := structs.Struct(i)
+		e.SetUint32(0, uint32(i))
+		child, err := NewStruct(seg, ObjectSize{DataSize: 8, PointerCount: 1})
+		require.NoError(t, err)
+		child.SetUint64(0, uint64(100+i))
+		name, err := NewText(seg, "child")
+		require.NoError(t, err)
+		require.NoError(t, child.SetPtr(0, name.ToPtr()))
+		require.NoError(t, e.SetPtr(uint16(i%2), child.ToPtr()))
+	}
+	// Fill up the first segment so that later objects land in others.
+	data, err := NewData(seg, make([]byte, 64<<10))
+	require.NoError(t, err)
+	last, err := NewStruct(data.Segment(), ObjectSize{PointerCount: 1})
+	require.NoError(t, err)
+	require.NoError(t, last.SetPtr(0, txt.ToPtr()))
+	require.NoError(t, root.SetPtr(3, last.ToPtr()))
+	require.Greater(t, msg.NumSegments(), int64(1))
+
+	want, err := Canonicalize(root)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, CanonicalizeInto(&buf, root))
+	require.Equal(t, want, buf.Bytes())
+
+	errWrite := errors.New("write failed")
+	err = CanonicalizeInto(failingWriter{errWrite}, root)
+	require.ErrorIs(t, err, errWrite)
+}
+
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestIsCanonical(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"no root pointer", []byte{}, false},
+		{"null root", []byte{0, 0, 0, 0, 0, 0, 0, 0}, true},
+		{"partial word", []byte{0, 0, 0, 0}, false},
+		{
+			name: "struct",
+			data: []byte{
+				0, 0, 0, 0, 1, 0, 0, 0,
+				0xef, 0xbe, 0, 0, 0, 0, 0, 0,
+			},
+			want: true,
+		},
+		{
+			name: "zero data word",
+			data: []byte{
+				0, 0, 0, 0, 2, 0, 0, 0,
+				0xef, 0xbe, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "null pointer",
+			data: []byte{
+				0, 0, 0, 0, 1, 0, 1, 0,
+				0xef, 0xbe, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "trailing word",
+			data: []byte{
+				0, 0, 0, 0, 1, 0, 0, 0,
+				0xef, 0xbe, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "empty struct not at -1",
+			data: []byte{
+				0, 0, 0, 0, 0, 0, 1, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "objects out of order",
+			data: []byte{
+				0, 0, 0, 0, 0, 0, 2, 0,
+				0x09, 0, 0, 0, 0x0a, 0, 0, 0, // list of 1 byte, 2 words later
+				0x00, 0, 0, 0, 1, 0, 0, 0, // struct right after the pointers
+				1, 0, 0, 0, 0, 0, 0, 0,
+				2, 0, 0, 0, 0, 0, 0, 0,
+			},
+		},
+		{
+			name: "objects in order",
+			data: []byte{
+				0, 0, 0, 0, 0, 0, 2, 0,
+				0x05, 0, 0, 0, 0x0a, 0, 0, 0, // list of 1 byte, after the struct
+				0x04, 0, 0, 0, 1, 0, 0, 0,
+				2, 0, 0, 0, 0, 0, 0, 0,
+				1, 0, 0, 0, 0, 0, 0, 0,
+			},
+			want: true,
+		},
+		{
+			name: "non-zero list padding",
+			data: []byte{
+				0, 0, 0, 0, 0, 0, 1, 0,
+				0x01, 0, 0, 0, 0x0a, 0, 0, 0,
+				1, 0, 0, 0, 0, 0, 0, 0xff,
+			},
+		},
+	}
+	for _, test := range tests {
+		msg, _, err := NewMessage(SingleSegment(test.data))
+		require.NoError(t, err, test.name)
+		ok, err := IsCanonical(msg)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.want, ok, test.name)
+	}
+
+	t.Run("multiple segments", func(t *testing.T) {
+		msg, seg, err := NewMessage(MultiSegment(nil))
+		require.NoError(t, err)
+		root, err := NewRootStruct(seg, ObjectSize{DataSize: 8})
+		require.NoError(t, err)
+		root.SetUint64(0, 1)
+		data, err := NewData(seg, make([]byte, 64<<10))
+		require.NoError(t, err)
+		_, err = NewStruct(data.Segment(), ObjectSize{DataSize: 8})
+		require.NoError(t, err)
+		require.Greater(t, msg.NumSegments(), int64(1))
+		ok, err := IsCanonical(msg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid root", func(t *testing.T) {
+		msg, _, err := NewMessage(SingleSegment([]byte{0, 0, 0, 0, 1, 0, 0, 0}))
+		require.NoError(t, err)
+		_, err = IsCanonical(msg)
+		assert.Error(t, err)
+	})
+}
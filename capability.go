@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"capnproto.org/go/capnp/v3/exc"
 	"capnproto.org/go/capnp/v3/exp/bufferpool"
@@ -117,6 +118,28 @@ type ClientKind = struct {
 
 type client struct {
 	state mutex.Mutex[clientState]
+
+	// order is set by ReleaseBefore, and nil for clients that were
+	// never ordered.  Its contents are guarded by releaseOrderMu.
+	order atomic.Pointer[releaseOrder]
+}
+
+// releaseOrderMu guards the release ordering graph built by
+// ReleaseBefore.  It is only taken for clients that have an order, so
+// releasing unrelated clients does not contend on it.
+var releaseOrderMu sync.Mutex
+
+// releaseOrder is a client's place in the release ordering graph.
+type releaseOrder struct {
+	// before is the number of clients that must be released before
+	// this one.
+	before int
+
+	// pending is set when Release was called while before > 0.
+	pending bool
+
+	// next lists the clients that wait on this one.
+	next []*client
 }
 
 type clientState struct {
@@ -412,7 +435,7 @@ func (c Client) SendCall(ctx context.Context, s Send) (*Answer, ReleaseFunc) {
 		l.Unlock()
 	}
 
-	return ans, rel
+	return ans, setupCallRelease(s.Method, rel)
 }
 
 // SendStreamCall is like SendCall except that:
@@ -494,8 +517,8 @@ func (c Client) IsValid() bool {
 
 // IsSame reports whether c and c2 refer to a capability created by the
 // same call to NewClient.  This can return false negatives if c or c2
-// are not fully resolved: use Resolve if this is an issue.  If either
-// c or c2 are released, then IsSame panics.
+// are not fully resolved: use Resolve or IsSameResolved if this is an
+// issue.  If either c or c2 are released, then IsSame panics.
 func (c Client) IsSame(c2 Client) bool {
 	h1, _, released := c.startCall()
 	defer h1.Release()
@@ -507,6 +530,40 @@ func (c Client) IsSame(c2 Client) bool {
 	if released {
 		panic("IsSame on released client")
 	}
+	return sameHook(h1, h2, false)
+}
+
+// IsSameResolved reports whether c and c2 refer to the same capability.
+// Unlike IsSame, it first waits for both clients to resolve, and it also
+// recognizes distinct hooks that identify the same capability (see
+// CapIdentifier), such as two references to the same capability
+// imported over an RPC connection.  IsSameResolved returns an error if
+// either client is released or if ctx is done before both clients
+// resolve.
+func (c Client) IsSameResolved(ctx context.Context, c2 Client) (bool, error) {
+	if err := c.Resolve(ctx); err != nil {
+		return false, err
+	}
+	if err := c2.Resolve(ctx); err != nil {
+		return false, err
+	}
+	h1, _, released := c.startCall()
+	defer h1.Release()
+	if released {
+		return false, errors.New("IsSameResolved on released client")
+	}
+	h2, _, released := c2.startCall()
+	defer h2.Release()
+	if released {
+		return false, errors.New("IsSameResolved on released client")
+	}
+	return sameHook(h1, h2, true), nil
+}
+
+// sameHook reports whether h1 and h2 refer to the same capability.  If
+// byIdentity is true, then hooks that implement CapIdentifier are
+// compared by their identities.
+func sameHook(h1, h2 *rc.Ref[clientHook], byIdentity bool) bool {
 	valid1 := h1.IsValid()
 	valid2 := h2.IsValid()
 	if !valid1 && !valid2 {
@@ -515,7 +572,19 @@ func (c Client) IsSame(c2 Client) bool {
 	if !valid1 || !valid2 {
 		return false
 	}
-	return h1.Value() == h2.Value()
+	if h1.Value() == h2.Value() {
+		return true
+	}
+	if !byIdentity {
+		return false
+	}
+	id1, ok1 := h1.Value().ClientHook.(CapIdentifier)
+	id2, ok2 := h2.Value().ClientHook.(CapIdentifier)
+	if !ok1 || !ok2 {
+		return false
+	}
+	v1, v2 := id1.CapIdentity(), id2.CapIdentity()
+	return v1 != nil && v1 == v2
 }
 
 // Resolve blocks until the capability is fully resolved or the Context is Done.
@@ -589,6 +658,114 @@ func (c Client) AttachReleaser(f func()) (released bool) {
 	return
 }
 
+// ReleaseBefore declares that c must be released before dep, such as a
+// file handle before the filesystem it was opened from.  If dep.Release()
+// is called while c is still held, then dep is released once c is,
+// instead of right away.  A client may wait on several others, and is
+// released after the last of them.
+//
+// ReleaseBefore returns an error if either client is nil or has already
+// been released, or if the order would form a cycle.  It must not race
+// with the release of either client.
+func (c Client) ReleaseBefore(dep Client) error {
+	if c.client == nil || dep.client == nil {
+		return errors.New("ReleaseBefore on null client")
+	}
+	releaseOrderMu.Lock()
+	defer releaseOrderMu.Unlock()
+	if c.isReleased() || dep.isReleased() {
+		return errors.New("ReleaseBefore on released client")
+	}
+	if dep.client == c.client || dep.client.precedes(c.client) {
+		return errors.New("ReleaseBefore would form a release cycle")
+	}
+	o := c.client.releaseOrder()
+	o.next = append(o.next, dep.client)
+	dep.client.releaseOrder().before++
+	return nil
+}
+
+func (c Client) isReleased() bool {
+	return mutex.With1(&c.state, func(s *clientState) bool {
+		return s.released
+	})
+}
+
+// releaseOrder returns cl's place in the release ordering graph,
+// creating it if needed.  The caller must hold releaseOrderMu.
+func (cl *client) releaseOrder() *releaseOrder {
+	o := cl.order.Load()
+	if o == nil {
+		o = new(releaseOrder)
+		cl.order.Store(o)
+	}
+	return o
+}
+
+// precedes reports whether cl must be released before target, directly
+// or transitively.  The caller must hold releaseOrderMu.
+func (cl *client) precedes(target *client) bool {
+	seen := map[*client]bool{cl: true}
+	stack := []*client{cl}
+	for len(stack) > 0 {
+		o := stack[len(stack)-1].order.Load()
+		stack = stack[:len(stack)-1]
+		if o == nil {
+			continue
+		}
+		for _, n := range o.next {
+			if n == target {
+				return true
+			}
+			if !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return false
+}
+
+// deferRelease reports whether cl is still waiting on clients ordered
+// before it, marking its release as pending if so.
+func (cl *client) deferRelease() bool {
+	o := cl.order.Load()
+	if o == nil {
+		return false
+	}
+	releaseOrderMu.Lock()
+	defer releaseOrderMu.Unlock()
+	if o.before > 0 {
+		o.pending = true
+		return true
+	}
+	return false
+}
+
+// releaseNext releases the clients that were only waiting on cl.
+func (cl *client) releaseNext() {
+	o := cl.order.Load()
+	if o == nil {
+		return
+	}
+	releaseOrderMu.Lock()
+	next := o.next
+	o.next = nil
+	var ready []*client
+	for _, n := range next {
+		no := n.order.Load()
+		no.before--
+		if no.before == 0 && no.pending {
+			no.pending = false
+			ready = append(ready, n)
+		}
+	}
+	releaseOrderMu.Unlock()
+	for _, n := range ready {
+		Client{client: n}.Release()
+	}
+}
+
 // A Brand is an opaque value used to identify a capability.
 type Brand struct {
 	Value any
@@ -788,9 +965,11 @@ func (c Client) String() string {
 // with the capability will be released.
 //
 // Release has no effect if c has already been released, or if c is
-// nil or resolved to null.
+// nil or resolved to null.  If other clients were ordered before c
+// with ReleaseBefore, then the release is deferred until they have
+// been released.
 func (c Client) Release() {
-	if c.client == nil {
+	if c.client == nil || c.client.deferRelease() {
 		return
 	}
 	limiter := c.GetFlowLimiter()
@@ -805,6 +984,7 @@ func (c Client) Release() {
 			}
 		}
 	})
+	c.client.releaseNext()
 }
 
 func (c Client) EncodeAsPtr(seg *Segment) Ptr {
@@ -830,6 +1010,10 @@ func (s *resolveState) isResolved() bool {
 
 var setupLeakReporting func(any) = func(any) {}
 
+// setupCallRelease wraps the ReleaseFunc of a call to m made by
+// Client.SendCall.
+var setupCallRelease = func(m Method, rel ReleaseFunc) ReleaseFunc { return rel }
+
 // SetClientLeakFunc sets a callback for reporting Clients that went
 // out of scope without being released.  The callback is not guaranteed
 // to be called and must be safe to call concurrently from multiple
@@ -838,35 +1022,122 @@ var setupLeakReporting func(any) = func(any) {}
 // SetClientLeakFunc must not be called after any calls to NewClient or
 // NewPromisedClient.
 func SetClientLeakFunc(clientLeakFunc func(msg string)) {
-	setupLeakReporting = func(v any) {
-		buf := bufferpool.Default.Get(1e6)
-		n := runtime.Stack(buf, false)
-		stack := string(buf[:n])
-		bufferpool.Default.Put(buf)
-		switch c := v.(type) {
-		case Client:
-			runtime.SetFinalizer(c.client, func(c *client) {
-				released := mutex.With1(&c.state, func(c *clientState) bool {
-					return c.released
-				})
-				if released {
-					return
-				}
-				clientLeakFunc("leaked client created at:\n\n" + stack)
+	h := &leakHandler{report: clientLeakFunc}
+	setupLeakReporting = h.setup
+}
+
+// SetLeakRelease makes Clients, ClientSnapshots and calls that are
+// garbage collected without being released be released anyway, as a
+// fail-safe for code paths that forget to.  Releasing a leaked call
+// sends Finish to the remote vat, so a long-running process that leaks
+// degrades gracefully instead of holding on to remote resources
+// forever.  This costs a finalizer for every client and call.
+//
+// A call leaks when its ReleaseFunc becomes unreachable without having
+// been called.  Its results are released along with it, so code that
+// drops the ReleaseFunc while it still reads the results may see them
+// change underneath it.  The fallback is no substitute for releasing.
+//
+// If leakFunc is not nil, it is called with a message describing each
+// leak, which includes the stack that created the leaked value, like
+// the callback passed to SetClientLeakFunc.  Recording the stacks is
+// expensive, so leakFunc should be nil in production unless leaks are
+// being tracked down.
+//
+// SetLeakRelease replaces any callback set by SetClientLeakFunc, and
+// it must not be called after any calls to NewClient or
+// NewPromisedClient.
+func SetLeakRelease(leakFunc func(msg string)) {
+	h := &leakHandler{report: leakFunc, release: true}
+	setupLeakReporting = h.setup
+	setupCallRelease = h.setupCall
+}
+
+// A leakHandler sets finalizers that report or release values that are
+// garbage collected without being released.
+type leakHandler struct {
+	report  func(msg string) // may be nil if release is set
+	release bool
+}
+
+// stack returns the current goroutine's stack, if it will be reported.
+func (h *leakHandler) stack() string {
+	if h.report == nil {
+		return ""
+	}
+	buf := bufferpool.Default.Get(1e6)
+	n := runtime.Stack(buf, false)
+	stack := string(buf[:n])
+	bufferpool.Default.Put(buf)
+	return stack
+}
+
+// leaked reports a leak of what, created at stack, and releases it
+// with release if h releases leaks.  release is called on its own
+// goroutine so that it cannot block other finalizers.
+func (h *leakHandler) leaked(what, stack string, release func()) {
+	if h.report != nil {
+		msg := "leaked " + what + " created at:\n\n" + stack
+		if h.release {
+			msg = "released " + msg
+		}
+		h.report(msg)
+	}
+	if h.release {
+		go release()
+	}
+}
+
+func (h *leakHandler) setup(v any) {
+	stack := h.stack()
+	switch c := v.(type) {
+	case Client:
+		runtime.SetFinalizer(c.client, func(c *client) {
+			released := mutex.With1(&c.state, func(c *clientState) bool {
+				return c.released
 			})
-		case ClientSnapshot:
+			if released {
+				return
+			}
+			h.leaked("client", stack, Client{client: c}.Release)
+		})
+	case ClientSnapshot:
+		if !c.IsValid() {
+			return
+		}
+		runtime.SetFinalizer(c.hook, func(c *rc.Ref[clientHook]) {
 			if !c.IsValid() {
 				return
 			}
-			runtime.SetFinalizer(c.hook, func(c *rc.Ref[clientHook]) {
-				if !c.IsValid() {
-					return
-				}
-				clientLeakFunc("leaked client snapshot created at:\n\n" + stack)
-			})
-		default:
-			panic("setupLeakReporting called on unrecognized type!")
+			h.leaked("client snapshot", stack, c.Release)
+		})
+	default:
+		panic("setupLeakReporting called on unrecognized type!")
+	}
+}
+
+func (h *leakHandler) setupCall(m Method, rel ReleaseFunc) ReleaseFunc {
+	g := &callGuard{rel: rel}
+	stack := h.stack()
+	runtime.SetFinalizer(g, func(g *callGuard) {
+		if g.done.Load() {
+			return
 		}
+		h.leaked("call to "+m.String(), stack, g.release)
+	})
+	return g.release
+}
+
+// A callGuard is the target of the finalizer of a call's ReleaseFunc.
+// It is only reachable through the ReleaseFunc returned to the caller.
+type callGuard struct {
+	done atomic.Bool
+	rel  ReleaseFunc
+}
+
+func (g *callGuard) release() {
+	if g.done.CompareAndSwap(false, true) {
+		g.rel()
 	}
 }
 
@@ -998,6 +1269,17 @@ type ClientHook interface {
 	String() string
 }
 
+// A CapIdentifier is a ClientHook that can identify the capability it
+// refers to, so that Client.IsSameResolved can recognize distinct hooks
+// for the same capability.  Implementing it is optional.
+type CapIdentifier interface {
+	// CapIdentity returns a comparable value that identifies the
+	// capability.  Two hooks whose identities are equal and non-nil
+	// refer to the same capability.  The identity must not change
+	// during the hook's lifetime.
+	CapIdentity() any
+}
+
 // Send is the input to ClientHook.Send.
 type Send struct {
 	// Method must have InterfaceID and MethodID filled in.
@@ -1106,9 +1388,22 @@ type Method struct {
 }
 
 // String returns a formatted string containing the interface name or
-// the method name if present, otherwise it uses the raw IDs.
+// the method name if present, otherwise it uses the names recorded by
+// RegisterMethods, or failing that, the raw IDs.
 // This is suitable for use in error messages and logs.
 func (m *Method) String() string {
+	if m.InterfaceName == "" || m.MethodName == "" {
+		if info, ok := LookupMethod(m.InterfaceID, m.MethodID); ok {
+			named := info.Method
+			if m.InterfaceName != "" {
+				named.InterfaceName = m.InterfaceName
+			}
+			if m.MethodName != "" {
+				named.MethodName = m.MethodName
+			}
+			m = &named
+		}
+	}
 	buf := make([]byte, 0, 128)
 	if m.InterfaceName == "" {
 		buf = append(buf, '@', '0', 'x')
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"testing"
 	"time"
 
@@ -314,6 +315,139 @@ func TestPromisedClient_EarlyClose(t *testing.T) {
 	}
 }
 
+func TestIsSameResolved(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("Promise", func(t *testing.T) {
+		c := NewClient(new(dummyHook))
+		defer c.Release()
+		p, r := NewPromisedClient(new(dummyHook))
+		defer p.Release()
+		go r.Fulfill(c.AddRef())
+
+		same, err := p.IsSameResolved(ctx, c)
+		require.NoError(t, err)
+		assert.True(t, same, "promise is the same as its resolution")
+
+		other := NewClient(new(dummyHook))
+		defer other.Release()
+		same, err = p.IsSameResolved(ctx, other)
+		require.NoError(t, err)
+		assert.False(t, same, "promise is not the same as another client")
+	})
+	t.Run("Unresolved", func(t *testing.T) {
+		c := NewClient(new(dummyHook))
+		defer c.Release()
+		p, r := NewPromisedClient(new(dummyHook))
+		defer p.Release()
+		defer r.Fulfill(Client{})
+
+		ctx, cancel := context.WithTimeout(ctx, time.Second/10)
+		defer cancel()
+		_, err := c.IsSameResolved(ctx, p)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+	t.Run("Released", func(t *testing.T) {
+		c := NewClient(new(dummyHook))
+		defer c.Release()
+		rel := NewClient(new(dummyHook))
+		rel.Release()
+		_, err := c.IsSameResolved(ctx, rel)
+		assert.Error(t, err)
+	})
+	t.Run("Identity", func(t *testing.T) {
+		c1 := NewClient(&identityHook{id: 1})
+		defer c1.Release()
+		c2 := NewClient(&identityHook{id: 1})
+		defer c2.Release()
+		c3 := NewClient(&identityHook{id: 2})
+		defer c3.Release()
+
+		assert.False(t, c1.IsSame(c2), "IsSame ignores identities")
+		same, err := c1.IsSameResolved(ctx, c2)
+		require.NoError(t, err)
+		assert.True(t, same, "hooks with the same identity")
+		same, err = c1.IsSameResolved(ctx, c3)
+		require.NoError(t, err)
+		assert.False(t, same, "hooks with different identities")
+	})
+}
+
+// identityHook is a dummyHook that implements CapIdentifier.
+func TestLeakRelease(t *testing.T) {
+	t.Parallel()
+
+	msgs := make(chan string, 10)
+	h := &leakHandler{
+		report:  func(msg string) { msgs <- msg },
+		release: true,
+	}
+	gcUntil := func(done <-chan struct{}) bool {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			runtime.GC()
+			select {
+			case <-done:
+				return true
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		return false
+	}
+
+	hook := &shutdownHook{shutdown: make(chan struct{})}
+	func() {
+		c := NewClient(hook)
+		h.setup(c)
+	}()
+	require.True(t, gcUntil(hook.shutdown), "leaked client was not released")
+	assert.Contains(t, <-msgs, "released leaked client created at:")
+
+	released := make(chan struct{})
+	func() {
+		rel := h.setupCall(Method{InterfaceID: 0xa7317bd7216570aa, MethodID: 9}, func() { close(released) })
+		_ = rel
+	}()
+	require.True(t, gcUntil(released), "leaked call was not released")
+	assert.Contains(t, <-msgs, "released leaked call to @0xa7317bd7216570aa.@9 created at:")
+
+	// Calls that were released are not reported, and the ReleaseFunc
+	// only releases once.
+	n := 0
+	func() {
+		rel := h.setupCall(Method{}, func() { n++ })
+		rel()
+		rel()
+	}()
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, n)
+	assert.Empty(t, msgs)
+}
+
+// shutdownHook closes shutdown when it is shut down.
+type shutdownHook struct {
+	dummyHook
+	shutdown chan struct{}
+}
+
+func (sh *shutdownHook) Shutdown() {
+	close(sh.shutdown)
+}
+
+type identityHook struct {
+	dummyHook
+	id int
+}
+
+func (ih *identityHook) CapIdentity() any {
+	return ih.id
+}
+
 type dummyHook struct {
 	calls     int
 	brand     Brand
@@ -622,6 +756,66 @@ func TestWeakClient(t *testing.T) {
 	}
 }
 
+func TestReleaseBefore(t *testing.T) {
+	t.Run("Deferred", func(t *testing.T) {
+		fsHook, fileHook, dirHook := new(dummyHook), new(dummyHook), new(dummyHook)
+		fs, file, dir := NewClient(fsHook), NewClient(fileHook), NewClient(dirHook)
+		require.NoError(t, file.ReleaseBefore(fs))
+		require.NoError(t, dir.ReleaseBefore(fs))
+
+		fs.Release()
+		assert.Zero(t, fsHook.shutdowns, "fs released before its dependents")
+		file.Release()
+		assert.Equal(t, 1, fileHook.shutdowns)
+		assert.Zero(t, fsHook.shutdowns, "fs released while dir is held")
+		dir.Release()
+		assert.Equal(t, 1, dirHook.shutdowns)
+		assert.Equal(t, 1, fsHook.shutdowns)
+	})
+
+	t.Run("InOrder", func(t *testing.T) {
+		fsHook, fileHook := new(dummyHook), new(dummyHook)
+		fs, file := NewClient(fsHook), NewClient(fileHook)
+		require.NoError(t, file.ReleaseBefore(fs))
+
+		file.Release()
+		assert.Equal(t, 1, fileHook.shutdowns)
+		fs.Release()
+		assert.Equal(t, 1, fsHook.shutdowns)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		hooks := []*dummyHook{new(dummyHook), new(dummyHook), new(dummyHook)}
+		a, b, c := NewClient(hooks[0]), NewClient(hooks[1]), NewClient(hooks[2])
+		require.NoError(t, a.ReleaseBefore(b))
+		require.NoError(t, b.ReleaseBefore(c))
+
+		c.Release()
+		b.Release()
+		assert.Zero(t, hooks[1].shutdowns+hooks[2].shutdowns)
+		a.Release()
+		for i, h := range hooks {
+			assert.Equal(t, 1, h.shutdowns, "hook %d", i)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		a, b, c := NewClient(new(dummyHook)), NewClient(new(dummyHook)), NewClient(new(dummyHook))
+		defer a.Release()
+		defer c.Release()
+		require.NoError(t, a.ReleaseBefore(b))
+		require.NoError(t, b.ReleaseBefore(c))
+
+		assert.Error(t, c.ReleaseBefore(a), "cycle")
+		assert.Error(t, a.ReleaseBefore(a), "self")
+		assert.Error(t, a.ReleaseBefore(Client{}), "null")
+		b.Release()
+		d := NewClient(new(dummyHook))
+		d.Release()
+		assert.Error(t, a.ReleaseBefore(d), "released")
+	})
+}
+
 func TestWeakPromisedClient(t *testing.T) {
 	a := new(dummyHook)
 	b := new(dummyHook)
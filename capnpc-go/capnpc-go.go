@@ -5,6 +5,8 @@ Licensed under the MIT License:
 capnpc-go is the Cap'n proto code generator for Go.  It reads a
 CodeGeneratorRequest from stdin and for a file foo.capnp it writes
 foo.capnp.go.  This is usually invoked from `capnp compile -ogo`.
+With -testvectors, it also writes foo.capnp_test.go if foo.capnp
+declares struct constants annotated with $Go.testVector.
 
 See https://capnproto.org/otherlang.html#how-to-write-compiler-plugins
 for more details.
@@ -14,20 +16,26 @@ package main
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"flag"
 	"fmt"
 	"go/format"
 	"math"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/httpgw"
 	"capnproto.org/go/capnp/v3/internal/schema"
 )
 
@@ -38,6 +46,8 @@ const (
 	schemasImport     = capnpImport + "/schemas"
 	serverImport      = capnpImport + "/server"
 	flowcontrolImport = capnpImport + "/flowcontrol"
+	httpgwImport      = capnpImport + "/httpgw"
+	pogsImport        = capnpImport + "/pogs"
 )
 
 // genoptions are parameters that control code generation.
@@ -47,6 +57,11 @@ type genoptions struct {
 	schemas            bool
 	structStrings      bool
 	forceSchemasAlways bool
+	views              bool
+	testVectors        bool
+	http               bool
+	sync               bool
+	paramStructs       int
 }
 
 type renderer interface {
@@ -859,9 +874,17 @@ func (g *generator) defineStruct(n *node) error {
 	if err := g.defineStructFuncs(n); err != nil {
 		return err
 	}
+	if err := g.defineStructValidate(n); err != nil {
+		return err
+	}
 	if err := g.defineStructList(n); err != nil {
 		return err
 	}
+	if g.opts.views {
+		if err := g.defineStructView(n); err != nil {
+			return err
+		}
+	}
 	if g.opts.promises {
 		if err := g.defineStructPromise(n); err != nil {
 			return err
@@ -970,6 +993,362 @@ func (g *generator) defineStructFuncs(n *node) error {
 	return nil
 }
 
+// hasConstraints reports whether any field of n or of its groups has a
+// validation constraint annotation.
+func (g *generator) hasConstraints(n *node) bool {
+	for _, f := range n.codeOrderFields() {
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			fann, _ := f.Annotations()
+			if parseAnnotations(fann).HasConstraints() {
+				return true
+			}
+		case schema.Field_Which_group:
+			if grp := g.nodes[f.Group().TypeId()]; grp != nil && g.hasConstraints(grp) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defineStructValidate renders a Validate method for n and its groups
+// if any of their fields have validation constraints.
+func (g *generator) defineStructValidate(n *node) error {
+	if !g.hasConstraints(n) {
+		return nil
+	}
+	var checks []validateCheck
+	for _, f := range n.codeOrderFields() {
+		if strings.Title(f.Name) == "Validate" {
+			return fmt.Errorf("field %s.%s: accessor collides with the generated Validate method; rename it with $Go.name", n.shortDisplayName(), f.Name)
+		}
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			fann, _ := f.Annotations()
+			ann := parseAnnotations(fann)
+			if !ann.HasConstraints() {
+				continue
+			}
+			c, err := makeValidateCheck(n, f, ann)
+			if err != nil {
+				return fmt.Errorf("field %s.%s: %v", n.shortDisplayName(), f.Name, err)
+			}
+			checks = append(checks, c)
+		case schema.Field_Which_group:
+			grp, err := g.nodes.mustFind(f.Group().TypeId())
+			if err != nil {
+				return err
+			}
+			if !g.hasConstraints(grp) {
+				continue
+			}
+			if err := g.defineStructValidate(grp); err != nil {
+				return err
+			}
+			checks = append(checks, validateCheck{Field: f, Kind: "group"})
+		}
+	}
+	err := g.r.Render(structValidateParams{
+		G:      g,
+		Node:   n,
+		Checks: checks,
+	})
+	if err != nil {
+		return fmt.Errorf("validate for %s: %v", n, err)
+	}
+	return nil
+}
+
+func makeValidateCheck(n *node, f field, ann *annotations) (validateCheck, error) {
+	c := validateCheck{Field: f}
+	t, _ := f.Slot().Type()
+	switch t.Which() {
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64,
+		schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64,
+		schema.Type_Which_float32, schema.Type_Which_float64:
+		c.Kind = "number"
+	case schema.Type_Which_text:
+		c.Kind = "text"
+	case schema.Type_Which_data:
+		c.Kind = "data"
+	case schema.Type_Which_list:
+		c.Kind = "list"
+	default:
+		return c, fmt.Errorf("validation constraints not supported on %v fields", t.Which())
+	}
+	path := n.shortDisplayName() + "." + f.Name
+	if ann.Min != nil || ann.Max != nil {
+		if c.Kind != "number" {
+			return c, errors.New("$min and $max only apply to numeric fields")
+		}
+		conds, err := numberConds(path, t.Which(), ann)
+		if err != nil {
+			return c, err
+		}
+		c.Conds = append(c.Conds, conds...)
+	}
+	if ann.MaxLen != nil {
+		bound := strconv.FormatUint(uint64(*ann.MaxLen), 10)
+		switch c.Kind {
+		case "text", "data":
+			c.Conds = append(c.Conds, validateCond{
+				Expr: "len(v) > " + bound,
+				Msg:  path + ": length exceeds maximum " + bound,
+			})
+		case "list":
+			c.Conds = append(c.Conds, validateCond{
+				Expr: "v.Len() > " + bound,
+				Msg:  path + ": length exceeds maximum " + bound,
+			})
+		default:
+			return c, errors.New("$maxLen only applies to Text, Data and List fields")
+		}
+	}
+	if ann.Pattern != nil {
+		if c.Kind != "text" {
+			return c, errors.New("$pattern only applies to Text fields")
+		}
+		if _, err := regexp.Compile(*ann.Pattern); err != nil {
+			return c, fmt.Errorf("$pattern: %v", err)
+		}
+		c.Pattern = *ann.Pattern
+		c.PatternVar = "x_pattern_" + n.Name + "_" + f.Name
+		c.Conds = append(c.Conds, validateCond{
+			Expr: "!" + c.PatternVar + ".MatchString(v)",
+			Msg:  path + ": value does not match pattern " + strconv.Quote(*ann.Pattern),
+		})
+	}
+	return c, nil
+}
+
+// numberConds returns the $min and $max conditions for a numeric field
+// of type w.  The field value is compared in its own Go type: integer
+// bounds are rounded inward and emitted as integer literals, so fields
+// wider than a float64 mantissa are not rounded before the comparison.
+// Bounds that no value of the type can violate are dropped; bounds that
+// every value violates are reported as errors.
+func numberConds(path string, w schema.Type_Which, ann *annotations) ([]validateCond, error) {
+	var conds []validateCond
+	if ann.Min != nil {
+		bound, ok, err := numberBound(w, *ann.Min, true)
+		if err != nil {
+			return nil, fmt.Errorf("$min: %v", err)
+		}
+		if ok {
+			conds = append(conds, validateCond{
+				Expr: bound.value + " < " + bound.literal,
+				Msg:  path + ": value below minimum " + strconv.FormatFloat(*ann.Min, 'g', -1, 64),
+			})
+		}
+	}
+	if ann.Max != nil {
+		bound, ok, err := numberBound(w, *ann.Max, false)
+		if err != nil {
+			return nil, fmt.Errorf("$max: %v", err)
+		}
+		if ok {
+			conds = append(conds, validateCond{
+				Expr: bound.value + " > " + bound.literal,
+				Msg:  path + ": value above maximum " + strconv.FormatFloat(*ann.Max, 'g', -1, 64),
+			})
+		}
+	}
+	return conds, nil
+}
+
+type numericBound struct {
+	value   string // expression for the field value
+	literal string // Go literal for the bound
+}
+
+// numberBound converts b into a bound for a field of type w.  isMin
+// reports whether b is a minimum.  ok is false if no value of the type
+// lies beyond b.
+func numberBound(w schema.Type_Which, b float64, isMin bool) (bound numericBound, ok bool, err error) {
+	if math.IsNaN(b) {
+		return numericBound{}, false, errors.New("bound is NaN")
+	}
+	// A minimum of +Inf or a maximum of -Inf rejects everything;
+	// the opposite infinities reject nothing.
+	if math.IsInf(b, 0) {
+		if math.IsInf(b, 1) == isMin {
+			return numericBound{}, false, fmt.Errorf("%v rejects every value", b)
+		}
+		return numericBound{}, false, nil
+	}
+	switch w {
+	case schema.Type_Which_float32:
+		// float32 values convert to float64 exactly.
+		return numericBound{value: "float64(v)", literal: strconv.FormatFloat(b, 'g', -1, 64)}, true, nil
+	case schema.Type_Which_float64:
+		return numericBound{value: "v", literal: strconv.FormatFloat(b, 'g', -1, 64)}, true, nil
+	}
+	lo, hi := intRange(w)
+	// Integers below a fractional minimum are those below its ceiling,
+	// and likewise for maximums and floors.
+	var r *big.Int
+	if isMin {
+		r, _ = big.NewFloat(math.Ceil(b)).Int(nil)
+		if r.Cmp(lo) <= 0 {
+			return numericBound{}, false, nil
+		}
+		if r.Cmp(hi) > 0 {
+			return numericBound{}, false, fmt.Errorf("%v rejects every %v value", b, w)
+		}
+	} else {
+		r, _ = big.NewFloat(math.Floor(b)).Int(nil)
+		if r.Cmp(hi) >= 0 {
+			return numericBound{}, false, nil
+		}
+		if r.Cmp(lo) < 0 {
+			return numericBound{}, false, fmt.Errorf("%v rejects every %v value", b, w)
+		}
+	}
+	return numericBound{value: "v", literal: r.String()}, true, nil
+}
+
+// intRange returns the smallest and largest values of the integer type w.
+func intRange(w schema.Type_Which) (lo, hi *big.Int) {
+	var bits uint
+	signed := true
+	switch w {
+	case schema.Type_Which_int8:
+		bits = 8
+	case schema.Type_Which_int16:
+		bits = 16
+	case schema.Type_Which_int32:
+		bits = 32
+	case schema.Type_Which_int64:
+		bits = 64
+	case schema.Type_Which_uint8:
+		bits, signed = 8, false
+	case schema.Type_Which_uint16:
+		bits, signed = 16, false
+	case schema.Type_Which_uint32:
+		bits, signed = 32, false
+	case schema.Type_Which_uint64:
+		bits, signed = 64, false
+	default:
+		panic("intRange: not an integer type")
+	}
+	one := big.NewInt(1)
+	if !signed {
+		hi = new(big.Int).Lsh(one, bits)
+		return new(big.Int), hi.Sub(hi, one)
+	}
+	hi = new(big.Int).Lsh(one, bits-1)
+	lo = new(big.Int).Neg(hi)
+	return lo, hi.Sub(hi, one)
+}
+
+// defineStructView renders a plain Go struct holding the data fields of
+// n along with a FastRead method that fills it in.
+func (g *generator) defineStructView(n *node) error {
+	var fields []viewField
+	if n.StructNode().DiscriminantCount() > 0 {
+		fields = append(fields, viewField{
+			Name: "Which",
+			Type: n.Name + "_Which",
+			Expr: fmt.Sprintf("%s_Which(%s.LittleEndian.Uint16(d[%d:]))", n.Name, g.imports.Binary(), n.StructNode().DiscriminantOffset()*2),
+		})
+	}
+	for _, f := range n.codeOrderFields() {
+		if f.Which() != schema.Field_Which_slot {
+			continue
+		}
+		vf, ok, err := g.makeViewField(n, f)
+		if err != nil {
+			return fmt.Errorf("view field %s.%s: %v", n.shortDisplayName(), f.Name, err)
+		}
+		if ok {
+			fields = append(fields, vf)
+		}
+	}
+	err := g.r.Render(structViewParams{
+		G:        g,
+		Node:     n,
+		Fields:   fields,
+		DataSize: int(n.StructNode().DataWordCount()) * 8,
+	})
+	if err != nil {
+		return fmt.Errorf("view for %s: %v", n, err)
+	}
+	return nil
+}
+
+// makeViewField returns the view field for f, or ok == false if f is
+// not stored in the data section.
+func (g *generator) makeViewField(n *node, f field) (vf viewField, ok bool, err error) {
+	t, _ := f.Slot().Type()
+	def, _ := f.Slot().DefaultValue()
+	off := f.Slot().Offset()
+	vf.Name = strings.Title(f.Name)
+	if f.HasDiscriminant() {
+		vf.Doc = "Only meaningful if Which is " + n.Name + "_Which_" + f.Name + "."
+	}
+	bin := g.imports.Binary()
+	load := func(bits uint) string {
+		if bits == 8 {
+			return fmt.Sprintf("d[%d]", off)
+		}
+		return fmt.Sprintf("%s.LittleEndian.Uint%d(d[%d:])", bin, bits, off*uint32(bits/8))
+	}
+	switch t.Which() {
+	case schema.Type_Which_bool:
+		vf.Type = "bool"
+		vf.Expr = fmt.Sprintf("d[%d]&%#x != 0", off/8, 1<<(off%8))
+		if def.Bool() {
+			vf.Expr = fmt.Sprintf("d[%d]&%#x == 0", off/8, 1<<(off%8))
+		}
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		bits := intbits(t.Which())
+		vf.Type = fmt.Sprintf("uint%d", bits)
+		vf.Expr = load(bits)
+		if d := uintValue(def); d != 0 {
+			vf.Expr += fmt.Sprintf(" ^ %d", d)
+		}
+		if bits == 8 {
+			vf.Expr = "uint8(" + vf.Expr + ")"
+		}
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		bits := intbits(t.Which())
+		vf.Type = fmt.Sprintf("int%d", bits)
+		expr := load(bits)
+		if d := intFieldDefaultMask(def); d != 0 {
+			expr += fmt.Sprintf(" ^ %d", d)
+		}
+		vf.Expr = vf.Type + "(" + expr + ")"
+	case schema.Type_Which_enum:
+		vf.Type, err = g.RemoteTypeName(t, n)
+		if err != nil {
+			return vf, false, err
+		}
+		expr := load(16)
+		if d := def.Enum(); d != 0 {
+			expr += fmt.Sprintf(" ^ %d", d)
+		}
+		vf.Expr = vf.Type + "(" + expr + ")"
+	case schema.Type_Which_float32:
+		vf.Type = "float32"
+		expr := load(32)
+		if d := math.Float32bits(def.Float32()); d != 0 {
+			expr += fmt.Sprintf(" ^ %#x", d)
+		}
+		vf.Expr = g.imports.Math() + ".Float32frombits(" + expr + ")"
+	case schema.Type_Which_float64:
+		vf.Type = "float64"
+		expr := load(64)
+		if d := math.Float64bits(def.Float64()); d != 0 {
+			expr += fmt.Sprintf(" ^ %#x", d)
+		}
+		vf.Expr = g.imports.Math() + ".Float64frombits(" + expr + ")"
+	default:
+		return vf, false, nil
+	}
+	return vf, true, nil
+}
+
 func (g *generator) ObjectSize(n *node) (string, error) {
 	if n.Which() != schema.Node_Which_structNode {
 		return "", fmt.Errorf("object size called for %v node", n.Which())
@@ -1100,12 +1479,17 @@ func (g *generator) defineInterface(n *node) error {
 	if err != nil {
 		return fmt.Errorf("building method set of interface %s: %v", n, err)
 	}
+	fp, err := interfaceFingerprint(n, m)
+	if err != nil {
+		return fmt.Errorf("fingerprint of interface %s: %v", n, err)
+	}
 	nann, _ := n.Annotations()
 	err = g.r.Render(interfaceClientParams{
 		G:           g,
 		Node:        n,
 		Annotations: parseAnnotations(nann),
 		Methods:     m,
+		Fingerprint: fp,
 	})
 	if err != nil {
 		return fmt.Errorf("interface client %s: %v", n, err)
@@ -1129,9 +1513,224 @@ func (g *generator) defineInterface(n *node) error {
 		return fmt.Errorf("interface list %s: %v", n, err)
 	}
 
+	if g.opts.schemas {
+		// Register only the interface's own methods: inherited ones
+		// are registered by their own interface.
+		var own []interfaceMethod
+		for _, im := range m {
+			if im.Interface.Id() == n.Id() {
+				own = append(own, im)
+			}
+		}
+		err = g.r.Render(interfaceMethodInfoParams{
+			G:       g,
+			Node:    n,
+			Methods: own,
+		})
+		if err != nil {
+			return fmt.Errorf("interface method info %s: %v", n, err)
+		}
+	}
+
+	if g.opts.sync {
+		err = g.r.Render(interfaceSyncParams{
+			G:       g,
+			Node:    n,
+			Methods: m,
+		})
+		if err != nil {
+			return fmt.Errorf("interface sync methods %s: %v", n, err)
+		}
+	}
+
+	if g.opts.paramStructs > 0 {
+		if err := g.defineInterfaceArgs(n, m); err != nil {
+			return fmt.Errorf("interface args structs %s: %v", n, err)
+		}
+	}
+
+	if g.opts.http {
+		if err := g.defineInterfaceHTTP(n, m); err != nil {
+			return fmt.Errorf("interface HTTP routes %s: %v", n, err)
+		}
+	}
+
 	return nil
 }
 
+// defineInterfaceArgs generates Go structs for the parameters of the
+// methods that n declares with at least g.opts.paramStructs fields, along
+// with client methods that take them.  Methods with parameters that pogs
+// can't fill in from plain Go values are skipped.
+func (g *generator) defineInterfaceArgs(n *node, m []interfaceMethod) error {
+	var methods []interfaceArgsMethod
+	for _, im := range m {
+		if im.Interface.Id() != n.Id() {
+			continue
+		}
+		fields, ok, err := g.makeArgsFields(n, im.Params)
+		if err != nil {
+			return fmt.Errorf("method %s: %v", im.OriginalName, err)
+		}
+		if !ok || len(im.Params.codeOrderFields()) < g.opts.paramStructs {
+			continue
+		}
+		methods = append(methods, interfaceArgsMethod{
+			interfaceMethod: im,
+			TypeName:        n.Name + "_" + im.Name + "_Args",
+			Fields:          fields,
+		})
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+	return g.r.Render(interfaceArgsParams{
+		G:       g,
+		Node:    n,
+		Methods: methods,
+	})
+}
+
+// makeArgsFields returns the fields of the Go struct for params, or
+// ok == false if pogs can't fill in one of its fields from a plain Go
+// value.  Void fields are left out.
+func (g *generator) makeArgsFields(n, params *node) (fields []argsField, ok bool, err error) {
+	if params.StructNode().DiscriminantCount() > 0 {
+		return nil, false, nil
+	}
+	for _, f := range params.codeOrderFields() {
+		if f.Which() != schema.Field_Which_slot {
+			return nil, false, nil
+		}
+		t, _ := f.Slot().Type()
+		if t.Which() == schema.Type_Which_void {
+			continue
+		}
+		typ, ok, err := g.argsType(t, n)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		af := argsField{Name: strings.Title(f.Name), Type: typ}
+		if name, _ := f.Field.Name(); af.Name != strings.Title(name) {
+			af.Tag = name
+		}
+		fields = append(fields, af)
+	}
+	return fields, true, nil
+}
+
+// argsType returns the Go type that pogs fills in a field of type t
+// from, or ok == false if there is none that is a plain Go value.
+func (g *generator) argsType(t schema.Type, rel *node) (typ string, ok bool, err error) {
+	switch t.Which() {
+	case schema.Type_Which_bool:
+		return "bool", true, nil
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		return fmt.Sprintf("int%d", intbits(t.Which())), true, nil
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		return fmt.Sprintf("uint%d", intbits(t.Which())), true, nil
+	case schema.Type_Which_float32:
+		return "float32", true, nil
+	case schema.Type_Which_float64:
+		return "float64", true, nil
+	case schema.Type_Which_text:
+		return "string", true, nil
+	case schema.Type_Which_data:
+		return "[]byte", true, nil
+	case schema.Type_Which_enum, schema.Type_Which_interface:
+		typ, err := g.RemoteTypeName(t, rel)
+		return typ, err == nil, err
+	case schema.Type_Which_list:
+		elem, _ := t.List().ElementType()
+		switch elem.Which() {
+		case schema.Type_Which_list, schema.Type_Which_interface, schema.Type_Which_void:
+			return "", false, nil
+		}
+		typ, ok, err := g.argsType(elem, rel)
+		return "[]" + typ, ok, err
+	default:
+		return "", false, nil
+	}
+}
+
+// defineInterfaceHTTP generates the HTTP routes of the methods in m
+// that are annotated with $Go.http.
+func (g *generator) defineInterfaceHTTP(n *node, m []interfaceMethod) error {
+	var routes []interfaceHTTPRoute
+	for _, im := range m {
+		mann, _ := im.Method.Annotations()
+		rule := parseAnnotations(mann).HTTP
+		if rule == "" {
+			continue
+		}
+		verb, path, err := httpgw.ParseRule(rule)
+		if err != nil {
+			return fmt.Errorf("method %s: %v", im.OriginalName, err)
+		}
+		routes = append(routes, interfaceHTTPRoute{
+			HTTPMethod: verb,
+			Path:       path,
+			Method:     im,
+		})
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+	return g.r.Render(interfaceHTTPParams{
+		G:      g,
+		Node:   n,
+		Routes: routes,
+	})
+}
+
+// interfaceFingerprint hashes the nodes of interface n, its superclasses
+// and the parameter and result structs of its method set m.  Display
+// names are left out, as they depend on the path the schema was
+// compiled from.
+func interfaceFingerprint(n *node, m []interfaceMethod) (uint64, error) {
+	h := sha256.New()
+	seen := make(map[uint64]bool)
+	add := func(n *node) error {
+		if seen[n.Id()] {
+			return nil
+		}
+		seen[n.Id()] = true
+		msg, _ := capnp.NewSingleSegmentMessage(nil)
+		if err := msg.SetRoot(capnp.Struct(n.Node).ToPtr()); err != nil {
+			return err
+		}
+		root, err := msg.Root()
+		if err != nil {
+			return err
+		}
+		cp := schema.Node(root.Struct())
+		if err := cp.SetDisplayName(""); err != nil {
+			return err
+		}
+		cp.SetDisplayNamePrefixLength(0)
+		b, err := capnp.Canonicalize(capnp.Struct(cp))
+		if err != nil {
+			return err
+		}
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(b)))
+		h.Write(size[:])
+		h.Write(b)
+		return nil
+	}
+	if err := add(n); err != nil {
+		return 0, err
+	}
+	for _, im := range m {
+		for _, n := range []*node{im.Interface, im.Params, im.Results} {
+			if err := add(n); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil)), nil
+}
+
 type enumString []string
 
 func (es enumString) ValueString() string {
@@ -1192,10 +1791,81 @@ func (g *generator) defineFile() error {
 	return nil
 }
 
+// generateTestVectors produces a Go test file that checks the file's
+// $Go.testVector constants, or nil if there are none.
+func (g *generator) generateTestVectors() ([]byte, error) {
+	var vectors []testVector
+	for _, n := range g.nodes[g.fileID].nodes {
+		if n.Which() != schema.Node_Which_const {
+			continue
+		}
+		nann, _ := n.Annotations()
+		if !parseAnnotations(nann).TestVector {
+			continue
+		}
+		t, _ := n.Const().Type()
+		if t.Which() != schema.Type_Which_structType {
+			return nil, fmt.Errorf("%v: $Go.testVector is only supported on struct constants", n)
+		}
+		val, _ := n.Const().Value()
+		p, err := val.StructValue()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", n, err)
+		}
+		b, err := capnp.Canonicalize(p.Struct())
+		if err != nil {
+			return nil, fmt.Errorf("%v: canonicalize: %v", n, err)
+		}
+		vectors = append(vectors, testVector{
+			Node:      n,
+			Name:      n.shortDisplayName(),
+			Canonical: b,
+		})
+	}
+	if len(vectors) == 0 {
+		return nil, nil
+	}
+	base, err := g.Basename()
+	if err != nil {
+		return nil, err
+	}
+	r := &templateRenderer{t: templates}
+	err = r.Render(testVectorsParams{
+		G:        g,
+		Package:  g.nodes[g.fileID].pkg,
+		FuncName: "TestVectors_" + identifierFor(base),
+		Vectors:  vectors,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("test vectors: %v", err)
+	}
+	return r.Bytes(), nil
+}
+
+// identifierFor returns the part of a schema file name before the first
+// dot, with characters that are not valid in a Go identifier replaced.
+func identifierFor(name string) string {
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
 func generateFile(reqf schema.CodeGeneratorRequest_RequestedFile, trees nodeTrees, opts genoptions) error {
 	if opts.structStrings && !opts.schemas {
 		return errors.New("cannot generate struct String() methods without embedding schemas")
 	}
+	if opts.http && !opts.schemas {
+		return errors.New("cannot generate HTTP handlers without embedding schemas")
+	}
+	if opts.paramStructs > 0 && !opts.schemas {
+		return errors.New("cannot generate parameter structs without embedding schemas")
+	}
 	id := reqf.Id()
 	fname, _ := reqf.Filename()
 	g := newGenerator(id, trees, opts)
@@ -1231,14 +1901,37 @@ func generateFile(reqf schema.CodeGeneratorRequest_RequestedFile, trees nodeTree
 	if cerr != nil {
 		return err
 	}
+	if opts.testVectors {
+		return writeTestVectors(g, fname+"_test.go")
+	}
 	return nil
 }
 
+// writeTestVectors writes the test for the file's $Go.testVector
+// constants to path, if there are any.
+func writeTestVectors(g *generator, path string) error {
+	src, err := g.generateTestVectors()
+	if err != nil || src == nil {
+		return err
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, formatted, 0666)
+}
+
 func main() {
 	var opts genoptions
 	flag.BoolVar(&opts.promises, "promises", true, "generate code for promises")
 	flag.BoolVar(&opts.schemas, "schemas", true, "embed schema information in generated code")
 	flag.BoolVar(&opts.structStrings, "structstrings", true, "generate String() methods for structs (-schemas must be true)")
+	importMapPath := flag.String("importmap", "", "path to a file that maps schema files (by ID or path) to Go import paths and package names, overriding $Go.import and $Go.package")
+	flag.BoolVar(&opts.views, "views", false, "generate plain Go view structs with a FastRead method for the data fields of each struct")
+	flag.BoolVar(&opts.http, "http", false, "generate net/http handlers that serve the interface methods annotated with $Go.http as JSON (-schemas must be true)")
+	flag.IntVar(&opts.paramStructs, "paramstructs", 0, "generate a Go struct for the parameters of each interface method with at least this many parameters, and a MethodWith method on clients that takes it; 0 disables (-schemas must be true)")
+	flag.BoolVar(&opts.sync, "sync", false, "generate a blocking MethodSync method on clients for each non-streaming interface method, which returns a copy of the results")
+	flag.BoolVar(&opts.testVectors, "testvectors", false, "generate a Go test that checks the canonical encoding of each struct constant annotated with $Go.testVector")
 	flag.BoolVar(&opts.forceSchemasAlways, "forceschemasalways", false, "(temporary, will be removed) force RegisterSchema() code in every generated .go file even if it is in the same package as another go file. Perhaps useful if the code generation erroneously omits a RegisterSchemas()")
 	flag.Parse()
 
@@ -1252,7 +1945,15 @@ func main() {
 		fmt.Fprintln(os.Stderr, "capnpc-go: reading input:", err)
 		os.Exit(1)
 	}
-	trees, err := makeNodeTrees(req)
+	var imap *importMap
+	if *importMapPath != "" {
+		imap, err = readImportMapFile(*importMapPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "capnpc-go: reading import map:", err)
+			os.Exit(1)
+		}
+	}
+	trees, err := makeMappedNodeTrees(req, imap)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "capnpc-go:", err)
 		os.Exit(1)
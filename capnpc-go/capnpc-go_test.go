@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/parser"
 	"go/token"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -243,6 +244,77 @@ func TestRemoteScope(t *testing.T) {
 	}
 }
 
+func TestParseImportMap(t *testing.T) {
+	const input = `
+# comment
+0xd68755941d99d05e example.com/gen/scopes
+@83c2b5818e83ab19 example.com/gen/group grp
+vendor/other.capnp example.com/gen/other-pkg
+`
+	m, err := parseImportMap(strings.NewReader(input))
+	if err != nil {
+		t.Fatal("parseImportMap:", err)
+	}
+	want := map[uint64]importSpec{
+		0xd68755941d99d05e: {path: "example.com/gen/scopes", name: "scopes"},
+		0x83c2b5818e83ab19: {path: "example.com/gen/group", name: "grp"},
+	}
+	for id, spec := range want {
+		if got := m.byID[id]; got != spec {
+			t.Errorf("byID[%#x] = %v; want %v", id, got, spec)
+		}
+	}
+	if got, want := m.byName["vendor/other.capnp"], (importSpec{path: "example.com/gen/other-pkg", name: "otherpkg"}); got != want {
+		t.Errorf("byName[\"vendor/other.capnp\"] = %v; want %v", got, want)
+	}
+
+	bad := []string{
+		"foo.capnp",
+		"foo.capnp a b c",
+		"0xzz example.com/a",
+		"foo.capnp example.com/a\nfoo.capnp example.com/b",
+	}
+	for _, input := range bad {
+		if _, err := parseImportMap(strings.NewReader(input)); err == nil {
+			t.Errorf("parseImportMap(%q) = _, <nil>; want error", input)
+		}
+	}
+}
+
+func TestImportMapOverridesAnnotations(t *testing.T) {
+	req := mustReadGeneratorRequest(t, "scopes.capnp.out")
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	other := trees.nodes[0x836faf1834d91729].Const() // scopes.otherFooVar
+	typ, _ := other.Type()
+	otherFile := trees.nodes[trees.nodes[typ.StructType().TypeId()].ScopeId()]
+	dn, _ := otherFile.DisplayName()
+
+	m, err := parseImportMap(strings.NewReader(dn + " example.com/vendored/other vendored"))
+	if err != nil {
+		t.Fatal("parseImportMap:", err)
+	}
+	trees, err = makeMappedNodeTrees(req, m)
+	if err != nil {
+		t.Fatal("makeMappedNodeTrees:", err)
+	}
+	g := newGenerator(0xd68755941d99d05e, trees, genoptions{})
+	from := trees.nodes[0x836faf1834d91729]
+	rn, err := g.RemoteTypeName(typ, from)
+	if err != nil {
+		t.Fatal("RemoteTypeName:", err)
+	}
+	if rn != "vendored.Foo" {
+		t.Errorf("RemoteTypeName = %q; want \"vendored.Foo\"", rn)
+	}
+	want := []importSpec{{name: "vendored", path: "example.com/vendored/other"}}
+	if !hasExactImports(want, g.imports) {
+		t.Errorf("imports = %s; want %s", formatImportSpecs(g.imports.usedImports()), formatImportSpecs(want))
+	}
+}
+
 func hasExactImports(specs []importSpec, imp imports) bool {
 	used := imp.usedImports()
 	if len(used) != len(specs) {
@@ -299,6 +371,62 @@ func TestDefineConstNodes(t *testing.T) {
 	}
 }
 
+func TestInterfaceFingerprint(t *testing.T) {
+	// Copy the request, so that it can be modified.
+	msg, _, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.SetRoot(mustReadGeneratorRequest(t, "aircraft.capnp.out").ToPtr()); err != nil {
+		t.Fatal(err)
+	}
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	const echoID uint64 = 0x8e5322c1e9282534
+	n := trees.nodes[echoID]
+	if n == nil {
+		t.Fatalf("missing Echo node @%#x", echoID)
+	}
+	fingerprint := func() uint64 {
+		t.Helper()
+		m, err := methodSet(nil, n, trees.nodes)
+		if err != nil {
+			t.Fatal("methodSet:", err)
+		}
+		fp, err := interfaceFingerprint(n, m)
+		if err != nil {
+			t.Fatal("interfaceFingerprint:", err)
+		}
+		return fp
+	}
+
+	want := fingerprint()
+	if err := n.SetDisplayName("elsewhere/aircraft.capnp:Echo"); err != nil {
+		t.Fatal(err)
+	}
+	n.SetDisplayNamePrefixLength(uint32(len("elsewhere/aircraft.capnp:")))
+	if got := fingerprint(); got != want {
+		t.Errorf("fingerprint after moving schema = %#x; want %#x", got, want)
+	}
+
+	methods, err := n.Interface().Methods()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := methods.At(0).SetName("shout"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fingerprint(); got == want {
+		t.Errorf("fingerprint after renaming method = %#x; want a different value", got)
+	}
+}
+
 func TestDefineFile(t *testing.T) {
 	// Sanity check to make sure codegen produces parseable Go.
 
@@ -339,7 +467,26 @@ func TestDefineFile(t *testing.T) {
 			schemas:       true,
 			structStrings: true,
 		}},
+		{"aircraft.capnp.out", genoptions{
+			promises:      true,
+			schemas:       true,
+			structStrings: true,
+			views:         true,
+		}},
+		{"aircraft.capnp.out", genoptions{
+			promises:      true,
+			schemas:       true,
+			structStrings: true,
+			sync:          true,
+		}},
+		{"aircraft.capnp.out", genoptions{
+			promises:      true,
+			schemas:       true,
+			structStrings: true,
+			paramStructs:  1,
+		}},
 		{"group.capnp.out", defaultOptions},
+		{"group.capnp.out", genoptions{views: true}},
 		{"rpc.capnp.out", defaultOptions},
 		{"scopes.capnp.out", defaultOptions},
 		{"util.capnp.out", defaultOptions},
@@ -603,7 +750,7 @@ func TestPersistent(t *testing.T) {
 			}
 			src := g.generate()
 			genfpath := filepath.Join(dir, genfname)
-			err = os.WriteFile(genfpath, []byte(src), 0660)
+			err = os.WriteFile(genfpath, src, 0660)
 			if err != nil {
 				t.Fatalf("Writing generated code %q: %v", genfpath, err)
 				return
@@ -643,3 +790,528 @@ func TestPersistent(t *testing.T) {
 		}
 	}
 }
+
+// viewsTestSource is compiled together with aircraft.capnp.out generated
+// with -views to check that FastRead agrees with the accessors.
+const viewsTestSource = `package aircraftlib
+
+import (
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+func TestFastRead(t *testing.T) {
+	_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+	z, err := NewZdate(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z.SetYear(2024)
+	z.SetMonth(2)
+	z.SetDay(29)
+	var zv Zdate_View
+	zv.FastRead(z)
+	if zv.Year != z.Year() || zv.Month != z.Month() || zv.Day != z.Day() {
+		t.Errorf("Zdate view = %+v; want {Year:%d Month:%d Day:%d}", zv, z.Year(), z.Month(), z.Day())
+	}
+
+	d, err := NewDefaults(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dv Defaults_View
+	dv.FastRead(d)
+	if dv.Float != d.Float() || dv.Int != d.Int() || dv.Uint != d.Uint() {
+		t.Errorf("zero Defaults view = %+v; want {Float:%v Int:%d Uint:%d}", dv, d.Float(), d.Int(), d.Uint())
+	}
+	d.SetInt(7)
+	dv.FastRead(d)
+	if dv.Int != 7 || dv.Uint != 42 {
+		t.Errorf("Defaults view = %+v; want Int:7 Uint:42", dv)
+	}
+
+	// A truncated data section reads as defaults.
+	small, err := capnp.NewStruct(seg, capnp.ObjectSize{DataSize: 0, PointerCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dv.FastRead(Defaults(small))
+	if dv.Float != 3.14 || dv.Int != -123 || dv.Uint != 42 {
+		t.Errorf("truncated Defaults view = %+v; want {Float:3.14 Int:-123 Uint:42}", dv)
+	}
+}
+`
+
+func TestViews(t *testing.T) {
+	t.Parallel()
+	dir, err := setupTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	req := mustReadGeneratorRequest(t, "aircraft.capnp.out")
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	reqFiles, err := req.RequestedFiles()
+	if err != nil {
+		t.Fatal("RequestedFiles:", err)
+	}
+	g := newGenerator(reqFiles.At(0).Id(), trees, genoptions{
+		promises:      true,
+		schemas:       true,
+		structStrings: true,
+		views:         true,
+	})
+	if err := g.defineFile(); err != nil {
+		t.Fatal("defineFile:", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp.go"), []byte(g.generate()), 0660); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "views_test.go"), []byte(viewsTestSource), 0660); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "aircraft.capnp.go", "views_test.go")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go test: %v\n%s", err, out)
+	}
+}
+
+func TestTestVectors(t *testing.T) {
+	t.Parallel()
+	dir, err := setupTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Copy the request into a writable message, then annotate constDate
+	// with $Go.testVector.
+	ro := mustReadGeneratorRequest(t, "aircraft.capnp.out")
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	req, err := schema.NewRootCodeGeneratorRequest(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := capnp.Struct(req).CopyFrom(capnp.Struct(ro)); err != nil {
+		t.Fatal("CopyFrom:", err)
+	}
+	nodes, err := req.Nodes()
+	if err != nil {
+		t.Fatal("Nodes:", err)
+	}
+	found := false
+	for i := 0; i < nodes.Len(); i++ {
+		n := nodes.At(i)
+		if dn, _ := n.DisplayName(); !strings.HasSuffix(dn, ":constDate") {
+			continue
+		}
+		anns, err := n.NewAnnotations(1)
+		if err != nil {
+			t.Fatal("NewAnnotations:", err)
+		}
+		anns.At(0).SetId(0xfc8894fd77b086d5)
+		val, err := anns.At(0).NewValue()
+		if err != nil {
+			t.Fatal("NewValue:", err)
+		}
+		val.SetVoid()
+		found = true
+	}
+	if !found {
+		t.Fatal("constDate not found in aircraft.capnp.out")
+	}
+
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	reqFiles, err := req.RequestedFiles()
+	if err != nil {
+		t.Fatal("RequestedFiles:", err)
+	}
+	g := newGenerator(reqFiles.At(0).Id(), trees, genoptions{
+		promises:      true,
+		schemas:       true,
+		structStrings: true,
+		testVectors:   true,
+	})
+	if err := g.defineFile(); err != nil {
+		t.Fatal("defineFile:", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp.go"), []byte(g.generate()), 0660); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTestVectors(g, filepath.Join(dir, "aircraft.capnp_test.go")); err != nil {
+		t.Fatal("writeTestVectors:", err)
+	}
+	cmd := exec.Command("go", "test", "-v", "-run", "TestVectors_aircraft", "aircraft.capnp.go", "aircraft.capnp_test.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+	if !bytes.Contains(out, []byte("--- PASS: TestVectors_aircraft")) {
+		t.Errorf("go test did not run TestVectors_aircraft:\n%s", out)
+	}
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	t.Parallel()
+	dir, err := setupTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Copy the request into a writable message, then annotate
+	// Echo.echo with $Go.http.
+	ro := mustReadGeneratorRequest(t, "aircraft.capnp.out")
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	req, err := schema.NewRootCodeGeneratorRequest(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := capnp.Struct(req).CopyFrom(capnp.Struct(ro)); err != nil {
+		t.Fatal("CopyFrom:", err)
+	}
+	nodes, err := req.Nodes()
+	if err != nil {
+		t.Fatal("Nodes:", err)
+	}
+	found := false
+	for i := 0; i < nodes.Len(); i++ {
+		n := nodes.At(i)
+		if dn, _ := n.DisplayName(); !strings.HasSuffix(dn, ":Echo") {
+			continue
+		}
+		methods, err := n.Interface().Methods()
+		if err != nil {
+			t.Fatal("Methods:", err)
+		}
+		anns, err := methods.At(0).NewAnnotations(1)
+		if err != nil {
+			t.Fatal("NewAnnotations:", err)
+		}
+		anns.At(0).SetId(0x826c92a22f743ec1)
+		val, err := anns.At(0).NewValue()
+		if err != nil {
+			t.Fatal("NewValue:", err)
+		}
+		if err := val.SetText("post /echo"); err != nil {
+			t.Fatal("SetText:", err)
+		}
+		found = true
+	}
+	if !found {
+		t.Fatal("Echo not found in aircraft.capnp.out")
+	}
+
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	reqFiles, err := req.RequestedFiles()
+	if err != nil {
+		t.Fatal("RequestedFiles:", err)
+	}
+	g := newGenerator(reqFiles.At(0).Id(), trees, genoptions{
+		promises:      true,
+		schemas:       true,
+		structStrings: true,
+		http:          true,
+	})
+	if err := g.defineFile(); err != nil {
+		t.Fatal("defineFile:", err)
+	}
+	src := g.generate()
+	if bytes.Contains(src, []byte("CallSequence_HTTPRoutes")) {
+		t.Error("generated routes for an interface without $Go.http methods")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp.go"), src, 0660); err != nil {
+		t.Fatal(err)
+	}
+	const echoTest = `package aircraftlib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, call Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + "!")
+}
+
+func TestEchoHTTP(t *testing.T) {
+	RegisterSchema(schemas.DefaultRegistry)
+	c := Echo_ServerToClient(echoServer{})
+	defer c.Release()
+	mux := http.NewServeMux()
+	Echo_RegisterHTTP(mux, c)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/echo", strings.NewReader(` + "`" + `{"in": "hi"}` + "`" + `)))
+	if w.Code != http.StatusOK || w.Body.String() != ` + "`" + `{"out": "hi!"}` + "`" + ` {
+		t.Errorf("POST /echo = %d %s", w.Code, w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/echo", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("GET /echo = %d, Allow: %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp_test.go"), []byte(echoTest), 0660); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "-v", "-run", "TestEchoHTTP", "aircraft.capnp.go", "aircraft.capnp_test.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+	if !bytes.Contains(out, []byte("--- PASS: TestEchoHTTP")) {
+		t.Errorf("go test did not run TestEchoHTTP:\n%s", out)
+	}
+}
+
+func TestSyncMethods(t *testing.T) {
+	t.Parallel()
+	dir, err := setupTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	req := mustReadGeneratorRequest(t, "aircraft.capnp.out")
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	reqFiles, err := req.RequestedFiles()
+	if err != nil {
+		t.Fatal("RequestedFiles:", err)
+	}
+	g := newGenerator(reqFiles.At(0).Id(), trees, genoptions{
+		promises:      true,
+		schemas:       true,
+		structStrings: true,
+		sync:          true,
+	})
+	if err := g.defineFile(); err != nil {
+		t.Fatal("defineFile:", err)
+	}
+	src := g.generate()
+	if !bytes.Contains(src, []byte("func (c CallSequence) GetNumberSync(")) {
+		t.Error("no sync method generated for CallSequence.getNumber")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp.go"), src, 0660); err != nil {
+		t.Fatal(err)
+	}
+	const echoTest = `package aircraftlib
+
+import (
+	"context"
+	"testing"
+)
+
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, call Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + "!")
+}
+
+func TestEchoSync(t *testing.T) {
+	c := Echo_ServerToClient(echoServer{})
+	defer c.Release()
+	res, err := c.EchoSync(context.Background(), func(p Echo_echo_Params) error {
+		return p.SetIn("hi")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Message().Release()
+	if out, err := res.Out(); err != nil || out != "hi!" {
+		t.Errorf("EchoSync = %q, %v; want \"hi!\"", out, err)
+	}
+
+	c.Release()
+	if _, err := c.EchoSync(context.Background(), nil); err == nil {
+		t.Error("EchoSync on released client succeeded")
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp_test.go"), []byte(echoTest), 0660); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "-v", "-run", "TestEchoSync", "aircraft.capnp.go", "aircraft.capnp_test.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+	if !bytes.Contains(out, []byte("--- PASS: TestEchoSync")) {
+		t.Errorf("go test did not run TestEchoSync:\n%s", out)
+	}
+}
+
+func TestParamStructs(t *testing.T) {
+	t.Parallel()
+	dir, err := setupTempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	req := mustReadGeneratorRequest(t, "aircraft.capnp.out")
+	trees, err := makeNodeTrees(req)
+	if err != nil {
+		t.Fatal("buildNodeMap:", err)
+	}
+	reqFiles, err := req.RequestedFiles()
+	if err != nil {
+		t.Fatal("RequestedFiles:", err)
+	}
+	g := newGenerator(reqFiles.At(0).Id(), trees, genoptions{
+		promises:      true,
+		schemas:       true,
+		structStrings: true,
+		paramStructs:  1,
+	})
+	if err := g.defineFile(); err != nil {
+		t.Fatal("defineFile:", err)
+	}
+	src := g.generate()
+	if !bytes.Contains(src, []byte("type Echo_echo_Args struct")) {
+		t.Error("no args struct generated for Echo.echo")
+	}
+	if bytes.Contains(src, []byte("GetNumberWith(")) {
+		t.Error("args method generated for CallSequence.getNumber, which has no parameters")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp.go"), src, 0660); err != nil {
+		t.Fatal(err)
+	}
+	const echoTest = `package aircraftlib
+
+import (
+	"context"
+	"testing"
+
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, call Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + "!")
+}
+
+func TestEchoWith(t *testing.T) {
+	RegisterSchema(schemas.DefaultRegistry)
+	c := Echo_ServerToClient(echoServer{})
+	defer c.Release()
+	f, release := c.EchoWith(context.Background(), &Echo_echo_Args{In: "hi"})
+	defer release()
+	res, err := f.Struct()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := res.Out(); err != nil || out != "hi!" {
+		t.Errorf("EchoWith = %q, %v; want \"hi!\"", out, err)
+	}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "aircraft.capnp_test.go"), []byte(echoTest), 0660); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", "-v", "-run", "TestEchoWith", "aircraft.capnp.go", "aircraft.capnp_test.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go test: %v\n%s", err, out)
+	}
+	if !bytes.Contains(out, []byte("--- PASS: TestEchoWith")) {
+		t.Errorf("go test did not run TestEchoWith:\n%s", out)
+	}
+}
+
+func TestNumberBound(t *testing.T) {
+	tests := []struct {
+		name    string
+		which   schema.Type_Which
+		b       float64
+		isMin   bool
+		value   string
+		literal string
+		ok      bool
+		err     bool
+	}{
+		{name: "uint8 min", which: schema.Type_Which_uint8, b: 1, isMin: true, value: "v", literal: "1", ok: true},
+		{name: "fractional min", which: schema.Type_Which_int32, b: 1.5, isMin: true, value: "v", literal: "2", ok: true},
+		{name: "fractional max", which: schema.Type_Which_int32, b: -1.5, value: "v", literal: "-2", ok: true},
+		{name: "int64 above 2^53", which: schema.Type_Which_int64, b: 1 << 60, value: "v", literal: "1152921504606846976", ok: true},
+		{name: "uint64 above 2^53", which: schema.Type_Which_uint64, b: 1 << 63, isMin: true, value: "v", literal: "9223372036854775808", ok: true},
+		{name: "min at type minimum", which: schema.Type_Which_int8, b: -128, isMin: true},
+		{name: "max at type maximum", which: schema.Type_Which_uint64, b: 1 << 64},
+		{name: "min above type maximum", which: schema.Type_Which_uint8, b: 256, isMin: true, err: true},
+		{name: "max below type minimum", which: schema.Type_Which_uint16, b: -1, err: true},
+		{name: "float32", which: schema.Type_Which_float32, b: 0.5, isMin: true, value: "float64(v)", literal: "0.5", ok: true},
+		{name: "float64", which: schema.Type_Which_float64, b: 1e100, value: "v", literal: "1e+100", ok: true},
+		{name: "negative infinity min", which: schema.Type_Which_float64, b: math.Inf(-1), isMin: true},
+		{name: "positive infinity min", which: schema.Type_Which_int16, b: math.Inf(1), isMin: true, err: true},
+		{name: "NaN", which: schema.Type_Which_float64, b: math.NaN(), err: true},
+	}
+	for _, test := range tests {
+		bound, ok, err := numberBound(test.which, test.b, test.isMin)
+		if err != nil {
+			if !test.err {
+				t.Errorf("%s: numberBound: %v", test.name, err)
+			}
+			continue
+		}
+		if test.err {
+			t.Errorf("%s: numberBound did not return an error", test.name)
+			continue
+		}
+		if ok != test.ok || bound.value != test.value || bound.literal != test.literal {
+			t.Errorf("%s: numberBound = %+v, %t; want {value:%s literal:%s}, %t", test.name, bound, ok, test.value, test.literal, test.ok)
+		}
+	}
+}
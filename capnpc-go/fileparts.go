@@ -19,10 +19,15 @@ var (
 		{path: serverImport, name: "server"},
 		{path: textImport, name: "text"},
 		{path: flowcontrolImport, name: "fc"},
+		{path: httpgwImport, name: "httpgw"},
 
 		// stdlib imports
 		{path: "context", name: "context"},
+		{path: "encoding/binary", name: "binary"},
+		{path: "errors", name: "errors"},
 		{path: "math", name: "math"},
+		{path: "net/http", name: "http"},
+		{path: "regexp", name: "regexp"},
 		{path: "strconv", name: "strconv"},
 	}
 )
@@ -102,14 +107,38 @@ func (i *imports) FlowControl() string {
 	return i.add(importSpec{path: flowcontrolImport, name: "fc"})
 }
 
+func (i *imports) HTTPGateway() string {
+	return i.add(importSpec{path: httpgwImport, name: "httpgw"})
+}
+
+func (i *imports) Pogs() string {
+	return i.add(importSpec{path: pogsImport, name: "pogs"})
+}
+
 func (i *imports) Context() string {
 	return i.add(importSpec{path: "context", name: "context"})
 }
 
+func (i *imports) Binary() string {
+	return i.add(importSpec{path: "encoding/binary", name: "binary"})
+}
+
+func (i *imports) Errors() string {
+	return i.add(importSpec{path: "errors", name: "errors"})
+}
+
 func (i *imports) Math() string {
 	return i.add(importSpec{path: "math", name: "math"})
 }
 
+func (i *imports) HTTP() string {
+	return i.add(importSpec{path: "net/http", name: "http"})
+}
+
+func (i *imports) Regexp() string {
+	return i.add(importSpec{path: "regexp", name: "regexp"})
+}
+
 func (i *imports) Strconv() string {
 	return i.add(importSpec{path: "strconv", name: "strconv"})
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// importMap overrides the $Go.package and $Go.import annotations of
+// schema files.  Keys are either file IDs or file display names, which
+// are the paths that the capnp tool was given for each file.
+type importMap struct {
+	byID   map[uint64]importSpec
+	byName map[string]importSpec
+}
+
+// readImportMapFile parses the import map file at path.
+func readImportMapFile(path string) (*importMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := parseImportMap(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return m, nil
+}
+
+// parseImportMap parses an import map.  Each non-empty line that does
+// not start with '#' has the form:
+//
+//	<file ID or path> <Go import path> [<Go package name>]
+//
+// If the package name is omitted, it is derived from the last element
+// of the import path.
+func parseImportMap(r io.Reader) (*importMap, error) {
+	m := &importMap{
+		byID:   make(map[uint64]importSpec),
+		byName: make(map[string]importSpec),
+	}
+	s := bufio.NewScanner(r)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("line %d: want 2 or 3 fields, got %d", lineno, len(parts))
+		}
+		spec := importSpec{path: parts[1]}
+		if len(parts) == 3 {
+			spec.name = parts[2]
+		} else {
+			spec.name = pkgFromImport(spec.path)
+		}
+		if strings.HasPrefix(parts[0], "@") || strings.HasPrefix(parts[0], "0x") {
+			id, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(parts[0], "@"), "0x"), 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: file ID %q: %v", lineno, parts[0], err)
+			}
+			if _, dup := m.byID[id]; dup {
+				return nil, fmt.Errorf("line %d: duplicate entry for @%#x", lineno, id)
+			}
+			m.byID[id] = spec
+			continue
+		}
+		if _, dup := m.byName[parts[0]]; dup {
+			return nil, fmt.Errorf("line %d: duplicate entry for %s", lineno, parts[0])
+		}
+		m.byName[parts[0]] = spec
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// lookup returns the mapping for the file node f, if any.
+func (m *importMap) lookup(f *node) (importSpec, bool) {
+	if m == nil {
+		return importSpec{}, false
+	}
+	if spec, ok := m.byID[f.Id()]; ok {
+		return spec, true
+	}
+	dn, _ := f.DisplayName()
+	spec, ok := m.byName[dn]
+	return spec, ok
+}
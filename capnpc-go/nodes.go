@@ -39,6 +39,9 @@ func (n *node) codeOrderFields() []field {
 		if renamed == fname {	// Avoid collisions if no annotation
 			if _, ok := renameIdents[strings.Title(fname)]; ok {
 				renamed = fname + "_"
+			} else if strings.Title(fname) == "Visit" && n.StructNode().DiscriminantCount() > 0 {
+				// Structs with a union have a generated Visit method.
+				renamed = fname + "_"
 			}
 
 		}
@@ -175,6 +178,24 @@ type annotations struct {
 	TagType   int
 	CustomTag string
 	Name      string
+
+	// Field validation constraints.
+	Min     *float64
+	Max     *float64
+	MaxLen  *uint32
+	Pattern *string
+
+	// TestVector marks a struct constant as a wire-compatibility test
+	// vector.
+	TestVector bool
+
+	// HTTP is the HTTP method and path that a method is served at.
+	HTTP string
+}
+
+// HasConstraints reports whether any field validation constraint is set.
+func (ann *annotations) HasConstraints() bool {
+	return ann.Min != nil || ann.Max != nil || ann.MaxLen != nil || ann.Pattern != nil
 }
 
 func parseAnnotations(list capnp.StructList[schema.Annotation]) *annotations {
@@ -196,6 +217,22 @@ func parseAnnotations(list capnp.StructList[schema.Annotation]) *annotations {
 			ann.TagType = noTag
 		case 0xc2b96012172f8df1: // $name
 			ann.Name, _ = val.Text()
+		case 0xeef9cfe81ddeca5e: // $min
+			v := val.Float64()
+			ann.Min = &v
+		case 0xe1f93203db42ac8b: // $max
+			v := val.Float64()
+			ann.Max = &v
+		case 0x8baa1a3595b98165: // $maxLen
+			v := val.Uint32()
+			ann.MaxLen = &v
+		case 0xcffe29b68470b6e0: // $pattern
+			v, _ := val.Text()
+			ann.Pattern = &v
+		case 0xfc8894fd77b086d5: // $testVector
+			ann.TestVector = true
+		case 0x826c92a22f743ec1: // $http
+			ann.HTTP, _ = val.Text()
 		}
 	}
 	return ann
@@ -250,6 +287,12 @@ type nodeTrees struct {
 }
 
 func makeNodeTrees(req schema.CodeGeneratorRequest) (nodeTrees, error) {
+	return makeMappedNodeTrees(req, nil)
+}
+
+// makeMappedNodeTrees is like makeNodeTrees, but the package and import
+// of any file listed in m take precedence over its annotations.
+func makeMappedNodeTrees(req schema.CodeGeneratorRequest, m *importMap) (nodeTrees, error) {
 	ret := nodeTrees{}
 	rnodes, err := req.Nodes()
 	if err != nil {
@@ -274,6 +317,10 @@ func makeNodeTrees(req schema.CodeGeneratorRequest) (nodeTrees, error) {
 		ann := parseAnnotations(fann)
 		f.pkg = ann.Package
 		f.imp = ann.Import
+		if spec, ok := m.lookup(f); ok {
+			f.pkg = spec.name
+			f.imp = spec.path
+		}
 		nnodes, _ := f.NestedNodes()
 		for i := 0; i < nnodes.Len(); i++ {
 			nn := nnodes.At(i)
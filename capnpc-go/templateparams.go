@@ -16,6 +16,36 @@ type constantsParams struct {
 	Vars   []*node
 }
 
+type testVectorsParams struct {
+	G        *generator
+	Package  string
+	FuncName string
+	Vectors  []testVector
+}
+
+// A testVector is a $Go.testVector constant, along with its canonical
+// encoding.
+type testVector struct {
+	Node      *node
+	Name      string // name of the constant in the schema
+	Canonical []byte
+}
+
+// CanonicalLiteral returns a Go byte slice literal of the vector's
+// canonical encoding.
+func (v testVector) CanonicalLiteral() string {
+	var out bytes.Buffer
+	out.WriteString("[]byte{")
+	for i, b := range v.Canonical {
+		if i%8 == 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "%d,", b)
+	}
+	out.WriteString("\n}")
+	return out.String()
+}
+
 type enumParams struct {
 	G           *generator
 	Node        *node
@@ -45,6 +75,56 @@ type structFuncsParams struct {
 	Node *node
 }
 
+// UnionFields returns the fields of the node's union in code order.
+func (p structFuncsParams) UnionFields() []field {
+	var fields []field
+	for _, f := range p.Node.codeOrderFields() {
+		if f.HasDiscriminant() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+type structViewParams struct {
+	G        *generator
+	Node     *node
+	Fields   []viewField
+	DataSize int
+}
+
+// viewField is a field of a generated view struct.  Expr is a Go
+// expression that decodes the field from the data section bytes d.
+type viewField struct {
+	Name string
+	Type string
+	Doc  string
+	Expr string
+}
+
+type structValidateParams struct {
+	G      *generator
+	Node   *node
+	Checks []validateCheck
+}
+
+// validateCheck describes the constraints that Validate enforces on a
+// single field.  Kind is one of "number", "text", "data", "list" or
+// "group".  Each of Conds is a Go boolean expression over the field
+// value v that reports a violation.
+type validateCheck struct {
+	Field      field
+	Kind       string
+	Conds      []validateCond
+	Pattern    string
+	PatternVar string
+}
+
+type validateCond struct {
+	Expr string
+	Msg  string
+}
+
 type structGroupParams struct {
 	G     *generator
 	Node  *node
@@ -183,6 +263,50 @@ type interfaceClientParams struct {
 	Node        *node
 	Annotations *annotations
 	Methods     []interfaceMethod
+	Fingerprint uint64
+}
+
+type interfaceMethodInfoParams struct {
+	G       *generator
+	Node    *node
+	Methods []interfaceMethod
+}
+
+type interfaceSyncParams struct {
+	G       *generator
+	Node    *node
+	Methods []interfaceMethod
+}
+
+type interfaceArgsParams struct {
+	G       *generator
+	Node    *node
+	Methods []interfaceArgsMethod
+}
+
+type interfaceArgsMethod struct {
+	interfaceMethod
+	TypeName string
+	Fields   []argsField
+}
+
+type argsField struct {
+	Name string
+	Type string
+	Tag  string // schema name, if pogs can't derive it from Name
+}
+
+type interfaceHTTPParams struct {
+	G      *generator
+	Node   *node
+	Routes []interfaceHTTPRoute
+}
+
+// interfaceHTTPRoute is a method annotated with $Go.http.
+type interfaceHTTPRoute struct {
+	HTTPMethod string
+	Path       string
+	Method     interfaceMethod
 }
 
 type interfaceServerParams struct {
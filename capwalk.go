@@ -0,0 +1,173 @@
+package capnp
+
+import (
+	"strconv"
+	"strings"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A PtrPath locates a pointer reachable from another pointer, the
+// root.  Each step follows a pointer field of a struct or an element
+// of a list, starting from the root.  The empty path is the root
+// itself.
+type PtrPath []PathStep
+
+// A PathStep is a step in a PtrPath.
+type PathStep struct {
+	// Elem is true if the step follows the Index'th element of a
+	// list and false if it follows the Index'th pointer field of a
+	// struct.  An element of a list of structs is a struct, so it is
+	// followed by another step that picks one of its fields.
+	Elem  bool
+	Index int
+}
+
+// String returns the path in the format "root.p1[3].p0", where p1 is
+// the struct pointer field with index 1 and [3] is the list element
+// with index 3.
+func (path PtrPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("root")
+	for _, step := range path {
+		if step.Elem {
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(step.Index))
+			sb.WriteByte(']')
+		} else {
+			sb.WriteString(".p")
+			sb.WriteString(strconv.Itoa(step.Index))
+		}
+	}
+	return sb.String()
+}
+
+// WalkCaps calls f for each interface pointer reachable from p, along
+// with its path from p, in depth-first order.  It stops at and returns
+// the first error returned by f.  The walk is subject to the message's
+// traversal and depth limits, like any other read.
+//
+// WalkCaps works on any object, without its schema, so that packages
+// such as proxies and membranes can find the capabilities in arbitrary
+// call parameters and results.
+func WalkCaps(p Ptr, f func(path PtrPath, i Interface) error) error {
+	w := capWalker{visit: func(path PtrPath, i Interface, _ func(Ptr) error) error {
+		return f(path, i)
+	}}
+	if err := w.walk(p, nil); err != nil {
+		return exc.WrapError("walk capabilities", err)
+	}
+	return nil
+}
+
+// RewriteCaps calls f for each interface pointer reachable from p, as
+// WalkCaps does, with the client that the pointer refers to, and
+// replaces the pointer with one to the client that f returns.  f must
+// not release c.  RewriteCaps adds the client that f returns to the
+// capability table of p's message, which takes ownership of it; c is
+// left in the table, so it is released along with the message.  If f
+// returns a null client, the pointer is left unchanged.
+//
+// RewriteCaps returns p, or the new interface pointer if p is itself
+// an interface pointer that was replaced.
+func RewriteCaps(p Ptr, f func(path PtrPath, c Client) (Client, error)) (Ptr, error) {
+	msg := p.Message()
+	root := p
+	w := capWalker{visit: func(path PtrPath, i Interface, set func(Ptr) error) error {
+		c, err := f(path, i.Client())
+		if err != nil || c == (Client{}) {
+			return err
+		}
+		id := msg.CapTable().Add(c)
+		repl := NewInterface(i.seg, id).ToPtr()
+		if set == nil {
+			root = repl
+			return nil
+		}
+		return set(repl)
+	}}
+	if err := w.walk(p, nil); err != nil {
+		return Ptr{}, exc.WrapError("rewrite capabilities", err)
+	}
+	return root, nil
+}
+
+// A capWalker finds the interface pointers in an object.  visit is
+// called with each one and with a function that replaces it, which is
+// nil for the root.
+type capWalker struct {
+	visit func(path PtrPath, i Interface, set func(Ptr) error) error
+}
+
+func (w capWalker) walk(p Ptr, path PtrPath) error {
+	if !p.IsValid() {
+		return nil
+	}
+	switch p.flags.ptrType() {
+	case structPtrType:
+		return w.walkStruct(p.Struct(), path)
+	case listPtrType:
+		return w.walkList(p.List(), path)
+	case interfacePtrType:
+		return w.visit(append(PtrPath(nil), path...), p.Interface(), nil)
+	default:
+		panic("unreachable")
+	}
+}
+
+func (w capWalker) walkStruct(s Struct, path PtrPath) error {
+	for i := uint16(0); i < s.size.PointerCount; i++ {
+		p, err := s.Ptr(i)
+		if err != nil {
+			return exc.WrapError(path.String()+": struct pointer "+str.Utod(i), err)
+		}
+		fieldPath := append(path, PathStep{Index: int(i)})
+		if p.flags.ptrType() == interfacePtrType && p.IsValid() {
+			i := i
+			err = w.visit(append(PtrPath(nil), fieldPath...), p.Interface(), func(repl Ptr) error {
+				return s.SetPtr(i, repl)
+			})
+		} else {
+			err = w.walk(p, fieldPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w capWalker) walkList(l List, path PtrPath) error {
+	if l.size.PointerCount == 0 {
+		return nil
+	}
+	if l.flags&isCompositeList != 0 {
+		for i := 0; i < l.Len(); i++ {
+			if err := w.walkStruct(l.Struct(i), append(path, PathStep{Elem: true, Index: i})); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pl := PointerList(l)
+	for i := 0; i < pl.Len(); i++ {
+		p, err := pl.At(i)
+		if err != nil {
+			return exc.WrapError(path.String()+": list element "+str.Itod(i), err)
+		}
+		elemPath := append(path, PathStep{Elem: true, Index: i})
+		if p.flags.ptrType() == interfacePtrType && p.IsValid() {
+			i := i
+			err = w.visit(append(PtrPath(nil), elemPath...), p.Interface(), func(repl Ptr) error {
+				return pl.Set(i, repl)
+			})
+		} else {
+			err = w.walk(p, elemPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package capnp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCapWalkMessage returns a root struct with an interface pointer in
+// field 0, a list of structs holding an interface pointer in field 1
+// and a pointer list holding an interface pointer in field 2.
+func newCapWalkMessage(t *testing.T) (Struct, []*dummyHook) {
+	_, seg := NewSingleSegmentMessage(nil)
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 3})
+	require.NoError(t, err)
+	root.SetUint64(0, 7)
+
+	hooks := []*dummyHook{{}, {}, {}}
+	ct := seg.Message().CapTable()
+	iface := func(h *dummyHook) Ptr {
+		return NewInterface(seg, ct.Add(NewClient(h))).ToPtr()
+	}
+
+	require.NoError(t, root.SetPtr(0, iface(hooks[0])))
+
+	structs, err := NewCompositeList(seg, ObjectSize{PointerCount: 2}, 2)
+	require.NoError(t, err)
+	text, err := NewText(seg, "not a capability")
+	require.NoError(t, err)
+	require.NoError(t, structs.Struct(0).SetPtr(0, text.ToPtr()))
+	require.NoError(t, structs.Struct(1).SetPtr(1, iface(hooks[1])))
+	require.NoError(t, root.SetPtr(1, structs.ToPtr()))
+
+	ptrs, err := NewPointerList(seg, 2)
+	require.NoError(t, err)
+	require.NoError(t, ptrs.Set(1, iface(hooks[2])))
+	require.NoError(t, root.SetPtr(2, ptrs.ToPtr()))
+
+	return root, hooks
+}
+
+func TestWalkCaps(t *testing.T) {
+	t.Parallel()
+
+	root, hooks := newCapWalkMessage(t)
+	defer root.Message().Release()
+
+	var paths []string
+	var caps []CapabilityID
+	err := WalkCaps(root.ToPtr(), func(path PtrPath, i Interface) error {
+		paths = append(paths, path.String())
+		caps = append(caps, i.Capability())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"root.p0", "root.p1[1].p1", "root.p2[1]"}, paths)
+	assert.Equal(t, []CapabilityID{0, 1, 2}, caps)
+	assert.Zero(t, hooks[0].shutdowns, "walking should not release clients")
+
+	stop := errors.New("stop")
+	n := 0
+	err = WalkCaps(root.ToPtr(), func(PtrPath, Interface) error {
+		n++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, n)
+
+	assert.NoError(t, WalkCaps(Ptr{}, func(PtrPath, Interface) error {
+		t.Error("called for null pointer")
+		return nil
+	}))
+}
+
+func TestRewriteCaps(t *testing.T) {
+	t.Parallel()
+
+	root, hooks := newCapWalkMessage(t)
+	msg := root.Message()
+	repl := &dummyHook{}
+	replClient := NewClient(repl)
+	defer replClient.Release()
+
+	p, err := RewriteCaps(root.ToPtr(), func(path PtrPath, c Client) (Client, error) {
+		if path.String() == "root.p1[1].p1" {
+			return Client{}, nil
+		}
+		return replClient.AddRef(), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, root.ToPtr(), p)
+	assert.Equal(t, uint64(7), root.Uint64(0), "data should be left alone")
+
+	got := map[string]Client{}
+	require.NoError(t, WalkCaps(root.ToPtr(), func(path PtrPath, i Interface) error {
+		got[path.String()] = i.Client()
+		return nil
+	}))
+	assert.True(t, got["root.p0"].IsSame(replClient))
+	assert.True(t, got["root.p2[1]"].IsSame(replClient))
+	assert.False(t, got["root.p1[1].p1"].IsSame(replClient), "pointer should be left unchanged")
+
+	msg.Release()
+	for i, h := range hooks {
+		assert.Equal(t, 1, h.shutdowns, "hook %d", i)
+	}
+
+	// A root interface pointer is returned rather than replaced.
+	_, seg := NewSingleSegmentMessage(nil)
+	defer seg.Message().Release()
+	iface := NewInterface(seg, seg.Message().CapTable().Add(ErrorClient(errors.New("old"))))
+	p, err = RewriteCaps(iface.ToPtr(), func(path PtrPath, c Client) (Client, error) {
+		assert.Empty(t, path)
+		return replClient.AddRef(), nil
+	})
+	require.NoError(t, err)
+	assert.True(t, p.Interface().Client().IsSame(replClient))
+}
@@ -24,20 +24,56 @@ type Decoder struct {
 	// Maximum number of bytes that can be read per call to Decode.
 	// If not set, a reasonable default is used.
 	MaxMessageSize uint64
+
+	maxSegments    int
+	maxSegmentSize uint64
+}
+
+// A DecoderOption sets a limit on the messages that a Decoder accepts,
+// so that a server exposed to untrusted peers can bound the memory that
+// each stream may make it allocate.
+type DecoderOption func(*Decoder)
+
+// MaxSegments limits the number of segments in a message.  If n is not
+// positive, the default of 513 segments is used.
+func MaxSegments(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxSegments = n
+	}
+}
+
+// MaxSegmentSize limits the size in bytes of each segment of a message.
+// If n is zero, segments are only limited by the size of the message.
+func MaxSegmentSize(n uint64) DecoderOption {
+	return func(d *Decoder) {
+		d.maxSegmentSize = n
+	}
+}
+
+// MaxTotalSize limits the size in bytes of a message, including its
+// framing header.  It sets the Decoder's MaxMessageSize.
+func MaxTotalSize(n uint64) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxMessageSize = n
+	}
 }
 
 // NewDecoder creates a new Cap'n Proto framer that reads from r.
 // The returned decoder will only read as much data as necessary to
 // decode the message.
-func NewDecoder(r io.Reader) *Decoder {
-	return &Decoder{r: r}
+func NewDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
+	d := &Decoder{r: r}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
 }
 
 // NewPackedDecoder creates a new Cap'n Proto framer that reads from a
 // packed stream r.  The returned decoder may read more data than
 // necessary from r.
-func NewPackedDecoder(r io.Reader) *Decoder {
-	return NewDecoder(packed.NewReader(bufio.NewReader(r)))
+func NewPackedDecoder(r io.Reader, opts ...DecoderOption) *Decoder {
+	return NewDecoder(packed.NewReader(bufio.NewReader(r)), opts...)
 }
 
 // Decode reads a message from the decoder stream.  The error is io.EOF
@@ -60,6 +96,16 @@ func (d *Decoder) Decode() (*Message, error) {
 		return nil, exc.WrapError("decode", err)
 	}
 
+	if d.maxSegmentSize > 0 {
+		for i := SegmentID(0); i <= hdr.maxSegment(); i++ {
+			// totalSize already checked that the sizes don't overflow.
+			sz, _ := hdr.segmentSize(i)
+			if uint64(sz) > d.maxSegmentSize {
+				return nil, errors.New("decode: segment " + str.Utod(i) + " too large")
+			}
+		}
+	}
+
 	// Special case an empty message to return a new MultiSegment message
 	// ready for writing. This maintains compatibility to tests and older
 	// implementation of message and arenas.
@@ -126,19 +172,26 @@ func (d *Decoder) readMaxSeg() (SegmentID, error) {
 	}
 
 	maxSeg := SegmentID(binary.LittleEndian.Uint32(d.wordbuf[:]))
-	if maxSeg > maxStreamSegments {
-		return 0, errSegIDTooLarge(maxSeg)
+	maxSegments := d.maxSegments
+	if maxSegments <= 0 {
+		maxSegments = maxStreamSegments + 1
+	}
+	if uint64(maxSeg) >= uint64(maxSegments) {
+		return 0, errSegIDTooLarge{id: maxSeg, max: maxSegments}
 	}
 
 	return maxSeg, nil
 }
 
-type errSegIDTooLarge SegmentID
+type errSegIDTooLarge struct {
+	id  SegmentID
+	max int
+}
 
 func (err errSegIDTooLarge) Error() string {
-	id := str.Utod(err)
-	max := str.Itod(maxStreamSegments)
-	return "decode: segment id" + id + "exceeds max segment count (max=" + max + ")"
+	id := str.Utod(err.id)
+	max := str.Itod(err.max)
+	return "decode: segment id " + id + " exceeds max segment count (max=" + max + ")"
 }
 
 func resizeSlice(b []byte, size int) []byte {
@@ -227,11 +280,12 @@ func NewEncoder(w io.Writer) *Encoder {
 // NewPackedEncoder creates a new Cap'n Proto framer that writes to a
 // packed stream w.
 func NewPackedEncoder(w io.Writer) *Encoder {
-	return NewEncoder(&packed.Writer{Writer: w})
+	return NewEncoder(packed.NewWriter(w))
 }
 
 // Encode writes a message to the encoder stream.
 func (e *Encoder) Encode(m *Message) error {
+	m.checkOwner("encode")
 	nsegs := m.NumSegments()
 	if nsegs == 0 {
 		return errors.New("encode: message has no segments")
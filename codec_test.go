@@ -6,7 +6,10 @@ import (
 	"io"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3/packed"
 )
 
 func TestEncoder(t *testing.T) {
@@ -189,6 +192,58 @@ func TestDecoder_MaxMessageSize(t *testing.T) {
 	}
 }
 
+func TestDecoder_Options(t *testing.T) {
+	t.Parallel()
+
+	// Three one-word segments, and one two-word segment.
+	threeSegs := []byte{
+		0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		1, 0, 0, 0, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+		3, 0, 0, 0, 0, 0, 0, 0,
+	}
+	twoWords := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00,
+		1, 0, 0, 0, 0, 0, 0, 0,
+		2, 0, 0, 0, 0, 0, 0, 0,
+	}
+	tests := []struct {
+		name string
+		data []byte
+		opts []DecoderOption
+		ok   bool
+	}{
+		{name: "segments default", data: threeSegs, ok: true},
+		{name: "segments at limit", data: threeSegs, opts: []DecoderOption{MaxSegments(3)}, ok: true},
+		{name: "too many segments", data: threeSegs, opts: []DecoderOption{MaxSegments(2)}},
+		{name: "segment size at limit", data: twoWords, opts: []DecoderOption{MaxSegmentSize(16)}, ok: true},
+		{name: "segment too large", data: twoWords, opts: []DecoderOption{MaxSegmentSize(15)}},
+		{name: "small segments", data: threeSegs, opts: []DecoderOption{MaxSegmentSize(8)}, ok: true},
+		{name: "total size at limit", data: twoWords, opts: []DecoderOption{MaxTotalSize(24)}, ok: true},
+		{name: "total size too large", data: twoWords, opts: []DecoderOption{MaxTotalSize(23)}},
+		{name: "last option wins", data: threeSegs, opts: []DecoderOption{MaxSegments(2), MaxSegments(0)}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, d := range []*Decoder{
+				NewDecoder(bytes.NewReader(test.data), test.opts...),
+				NewPackedDecoder(bytes.NewReader(packed.Pack(nil, test.data)), test.opts...),
+			} {
+				_, err := d.Decode()
+				if test.ok {
+					assert.NoError(t, err)
+				} else {
+					assert.Error(t, err)
+				}
+			}
+		})
+	}
+
+	_, err := NewDecoder(bytes.NewReader(threeSegs), MaxSegments(1)).Decode()
+	assert.EqualError(t, err, "decode: segment id 2 exceeds max segment count (max=1)")
+}
+
 // TestStreamHeaderPadding is a regression test for
 // stream header padding.
 //
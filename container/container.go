@@ -0,0 +1,213 @@
+// Package container stores many Cap'n Proto messages in a single file
+// with an index, so that any one of them can be read without scanning
+// the file.
+//
+// A container starts with an 8-byte magic number, followed by the
+// messages in the standard stream encoding, one after the other.  The
+// index follows the last message, and holds, for each message, its
+// offset and size in the file and an optional schema ID identifying the
+// type of its root struct.  The file ends with a trailer that holds the
+// offset of the index, the number of messages and the magic number
+// again.  All integers are little-endian 64-bit words:
+//
+//	magic
+//	message 0
+//	...
+//	message N-1
+//	index: N × (offset, size, schema ID)
+//	trailer: (index offset, N, magic)
+//
+// Since the messages are in the stream encoding, a container can be
+// read sequentially with a capnp.Decoder after skipping the magic
+// number, up to the index.
+package container
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// Magic is the magic number at the start and end of a container.
+const Magic = "capnpctr"
+
+const (
+	entrySize   = 24
+	trailerSize = 24
+)
+
+// An Entry describes a message in a container.
+type Entry struct {
+	// Offset and Size are the location of the message in the file.
+	Offset int64
+	Size   int64
+
+	// SchemaID is the ID of the message's root struct type, or zero if
+	// it was not recorded.
+	SchemaID uint64
+}
+
+// A Writer writes a container.  Messages are written as they are added,
+// and the index when the Writer is closed.
+type Writer struct {
+	w       io.Writer
+	off     int64
+	entries []Entry
+	err     error
+	closed  bool
+}
+
+// NewWriter returns a Writer that writes a container to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) write(b []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(b)
+	w.off += int64(n)
+	if err != nil {
+		w.err = exc.WrapError("container", err)
+	}
+}
+
+// Write appends msg to the container.  schemaID is stored in the index
+// and may be zero.  Write returns the index of the message.
+func (w *Writer) Write(msg *capnp.Message, schemaID uint64) (int, error) {
+	if w.closed {
+		return 0, errors.New("container: write to closed writer")
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+	b, err := msg.Marshal()
+	if err != nil {
+		return 0, exc.WrapError("container", err)
+	}
+	if w.off == 0 {
+		w.write([]byte(Magic))
+	}
+	e := Entry{Offset: w.off, Size: int64(len(b)), SchemaID: schemaID}
+	w.write(b)
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.entries = append(w.entries, e)
+	return len(w.entries) - 1, nil
+}
+
+// Len returns the number of messages written so far.
+func (w *Writer) Len() int {
+	return len(w.entries)
+}
+
+// Close writes the index and the trailer.  It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return errors.New("container: writer already closed")
+	}
+	w.closed = true
+	if w.off == 0 {
+		w.write([]byte(Magic))
+	}
+	indexOff := w.off
+	buf := make([]byte, len(w.entries)*entrySize+trailerSize)
+	b := buf
+	for _, e := range w.entries {
+		binary.LittleEndian.PutUint64(b, uint64(e.Offset))
+		binary.LittleEndian.PutUint64(b[8:], uint64(e.Size))
+		binary.LittleEndian.PutUint64(b[16:], e.SchemaID)
+		b = b[entrySize:]
+	}
+	binary.LittleEndian.PutUint64(b, uint64(indexOff))
+	binary.LittleEndian.PutUint64(b[8:], uint64(len(w.entries)))
+	copy(b[16:], Magic)
+	w.write(buf)
+	return w.err
+}
+
+// A Reader reads messages from a container in random order.  A Reader
+// is safe to use from multiple goroutines if its io.ReaderAt is.
+type Reader struct {
+	r       io.ReaderAt
+	entries []Entry
+}
+
+// NewReader reads the index of the container of the given size in r.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(len(Magic))+trailerSize {
+		return nil, errors.New("container: file too short")
+	}
+	var magic [len(Magic)]byte
+	if _, err := r.ReadAt(magic[:], 0); err != nil {
+		return nil, exc.WrapError("container: read magic", err)
+	}
+	if string(magic[:]) != Magic {
+		return nil, errors.New("container: bad magic number")
+	}
+	var trailer [trailerSize]byte
+	if _, err := r.ReadAt(trailer[:], size-trailerSize); err != nil {
+		return nil, exc.WrapError("container: read trailer", err)
+	}
+	if string(trailer[16:]) != Magic {
+		return nil, errors.New("container: bad trailer")
+	}
+	indexOff := binary.LittleEndian.Uint64(trailer[:])
+	n := binary.LittleEndian.Uint64(trailer[8:])
+	indexEnd := uint64(size - trailerSize)
+	if indexOff < uint64(len(Magic)) || indexOff > indexEnd || n != (indexEnd-indexOff)/entrySize || (indexEnd-indexOff)%entrySize != 0 {
+		return nil, errors.New("container: corrupt index location")
+	}
+
+	buf := make([]byte, indexEnd-indexOff)
+	if _, err := r.ReadAt(buf, int64(indexOff)); err != nil {
+		return nil, exc.WrapError("container: read index", err)
+	}
+	entries := make([]Entry, n)
+	for i := range entries {
+		b := buf[i*entrySize:]
+		off := binary.LittleEndian.Uint64(b)
+		sz := binary.LittleEndian.Uint64(b[8:])
+		if off < uint64(len(Magic)) || off > indexOff || sz > indexOff-off {
+			return nil, errors.New("container: entry " + str.Itod(i) + " out of bounds")
+		}
+		entries[i] = Entry{
+			Offset:   int64(off),
+			Size:     int64(sz),
+			SchemaID: binary.LittleEndian.Uint64(b[16:]),
+		}
+	}
+	return &Reader{r: r, entries: entries}, nil
+}
+
+// Len returns the number of messages in the container.
+func (r *Reader) Len() int {
+	return len(r.entries)
+}
+
+// Entry returns the index entry of the i'th message.  It panics if i is
+// out of range.
+func (r *Reader) Entry(i int) Entry {
+	return r.entries[i]
+}
+
+// Message reads the i'th message.  It panics if i is out of range.
+func (r *Reader) Message(i int) (*capnp.Message, error) {
+	e := r.entries[i]
+	buf := make([]byte, e.Size)
+	if _, err := r.r.ReadAt(buf, e.Offset); err != nil {
+		return nil, exc.WrapError("container: read message "+str.Itod(i), err)
+	}
+	msg, err := capnp.Unmarshal(buf)
+	if err != nil {
+		return nil, exc.WrapError("container: message "+str.Itod(i), err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,90 @@
+package container_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/container"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+)
+
+func newZdate(t *testing.T, year int16) *capnp.Message {
+	msg, seg := capnp.NewSingleSegmentMessage(nil)
+	d, err := air.NewRootZdate(seg)
+	require.NoError(t, err)
+	d.SetYear(year)
+	return msg
+}
+
+func TestContainer(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := container.NewWriter(&buf)
+	for i := 0; i < 100; i++ {
+		n, err := w.Write(newZdate(t, int16(2000+i)), air.Zdate_TypeID)
+		require.NoError(t, err)
+		assert.Equal(t, i, n)
+	}
+	require.NoError(t, w.Close())
+
+	r, err := container.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 100, r.Len())
+	for _, i := range []int{57, 0, 99} {
+		assert.Equal(t, uint64(air.Zdate_TypeID), r.Entry(i).SchemaID)
+		msg, err := r.Message(i)
+		require.NoError(t, err)
+		d, err := air.ReadRootZdate(msg)
+		require.NoError(t, err)
+		assert.Equal(t, int16(2000+i), d.Year())
+	}
+
+	// The messages can also be read in sequence.
+	start := int64(len(container.Magic))
+	end := r.Entry(99).Offset + r.Entry(99).Size
+	dec := capnp.NewDecoder(io.NewSectionReader(bytes.NewReader(buf.Bytes()), start, end-start))
+	for i := 0; i < 100; i++ {
+		msg, err := dec.Decode()
+		require.NoError(t, err)
+		d, err := air.ReadRootZdate(msg)
+		require.NoError(t, err)
+		assert.Equal(t, int16(2000+i), d.Year())
+	}
+	_, err = dec.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestContainerEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, container.NewWriter(&buf).Close())
+	r, err := container.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.Len())
+}
+
+func TestContainerCorrupt(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := container.NewWriter(&buf)
+	_, err := w.Write(newZdate(t, 2000), 0)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	b := buf.Bytes()
+	_, err = container.NewReader(bytes.NewReader(b[:len(b)-1]), int64(len(b)-1))
+	assert.Error(t, err)
+
+	bad := append([]byte(nil), b...)
+	bad[len(bad)-24] ^= 0xff // index offset
+	_, err = container.NewReader(bytes.NewReader(bad), int64(len(bad)))
+	assert.ErrorContains(t, err, "corrupt index location")
+}
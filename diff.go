@@ -0,0 +1,231 @@
+package capnp
+
+import (
+	"bytes"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A Difference describes the first place where two pointers differ.
+type Difference struct {
+	// Path leads from the pointers given to Diff to the values that
+	// differ.  It is made of ".ptr[i]" for the i'th pointer of a
+	// struct, "[i]" for the i'th element of a list and ".data[i]" for
+	// the i'th byte of a struct's data section.  It is empty if the
+	// pointers themselves differ.
+	Path string
+
+	// Reason describes how the values differ.
+	Reason string
+}
+
+func (d *Difference) String() string {
+	if d.Path == "" {
+		return d.Reason
+	}
+	return d.Path + ": " + d.Reason
+}
+
+// Diff returns the first difference between p1 and p2, or nil if they
+// are equal as defined by Equal.  Like Equal, Diff does not need a
+// schema: it compares the encoded objects, ignoring the differences in
+// layout that canonicalization removes, such as where objects are
+// placed and trailing zero fields.  Structs are compared data section
+// first, then pointers in order, and lists element by element.
+func Diff(p1, p2 Ptr) (*Difference, error) {
+	return diffPtr(nil, p1, p2)
+}
+
+func newDifference(path []byte, reason string) *Difference {
+	return &Difference{Path: string(path), Reason: reason}
+}
+
+func diffPtr(path []byte, p1, p2 Ptr) (*Difference, error) {
+	if !p1.IsValid() && !p2.IsValid() {
+		return nil, nil
+	}
+	if !p1.IsValid() {
+		return newDifference(path, "null vs non-null"), nil
+	}
+	if !p2.IsValid() {
+		return newDifference(path, "non-null vs null"), nil
+	}
+	pt := p1.flags.ptrType()
+	if pt2 := p2.flags.ptrType(); pt != pt2 {
+		return newDifference(path, ptrTypeName(pt)+" vs "+ptrTypeName(pt2)), nil
+	}
+	switch pt {
+	case structPtrType:
+		return diffStruct(path, p1.Struct(), p2.Struct())
+	case listPtrType:
+		return diffList(path, p1.List(), p2.List())
+	case interfacePtrType:
+		if !interfacesEqual(p1.Interface(), p2.Interface()) {
+			return newDifference(path, "different capabilities"), nil
+		}
+		return nil, nil
+	default:
+		panic("unreachable")
+	}
+}
+
+// diffStruct compares two structs.  Fields that are past the end of one
+// of the structs are compared as if they were zero.
+func diffStruct(path []byte, s1, s2 Struct) (*Difference, error) {
+	data1 := s1.seg.slice(s1.off, s1.size.DataSize)
+	data2 := s2.seg.slice(s2.off, s2.size.DataSize)
+	if d := diffData(path, data1, data2); d != nil {
+		return d, nil
+	}
+	n := s1.size.PointerCount
+	if n2 := s2.size.PointerCount; n2 > n {
+		n = n2
+	}
+	for i := uint16(0); i < n; i++ {
+		if !s1.HasPtr(i) && !s2.HasPtr(i) {
+			continue
+		}
+		ppath := append(append(path, ".ptr["...), str.Utod(i)+"]"...)
+		sp1, err := s1.Ptr(i)
+		if err != nil {
+			return nil, exc.WrapError(comparing(ppath), err)
+		}
+		sp2, err := s2.Ptr(i)
+		if err != nil {
+			return nil, exc.WrapError(comparing(ppath), err)
+		}
+		if d, err := diffPtr(ppath, sp1, sp2); d != nil || err != nil {
+			return d, err
+		}
+	}
+	return nil, nil
+}
+
+// diffData compares two data sections, the shorter of which is
+// extended with zeros.
+func diffData(path []byte, data1, data2 []byte) *Difference {
+	n := len(data1)
+	if len(data2) < n {
+		n = len(data2)
+	}
+	if bytes.Equal(data1[:n], data2[:n]) && isZeroFilled(data1[n:]) && isZeroFilled(data2[n:]) {
+		return nil
+	}
+	for i := 0; ; i++ {
+		var b1, b2 byte
+		if i < len(data1) {
+			b1 = data1[i]
+		}
+		if i < len(data2) {
+			b2 = data2[i]
+		}
+		if b1 != b2 {
+			path = append(append(path, ".data["...), str.Itod(i)+"]"...)
+			return newDifference(path, hexByte(b1)+" vs "+hexByte(b2))
+		}
+	}
+}
+
+func diffList(path []byte, l1, l2 List) (*Difference, error) {
+	if l1.Len() != l2.Len() {
+		return newDifference(path, "length "+str.Itod(l1.Len())+" vs "+str.Itod(l2.Len())), nil
+	}
+	bits1, bits2 := l1.flags&isBitList != 0, l2.flags&isBitList != 0
+	if bits1 || bits2 {
+		if !bits1 || !bits2 {
+			return newDifference(path, listKind(l1)+" vs "+listKind(l2)), nil
+		}
+		for i := 0; i < l1.Len(); i++ {
+			if b1, b2 := BitList(l1).At(i), BitList(l2).At(i); b1 != b2 {
+				path = append(append(path, '['), str.Itod(i)+"]"...)
+				return newDifference(path, strconv.FormatBool(b1)+" vs "+strconv.FormatBool(b2)), nil
+			}
+		}
+		return nil, nil
+	}
+	if l1.flags&isCompositeList == 0 && l2.flags&isCompositeList == 0 && l1.size != l2.size {
+		return newDifference(path, listKind(l1)+" vs "+listKind(l2)), nil
+	}
+	if l1.size.PointerCount == 0 && l2.size.PointerCount == 0 && l1.size.DataSize == l2.size.DataSize {
+		// Optimization: pure data lists can be compared bytewise.
+		sz, _ := l1.size.totalSize().times(l1.length) // both list bounds have been validated
+		if bytes.Equal(l1.seg.slice(l1.off, sz), l2.seg.slice(l2.off, sz)) {
+			return nil, nil
+		}
+	}
+	ptrList := ObjectSize{PointerCount: 1}
+	for i := 0; i < l1.Len(); i++ {
+		epath := append(append(path, '['), str.Itod(i)+"]"...)
+		if l1.flags&isCompositeList == 0 && l1.size == ptrList && l2.flags&isCompositeList == 0 {
+			// Compare pointer list elements as pointers, so that the
+			// path does not go through a struct.
+			p1, err := PointerList(l1).At(i)
+			if err != nil {
+				return nil, exc.WrapError(comparing(epath), err)
+			}
+			p2, err := PointerList(l2).At(i)
+			if err != nil {
+				return nil, exc.WrapError(comparing(epath), err)
+			}
+			if d, err := diffPtr(epath, p1, p2); d != nil || err != nil {
+				return d, err
+			}
+			continue
+		}
+		if d, err := diffStruct(epath, l1.Struct(i), l2.Struct(i)); d != nil || err != nil {
+			return d, err
+		}
+	}
+	return nil, nil
+}
+
+// interfacesEqual reports whether two interfaces are equal, as defined
+// by Equal.
+func interfacesEqual(i1, i2 Interface) bool {
+	if i1.Message() == i2.Message() {
+		if i1.Capability() == i2.Capability() {
+			return true
+		}
+
+		if !i1.Message().CapTable().Contains(i1) || !i1.Message().CapTable().Contains(i2) {
+			return false
+		}
+	}
+	return i1.Client().IsSame(i2.Client())
+}
+
+// comparing returns the prefix of errors that occur while comparing
+// the values at path.
+func comparing(path []byte) string {
+	if len(path) == 0 {
+		return "compare"
+	}
+	return "compare " + string(path)
+}
+
+func listKind(l List) string {
+	if l.flags&isBitList != 0 {
+		return "bit list"
+	}
+	return "list of " + l.size.String()
+}
+
+func ptrTypeName(pt int) string {
+	switch pt {
+	case structPtrType:
+		return "struct"
+	case listPtrType:
+		return "list"
+	case interfacePtrType:
+		return "interface"
+	default:
+		return "unknown pointer"
+	}
+}
+
+func hexByte(b byte) string {
+	const digits = "0123456789abcdef"
+	return string([]byte{'0', 'x', digits[b>>4], digits[b&0xf]})
+}
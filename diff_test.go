@@ -0,0 +1,89 @@
+package capnp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	_, seg := NewSingleSegmentMessage(nil)
+	newStruct := func(data uint32, sz ObjectSize, ptrs ...Ptr) Struct {
+		s, err := NewStruct(seg, sz)
+		require.NoError(t, err)
+		if sz.DataSize > 0 {
+			s.SetUint32(0, data)
+		}
+		for i, p := range ptrs {
+			require.NoError(t, s.SetPtr(uint16(i), p))
+		}
+		return s
+	}
+	newInt32List := func(vals ...int32) Int32List {
+		l, err := NewInt32List(seg, int32(len(vals)))
+		require.NoError(t, err)
+		for i, v := range vals {
+			l.Set(i, v)
+		}
+		return l
+	}
+	newBitList := func(vals ...bool) BitList {
+		l, err := NewBitList(seg, int32(len(vals)))
+		require.NoError(t, err)
+		for i, v := range vals {
+			l.Set(i, v)
+		}
+		return l
+	}
+	newPointerList := func(ptrs ...Ptr) PointerList {
+		l, err := NewPointerList(seg, int32(len(ptrs)))
+		require.NoError(t, err)
+		for i, p := range ptrs {
+			require.NoError(t, l.Set(i, p))
+		}
+		return l
+	}
+
+	empty := newStruct(0, ObjectSize{})
+	subA := newStruct(0x0cafefe0, ObjectSize{DataSize: 8})
+	subD := newStruct(0x12345678, ObjectSize{DataSize: 8})
+	structA := newStruct(0xdeadbeef, ObjectSize{DataSize: 16, PointerCount: 1}, subA.ToPtr())
+	structB := newStruct(0xdeadbeef, ObjectSize{DataSize: 8, PointerCount: 2}, subA.ToPtr(), empty.ToPtr())
+	structC := newStruct(0xfeed1234, ObjectSize{DataSize: 16, PointerCount: 1}, subA.ToPtr())
+	structD := newStruct(0xdeadbeef, ObjectSize{DataSize: 16, PointerCount: 1}, subD.ToPtr())
+	wide := newStruct(0xdeadbeef, ObjectSize{DataSize: 16, PointerCount: 1}, subA.ToPtr())
+	wide.SetUint8(10, 7)
+
+	tests := []struct {
+		name   string
+		p1, p2 Ptr
+		want   string
+	}{
+		{"Equal", structA.ToPtr(), newStruct(0xdeadbeef, ObjectSize{DataSize: 8, PointerCount: 1}, subA.ToPtr()).ToPtr(), ""},
+		{"Null", structA.ToPtr(), Ptr{}, "non-null vs null"},
+		{"PointerType", structA.ToPtr(), newInt32List().ToPtr(), "struct vs list"},
+		{"Data", structA.ToPtr(), structC.ToPtr(), ".data[0]: 0xef vs 0x34"},
+		{"TrailingData", structA.ToPtr(), wide.ToPtr(), ".data[10]: 0x00 vs 0x07"},
+		{"NestedData", structA.ToPtr(), structD.ToPtr(), ".ptr[0].data[0]: 0xe0 vs 0x78"},
+		{"ExtraPointer", structA.ToPtr(), structB.ToPtr(), ".ptr[1]: null vs non-null"},
+		{"ListLength", newInt32List(1, 2, 3).ToPtr(), newInt32List(1, 2).ToPtr(), "length 3 vs 2"},
+		{"ListElement", newInt32List(1, 2, 3).ToPtr(), newInt32List(1, 5, 3).ToPtr(), "[1].data[0]: 0x02 vs 0x05"},
+		{"BitList", newBitList(true, true).ToPtr(), newBitList(true, false).ToPtr(), "[1]: true vs false"},
+		{"BitListKind", newBitList(true).ToPtr(), newInt32List(1).ToPtr(), "bit list vs list of {datasz=4 ptrs=0}"},
+		{"PointerList", newPointerList(structA.ToPtr()).ToPtr(), newPointerList(structB.ToPtr()).ToPtr(), "[0].ptr[1]: null vs non-null"},
+	}
+	for _, test := range tests {
+		d, err := Diff(test.p1, test.p2)
+		require.NoError(t, err, test.name)
+		if test.want == "" {
+			assert.Nil(t, d, test.name)
+			continue
+		}
+		if assert.NotNil(t, d, test.name) {
+			assert.Equal(t, test.want, d.String(), test.name)
+		}
+	}
+}
@@ -37,6 +37,15 @@ that godoc will pick it up. For example:
 	  day   @2   :UInt8 ;
 	}
 
+Fields can declare validation constraints with the min, max, maxLen and
+pattern annotations.  capnpc-go compiles them into a Validate method on
+the generated struct type.  For example:
+
+	struct Event {
+	  name  @0 :Text $Go.maxLen(16) $Go.pattern("^[a-z]+$");
+	  month @1 :UInt8 $Go.min(1) $Go.max(12);
+	}
+
 Messages and Segments
 
 In Cap'n Proto, the unit of communication is a message. A message
@@ -359,6 +368,10 @@ capnpc-go also generates code to implement the interface:
 	// If s does not implement the Close method, then nil is used.
 	func Calculator_ServerToClient(s Calculator_Server) Calculator
 
+	// Calculator_ServerToClientWithOptions is like Calculator_ServerToClient,
+	// but configures the server with opts instead of server.DefaultOptions.
+	func Calculator_ServerToClientWithOptions(s Calculator_Server, opts *server.Options) Calculator
+
 	// Calculator_Methods appends methods from Calculator that call to server and
 	// returns the methods.  If methods is nil or the capacity of the underlying
 	// slice is too small, a new slice is returned.
@@ -381,5 +394,9 @@ implemented Calculator:
 A note about message ordering: by default, only one method per server will
 be invoked at a time; when implementing a server method which blocks or takes
 a long time, you calling the server.Go function to unblock future calls.
+Alternatively, server.Options.MaxConcurrentCalls lets a server run several
+calls at once, and server.Options.Actor guarantees one call at a time
+even when such options are set by default.  server.SetDefaultOptions applies options, such as
+interceptors and an error mapper, to every server in a process.
 */
 package capnp // import "capnproto.org/go/capnp/v3"
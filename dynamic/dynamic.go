@@ -0,0 +1,148 @@
+// Package dynamic calls Cap'n Proto methods by name, using schemas
+// looked up at runtime instead of generated code.
+//
+// Arguments and results use the text format of package
+// capnproto.org/go/capnp/v3/encoding/text, which also accepts JSON for
+// arguments.  This is the core of a "capnp call" style developer tool
+// that can be embedded in any binary that registers its schemas:
+//
+//	res, err := dynamic.Call(ctx, client, foo.Foo_TypeID, "bar", `(baz = 42)`)
+package dynamic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// A Caller calls methods using the schemas in a registry.  The zero
+// value uses schemas.DefaultRegistry.
+type Caller struct {
+	// Registry holds the schemas of the called interface, its
+	// superclasses, and the types of their parameters and results.
+	// If nil, schemas.DefaultRegistry is used.
+	Registry *schemas.Registry
+}
+
+// Call calls the method named method on c, which must implement the
+// interface with the given ID, or one that extends it.  Methods
+// inherited from superclasses of the interface can be called too.
+// params is the text or JSON representation of the method's parameter
+// struct; an empty string passes the default parameters.  Call returns
+// the text representation of the results.
+func Call(ctx context.Context, c capnp.Client, iface uint64, method, params string) (string, error) {
+	var caller Caller
+	return caller.Call(ctx, c, iface, method, params)
+}
+
+// Call is like the package-level Call, but uses the schemas in
+// caller.Registry.
+func (caller Caller) Call(ctx context.Context, c capnp.Client, iface uint64, method, params string) (string, error) {
+	var nodes nodemap.Map
+	if caller.Registry != nil {
+		nodes.UseRegistry(caller.Registry)
+	}
+	m, paramsID, resultsID, err := findMethod(&nodes, iface, method)
+	if err != nil {
+		return "", err
+	}
+	argsSize, err := structSize(&nodes, paramsID)
+	if err != nil {
+		return "", err
+	}
+
+	s := capnp.Send{
+		Method:   m,
+		ArgsSize: argsSize,
+		PlaceArgs: func(args capnp.Struct) error {
+			if params == "" {
+				return nil
+			}
+			dec := text.NewDecoder(strings.NewReader(params))
+			if caller.Registry != nil {
+				dec.UseRegistry(caller.Registry)
+			}
+			return dec.Decode(paramsID, args)
+		},
+	}
+	ans, release := c.SendCall(ctx, s)
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	enc := text.NewEncoder(&buf)
+	if caller.Registry != nil {
+		enc.UseRegistry(caller.Registry)
+	}
+	if err := enc.Encode(resultsID, res); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// findMethod looks up the method named name in the interface with the
+// given ID or, failing that, in its superclasses.
+func findMethod(nodes *nodemap.Map, iface uint64, name string) (m capnp.Method, paramsID, resultsID uint64, err error) {
+	n, err := nodes.Find(iface)
+	if err != nil {
+		return capnp.Method{}, 0, 0, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_interface {
+		return capnp.Method{}, 0, 0, errors.New("dynamic: cannot find interface " + str.UToHex(iface))
+	}
+	methods, err := n.Interface().Methods()
+	if err != nil {
+		return capnp.Method{}, 0, 0, err
+	}
+	for i := 0; i < methods.Len(); i++ {
+		meth := methods.At(i)
+		if mname, _ := meth.Name(); mname != name {
+			continue
+		}
+		dn, _ := n.DisplayName()
+		m = capnp.Method{
+			InterfaceID:   iface,
+			MethodID:      uint16(i),
+			InterfaceName: dn,
+			MethodName:    name,
+		}
+		return m, meth.ParamStructType(), meth.ResultStructType(), nil
+	}
+	supers, err := n.Interface().Superclasses()
+	if err != nil {
+		return capnp.Method{}, 0, 0, err
+	}
+	for i := 0; i < supers.Len(); i++ {
+		m, paramsID, resultsID, err = findMethod(nodes, supers.At(i).Id(), name)
+		if err == nil {
+			return m, paramsID, resultsID, nil
+		}
+	}
+	dn, _ := n.DisplayName()
+	return capnp.Method{}, 0, 0, errors.New("dynamic: no method " + name + " in " + dn)
+}
+
+func structSize(nodes *nodemap.Map, id uint64) (capnp.ObjectSize, error) {
+	n, err := nodes.Find(id)
+	if err != nil {
+		return capnp.ObjectSize{}, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_structNode {
+		return capnp.ObjectSize{}, errors.New("dynamic: cannot find struct type " + str.UToHex(id))
+	}
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructNode().DataWordCount()) * 8,
+		PointerCount: n.StructNode().PointerCount(),
+	}, nil
+}
@@ -0,0 +1,86 @@
+package dynamic_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/dynamic"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+type echoImpl struct{}
+
+func (echoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + in)
+}
+
+type pipelinerImpl struct{}
+
+func (pipelinerImpl) GetNumber(ctx context.Context, call air.CallSequence_getNumber) error {
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	res.SetN(42)
+	return nil
+}
+
+func (pipelinerImpl) NewPipeliner(ctx context.Context, call air.Pipeliner_newPipeliner) error {
+	return nil
+}
+
+func TestCall(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	reg := new(schemas.Registry)
+	air.RegisterSchema(reg)
+	caller := dynamic.Caller{Registry: reg}
+
+	echo := capnp.Client(air.Echo_ServerToClient(echoImpl{}))
+	defer echo.Release()
+
+	t.Run("Text", func(t *testing.T) {
+		res, err := caller.Call(ctx, echo, air.Echo_TypeID, "echo", `(in = "foo")`)
+		require.NoError(t, err)
+		assert.Equal(t, `(out = "foofoo")`, res)
+	})
+	t.Run("JSON", func(t *testing.T) {
+		res, err := caller.Call(ctx, echo, air.Echo_TypeID, "echo", `{"in": "bar"}`)
+		require.NoError(t, err)
+		assert.Equal(t, `(out = "barbar")`, res)
+	})
+	t.Run("NoParams", func(t *testing.T) {
+		res, err := caller.Call(ctx, echo, air.Echo_TypeID, "echo", "")
+		require.NoError(t, err)
+		assert.Equal(t, `(out = "")`, res)
+	})
+	t.Run("Inherited", func(t *testing.T) {
+		p := capnp.Client(air.Pipeliner_ServerToClient(pipelinerImpl{}))
+		defer p.Release()
+		res, err := caller.Call(ctx, p, air.Pipeliner_TypeID, "getNumber", "")
+		require.NoError(t, err)
+		assert.Equal(t, `(n = 42)`, res)
+	})
+	t.Run("UnknownMethod", func(t *testing.T) {
+		_, err := caller.Call(ctx, echo, air.Echo_TypeID, "nope", "")
+		assert.ErrorContains(t, err, "no method nope")
+	})
+	t.Run("BadParams", func(t *testing.T) {
+		_, err := caller.Call(ctx, echo, air.Echo_TypeID, "echo", `(out = "x")`)
+		assert.ErrorContains(t, err, "unknown field out")
+	})
+}
@@ -0,0 +1,353 @@
+package dynamic
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// A Struct reads the fields of a struct by name, using its schema.
+// Values are read from the message as they are requested, so a Struct
+// can be handed to code that only needs a few fields of a large message,
+// such as a template, without converting the whole message first.
+//
+// A Struct and the values obtained from it share a cache of schemas, and
+// must not be used by multiple goroutines at once.
+type Struct struct {
+	s     capnp.Struct
+	node  schema.Node
+	nodes *nodemap.Map
+	reg   *schemas.Registry
+}
+
+// NewStruct returns a Struct that reads s, a struct of the given type.
+// Its schema is looked up in reg or, if reg is nil, in
+// schemas.DefaultRegistry.
+func NewStruct(reg *schemas.Registry, typeID uint64, s capnp.Struct) (Struct, error) {
+	nodes := new(nodemap.Map)
+	if reg != nil {
+		nodes.UseRegistry(reg)
+	}
+	return newStruct(nodes, reg, typeID, s)
+}
+
+func newStruct(nodes *nodemap.Map, reg *schemas.Registry, typeID uint64, s capnp.Struct) (Struct, error) {
+	n, err := nodes.Find(typeID)
+	if err != nil {
+		return Struct{}, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_structNode {
+		return Struct{}, errors.New("dynamic: cannot find struct type " + str.UToHex(typeID))
+	}
+	return Struct{s: s, node: n, nodes: nodes, reg: reg}, nil
+}
+
+// TypeID returns the ID of the struct's type.
+func (s Struct) TypeID() uint64 {
+	return s.node.Id()
+}
+
+// Struct returns the underlying struct.
+func (s Struct) Struct() capnp.Struct {
+	return s.s
+}
+
+// Which returns the name of the active member of the struct's unnamed
+// union, or "" if it has none.
+func (s Struct) Which() (string, error) {
+	if s.node.StructNode().DiscriminantCount() == 0 {
+		return "", nil
+	}
+	fields, err := s.node.StructNode().Fields()
+	if err != nil {
+		return "", err
+	}
+	discriminant := s.discriminant()
+	for i := 0; i < fields.Len(); i++ {
+		if f := fields.At(i); f.DiscriminantValue() == discriminant {
+			return f.Name()
+		}
+	}
+	return "", errors.New("dynamic: unknown union member " + str.Utod(discriminant))
+}
+
+func (s Struct) discriminant() uint16 {
+	return s.s.Uint16(capnp.DataOffset(s.node.StructNode().DiscriminantOffset() * 2))
+}
+
+// Fields returns the names of the struct's fields in code order,
+// leaving out the members of its union that are not active.
+func (s Struct) Fields() ([]string, error) {
+	list, err := s.node.StructNode().Fields()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]schema.Field, list.Len())
+	for i := range fields {
+		f := list.At(i)
+		fields[f.CodeOrder()] = f
+	}
+	var discriminant uint16
+	if s.node.StructNode().DiscriminantCount() > 0 {
+		discriminant = s.discriminant()
+	}
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if dv := f.DiscriminantValue(); dv != schema.Field_noDiscriminant && dv != discriminant {
+			continue
+		}
+		name, err := f.Name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// Has reports whether the struct has a field with the given name that
+// is not an inactive member of a union.
+func (s Struct) Has(name string) bool {
+	f, ok := s.field(name)
+	if !ok {
+		return false
+	}
+	dv := f.DiscriminantValue()
+	return dv == schema.Field_noDiscriminant || dv == s.discriminant()
+}
+
+func (s Struct) field(name string) (schema.Field, bool) {
+	fields, err := s.node.StructNode().Fields()
+	if err != nil {
+		return schema.Field{}, false
+	}
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if fname, _ := f.Name(); fname == name {
+			return f, true
+		}
+	}
+	return schema.Field{}, false
+}
+
+// Get returns the value of the field with the given name.  It returns
+// nil for members of a union that are not active, and an error if the
+// struct has no such field.
+//
+// Values have the Go type that corresponds to the field's type: bool,
+// int8 through uint64, float32, float64, string for Text, []byte for
+// Data, Struct for structs and groups, and List for lists.  Enumerants
+// are returned as their names, or as decimal numbers if they are not
+// in the schema.  Void fields are nil, and capabilities and AnyPointer
+// fields are returned as their capnp.Ptr.
+func (s Struct) Get(name string) (any, error) {
+	f, ok := s.field(name)
+	if !ok {
+		dn, _ := s.node.DisplayName()
+		return nil, errors.New("dynamic: no field " + name + " in " + dn)
+	}
+	if dv := f.DiscriminantValue(); dv != schema.Field_noDiscriminant && dv != s.discriminant() {
+		return nil, nil
+	}
+	switch f.Which() {
+	case schema.Field_Which_slot:
+		return s.slot(f)
+	case schema.Field_Which_group:
+		return newStruct(s.nodes, s.reg, f.Group().TypeId(), s.s)
+	default:
+		return nil, errors.New("dynamic: unknown field kind " + f.Which().String())
+	}
+}
+
+func (s Struct) slot(f schema.Field) (any, error) {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return nil, err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return nil, err
+	}
+	off := f.Slot().Offset()
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		return nil, nil
+	case schema.Type_Which_bool:
+		return s.s.Bit(capnp.BitOffset(off)) != dv.Bool(), nil
+	case schema.Type_Which_int8:
+		return int8(s.s.Uint8(capnp.DataOffset(off)) ^ uint8(dv.Int8())), nil
+	case schema.Type_Which_int16:
+		return int16(s.s.Uint16(capnp.DataOffset(off*2)) ^ uint16(dv.Int16())), nil
+	case schema.Type_Which_int32:
+		return int32(s.s.Uint32(capnp.DataOffset(off*4)) ^ uint32(dv.Int32())), nil
+	case schema.Type_Which_int64:
+		return int64(s.s.Uint64(capnp.DataOffset(off*8)) ^ uint64(dv.Int64())), nil
+	case schema.Type_Which_uint8:
+		return s.s.Uint8(capnp.DataOffset(off)) ^ dv.Uint8(), nil
+	case schema.Type_Which_uint16:
+		return s.s.Uint16(capnp.DataOffset(off*2)) ^ dv.Uint16(), nil
+	case schema.Type_Which_uint32:
+		return s.s.Uint32(capnp.DataOffset(off*4)) ^ dv.Uint32(), nil
+	case schema.Type_Which_uint64:
+		return s.s.Uint64(capnp.DataOffset(off*8)) ^ dv.Uint64(), nil
+	case schema.Type_Which_float32:
+		return math.Float32frombits(s.s.Uint32(capnp.DataOffset(off*4)) ^ math.Float32bits(dv.Float32())), nil
+	case schema.Type_Which_float64:
+		return math.Float64frombits(s.s.Uint64(capnp.DataOffset(off*8)) ^ math.Float64bits(dv.Float64())), nil
+	case schema.Type_Which_enum:
+		return s.enumName(typ.Enum().TypeId(), s.s.Uint16(capnp.DataOffset(off*2))^dv.Enum())
+	}
+
+	p, err := s.s.Ptr(uint16(off))
+	if err != nil {
+		return nil, err
+	}
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		if !p.IsValid() {
+			return dv.Text()
+		}
+		return p.Text(), nil
+	case schema.Type_Which_data:
+		if !p.IsValid() {
+			return dv.Data()
+		}
+		return p.Data(), nil
+	case schema.Type_Which_structType:
+		if !p.IsValid() {
+			p, _ = dv.StructValue()
+		}
+		return newStruct(s.nodes, s.reg, typ.StructType().TypeId(), p.Struct())
+	case schema.Type_Which_list:
+		if !p.IsValid() {
+			p, _ = dv.List()
+		}
+		elem, err := typ.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		return List{l: p.List(), elem: elem, parent: s}, nil
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		return p, nil
+	default:
+		return nil, errors.New("dynamic: unknown field type " + typ.Which().String())
+	}
+}
+
+func (s Struct) enumName(typeID uint64, val uint16) (string, error) {
+	n, err := s.nodes.Find(typeID)
+	if err != nil {
+		return "", err
+	}
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return "", err
+	}
+	if int(val) >= enums.Len() {
+		return str.Utod(val), nil
+	}
+	return enums.At(int(val)).Name()
+}
+
+// String returns the text representation of the struct, as written by
+// package capnproto.org/go/capnp/v3/encoding/text.
+func (s Struct) String() string {
+	var sb strings.Builder
+	enc := text.NewEncoder(&sb)
+	if s.reg != nil {
+		enc.UseRegistry(s.reg)
+	}
+	if err := enc.Encode(s.node.Id(), s.s); err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return sb.String()
+}
+
+// A List reads the elements of a list, using its schema.
+type List struct {
+	l      capnp.List
+	elem   schema.Type
+	parent Struct
+}
+
+// Len returns the number of elements in the list.
+func (l List) Len() int {
+	return l.l.Len()
+}
+
+// At returns the i'th element of the list, with the same Go type that
+// Struct.Get would return for a field of the list's element type.
+func (l List) At(i int) (any, error) {
+	if i < 0 || i >= l.l.Len() {
+		return nil, errors.New("dynamic: list index " + str.Itod(i) + " out of range")
+	}
+	s := l.parent
+	switch l.elem.Which() {
+	case schema.Type_Which_void:
+		return nil, nil
+	case schema.Type_Which_bool:
+		return capnp.BitList(l.l).At(i), nil
+	case schema.Type_Which_int8:
+		return capnp.Int8List(l.l).At(i), nil
+	case schema.Type_Which_int16:
+		return capnp.Int16List(l.l).At(i), nil
+	case schema.Type_Which_int32:
+		return capnp.Int32List(l.l).At(i), nil
+	case schema.Type_Which_int64:
+		return capnp.Int64List(l.l).At(i), nil
+	case schema.Type_Which_uint8:
+		return capnp.UInt8List(l.l).At(i), nil
+	case schema.Type_Which_uint16:
+		return capnp.UInt16List(l.l).At(i), nil
+	case schema.Type_Which_uint32:
+		return capnp.UInt32List(l.l).At(i), nil
+	case schema.Type_Which_uint64:
+		return capnp.UInt64List(l.l).At(i), nil
+	case schema.Type_Which_float32:
+		return capnp.Float32List(l.l).At(i), nil
+	case schema.Type_Which_float64:
+		return capnp.Float64List(l.l).At(i), nil
+	case schema.Type_Which_enum:
+		return s.enumName(l.elem.Enum().TypeId(), capnp.UInt16List(l.l).At(i))
+	case schema.Type_Which_text:
+		return capnp.TextList(l.l).At(i)
+	case schema.Type_Which_data:
+		return capnp.DataList(l.l).At(i)
+	case schema.Type_Which_structType:
+		return newStruct(s.nodes, s.reg, l.elem.StructType().TypeId(), l.l.Struct(i))
+	case schema.Type_Which_list:
+		p, err := capnp.PointerList(l.l).At(i)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := l.elem.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		return List{l: p.List(), elem: elem, parent: s}, nil
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		return capnp.PointerList(l.l).At(i)
+	default:
+		return nil, errors.New("dynamic: unknown list type " + l.elem.Which().String())
+	}
+}
+
+// Elems returns the elements of the list, as returned by At, so that
+// templates can range over them.
+func (l List) Elems() ([]any, error) {
+	elems := make([]any, l.Len())
+	for i := range elems {
+		var err error
+		if elems[i], err = l.At(i); err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
+}
@@ -0,0 +1,116 @@
+package dynamic_test
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/dynamic"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func newRegression(t *testing.T, reg *schemas.Registry) dynamic.Struct {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	r, err := air.NewRootRegression(seg)
+	require.NoError(t, err)
+	dec := text.NewDecoder(strings.NewReader(`(
+		base = (name = "<747>", homes = [jfk, sfo], rating = -3, canFly = true, capacity = 400, maxSpeed = 920.5),
+		beta = [1, 2.5],
+		planes = [(b737 = (base = (name = "boeing"))), (f16 = (base = (name = "falcon")))])`))
+	dec.UseRegistry(reg)
+	require.NoError(t, dec.Decode(air.Regression_TypeID, capnp.Struct(r)))
+	s, err := dynamic.NewStruct(reg, air.Regression_TypeID, capnp.Struct(r))
+	require.NoError(t, err)
+	return s
+}
+
+func TestStruct(t *testing.T) {
+	t.Parallel()
+
+	reg := new(schemas.Registry)
+	air.RegisterSchema(reg)
+	r := newRegression(t, reg)
+
+	fields, err := r.Fields()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "b0", "beta", "planes", "ymu", "ysd"}, fields)
+
+	v, err := r.Get("base")
+	require.NoError(t, err)
+	base, ok := v.(dynamic.Struct)
+	require.True(t, ok, "base is a %T", v)
+	assert.Equal(t, uint64(air.PlaneBase_TypeID), base.TypeID())
+	for name, want := range map[string]any{
+		"name":     "<747>",
+		"rating":   int64(-3),
+		"canFly":   true,
+		"maxSpeed": 920.5,
+	} {
+		got, err := base.Get(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	v, err = base.Get("homes")
+	require.NoError(t, err)
+	homes, ok := v.(dynamic.List)
+	require.True(t, ok, "homes is a %T", v)
+	elems, err := homes.Elems()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"jfk", "sfo"}, elems)
+
+	v, err = r.Get("planes")
+	require.NoError(t, err)
+	plane, err := v.(dynamic.List).At(1)
+	require.NoError(t, err)
+	which, err := plane.(dynamic.Struct).Which()
+	require.NoError(t, err)
+	assert.Equal(t, "f16", which)
+	assert.True(t, plane.(dynamic.Struct).Has("f16"))
+	assert.False(t, plane.(dynamic.Struct).Has("b737"))
+	v, err = plane.(dynamic.Struct).Get("b737")
+	require.NoError(t, err)
+	assert.Nil(t, v, "inactive union member")
+	fields, err = plane.(dynamic.Struct).Fields()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"f16"}, fields)
+
+	_, err = r.Get("bogus")
+	assert.Error(t, err)
+	_, err = dynamic.NewStruct(reg, air.Airport_TypeID, capnp.Struct{})
+	assert.Error(t, err, "not a struct")
+}
+
+func TestFuncMap(t *testing.T) {
+	t.Parallel()
+
+	reg := new(schemas.Registry)
+	air.RegisterSchema(reg)
+	r := newRegression(t, reg)
+
+	const tmpl = `{{field . "base" "name"}} from {{field . "base" "homes" 0}}` +
+		`{{range (field . "planes").Elems}}; {{which .}}{{with field . "b737"}}: {{field . "base" "name"}}{{end}}{{end}}`
+
+	var sb strings.Builder
+	tt := template.Must(template.New("").Funcs(dynamic.FuncMap()).Parse(tmpl))
+	require.NoError(t, tt.Execute(&sb, r))
+	assert.Equal(t, "<747> from jfk; b737: boeing; f16", sb.String())
+
+	sb.Reset()
+	ht := htmltemplate.Must(htmltemplate.New("").Funcs(dynamic.FuncMap()).Parse(tmpl))
+	require.NoError(t, ht.Execute(&sb, r))
+	assert.Equal(t, "&lt;747&gt; from jfk; b737: boeing; f16", sb.String())
+
+	sb.Reset()
+	tt = template.Must(template.New("").Funcs(dynamic.FuncMap()).Parse(`{{field . "base" "bogus"}}`))
+	assert.Error(t, tt.Execute(&sb, r))
+	tt = template.Must(template.New("").Funcs(dynamic.FuncMap()).Parse(`{{field . "base" 0}}`))
+	assert.Error(t, tt.Execute(&sb, r))
+}
@@ -0,0 +1,59 @@
+package dynamic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FuncMap returns functions that let text/template and html/template
+// templates read Structs.  It can be passed to the Funcs method of the
+// templates of either package:
+//
+//	field  {{field . "base" "name"}} follows a path of field names and
+//	       list indexes from a Struct or List, as Struct.Get and
+//	       List.At do.  It returns nil if the path goes through an
+//	       inactive union member, so that {{with field . "b737"}}
+//	       only renders the active one.
+//	has    {{if has . "b737"}} reports whether a Struct has a field
+//	       that is not an inactive union member, as Struct.Has does.
+//	which  {{which .}} returns the name of the active member of a
+//	       Struct's union, as Struct.Which does.
+//
+// Templates can also call the methods of Struct and List directly, such
+// as {{range (field . "planes").Elems}}.
+func FuncMap() map[string]any {
+	return map[string]any{
+		"field": field,
+		"has":   Struct.Has,
+		"which": Struct.Which,
+	}
+}
+
+func field(v any, path ...any) (any, error) {
+	for _, elem := range path {
+		if v == nil {
+			return nil, nil
+		}
+		var err error
+		switch key := elem.(type) {
+		case string:
+			s, ok := v.(Struct)
+			if !ok {
+				return nil, fmt.Errorf("dynamic: cannot get field %q of %T", key, v)
+			}
+			v, err = s.Get(key)
+		case int:
+			l, ok := v.(List)
+			if !ok {
+				return nil, fmt.Errorf("dynamic: cannot index %T", v)
+			}
+			v, err = l.At(key)
+		default:
+			return nil, errors.New("dynamic: path elements must be field names or list indexes")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
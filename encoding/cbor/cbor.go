@@ -0,0 +1,419 @@
+// Package cbor converts Cap'n Proto structs to and from CBOR (RFC 8949)
+// based on a schema, so that components that already speak CBOR can
+// exchange data with Cap'n Proto pipelines without hand-written mapping
+// code.
+//
+// Structs are encoded as maps keyed by field name, leaving out fields
+// that are not set in the active member of a union.  Enumerants are
+// encoded as their numbers, Text as text strings, Data as byte strings
+// and Void as null.  Capabilities and AnyPointer values cannot be
+// converted: encoding fails if one is set, and decoding only accepts
+// null for them.
+//
+// When decoding, map keys must be text strings naming fields of the
+// struct, enumerants may also be given by name, and text strings are
+// accepted for Data.  Indefinite-length items, half-precision floats
+// and undefined (which is treated as null) are supported; tags are
+// ignored, and their content decoded as if they were absent.
+package cbor // import "capnproto.org/go/capnp/v3/encoding/cbor"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/dynval"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Major types.
+const (
+	majorUint   = 0
+	majorNegInt = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Simple values and floats.
+const (
+	simpleFalse     = 20
+	simpleTrue      = 21
+	simpleNull      = 22
+	simpleUndefined = 23
+	simpleFloat16   = 25
+	simpleFloat32   = 26
+	simpleFloat64   = 27
+
+	// indefinite is the additional information of indefinite-length
+	// items, and of the "break" stop code when the major type is 7.
+	indefinite = 31
+)
+
+// maxDepth is the maximum nesting of arrays, maps and tags that the
+// decoder accepts.
+const maxDepth = 100
+
+// Marshal returns the CBOR encoding of a struct.
+func Marshal(typeID uint64, s capnp.Struct) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(typeID, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the CBOR encoding of a struct into s, which must be
+// large enough to hold a struct of the given type.  It is an error for
+// data to hold anything after the struct.
+func Unmarshal(typeID uint64, s capnp.Struct, data []byte) error {
+	r := bytes.NewReader(data)
+	if err := NewDecoder(r).Decode(typeID, s); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return errors.New("cbor: unexpected data after struct")
+	}
+	return nil
+}
+
+// An Encoder writes structs to an output stream as a CBOR sequence.
+type Encoder struct {
+	w    io.Writer
+	conv dynval.Converter
+	buf  []byte
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// UseRegistry changes the registry that the encoder consults for
+// schemas from the default registry.
+func (enc *Encoder) UseRegistry(reg *schemas.Registry) {
+	enc.conv.UseRegistry(reg)
+}
+
+// Encode writes the CBOR encoding of s to the stream.
+func (enc *Encoder) Encode(typeID uint64, s capnp.Struct) error {
+	v, err := enc.conv.FromStruct(typeID, s)
+	if err != nil {
+		return errors.New("cbor: " + err.Error())
+	}
+	enc.buf = appendValue(enc.buf[:0], v)
+	_, err = enc.w.Write(enc.buf)
+	return err
+}
+
+func appendHead(buf []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(buf, m|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, m|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, m|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, m|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, m|27), n)
+	}
+}
+
+func appendValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, majorSimple<<5|simpleNull)
+	case bool:
+		if v {
+			return append(buf, majorSimple<<5|simpleTrue)
+		}
+		return append(buf, majorSimple<<5|simpleFalse)
+	case int64:
+		if v < 0 {
+			return appendHead(buf, majorNegInt, uint64(-1-v))
+		}
+		return appendHead(buf, majorUint, uint64(v))
+	case uint64:
+		return appendHead(buf, majorUint, v)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, majorSimple<<5|simpleFloat32), math.Float32bits(v))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, majorSimple<<5|simpleFloat64), math.Float64bits(v))
+	case string:
+		return append(appendHead(buf, majorText, uint64(len(v))), v...)
+	case []byte:
+		return append(appendHead(buf, majorBytes, uint64(len(v))), v...)
+	case []any:
+		buf = appendHead(buf, majorArray, uint64(len(v)))
+		for _, e := range v {
+			buf = appendValue(buf, e)
+		}
+		return buf
+	case dynval.Struct:
+		buf = appendHead(buf, majorMap, uint64(len(v)))
+		for _, f := range v {
+			buf = appendValue(buf, f.Name)
+			buf = appendValue(buf, f.Value)
+		}
+		return buf
+	default:
+		panic("unreachable")
+	}
+}
+
+// A Decoder reads structs from a CBOR sequence on an input stream.
+type Decoder struct {
+	r    reader
+	conv dynval.Converter
+}
+
+type reader interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// NewDecoder returns a new decoder that reads from r.  If r does not
+// implement io.ByteScanner, the decoder buffers it, and may read data
+// from r beyond the structs that it decodes.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br}
+}
+
+// UseRegistry changes the registry that the decoder consults for
+// schemas from the default registry.
+func (dec *Decoder) UseRegistry(reg *schemas.Registry) {
+	dec.conv.UseRegistry(reg)
+}
+
+// Decode reads the next CBOR data item from the stream and stores the
+// struct it represents in s.  Fields that are not present in the input
+// are left unchanged.  Decode returns io.EOF if the stream is at its
+// end.
+func (dec *Decoder) Decode(typeID uint64, s capnp.Struct) error {
+	if _, err := dec.r.ReadByte(); err != nil {
+		return err
+	}
+	if err := dec.r.UnreadByte(); err != nil {
+		return err
+	}
+	v, err := dec.readValue(0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return errors.New("cbor: " + err.Error())
+	}
+	sv, ok := v.(dynval.Struct)
+	if !ok {
+		return errors.New("cbor: struct must be a map")
+	}
+	if err := dec.conv.ToStruct(typeID, s, sv); err != nil {
+		return errors.New("cbor: " + err.Error())
+	}
+	return nil
+}
+
+// errBreak is returned by readValue when it reads the "break" stop
+// code of an indefinite-length item.
+var errBreak = errors.New("unexpected break")
+
+// readHead reads the initial byte and argument of a data item.
+func (dec *Decoder) readHead() (major, info byte, n uint64, err error) {
+	b, err := dec.r.ReadByte()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b>>5, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		var arg [8]byte
+		size := 1 << (info - 24)
+		if _, err := io.ReadFull(dec.r, arg[8-size:]); err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, binary.BigEndian.Uint64(arg[:]), nil
+	case info == indefinite:
+		if major == majorUint || major == majorNegInt || major == majorTag {
+			return 0, 0, 0, errors.New("invalid indefinite length")
+		}
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, errors.New("reserved additional information")
+	}
+}
+
+// readValue reads a data item, with depth enclosing arrays, maps and
+// tags.
+func (dec *Decoder) readValue(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("data nested too deeply")
+	}
+	major, info, n, err := dec.readHead()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return n, nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, errors.New("negative integer overflows Int64")
+		}
+		return -1 - int64(n), nil
+	case majorBytes, majorText:
+		b, err := dec.readString(major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		var l []any
+		for i := uint64(0); info == indefinite || i < n; i++ {
+			e, err := dec.readValue(depth + 1)
+			if err == errBreak && info == indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, e)
+		}
+		if l == nil {
+			l = []any{}
+		}
+		return l, nil
+	case majorMap:
+		var s dynval.Struct
+		for i := uint64(0); info == indefinite || i < n; i++ {
+			k, err := dec.readValue(depth + 1)
+			if err == errBreak && info == indefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			name, ok := k.(string)
+			if !ok {
+				return nil, errors.New("map key is not a text string")
+			}
+			v, err := dec.readValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, dynval.Field{Name: name, Value: v})
+		}
+		if s == nil {
+			s = dynval.Struct{}
+		}
+		return s, nil
+	case majorTag:
+		return dec.readValue(depth + 1)
+	default:
+		switch info {
+		case simpleFalse:
+			return false, nil
+		case simpleTrue:
+			return true, nil
+		case simpleNull, simpleUndefined:
+			return nil, nil
+		case simpleFloat16:
+			return float32frombits16(uint16(n)), nil
+		case simpleFloat32:
+			return math.Float32frombits(uint32(n)), nil
+		case simpleFloat64:
+			return math.Float64frombits(n), nil
+		case indefinite:
+			return nil, errBreak
+		default:
+			return nil, errors.New("unsupported simple value")
+		}
+	}
+}
+
+// readString reads the content of a byte or text string whose head has
+// been read.  The chunks of an indefinite-length string are
+// concatenated.
+func (dec *Decoder) readString(major, info byte, n uint64) ([]byte, error) {
+	if info != indefinite {
+		return readN(dec.r, n)
+	}
+	var b []byte
+	for {
+		cmajor, cinfo, cn, err := dec.readHead()
+		if err != nil {
+			return nil, err
+		}
+		if cmajor == majorSimple && cinfo == indefinite {
+			if b == nil {
+				b = []byte{}
+			}
+			return b, nil
+		}
+		if cmajor != major || cinfo == indefinite {
+			return nil, errors.New("invalid chunk in indefinite-length string")
+		}
+		chunk, err := readN(dec.r, cn)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, chunk...)
+	}
+}
+
+// readN reads n bytes from r.  Memory is allocated as the bytes arrive,
+// so that a bogus length cannot cause a large allocation.
+func readN(r io.Reader, n uint64) ([]byte, error) {
+	if n > math.MaxInt64 {
+		return nil, errors.New("string too long")
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// float32frombits16 returns the value of an IEEE 754 half-precision
+// float.
+func float32frombits16(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+	switch exp {
+	case 0:
+		// Zero or subnormal: frac * 2^-24.
+		f := float32(frac) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+	}
+}
@@ -0,0 +1,195 @@
+package cbor_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/cbor"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+func newZ(t *testing.T) air.Z {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	z, err := air.NewRootZ(seg)
+	require.NoError(t, err)
+	return z
+}
+
+func newRegression(t *testing.T) air.Regression {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	r, err := air.NewRootRegression(seg)
+	require.NoError(t, err)
+	return r
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	in := `(base = (name = "747", homes = [jfk, sfo], rating = -3, canFly = true, capacity = 400, maxSpeed = 920.5), ` +
+		`b0 = -1.5, beta = [1, 2.5], planes = [(b737 = (base = (name = "", homes = [], rating = 0, canFly = false, capacity = 0, maxSpeed = 0))), (void = void)], ` +
+		`ymu = 0, ysd = inf)`
+	r := newRegression(t)
+	require.NoError(t, text.Unmarshal(air.Regression_TypeID, capnp.Struct(r), in))
+	want, err := text.Marshal(air.Regression_TypeID, capnp.Struct(r))
+	require.NoError(t, err)
+
+	b, err := cbor.Marshal(air.Regression_TypeID, capnp.Struct(r))
+	require.NoError(t, err)
+	r2 := newRegression(t)
+	require.NoError(t, cbor.Unmarshal(air.Regression_TypeID, capnp.Struct(r2), b))
+	got, err := text.Marshal(air.Regression_TypeID, capnp.Struct(r2))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	z := newZ(t)
+	zdata, err := z.NewZdata()
+	require.NoError(t, err)
+	require.NoError(t, zdata.SetData([]byte("hi")))
+	b, err := cbor.Marshal(air.Z_TypeID, capnp.Struct(z))
+	require.NoError(t, err)
+	// {"zdata": {"data": h'6869'}}
+	assert.Equal(t, []byte{
+		0xa1, 0x65, 'z', 'd', 'a', 't', 'a',
+		0xa1, 0x64, 'd', 'a', 't', 'a', 0x42, 'h', 'i',
+	}, b)
+
+	z = newZ(t)
+	z.SetI64(-500)
+	b, err = cbor.Marshal(air.Z_TypeID, capnp.Struct(z))
+	require.NoError(t, err)
+	// {"i64": -500}
+	assert.Equal(t, []byte{0xa1, 0x63, 'i', '6', '4', 0x39, 0x01, 0xf3}, b)
+
+	z = newZ(t)
+	opaque, err := capnp.NewText(z.Segment(), "opaque")
+	require.NoError(t, err)
+	require.NoError(t, z.SetAnyPtr(opaque.ToPtr()))
+	_, err = cbor.Marshal(air.Z_TypeID, capnp.Struct(z))
+	assert.Error(t, err, "non-null AnyPointer")
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			// {_ "u16": 7}, with an indefinite-length map.
+			name: "indefinite map",
+			data: []byte{0xbf, 0x63, 'u', '1', '6', 0x07, 0xff},
+			want: "(u16 = 7)",
+		},
+		{
+			// {"text": (_ "ab", "c")}
+			name: "indefinite text",
+			data: []byte{0xa1, 0x64, 't', 'e', 'x', 't', 0x7f, 0x62, 'a', 'b', 0x61, 'c', 0xff},
+			want: `(text = "abc")`,
+		},
+		{
+			// {"f32": 1.5}, as a half-precision float.
+			name: "half float",
+			data: []byte{0xa1, 0x63, 'f', '3', '2', 0xf9, 0x3e, 0x00},
+			want: "(f32 = 1.5)",
+		},
+		{
+			// {"airport": "lax"}
+			name: "enum by name",
+			data: []byte{0xa1, 0x67, 'a', 'i', 'r', 'p', 'o', 'r', 't', 0x63, 'l', 'a', 'x'},
+			want: "(airport = lax)",
+		},
+		{
+			// {"blob": 1("x")}: the tag is ignored, and a text string
+			// is accepted for Data.
+			name: "tagged",
+			data: []byte{0xa1, 0x64, 'b', 'l', 'o', 'b', 0xc1, 0x61, 'x'},
+			want: `(blob = "x")`,
+		},
+		{
+			// {"grp": {"first": 1, "second": 2}}
+			name: "group",
+			data: []byte{
+				0xa1, 0x63, 'g', 'r', 'p',
+				0xa2, 0x65, 'f', 'i', 'r', 's', 't', 0x01, 0x66, 's', 'e', 'c', 'o', 'n', 'd', 0x02,
+			},
+			want: "(grp = (first = 1, second = 2))",
+		},
+	}
+	for _, test := range tests {
+		z := newZ(t)
+		if !assert.NoError(t, cbor.Unmarshal(air.Z_TypeID, capnp.Struct(z), test.data), test.name) {
+			continue
+		}
+		got, err := text.Marshal(air.Z_TypeID, capnp.Struct(z))
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"not a map", []byte{0x01}},
+		{"truncated", []byte{0xa1, 0x63, 'u', '1'}},
+		{"unknown field", []byte{0xa1, 0x63, 'x', 'y', 'z', 0x01}},
+		{"integer key", []byte{0xa1, 0x01, 0x01}},
+		{"overflow", []byte{0xa1, 0x62, 'i', '8', 0x19, 0x01, 0x2c}},
+		{"wrong type", []byte{0xa1, 0x64, 'b', 'o', 'o', 'l', 0x01}},
+		{"two union members", []byte{0xa2, 0x62, 'u', '8', 0x01, 0x63, 'u', '1', '6', 0x02}},
+		{"non-null capability", []byte{0xa1, 0x64, 'e', 'c', 'h', 'o', 0x60}},
+		{"trailing data", []byte{0xa0, 0xa0}},
+		{"stray break", []byte{0xa1, 0x62, 'u', '8', 0xff}},
+		{"bogus length", []byte{0xa1, 0x64, 'b', 'l', 'o', 'b', 0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"too deep", append(bytes.Repeat([]byte{0x81}, 200), 0x01)},
+	}
+	for _, test := range tests {
+		z := newZ(t)
+		assert.Error(t, cbor.Unmarshal(air.Z_TypeID, capnp.Struct(z), test.data), test.name)
+	}
+}
+
+func TestDecoderSequence(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	enc := cbor.NewEncoder(&buf)
+	for i := uint16(1); i <= 3; i++ {
+		z := newZ(t)
+		z.SetU16(i)
+		require.NoError(t, enc.Encode(air.Z_TypeID, capnp.Struct(z)))
+	}
+
+	dec := cbor.NewDecoder(io.MultiReader(&buf))
+	for i := uint16(1); i <= 3; i++ {
+		z := newZ(t)
+		require.NoError(t, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+		assert.Equal(t, air.Z_Which_u16, z.Which())
+		assert.Equal(t, i, z.U16())
+	}
+	z := newZ(t)
+	assert.Equal(t, io.EOF, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+}
@@ -0,0 +1,266 @@
+// Package jsonschema describes the JSON form of Cap'n Proto structs, as
+// written and read by package capnproto.org/go/capnp/v3/encoding/text,
+// with JSON Schema documents.
+//
+// The schemas follow the JSON Schema 2020-12 dialect that OpenAPI 3.1
+// uses, so that the same definitions can describe the HTTP front ends
+// served by package capnproto.org/go/capnp/v3/httpgw to tools and
+// client generators that do not speak Cap'n Proto.
+//
+// Each struct and enum is described once, in a named definition that
+// other schemas refer to with $ref.  Fields of a union are all listed
+// as optional properties; at most one of them is present in an object.
+// Infinite and NaN floats, which the JSON encoder writes as strings,
+// are not reflected in the schemas.
+package jsonschema // import "capnproto.org/go/capnp/v3/encoding/jsonschema"
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// A Schema is a JSON Schema object.
+type Schema map[string]any
+
+// Dialect is the URI of the JSON Schema dialect used by this package.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Struct returns a JSON Schema document for the struct with the given
+// type ID, looked up in schemas.DefaultRegistry.  The definitions of
+// the types that it refers to are included under $defs.
+func Struct(typeID uint64) ([]byte, error) {
+	b := NewBuilder("#/$defs/")
+	ref, err := b.Ref(typeID)
+	if err != nil {
+		return nil, err
+	}
+	doc := Schema{"$schema": Dialect, "$defs": b.Defs()}
+	for k, v := range ref {
+		doc[k] = v
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// A Builder accumulates the definitions of the structs and enums that
+// the schemas it returns refer to.
+type Builder struct {
+	nodes  nodemap.Map
+	prefix string
+	defs   map[string]Schema
+	names  map[uint64]string
+}
+
+// NewBuilder returns a builder whose references are refPrefix followed
+// by the name of a definition, such as "#/$defs/" or
+// "#/components/schemas/".
+func NewBuilder(refPrefix string) *Builder {
+	return &Builder{
+		prefix: refPrefix,
+		defs:   make(map[string]Schema),
+		names:  make(map[uint64]string),
+	}
+}
+
+// UseRegistry changes the registry that the builder consults for
+// schemas from the default registry.
+func (b *Builder) UseRegistry(reg *schemas.Registry) {
+	b.nodes.UseRegistry(reg)
+}
+
+// Ref returns a schema that refers to the definition of the struct or
+// enum with the given type ID, adding the definition and those of the
+// types it refers to if they are not defined yet.
+func (b *Builder) Ref(typeID uint64) (Schema, error) {
+	if name, ok := b.names[typeID]; ok {
+		return Schema{"$ref": b.prefix + name}, nil
+	}
+	n, err := b.nodes.Find(typeID)
+	if err != nil {
+		return nil, err
+	}
+	if !n.IsValid() {
+		return nil, errors.New("jsonschema: cannot find type " + str.UToHex(typeID))
+	}
+	name := b.defName(n)
+	// Name the type before describing it, so that recursive types
+	// refer to themselves.
+	b.names[typeID] = name
+	var def Schema
+	switch n.Which() {
+	case schema.Node_Which_structNode:
+		def, err = b.structSchema(n)
+	case schema.Node_Which_enum:
+		def, err = enumSchema(n)
+	default:
+		err = errors.New("jsonschema: type " + str.UToHex(typeID) + " is a " + n.Which().String() + ", not a struct or enum")
+	}
+	if err != nil {
+		delete(b.names, typeID)
+		return nil, err
+	}
+	def["title"] = displayName(n)
+	b.defs[name] = def
+	return Schema{"$ref": b.prefix + name}, nil
+}
+
+// Defs returns the definitions added so far, keyed by name.
+func (b *Builder) Defs() map[string]Schema {
+	return b.defs
+}
+
+// defName returns a unique definition name for n, derived from its
+// display name.
+func (b *Builder) defName(n schema.Node) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, displayName(n))
+	for id, other := range b.names {
+		if other == name && id != n.Id() {
+			return name + "_" + str.UToHex(n.Id())
+		}
+	}
+	return name
+}
+
+// displayName returns n's display name without the file name, such as
+// "Foo.bar$Params".
+func displayName(n schema.Node) string {
+	dn, _ := n.DisplayName()
+	if i := strings.LastIndexByte(dn, ':'); i >= 0 {
+		return dn[i+1:]
+	}
+	return dn
+}
+
+func (b *Builder) structSchema(n schema.Node) (Schema, error) {
+	fields, err := n.StructNode().Fields()
+	if err != nil {
+		return nil, err
+	}
+	props := make(map[string]Schema, fields.Len())
+	var union []string
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		name, err := f.Name()
+		if err != nil {
+			return nil, err
+		}
+		var s Schema
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			typ, err := f.Slot().Type()
+			if err != nil {
+				return nil, err
+			}
+			s, err = b.typeSchema(typ)
+			if err != nil {
+				return nil, errors.New("jsonschema: field " + name + ": " + strings.TrimPrefix(err.Error(), "jsonschema: "))
+			}
+		case schema.Field_Which_group:
+			g, err := b.nodes.Find(f.Group().TypeId())
+			if err != nil {
+				return nil, err
+			}
+			s, err = b.structSchema(g)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		if f.DiscriminantValue() != schema.Field_noDiscriminant {
+			union = append(union, name)
+		}
+		props[name] = s
+	}
+	s := Schema{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(union) > 0 {
+		s["description"] = "Union: at most one of " + strings.Join(union, ", ") + " is present."
+	}
+	return s, nil
+}
+
+func enumSchema(n schema.Node) (Schema, error) {
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, enums.Len())
+	for i := range names {
+		if names[i], err = enums.At(i).Name(); err != nil {
+			return nil, err
+		}
+	}
+	return Schema{"type": "string", "enum": names}, nil
+}
+
+func (b *Builder) typeSchema(t schema.Type) (Schema, error) {
+	switch t.Which() {
+	case schema.Type_Which_void:
+		return Schema{"type": "null"}, nil
+	case schema.Type_Which_bool:
+		return Schema{"type": "boolean"}, nil
+	case schema.Type_Which_int8:
+		return intSchema(math.MinInt8, math.MaxInt8), nil
+	case schema.Type_Which_int16:
+		return intSchema(math.MinInt16, math.MaxInt16), nil
+	case schema.Type_Which_int32:
+		return Schema{"type": "integer", "format": "int32"}, nil
+	case schema.Type_Which_int64:
+		return Schema{"type": "integer", "format": "int64"}, nil
+	case schema.Type_Which_uint8:
+		return intSchema(0, math.MaxUint8), nil
+	case schema.Type_Which_uint16:
+		return intSchema(0, math.MaxUint16), nil
+	case schema.Type_Which_uint32:
+		return intSchema(0, math.MaxUint32), nil
+	case schema.Type_Which_uint64:
+		return Schema{"type": "integer", "format": "uint64", "minimum": 0}, nil
+	case schema.Type_Which_float32:
+		return Schema{"type": "number", "format": "float"}, nil
+	case schema.Type_Which_float64:
+		return Schema{"type": "number", "format": "double"}, nil
+	case schema.Type_Which_text:
+		return Schema{"type": "string"}, nil
+	case schema.Type_Which_data:
+		return Schema{"type": "array", "items": intSchema(0, math.MaxUint8)}, nil
+	case schema.Type_Which_list:
+		elem, err := t.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		items, err := b.typeSchema(elem)
+		if err != nil {
+			return nil, err
+		}
+		return Schema{"type": "array", "items": items}, nil
+	case schema.Type_Which_enum:
+		return b.Ref(t.Enum().TypeId())
+	case schema.Type_Which_structType:
+		return b.Ref(t.StructType().TypeId())
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		// The encoder writes a placeholder string for these, and the
+		// decoder only accepts null.
+		return Schema{"type": []string{"string", "null"}}, nil
+	default:
+		return nil, errors.New("jsonschema: unknown type " + t.Which().String())
+	}
+}
+
+func intSchema(lo, hi int64) Schema {
+	return Schema{"type": "integer", "minimum": lo, "maximum": hi}
+}
@@ -0,0 +1,79 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3/encoding/jsonschema"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+func TestStruct(t *testing.T) {
+	t.Parallel()
+
+	b, err := jsonschema.Struct(air.B737_TypeID)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Equal(t, jsonschema.Dialect, doc["$schema"])
+	assert.Equal(t, "#/$defs/B737", doc["$ref"])
+	defs := doc["$defs"].(map[string]any)
+	assert.Len(t, defs, 3, "B737, PlaneBase and Airport")
+
+	base := defs["PlaneBase"].(map[string]any)
+	assert.Equal(t, "object", base["type"])
+	assert.Equal(t, false, base["additionalProperties"])
+	props := base["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string"}, props["name"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, props["canFly"])
+	assert.Equal(t, map[string]any{"type": "integer", "format": "int64"}, props["rating"])
+	assert.Equal(t, map[string]any{"type": "number", "format": "double"}, props["maxSpeed"])
+	assert.Equal(t, map[string]any{
+		"type":  "array",
+		"items": map[string]any{"$ref": "#/$defs/Airport"},
+	}, props["homes"])
+
+	airport := defs["Airport"].(map[string]any)
+	assert.Equal(t, "string", airport["type"])
+	assert.Equal(t, []any{"none", "jfk", "lax", "sfo", "luv", "dfw", "test"}, airport["enum"])
+}
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	b := jsonschema.NewBuilder("#/components/schemas/")
+	ref, err := b.Ref(air.Z_TypeID)
+	require.NoError(t, err)
+	assert.Equal(t, jsonschema.Schema{"$ref": "#/components/schemas/Z"}, ref)
+
+	// Recursive types refer to themselves, and are defined once.
+	z := b.Defs()["Z"]
+	require.NotNil(t, z)
+	props := z["properties"].(map[string]jsonschema.Schema)
+	assert.Equal(t, ref, props["zz"])
+	assert.Equal(t, jsonschema.Schema{"type": "null"}, props["void"])
+	assert.Contains(t, z["description"], "at most one of void, zz,")
+	again, err := b.Ref(air.Z_TypeID)
+	require.NoError(t, err)
+	assert.Equal(t, ref, again)
+
+	data, err := b.Ref(air.Zdata_TypeID)
+	require.NoError(t, err)
+	require.Equal(t, jsonschema.Schema{"$ref": "#/components/schemas/Zdata"}, data)
+	assert.Equal(t, jsonschema.Schema{
+		"type":  "array",
+		"items": jsonschema.Schema{"type": "integer", "minimum": int64(0), "maximum": int64(255)},
+	}, b.Defs()["Zdata"]["properties"].(map[string]jsonschema.Schema)["data"])
+
+	_, err = b.Ref(air.Echo_TypeID)
+	assert.Error(t, err, "interfaces have no JSON form")
+}
@@ -0,0 +1,410 @@
+// Package msgpack converts Cap'n Proto structs to and from MessagePack
+// based on a schema, so that components that already speak MessagePack
+// can exchange data with Cap'n Proto pipelines without hand-written
+// mapping code.
+//
+// Structs are encoded as maps keyed by field name, leaving out fields
+// that are not set in the active member of a union.  Integers use the
+// smallest encoding that holds them, enumerants are encoded as their
+// numbers, Text as str, Data as bin and Void as nil.  Capabilities and
+// AnyPointer values cannot be converted: encoding fails if one is set,
+// and decoding only accepts nil for them.
+//
+// When decoding, map keys must be strs naming fields of the struct,
+// enumerants may also be given by name, and strs are accepted for Data.
+// Extension types are not supported.
+package msgpack // import "capnproto.org/go/capnp/v3/encoding/msgpack"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/dynval"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Formats.  The fix formats hold their value or length in the low bits
+// of the format byte.
+const (
+	fixMap      = 0x80
+	fixArray    = 0x90
+	fixStr      = 0xa0
+	formNil     = 0xc0
+	formFalse   = 0xc2
+	formTrue    = 0xc3
+	formBin8    = 0xc4
+	formBin16   = 0xc5
+	formBin32   = 0xc6
+	formFloat32 = 0xca
+	formFloat64 = 0xcb
+	formUint8   = 0xcc
+	formUint16  = 0xcd
+	formUint32  = 0xce
+	formUint64  = 0xcf
+	formInt8    = 0xd0
+	formInt16   = 0xd1
+	formInt32   = 0xd2
+	formInt64   = 0xd3
+	formStr8    = 0xd9
+	formStr16   = 0xda
+	formStr32   = 0xdb
+	formArray16 = 0xdc
+	formArray32 = 0xdd
+	formMap16   = 0xde
+	formMap32   = 0xdf
+	negFix      = 0xe0
+)
+
+// maxDepth is the maximum nesting of arrays and maps that the decoder
+// accepts.
+const maxDepth = 100
+
+// Marshal returns the MessagePack encoding of a struct.
+func Marshal(typeID uint64, s capnp.Struct) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(typeID, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes the MessagePack encoding of a struct into s, which
+// must be large enough to hold a struct of the given type.  It is an
+// error for data to hold anything after the struct.
+func Unmarshal(typeID uint64, s capnp.Struct, data []byte) error {
+	r := bytes.NewReader(data)
+	if err := NewDecoder(r).Decode(typeID, s); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return errors.New("msgpack: unexpected data after struct")
+	}
+	return nil
+}
+
+// An Encoder writes structs to an output stream, one after another.
+type Encoder struct {
+	w    io.Writer
+	conv dynval.Converter
+	buf  []byte
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// UseRegistry changes the registry that the encoder consults for
+// schemas from the default registry.
+func (enc *Encoder) UseRegistry(reg *schemas.Registry) {
+	enc.conv.UseRegistry(reg)
+}
+
+// Encode writes the MessagePack encoding of s to the stream.
+func (enc *Encoder) Encode(typeID uint64, s capnp.Struct) error {
+	v, err := enc.conv.FromStruct(typeID, s)
+	if err != nil {
+		return errors.New("msgpack: " + err.Error())
+	}
+	enc.buf = appendValue(enc.buf[:0], v)
+	_, err = enc.w.Write(enc.buf)
+	return err
+}
+
+// A lenFormats lists the formats of a str, bin, array or map family,
+// from the fix format to the 32-bit one.  Families without a fix format
+// have a fixMax of -1, and those without an 8-bit format a len8 of 0.
+type lenFormats struct {
+	fix                byte
+	fixMax             int
+	len8, len16, len32 byte
+}
+
+var (
+	strFormats   = lenFormats{fixStr, 31, formStr8, formStr16, formStr32}
+	binFormats   = lenFormats{0, -1, formBin8, formBin16, formBin32}
+	arrayFormats = lenFormats{fixArray, 15, 0, formArray16, formArray32}
+	mapFormats   = lenFormats{fixMap, 15, 0, formMap16, formMap32}
+)
+
+// appendLen appends the format and length of an object of the family.
+func (f lenFormats) appendLen(buf []byte, n int) []byte {
+	switch {
+	case n <= f.fixMax:
+		return append(buf, f.fix|byte(n))
+	case f.len8 != 0 && n <= math.MaxUint8:
+		return append(buf, f.len8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, f.len16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, f.len32), uint32(n))
+	}
+}
+
+func appendUint(buf []byte, x uint64) []byte {
+	switch {
+	case x < fixMap:
+		return append(buf, byte(x))
+	case x <= math.MaxUint8:
+		return append(buf, formUint8, byte(x))
+	case x <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, formUint16), uint16(x))
+	case x <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, formUint32), uint32(x))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, formUint64), x)
+	}
+}
+
+func appendInt(buf []byte, x int64) []byte {
+	switch {
+	case x >= 0:
+		return appendUint(buf, uint64(x))
+	case x >= -32:
+		return append(buf, byte(x))
+	case x >= math.MinInt8:
+		return append(buf, formInt8, byte(x))
+	case x >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, formInt16), uint16(x))
+	case x >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, formInt32), uint32(x))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, formInt64), uint64(x))
+	}
+}
+
+func appendValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, formNil)
+	case bool:
+		if v {
+			return append(buf, formTrue)
+		}
+		return append(buf, formFalse)
+	case int64:
+		return appendInt(buf, v)
+	case uint64:
+		return appendUint(buf, v)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, formFloat32), math.Float32bits(v))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, formFloat64), math.Float64bits(v))
+	case string:
+		return append(strFormats.appendLen(buf, len(v)), v...)
+	case []byte:
+		return append(binFormats.appendLen(buf, len(v)), v...)
+	case []any:
+		buf = arrayFormats.appendLen(buf, len(v))
+		for _, e := range v {
+			buf = appendValue(buf, e)
+		}
+		return buf
+	case dynval.Struct:
+		buf = mapFormats.appendLen(buf, len(v))
+		for _, f := range v {
+			buf = appendValue(buf, f.Name)
+			buf = appendValue(buf, f.Value)
+		}
+		return buf
+	default:
+		panic("unreachable")
+	}
+}
+
+// A Decoder reads structs from an input stream, one after another.
+type Decoder struct {
+	r    reader
+	conv dynval.Converter
+}
+
+type reader interface {
+	io.Reader
+	io.ByteScanner
+}
+
+// NewDecoder returns a new decoder that reads from r.  If r does not
+// implement io.ByteScanner, the decoder buffers it, and may read data
+// from r beyond the structs that it decodes.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br}
+}
+
+// UseRegistry changes the registry that the decoder consults for
+// schemas from the default registry.
+func (dec *Decoder) UseRegistry(reg *schemas.Registry) {
+	dec.conv.UseRegistry(reg)
+}
+
+// Decode reads the next MessagePack object from the stream and stores
+// the struct it represents in s.  Fields that are not present in the
+// input are left unchanged.  Decode returns io.EOF if the stream is at
+// its end.
+func (dec *Decoder) Decode(typeID uint64, s capnp.Struct) error {
+	if _, err := dec.r.ReadByte(); err != nil {
+		return err
+	}
+	if err := dec.r.UnreadByte(); err != nil {
+		return err
+	}
+	v, err := dec.readValue(0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return errors.New("msgpack: " + err.Error())
+	}
+	sv, ok := v.(dynval.Struct)
+	if !ok {
+		return errors.New("msgpack: struct must be a map")
+	}
+	if err := dec.conv.ToStruct(typeID, s, sv); err != nil {
+		return errors.New("msgpack: " + err.Error())
+	}
+	return nil
+}
+
+// readUint reads a big-endian unsigned integer of size bytes.
+func (dec *Decoder) readUint(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(dec.r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// readValue reads an object, with depth enclosing arrays and maps.
+func (dec *Decoder) readValue(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("data nested too deeply")
+	}
+	b, err := dec.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b < fixMap:
+		return uint64(b), nil
+	case b < fixArray:
+		return dec.readMap(depth, uint64(b&0x0f))
+	case b < fixStr:
+		return dec.readArray(depth, uint64(b&0x0f))
+	case b < formNil:
+		return dec.readString(uint64(b & 0x1f))
+	case b >= negFix:
+		return int64(int8(b)), nil
+	}
+	switch b {
+	case formNil:
+		return nil, nil
+	case formFalse:
+		return false, nil
+	case formTrue:
+		return true, nil
+	case formBin8, formBin16, formBin32:
+		n, err := dec.readUint(1 << (b - formBin8))
+		if err != nil {
+			return nil, err
+		}
+		return readN(dec.r, n)
+	case formFloat32:
+		x, err := dec.readUint(4)
+		return math.Float32frombits(uint32(x)), err
+	case formFloat64:
+		x, err := dec.readUint(8)
+		return math.Float64frombits(x), err
+	case formUint8, formUint16, formUint32, formUint64:
+		return dec.readUint(1 << (b - formUint8))
+	case formInt8, formInt16, formInt32, formInt64:
+		size := 1 << (b - formInt8)
+		x, err := dec.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend from the size of the integer.
+		shift := 64 - 8*size
+		return int64(x<<shift) >> shift, nil
+	case formStr8, formStr16, formStr32:
+		n, err := dec.readUint(1 << (b - formStr8))
+		if err != nil {
+			return nil, err
+		}
+		return dec.readString(n)
+	case formArray16, formArray32:
+		n, err := dec.readUint(2 << (b - formArray16))
+		if err != nil {
+			return nil, err
+		}
+		return dec.readArray(depth, n)
+	case formMap16, formMap32:
+		n, err := dec.readUint(2 << (b - formMap16))
+		if err != nil {
+			return nil, err
+		}
+		return dec.readMap(depth, n)
+	default:
+		return nil, errors.New("unsupported format 0x" + str.UToHex(b))
+	}
+}
+
+func (dec *Decoder) readString(n uint64) (string, error) {
+	b, err := readN(dec.r, n)
+	return string(b), err
+}
+
+func (dec *Decoder) readArray(depth int, n uint64) ([]any, error) {
+	l := []any{}
+	for i := uint64(0); i < n; i++ {
+		e, err := dec.readValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, e)
+	}
+	return l, nil
+}
+
+func (dec *Decoder) readMap(depth int, n uint64) (dynval.Struct, error) {
+	s := dynval.Struct{}
+	for i := uint64(0); i < n; i++ {
+		k, err := dec.readValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := k.(string)
+		if !ok {
+			return nil, errors.New("map key is not a str")
+		}
+		v, err := dec.readValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, dynval.Field{Name: name, Value: v})
+	}
+	return s, nil
+}
+
+// readN reads n bytes from r.  Memory is allocated as the bytes arrive,
+// so that a bogus length cannot cause a large allocation.
+func readN(r io.Reader, n uint64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,198 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/msgpack"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+func newZ(t *testing.T) air.Z {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	z, err := air.NewRootZ(seg)
+	require.NoError(t, err)
+	return z
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		`(i64 = -9223372036854775808)`,
+		`(u64 = 18446744073709551615)`,
+		`(i32 = -40000)`,
+		`(f64 = -0.25)`,
+		`(text = "` + string(bytes.Repeat([]byte("x"), 300)) + `")`,
+		`(i16vec = [-1, 0, 32767, -32768])`,
+		`(boolvec = [true, false, true])`,
+		`(datavec = ["a", "bc"])`,
+		`(zvecvec = [[(u8 = 1), (text = "z")], []])`,
+		`(planebase = (name = "747", homes = [jfk, sfo], rating = -3, canFly = true, capacity = 400, maxSpeed = 920.5))`,
+		`(grp = (first = 1, second = 2))`,
+		`(zdate = (year = 2024, month = 2, day = 29))`,
+	}
+	for _, in := range tests {
+		z := newZ(t)
+		require.NoError(t, text.Unmarshal(air.Z_TypeID, capnp.Struct(z), in), in)
+		want, err := text.Marshal(air.Z_TypeID, capnp.Struct(z))
+		require.NoError(t, err)
+
+		b, err := msgpack.Marshal(air.Z_TypeID, capnp.Struct(z))
+		require.NoError(t, err, in)
+		z2 := newZ(t)
+		require.NoError(t, msgpack.Unmarshal(air.Z_TypeID, capnp.Struct(z2), b), in)
+		got, err := text.Marshal(air.Z_TypeID, capnp.Struct(z2))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	z := newZ(t)
+	zdata, err := z.NewZdata()
+	require.NoError(t, err)
+	require.NoError(t, zdata.SetData([]byte("hi")))
+	b, err := msgpack.Marshal(air.Z_TypeID, capnp.Struct(z))
+	require.NoError(t, err)
+	// {"zdata": {"data": bin "hi"}}
+	assert.Equal(t, []byte{
+		0x81, 0xa5, 'z', 'd', 'a', 't', 'a',
+		0x81, 0xa4, 'd', 'a', 't', 'a', 0xc4, 0x02, 'h', 'i',
+	}, b)
+
+	z = newZ(t)
+	z.SetI64(-500)
+	b, err = msgpack.Marshal(air.Z_TypeID, capnp.Struct(z))
+	require.NoError(t, err)
+	// {"i64": -500}, as an int16.
+	assert.Equal(t, []byte{0x81, 0xa3, 'i', '6', '4', 0xd1, 0xfe, 0x0c}, b)
+
+	z = newZ(t)
+	z.SetU32(200)
+	b, err = msgpack.Marshal(air.Z_TypeID, capnp.Struct(z))
+	require.NoError(t, err)
+	// {"u32": 200}, as a uint8.
+	assert.Equal(t, []byte{0x81, 0xa3, 'u', '3', '2', 0xcc, 0xc8}, b)
+
+	z = newZ(t)
+	opaque, err := capnp.NewText(z.Segment(), "opaque")
+	require.NoError(t, err)
+	require.NoError(t, z.SetAnyPtr(opaque.ToPtr()))
+	_, err = msgpack.Marshal(air.Z_TypeID, capnp.Struct(z))
+	assert.Error(t, err, "non-null AnyPointer")
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			// {"u16": 7}, with a map16 and a uint64.
+			name: "wide formats",
+			data: []byte{0xde, 0x00, 0x01, 0xa3, 'u', '1', '6', 0xcf, 0, 0, 0, 0, 0, 0, 0, 0x07},
+			want: "(u16 = 7)",
+		},
+		{
+			// {"i8": -3}, with a negative fixint.
+			name: "negative fixint",
+			data: []byte{0x81, 0xa2, 'i', '8', 0xfd},
+			want: "(i8 = -3)",
+		},
+		{
+			// {"f32": 1}, with an integer.
+			name: "integer float",
+			data: []byte{0x81, 0xa3, 'f', '3', '2', 0x01},
+			want: "(f32 = 1)",
+		},
+		{
+			// {"airport": "lax"}
+			name: "enum by name",
+			data: []byte{0x81, 0xa7, 'a', 'i', 'r', 'p', 'o', 'r', 't', 0xa3, 'l', 'a', 'x'},
+			want: "(airport = lax)",
+		},
+		{
+			// {"blob": "x"}, with a str8.
+			name: "str for data",
+			data: []byte{0x81, 0xa4, 'b', 'l', 'o', 'b', 0xd9, 0x01, 'x'},
+			want: `(blob = "x")`,
+		},
+	}
+	for _, test := range tests {
+		z := newZ(t)
+		if !assert.NoError(t, msgpack.Unmarshal(air.Z_TypeID, capnp.Struct(z), test.data), test.name) {
+			continue
+		}
+		got, err := text.Marshal(air.Z_TypeID, capnp.Struct(z))
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"not a map", []byte{0x01}},
+		{"truncated", []byte{0x81, 0xa3, 'u', '1'}},
+		{"unknown field", []byte{0x81, 0xa3, 'x', 'y', 'z', 0x01}},
+		{"integer key", []byte{0x81, 0x01, 0x01}},
+		{"overflow", []byte{0x81, 0xa2, 'i', '8', 0xcd, 0x01, 0x2c}},
+		{"negative unsigned", []byte{0x81, 0xa2, 'u', '8', 0xff}},
+		{"wrong type", []byte{0x81, 0xa4, 'b', 'o', 'o', 'l', 0x01}},
+		{"two union members", []byte{0x82, 0xa2, 'u', '8', 0x01, 0xa3, 'u', '1', '6', 0x02}},
+		{"non-null capability", []byte{0x81, 0xa4, 'e', 'c', 'h', 'o', 0xa0}},
+		{"trailing data", []byte{0x80, 0x80}},
+		{"extension", []byte{0x81, 0xa2, 'u', '8', 0xd4, 0x01, 0x00}},
+		{"bogus length", []byte{0x81, 0xa4, 'b', 'l', 'o', 'b', 0xc6, 0xff, 0xff, 0xff, 0xff}},
+		{"too deep", append(bytes.Repeat([]byte{0x91}, 200), 0x01)},
+	}
+	for _, test := range tests {
+		z := newZ(t)
+		assert.Error(t, msgpack.Unmarshal(air.Z_TypeID, capnp.Struct(z), test.data), test.name)
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	for i := uint16(1); i <= 3; i++ {
+		z := newZ(t)
+		z.SetU16(i)
+		require.NoError(t, enc.Encode(air.Z_TypeID, capnp.Struct(z)))
+	}
+
+	dec := msgpack.NewDecoder(io.MultiReader(&buf))
+	for i := uint16(1); i <= 3; i++ {
+		z := newZ(t)
+		require.NoError(t, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+		assert.Equal(t, air.Z_Which_u16, z.Which())
+		assert.Equal(t, i, z.U16())
+	}
+	z := newZ(t)
+	assert.Equal(t, io.EOF, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"math"
 	"strconv"
+	"unicode/utf8"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/internal/nodemap"
@@ -33,6 +34,16 @@ func Marshal(typeID uint64, s capnp.Struct) (string, error) {
 	return buf.String(), nil
 }
 
+// MarshalNode returns the text representation of a struct whose type is
+// described by the schema node n.  See Encoder.EncodeNode.
+func MarshalNode(n, s capnp.Struct) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeNode(n, s); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // MarshalList returns the text representation of a struct list.
 func MarshalList(typeID uint64, l capnp.List) (string, error) {
 	buf := new(bytes.Buffer)
@@ -47,6 +58,7 @@ type Encoder struct {
 	w     errWriter
 	tmp   []byte
 	nodes nodemap.Map
+	json  bool
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -60,6 +72,30 @@ func (enc *Encoder) UseRegistry(reg *schemas.Registry) {
 	enc.nodes.UseRegistry(reg)
 }
 
+// AddNodes makes the encoder use the schema nodes in nodes for their IDs
+// instead of looking them up in its registry.  This lets it encode types
+// whose schemas are loaded at runtime, for instance from a
+// CodeGeneratorRequest, without registering them.  Calling UseRegistry
+// discards the nodes added before.
+//
+// Nodes are passed as structs, since the generated schema package uses
+// this package: convert a schema.Node n with capnp.Struct(n).
+func (enc *Encoder) AddNodes(nodes ...capnp.Struct) {
+	for _, n := range nodes {
+		enc.nodes.Add(schema.Node(n))
+	}
+}
+
+// UseJSON makes the encoder write JSON instead of the text format.
+// Structs are written as objects keyed by field name, enumerants as
+// strings, Data as a list of bytes, Void as null and infinite or NaN
+// floats as the strings "inf", "-inf" and "nan", all of which a Decoder
+// reads back.  Capabilities and AnyPointer values are written as
+// strings that cannot be decoded.
+func (enc *Encoder) UseJSON() {
+	enc.json = true
+}
+
 // Encode writes the text representation of s to the stream.
 func (enc *Encoder) Encode(typeID uint64, s capnp.Struct) error {
 	if enc.w.err != nil {
@@ -72,6 +108,20 @@ func (enc *Encoder) Encode(typeID uint64, s capnp.Struct) error {
 	return enc.w.err
 }
 
+// EncodeNode writes the text representation of s, a struct whose type
+// is described by the schema node n, as passed to AddNodes.  The nodes
+// of groups, enums and structs used by its fields are looked up as for
+// Encode, so they must be in the encoder's registry or have been added
+// with AddNodes.
+func (enc *Encoder) EncodeNode(n, s capnp.Struct) error {
+	node := schema.Node(n)
+	if node.Which() != schema.Node_Which_structNode {
+		return errors.New("node " + str.UToHex(node.Id()) + " is not a struct")
+	}
+	enc.AddNodes(n)
+	return enc.Encode(node.Id(), s)
+}
+
 // EncodeList writes the text representation of struct list l to the stream.
 func (enc *Encoder) EncodeList(typeID uint64, l capnp.List) error {
 	_, seg := capnp.NewSingleSegmentMessage(nil)
@@ -99,21 +149,120 @@ func (enc *Encoder) marshalUint(i uint64) {
 	enc.w.Write(enc.tmp)
 }
 
+func (enc *Encoder) marshalVoid() {
+	if enc.json {
+		enc.w.WriteString("null")
+	} else {
+		enc.w.WriteString(voidMarker)
+	}
+}
+
+// marshalMarker writes one of the marker strings for values that have
+// no text representation.
+func (enc *Encoder) marshalMarker(m string) {
+	if enc.json && m != interfaceNullMarker {
+		enc.w.WriteByte('"')
+		enc.w.WriteString(m)
+		enc.w.WriteByte('"')
+	} else {
+		enc.w.WriteString(m)
+	}
+}
+
 func (enc *Encoder) marshalFloat32(f float32) {
+	if enc.marshalNonFinite(float64(f)) {
+		return
+	}
 	enc.tmp = strconv.AppendFloat(enc.tmp[:0], float64(f), 'g', -1, 32)
 	enc.w.Write(enc.tmp)
 }
 
 func (enc *Encoder) marshalFloat64(f float64) {
+	if enc.marshalNonFinite(f) {
+		return
+	}
 	enc.tmp = strconv.AppendFloat(enc.tmp[:0], f, 'g', -1, 64)
 	enc.w.Write(enc.tmp)
 }
 
+// marshalNonFinite writes f as inf, -inf or nan if it is infinite or
+// NaN, and reports whether it did.  JSON numbers cannot represent such
+// values, so they are written as strings in JSON.
+func (enc *Encoder) marshalNonFinite(f float64) bool {
+	var m string
+	switch {
+	case math.IsInf(f, 1):
+		m = "inf"
+	case math.IsInf(f, -1):
+		m = "-inf"
+	case math.IsNaN(f):
+		m = "nan"
+	default:
+		return false
+	}
+	if enc.json {
+		enc.w.WriteByte('"')
+		enc.w.WriteString(m)
+		enc.w.WriteByte('"')
+	} else {
+		enc.w.WriteString(m)
+	}
+	return true
+}
+
 func (enc *Encoder) marshalText(t []byte) {
-	enc.tmp = strquote.Append(enc.tmp[:0], t)
+	if enc.json {
+		enc.tmp = appendJSONString(enc.tmp[:0], t)
+	} else {
+		enc.tmp = strquote.Append(enc.tmp[:0], t)
+	}
+	enc.w.Write(enc.tmp)
+}
+
+func (enc *Encoder) marshalData(b []byte) {
+	if !enc.json {
+		enc.marshalText(b)
+		return
+	}
+	enc.tmp = append(enc.tmp[:0], '[')
+	for i, c := range b {
+		if i > 0 {
+			enc.tmp = append(enc.tmp, ", "...)
+		}
+		enc.tmp = strconv.AppendUint(enc.tmp, uint64(c), 10)
+	}
+	enc.tmp = append(enc.tmp, ']')
 	enc.w.Write(enc.tmp)
 }
 
+// appendJSONString appends a JSON string literal of t to buf.  Invalid
+// UTF-8 is replaced with U+FFFD, as encoding/json does.
+func appendJSONString(buf []byte, t []byte) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for len(t) > 0 {
+		r, size := utf8.DecodeRune(t)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, `\ufffd`...)
+		case r == '"' || r == '\\':
+			buf = append(buf, '\\', byte(r))
+		case r == '\n':
+			buf = append(buf, '\\', 'n')
+		case r == '\r':
+			buf = append(buf, '\\', 'r')
+		case r == '\t':
+			buf = append(buf, '\\', 't')
+		case r < 0x20:
+			buf = append(buf, '\\', 'u', '0', '0', hex[r>>4], hex[r&0xf])
+		default:
+			buf = append(buf, t[:size]...)
+		}
+		t = t[size:]
+	}
+	return append(buf, '"')
+}
+
 func (enc *Encoder) marshalStruct(typeID uint64, s capnp.Struct) error {
 	n, err := enc.nodes.Find(typeID)
 	if err != nil {
@@ -126,7 +275,11 @@ func (enc *Encoder) marshalStruct(typeID uint64, s capnp.Struct) error {
 	if n.StructNode().DiscriminantCount() > 0 {
 		discriminant = s.Uint16(capnp.DataOffset(n.StructNode().DiscriminantOffset() * 2))
 	}
-	enc.w.WriteByte('(')
+	open, close, sep := byte('('), byte(')'), " = "
+	if enc.json {
+		open, close, sep = '{', '}', ": "
+	}
+	enc.w.WriteByte(open)
 	fields := codeOrderFields(n.StructNode())
 	first := true
 	for _, f := range fields {
@@ -144,8 +297,14 @@ func (enc *Encoder) marshalStruct(typeID uint64, s capnp.Struct) error {
 		if err != nil {
 			return err
 		}
-		enc.w.Write(name)
-		enc.w.WriteString(" = ")
+		if enc.json {
+			enc.w.WriteByte('"')
+			enc.w.Write(name)
+			enc.w.WriteByte('"')
+		} else {
+			enc.w.Write(name)
+		}
+		enc.w.WriteString(sep)
 		switch f.Which() {
 		case schema.Field_Which_slot:
 			if err := enc.marshalFieldValue(s, f); err != nil {
@@ -157,7 +316,7 @@ func (enc *Encoder) marshalStruct(typeID uint64, s capnp.Struct) error {
 			}
 		}
 	}
-	enc.w.WriteByte(')')
+	enc.w.WriteByte(close)
 	return nil
 }
 
@@ -180,7 +339,7 @@ func (enc *Encoder) marshalFieldValue(s capnp.Struct, f schema.Field) error {
 	}
 	switch typ.Which() {
 	case schema.Type_Which_void:
-		enc.w.WriteString(voidMarker)
+		enc.marshalVoid()
 	case schema.Type_Which_bool:
 		v := s.Bit(capnp.BitOffset(f.Slot().Offset()))
 		d := dv.Bool()
@@ -241,10 +400,10 @@ func (enc *Encoder) marshalFieldValue(s capnp.Struct, f schema.Field) error {
 		}
 		if !p.IsValid() {
 			b, _ := dv.Data()
-			enc.marshalText(b)
+			enc.marshalData(b)
 			return nil
 		}
-		enc.marshalText(p.Data())
+		enc.marshalData(p.Data())
 	case schema.Type_Which_text:
 		p, err := s.Ptr(uint16(f.Slot().Offset()))
 		if err != nil {
@@ -275,12 +434,12 @@ func (enc *Encoder) marshalFieldValue(s capnp.Struct, f schema.Field) error {
 		return enc.marshalEnum(typ.Enum().TypeId(), v^d)
 	case schema.Type_Which_interface:
 		if s.HasPtr(uint16(f.Slot().Offset())) {
-			enc.w.WriteString(interfaceMarker)
+			enc.marshalMarker(interfaceMarker)
 		} else {
-			enc.w.WriteString(interfaceNullMarker)
+			enc.marshalMarker(interfaceNullMarker)
 		}
 	case schema.Type_Which_anyPointer:
-		enc.w.WriteString(anyPointerMarker)
+		enc.marshalMarker(anyPointerMarker)
 	default:
 		return errors.New("unknown field type " + typ.Which().String())
 	}
@@ -299,6 +458,13 @@ func codeOrderFields(s schema.Node_structNode) []schema.Field {
 }
 
 func (enc *Encoder) marshalList(elem schema.Type, l capnp.List) error {
+	if enc.json {
+		switch elem.Which() {
+		case schema.Type_Which_void, schema.Type_Which_float32, schema.Type_Which_float64,
+			schema.Type_Which_data, schema.Type_Which_text:
+			return enc.marshalJSONList(elem, l)
+		}
+	}
 	switch elem.Which() {
 	case schema.Type_Which_void:
 		enc.w.WriteString(capnp.VoidList(l).String())
@@ -383,9 +549,9 @@ func (enc *Encoder) marshalList(elem schema.Type, l capnp.List) error {
 				return err
 			}
 			if p.IsValid() {
-				enc.w.WriteString(interfaceMarker)
+				enc.marshalMarker(interfaceMarker)
 			} else {
-				enc.w.WriteString(interfaceNullMarker)
+				enc.marshalMarker(interfaceNullMarker)
 			}
 		}
 		enc.w.WriteByte(']')
@@ -395,7 +561,7 @@ func (enc *Encoder) marshalList(elem schema.Type, l capnp.List) error {
 			if i > 0 {
 				enc.w.WriteString(", ")
 			}
-			enc.w.WriteString(anyPointerMarker)
+			enc.marshalMarker(anyPointerMarker)
 		}
 		enc.w.WriteByte(']')
 	default:
@@ -404,6 +570,39 @@ func (enc *Encoder) marshalList(elem schema.Type, l capnp.List) error {
 	return nil
 }
 
+// marshalJSONList writes the lists whose String methods do not produce
+// valid JSON.
+func (enc *Encoder) marshalJSONList(elem schema.Type, l capnp.List) error {
+	enc.w.WriteByte('[')
+	for i := 0; i < l.Len(); i++ {
+		if i > 0 {
+			enc.w.WriteString(", ")
+		}
+		switch elem.Which() {
+		case schema.Type_Which_void:
+			enc.marshalVoid()
+		case schema.Type_Which_float32:
+			enc.marshalFloat32(capnp.Float32List(l).At(i))
+		case schema.Type_Which_float64:
+			enc.marshalFloat64(capnp.Float64List(l).At(i))
+		case schema.Type_Which_data:
+			b, err := capnp.DataList(l).At(i)
+			if err != nil {
+				return err
+			}
+			enc.marshalData(b)
+		case schema.Type_Which_text:
+			t, err := capnp.TextList(l).BytesAt(i)
+			if err != nil {
+				return err
+			}
+			enc.marshalText(t)
+		}
+	}
+	enc.w.WriteByte(']')
+	return nil
+}
+
 func (enc *Encoder) marshalEnum(typ uint64, val uint16) error {
 	n, err := enc.nodes.Find(typ)
 	if err != nil {
@@ -426,7 +625,13 @@ func (enc *Encoder) marshalEnum(typ uint64, val uint16) error {
 	if err != nil {
 		return err
 	}
-	enc.w.Write(name)
+	if enc.json {
+		enc.w.WriteByte('"')
+		enc.w.Write(name)
+		enc.w.WriteByte('"')
+	} else {
+		enc.w.Write(name)
+	}
 	return nil
 }
 
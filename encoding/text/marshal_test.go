@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"capnproto.org/go/capnp/v3"
@@ -228,3 +229,66 @@ func TestEncodeList(t *testing.T) {
 		}
 	}
 }
+
+func TestEncodeNode(t *testing.T) {
+	data, err := readTestFile("txt.capnp.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := capnp.Unmarshal(data)
+	if err != nil {
+		t.Fatal("Unmarshaling txt.capnp.out:", err)
+	}
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	if err != nil {
+		t.Fatal("Reading code generator request txt.capnp.out:", err)
+	}
+	nodes, err := req.Nodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyValue, floatKv capnp.Struct
+	all := make([]capnp.Struct, nodes.Len())
+	for i := range all {
+		all[i] = capnp.Struct(nodes.At(i))
+		switch nodes.At(i).Id() {
+		case keyValueID:
+			keyValue = all[i]
+		case 0x967c8fe21790b0fb:
+			floatKv = all[i]
+		}
+	}
+
+	const want = `(key = "cheese", value = (cheeseList = [gouda, cheddar]))`
+	_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+	s, err := capnp.NewRootStruct(seg, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := NewDecoder(strings.NewReader(want))
+	dec.UseRegistry(newTestRegistry(t))
+	if err := dec.Decode(keyValueID, s); err != nil {
+		t.Fatal("Decode:", err)
+	}
+
+	// An empty registry: the nodes must come from AddNodes.
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+	enc.UseRegistry(new(schemas.Registry))
+	enc.AddNodes(all...)
+	if err := enc.EncodeNode(keyValue, s); err != nil {
+		t.Fatal("EncodeNode:", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("EncodeNode(...) = %q; want %q", got, want)
+	}
+
+	enc = NewEncoder(new(bytes.Buffer))
+	enc.UseRegistry(new(schemas.Registry))
+	if err := enc.EncodeNode(keyValue, s); err == nil {
+		t.Error("EncodeNode without the nodes of the value field succeeded")
+	}
+	if _, err := MarshalNode(floatKv, s); err == nil {
+		t.Error("MarshalNode with a const node succeeded")
+	}
+}
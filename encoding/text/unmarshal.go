@@ -0,0 +1,883 @@
+package text
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Unmarshal parses the text representation of a struct into s, which
+// must be large enough to hold a struct of the given type.
+func Unmarshal(typeID uint64, s capnp.Struct, data string) error {
+	return NewDecoder(strings.NewReader(data)).Decode(typeID, s)
+}
+
+// UnmarshalMessage parses the text representation of a struct of the
+// given type into the root of a new message.  Use the generated
+// ReadRoot function of the type to read it.
+func UnmarshalMessage(typeID uint64, data string) (*capnp.Message, error) {
+	return NewDecoder(strings.NewReader(data)).DecodeMessage(typeID)
+}
+
+// A Decoder reads the text format of Cap'n Proto messages from an input
+// stream.
+//
+// In addition to the text format written by Encoder, a Decoder accepts
+// JSON, so that field values can be given as {"name": value} as well as
+// (name = value).  Enumerants may be written as identifiers or strings,
+// Data may be written as a string or as a list of bytes, and infinite
+// and NaN floats may be written as the strings "inf", "-inf" and "nan".
+// Fields of interface and AnyPointer types can only be null.
+type Decoder struct {
+	r     io.Reader
+	nodes nodemap.Map
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// UseRegistry changes the registry that the decoder consults for
+// schemas from the default registry.
+func (dec *Decoder) UseRegistry(reg *schemas.Registry) {
+	dec.nodes.UseRegistry(reg)
+}
+
+// AddNodes makes the decoder use the schema nodes in nodes for their IDs
+// instead of looking them up in its registry, like Encoder.AddNodes.
+func (dec *Decoder) AddNodes(nodes ...capnp.Struct) {
+	for _, n := range nodes {
+		dec.nodes.Add(schema.Node(n))
+	}
+}
+
+// DecodeMessage reads the rest of the input stream and stores the
+// struct it represents in the root of a new message, sized as the
+// struct's schema says.
+func (dec *Decoder) DecodeMessage(typeID uint64) (*capnp.Message, error) {
+	size, err := dec.structSize(typeID)
+	if err != nil {
+		return nil, err
+	}
+	msg, seg, err := capnp.NewMessage(capnp.MultiSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	s, err := capnp.NewRootStruct(seg, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(typeID, s); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Decode reads the rest of the input stream and stores the struct it
+// represents in s.  Fields that are not present in the input are left
+// unchanged.
+func (dec *Decoder) Decode(typeID uint64, s capnp.Struct) error {
+	b, err := io.ReadAll(dec.r)
+	if err != nil {
+		return err
+	}
+	p := &parser{s: string(b)}
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return p.errorf("unexpected " + strconv.Quote(p.s[p.pos:p.pos+1]) + " after value")
+	}
+	return dec.unmarshalStruct(typeID, s, v)
+}
+
+// A valueKind is the syntactic kind of a parsed value.
+type valueKind int
+
+const (
+	structValue valueKind = iota
+	listValue
+	stringValue
+	numberValue
+	identValue // includes true, false, void, null, inf and nan
+)
+
+// A value is a parsed value that has not been matched to a schema yet.
+type value struct {
+	kind   valueKind
+	s      string       // string, number or identifier
+	fields []fieldValue // struct
+	elems  []value      // list
+}
+
+type fieldValue struct {
+	name string
+	val  value
+}
+
+func (v value) isNull() bool {
+	return v.kind == identValue && v.s == "null"
+}
+
+// parser parses the text format and JSON.
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) errorf(msg string) error {
+	return errors.New("text: offset " + str.Itod(p.pos) + ": " + msg)
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			// Comment until end of line.
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// consume skips whitespace and then c, if present.
+func (p *parser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseValue() (value, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return value{}, p.errorf("unexpected end of input")
+	}
+	switch c := p.s[p.pos]; {
+	case c == '(':
+		p.pos++
+		return p.parseStruct(')', '=')
+	case c == '{':
+		p.pos++
+		return p.parseStruct('}', ':')
+	case c == '[':
+		p.pos++
+		return p.parseList()
+	case c == '"':
+		s, err := p.parseString()
+		return value{kind: stringValue, s: s}, err
+	case c == '-' || c == '+' || c == '.' || '0' <= c && c <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.s) && isNumberChar(p.s[p.pos]) {
+			p.pos++
+		}
+		return value{kind: numberValue, s: p.s[start:p.pos]}, nil
+	case isIdentChar(c):
+		return value{kind: identValue, s: p.parseIdent()}, nil
+	default:
+		return value{}, p.errorf("unexpected " + strconv.Quote(string(c)))
+	}
+}
+
+func (p *parser) parseStruct(end, sep byte) (value, error) {
+	v := value{kind: structValue}
+	if p.consume(end) {
+		return v, nil
+	}
+	for {
+		p.skipSpace()
+		var name string
+		switch {
+		case p.pos < len(p.s) && p.s[p.pos] == '"':
+			var err error
+			if name, err = p.parseString(); err != nil {
+				return value{}, err
+			}
+		case p.pos < len(p.s) && isIdentChar(p.s[p.pos]):
+			name = p.parseIdent()
+		default:
+			return value{}, p.errorf("expected field name")
+		}
+		if !p.consume(sep) {
+			return value{}, p.errorf("expected " + strconv.Quote(string(sep)) + " after field " + name)
+		}
+		fv, err := p.parseValue()
+		if err != nil {
+			return value{}, err
+		}
+		v.fields = append(v.fields, fieldValue{name, fv})
+		if p.consume(end) {
+			return v, nil
+		}
+		if !p.consume(',') {
+			return value{}, p.errorf("expected ',' or " + strconv.Quote(string(end)))
+		}
+		if p.consume(end) {
+			return v, nil // trailing comma
+		}
+	}
+}
+
+func (p *parser) parseList() (value, error) {
+	v := value{kind: listValue}
+	if p.consume(']') {
+		return v, nil
+	}
+	for {
+		ev, err := p.parseValue()
+		if err != nil {
+			return value{}, err
+		}
+		v.elems = append(v.elems, ev)
+		if p.consume(']') {
+			return v, nil
+		}
+		if !p.consume(',') {
+			return value{}, p.errorf("expected ',' or ']'")
+		}
+		if p.consume(']') {
+			return v, nil // trailing comma
+		}
+	}
+}
+
+// parseString parses a double-quoted string with C-style escapes,
+// which covers both the text format and JSON.
+func (p *parser) parseString() (string, error) {
+	start := p.pos
+	p.pos++ // opening quote
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			lit := strings.ReplaceAll(p.s[start:p.pos], `\/`, `/`)
+			s, err := strconv.Unquote(lit)
+			if err != nil {
+				p.pos = start
+				return "", p.errorf("invalid string " + lit)
+			}
+			return s, nil
+		default:
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.s) && (isIdentChar(p.s[p.pos]) || '0' <= p.s[p.pos] && p.s[p.pos] <= '9') {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isIdentChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+func isNumberChar(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '.' || c == '-' || c == '+' || c == '_'
+}
+
+func (dec *Decoder) unmarshalStruct(typeID uint64, s capnp.Struct, v value) error {
+	if v.kind != structValue {
+		return errors.New("text: cannot use " + v.describe() + " as struct")
+	}
+	n, err := dec.nodes.Find(typeID)
+	if err != nil {
+		return err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_structNode {
+		return errors.New("text: cannot find struct type " + str.UToHex(typeID))
+	}
+	fields, err := n.StructNode().Fields()
+	if err != nil {
+		return err
+	}
+	unionSet := ""
+	for _, fv := range v.fields {
+		f, ok := findField(fields, fv.name)
+		if !ok {
+			return errors.New("text: unknown field " + fv.name + " in " + shortDisplayName(n))
+		}
+		if dv := f.DiscriminantValue(); dv != schema.Field_noDiscriminant {
+			if unionSet != "" {
+				return errors.New("text: fields " + unionSet + " and " + fv.name + " of " + shortDisplayName(n) + " are in the same union")
+			}
+			unionSet = fv.name
+			s.SetUint16(capnp.DataOffset(n.StructNode().DiscriminantOffset()*2), dv)
+		}
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			err = dec.unmarshalField(s, f, fv.val)
+		case schema.Field_Which_group:
+			err = dec.unmarshalStruct(f.Group().TypeId(), s, fv.val)
+		}
+		if err != nil {
+			return errors.New("text: field " + fv.name + ": " + strings.TrimPrefix(err.Error(), "text: "))
+		}
+	}
+	return nil
+}
+
+func findField(fields schema.Field_List, name string) (schema.Field, bool) {
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if fname, _ := f.Name(); fname == name {
+			return f, true
+		}
+	}
+	return schema.Field{}, false
+}
+
+func shortDisplayName(n schema.Node) string {
+	dn, _ := n.DisplayName()
+	return dn[n.DisplayNamePrefixLength():]
+}
+
+func (v value) describe() string {
+	switch v.kind {
+	case structValue:
+		return "struct"
+	case listValue:
+		return "list"
+	case stringValue:
+		return "string " + strconv.Quote(v.s)
+	default:
+		return v.s
+	}
+}
+
+func (dec *Decoder) unmarshalField(s capnp.Struct, f schema.Field, v value) error {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	off := f.Slot().Offset()
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		if v.kind != identValue || v.s != "void" && v.s != "null" {
+			return errors.New("cannot use " + v.describe() + " as void")
+		}
+	case schema.Type_Which_bool:
+		b, err := parseBool(v)
+		if err != nil {
+			return err
+		}
+		s.SetBit(capnp.BitOffset(off), b != dv.Bool())
+	case schema.Type_Which_int8:
+		i, err := parseInt(v, 8)
+		if err != nil {
+			return err
+		}
+		s.SetUint8(capnp.DataOffset(off), uint8(int8(i)^dv.Int8()))
+	case schema.Type_Which_int16:
+		i, err := parseInt(v, 16)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), uint16(int16(i)^dv.Int16()))
+	case schema.Type_Which_int32:
+		i, err := parseInt(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), uint32(int32(i)^dv.Int32()))
+	case schema.Type_Which_int64:
+		i, err := parseInt(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), uint64(i^dv.Int64()))
+	case schema.Type_Which_uint8:
+		u, err := parseUint(v, 8)
+		if err != nil {
+			return err
+		}
+		s.SetUint8(capnp.DataOffset(off), uint8(u)^dv.Uint8())
+	case schema.Type_Which_uint16:
+		u, err := parseUint(v, 16)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), uint16(u)^dv.Uint16())
+	case schema.Type_Which_uint32:
+		u, err := parseUint(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), uint32(u)^dv.Uint32())
+	case schema.Type_Which_uint64:
+		u, err := parseUint(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), u^dv.Uint64())
+	case schema.Type_Which_float32:
+		x, err := parseFloat(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), math.Float32bits(float32(x))^math.Float32bits(dv.Float32()))
+	case schema.Type_Which_float64:
+		x, err := parseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), math.Float64bits(x)^math.Float64bits(dv.Float64()))
+	case schema.Type_Which_enum:
+		e, err := dec.parseEnum(typ.Enum().TypeId(), v)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), e^dv.Enum())
+	case schema.Type_Which_text:
+		if v.isNull() {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		if v.kind != stringValue {
+			return errors.New("cannot use " + v.describe() + " as text")
+		}
+		return s.SetNewText(uint16(off), v.s)
+	case schema.Type_Which_data:
+		if v.isNull() {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		b, err := parseData(v)
+		if err != nil {
+			return err
+		}
+		return s.SetData(uint16(off), b)
+	case schema.Type_Which_structType:
+		if v.isNull() {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		id := typ.StructType().TypeId()
+		sz, err := dec.structSize(id)
+		if err != nil {
+			return err
+		}
+		ss, err := capnp.NewStruct(s.Segment(), sz)
+		if err != nil {
+			return err
+		}
+		if err := s.SetPtr(uint16(off), ss.ToPtr()); err != nil {
+			return err
+		}
+		return dec.unmarshalStruct(id, ss, v)
+	case schema.Type_Which_list:
+		if v.isNull() {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		l, err := dec.newList(s.Segment(), typ, v)
+		if err != nil {
+			return err
+		}
+		return s.SetPtr(uint16(off), l.ToPtr())
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		if !v.isNull() {
+			return errors.New("cannot set a " + typ.Which().String() + " from text")
+		}
+		return s.SetPtr(uint16(off), capnp.Ptr{})
+	default:
+		return errors.New("unknown field type " + typ.Which().String())
+	}
+	return nil
+}
+
+func (dec *Decoder) structSize(id uint64) (capnp.ObjectSize, error) {
+	n, err := dec.nodes.Find(id)
+	if err != nil {
+		return capnp.ObjectSize{}, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_structNode {
+		return capnp.ObjectSize{}, errors.New("cannot find struct type " + str.UToHex(id))
+	}
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructNode().DataWordCount()) * 8,
+		PointerCount: n.StructNode().PointerCount(),
+	}, nil
+}
+
+// newList allocates a list of type typ in seg and fills it from v.
+func (dec *Decoder) newList(seg *capnp.Segment, typ schema.Type, v value) (capnp.List, error) {
+	if v.kind != listValue {
+		return capnp.List{}, errors.New("cannot use " + v.describe() + " as list")
+	}
+	elem, err := typ.List().ElementType()
+	if err != nil {
+		return capnp.List{}, err
+	}
+	n := int32(len(v.elems))
+	switch elem.Which() {
+	case schema.Type_Which_void:
+		return capnp.List(capnp.NewVoidList(seg, n)), nil
+	case schema.Type_Which_bool:
+		l, err := capnp.NewBitList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			b, err := parseBool(ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, b)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		return newIntList(seg, elem.Which(), v.elems)
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		return newUintList(seg, elem.Which(), v.elems)
+	case schema.Type_Which_float32:
+		l, err := capnp.NewFloat32List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			x, err := parseFloat(ev, 32)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, float32(x))
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_float64:
+		l, err := capnp.NewFloat64List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			x, err := parseFloat(ev, 64)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, x)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_enum:
+		l, err := capnp.NewUInt16List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			e, err := dec.parseEnum(elem.Enum().TypeId(), ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, e)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_text:
+		l, err := capnp.NewTextList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			if ev.kind != stringValue {
+				return capnp.List{}, errors.New("cannot use " + ev.describe() + " as text")
+			}
+			if err := l.Set(i, ev.s); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_data:
+		l, err := capnp.NewDataList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			b, err := parseData(ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			if err := l.Set(i, b); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_structType:
+		id := elem.StructType().TypeId()
+		sz, err := dec.structSize(id)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		l, err := capnp.NewCompositeList(seg, sz, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			if err := dec.unmarshalStruct(id, l.Struct(i), ev); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return l, nil
+	case schema.Type_Which_list:
+		l, err := capnp.NewPointerList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range v.elems {
+			if ev.isNull() {
+				continue
+			}
+			li, err := dec.newList(seg, elem, ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			if err := l.Set(i, li.ToPtr()); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		for _, ev := range v.elems {
+			if !ev.isNull() {
+				return capnp.List{}, errors.New("cannot set a " + elem.Which().String() + " from text")
+			}
+		}
+		l, err := capnp.NewPointerList(seg, n)
+		return capnp.List(l), err
+	default:
+		return capnp.List{}, errors.New("unknown list type " + elem.Which().String())
+	}
+}
+
+func newIntList(seg *capnp.Segment, which schema.Type_Which, elems []value) (capnp.List, error) {
+	n := int32(len(elems))
+	var (
+		l   capnp.List
+		err error
+	)
+	bits := 0
+	switch which {
+	case schema.Type_Which_int8:
+		bits = 8
+		var il capnp.Int8List
+		il, err = capnp.NewInt8List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int16:
+		bits = 16
+		var il capnp.Int16List
+		il, err = capnp.NewInt16List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int32:
+		bits = 32
+		var il capnp.Int32List
+		il, err = capnp.NewInt32List(seg, n)
+		l = capnp.List(il)
+	default:
+		bits = 64
+		var il capnp.Int64List
+		il, err = capnp.NewInt64List(seg, n)
+		l = capnp.List(il)
+	}
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i, ev := range elems {
+		x, err := parseInt(ev, bits)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		switch bits {
+		case 8:
+			capnp.Int8List(l).Set(i, int8(x))
+		case 16:
+			capnp.Int16List(l).Set(i, int16(x))
+		case 32:
+			capnp.Int32List(l).Set(i, int32(x))
+		default:
+			capnp.Int64List(l).Set(i, x)
+		}
+	}
+	return l, nil
+}
+
+func newUintList(seg *capnp.Segment, which schema.Type_Which, elems []value) (capnp.List, error) {
+	n := int32(len(elems))
+	var (
+		l   capnp.List
+		err error
+	)
+	bits := 0
+	switch which {
+	case schema.Type_Which_uint8:
+		bits = 8
+		var ul capnp.UInt8List
+		ul, err = capnp.NewUInt8List(seg, n)
+		l = capnp.List(ul)
+	case schema.Type_Which_uint16:
+		bits = 16
+		var ul capnp.UInt16List
+		ul, err = capnp.NewUInt16List(seg, n)
+		l = capnp.List(ul)
+	case schema.Type_Which_uint32:
+		bits = 32
+		var ul capnp.UInt32List
+		ul, err = capnp.NewUInt32List(seg, n)
+		l = capnp.List(ul)
+	default:
+		bits = 64
+		var ul capnp.UInt64List
+		ul, err = capnp.NewUInt64List(seg, n)
+		l = capnp.List(ul)
+	}
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i, ev := range elems {
+		x, err := parseUint(ev, bits)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		switch bits {
+		case 8:
+			capnp.UInt8List(l).Set(i, uint8(x))
+		case 16:
+			capnp.UInt16List(l).Set(i, uint16(x))
+		case 32:
+			capnp.UInt32List(l).Set(i, uint32(x))
+		default:
+			capnp.UInt64List(l).Set(i, x)
+		}
+	}
+	return l, nil
+}
+
+func (dec *Decoder) parseEnum(typ uint64, v value) (uint16, error) {
+	if v.kind == numberValue {
+		u, err := parseUint(v, 16)
+		return uint16(u), err
+	}
+	if v.kind != identValue && v.kind != stringValue {
+		return 0, errors.New("cannot use " + v.describe() + " as enum")
+	}
+	n, err := dec.nodes.Find(typ)
+	if err != nil {
+		return 0, err
+	}
+	if n.Which() != schema.Node_Which_enum {
+		return 0, errors.New("type @" + str.UToHex(typ) + " is not an enum")
+	}
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < enums.Len(); i++ {
+		if name, _ := enums.At(i).Name(); name == v.s {
+			return uint16(i), nil
+		}
+	}
+	return 0, errors.New("unknown enumerant " + v.s + " of " + shortDisplayName(n))
+}
+
+func parseBool(v value) (bool, error) {
+	if v.kind == identValue {
+		switch v.s {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, errors.New("cannot use " + v.describe() + " as bool")
+}
+
+func parseInt(v value, bits int) (int64, error) {
+	if v.kind != numberValue {
+		return 0, errors.New("cannot use " + v.describe() + " as integer")
+	}
+	i, err := strconv.ParseInt(v.s, 0, bits)
+	if err != nil {
+		return 0, errors.New("invalid Int" + str.Itod(bits) + " " + v.s)
+	}
+	return i, nil
+}
+
+func parseUint(v value, bits int) (uint64, error) {
+	if v.kind != numberValue {
+		return 0, errors.New("cannot use " + v.describe() + " as integer")
+	}
+	u, err := strconv.ParseUint(strings.TrimPrefix(v.s, "+"), 0, bits)
+	if err != nil {
+		return 0, errors.New("invalid UInt" + str.Itod(bits) + " " + v.s)
+	}
+	return u, nil
+}
+
+func parseFloat(v value, bits int) (float64, error) {
+	if v.kind == stringValue {
+		// JSON has no literals for these, so Encoder writes them as
+		// strings.
+		switch v.s {
+		case "inf":
+			return math.Inf(1), nil
+		case "-inf":
+			return math.Inf(-1), nil
+		case "nan":
+			return math.NaN(), nil
+		}
+	}
+	switch {
+	case v.kind == identValue && v.s == "inf":
+		return math.Inf(1), nil
+	case v.kind == identValue && v.s == "nan":
+		return math.NaN(), nil
+	case v.kind == numberValue && v.s == "-inf":
+		return math.Inf(-1), nil
+	case v.kind != numberValue:
+		return 0, errors.New("cannot use " + v.describe() + " as float")
+	}
+	x, err := strconv.ParseFloat(v.s, bits)
+	if err != nil {
+		return 0, errors.New("invalid Float" + str.Itod(bits) + " " + v.s)
+	}
+	return x, nil
+}
+
+func parseData(v value) ([]byte, error) {
+	switch v.kind {
+	case stringValue:
+		return []byte(v.s), nil
+	case listValue:
+		b := make([]byte, len(v.elems))
+		for i, ev := range v.elems {
+			u, err := parseUint(ev, 8)
+			if err != nil {
+				return nil, err
+			}
+			b[i] = byte(u)
+		}
+		return b, nil
+	default:
+		return nil, errors.New("cannot use " + v.describe() + " as data")
+	}
+}
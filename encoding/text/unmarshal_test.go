@@ -0,0 +1,279 @@
+package text
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+const (
+	keyValueID = 0x8df8bc5abdc060a6
+	valueID    = 0xd3602730c572a43b
+)
+
+func newTestRegistry(t *testing.T) *schemas.Registry {
+	t.Helper()
+	data, err := readTestFile("txt.capnp.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := new(schemas.Registry)
+	err = reg.Register(&schemas.Schema{
+		Bytes: data,
+		Nodes: []uint64{keyValueID, valueID},
+	})
+	if err != nil {
+		t.Fatalf("Adding to registry: %v", err)
+	}
+	return reg
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		input  string
+		text   string
+	}{
+		{keyValueID, `(key = "42", value = (int32 = -123))`, ""},
+		{keyValueID, `(key = "float", value = (float64 = 3.14))`, ""},
+		{keyValueID, `(key = "bool", value = (bool = false))`, ""},
+		{valueID, `(map = [(key = "foo", value = (void = void)), (key = "bar", value = (void = void))])`, ""},
+		{valueID, `(map = [])`, ""},
+		{valueID, `(data = "Hi\xde\xad\xbe\xef\xca\xfe")`, ""},
+		{valueID, `(voidList = [void, void])`, ""},
+		{valueID, `(boolList = [true, false, true, false])`, ""},
+		{valueID, `(int8List = [1, -2, 3])`, ""},
+		{valueID, `(int64List = [1, -2, 3])`, ""},
+		{valueID, `(uint8List = [255, 0, 1])`, ""},
+		{valueID, `(uint64List = [1, 2, 3])`, ""},
+		{valueID, `(float32List = [0.5, 3.14, -2])`, ""},
+		{valueID, `(textList = ["foo", "bar", "baz"])`, ""},
+		{valueID, `(dataList = ["\xde\xad\xbe\xef", "\xca\xfe"])`, ""},
+		{valueID, `(cheese = gouda)`, ""},
+		{valueID, `(cheeseList = [gouda, cheddar])`, ""},
+		{valueID, `(matrix = [[1, 2, 3], [4, 5, 6]])`, ""},
+		{valueID, `(data = "\x00\n\"\\\xff")`, ""},
+		{valueID, `(float64 = inf)`, ""},
+		{valueID, `(float32 = -inf)`, ""},
+		{valueID, `(float64 = nan)`, ""},
+
+		// Alternate spellings.
+		{valueID, "  ( uint16 = 0x10 ) # comment\n", `(uint16 = 16)`},
+		{valueID, `(cheese = 1)`, `(cheese = gouda)`},
+		{valueID, `(data = [72, 105])`, `(data = "Hi")`},
+		{keyValueID, `(key = "a",)`, `(key = "a", value = (void = void))`},
+
+		// JSON.
+		{keyValueID, `{"key": "42", "value": {"int32": -123}}`, `(key = "42", value = (int32 = -123))`},
+		{valueID, `{"cheeseList": ["gouda", "cheddar"]}`, `(cheeseList = [gouda, cheddar])`},
+		{valueID, `{"textList": ["a\/b", "é"]}`, `(textList = ["a/b", "\xc3\xa9"])`},
+		{valueID, `{"map": [{"key": "x", "value": {"bool": true}}]}`, `(map = [(key = "x", value = (bool = true))])`},
+	}
+
+	reg := newTestRegistry(t)
+	for _, test := range tests {
+		want := test.text
+		if want == "" {
+			want = test.input
+		}
+		_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+		s, err := capnp.NewRootStruct(seg, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := NewDecoder(strings.NewReader(test.input))
+		dec.UseRegistry(reg)
+		if err := dec.Decode(test.typeID, s); err != nil {
+			t.Errorf("Decode(%#x, %q): %v", test.typeID, test.input, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		enc.UseRegistry(reg)
+		if err := enc.Encode(test.typeID, s); err != nil {
+			t.Errorf("Encode after Decode(%#x, %q): %v", test.typeID, test.input, err)
+			continue
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("Decode(%#x, %q) encodes as %q; want %q", test.typeID, test.input, got, want)
+		}
+	}
+}
+
+func TestDecodeMessage(t *testing.T) {
+	const fixture = `# A fixture, as it could be written in a file.
+(
+  key = "cheese",
+  value = (cheeseList = [gouda, cheddar]),
+)
+`
+	const want = `(key = "cheese", value = (cheeseList = [gouda, cheddar]))`
+
+	check := func(t *testing.T, dec *Decoder, reg *schemas.Registry) {
+		t.Helper()
+		msg, err := dec.DecodeMessage(keyValueID)
+		if err != nil {
+			t.Fatal("DecodeMessage:", err)
+		}
+		root, err := msg.Root()
+		if err != nil {
+			t.Fatal("Root:", err)
+		}
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		enc.UseRegistry(reg)
+		if err := enc.Encode(keyValueID, root.Struct()); err != nil {
+			t.Fatal("Encode:", err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("DecodeMessage(%q) encodes as %q; want %q", fixture, got, want)
+		}
+	}
+
+	t.Run("Registry", func(t *testing.T) {
+		reg := newTestRegistry(t)
+		dec := NewDecoder(strings.NewReader(fixture))
+		dec.UseRegistry(reg)
+		check(t, dec, reg)
+	})
+
+	t.Run("AddNodes", func(t *testing.T) {
+		data, err := readTestFile("txt.capnp.out")
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := capnp.Unmarshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := schema.ReadRootCodeGeneratorRequest(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes, err := req.Nodes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := NewDecoder(strings.NewReader(fixture))
+		dec.UseRegistry(new(schemas.Registry))
+		for i := 0; i < nodes.Len(); i++ {
+			dec.AddNodes(capnp.Struct(nodes.At(i)))
+		}
+		check(t, dec, newTestRegistry(t))
+	})
+
+	t.Run("UnknownType", func(t *testing.T) {
+		if _, err := UnmarshalMessage(keyValueID, fixture); err == nil {
+			t.Error("UnmarshalMessage with an unregistered type succeeded")
+		}
+	})
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		input  string
+	}{
+		{keyValueID, ``},
+		{keyValueID, `(key = "a"`},
+		{keyValueID, `(key = "a") extra`},
+		{keyValueID, `(nope = 1)`},
+		{keyValueID, `(key = 1)`},
+		{keyValueID, `[1, 2]`},
+		{valueID, `(int8 = 128)`},
+		{valueID, `(uint8 = -1)`},
+		{valueID, `(bool = 1)`},
+		{valueID, `(cheese = brie)`},
+		{valueID, `(int8 = 1, int16 = 2)`},
+		{valueID, `(textList = "a")`},
+		{valueID, `(text = "unterminated)`},
+	}
+
+	reg := newTestRegistry(t)
+	for _, test := range tests {
+		_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+		s, err := capnp.NewRootStruct(seg, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := NewDecoder(strings.NewReader(test.input))
+		dec.UseRegistry(reg)
+		if err := dec.Decode(test.typeID, s); err == nil {
+			t.Errorf("Decode(%#x, %q) = <nil>; want error", test.typeID, test.input)
+		}
+	}
+}
+
+func TestEncodeJSON(t *testing.T) {
+	tests := []struct {
+		typeID uint64
+		text   string
+		json   string
+	}{
+		{keyValueID, `(key = "42", value = (int32 = -123))`, `{"key": "42", "value": {"int32": -123}}`},
+		{keyValueID, `(key = "a", value = (void = void))`, `{"key": "a", "value": {"void": null}}`},
+		{keyValueID, `(key = "inf", value = (float64 = inf))`, `{"key": "inf", "value": {"float64": "inf"}}`},
+		{valueID, `(voidList = [void, void])`, `{"voidList": [null, null]}`},
+		{valueID, `(float32List = [0.5, -inf, nan])`, `{"float32List": [0.5, "-inf", "nan"]}`},
+		{valueID, `(textList = ["a\n", "\x01\xc3\xa9\"\\"])`, `{"textList": ["a\n", "\u0001é\"\\"]}`},
+		{valueID, `(data = "Hi")`, `{"data": [72, 105]}`},
+		{valueID, `(dataList = ["\xde", ""])`, `{"dataList": [[222], []]}`},
+		{valueID, `(cheeseList = [gouda, cheddar])`, `{"cheeseList": ["gouda", "cheddar"]}`},
+		{valueID, `(matrix = [[1, 2], [3]])`, `{"matrix": [[1, 2], [3]]}`},
+	}
+
+	reg := newTestRegistry(t)
+	for _, test := range tests {
+		_, seg, _ := capnp.NewMessage(capnp.SingleSegment(nil))
+		s, err := capnp.NewRootStruct(seg, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := NewDecoder(strings.NewReader(test.text))
+		dec.UseRegistry(reg)
+		if err := dec.Decode(test.typeID, s); err != nil {
+			t.Errorf("Decode(%#x, %q): %v", test.typeID, test.text, err)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		enc.UseRegistry(reg)
+		enc.UseJSON()
+		if err := enc.Encode(test.typeID, s); err != nil {
+			t.Errorf("Encode(%#x, %q) as JSON: %v", test.typeID, test.text, err)
+			continue
+		}
+		if got := buf.String(); got != test.json {
+			t.Errorf("Encode(%#x, %q) as JSON = %q; want %q", test.typeID, test.text, got, test.json)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("Encode(%#x, %q) as JSON = %q; not valid JSON", test.typeID, test.text, buf.String())
+		}
+
+		// The JSON decodes back to the same struct.
+		_, seg, _ = capnp.NewMessage(capnp.SingleSegment(nil))
+		s, _ = capnp.NewRootStruct(seg, capnp.ObjectSize{DataSize: 16, PointerCount: 2})
+		dec = NewDecoder(buf)
+		dec.UseRegistry(reg)
+		if err := dec.Decode(test.typeID, s); err != nil {
+			t.Errorf("Decode(%#x, %q): %v", test.typeID, test.json, err)
+			continue
+		}
+		again := new(bytes.Buffer)
+		enc = NewEncoder(again)
+		enc.UseRegistry(reg)
+		enc.UseJSON()
+		if err := enc.Encode(test.typeID, s); err != nil {
+			t.Errorf("Encode after Decode(%#x, %q): %v", test.typeID, test.json, err)
+			continue
+		}
+		if got := again.String(); got != test.json {
+			t.Errorf("Decode(%#x, %q) encodes as %q", test.typeID, test.json, got)
+		}
+	}
+}
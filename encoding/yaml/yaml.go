@@ -0,0 +1,246 @@
+// Package yaml decodes YAML documents into Cap'n Proto structs based on
+// a schema, so that applications can use Cap'n Proto types to represent
+// their configuration from the file on disk to the code that reads it.
+//
+// Mappings are decoded as structs and groups, keyed by field name, and
+// sequences as lists.  Fields that a document does not mention keep
+// their schema defaults.  Plain scalars are parsed according to the
+// type of the field they are stored in, so that "1.0" is read as a
+// float for Float64 fields and as text for Text fields, and enumerants
+// may be given by name or number.  Quoted and block scalars are always
+// strings, which are accepted for Text, Data and enumerant names.
+// Scalars tagged !!binary are decoded as base64 for Data fields.
+// Anchors, aliases and merge keys ("<<") are supported.
+//
+// Capabilities and AnyPointer fields can only be null.
+package yaml // import "capnproto.org/go/capnp/v3/encoding/yaml"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/dynval"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+const (
+	// maxDepth is the maximum nesting of mappings and sequences.
+	maxDepth = 100
+
+	// maxNodes is the maximum number of nodes in a document after
+	// expanding aliases, which guards against documents that alias
+	// the same nodes over and over to expand exponentially.
+	maxNodes = 1 << 20
+)
+
+// Unmarshal decodes the YAML document in data into s, which must be
+// large enough to hold a struct of the given type.  An empty document
+// leaves s unchanged.  It is an error for data to hold more than one
+// document.
+func Unmarshal(typeID uint64, s capnp.Struct, data []byte) error {
+	dec := NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(typeID, s); err != nil && err != io.EOF {
+		return err
+	}
+	var extra yamlv3.Node
+	if err := dec.dec.Decode(&extra); err != io.EOF {
+		if err != nil {
+			return errors.New("yaml: " + err.Error())
+		}
+		return errors.New("yaml: line " + str.Itod(extra.Line) + ": unexpected document after struct")
+	}
+	return nil
+}
+
+// A Decoder reads structs from a stream of YAML documents.
+type Decoder struct {
+	dec  *yamlv3.Decoder
+	conv dynval.Converter
+}
+
+// NewDecoder returns a new decoder that reads from r.  The decoder may
+// read data from r beyond the documents that it decodes.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: yamlv3.NewDecoder(r)}
+}
+
+// UseRegistry changes the registry that the decoder consults for
+// schemas from the default registry.
+func (dec *Decoder) UseRegistry(reg *schemas.Registry) {
+	dec.conv.UseRegistry(reg)
+}
+
+// Decode reads the next YAML document from the stream and stores the
+// struct it represents in s.  Fields that are not present in the
+// document are left unchanged.  Decode returns io.EOF if there are no
+// more documents.
+func (dec *Decoder) Decode(typeID uint64, s capnp.Struct) error {
+	var doc yamlv3.Node
+	if err := dec.dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return errors.New("yaml: " + err.Error())
+	}
+	c := converter{nodes: maxNodes}
+	v, err := c.value(&doc, 0)
+	if err != nil {
+		return errors.New("yaml: " + err.Error())
+	}
+	sv, ok := v.(dynval.Struct)
+	if !ok {
+		return errors.New("yaml: line " + str.Itod(doc.Line) + ": struct must be a mapping")
+	}
+	if err := dec.conv.ToStruct(typeID, s, sv); err != nil {
+		return errors.New("yaml: " + err.Error())
+	}
+	return nil
+}
+
+// A converter converts YAML nodes to generic values.
+type converter struct {
+	nodes int // remaining node budget
+}
+
+func nodeError(n *yamlv3.Node, msg string) error {
+	return errors.New("line " + str.Itod(n.Line) + ": " + msg)
+}
+
+func (c *converter) value(n *yamlv3.Node, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, nodeError(n, "document nested too deeply")
+	}
+	if c.nodes--; c.nodes < 0 {
+		return nil, nodeError(n, "document too large after expanding aliases")
+	}
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		if len(n.Content) == 0 {
+			return dynval.Struct{}, nil
+		}
+		return c.value(n.Content[0], depth)
+	case yamlv3.AliasNode:
+		return c.value(n.Alias, depth+1)
+	case yamlv3.SequenceNode:
+		l := make([]any, len(n.Content))
+		for i, e := range n.Content {
+			var err error
+			if l[i], err = c.value(e, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case yamlv3.MappingNode:
+		return c.mapping(n, depth)
+	case yamlv3.ScalarNode:
+		return scalar(n)
+	default:
+		return nil, nodeError(n, "unknown node kind")
+	}
+}
+
+// mapping converts a mapping to a struct.  Fields from merge keys come
+// first, so that the mapping's own keys override them.
+func (c *converter) mapping(n *yamlv3.Node, depth int) (dynval.Struct, error) {
+	var own, merged dynval.Struct
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind != yamlv3.ScalarNode {
+			return nil, nodeError(k, "mapping key is not a scalar")
+		}
+		if k.ShortTag() == "!!merge" {
+			m, err := c.merge(v, depth)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, m...)
+			continue
+		}
+		fv, err := c.value(v, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		own = append(own, dynval.Field{Name: k.Value, Value: fv})
+	}
+	s := make(dynval.Struct, 0, len(merged)+len(own))
+	seen := make(map[string]bool, len(own))
+	for _, f := range own {
+		seen[f.Name] = true
+	}
+	for _, f := range merged {
+		if !seen[f.Name] {
+			seen[f.Name] = true
+			s = append(s, f)
+		}
+	}
+	return append(s, own...), nil
+}
+
+// merge returns the fields of the value of a merge key: a mapping, or a
+// sequence of mappings whose earlier entries override later ones.
+func (c *converter) merge(n *yamlv3.Node, depth int) (dynval.Struct, error) {
+	if n.Kind == yamlv3.AliasNode {
+		return c.merge(n.Alias, depth+1)
+	}
+	if n.Kind == yamlv3.SequenceNode {
+		var s dynval.Struct
+		for _, e := range n.Content {
+			m, err := c.merge(e, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, m...)
+		}
+		return s, nil
+	}
+	if n.Kind != yamlv3.MappingNode {
+		return nil, nodeError(n, "merge value is not a mapping")
+	}
+	return c.mapping(n, depth+1)
+}
+
+// scalar converts a scalar node.  Plain scalars without a tag are left
+// for the converter to parse according to the type of their field.
+func scalar(n *yamlv3.Node) (any, error) {
+	const typed = yamlv3.TaggedStyle | yamlv3.DoubleQuotedStyle | yamlv3.SingleQuotedStyle |
+		yamlv3.LiteralStyle | yamlv3.FoldedStyle
+	if n.Style&typed == 0 {
+		return dynval.Untyped(n.Value), nil
+	}
+	switch n.ShortTag() {
+	case "!!str":
+		return n.Value, nil
+	case "!!binary":
+		b, err := base64.StdEncoding.DecodeString(n.Value)
+		if err != nil {
+			return nil, nodeError(n, "invalid !!binary: "+err.Error())
+		}
+		return b, nil
+	case "!!null":
+		return nil, nil
+	case "!!bool", "!!int", "!!float":
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, nodeError(n, err.Error())
+		}
+		switch v := v.(type) {
+		case int:
+			return int64(v), nil
+		case uint64:
+			return v, nil
+		case float64:
+			return v, nil
+		case bool:
+			return v, nil
+		}
+		return nil, nodeError(n, "cannot decode "+n.ShortTag()+" "+n.Value)
+	default:
+		return nil, nodeError(n, "unsupported tag "+n.Tag)
+	}
+}
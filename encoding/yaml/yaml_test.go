@@ -0,0 +1,199 @@
+package yaml_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	"capnproto.org/go/capnp/v3/encoding/yaml"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+func newStruct(t *testing.T, sz capnp.ObjectSize) capnp.Struct {
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	s, err := capnp.NewRootStruct(seg, sz)
+	require.NoError(t, err)
+	return s
+}
+
+func TestDefaults(t *testing.T) {
+	t.Parallel()
+
+	d := air.Defaults(newStruct(t, capnp.ObjectSize{DataSize: 16, PointerCount: 2}))
+	require.NoError(t, yaml.Unmarshal(air.Defaults_TypeID, capnp.Struct(d), []byte("int: 7\n")))
+	assert.Equal(t, int32(7), d.Int())
+	// Fields that are not in the document keep their defaults.
+	txt, err := d.Text()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", txt)
+	assert.Equal(t, uint32(42), d.Uint())
+
+	d = air.Defaults(newStruct(t, capnp.ObjectSize{DataSize: 16, PointerCount: 2}))
+	require.NoError(t, yaml.Unmarshal(air.Defaults_TypeID, capnp.Struct(d), nil))
+	assert.Equal(t, int32(-123), d.Int(), "empty document")
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{
+			name: "plain scalars by field type",
+			doc: `
+base:
+  name: 1.0
+  homes: [jfk, 3]
+  rating: 0x10
+  canFly: True
+  capacity: 010
+  maxSpeed: .inf
+b0: -2
+beta: [1, 2.5]
+`,
+			want: `(base = (name = "1.0", homes = [jfk, sfo], rating = 16, canFly = true, capacity = 10, maxSpeed = inf), ` +
+				`b0 = -2, beta = [1, 2.5], planes = [], ymu = 0, ysd = 0)`,
+		},
+		{
+			name: "quoted and tagged scalars",
+			doc: `
+base:
+  name: "yes"
+  homes: ["lax"]
+  rating: !!int "-5"
+  maxSpeed: !!float 3
+`,
+			want: `(base = (name = "yes", homes = [lax], rating = -5, canFly = false, capacity = 0, maxSpeed = 3), ` +
+				`b0 = 0, beta = [], planes = [], ymu = 0, ysd = 0)`,
+		},
+		{
+			name: "unions and nulls",
+			doc: `
+base: ~
+planes:
+  - b737: {base: {name: boeing}}
+  - void: null
+  - {}
+`,
+			// A null struct is written like an empty one.
+			want: `(base = (name = "", homes = [], rating = 0, canFly = false, capacity = 0, maxSpeed = 0), ` +
+				`b0 = 0, beta = [], planes = [(b737 = (base = (name = "boeing", homes = [], rating = 0, canFly = false, capacity = 0, maxSpeed = 0))), ` +
+				`(void = void), (void = void)], ymu = 0, ysd = 0)`,
+		},
+		{
+			name: "anchors and merge keys",
+			doc: `
+ymu: &mu 4
+ysd: *mu
+base:
+  <<: &common {name: shared, rating: 1, capacity: 100}
+  rating: 2
+`,
+			want: `(base = (name = "shared", homes = [], rating = 2, canFly = false, capacity = 100, maxSpeed = 0), ` +
+				`b0 = 0, beta = [], planes = [], ymu = 4, ysd = 4)`,
+		},
+	}
+	for _, test := range tests {
+		r := air.Regression(newStruct(t, capnp.ObjectSize{DataSize: 24, PointerCount: 3}))
+		if !assert.NoError(t, yaml.Unmarshal(air.Regression_TypeID, capnp.Struct(r), []byte(test.doc)), test.name) {
+			continue
+		}
+		got, err := text.Marshal(air.Regression_TypeID, capnp.Struct(r))
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got, test.name)
+	}
+}
+
+func TestUnmarshalData(t *testing.T) {
+	t.Parallel()
+
+	for _, doc := range []string{"data: hi", "data: !!binary aGk="} {
+		zd := air.Zdata(newStruct(t, capnp.ObjectSize{PointerCount: 1}))
+		require.NoError(t, yaml.Unmarshal(air.Zdata_TypeID, capnp.Struct(zd), []byte(doc)), doc)
+		b, err := zd.Data()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hi"), b, doc)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{"not a mapping", "- 1", "struct must be a mapping"},
+		{"unknown field", "bogus: 1", "unknown field bogus"},
+		{"quoted integer", `base: {rating: "1"}`, "field base: field rating: cannot use string as Int64"},
+		{"bad integer", "base: {rating: lots}", `cannot use "lots" as integer`},
+		{"unknown enumerant", "base: {homes: [nowhere]}", "unknown enumerant nowhere"},
+		{"scalar as struct", "base: 1", "as structType"},
+		{"non-scalar key", "? [a]\n: 1", "line 1: mapping key is not a scalar"},
+		{"custom tag", "b0: !celsius 3", "line 1: unsupported tag !celsius"},
+		{"two documents", "b0: 1\n---\nb0: 2\n", "line 2: unexpected document after struct"},
+		{"syntax", "b0: [1", "yaml:"},
+		{"alias bomb", aliasBomb(), "too large"},
+	}
+	for _, test := range tests {
+		r := newStruct(t, capnp.ObjectSize{DataSize: 24, PointerCount: 3})
+		err := yaml.Unmarshal(air.Regression_TypeID, r, []byte(test.doc))
+		if assert.Error(t, err, test.name) {
+			assert.Contains(t, err.Error(), test.err, test.name)
+		}
+	}
+}
+
+// aliasBomb returns a small document that expands to billions of
+// nodes.  The document fails to convert before reaching any field.
+func aliasBomb() string {
+	var sb strings.Builder
+	sb.WriteString("a0: &a0 [1, 1, 1, 1, 1, 1, 1, 1, 1, 1]\n")
+	for i := 1; i < 10; i++ {
+		prev := "*a" + string(rune('0'+i-1))
+		sb.WriteString("a" + string(rune('0'+i)) + ": &a" + string(rune('0'+i)) + " [")
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(prev)
+		}
+		sb.WriteString("]\n")
+	}
+	return sb.String()
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	dec := yaml.NewDecoder(strings.NewReader("u16: 1\n---\nu16: 2\n---\nu16: 3\n"))
+	for i := uint16(1); i <= 3; i++ {
+		_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+		require.NoError(t, err)
+		z, err := air.NewRootZ(seg)
+		require.NoError(t, err)
+		require.NoError(t, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+		assert.Equal(t, air.Z_Which_u16, z.Which())
+		assert.Equal(t, i, z.U16())
+	}
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	z, err := air.NewRootZ(seg)
+	require.NoError(t, err)
+	assert.Equal(t, io.EOF, dec.Decode(air.Z_TypeID, capnp.Struct(z)))
+}
@@ -0,0 +1,173 @@
+// Package envelope extracts routing headers from serialized messages.
+//
+// A message router often only needs a small header to decide where a
+// message goes, and forwards the message itself unchanged.  Such
+// protocols can put the header, or "envelope", in the first pointer
+// field of the root struct:
+//
+//	struct Envelope { topic @0 :Text; priority @1 :UInt8; }
+//	struct Message { envelope @0 :Envelope; body @1 :Body; }
+//
+// Read and Reader.Next then return the envelope, without reading or
+// validating anything else in the message, together with the raw bytes
+// of the message, ready to be written to the next hop as is:
+//
+//	env, raw, err := envelope.Read[foo.Envelope](data)
+//	if err != nil {
+//		return err
+//	}
+//	topic, _ := env.Topic()
+//	_, err = routes[topic].Write(raw)
+package envelope
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// The limits on the framing of messages match those of capnp.Decoder.
+const (
+	maxSegments           = 512
+	defaultMaxMessageSize = 64 << 20
+)
+
+// envelopeProj selects the first pointer of the root struct.
+var envelopeProj = capnp.NewProjection([]int{0})
+
+// Read returns the envelope of the first message in data, along with
+// the bytes of its frame in the standard stream encoding.  The frame is
+// a prefix of data, and any bytes after it are ignored.  The envelope
+// aliases the frame, which must not be modified while the envelope is
+// in use.  The envelope is the zero value if the root struct's first
+// pointer is null.
+func Read[E ~capnp.StructKind](data []byte) (env E, frame []byte, err error) {
+	n, err := frameSize(data, math.MaxUint64)
+	if err != nil {
+		return env, nil, err
+	}
+	if uint64(len(data)) < n {
+		return env, nil, errors.New("envelope: short frame")
+	}
+	frame = data[:n:n]
+	env, err = extract[E](frame)
+	if err != nil {
+		return env, nil, err
+	}
+	return env, frame, nil
+}
+
+// extract returns the envelope of the message in frame.
+func extract[E ~capnp.StructKind](frame []byte) (env E, err error) {
+	msg, err := capnp.Unmarshal(frame)
+	if err != nil {
+		return env, exc.WrapError("envelope", err)
+	}
+	res, err := envelopeProj.Read(msg)
+	if err != nil {
+		return env, exc.WrapError("envelope", err)
+	}
+	s := res[0].Struct()
+	if res[0].IsValid() && !s.IsValid() {
+		return env, errors.New("envelope: first pointer is not a struct")
+	}
+	return E(s), nil
+}
+
+// frameSize returns the total size of the frame that starts with hdr,
+// which must contain at least the frame's header.  It fails if the
+// frame is larger than max.
+func frameSize(hdr []byte, max uint64) (uint64, error) {
+	if len(hdr) < 8 {
+		return 0, errors.New("envelope: short header")
+	}
+	maxSeg := binary.LittleEndian.Uint32(hdr)
+	n, err := headerSize(maxSeg)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(hdr)) < n {
+		return 0, errors.New("envelope: short header")
+	}
+	for i := uint64(0); i <= uint64(maxSeg); i++ {
+		n += uint64(binary.LittleEndian.Uint32(hdr[4+4*i:])) * 8
+		if n > max {
+			return 0, errors.New("envelope: message too large")
+		}
+	}
+	return n, nil
+}
+
+// headerSize returns the size of the header of a frame with maxSeg+1
+// segments.  The header holds the segment count and the size of each
+// segment as 32-bit words, padded to a 64-bit boundary.
+func headerSize(maxSeg uint32) (uint64, error) {
+	if maxSeg > maxSegments {
+		return 0, errors.New("envelope: too many segments (" + str.Utod(maxSeg+1) + ")")
+	}
+	n := (uint64(maxSeg) + 2) * 4
+	return n + n%8, nil
+}
+
+// A Reader reads messages from a stream and extracts their envelopes.
+type Reader[E ~capnp.StructKind] struct {
+	r io.Reader
+
+	// MaxMessageSize is the largest frame, in bytes, that Next
+	// accepts.  If zero, 64 MiB is used.
+	MaxMessageSize uint64
+}
+
+// NewReader returns a Reader that reads messages in the standard
+// stream encoding from r.  It reads exactly the bytes of each message.
+func NewReader[E ~capnp.StructKind](r io.Reader) *Reader[E] {
+	return &Reader[E]{r: r}
+}
+
+// Next reads the next message and returns its envelope along with the
+// frame, as in Read.  Each call returns a new frame, so frames may be
+// kept after later calls.  The error is io.EOF only if no bytes were
+// read.
+func (r *Reader[E]) Next() (env E, frame []byte, err error) {
+	max := r.MaxMessageSize
+	if max == 0 {
+		max = defaultMaxMessageSize
+	}
+
+	var word [8]byte
+	if _, err := io.ReadFull(r.r, word[:]); err == io.EOF {
+		return env, nil, io.EOF
+	} else if err != nil {
+		return env, nil, exc.WrapError("envelope: read header", err)
+	}
+	// The header's size only depends on its first word.
+	hdrSize, err := headerSize(binary.LittleEndian.Uint32(word[:]))
+	if err != nil {
+		return env, nil, err
+	}
+	hdr := make([]byte, hdrSize)
+	copy(hdr, word[:])
+	if _, err := io.ReadFull(r.r, hdr[len(word):]); err != nil {
+		return env, nil, exc.WrapError("envelope: read header", err)
+	}
+	n, err := frameSize(hdr, max)
+	if err != nil {
+		return env, nil, err
+	}
+
+	frame = make([]byte, n)
+	copy(frame, hdr)
+	if _, err := io.ReadFull(r.r, frame[len(hdr):]); err != nil {
+		return env, nil, exc.WrapError("envelope: read segments", err)
+	}
+	env, err = extract[E](frame)
+	if err != nil {
+		return env, nil, err
+	}
+	return env, frame, nil
+}
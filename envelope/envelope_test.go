@@ -0,0 +1,88 @@
+package envelope_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/envelope"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+)
+
+// newMessage returns a serialized StackingRoot whose envelope, the
+// aWithDefault field, has the given num.
+func newMessage(t *testing.T, num int32) []byte {
+	msg, seg := capnp.NewMultiSegmentMessage(nil)
+	root, err := air.NewRootStackingRoot(seg)
+	require.NoError(t, err)
+	env, err := root.NewAWithDefault()
+	require.NoError(t, err)
+	env.SetNum(num)
+	a, err := root.NewA()
+	require.NoError(t, err)
+	a.SetNum(1)
+	b, err := msg.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	msg1, msg2 := newMessage(t, 42), newMessage(t, 7)
+	data := append(append([]byte(nil), msg1...), msg2...)
+
+	env, frame, err := envelope.Read[air.StackingA](data)
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), env.Num())
+	assert.Equal(t, msg1, frame)
+
+	env, frame, err = envelope.Read[air.StackingA](data[len(frame):])
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), env.Num())
+	assert.Equal(t, msg2, frame)
+
+	_, _, err = envelope.Read[air.StackingA](msg1[:len(msg1)-8])
+	assert.ErrorContains(t, err, "short frame")
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	msg1, msg2 := newMessage(t, 42), newMessage(t, 7)
+	r := envelope.NewReader[air.StackingA](io.MultiReader(bytes.NewReader(msg1), bytes.NewReader(msg2)))
+
+	env, frame, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int32(42), env.Num())
+	assert.Equal(t, msg1, frame)
+
+	env, frame, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), env.Num())
+	assert.Equal(t, msg2, frame)
+
+	// The frames are forwarded untouched.
+	m, err := capnp.Unmarshal(frame)
+	require.NoError(t, err)
+	root, err := air.ReadRootStackingRoot(m)
+	require.NoError(t, err)
+	assert.True(t, root.HasA())
+
+	_, _, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReaderMaxMessageSize(t *testing.T) {
+	t.Parallel()
+
+	msg := newMessage(t, 42)
+	r := envelope.NewReader[air.StackingA](bytes.NewReader(msg))
+	r.MaxMessageSize = uint64(len(msg) - 1)
+	_, _, err := r.Next()
+	assert.ErrorContains(t, err, "message too large")
+}
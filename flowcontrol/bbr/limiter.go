@@ -3,11 +3,15 @@ package bbr
 import (
 	"context"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/flowcontrol"
 )
 
+var _ flowcontrol.StatsReporter = &Limiter{}
+
 // A packetMeta contains metadata about a packet that was sent.
 type packetMeta struct {
 	SendTime time.Time // The time at which the packet was sent.
@@ -28,6 +32,13 @@ type sendRequest struct {
 }
 
 func (l *Limiter) StartMessage(ctx context.Context, size uint64) (gotResponse func(), err error) {
+	l.queued.messages.Add(1)
+	l.queued.bytes.Add(int64(size))
+	defer func() {
+		l.queued.messages.Add(-1)
+		l.queued.bytes.Add(-int64(size))
+	}()
+
 	replyChan := make(chan packetMeta)
 	select {
 	case <-ctx.Done():
@@ -49,6 +60,28 @@ func (l *Limiter) Release() {
 	l.cancel()
 }
 
+// Stats returns the current state of the limiter.  The window is the
+// congestion window, which is zero until the limiter has collected
+// enough samples to estimate it.  Once the limiter has been released,
+// only the queued counts are reported.
+func (l *Limiter) Stats() flowcontrol.Stats {
+	st := flowcontrol.Stats{
+		QueuedMessages: uint64(l.queued.messages.Load()),
+		QueuedBytes:    uint64(l.queued.bytes.Load()),
+	}
+	select {
+	case l.chPause <- struct{}{}:
+		st.InflightMessages = l.packetsInflight
+		st.InflightBytes = l.inflight()
+		if bdp := l.computeBDP(); bdp > 0 {
+			st.Window = uint64(l.cwndGain * bdp)
+		}
+		<-l.chPause
+	case <-l.ctx.Done():
+	}
+	return st
+}
+
 // Compute the bandwidth delay product, in bytes.
 func (l *Limiter) computeBDP() float64 {
 	bandwidth := l.btlBwFilter.Estimate
@@ -207,7 +240,18 @@ type Limiter struct {
 	clock clock.Clock
 
 	// This channel is used for testing; the whilePaused() method needs it.
+	// Stats() uses it too.
 	chPause chan struct{}
+
+	// Callers blocked in StartMessage. This is a pointer so that
+	// snapshots can copy the Limiter.
+	queued *queueStats
+}
+
+// queueStats counts the callers blocked in StartMessage, and the total
+// size of their messages.
+type queueStats struct {
+	messages, bytes atomic.Int64
 }
 
 // For testing purpoes; temporarily pauses the goroutine managing the limiter,
@@ -252,6 +296,7 @@ func NewLimiter(clk clock.Clock) *Limiter {
 		maxPacketsInflight: math.MaxUint64,
 
 		chPause: make(chan struct{}),
+		queued:  &queueStats{},
 	}
 	l.changeState(&startupState{})
 	go l.run(ctx)
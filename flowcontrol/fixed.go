@@ -17,8 +17,9 @@ func NewFixedLimiter(size int64) FlowLimiter {
 }
 
 type fixedLimiter struct {
-	size int64
-	sem  *semaphore.Weighted
+	size  int64
+	sem   *semaphore.Weighted
+	stats flowStats
 }
 
 func (fl *fixedLimiter) StartMessage(ctx context.Context, size uint64) (gotResponse func(), err error) {
@@ -31,11 +32,23 @@ func (fl *fixedLimiter) StartMessage(ctx context.Context, size uint64) (gotRespo
 			" is too large (max " + str.Itod(fl.size) + ")")
 	}
 
-	if err = fl.sem.Acquire(ctx, int64(size)); err == nil {
-		gotResponse = func() { fl.sem.Release(int64(size)) }
+	fl.stats.queue(size)
+	err = fl.sem.Acquire(ctx, int64(size))
+	fl.stats.dequeue(size, err == nil)
+	if err == nil {
+		gotResponse = func() {
+			fl.stats.done(size)
+			fl.sem.Release(int64(size))
+		}
 	}
 
 	return
 }
 
-func (fixedLimiter) Release() {}
+func (fl *fixedLimiter) Stats() Stats {
+	st := fl.stats.snapshot()
+	st.Window = uint64(fl.size)
+	return st
+}
+
+func (*fixedLimiter) Release() {}
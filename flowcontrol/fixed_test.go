@@ -44,8 +44,19 @@ func TestFixed(t *testing.T) {
 		_, err = lim.StartMessage(ctxTimeout, 4)
 		assert.ErrorIs(t, err, context.DeadlineExceeded, "should return context error")
 	}()
+	st, ok := StatsOf(lim)
+	require.True(t, ok, "limiter should report stats")
+	assert.Equal(t, Stats{
+		Window:           10,
+		InflightMessages: 2,
+		InflightBytes:    7,
+	}, st)
+
 	got6()
 	got1()
+
+	st, _ = StatsOf(lim)
+	assert.Equal(t, Stats{Window: 10}, st)
 }
 
 func TestFixeLimiterPanics(t *testing.T) {
@@ -11,6 +11,8 @@
 //
 // To change the default flow control policy on a Client, call Client.SetFlowLimiter
 // with the desired FlowLimiter.
+// For example, NewMaxInflightLimiter bounds the number of concurrent calls on
+// a single capability.  Use StatsOf to inspect the state of a FlowLimiter.
 package flowcontrol
 
 import (
@@ -32,3 +34,42 @@ type FlowLimiter interface {
 	// Release releases any resources used by the FlowLimiter.
 	Release()
 }
+
+// Stats describes the state of a FlowLimiter at a point in time.
+type Stats struct {
+	// Window is the number of bytes the limiter currently allows to be
+	// in flight, or zero if it does not limit bytes in flight.
+	Window uint64
+
+	// MaxInflightMessages is the number of messages the limiter allows
+	// to be in flight, or zero if it does not limit messages in flight.
+	MaxInflightMessages uint64
+
+	// Messages, and their total size in bytes, for which StartMessage
+	// has returned but gotResponse has not yet been called.
+	InflightMessages uint64
+	InflightBytes    uint64
+
+	// Messages, and their total size in bytes, for which StartMessage
+	// is blocked waiting for permission to send.
+	QueuedMessages uint64
+	QueuedBytes    uint64
+}
+
+// A StatsReporter is a FlowLimiter that can report its current state.
+// All of the FlowLimiters in this module implement StatsReporter.
+type StatsReporter interface {
+	FlowLimiter
+
+	// Stats returns the current state of the limiter.  It must be safe
+	// to call from multiple goroutines.
+	Stats() Stats
+}
+
+// StatsOf returns the current state of lim, if lim is a StatsReporter.
+func StatsOf(lim FlowLimiter) (_ Stats, ok bool) {
+	if r, ok := lim.(StatsReporter); ok {
+		return r.Stats(), true
+	}
+	return Stats{}, false
+}
@@ -0,0 +1,45 @@
+package flowcontrol
+
+import (
+	"context"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// NewMaxInflightLimiter returns a FlowLimiter that allows at most
+// maxCalls messages to be outstanding at once, regardless of their size.
+// Since flow limiters are configured per client with Client.SetFlowLimiter,
+// this bounds the number of concurrent calls on a single capability.
+func NewMaxInflightLimiter(maxCalls int64) FlowLimiter {
+	return &inflightLimiter{
+		max: maxCalls,
+		sem: semaphore.NewWeighted(maxCalls),
+	}
+}
+
+type inflightLimiter struct {
+	max   int64
+	sem   *semaphore.Weighted
+	stats flowStats
+}
+
+func (l *inflightLimiter) StartMessage(ctx context.Context, size uint64) (gotResponse func(), err error) {
+	l.stats.queue(size)
+	err = l.sem.Acquire(ctx, 1)
+	l.stats.dequeue(size, err == nil)
+	if err == nil {
+		gotResponse = func() {
+			l.stats.done(size)
+			l.sem.Release(1)
+		}
+	}
+	return
+}
+
+func (l *inflightLimiter) Stats() Stats {
+	st := l.stats.snapshot()
+	st.MaxInflightMessages = uint64(l.max)
+	return st
+}
+
+func (*inflightLimiter) Release() {}
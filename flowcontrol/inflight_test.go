@@ -0,0 +1,58 @@
+package flowcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxInflight(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	lim := NewMaxInflightLimiter(2)
+	defer lim.Release()
+
+	// Sizes don't matter, only the number of messages:
+	got1, err := lim.StartMessage(ctx, 1000)
+	require.NoError(t, err, "Limiter returned an error")
+	got2, err := lim.StartMessage(ctx, 1)
+	require.NoError(t, err, "Limiter returned an error")
+
+	st, ok := StatsOf(lim)
+	require.True(t, ok, "limiter should report stats")
+	assert.Equal(t, Stats{
+		MaxInflightMessages: 2,
+		InflightMessages:    2,
+		InflightBytes:       1001,
+	}, st)
+
+	// A third message has to wait for one of the others:
+	started := make(chan func())
+	go func() {
+		got3, err := lim.StartMessage(ctx, 5)
+		assert.NoError(t, err, "Limiter returned an error")
+		started <- got3
+	}()
+	assert.Eventually(t, func() bool {
+		st, _ := StatsOf(lim)
+		return st.QueuedMessages == 1 && st.QueuedBytes == 5
+	}, time.Second, time.Millisecond, "third message should be queued")
+
+	got1()
+	got3 := <-started
+	st, _ = StatsOf(lim)
+	assert.Equal(t, Stats{
+		MaxInflightMessages: 2,
+		InflightMessages:    2,
+		InflightBytes:       6,
+	}, st)
+
+	got2()
+	got3()
+	st, _ = StatsOf(lim)
+	assert.Equal(t, Stats{MaxInflightMessages: 2}, st)
+}
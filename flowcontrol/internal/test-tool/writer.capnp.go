@@ -16,6 +16,10 @@ type Writer capnp.Client
 // Writer_TypeID is the unique identifier for the type Writer.
 const Writer_TypeID = 0xf82e58b4a78f136b
 
+// Writer_Fingerprint is a hash of the schema of Writer's methods,
+// used to detect peers built from a different version of the schema.
+const Writer_Fingerprint = 0x568f370d8fc59c69
+
 func (c Writer) Write(ctx context.Context, params func(Writer_write_Params) error) (Writer_write_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -112,6 +116,16 @@ type Writer_Server interface {
 	Write(context.Context, Writer_write) error
 }
 
+// Writer_UnimplementedServer can be embedded in an implementation of
+// Writer_Server, including those of interfaces that extend Writer, to fail
+// the methods it does not define with an unimplemented exception.
+type Writer_UnimplementedServer struct{}
+
+// Write returns an unimplemented exception.
+func (Writer_UnimplementedServer) Write(context.Context, Writer_write) error {
+	return capnp.Unimplemented("unimplemented method writer.capnp:Writer.write")
+}
+
 // Writer_NewServer creates a new Server from an implementation of Writer_Server.
 func Writer_NewServer(s Writer_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -124,6 +138,19 @@ func Writer_ServerToClient(s Writer_Server) Writer {
 	return Writer(capnp.NewClient(Writer_NewServer(s)))
 }
 
+// Writer_NewServerWithOptions is like Writer_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func Writer_NewServerWithOptions(s Writer_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(Writer_Methods(nil, s), s, c, opts)
+}
+
+// Writer_ServerToClientWithOptions is like Writer_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func Writer_ServerToClientWithOptions(s Writer_Server, opts *server.Options) Writer {
+	return Writer(capnp.NewClient(Writer_NewServerWithOptions(s, opts)))
+}
+
 // Writer_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func Writer_Methods(methods []server.Method, s Writer_Server) []server.Method {
@@ -166,12 +193,29 @@ func (c Writer_write) AllocResults() (Writer_write_Results, error) {
 // Writer_List is a list of Writer.
 type Writer_List = capnp.CapList[Writer]
 
-// NewWriter creates a new list of Writer.
+// NewWriter_List creates a new list of Writer.
 func NewWriter_List(s *capnp.Segment, sz int32) (Writer_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[Writer](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xf82e58b4a78f136b,
+				MethodID:      0,
+				InterfaceName: "writer.capnp:Writer",
+				MethodName:    "write",
+			},
+			ParamsTypeID:  0x80b8cd5f44e3c477,
+			ResultsTypeID: 0xd939de8c6024e7f8,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 0},
+		},
+	)
+	capnp.RegisterFingerprint(Writer_TypeID, Writer_Fingerprint)
+}
+
 type Writer_write_Params capnp.Struct
 
 // Writer_write_Params_TypeID is the unique identifier for the type Writer_write_Params.
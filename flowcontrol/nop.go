@@ -17,3 +17,8 @@ func (nopLimiter) StartMessage(context.Context, uint64) (func(), error) {
 }
 
 func (nopLimiter) Release() {}
+
+// Stats always returns the zero Stats, since nopLimiter keeps no state.
+func (nopLimiter) Stats() Stats {
+	return Stats{}
+}
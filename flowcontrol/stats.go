@@ -0,0 +1,45 @@
+package flowcontrol
+
+import (
+	"sync/atomic"
+)
+
+// flowStats keeps the inflight and queued counts reported by Stats.
+// The zero value is ready to use.
+type flowStats struct {
+	inflightMessages, inflightBytes atomic.Int64
+	queuedMessages, queuedBytes     atomic.Int64
+}
+
+// queue records that a message of the given size is waiting to be sent.
+func (s *flowStats) queue(size uint64) {
+	s.queuedMessages.Add(1)
+	s.queuedBytes.Add(int64(size))
+}
+
+// dequeue undoes queue.  If sent is true, the message is recorded as
+// being in flight.
+func (s *flowStats) dequeue(size uint64, sent bool) {
+	s.queuedMessages.Add(-1)
+	s.queuedBytes.Add(-int64(size))
+	if sent {
+		s.inflightMessages.Add(1)
+		s.inflightBytes.Add(int64(size))
+	}
+}
+
+// done records that a response was received for a message in flight.
+func (s *flowStats) done(size uint64) {
+	s.inflightMessages.Add(-1)
+	s.inflightBytes.Add(-int64(size))
+}
+
+// snapshot returns the counts, leaving the limits in the result unset.
+func (s *flowStats) snapshot() Stats {
+	return Stats{
+		InflightMessages: uint64(s.inflightMessages.Load()),
+		InflightBytes:    uint64(s.inflightBytes.Load()),
+		QueuedMessages:   uint64(s.queuedMessages.Load()),
+		QueuedBytes:      uint64(s.queuedBytes.Load()),
+	}
+}
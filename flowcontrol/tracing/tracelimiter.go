@@ -8,7 +8,7 @@ import (
 	"capnproto.org/go/capnp/v3/flowcontrol"
 )
 
-var _ flowcontrol.FlowLimiter = &TraceLimiter{}
+var _ flowcontrol.StatsReporter = &TraceLimiter{}
 
 // A TraceLimiter wraps an underlying FlowLimiter, and records data about messages.
 type TraceLimiter struct {
@@ -53,6 +53,13 @@ func (l *TraceLimiter) StartMessage(ctx context.Context, size uint64) (gotRespon
 	}, nil
 }
 
+// Stats returns the Stats of the underlying flow limiter, or the zero
+// Stats if it does not implement flowcontrol.StatsReporter.
+func (l *TraceLimiter) Stats() flowcontrol.Stats {
+	st, _ := flowcontrol.StatsOf(l.underlying)
+	return st
+}
+
 // Release releases the underlying flow limiter.
 func (l *TraceLimiter) Release() {
 	l.underlying.Release()
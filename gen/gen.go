@@ -0,0 +1,426 @@
+// Package gen generates random Cap'n Proto messages from schemas, for
+// property-based testing and fuzzing of code that handles them.
+//
+// Generated messages are valid for their schema: every union has a
+// single member set, enums hold declared enumerants, and nesting, list
+// lengths and text lengths are bounded.  Capabilities are only filled in
+// if Options.Caps is set.  A Generator with a seeded *rand.Rand produces
+// the same messages each time, which makes failures reproducible:
+//
+//	g := gen.New(rand.New(rand.NewSource(seed)), nil)
+//	msg, root, err := g.Message(node)
+package gen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/schemas"
+	stdschema "capnproto.org/go/capnp/v3/std/capnp/schema"
+)
+
+// Options bound the messages produced by a Generator.
+type Options struct {
+	// Registry holds the schemas of the generated types and of the
+	// types they refer to.  If nil, schemas.DefaultRegistry is used.
+	Registry *schemas.Registry
+
+	// MaxDepth is the maximum nesting of structs and lists below the
+	// root struct.  Pointer fields deeper than this are left null.  If
+	// zero, 4 is used.
+	MaxDepth int
+
+	// MaxListLen is the maximum number of elements in a list.  If zero,
+	// 8 is used.
+	MaxListLen int
+
+	// MaxTextLen is the maximum length, in bytes, of Text and Data
+	// values.  If zero, 16 is used.
+	MaxTextLen int
+
+	// NullPointers, if true, leaves some pointer fields null at
+	// random, as senders that do not set every field would.
+	NullPointers bool
+
+	// Caps, if not nil, is called for each interface field or list
+	// element, with the interface's type ID, to get a capability to
+	// put there.  Returning the null client leaves the field null.  The
+	// message takes ownership of the returned client.  If Caps is nil,
+	// interface fields are left null.
+	Caps func(interfaceID uint64) capnp.Client
+}
+
+// A Generator produces random messages.  It is not safe to use from
+// multiple goroutines.
+type Generator struct {
+	rand  *rand.Rand
+	opts  Options
+	nodes nodemap.Map
+}
+
+// New returns a Generator that draws its randomness from r.  If opts is
+// nil, the defaults are used.
+func New(r *rand.Rand, opts *Options) *Generator {
+	g := &Generator{rand: r}
+	if opts != nil {
+		g.opts = *opts
+	}
+	if g.opts.MaxDepth <= 0 {
+		g.opts.MaxDepth = 4
+	}
+	if g.opts.MaxListLen <= 0 {
+		g.opts.MaxListLen = 8
+	}
+	if g.opts.MaxTextLen <= 0 {
+		g.opts.MaxTextLen = 16
+	}
+	if g.opts.Registry != nil {
+		g.nodes.UseRegistry(g.opts.Registry)
+	}
+	return g
+}
+
+// Message returns a new message whose root is a random struct of the
+// type described by node.
+func (g *Generator) Message(node stdschema.Node) (*capnp.Message, capnp.Struct, error) {
+	msg, seg, err := capnp.NewMessage(capnp.MultiSegment(nil))
+	if err != nil {
+		return nil, capnp.Struct{}, err
+	}
+	s, err := g.Struct(seg, node)
+	if err != nil {
+		return nil, capnp.Struct{}, err
+	}
+	if err := msg.SetRoot(s.ToPtr()); err != nil {
+		return nil, capnp.Struct{}, err
+	}
+	return msg, s, nil
+}
+
+// MessageByID is like Message, but looks up the struct type by ID in
+// Options.Registry.
+func (g *Generator) MessageByID(id uint64) (*capnp.Message, capnp.Struct, error) {
+	n, err := g.nodes.Find(id)
+	if err != nil {
+		return nil, capnp.Struct{}, fmt.Errorf("gen: %v", err)
+	}
+	return g.Message(stdschema.Node(capnp.Struct(n)))
+}
+
+// Struct allocates a random struct of the type described by node in
+// seg.
+func (g *Generator) Struct(seg *capnp.Segment, node stdschema.Node) (capnp.Struct, error) {
+	n := schema.Node(capnp.Struct(node))
+	if n.Which() != schema.Node_Which_structNode {
+		return capnp.Struct{}, fmt.Errorf("gen: node %#x is a %v, not a struct", n.Id(), n.Which())
+	}
+	s, err := capnp.NewStruct(seg, structSize(n))
+	if err != nil {
+		return capnp.Struct{}, fmt.Errorf("gen: %v", err)
+	}
+	if err := g.fill(s, n, 0); err != nil {
+		return capnp.Struct{}, fmt.Errorf("gen: %s: %v", displayName(n), err)
+	}
+	return s, nil
+}
+
+func structSize(n schema.Node) capnp.ObjectSize {
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructNode().DataWordCount()) * 8,
+		PointerCount: n.StructNode().PointerCount(),
+	}
+}
+
+func displayName(n schema.Node) string {
+	name, err := n.DisplayName()
+	if err != nil || name == "" {
+		return fmt.Sprintf("@%#x", n.Id())
+	}
+	return name
+}
+
+// fill sets the fields of s, including the members of groups, which
+// are stored in s too.  depth is the nesting of s below the root.
+func (g *Generator) fill(s capnp.Struct, n schema.Node, depth int) error {
+	st := n.StructNode()
+	fields, err := st.Fields()
+	if err != nil {
+		return err
+	}
+
+	// Pick the union member to set, if there is a union.
+	var union []int
+	for i := 0; i < fields.Len(); i++ {
+		if fields.At(i).DiscriminantValue() != schema.Field_noDiscriminant {
+			union = append(union, i)
+		}
+	}
+	chosen := -1
+	if len(union) > 0 {
+		chosen = union[g.rand.Intn(len(union))]
+		d := fields.At(chosen).DiscriminantValue()
+		s.SetUint16(capnp.DataOffset(st.DiscriminantOffset()*2), d)
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if f.DiscriminantValue() != schema.Field_noDiscriminant && i != chosen {
+			continue
+		}
+		name, _ := f.Name()
+		if err := g.field(s, f, depth); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) field(s capnp.Struct, f schema.Field, depth int) error {
+	switch f.Which() {
+	case schema.Field_Which_group:
+		n, err := g.nodes.Find(f.Group().TypeId())
+		if err != nil {
+			return err
+		}
+		return g.fill(s, n, depth)
+	case schema.Field_Which_slot:
+	default:
+		return nil
+	}
+
+	slot := f.Slot()
+	t, err := slot.Type()
+	if err != nil {
+		return err
+	}
+	def, err := slot.DefaultValue()
+	if err != nil {
+		return err
+	}
+	off := slot.Offset()
+	switch t.Which() {
+	case schema.Type_Which_void:
+	case schema.Type_Which_bool:
+		s.SetBit(capnp.BitOffset(off), g.rand.Intn(2) == 1)
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		s.SetUint8(capnp.DataOffset(off), uint8(g.rand.Uint32()))
+	case schema.Type_Which_int16, schema.Type_Which_uint16:
+		s.SetUint16(capnp.DataOffset(off*2), uint16(g.rand.Uint32()))
+	case schema.Type_Which_int32, schema.Type_Which_uint32:
+		s.SetUint32(capnp.DataOffset(off*4), g.rand.Uint32())
+	case schema.Type_Which_int64, schema.Type_Which_uint64:
+		s.SetUint64(capnp.DataOffset(off*8), g.rand.Uint64())
+	case schema.Type_Which_float32:
+		v := math.Float32bits(float32(g.rand.NormFloat64()))
+		s.SetUint32(capnp.DataOffset(off*4), v^math.Float32bits(def.Float32()))
+	case schema.Type_Which_float64:
+		v := math.Float64bits(g.rand.NormFloat64())
+		s.SetUint64(capnp.DataOffset(off*8), v^math.Float64bits(def.Float64()))
+	case schema.Type_Which_enum:
+		v, err := g.enum(t.Enum().TypeId())
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), v^def.Enum())
+	default:
+		if g.skipPointer(depth) {
+			return nil
+		}
+		p, err := g.pointer(s.Segment(), t, depth+1)
+		if err != nil {
+			return err
+		}
+		return s.SetPtr(uint16(off), p)
+	}
+	return nil
+}
+
+// skipPointer reports whether to leave a pointer field at depth null.
+func (g *Generator) skipPointer(depth int) bool {
+	if depth >= g.opts.MaxDepth {
+		return true
+	}
+	return g.opts.NullPointers && g.rand.Intn(4) == 0
+}
+
+// enum returns a random enumerant of the enum with the given ID.
+func (g *Generator) enum(id uint64) (uint16, error) {
+	n, err := g.nodes.Find(id)
+	if err != nil {
+		return 0, err
+	}
+	es, err := n.Enum().Enumerants()
+	if err != nil {
+		return 0, err
+	}
+	if es.Len() == 0 {
+		return 0, nil
+	}
+	return uint16(g.rand.Intn(es.Len())), nil
+}
+
+// pointer returns a random value of the pointer type t, allocated in
+// seg.  depth is the nesting of the value below the root.
+func (g *Generator) pointer(seg *capnp.Segment, t schema.Type, depth int) (capnp.Ptr, error) {
+	switch t.Which() {
+	case schema.Type_Which_text:
+		v, err := capnp.NewText(seg, g.text())
+		return v.ToPtr(), err
+	case schema.Type_Which_data:
+		v, err := capnp.NewData(seg, g.data())
+		return v.ToPtr(), err
+	case schema.Type_Which_structType:
+		n, err := g.nodes.Find(t.StructType().TypeId())
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		s, err := capnp.NewStruct(seg, structSize(n))
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		if err := g.fill(s, n, depth); err != nil {
+			return capnp.Ptr{}, fmt.Errorf("%s: %v", displayName(n), err)
+		}
+		return s.ToPtr(), nil
+	case schema.Type_Which_interface:
+		return g.capability(seg, t.Interface().TypeId()), nil
+	case schema.Type_Which_list:
+		et, err := t.List().ElementType()
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		return g.list(seg, et, depth)
+	default:
+		// AnyPointer: there is no type to generate.
+		return capnp.Ptr{}, nil
+	}
+}
+
+// capability returns a pointer to a capability from Options.Caps, or
+// a null pointer.
+func (g *Generator) capability(seg *capnp.Segment, id uint64) capnp.Ptr {
+	if g.opts.Caps == nil {
+		return capnp.Ptr{}
+	}
+	c := g.opts.Caps(id)
+	if !c.IsValid() {
+		return capnp.Ptr{}
+	}
+	capID := seg.Message().CapTable().Add(c)
+	return capnp.NewInterface(seg, capID).ToPtr()
+}
+
+// list returns a random list of elements of type et.
+func (g *Generator) list(seg *capnp.Segment, et schema.Type, depth int) (capnp.Ptr, error) {
+	n := int32(g.rand.Intn(g.opts.MaxListLen + 1))
+	switch et.Which() {
+	case schema.Type_Which_void:
+		return capnp.NewVoidList(seg, n).ToPtr(), nil
+	case schema.Type_Which_bool:
+		l, err := capnp.NewBitList(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, g.rand.Intn(2) == 1)
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		l, err := capnp.NewUInt8List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, uint8(g.rand.Uint32()))
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_int16, schema.Type_Which_uint16:
+		l, err := capnp.NewUInt16List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, uint16(g.rand.Uint32()))
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_int32, schema.Type_Which_uint32:
+		l, err := capnp.NewUInt32List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, g.rand.Uint32())
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_int64, schema.Type_Which_uint64:
+		l, err := capnp.NewUInt64List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, g.rand.Uint64())
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_float32:
+		l, err := capnp.NewFloat32List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, float32(g.rand.NormFloat64()))
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_float64:
+		l, err := capnp.NewFloat64List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			l.Set(i, g.rand.NormFloat64())
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_enum:
+		l, err := capnp.NewUInt16List(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			var v uint16
+			v, err = g.enum(et.Enum().TypeId())
+			l.Set(i, v)
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_text:
+		l, err := capnp.NewTextList(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			err = l.Set(i, g.text())
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_data:
+		l, err := capnp.NewDataList(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			err = l.Set(i, g.data())
+		}
+		return l.ToPtr(), err
+	case schema.Type_Which_structType:
+		node, err := g.nodes.Find(et.StructType().TypeId())
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		l, err := capnp.NewCompositeList(seg, structSize(node), n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			err = g.fill(l.Struct(i), node, depth)
+		}
+		return l.ToPtr(), err
+	default:
+		// Lists of lists, interfaces and AnyPointers.
+		l, err := capnp.NewPointerList(seg, n)
+		for i := 0; err == nil && i < l.Len(); i++ {
+			if g.skipPointer(depth) {
+				continue
+			}
+			var p capnp.Ptr
+			p, err = g.pointer(seg, et, depth+1)
+			if err == nil {
+				err = l.Set(i, p)
+			}
+		}
+		return l.ToPtr(), err
+	}
+}
+
+// text returns random printable ASCII text.
+func (g *Generator) text() string {
+	b := make([]byte, g.rand.Intn(g.opts.MaxTextLen+1))
+	for i := range b {
+		b[i] = byte(' ' + g.rand.Intn('~'-' '+1))
+	}
+	return string(b)
+}
+
+// data returns random bytes.
+func (g *Generator) data() []byte {
+	b := make([]byte, g.rand.Intn(g.opts.MaxTextLen+1))
+	g.rand.Read(b)
+	return b
+}
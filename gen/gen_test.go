@@ -0,0 +1,125 @@
+package gen_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	"capnproto.org/go/capnp/v3/gen"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func newRegistry() *schemas.Registry {
+	reg := new(schemas.Registry)
+	air.RegisterSchema(reg)
+	return reg
+}
+
+func TestMessageIsValid(t *testing.T) {
+	t.Parallel()
+
+	reg := newRegistry()
+	var caps int
+	g := gen.New(rand.New(rand.NewSource(1)), &gen.Options{
+		Registry:     reg,
+		NullPointers: true,
+		Caps: func(id uint64) capnp.Client {
+			assert.Equal(t, uint64(air.Echo_TypeID), id)
+			caps++
+			return capnp.ErrorClient(errors.New("test capability"))
+		},
+	})
+	seen := make(map[air.Z_Which]bool)
+	for i := 0; i < 500; i++ {
+		msg, root, err := g.MessageByID(air.Z_TypeID)
+		require.NoError(t, err)
+		z := air.Z(root)
+		seen[z.Which()] = true
+		assert.NotContains(t, z.Which().String(), "Z_Which(", "union member is valid")
+		if z.Which() == air.Z_Which_airport {
+			assert.NotContains(t, z.Airport().String(), "Airport(", "enumerant is valid")
+		}
+
+		// The message survives a round trip and can be printed.
+		b, err := msg.Marshal()
+		require.NoError(t, err)
+		msg2, err := capnp.Unmarshal(b)
+		require.NoError(t, err)
+		p, err := msg2.Root()
+		require.NoError(t, err)
+		enc := text.NewEncoder(io.Discard)
+		enc.UseRegistry(reg)
+		require.NoError(t, enc.Encode(air.Z_TypeID, p.Struct()), "message %d", i)
+		msg.Release()
+	}
+	assert.Greater(t, len(seen), 30, "union members are spread out")
+	assert.Greater(t, caps, 0, "interface fields are filled in with Caps")
+}
+
+func TestDeterministic(t *testing.T) {
+	t.Parallel()
+
+	reg := newRegistry()
+	generate := func() []byte {
+		g := gen.New(rand.New(rand.NewSource(42)), &gen.Options{Registry: reg})
+		var out []byte
+		for i := 0; i < 20; i++ {
+			msg, _, err := g.MessageByID(air.Z_TypeID)
+			require.NoError(t, err)
+			b, err := msg.Marshal()
+			require.NoError(t, err)
+			out = append(out, b...)
+		}
+		return out
+	}
+	assert.True(t, bytes.Equal(generate(), generate()), "same seed gives the same messages")
+}
+
+func TestBounds(t *testing.T) {
+	t.Parallel()
+
+	reg := newRegistry()
+	g := gen.New(rand.New(rand.NewSource(7)), &gen.Options{
+		Registry:   reg,
+		MaxDepth:   1,
+		MaxListLen: 3,
+		MaxTextLen: 5,
+	})
+	for i := 0; i < 200; i++ {
+		_, root, err := g.MessageByID(air.Z_TypeID)
+		require.NoError(t, err)
+		z := air.Z(root)
+		switch z.Which() {
+		case air.Z_Which_text:
+			s, err := z.Text()
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(s), 5)
+		case air.Z_Which_zvec:
+			l, err := z.Zvec()
+			require.NoError(t, err)
+			assert.LessOrEqual(t, l.Len(), 3)
+			for j := 0; j < l.Len(); j++ {
+				if l.At(j).Which() == air.Z_Which_zz {
+					assert.False(t, l.At(j).HasZz(), "pointers below MaxDepth are null")
+				}
+			}
+		case air.Z_Which_zz:
+			zz, err := z.Zz()
+			require.NoError(t, err)
+			if zz.Which() == air.Z_Which_zz {
+				assert.False(t, zz.HasZz(), "pointers below MaxDepth are null")
+			}
+		}
+	}
+
+	_, _, err := g.MessageByID(air.Echo_TypeID)
+	assert.Error(t, err, "interfaces are not structs")
+}
@@ -0,0 +1,212 @@
+// Package httpgw serves Cap'n Proto methods as JSON over HTTP, so that
+// REST clients can call capnp services without a separate gateway.
+//
+// Routes are usually generated by capnpc-go when run with -http, from
+// methods annotated with $Go.http:
+//
+//	interface Users {
+//		get @0 (id :UInt64) -> (user :User) $Go.http("POST /v1/users/get");
+//	}
+//
+// which generates Users_HTTPRoutes and Users_RegisterHTTP.  The
+// handlers encode and decode JSON using the schemas in
+// schemas.DefaultRegistry, so the generated package's schemas must be
+// registered there:
+//
+//	foo.RegisterSchema(schemas.DefaultRegistry)
+//	mux := http.NewServeMux()
+//	foo.Users_RegisterHTTP(mux, users)
+//
+// A request's body is the JSON representation of the method's
+// parameters, as read by package capnproto.org/go/capnp/v3/encoding/text;
+// an empty body passes the default parameters.  Paths are matched
+// exactly: path templates and query parameters are not mapped to
+// parameters.  The response is the JSON representation of the results,
+// or an object with an "error" field and a status code that reflects
+// the exception type.
+//
+// OpenAPI describes routes with an OpenAPI document, for use by tools
+// and client generators that do not speak Cap'n Proto.
+package httpgw // import "capnproto.org/go/capnp/v3/httpgw"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/encoding/text"
+	"capnproto.org/go/capnp/v3/exc"
+)
+
+// MaxBodySize is the largest request body that a handler reads.
+const MaxBodySize = 1 << 20
+
+// A Route exposes a method over HTTP.
+type Route struct {
+	// HTTPMethod and Path are the HTTP method and the exact path that
+	// the route serves.
+	HTTPMethod string
+	Path       string
+
+	// Method is the capnp method that the route calls.
+	Method capnp.Method
+
+	// ParamsID and ResultsID are the type IDs of the method's
+	// parameter and result structs.  Their schemas must be registered
+	// in schemas.DefaultRegistry.
+	ParamsID  uint64
+	ResultsID uint64
+
+	// ParamsSize is the size of the parameter struct.
+	ParamsSize capnp.ObjectSize
+}
+
+// ParseRule splits the value of a $Go.http annotation, such as
+// "POST /v1/users", into an HTTP method and a path.
+func ParseRule(rule string) (httpMethod, path string, err error) {
+	httpMethod, path, ok := strings.Cut(strings.TrimSpace(rule), " ")
+	path = strings.TrimSpace(path)
+	if !ok || httpMethod == "" || !strings.HasPrefix(path, "/") {
+		return "", "", errors.New("httpgw: invalid rule " + strings.TrimSpace(rule) + `; want "METHOD /path"`)
+	}
+	return strings.ToUpper(httpMethod), path, nil
+}
+
+// Register adds handlers for routes to mux that call methods on c.
+// Routes with the same path share a handler that dispatches on the
+// HTTP method.  Like http.ServeMux.Handle, Register panics if a path
+// is already registered.  Register does not take ownership of c; it
+// must stay valid for as long as mux serves requests.
+func Register(mux *http.ServeMux, c capnp.Client, routes []Route) {
+	byPath := make(map[string][]Route)
+	var paths []string
+	for _, r := range routes {
+		if _, ok := byPath[r.Path]; !ok {
+			paths = append(paths, r.Path)
+		}
+		byPath[r.Path] = append(byPath[r.Path], r)
+	}
+	for _, p := range paths {
+		mux.Handle(p, handler{c: c, routes: byPath[p]})
+	}
+}
+
+// Handler returns a handler that serves a single route by calling c.
+// It does not match the request's path against the route's.
+func Handler(c capnp.Client, r Route) http.Handler {
+	return handler{c: c, routes: []Route{r}}
+}
+
+type handler struct {
+	c      capnp.Client
+	routes []Route
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var route *Route
+	for i := range h.routes {
+		if h.routes[i].HTTPMethod == req.Method {
+			route = &h.routes[i]
+			break
+		}
+	}
+	if route == nil {
+		allow := make([]string, 0, len(h.routes))
+		for _, r := range h.routes {
+			allow = append(allow, r.HTTPMethod)
+		}
+		sort.Strings(allow)
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		writeError(w, http.StatusMethodNotAllowed, "method "+req.Method+" not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, MaxBodySize))
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	params, err := decodeParams(*route, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer params.Message().Release()
+
+	ans, release := h.c.SendCall(req.Context(), capnp.Send{
+		Method:   route.Method,
+		ArgsSize: route.ParamsSize,
+		PlaceArgs: func(args capnp.Struct) error {
+			return args.CopyFrom(params)
+		},
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		writeError(w, statusOf(err), err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := text.NewEncoder(&buf)
+	enc.UseJSON()
+	if err := enc.Encode(route.ResultsID, res); err != nil {
+		writeError(w, http.StatusInternalServerError, "encode results: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// decodeParams decodes body into a new parameter struct, before the
+// call is sent, so that malformed requests can be told apart from
+// failed calls.
+func decodeParams(r Route, body []byte) (capnp.Struct, error) {
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	params, err := capnp.NewRootStruct(seg, r.ParamsSize)
+	if err != nil {
+		seg.Message().Release()
+		return capnp.Struct{}, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return params, nil
+	}
+	if err := text.NewDecoder(bytes.NewReader(body)).Decode(r.ParamsID, params); err != nil {
+		seg.Message().Release()
+		return capnp.Struct{}, errors.New("decode params: " + err.Error())
+	}
+	return params, nil
+}
+
+// statusOf returns the HTTP status code for a call that failed with err.
+func statusOf(err error) int {
+	switch exc.TypeOf(err) {
+	case exc.Overloaded:
+		return http.StatusServiceUnavailable
+	case exc.Disconnected:
+		return http.StatusBadGateway
+	case exc.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	b, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{msg})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,170 @@
+package httpgw_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/httpgw"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+// echoServer appends "!" to its input.  "fail" and "unimplemented"
+// fail with the corresponding exception types.
+type echoServer struct{}
+
+func (echoServer) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	switch in {
+	case "fail":
+		return errors.New("failed")
+	case "unimplemented":
+		return exc.New(exc.Unimplemented, "", "not here")
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + "!")
+}
+
+var echoRoute = httpgw.Route{
+	HTTPMethod: "POST",
+	Path:       "/echo",
+	Method: capnp.Method{
+		InterfaceID:   air.Echo_TypeID,
+		MethodID:      0,
+		InterfaceName: "aircraft.capnp:Echo",
+		MethodName:    "echo",
+	},
+	ParamsID:   air.Echo_echo_Params_TypeID,
+	ResultsID:  air.Echo_echo_Results_TypeID,
+	ParamsSize: capnp.ObjectSize{PointerCount: 1},
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	c := air.Echo_ServerToClient(echoServer{})
+	defer c.Release()
+	put := echoRoute
+	put.HTTPMethod = "PUT"
+	mux := http.NewServeMux()
+	httpgw.Register(mux, capnp.Client(c), []httpgw.Route{echoRoute, put})
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+		resp   string
+	}{
+		{"POST", `{"in": "hi"}`, http.StatusOK, `{"out": "hi!"}`},
+		{"PUT", `(in = "text")`, http.StatusOK, `{"out": "text!"}`},
+		{"POST", ``, http.StatusOK, `{"out": "!"}`},
+		{"POST", `{"in": 42}`, http.StatusBadRequest, ""},
+		{"POST", `{"bogus": "x"}`, http.StatusBadRequest, ""},
+		{"POST", `{"in": "fail"}`, http.StatusInternalServerError, ""},
+		{"POST", `{"in": "unimplemented"}`, http.StatusNotImplemented, ""},
+		{"GET", ``, http.StatusMethodNotAllowed, ""},
+	}
+	for _, test := range tests {
+		w := serve(mux, test.method, "/echo", test.body)
+		assert.Equal(t, test.status, w.Code, "%s %s", test.method, test.body)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		if test.resp != "" {
+			assert.Equal(t, test.resp, w.Body.String(), "%s %s", test.method, test.body)
+		} else {
+			assert.Contains(t, w.Body.String(), `"error":`, "%s %s", test.method, test.body)
+		}
+	}
+
+	w := serve(mux, "DELETE", "/echo", "")
+	assert.Equal(t, "POST, PUT", w.Header().Get("Allow"))
+}
+
+func TestHandlerBodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	c := air.Echo_ServerToClient(echoServer{})
+	defer c.Release()
+	h := httpgw.Handler(capnp.Client(c), echoRoute)
+	w := serve(h, "POST", "/", `{"in": "`+strings.Repeat("x", httpgw.MaxBodySize)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestParseRule(t *testing.T) {
+	t.Parallel()
+
+	method, path, err := httpgw.ParseRule(" get  /v1/users ")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", method)
+	assert.Equal(t, "/v1/users", path)
+
+	for _, rule := range []string{"", "GET", "/v1/users", "GET v1/users"} {
+		_, _, err := httpgw.ParseRule(rule)
+		assert.Error(t, err, "%q", rule)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	b, err := httpgw.OpenAPI("Echo", "1.0", []httpgw.Route{echoRoute})
+	require.NoError(t, err)
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title, Version string
+		}
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]any
+				}
+			} `json:"requestBody"`
+			Responses map[string]any
+		}
+		Components struct {
+			Schemas map[string]map[string]any
+		}
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Equal(t, "Echo", doc.Info.Title)
+	assert.Equal(t, "1.0", doc.Info.Version)
+	op, ok := doc.Paths["/echo"]["post"]
+	require.True(t, ok, "missing POST /echo")
+	assert.Equal(t, "Echo.echo", op.OperationID)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/Echo.echo_Params"},
+		op.RequestBody.Content["application/json"].Schema)
+	assert.Contains(t, op.Responses, "200")
+	assert.Contains(t, op.Responses, "default")
+	assert.Contains(t, doc.Components.Schemas, "Echo.echo_Params")
+	assert.Contains(t, doc.Components.Schemas, "Echo.echo_Results")
+	assert.Contains(t, doc.Components.Schemas, "httpgw.Error")
+}
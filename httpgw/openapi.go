@@ -0,0 +1,88 @@
+package httpgw
+
+import (
+	"encoding/json"
+	"strings"
+
+	"capnproto.org/go/capnp/v3/encoding/jsonschema"
+)
+
+// errorSchemaName is the name of the schema of error responses.  It
+// contains a character that definitions generated from capnp type
+// names do not, so that it cannot collide with them.
+const errorSchemaName = "httpgw.Error"
+
+// OpenAPI returns an OpenAPI 3.1 document, in JSON, that describes the
+// routes, so that clients can be generated for them in languages that
+// do not speak Cap'n Proto.  The schemas of the parameters and results
+// are looked up in schemas.DefaultRegistry, like the handlers do.
+func OpenAPI(title, version string, routes []Route) ([]byte, error) {
+	b := jsonschema.NewBuilder("#/components/schemas/")
+	errorRef := jsonschema.Schema{"$ref": "#/components/schemas/" + errorSchemaName}
+	paths := make(map[string]map[string]any)
+	for _, r := range routes {
+		params, err := b.Ref(r.ParamsID)
+		if err != nil {
+			return nil, err
+		}
+		results, err := b.Ref(r.ResultsID)
+		if err != nil {
+			return nil, err
+		}
+		op := map[string]any{
+			"operationId": operationID(r),
+			"requestBody": map[string]any{
+				"content": jsonContent(params),
+			},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "The method's results.",
+					"content":     jsonContent(results),
+				},
+				"default": map[string]any{
+					"description": "The call failed.",
+					"content":     jsonContent(errorRef),
+				},
+			},
+		}
+		if paths[r.Path] == nil {
+			paths[r.Path] = make(map[string]any)
+		}
+		paths[r.Path][strings.ToLower(r.HTTPMethod)] = op
+	}
+
+	schemas := make(map[string]jsonschema.Schema, len(b.Defs())+1)
+	for name, s := range b.Defs() {
+		schemas[name] = s
+	}
+	schemas[errorSchemaName] = jsonschema.Schema{
+		"type":       "object",
+		"properties": map[string]any{"error": jsonschema.Schema{"type": "string"}},
+		"required":   []string{"error"},
+	}
+	doc := map[string]any{
+		"openapi":           "3.1.0",
+		"jsonSchemaDialect": jsonschema.Dialect,
+		"info":              map[string]any{"title": title, "version": version},
+		"paths":             paths,
+		"components":        map[string]any{"schemas": schemas},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func jsonContent(s jsonschema.Schema) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": s}}
+}
+
+// operationID returns an identifier for r's method, such as
+// "Users.get".
+func operationID(r Route) string {
+	iface := r.Method.InterfaceName
+	if i := strings.LastIndexByte(iface, ':'); i >= 0 {
+		iface = iface[i+1:]
+	}
+	if iface == "" || r.Method.MethodName == "" {
+		return r.Method.String()
+	}
+	return iface + "." + r.Method.MethodName
+}
@@ -1547,6 +1547,96 @@ func TestVoidUnionSetters(t *testing.T) {
 	}
 }
 
+type voidUnionVisitor []string
+
+func (v *voidUnionVisitor) VisitA(air.VoidUnion) error {
+	*v = append(*v, "a")
+	return nil
+}
+
+func (v *voidUnionVisitor) VisitB(air.VoidUnion) error {
+	*v = append(*v, "b")
+	return nil
+}
+
+func TestUnionVisit(t *testing.T) {
+	t.Parallel()
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	voidUnion, err := air.NewRootVoidUnion(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v voidUnionVisitor
+	voidUnion.SetB()
+	if err := voidUnion.Visit(&v); err != nil {
+		t.Fatal("Visit:", err)
+	}
+	voidUnion.SetA()
+	if err := voidUnion.Visit(&v); err != nil {
+		t.Fatal("Visit:", err)
+	}
+	if len(v) != 2 || v[0] != "b" || v[1] != "a" {
+		t.Errorf("visited %q; want [\"b\" \"a\"]", v)
+	}
+
+	capnp.Struct(voidUnion).SetUint16(0, 7)
+	if err := voidUnion.Visit(&v); !capnp.IsUnimplemented(err) {
+		t.Errorf("Visit on unknown variant = %v; want unimplemented", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	zdate, err := air.NewZdate(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zdate.SetMonth(12)
+	if err := zdate.Validate(); err != nil {
+		t.Errorf("Zdate{month: 12}.Validate() = %v; want <nil>", err)
+	}
+	zdate.SetMonth(0)
+	if err := zdate.Validate(); err == nil {
+		t.Error("Zdate{month: 0}.Validate() = <nil>; want error")
+	}
+	zdate.SetMonth(13)
+	if err := zdate.Validate(); err == nil {
+		t.Error("Zdate{month: 13}.Validate() = <nil>; want error")
+	}
+
+	zjob, err := air.NewZjob(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cmd  string
+		args int32
+		ok   bool
+	}{
+		{"ls", 0, true},
+		{"ls", 4, true},
+		{"ls", 5, false},
+		{"LS", 0, false},
+		{"abcdefghijklmnopq", 0, false},
+	}
+	for _, test := range tests {
+		if err := zjob.SetCmd(test.cmd); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := zjob.NewArgs(test.args); err != nil {
+			t.Fatal(err)
+		}
+		err := zjob.Validate()
+		if test.ok && err != nil {
+			t.Errorf("Zjob{cmd: %q, args: %d}.Validate() = %v; want <nil>", test.cmd, test.args, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("Zjob{cmd: %q, args: %d}.Validate() = <nil>; want error", test.cmd, test.args)
+		}
+	}
+}
+
 func TestReadDefaults(t *testing.T) {
 	t.Parallel()
 	data := mustEncodeTestMessage(t, "Defaults", "()", []byte{
@@ -1887,6 +1977,31 @@ func TestPointerDepthDefense(t *testing.T) {
 	}
 }
 
+func TestStructWithDepthLimit(t *testing.T) {
+	t.Parallel()
+	msg, _ := capnp.NewSingleSegmentMessage([]byte{
+		0, 0, 0, 0, 0, 0, 1, 0, // root 1-pointer struct pointer to next word
+		0xfc, 0xff, 0xff, 0xff, 0, 0, 1, 0, // root struct pointer that points back to itself
+	})
+	root, err := msg.Root()
+	if err != nil {
+		t.Fatal("Root:", err)
+	}
+
+	const limit = 3
+	curr := root.Struct().WithDepthLimit(limit)
+	for i := 0; i < limit; i++ {
+		p, err := curr.Ptr(0)
+		if err != nil {
+			t.Fatalf("deref %d fail: %v", i+1, err)
+		}
+		curr = p.Struct()
+	}
+	if _, err := curr.Ptr(0); err == nil {
+		t.Fatalf("deref %d did not fail as expected", limit+1)
+	}
+}
+
 func TestPointerDepthDefenseAcrossStructsAndLists(t *testing.T) {
 	t.Parallel()
 	const limit = 63
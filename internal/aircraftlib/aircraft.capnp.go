@@ -9,7 +9,9 @@ import (
 	schemas "capnproto.org/go/capnp/v3/schemas"
 	server "capnproto.org/go/capnp/v3/server"
 	context "context"
+	errors "errors"
 	math "math"
+	regexp "regexp"
 	strconv "strconv"
 )
 
@@ -95,6 +97,21 @@ func (s Zdate) SetDay(v uint8) {
 	capnp.Struct(s).SetUint8(3, v)
 }
 
+// Validate checks s against the constraints declared on its fields with
+// the $Go.min, $Go.max, $Go.maxLen and $Go.pattern annotations.
+func (s Zdate) Validate() error {
+	{
+		v := s.Month()
+		if v < 1 {
+			return errors.New("Zdate.month: value below minimum 1")
+		}
+		if v > 12 {
+			return errors.New("Zdate.month: value above maximum 12")
+		}
+	}
+	return nil
+}
+
 // Zdate_List is a list of Zdate.
 type Zdate_List = capnp.StructList[Zdate]
 
@@ -855,6 +872,16 @@ func (w Aircraft_Which) String() string {
 	return "Aircraft_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Aircraft_Visitor handles every variant of the Aircraft union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Aircraft_Visitor interface {
+	VisitVoid(Aircraft) error
+	VisitB737(Aircraft) error
+	VisitA320(Aircraft) error
+	VisitF16(Aircraft) error
+}
+
 // Aircraft_TypeID is the unique identifier for the type Aircraft.
 const Aircraft_TypeID = 0xe54e10aede55c7b1
 
@@ -893,6 +920,22 @@ func (s Aircraft) ToPtr() capnp.Ptr {
 func (s Aircraft) Which() Aircraft_Which {
 	return Aircraft_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Aircraft) Visit(v Aircraft_Visitor) error {
+	switch w := s.Which(); w {
+	case Aircraft_Which_void:
+		return v.VisitVoid(s)
+	case Aircraft_Which_b737:
+		return v.VisitB737(s)
+	case Aircraft_Which_a320:
+		return v.VisitA320(s)
+	case Aircraft_Which_f16:
+		return v.VisitF16(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Aircraft) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -1193,6 +1236,61 @@ func (w Z_Which) String() string {
 	return "Z_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Z_Visitor handles every variant of the Z union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Z_Visitor interface {
+	VisitVoid(Z) error
+	VisitZz(Z) error
+	VisitF64(Z) error
+	VisitF32(Z) error
+	VisitI64(Z) error
+	VisitI32(Z) error
+	VisitI16(Z) error
+	VisitI8(Z) error
+	VisitU64(Z) error
+	VisitU32(Z) error
+	VisitU16(Z) error
+	VisitU8(Z) error
+	VisitBool(Z) error
+	VisitText(Z) error
+	VisitBlob(Z) error
+	VisitF64vec(Z) error
+	VisitF32vec(Z) error
+	VisitI64vec(Z) error
+	VisitI32vec(Z) error
+	VisitI16vec(Z) error
+	VisitI8vec(Z) error
+	VisitU64vec(Z) error
+	VisitU32vec(Z) error
+	VisitU16vec(Z) error
+	VisitU8vec(Z) error
+	VisitBoolvec(Z) error
+	VisitDatavec(Z) error
+	VisitTextvec(Z) error
+	VisitZvec(Z) error
+	VisitZvecvec(Z) error
+	VisitZdate(Z) error
+	VisitZdata(Z) error
+	VisitAircraftvec(Z) error
+	VisitAircraft(Z) error
+	VisitRegression(Z) error
+	VisitPlanebase(Z) error
+	VisitAirport(Z) error
+	VisitB737(Z) error
+	VisitA320(Z) error
+	VisitF16(Z) error
+	VisitZdatevec(Z) error
+	VisitZdatavec(Z) error
+	VisitGrp(Z) error
+	VisitEcho(Z) error
+	VisitEchoes(Z) error
+	VisitAnyPtr(Z) error
+	VisitAnyStruct(Z) error
+	VisitAnyList(Z) error
+	VisitAnyCapability(Z) error
+}
+
 // Z_TypeID is the unique identifier for the type Z.
 const Z_TypeID = 0xea26e9973bd6a0d9
 
@@ -1231,6 +1329,112 @@ func (s Z) ToPtr() capnp.Ptr {
 func (s Z) Which() Z_Which {
 	return Z_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Z) Visit(v Z_Visitor) error {
+	switch w := s.Which(); w {
+	case Z_Which_void:
+		return v.VisitVoid(s)
+	case Z_Which_zz:
+		return v.VisitZz(s)
+	case Z_Which_f64:
+		return v.VisitF64(s)
+	case Z_Which_f32:
+		return v.VisitF32(s)
+	case Z_Which_i64:
+		return v.VisitI64(s)
+	case Z_Which_i32:
+		return v.VisitI32(s)
+	case Z_Which_i16:
+		return v.VisitI16(s)
+	case Z_Which_i8:
+		return v.VisitI8(s)
+	case Z_Which_u64:
+		return v.VisitU64(s)
+	case Z_Which_u32:
+		return v.VisitU32(s)
+	case Z_Which_u16:
+		return v.VisitU16(s)
+	case Z_Which_u8:
+		return v.VisitU8(s)
+	case Z_Which_bool:
+		return v.VisitBool(s)
+	case Z_Which_text:
+		return v.VisitText(s)
+	case Z_Which_blob:
+		return v.VisitBlob(s)
+	case Z_Which_f64vec:
+		return v.VisitF64vec(s)
+	case Z_Which_f32vec:
+		return v.VisitF32vec(s)
+	case Z_Which_i64vec:
+		return v.VisitI64vec(s)
+	case Z_Which_i32vec:
+		return v.VisitI32vec(s)
+	case Z_Which_i16vec:
+		return v.VisitI16vec(s)
+	case Z_Which_i8vec:
+		return v.VisitI8vec(s)
+	case Z_Which_u64vec:
+		return v.VisitU64vec(s)
+	case Z_Which_u32vec:
+		return v.VisitU32vec(s)
+	case Z_Which_u16vec:
+		return v.VisitU16vec(s)
+	case Z_Which_u8vec:
+		return v.VisitU8vec(s)
+	case Z_Which_boolvec:
+		return v.VisitBoolvec(s)
+	case Z_Which_datavec:
+		return v.VisitDatavec(s)
+	case Z_Which_textvec:
+		return v.VisitTextvec(s)
+	case Z_Which_zvec:
+		return v.VisitZvec(s)
+	case Z_Which_zvecvec:
+		return v.VisitZvecvec(s)
+	case Z_Which_zdate:
+		return v.VisitZdate(s)
+	case Z_Which_zdata:
+		return v.VisitZdata(s)
+	case Z_Which_aircraftvec:
+		return v.VisitAircraftvec(s)
+	case Z_Which_aircraft:
+		return v.VisitAircraft(s)
+	case Z_Which_regression:
+		return v.VisitRegression(s)
+	case Z_Which_planebase:
+		return v.VisitPlanebase(s)
+	case Z_Which_airport:
+		return v.VisitAirport(s)
+	case Z_Which_b737:
+		return v.VisitB737(s)
+	case Z_Which_a320:
+		return v.VisitA320(s)
+	case Z_Which_f16:
+		return v.VisitF16(s)
+	case Z_Which_zdatevec:
+		return v.VisitZdatevec(s)
+	case Z_Which_zdatavec:
+		return v.VisitZdatavec(s)
+	case Z_Which_grp:
+		return v.VisitGrp(s)
+	case Z_Which_echo:
+		return v.VisitEcho(s)
+	case Z_Which_echoes:
+		return v.VisitEchoes(s)
+	case Z_Which_anyPtr:
+		return v.VisitAnyPtr(s)
+	case Z_Which_anyStruct:
+		return v.VisitAnyStruct(s)
+	case Z_Which_anyList:
+		return v.VisitAnyList(s)
+	case Z_Which_anyCapability:
+		return v.VisitAnyCapability(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Z) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2926,6 +3130,35 @@ func (s Zjob) NewArgs(n int32) (capnp.TextList, error) {
 	return l, err
 }
 
+var x_pattern_Zjob_cmd = regexp.MustCompile("^[a-z]+$")
+
+// Validate checks s against the constraints declared on its fields with
+// the $Go.min, $Go.max, $Go.maxLen and $Go.pattern annotations.
+func (s Zjob) Validate() error {
+	{
+		v, err := s.Cmd()
+		if err != nil {
+			return err
+		}
+		if len(v) > 16 {
+			return errors.New("Zjob.cmd: length exceeds maximum 16")
+		}
+		if !x_pattern_Zjob_cmd.MatchString(v) {
+			return errors.New("Zjob.cmd: value does not match pattern \"^[a-z]+$\"")
+		}
+	}
+	{
+		v, err := s.Args()
+		if err != nil {
+			return err
+		}
+		if v.Len() > 4 {
+			return errors.New("Zjob.args: length exceeds maximum 4")
+		}
+	}
+	return nil
+}
+
 // Zjob_List is a list of Zjob.
 type Zjob_List = capnp.StructList[Zjob]
 
@@ -4707,6 +4940,14 @@ func (w VoidUnion_Which) String() string {
 	return "VoidUnion_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// VoidUnion_Visitor handles every variant of the VoidUnion union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type VoidUnion_Visitor interface {
+	VisitA(VoidUnion) error
+	VisitB(VoidUnion) error
+}
+
 // VoidUnion_TypeID is the unique identifier for the type VoidUnion.
 const VoidUnion_TypeID = 0x8821cdb23640783a
 
@@ -4745,6 +4986,18 @@ func (s VoidUnion) ToPtr() capnp.Ptr {
 func (s VoidUnion) Which() VoidUnion_Which {
 	return VoidUnion_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s VoidUnion) Visit(v VoidUnion_Visitor) error {
+	switch w := s.Which(); w {
+	case VoidUnion_Which_a:
+		return v.VisitA(s)
+	case VoidUnion_Which_b:
+		return v.VisitB(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s VoidUnion) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -5052,6 +5305,10 @@ type Echo capnp.Client
 // Echo_TypeID is the unique identifier for the type Echo.
 const Echo_TypeID = 0x8e5322c1e9282534
 
+// Echo_Fingerprint is a hash of the schema of Echo's methods,
+// used to detect peers built from a different version of the schema.
+const Echo_Fingerprint = 0x4fa57ab920cc4be6
+
 func (c Echo) Echo(ctx context.Context, params func(Echo_echo_Params) error) (Echo_echo_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -5148,6 +5405,16 @@ type Echo_Server interface {
 	Echo(context.Context, Echo_echo) error
 }
 
+// Echo_UnimplementedServer can be embedded in an implementation of
+// Echo_Server, including those of interfaces that extend Echo, to fail
+// the methods it does not define with an unimplemented exception.
+type Echo_UnimplementedServer struct{}
+
+// Echo returns an unimplemented exception.
+func (Echo_UnimplementedServer) Echo(context.Context, Echo_echo) error {
+	return capnp.Unimplemented("unimplemented method aircraft.capnp:Echo.echo")
+}
+
 // Echo_NewServer creates a new Server from an implementation of Echo_Server.
 func Echo_NewServer(s Echo_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -5160,6 +5427,19 @@ func Echo_ServerToClient(s Echo_Server) Echo {
 	return Echo(capnp.NewClient(Echo_NewServer(s)))
 }
 
+// Echo_NewServerWithOptions is like Echo_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func Echo_NewServerWithOptions(s Echo_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(Echo_Methods(nil, s), s, c, opts)
+}
+
+// Echo_ServerToClientWithOptions is like Echo_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func Echo_ServerToClientWithOptions(s Echo_Server, opts *server.Options) Echo {
+	return Echo(capnp.NewClient(Echo_NewServerWithOptions(s, opts)))
+}
+
 // Echo_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func Echo_Methods(methods []server.Method, s Echo_Server) []server.Method {
@@ -5202,12 +5482,29 @@ func (c Echo_echo) AllocResults() (Echo_echo_Results, error) {
 // Echo_List is a list of Echo.
 type Echo_List = capnp.CapList[Echo]
 
-// NewEcho creates a new list of Echo.
+// NewEcho_List creates a new list of Echo.
 func NewEcho_List(s *capnp.Segment, sz int32) (Echo_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[Echo](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0x8e5322c1e9282534,
+				MethodID:      0,
+				InterfaceName: "aircraft.capnp:Echo",
+				MethodName:    "echo",
+			},
+			ParamsTypeID:  0x8a165fb4d71bf3a2,
+			ResultsTypeID: 0x9b37d729b9dd7b9d,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+		},
+	)
+	capnp.RegisterFingerprint(Echo_TypeID, Echo_Fingerprint)
+}
+
 type Echo_echo_Params capnp.Struct
 
 // Echo_echo_Params_TypeID is the unique identifier for the type Echo_echo_Params.
@@ -5846,6 +6143,10 @@ type CallSequence capnp.Client
 // CallSequence_TypeID is the unique identifier for the type CallSequence.
 const CallSequence_TypeID = 0xabaedf5f7817c820
 
+// CallSequence_Fingerprint is a hash of the schema of CallSequence's methods,
+// used to detect peers built from a different version of the schema.
+const CallSequence_Fingerprint = 0x5065224933c22489
+
 func (c CallSequence) GetNumber(ctx context.Context, params func(CallSequence_getNumber_Params) error) (CallSequence_getNumber_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -5942,6 +6243,16 @@ type CallSequence_Server interface {
 	GetNumber(context.Context, CallSequence_getNumber) error
 }
 
+// CallSequence_UnimplementedServer can be embedded in an implementation of
+// CallSequence_Server, including those of interfaces that extend CallSequence, to fail
+// the methods it does not define with an unimplemented exception.
+type CallSequence_UnimplementedServer struct{}
+
+// GetNumber returns an unimplemented exception.
+func (CallSequence_UnimplementedServer) GetNumber(context.Context, CallSequence_getNumber) error {
+	return capnp.Unimplemented("unimplemented method aircraft.capnp:CallSequence.getNumber")
+}
+
 // CallSequence_NewServer creates a new Server from an implementation of CallSequence_Server.
 func CallSequence_NewServer(s CallSequence_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -5954,6 +6265,19 @@ func CallSequence_ServerToClient(s CallSequence_Server) CallSequence {
 	return CallSequence(capnp.NewClient(CallSequence_NewServer(s)))
 }
 
+// CallSequence_NewServerWithOptions is like CallSequence_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func CallSequence_NewServerWithOptions(s CallSequence_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(CallSequence_Methods(nil, s), s, c, opts)
+}
+
+// CallSequence_ServerToClientWithOptions is like CallSequence_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func CallSequence_ServerToClientWithOptions(s CallSequence_Server, opts *server.Options) CallSequence {
+	return CallSequence(capnp.NewClient(CallSequence_NewServerWithOptions(s, opts)))
+}
+
 // CallSequence_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func CallSequence_Methods(methods []server.Method, s CallSequence_Server) []server.Method {
@@ -5996,12 +6320,29 @@ func (c CallSequence_getNumber) AllocResults() (CallSequence_getNumber_Results,
 // CallSequence_List is a list of CallSequence.
 type CallSequence_List = capnp.CapList[CallSequence]
 
-// NewCallSequence creates a new list of CallSequence.
+// NewCallSequence_List creates a new list of CallSequence.
 func NewCallSequence_List(s *capnp.Segment, sz int32) (CallSequence_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[CallSequence](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xabaedf5f7817c820,
+				MethodID:      0,
+				InterfaceName: "aircraft.capnp:CallSequence",
+				MethodName:    "getNumber",
+			},
+			ParamsTypeID:  0xf58782f48a121998,
+			ResultsTypeID: 0xa465f9502fd11e97,
+			ResultsSize:   capnp.ObjectSize{DataSize: 8, PointerCount: 0},
+		},
+	)
+	capnp.RegisterFingerprint(CallSequence_TypeID, CallSequence_Fingerprint)
+}
+
 type CallSequence_getNumber_Params capnp.Struct
 
 // CallSequence_getNumber_Params_TypeID is the unique identifier for the type CallSequence_getNumber_Params.
@@ -6144,6 +6485,10 @@ type Pipeliner capnp.Client
 // Pipeliner_TypeID is the unique identifier for the type Pipeliner.
 const Pipeliner_TypeID = 0xd6514008f0f84ebc
 
+// Pipeliner_Fingerprint is a hash of the schema of Pipeliner's methods,
+// used to detect peers built from a different version of the schema.
+const Pipeliner_Fingerprint = 0x7d65739c0b5dd5d9
+
 func (c Pipeliner) NewPipeliner(ctx context.Context, params func(Pipeliner_newPipeliner_Params) error) (Pipeliner_newPipeliner_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -6262,6 +6607,21 @@ type Pipeliner_Server interface {
 	GetNumber(context.Context, CallSequence_getNumber) error
 }
 
+// Pipeliner_UnimplementedServer can be embedded in an implementation of
+// Pipeliner_Server, including those of interfaces that extend Pipeliner, to fail
+// the methods it does not define with an unimplemented exception.
+type Pipeliner_UnimplementedServer struct{}
+
+// NewPipeliner returns an unimplemented exception.
+func (Pipeliner_UnimplementedServer) NewPipeliner(context.Context, Pipeliner_newPipeliner) error {
+	return capnp.Unimplemented("unimplemented method aircraft.capnp:Pipeliner.newPipeliner")
+}
+
+// GetNumber returns an unimplemented exception.
+func (Pipeliner_UnimplementedServer) GetNumber(context.Context, CallSequence_getNumber) error {
+	return capnp.Unimplemented("unimplemented method aircraft.capnp:CallSequence.getNumber")
+}
+
 // Pipeliner_NewServer creates a new Server from an implementation of Pipeliner_Server.
 func Pipeliner_NewServer(s Pipeliner_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -6274,6 +6634,19 @@ func Pipeliner_ServerToClient(s Pipeliner_Server) Pipeliner {
 	return Pipeliner(capnp.NewClient(Pipeliner_NewServer(s)))
 }
 
+// Pipeliner_NewServerWithOptions is like Pipeliner_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func Pipeliner_NewServerWithOptions(s Pipeliner_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(Pipeliner_Methods(nil, s), s, c, opts)
+}
+
+// Pipeliner_ServerToClientWithOptions is like Pipeliner_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func Pipeliner_ServerToClientWithOptions(s Pipeliner_Server, opts *server.Options) Pipeliner {
+	return Pipeliner(capnp.NewClient(Pipeliner_NewServerWithOptions(s, opts)))
+}
+
 // Pipeliner_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func Pipeliner_Methods(methods []server.Method, s Pipeliner_Server) []server.Method {
@@ -6328,12 +6701,29 @@ func (c Pipeliner_newPipeliner) AllocResults() (Pipeliner_newPipeliner_Results,
 // Pipeliner_List is a list of Pipeliner.
 type Pipeliner_List = capnp.CapList[Pipeliner]
 
-// NewPipeliner creates a new list of Pipeliner.
+// NewPipeliner_List creates a new list of Pipeliner.
 func NewPipeliner_List(s *capnp.Segment, sz int32) (Pipeliner_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[Pipeliner](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xd6514008f0f84ebc,
+				MethodID:      0,
+				InterfaceName: "aircraft.capnp:Pipeliner",
+				MethodName:    "newPipeliner",
+			},
+			ParamsTypeID:  0xbaa7b3b1ca91f833,
+			ResultsTypeID: 0xbbcdbf4b4ae501fa,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 2},
+		},
+	)
+	capnp.RegisterFingerprint(Pipeliner_TypeID, Pipeliner_Fingerprint)
+}
+
 type Pipeliner_newPipeliner_Params capnp.Struct
 
 // Pipeliner_newPipeliner_Params_TypeID is the unique identifier for the type Pipeliner_newPipeliner_Params.
@@ -6922,301 +7312,307 @@ func (f AllocBenchmark_Field_Future) Struct() (AllocBenchmark_Field, error) {
 	return AllocBenchmark_Field(p.Struct()), err
 }
 
-const schema_832bcc6686a26d56 = "x\xda\xacZ}t\x14U\x96\xbf\xb7\xaa;\x15\x92t" +
-	"\xba+\xaf\x80\x10\x12#\x11\x14\x1a\xc1\x900\x01\x99u" +
-	"\x93`\xa2\xe0\x82\xa6h\x10ue\xa4\x92T\x92\xc6N" +
-	"w\xd3]\x0d\x89\xcaAv`\x04\x8f\xec\xc8QGQ" +
-	"\x99uX\xdc\xf1\x03\\\xf1cW\x18Dq\xc0\x81\xa8" +
-	"\xab\xb2\xf8\x01\xab\xa8\xb8\xa8\xa0\xec\x883\xae\xa0`\xed" +
-	"\xb9\xaf\xbb\xba*\x9dnQ\xce\xfeC\xaa\xdf\xef\xd6}" +
-	"\xf7\xdd\xefw\x8b\xeaVO\x83k\x82g\xee`\x10\xd4" +
-	"\x13\xee<s\xd7\x89coW\xdf<j\x05\xa8^D" +
-	"\xf3\xea\xee\xf5\xbf\xeaxu\xec/\xc1%\x01\xb05\x05" +
-	"}l]\x01=\xad-\xa8\x074\xe7\xbd\xe2\xfb\xc5\xa0" +
-	"m\xd3n\xcb\xa0u\x8bD\xb2\xa5`+\xdb\xc1\x89\x9f" +
-	"/\xf8\x14\xd0|\xf7\xe9\xef\xab\xcf\xab\xff\xe3m {" +
-	"\x9d\xb4(\x01\xd4>[X\x82lW!\x11\xef($" +
-	"\xceSz\x1a\xea\x9ezm\xc4\xca\x0c\xceM\x92\x00\xc0" +
-	"\x8e\x17\xf6\xb1\xd3\x9c\xf8d\xe1b@s\xfd_\x86\xbf" +
-	"\xf3\xcc\x0dCn\x07YAHq\x9cS$  \xbb" +
-	"\xb6\x88\xb8M\x1c5\xfa\xc8\x8e\xaa\xc0?\x82\xec\x15m" +
-	"f\x80\xac\xb7h=[VD\x9c\x96\x14]\xce\x1e\xa6" +
-	"'\xf3\xce\xdd\x95/7\xde\xfc\xc4\xaf3\xe4\x14\x88j" +
-	"u\xd1!\xb6\x96\xd3\xdfSD;\x9b\xb1W\x8f\xaak" +
-	"\xf7\xdc\xd5\x9f\x96\xeb\xeat\xd1K\xcc\xed\x91@4\x8b" +
-	"\xf6\xfdfg\xc9\xe3\xd5w\x83\xecu\xf5\xdb\xfd\xa3\xa2" +
-	">v\x8c\xb8\x05>+\x121\xe0\xf2\x08\x08`\xae\x1e" +
-	"\x91?\xf1\xc4\x92\x17\xee\xce\xa2'\x86\x9e>\xe6\xf1\xd0" +
-	"\xd3 \x0f\x1d\xec\xc6\xf0|\xa5\xfe\xfb\xed\xf7d\xd3\xe9" +
-	"\x18O\x09\xb2\x8b9\xf1\xcf8\xf1\xea\xdb*_\x9e\xb6" +
-	"\xea\xe3{I\xa7B\xe6\xc9t\xcfK\xac\x9b\x88k\x83" +
-	"\x9eJ\x044\xaf\xdd(<p\xef\xdag\xef\xcf&\xc6" +
-	"\xb2\xe2>\xb6\xba\x98\x9eV\x15\x13\xe7u7\xbf\xbfe" +
-	"\xcc;\x93\x1ep\x1a`sq\x01\x19`\x0b'\xd8=" +
-	"\xe7\x90{\xeb\x05\xbf~`\x80\x0a\xf6\x17\xf7\xb1\xc3\xc4" +
-	")\xf0a\xb1\x88\x81/\x8a\xb9\x0a\x12?w\xfd\xca\xac" +
-	"\xa9^\x97\xe9W|\xf3\xf7\x8b\xfb\xd8\x11\xbe\xf9\xe1b" +
-	"\xb2\xc1\xbd\xe7\xbcyQ\xcbI}\x03\xa8\xe5\x88\xc0\x95" +
-	"_\xdb\xe8\x8d\xd1\xe63\xbd\xb4y\xc7\xb4\xce\xaf\xbeg" +
-	"/<\x92\xed$\xdd\xde\x97X\xc2KO\x0b9\xed\xb9" +
-	"\xbb\x87\xf6\xdc\xf0\xc1\x13\x8f\x0f\xf0\x945\xdeCl\x1d" +
-	"'\\\xeb\xbd\x9c\xed\xa2'\xf3\xf8\x92\x99\xfe\xa9s\xde" +
-	"x<\x9b\xf67y\xcb\x90=\xcf_\xd8\xc29o\xf9" +
-	"\xfc\xc9H\xf3\x81\xdb6e\x93\xe2\x88w=;\xcei" +
-	"\x8fqZ9\xda\xfeN\xd8\xbdqs6Z\x8f\xef+" +
-	"6\xccGO\x83}D\xbb\xb4\xee\x17\xcb\xe7M\xfer" +
-	"3\xe9J\xcc\x8c\xc1f\xdf^\xa6\x12q\xedL\xdf\\" +
-	"\xb2j\xbco\xa2\xf9\xc5\xa1s\xfe-\x9b\x0f\xd4n\x91" +
-	"\x05d\xbbd\x1e\x852\x85\xec\x86\x7f\xd9:\xea\xe5\xee" +
-	"\xb1\xff\x0e\xaa\x8c\xa2\xb9\xff\xa1\xb7\x7f~\xef\x91\xf3\x8f" +
-	"\xc2`\x94\x90B\xa0\xe41@vO\x09\x19\xe1\x93\x05" +
-	"\xcb\x8f=\xd7\xfe\xdds \x9f\x83\xe6\x9c\xab\xb7o\xfb" +
-	"\xed\xdf\x1e\xfd\"\xa5\x8a\xe3%U\xc8\x90\xf1\xa0(!" +
-	"\x91kO\xac\xe9\xdb\xfc\xf4\xef\xb7\x82<\xcc2\xd8\x08" +
-	"\xb6\x00\xc1e~\x8b\x87\xaf\xf8\xbb\x17^\xfbC\x12I" +
-	"J\xe5a\xdc\x96\xc3\x18\x0f\xf5\xc2\xaf\xd6\x9f\xd4\x0f\xfc" +
-	")\x9bfz\xd9cl\x19\xdff\x09\xa3m\xc6\xcd\xbc" +
-	"\xf8\xf9\x8f\x1f\xff\xfb=\xd9\x02y\x1d\xebc\x8fr\xda" +
-	"\x879\xdf\xad\xdf|\xf0\xd6\x0d\x8b\xde{%\x9b)Q" +
-	")C&+\\\xf9\x0a1\xbeo\xe7\xa3\x85\x9f\xc8M" +
-	"\xaff\x13\xe2\x12e+k\xe6\xb4\x8d\x9c\xf6\xc3\xf3'" +
-	"\xcf\xff\xf8\xa9\xa7\xb3\xd2&\x94\xf5l\x09\xa7\xed\xe5\xb4" +
-	"\xd3[\x0e\xed?\xf4D\xd3\x7fd5\xe5Z\xe5({" +
-	"\x98\x88k\x7f\xa7pS\xee\xbeuGy\xdf\xd1\x07^" +
-	"\xcf&\xf2\xe9\xc1%\xc8<Cx\xa2\x18B\xac\xb7\x1f" +
-	"Xy|\xc3wuog\x13\xe3\xe2!\xf7\xb3FN" +
-	"{\x09\xa7\xddv\xe5\x89/\xf3\x1b\xd4\xb7\x07\xc4\xc0\xbc" +
-	"!},\xc8\x09\xf5!\x97\xb35C\x86\x02\x98k&" +
-	"\xfd~~\xf8\xf5m\xef\x92\xcc\xaeL-/\x1b\xd2\xc7" +
-	"V\xd3\x0b\xb5\xab\x86p\x99W\xbf\xb2\x7f\xf1\xca\xf9w" +
-	"\xec\xcf&\xc6\xb1\xa1\xeb\xd9\xd7C\xe9\xe9\xf8P\x12\x83" +
-	"-\xfa.\xd8\xd1\xf8\xe6\xfb\xd9\xcc'\x97\xaeg\xc3J" +
-	"y\x10\x94\x92\xf9\xcaw\xb4\xe5\xff\xa6\xcc\x7f0Ss" +
-	"I)J\xf7\xb2\xd5\xa5\\\x8aR.\xc5\xba\xd9s7" +
-	"\xfd\xe1\x89\x96\x83\xd9J\xdc\xfb\xc3\x1ec\x87\x87\xd1\xd3" +
-	"G\xc3\xfe\x15\xd0\xbc\xab\xfa\x91o\xfff\xdfo\x0ff" +
-	"\xd3roY\x01\xb2UeD\xbc\xa2\x8cD\xde\xb4]" +
-	"\x92\xdfzs\xfdG\xd9\x8e\xf7l\xd9V\xf6<\xa7\xdd" +
-	"\xc2i\xb5@m\xc9\xae\xa3{\xb2\xd2~Tv?;" +
-	"\xc2i\x0fs\xda\x13\xd7>\xf4\xcb\x07\xd7\xe7\x1f\xce&" +
-	"\x84{x\x09\xb2\xc1\xc3\xb9V\x86\x13\xf1\xe6?\xcd9" +
-	"\xf8\x84\xef\xca\xc3\x19\xa7kF\xc9E^9\xfc%6" +
-	"\x9dS7\x0f\xa7\x10\x1fq\xd1\x89\xe1\xa7V\xcc#\xd6" +
-	"B?c\x0f+\xdf\xcaF\x94\x13aE9)\xe2\xbd" +
-	"\xbc\x93\xff\xb4|\xe9\xb2L\x19\xb8o.,\xefcK" +
-	"8m/\xa7\xbd\xb6\xab\xec\xcfS>_\xfeI\xb6\xb3" +
-	"5V\x1c`3+\xe8iz\x05/aom\xdf\xb0" +
-	"\xa9l\xe1\xa7\x03J\xc3\xc2\x8a>\xb6\x84\x08\x03=\x15" +
-	"\"\x06\x96W\xf0\xd2\x90NB\xfd-\xdd\x8c\xd2\x04\xda" +
-	"\xbe\xe2v\xb6\x8c\xde\xa9]R\xf1B\x018RQ\x16" +
-	"IF\xf8\xbfb\xe3\xfcC\xa9>\xfaI\x92\xf2\xfcy" +
-	"S\x07m\xba\xeax6Z\xdd\x7f\x80-\xf4\xf3\x8a\xc1" +
-	"i\xef\x1bVr\xfb_\xff\xe1\xb6\xafA.\xb7R\xd8" +
-	":?Oa\xa7;.\xdf\xd3\xfc\x9e\xfb\x9b\x8c\x0c\xcb" +
-	"\x9dk\x95\x7f/\xbb\x87sY\xe3'\xb7]P\x1a\xba" +
-	"\\i2\xbf\xc9\x1a\x0e\xfe\xbd\xec$\xa7\xfd\x9a\xefx" +
-	"p\xc6\xf6\xbbF\x1b\xff|*\x9b\xd3^<v/k" +
-	"\x1e\xcb\xb5;\xb6\x1e\xc6\x99Z0\xd6\x16\xd3:\x0ca" +
-	"|\x9b\x16\x0dG\xa7\x04\x0c\xad\xed\xc6`\xb8s*@" +
-	"\x0b\xa2\xea\x12]\x00.\x04\x90=U\x00j\xbe\x88\xaa" +
-	"\"\xa0\x14Nt\xa3\x0b\x04t\x91w\xa68`\x8a\xc3" +
-	"\xa5\xf5\x91D\xd8\xd0c\xf4\xba/\xfd\xba\xe6\x07P\xaf" +
-	"\x17Q\xed\x12\x10Q\xa1\xf2+\xeb5\x00\xea|\x11\xd5" +
-	"\x90\x80\xb2\x80\x0a\x0a\x00r\xf0\x0a\x00\xb5KDu\xb9" +
-	"\x80\xb2(((\x02\xc8\xcb\xa6\x02\xa8\xb7\x88\xa8\xde'" +
-	"\xa07\x1e\xbcIG7\x08\xe8\x06\xac\\\x1c\x89\xb5\xc7" +
-	"\xb1\x08\x04,\x024\xe9W(\x187\x00\x00\x8b\x01[" +
-	"D\xe4P1\xe0\xd2\xd6\xa0A\x88\xb5\x8c\xc9\xe5\xb4\xf4" +
-	"bJ\xfai\x91P{\xfcj=6{qd\xf6\xe2" +
-	"HK(\x81\xf1\x0c=LI\xe9a\xa4\x80\xf5\xdd\xbd" +
-	"N\x9e>;-\x03\xf6\xe3ni\xf7\xeaH\xb0}N" +
-	"8\x18\x09'\xb5\x9b/\xba\x8aL\x93\xb3\x1dS\x02\xa0" +
-	"\x8e\x14Q\xad\x16\xd0\x83\xdf\x9bI\x0d\x8d\xa3\xd5\xd1\"" +
-	"\xaa\x13\x05D\x0d\xf2\xb0\x15\xf2\x06\x88\xdc\xdc\xd6\x15\x19" +
-	"\xaf\xb7uEF\xb6h1\xad;\x0eNi\xcbl\xab" +
-	"\x89\xc1pZQ\x99Fk\x96\xda\xba\"\xc9\x83\xba\x01" +
-	"\xd2m2Z\xed\x9a,\xfbA\x90\xdd\x92\x97\xf6i\xc0" +
-	"\x16\xc4\x9c\x9e3K\x8aD\x8c\xd4\xe9\x10]\x88(\x8f" +
-	"Y`\x1f\xc3\x972\xfe\xc5t\xb4\x89\"\xaa\x0d\x02\x9a" +
-	"\xda\xdc\xa0\xd1\xd5\xa4w\x80WK\x84\x0c\xf4\xd9m\x1d" +
-	" \xfa\xb8\xb2\xd0\x0f\x80\xda\x00(\x8b\x8e\xf5Xsw" +
-	"\xd4\xe8\x85lB\xb6E\xc2qc\x06\xf7\x10\xe2\x996" +
-	"\\:\xcfs\xc3\x81\x8cC\xe5|\xf4~ \xe5\x0f\xa7" +
-	"\x7f\xca\xb3mrUXo1b?\x18&Q#\x86" +
-	">;\x1c3\x04\xce\xeer3\x82q4~\x82\xcb\xa5" +
-	"\xdb\xb4\x1c.\xd7\xa4w\x90N\xe3\\N%\xc9\x13Q" +
-	"^B\xf1\xd8\x93\x0a3\x1e\x90\x88\xf2\x0aZ\xbcUD" +
-	"\xf5\x0e\x01QPP@\x94WS\x90\xae\x14Q\xbd\x9b" +
-	"\xe2\x11\x15\x14\x11\xe55t\xca;\x92\xf1(\xbb\x04\x85" +
-	"\xcc,\xdfCo\xdf)\xa2\xfa\xa0\x80^C\xef1R" +
-	"\xde\x062VI\x1d\x91\x88\xb7]34\xf4\x80\x80\x1e" +
-	"Z+\x93Z\xb5XeG(\xa2\x19X\x00\xc2\xf1\x82" +
-	"?~=\xad\x01P\x0a\x86\x0d\xca-\xc7]+L\xd3" +
-	"\x04\xf4&h!\x1f\x049\xdf\x9f\xe5xscZ4" +
-	"i\xeeLC<\x05\xa0\xfaDT\xcb\x054\xbb\x83\x9d" +
-	"]\xc6\x95\x11\x03\xa7\xea\xb3t-\x14\xea\xad\xe4\xef\xa0" +
-	"\xcf\xbe\x96\xe50\x8e\x1d\\\xb3\xf48\xd7#\xe4\xb2v" +
-	"$a\x0c\x88\xaf~~\xd7\x1cNt'\xfd\xcek\x17" +
-	"S@\xb7\x17!w85\xa6SEj\xcf1Uv" +
-	"\xa6\xb02\xa93O8\x933\xb6\xa2\xcf\xbe\xa6g\x1c" +
-	"\xd1e%l-\x14\x0a\xe8\x0b\x13z\xb8M\x1f\xdf\xa9" +
-	"\x1bW&\xba[\xf5\xd8\xc8Yz%?\xb0\xf3\xb8%" +
-	"\xf6q1LF\xc1\xfc,\xa2\x93\xce\xa6jq=\xd3" +
-	"\"~\xfbm\x9eGP\xb6\xaf\xdb\x80(ga\x95\x96" +
-	"M\x0a\xb7\xe9v~\xb2j*Z7:Y\x9e\x05\x82" +
-	"<H2-\xf9\x01c\xfd\xd3Tf\xb4]\x15\xd6\x9b" +
-	"4C\x9b\x11\x14\xe3?%\xdc\x9c\xc1\\\x9c%\x91N" +
-	"\x93\"F\xd7\x0f\x9c\xbbU\x8b\xeb\xe8\xb3/\x9a9\x92" +
-	"\x18\xa5\xa8\x80\x11K\xb4U\x1a\x97j\xd1p\x8e\x1c3" +
-	"R@i\x91\xdefK\x97\xeeKr$\x83Yzg" +
-	"L\x8f\xc7\x83\x11\xe4,K\xd3,\xd7\x92\x8cw\x8b\xa8" +
-	">d;\xd5:\xaa\x1d\xf7\x89\xa8np\x94\xe7\xdf\x11" +
-	"\xe1\x83\"\xaa\xcf9\xca\xf3\xb3\xa4\xb1'ET_\xa5" +
-	"t\x80\x0a\xba\x00\xe4=$\xe5N\x11\xd57\x04\x94\xdd" +
-	"\x82\x82n\x00\xf95Z\xdc-\xa2\xba\xcf\xd6E\xfa^" +
-	"\x90\xd4\x85\xd8Z\x8d\x85 `!\xa0\xb7U74\xeb" +
-	"t\x85\xc9\x8a]\x1f\x0dia=n\x9f9\xdd\xc4&" +
-	"\xcf,\xf5v'\xac\xf7\xa5\xdex\xbb\xf5<\xc0\x11\x92" +
-	"\x19\x97|\x80\x0a\xbd\x11\x83\x8c\x86\xa5\xcanX\xe4t" +
-	"\xc7Rew,<AR\xc3B\x1ai\x17Q\x8dZ" +
-	"\x09\x12@\xee\xf6\xa7\xba\x18\x83L\xa4\x85P\x04\x01E" +
-	"@\xa9=\x11\xb1z\x17o\xd4\x88M\x18X\x1fh\xb9" +
-	"\xe6\x07\xca\x86\xe5i\xd7y\xc7w\xc6\xa2\x94\x18\xa8\xa4" +
-	"\xfa)3\xd4\xd8\x99A&\xf1\xc6Rj\x98b\xa7\x86" +
-	"\xca\x8e`,n\xe0 \x10p\x10`}\\o\x8b\x84" +
-	"\xdb\xad\x9f\x03\x14\xd4\x18\x0aE\xda\xa6\xea\xe1\xb6\xaen" +
-	"-v\xe3\xf8\xcb\x82\x92\x1ej\xcf\xf0\xc4V\x00\xb5H" +
-	"D\xb5T@3n\xc4\x82\xe1\xce\xab5\x90B\x09}" +
-	"@&\xb4\xb2MK0\xaa\x87\x82a=6>\xac/" +
-	"N\xff\x18\xd9\xa2y\xa9w\xf9\xb1\xe4\xe9\xe4\xe4\xc8\x8b" +
-	"\xfdNo%\xc6Y\x00\xea\x85\"\xaa\x93\x05\xac\xd4{" +
-	"\x8c\x98\x86% `\x09\xa0\x19M1\x03\x8c\xa1l\xdf" +
-	"d3\x12\x91\xadm\xaa^?\x10\xd8\x8e\xe2\x96\xb5'" +
-	"I9Yf2\xf7g\x13\xdao\x9b\xec,\x9d$K" +
-	"oq6\xd9.}C\xc9\x91\xed\xae\xab\x8f\xeb\xb1E" +
-	"\xc9^?\x8bW\x8c\x16\xd0\\\xac\x05\x8d`\xb8s\x01" +
-	"H\x91VG\xd4\xa6\xaf\xec98O\x95&\xd5N:" +
-	"s\x1e\xcd\xc8\x1d9\x14?{q\xc4\xdb\x12J\xc43" +
-	"r^\x95\x9d\xf3\xd2\xba_We'=+\xc2\xd39" +
-	"\xef\x11G\x84?L\x8b\x0f\x89\xa8n\xb4Z \x00\xf9" +
-	"Qz{\x83\x88\xea\x93\x8e\x9c\xb7\x89(\x1f\x11Q\xdd" +
-	"y\xe6\\\xe0\xec\xe6\x1cf\xceX\x96\x8cX\xfa\x06\xe4" +
-	"\x0d\xc5\x8dZK\xaf\xee3\xdfjZ\x8c\xd8Om1" +
-	"\xd3\xd3\xb1\\\xb6\x12\xb5\xce\x0cvSmS-mK" +
-	"\xde\x07\xd1g\x7f\x04\xc8a\xadt|[\xcd\x1c\xd5z" +
-	"k\x04\x88\xd6\xc4O\x96\x17$k\xbd\x95\x11\xc0K\xef" +
-	"4\xa0\xeaB\xc7X\x16` \x7f*\x1d\xbc3\x81\xdc" +
-	"\xe5\xcfv\x85\x9a\x94+<c\xbb\xc2f\xd2\xd3F\xab" +
-	"\xfc5d\x94\xbfm\x0eW\xd8B\xf7\xd8\xe7\x92V\x97" +
-	"\xddb\xd2\x15v\xd0\xe2\x8b\xc9B\xe9\x0dk\xdd\xe94" +
-	"Y\xd9\x15\xe9\xb6\x8bZ\xbf\xf6\x90\x17\xbd\x98FAd" +
-	"\xd9\xbc\xbeM\x0b_\x16\xea\xe5\x97X\xea\x1d\xdb\xb4\xa8" +
-	"\xd6\x16\xa4\x1e\x18,\x12\xb3[\xeb\x09Du\xbd\x9d\xd6" +
-	"2K\xa0e\xb8F\xa9\xb6\xa6\xfa\xec\x83,\x9d\x05\xa4" +
-	"\x05\x91\xd6\xdcM\xea\xc0\xbc\xd6$\xa0\xd4\xd6\xddn\x1d" +
-	"\xde\xab\xc5:\xe3\x19\xf7\xf5\x01\xb6K\x17\"l\xfc\x11" +
-	"\xad\xcb\x15\x8e\x88\xb5Z\x97\x87k\x1c\x11k\xb5.\x8f" +
-	"^\x91\x0a\xceg\xc8v\xf3\x93\xb6\xebge+\x8c\x9f" +
-	"\xad\xb1\xad\xdc\xcfvfk0ft5iN\xf5W" +
-	"F\xbb\"a\x9b\"\x1el\x0d\x05\xc3\x9dq\xa2H\xb5" +
-	"\xe6\xf5\xf1h$\x11\xd7-\x1bVvG\xc2zoN" +
-	"K\xf1\xf2\xc3\x1b\xe0\xa2\xf4\xc1\x9b\xe9\xe0\x0d\"\xaa3" +
-	"R\xe5\x9e\x16\xa7\x93\x94M\"\xaa-tr1y\xf2" +
-	"\x99d\x8di\"\xaa\xb3\x05\xf4\xf6\xeaZ\xccJA\xb4" +
-	"\xab\xd1\x85y `\x1e%$\xad\xd7z\xce\x99B\xf8" +
-	"\xc5)}\x83\xfe\xb1)\xc4y\xcd\xca\x96B\xe6\xd6\xc7" +
-	"\xb4hMO\xcdY\xdd\xe12\xb2\xe3\x00\xe6\x97\x89\x13" +
-	"\xea\xce\xde\xcb\xb3\\\x1c\xce\"\x87\xa6?\xd5\xe5\xe8\xcc" +
-	"\x1bS\xbf\xad.\xd4\x9a\x0b9\xe7f\xf6\\H\xf7\xdb" +
-	"}\xa8G8m\x0e\xecD=\xe2)3\xd5\x8aV\xd9" +
-	"\xad\xa8wQ$\xd8\x0ey\xde\xd6I\xb5\x93\xd0g\x7f" +
-	"qHU\x1b\xad\xb6\xa6\x9ad\xb5F\xef\xa9j\xd31" +
-	"\xa1\x0e}\xf6\x14:\x87\x96\x1b\xeb\x83\xb1h$\xc6\x95" +
-	"R\x9et;?\xef\xa6\x9a\xab\x00P\x90/\xa1?\xa2" +
-	"\xfc3\xfa\xe3\x92\xc7\xd1\x1f\xb7<\x8a\xfe\xe4\xc9\x15~" +
-	"\x00o8\x12\xd6\xa5\x05\x1d7J!\xadG\x8awD" +
-	"\xa4Pb\x91\xd4\xde\xb1\xd8k\xe8qc\x80\xc2\xb89" +
-	"f\xeb=)?t\x84E\x953,R\x09azU" +
-	"*,\xe6SX\xa4\x92\xf9<2\xd85\xc9\x19\x88d" +
-	"\xa4\xe7\x15(\x85l\xcb\xa5\xd2Q}(n8V\xcf" +
-	"\x90\xab\xe6&\x9d9\x1a\x12\x13\xf1\xb3\xf2h\xe7\x1c\xd1" +
-	"\x97k\x9c\xd0\xa4\x19\xc9B\x961\xbf\xf2\x01\xf8\x92\xb3" +
-	"\xab\x81i$\xa9\xac\x9di\xffb3\xdd~\x80\xc04" +
-	"\xb7\x88\x81\xd9n\x87\x8b1\xd5]\x06\x10\x98A\xc05" +
-	"n\x01+\x84\xd3f2\x8f\xb29\xee*\x80@\x0b!" +
-	"\xd7\x13\"\x9e2\x93\xc9\x94]\xcb\x91\xd9\x84\xcc'\xc4" +
-	"\xf5\x9d\x99\xbc\x0c\xb2y\x1c\xb9\x86\x90vB\xdc\xdf\x9a" +
-	"\xc9\xb4\xca4\x8e\\OH\x17!y'M\x97\x82y" +
-	"\x00L\xe7\xc8|BB\x84H'\xcc|\x85\x0f\xbc\x83" +
-	"\\\xb6vB\xa2\x84\xe4\x7fC\xfb\xe4\x03\xb0n\xfeN" +
-	"\x17!\x06!\x83\xfe\x97\xf6\x19\x04\xc0\x16r$DH" +
-	"\x0f!\x05_\xd3>\x05\x00,\xc1\x91(!\xb7\x10R" +
-	"\xf8W\xda\xa7\x10\x80\xf5\xf2}\x0cBn%\xa4\xe8/" +
-	"f\x83\x82E\x00l\x09W[\x0f!\xcbIm\x9e\xaf" +
-	"L\x05=\x00l\x19\x07n!`%\x01\xc5\xc7M\x05" +
-	"\x8b\x01\xd8\x0a\x0e\xdcJ\xc0\x1d\x04x\xbf4\x15\xf4\x02" +
-	"\xb0U\xee)\x00\x81\xe5\x04<D\x80\xef\xcf\xa6B6" +
-	"d\xeb8p\x1f\x01\xcf\x10 \xff\x8f\xa9\xa0\x0c\xc06" +
-	"s`#\x01\xbb\x09(9f*X\x02\xc0vq\xe0" +
-	"E\x02\xde#\x80}a*\xc8\x00\xd8~\x0e\xec#\xe0" +
-	"K\x02\x94\xcfM\x05\x15\x00v\xcc]\x03\x10\xf8\x8c\x00" +
-	"W\x9e\x80\x9e\xc1GM\x05\x07\x030\xcc\xa37N\x11" +
-	"PJ\xc0\x90#\xa6\x82C\x00\xd8`\x0e\xf8\xf2D\x0c" +
-	"\\H\xc0\xd0\xcfL\x05\x87\x02\xb01\x1c\x18I@\x03" +
-	"\x01\xa5\x9f\x9a\x0a\x96\x02\xb0K\xf2h\x8f\xc9\x04\xcc&" +
-	"\xa0\xfc\x13S\xc1a\xe4by\xa4\x92\x19\x04t\x11P" +
-	"q\xd8T\xb0\x8c,\x9f7\x95,O\xc0\x1d\x04\x9c\xf3" +
-	"\xdf\xa6\x82\xc3IW\x9c\xd5r\x02\xee$\xa0\xf2cS" +
-	"\xc1r\x00\xb6\x9a\x03+\x09\xb8\x9b\x80s\x0f\x99\x0aV" +
-	"\x00\xb05y\xad\x00\x81;\x09\xd8H\xc0\x88\x8fL\x05" +
-	"\xcf\x01`\x8f\xe6]\x01\x10x\x84\x80g\x08\xa8\xfa\xd0" +
-	"T\xb0\x92\xb4\x9bw\x1d@\xe0I\x02\xb6\x11p\xde\x07" +
-	"\xa6\x82\xe7\x02\xb0-y\xb3\x00\x02\xcf\x11\xb03O\xc0" +
-	"\x8a\x91\x07\xc9\x83F\x00\xb0\x1d\\\xdem\x84\xec\xa6W" +
-	"F\xbdo*XE\x06\xe1'|\x91\x80W\x098\xff" +
-	"=S\xc1\xf3\x00\xd8\x1e\x0e\xec$\xe0\x0d\x02.\xf8/" +
-	"S\xc1\x91\x00\xec\xb5<r\xc6\xdd\x04\xec#`\xf4\x01" +
-	"S\xc1Q\x00\xecM.\xef\x1b\x04|A\xc0\x98\xfd\xa6" +
-	"\x82\xe7\x03\xb0#\x1c\xf8\x8c\x00\x97$\xa0g\xd8\xbb\xa6" +
-	"\x82\x17\x90\x09%\x92\xea\x14\x01\xa5\x04\x94\xbdc*8" +
-	"\x9aL\xc8\x01\x9fD&$`\xf8\xdb\xa6\x82c\xc8\x84" +
-	"\x1c\x18I@\x03\x01\xfe\xb7Lt|\xdag\x97HU" +
-	" x\xc6\xee3\x15\xbc\x10\x80\x8d\x92\xe8\x10\xe7\xa6\xf9" +
-	"\\\xf8\x9f\xa6\x82\xe38\x9f)\xfd\xf8\x8c\xdbk*8" +
-	"\x9e\\\x81\x03\x93\x09h\"`\xfc\x9b\xa6\x82\x17\x01\xb0" +
-	"F\x89t\xdb@\xc0\x0c\x02.z\xc3T\xb0\x1a\x80M" +
-	"\xe7\"5\x11\xd0B@\xf5\xeb\xa6\x82\x13(qI1" +
-	"r\x1e\x02\xae\x91\xd2\x95M\xbc\xe9&\xf4\xd9_\xfe\xac" +
-	"\x02V71=\x12\xea\xa8\xad\xc1\x02\x10\xb0\x00P\x0a" +
-	"\xd6M\xb4\x1a6)X[c\xb5fRpB\x9d\xd5" +
-	"!\x89\xc1\xc9(\x80\x80\x02\xa0\x94\xa8\x9bh\x0dK\xa4" +
-	"Dm\x8d5\xff\x94\x12\x13\xeaP\x02\x01%@11" +
-	"\xd9\xea\xa0\xbc\xad\x91H\xc8j\xef\x9c\xf3p\xf4\xb6\x86" +
-	"\"\xad\xd6\xac\xa0\xbe\xa3n\xa2c\x86gM\xb9:j" +
-	"k\x1c\xab\x05\xa9\xd5`?Z\xb7\xb5\xda\x8f\xd6e\xad" +
-	"N\xa8s\xac\x8a\xc9\xd5\xca\xe0d\xc7\xa2\x90\"M\xf4" +
-	"c;\xc8Z\xed\xc76\xdfZ\xed\xc7VJ\xb1M8" +
-	"\xd9\xe6%\x17\xbd7\xf5\x9bM:\x8dR\x0c\xb8\x94P" +
-	"\x07A.\xba\xca\x9b\xa8\xf3\x1dP\xe2\x92\xeb\x1a\xfa\xec" +
-	"\xff\xe7\x91Q+\xa1\xffh4cLh\x93\x01d\xa0" +
-	"\xc4$\x96\x1a\x94\x82\x18\x09\xa3\xcf\xfe\x9f3)\x98\xcf" +
-	" [\xb58`\x96\xceq\xa9\x96l\x842\xaeq^" +
-	"2\xfc\xffC\xdf\xc5\xf5\xb1Hos|\xe3\xcc\xfc~" +
-	"\x95$\xd22\x89\x9c\x9a\xe2\x9fD#\x91\x90CG\xa9" +
-	"O\xa2KS\xafZ\xcb\x9e\xd42y\xb0c9\xd5\xfb" +
-	"H\x9d\xb1h\x8ea~=-\xdb\x97\xda\xfe8\xb9\x92" +
-	"\x16\xeem1b\xe9\x81\x9c\x16\xee\xe5#o@\x03K" +
-	"\\\x08H\xabK\xb50\xbfm`\x89KH\xae\x10\xdd" +
-	"\xa5ZTk\x85\xca`(h\xf4b\x89K\xb4\x90\xcc" +
-	"\xa6\xda\x9a`V\xf2\x9b#\x9f\x0f\xd8\xff\xfd\x08k*" +
-	"/\x0b\xea\xa1\xf6\\-|\x07\x81\x8e\x91U\xfa\xcd\x1c" +
-	"-\xfc\x95z\xdc\xd0c\x13.\xd5\xc4\x01\x03{\xbf\xcd" +
-	"\xd6\x1b7b9/\xbbg\xf8$\x939$\x152G" +
-	"\xd9\xa8\xfd\x98kx\x95\xe3cQ\x8ey\xd4\xc0/\x07" +
-	"sg\xebq\xe3R-\x8a\x99g\xbb.5\xec\x9b," +
-	"\xa0\x19\xd6\xe3\xc6L\xcd\x88\x81\x18\xec\x19\x10\xd8g\xfa" +
-	"8\x91\xfe(\x83\xda\x0f|Su\x08\xfc\x7f\x01\x00\x00" +
-	"\xff\xffX\x17OX"
+const schema_832bcc6686a26d56 = "x\xda\xacZ}x\x14e\x92\xaf\xea\x9e\x99\xce\xd7d" +
+	"\xa6\xd3\x0d\x84|\x10\xc9\x82\x0b\x13\xc1\x90`@\xf6\xbc" +
+	"$\x98(p\xe0\xa6\x09\x88\xba\xa2t\x92N28\x99" +
+	"\x19gz Ay\x90[X\xd1\x95[y\xd4UT" +
+	"\xf6\x94\x83[?\xc0\x13\xbfNXDq\xc1\x85(\xa7" +
+	"p\xa0\xc2**\x1e~\xa0\xec\x8a\xbb\x9e\xa0`\xdfS" +
+	"\xefLOw\xe6C\x94\xe7\xfeIz\xde_u\xbd\xf5" +
+	"V\xd5[o\xbdU]\xbd\xdc\xdd\xe0\x18\xe7\x9e3\x08" +
+	"8\xe5\xa4\xd3e\xec<y\xfc\xcd\xea\x9bF.\x07\xc5" +
+	"\x83h\\\xd9\xb3\xf6W\x9d\xafU\xfd\x12\x1c\x02\x80\xb4" +
+	"*\xaf_Z\x93GO\xab\xf3\xea\x01\x8d\xb9\xafz\xaf" +
+	"\xcb\xdd:\xe5\xd6\x14Z'O$\x9b\xf3\xb6H\xdb\x19" +
+	"\xf1\x0by\x1f\x03\x1ao?\xfd]\xf5O\xea\xffx+" +
+	"\x88\x1e;-\x0a\x00\xb5\xcf\xe6\x17\xa1\xb43\x9f\x88\xb7" +
+	"\xe7\x13\xe7I\xbd\x0duO\xed\x19\xbe\"\x85s\x93\xc0" +
+	"\x01H'\xf2\xfb\xa53\x8c\xf8T\xfeB@c\xed\xdf" +
+	"J\xdfz\xe6\xfa\xc1\xb7\x83(#$8\xce.\xe0\x10" +
+	"P\xba\xba\x80\xb8\x8d\x1f9\xea\xd3\xed\x95\xad\xff\x02\xa2" +
+	"\x87\xb7\x98\x01J}\x05k\xa5\xa5\x05\xc4iq\xc1\xe5" +
+	"\xd2zz2\xee\xdcU\xf1J\xe3MO\xfc&EN" +
+	"\x8e\xa8V\x16\x1c\x91V3\xfa{\x0ahf#\xf2\xda" +
+	"1e\xf5\xee\xbb\x06\xd22]\x9d)xYr\xba\x05" +
+	"\xe0\x8d\x82\xfd\xbf\xddQ\xf4x\xf5\xdd z\x1c\x03f" +
+	"\xff\xa0\xa0_:N\xdcZ?)\xe0\xb1\xd5\xe1\xe6\x10" +
+	"\xc0X9<g\xfc\xc9\xc5/\xde\x9dAO\x12\xba\xfb" +
+	"%\xb7\x9b\x9er\xdd\xb4\xb0\x1b\x82\xf3\xe4\xfa\xef\xb6\xdd" +
+	"\x93I\xa7\xa3\xddE(]\xcc\x88/b\xc4+o\xad" +
+	"xe\xcam\x1f\xdeK:\xe5RW\xa6\xb9_\x96z" +
+	"\x88\xb8\xd6\xef\xae@@\xe3\xea\x0d\xdc\x03\xf7\xae~\xf6" +
+	"\xfeLb,-\xec\x97V\x16\xd2\xd3m\x85\xc4y\xcd" +
+	"M\xefn\x1e\xfd\xd6\x84\x07\xec\x06\xd8T\x98G\x06\xd8" +
+	"\xcc\x08v\xcd>\xe2\xdc\xf2\xd3\xdf<\x90\xa6\x82\x83\x85" +
+	"\xfd\xd2Q\xe2\xd4\xfa~!\x8f\xad\x9f\x172\x15\xc4~" +
+	"\xe6\xf8\x95QS\xbd&\xd5\xaf\xd8\xe4\xef\x16\xf6K\x9f" +
+	"\xb2\xc9\x8f\x16\x92\x0d\xee\x1d\xb6\xf7\xc2\x96S\xda:P" +
+	"\xca\x10\x81)\xbf\xb6\xd1\x13\xa1\xc9gxh\xf2\xce)" +
+	"]_~'\xbd\xf8H\xa6\x95\xf4x^\x96b\x1ez" +
+	"\xba\x91\xd1\x9e\xb7kH\xef\xf5\xef=\xf1x\x9a\xa7\xac" +
+	"\xf2\x1c\x91\xd60\xc2\xd5\x9e\xcb\xa5\x9d\xf4d\x9cX<" +
+	"\xc37y\xf6\x1b\x8fg\xd2\xfeFO\x09J/\xb0\x17" +
+	"63\xce\x9b?{2\xd4|\xe8\xd6\x8d\x99\xa4\xf8\xd4" +
+	"\xb3V:\xc1h\x8f3Z1\xdc\xf1V\xd0\xb9aS" +
+	"&Z\xb7\xf7Ki\xa8\x97\x9e\x06y\x89vI\xddu" +
+	"\xcb\xe6N\xfcb\x13\xe9\x8aO\xdd\x83\xcd\xde}\x92B" +
+	"\xc4\xb53\xbcs\x10\xd0\x88\xf6\x8f7>?2\xec\xb9" +
+	"L>P\xbbY\xe4P\xda)\xb2](\xd2\x96]\xf7" +
+	"\xef[F\xbe\xd2S\xf5\x9f\xa0\x88\xc8\x1b\x07\x1fz\xf3" +
+	"g\xf7~z\xfe1\x18\x84\x02\xd2\x16(z\x0cP\xba" +
+	"\xa7\x88\x8c\xf0\xd1\xfce\xc7\x9f\xef\xf8\xf6y\x10\x87\xa1" +
+	"1\xfb\xcam[\x7f\xf7\x8f\xc7>O\xa8\xe2DQ%" +
+	"J(\xb1MQD\"\xd7\x9e\\\xd5\xbf\xe9\xe9\xdfo" +
+	"\x01q\xa8i\xb0\xe1\xd2|\x04\x87\xf1\x0d\x1e\x9d\xf6O" +
+	"/\xee\xf9C\x1c\x89K\xe5\x96\x98-\x87Jl\xab\xe7" +
+	"\x7f\xb9\xf6\x94v\xe8O\x994\xd3'=&-e\xd3" +
+	",\x96h\x9a13.~\xe1\xc3\xc7\x7f\xb1;\xd3F" +
+	"^#\xf5K\x8f2\xda\xf5\x8c\xef\x96\xaf\xdf;p\xfd" +
+	"\x82w^\xcddJ\x94KP\x12e\xa6|\x99\x18\xdf" +
+	"\xb7\xe3\xd1\xfc\x8f\xc4\xa6\xd72\x09q\x89\xbcEjf" +
+	"\xb4\x8d\x8c\xf6\xfd\xf3'\xce\xfb\xf0\xa9\xa73\xd2\xc6\xe4" +
+	"\xb5\xd2bF\xdb\xc7h\xa7\xb6\x1c9x\xe4\x89\xa6\xff" +
+	"\xcah\xca\xd5\xf21i=\x11\xd7>,3S\xee\xba" +
+	"e{Y\xff\xb1\x07^\xcf$\xf2\x99AE(\xb9\x07" +
+	"\xb3@1\x98Xo;\xb4\xe2\xc4\xbao\xeb\xde\xcc$" +
+	"\xc6\xc5\x83\xef\x97\x1a\x19\xed%\x8cv\xeb\x15'\xbf\xc8" +
+	"iP\xdeL\xdb\x03s\x07\xf7K~F\xa8\x0d\xbe\\" +
+	"Z5x\x08\x80\xb1j\xc2\xef\xe7\x05_\xdf\xfa6\xc9" +
+	"\xecH\xd5\xf2\xd2\xc1\xfd\xd2Jz\xa1\xf6\xb6\xc1L\xe6" +
+	"\x95\xaf\x1e\\\xb8b\xde\x1d\x073\x89q|\xc8Z\xe9" +
+	"\xab!\xf4tb\x08\x89!-\xf8\xd6\xdf\xd9\xb8\xf7\xdd" +
+	"L\xe6\x13\x8b\xd7JC\x8b\xd9&(&\xf3\x95mo" +
+	"\xcf\xf9m\x89\xefp\xaa\xe6\x18\xf1\xc1\xe2}\xd2Q\"" +
+	"\xae\xfd\xa0\x98I\xb1f\xd6\x9c\x8d\x7fx\xa2\xe5p\xa6" +
+	"#\xee\xa2\x92\xc7\xa4KJ\x98ZJ\xfe\x03\xd0\xb8\xab" +
+	"\xfa\x91o\xfea\xff\xef\x0eg\xd2\xb2\xb34\x0f\xa5A" +
+	"\xa5L\xa0R\x12y\xe36A<\xb0w\xed\x07\x99\x96" +
+	"\xd7X\xbaE\x9a\xcah\x9b\x19\xad\xdaZ[\xb4\xf3\xd8" +
+	"\xee\x8c\xb4=\xa5\xf7K1F{#\xa3=y\xf5C" +
+	"\xbf|pm\xce\xd1LB\xac*-B\xe9aF\xbc" +
+	"\x86\x11o\xfa\xd3\xec\xc3Ox\xaf8\x9a\xb2\xbaf\x14" +
+	"\x1c\x00\xd2\x9e\xd2\x97\xa5\x03\x8czo)m\xf1\xe1\x17" +
+	"\x9e,=\xbd|.\xb1\xe6\x06\x18{}\xd9\x16ic" +
+	"\x19\x11>ZF\x8ax\xc7u\xea_\x97-Y\x9a*" +
+	"\x03\xf3\xcd3e\xfdRn9=9\xcb\x89\xf6\xea\xee" +
+	"\x92\xbfN\xfal\xd9G\x99\xd6\xb6\xa7\xfc\x90t\x90\xd1" +
+	"\x1e(gG\xd8\x81m\xeb6\x96\xdc\xf8q\xda\xd1p" +
+	"\xa6\xbc_\xca\x1dFG\x83c\x18\x8f\xad\xdea\xech" +
+	"H\x06\xa1\x81\x96nFa\x1cM?\xecv\xc9M\xef" +
+	"\xd4\xe6\x0e{1\x0fl\xa1(\x83$\x1b}_J\x9b" +
+	"}C(\xda\xf9H\x92\xb2\x9c\xb9\x93s7\xfe\xfcD" +
+	"&\xda\x13\xbeC\xd2\x19\x1fK9\x18\xed}C\x8bn" +
+	"\xff\xfb?\xdf\xfa\x15\x88ef\x08\x1b]\xc5B\xd8\x99" +
+	"\xce\xcbw7\xbf\xe3\xfc:%\xc22\xe7\x1aT\xb5O" +
+	"\x1a^EO\xe5U\xe4\xb6\xf3\x8b\x03\x97\xcbM\xc6\xd7" +
+	"\x19\xa3Y\xd5>i9\xa3]ZE3\x1e\x9e\xbe\xed" +
+	"\xaeQ\xfa\xbf\x9d\xce\xe4\xb4;\xab\xf6I{\x19\xed\x9e" +
+	"\xaaz\x18c\xa8\xfeH{D\xed\xd4\xb9\xb1\xedj8" +
+	"\x18\x9e\xd4\xaa\xab\xed7\xf8\x83]\x93\x01Z\x10\x15\x07" +
+	"\xef\x00p \x80\xe8\xae\x04PrxTd\x0e\x85`" +
+	"\xac\x07\x1d\xc0\xa1\x030\xc9\x01\x13\x1c.\xad\x0f\xc5\x82" +
+	"\xba\x16\xa1\xd7\xbd\xc9\xd7U\x1f\x80r-\x8fJ7\x87" +
+	"\x882\xd2\x98V\x03\xa0\xcc\xe3Q\x09p(r(#" +
+	"\x07 \xfa\xa7\x01(\xdd<*\xcb8\x14yNF\x1e" +
+	"@\\:\x19@\xb9\x99G\xe5>\x0e=Q\xff\"\x0d" +
+	"\x9d\xc0\xa1\x13\xb0ba(\xd2\x11\xc5\x02\xe0\xb0\x00\xd0" +
+	"\xa0_\x01\x7fT\x07\x00,\x04l\xe1\x91A\x85\x80K" +
+	"\xda\xfc:!\xe60\xc6\x87\x93\xd2\xf3\x09\xe9\xa7\x84\x02" +
+	"\x1d\xd1+\xb5\xc8\xac\x85\xa1Y\x0bC-\x81\x18FS" +
+	"\xf40)\xa1\x87\x11\x1c\xd6\xf7\xf4\xd9yz\xad\xb0\x0c" +
+	"8\x80\xbb\xa9\xdd+C\xfe\x8e\xd9A\x7f(\x18\xd7n" +
+	"\x0e\xef(0\x0c\xc6vt\x11\x802\x82G\xa5\x9aC" +
+	"7~g\xc454\x86FG\xf1\xa8\x8c\xe7\x10Up" +
+	"a\x1b\xb8\xd2Dnn\xef\x0e\x8d\xd5\xda\xbbC#Z" +
+	"\xd4\x88\xda\x13\x05\xbb\xb4%\x96\xd5x\x7f0\xa9\xa8T" +
+	"\xa35\x0b\xed\xdd\xa1\xf8B\x9d\x00\xc94\x19\xcdtM" +
+	"\x14}\xc0\x89N\xc1C\xf34`\x0bbV\xcf\x99)" +
+	"\x84Bzbu\x88\x0eD\x14G\xcf\xb7\x96\xe1M\x18" +
+	"\xffbZ\xdax\x1e\x95\x06\x0e\x0du\x8e_\xefn\xd2" +
+	":\xc1\xa3\xc6\x02:z\xad\xb4\x0e\x10\xbdLY\xe8\x03" +
+	"@5\x0d\xca\xa0c-\xd2\xdc\x13\xd6\xfb \x93\x90\xed" +
+	"\xa1`T\x9f\xce<\x84x&\x0d\x97\x8c\xf3\xccp " +
+	"\xe2\x101\x07=\xef\x099\xa5\xf4\xa7,\xd3$?\x0f" +
+	"j-z\xe4{\xb7IX\x8f\xa0\xd7\xda\x8e)\x02g" +
+	"v\xb9\xe9\xfe(\xea?\xc2\xe5\x92iZ\x16\x97k\xd2" +
+	":I\xa7Q&\xa7\x1c\xe7\x89(.\xa6\xfd\xd8\x9b\xd8" +
+	"flC\"\x8a\xcbi\xf0\x16\x1e\x95;8DNF" +
+	"\x0eQ\\I\x9bt\x05\x8f\xca\xdd\xb4\x1fQF\x1eQ" +
+	"\\E\xab\xbc#\xbe\x1fE\x07'\x93\x99\xc5{\xe8\xed" +
+	";yT\x1e\xe4\xd0\xa3k\xbdz\xc2\xdb@\xc4J\xa1" +
+	"3\x14\xf2t\xa8\xba\x8an\xe0\xd0Mc%B\x9b\x1a" +
+	"\xa9\xe8\x0c\x84T\x1d\xf3\x80;\x91\xf7\xc7\xaf\xa64\x00" +
+	"\x0a\xfe\xa0N\xb1\xe5\x84c\xb9a\x18\x80\x9e\x18\x0d\xe4" +
+	"\x00'\xe6\xf82,oND\x0d\xc7\xcd\x9dj\x88\xa7" +
+	"\x00\x14/\x8fJ\x19\x87F\x8f\xbf\xab[\xbf\"\xa4\xe3" +
+	"dm\xa6\xa6\x06\x02}\x15\xec\x1d\xf4Z\xd7\xb2,\xc6" +
+	"\xb16\xd7L-\xca\xf4\x08\xd9\xac\x1d\x8a\xe9i\xfbk" +
+	"\x80\xdf5\x07c=q\xbf\xf3X\x87)\xa0\xd3\x83\x90" +
+	"};5&CEb\xce\xd1\x95V\xa4@L\x8f\x13" +
+	"\xf6\xe0\x8cm\xe8\xb5\xae\xe9)Kt\x98\x01[\x0d\x04" +
+	"Z\xb5\x1bcZ\xb0]\x1b\xdb\xa5\xe9W\xc4z\xda\xb4" +
+	"\xc8\x88\x99Z\x05[\xb0}\xb9E\xd6r1HF\xc1" +
+	"\x9c\x0c\xa2\x93\xce&\xabQ-\xd5\">\xebm\x16G" +
+	"P\xb4\xae\xdb\x80(f`\x95\x94M\x08\xb6kV|" +
+	"2\xcfT4ot\xa28\x1381W0L\xf9\x01" +
+	"#\x03\xc3T\xean\xfbyPkRuu\xba\x9f\x8f" +
+	"\xfe\x98\xedf\xdf\xcc\x85\x19\x02\xe9\x14!\xa4w\x7f\xcf" +
+	"\xba\xdb\xd4\xa8\x86^\xeb\xa2\x99%\x88Q\x88j\xd5#" +
+	"\xb1\xf6\x0a\xfdR5\x1c\xcc\x12cFp(,\xd0\xda" +
+	"-\xe9\x92yI\x96`0S\xeb\x8ah\xd1\xa8?\x84" +
+	"\x8ceq\x92\xe5j\x92\xf1n\x1e\x95\x87,\xa7ZC" +
+	"g\xc7}<*\xebl\xc7\xf3\xc3D\xf8 \x8f\xca\xf3" +
+	"\xb6\xe3\xf9Y\xd2\xd8\x93<*\xafQ8@\x19\x1d\x00" +
+	"\xe2n\x92r\x07\x8f\xca\x1b\x1c\x8aNNF'\x80\xb8" +
+	"\x87\x06w\xf1\xa8\xec\xb7t\x91\xbc\x17\xc4u\xc1\xb7U" +
+	"c>p\x98\x0f\xe8i\xd3t\xd5\\]~\xfc\xc4\xae" +
+	"\x0f\x07\xd4\xa0\x16\xb5\xd6\x9cLb\xe3k\x16\xfazb" +
+	"\xe6\xfbB_\xb4\xc3|Ns\x84x\xc4%\x1f\xa0\x83" +
+	"^\x8f@J\xc2Ri%,b2c\xa9\xb42\x16" +
+	"\xe4\x12\x09\x0bi\xa4\x83G%l\x06H\x00\xb1\xc7\x97" +
+	"\xc8bt2\x91\x1a@\x1e8\xe4\x01\x85\x8eX\xc8\xcc" +
+	"]<a=2.\xfd|\xa0\xe1\x9a\xef96LO" +
+	"\xbb\xc63\xb6+\x12\xa6\xc0@G\xaa\x8f\"C\x8d\x15" +
+	"\x19D\x12\xaf\x8aB\xc3$+4Tt\xfa#Q\x1d" +
+	"s\x81\xc3\\\xc0\xfa\xa8\xd6\x1e\x0av\x98?\xd3\x14\xd4" +
+	"\x18\x08\x84\xda'k\xc1\xf6\xee\x1e5r\xc3\xd8\xcb\xfc" +
+	"\x82\x16\xe8H\xf1\xc46\x00\xa5\x80G\xa5\x98C#\xaa" +
+	"G\xfc\xc1\xae+U\x10\x021--\x12\x9a\xd1\xa6\xc5" +
+	"\x1f\xd6\x02\xfe\xa0\x16\x19\x1b\xd4\x16&\x7f\x8chQ=" +
+	"\x94\xbb\xfcP\xf2dp\xb2\xc5\xc5\x01\xab7\x03\xe3L" +
+	"\x00\xe5\x02\x1e\x95\x89\x1cVh\xbdzD\xc5\"\xe0\xb0" +
+	"\x08\xd0\x08'\x98\x01FP\xb4n\xb2)\x81\xc8\xd26" +
+	"\x9d^\xdf\xb3\xb1m\x87[\xc6\x9c$\xe1d\xa9\xc1\xdc" +
+	"\x97Ih\x9fe\xb2st\x92\x0c\xb9\xc5\xb9D\xbb\xe4" +
+	"\x0d%K\xb4\xbb\xa6>\xaaE\x16h\x91\x14\x96\xa6W" +
+	"\x8c\xe2\xd0X\xa8\xfau\x7f\xb0k>\x08\xa16\xdb\xae" +
+	"M^\xd9\xb3p\x9e,L\xa8\x9dp\xf68\x9a\x12;" +
+	"\xb2(~\xd6\xc2\x90\xa7%\x10\x8b\xa6\xc4\xbcJ+\xe6" +
+	"%u\xbf\xa6\xd2\x0az\xc8\xa5\xc4\xbcGl;|=" +
+	"\x0d>\xc4\xa3\xb2\xc1L\x81\x00\xc4G\xe9\xedu<*" +
+	"O\xdab\xdeF\xa2|\x84Ge\xc7\xd9c\x81=\x9b" +
+	"\xb3\x999eX\xd0#\xc9\x1b\x90'\x10\xd5kM\xbd" +
+	":\xcf~\xabi\xd1#?6\xc5LV\xc7\xb2\xd9\x8a" +
+	"W\xbbR\xd8M\xb6L\xb5\xa4=~\x1fD\xaf\xd5\x04" +
+	"\xc8b\xad\xe4\xfe\x06\xb0\xcez\xb3\x04\x88f\xc5O\x14" +
+	"\xe7\xc7\xcfz3\"\x80\x87\xdei@\xc5\x81\xb6\xb2," +
+	"@:\x7f::Xf\x02\xd9\x8f?\xcb\x15j\x12\xae" +
+	"\xf0\x8c\xe5\x0a\x9bHO\x1b\xcc\xe3\xaf!\xe5\xf8\xdbj" +
+	"s\x85\xcdt\x8f}>nu\xd1\xc9\xc7]a;\x0d" +
+	"\xbe\x14?(=A\xb5'\x19&+\xbaC=\xd6\xa1" +
+	"6 =d\x87^D\xa5Md\xda\xbc\xbe]\x0d^" +
+	"\x16\xe8C\x04\x0e\x11\xd0hW\xc3j\xbb\x9fr`0" +
+	"I\x8c\x1e\xb5\xb75\xaci\x1d4\x96z\x04\x9a\x86k" +
+	"\x14jk\xaa\xcf}\x93%\xa3\x800?\xd4\x96\x9a\xa4" +
+	"\x8a\xa3'$\xef\xf6\xa6F\xfd>\xd1_\xc1.\x18\x0f" +
+	"r(\xb4\xf7t(9\xc8\x19\xda\xd2\xcd\xf7\\T\xf2" +
+	"\xd8\xafA\xf1\xb2\xba\xcd\xfb\xcf\x85\x97=7\xfa\xbb\xd7" +
+	"\x13\xbf\xc5\x1c/`\xfc\x1a1\xcd\xb8\xee\x17\xea\x98E" +
+	"s\xabF\x00\x80\xa9:\x8f\x1a\xe9\x8a*\x8e\x01\x8c\x1c" +
+	"\xf1\x17\x1d\x80)e\x804\x97H\x9eo\xd8\xf8\x032" +
+	"\xa2i\xb6@`fD\xebkl\x81\xc0\xcc\x88\x1e\x9d" +
+	"\x96\xd8\xf3\xcf\x90K\xcc\x8b\xbb\xc4\x00\xe71\xa3\xc3\xb3" +
+	"5\x96\xf3\x0cp\x09\xa3\xcd\x1f\xd1\xbb\x9bT\xbbU+" +
+	"\xc2\xdd\xa1\xa0E\x11\xf5\xb7\x05\xfc\xc1\xae(Q$2" +
+	"\xfe\xfah8\x14\x8bj\xa6kT\xf4\x84\x82Z_V" +
+	"\x07`\xa7\x1a\xcb\xab\x0b\x92\x0bo\xa6\x857\xf0\xa8L" +
+	"Od\x1148\xb5F\x9c:!y_\xe4\xf8\xf8\xd2" +
+	"\x97V&\xca2+8\xf4\xf4ij\xc4\x0cm4\xad" +
+	"\xde\xcd\xac{]\xff\xe1\xf2O^?\xf5\x17\xd3\xba\xbf" +
+	"\xde0\xf9\xcf|\xcd\xa9\x0f\x12\xbf1\xff\xa5/\xea\xd9" +
+	"\xbfQ\x0d\x00\xe8\x02\x0e]\x14\x1a\xd5>\xf39k0" +
+	"cW\xb8\xe4]\xfe\x87\x063\xfb\x85/S0\x9bS" +
+	"\x1fQ\xc35\xbd5\xe7t\x9bL\x89\xd3i\xcc/\xe3" +
+	"\xc7\xd5\x9d\xfb~\xcbp\x859\x87h\x9el\x1af\xb9" +
+	"#4&~\x9b\xf9\xb0Y\xa1\xb2W\xf0\xac\x0a\x95\xe6" +
+	"\xb32b7w\xc6H\xcf\x89\xdd\xfci#\x91\x14W" +
+	"ZI\xb1gA\xc8\xdf\x01.O\xdb\x84\xda\x09\xe8\xb5" +
+	"z\x1f\x89sO\xad\xad\xa9F\xaf\xd5\x04\x88\x0f\x0b\x9d" +
+	"\xe3\xea\xd0k\xd5\xc3\xb3h\xb9\xb1\xde\x1f\x09\x87\"L" +
+	")eL\x9e\x19>\xa2\x15\x9b+\x01\x90\x13/\xa1\x7f" +
+	"\xbcx\x11\xfds\x88c\xe8\x9fS\x1cI\xff\\b\xb9" +
+	"\x0f\xc0\x13\x0c\x055a~\xe7\x0dB@\xed\x15\xa2\x9d" +
+	"!!\x10[ tt.\xf4\xe8ZTOS\x183" +
+	"\xc7,\xad7\xe1\x87\xb6\x9dTi\xdfI\x89\x182\x95" +
+	"\x06\x9bxT\xe6\xd1FJ\x1c+s\xc9`W\xc5w" +
+	"\x97\xa0'+'(\x04,\xcb%\"X} \xaa\xdb" +
+	"F\xcf\x12\xde\xe6\xc4\x9d9\x1c\xe0c\xd1s\xf2h{" +
+	"E\xd3\x9b\xad\xb0\xd1\xa4\xea\xf1#5\xa5\x92\xe6\x05\xf0" +
+	"\xc6\xabh\xe9\x91'\xae\xac\x1dI\xff\x92f8}\x00" +
+	"\xadS\x9c<\xb6\xcer\xda\\LR\x9c%\x00\xad\xd3" +
+	"\x09\xb8\xca\xc9a9w\xc6\x88\x87^i\xb6\xb3\x12\xa0" +
+	"\xb5\x85\x90k\x09\xe1O\x1b\xf1\xf8+]\xcd\x90Y\x84" +
+	"\xcc#\xc4\xf1\xad\x11\xbf\x96Js\x19r\x15!\x1d\x84" +
+	"8\xbf1\xe2\x91XR\x19r-!\xdd\x84\xb8N\x19" +
+	"\x0e\x19]\x00\x92\xc6\x90y\x84\x04\x08\x11N\x1a92" +
+	"+\xbd\xfb\x99l\x1d\x84\x84\x09\xc9\xf9\x9a\xe6\xc9\xa1\xc6" +
+	"\x0c{\xa7\x9b\x10\x9d\x90\xdc\xff\xa5yr\xa9Q\xc3\x90" +
+	"\x00!\xbd\x84\xe4}E\xf3\xe4Q\x97\x8f!aBn" +
+	"&$\xff\xef4O>\x95\xf8\xd9<:!\xb7\x10R" +
+	"\xf07\xa3A\xa6CQZ\xcc\xd4\xd6K\xc82R\x9b" +
+	"\xfbKCF7\xf5\x02\x18p3\x01+\x08(<a" +
+	"\xc8X\x08 -g\xc0-\x04\xdcA\x80\xe7\x0bCF" +
+	"\x0fu\xe8\x9d\x93\x00Z\x97\x11\xf0\x10\x01\xde\xbf\x1a2" +
+	"\xd9PZ\xc3\x80\xfb\x08x\x86\x00\xf1/\x86\x8c\"\x80" +
+	"\xb4\x89\x01\x1b\x08\xd8E@\xd1qC\xc6\"j30" +
+	"\xe0%\x02\xde!@\xfa\xdc\x90Q\xa2\x0e\x1b\x03\xf6\x13" +
+	"\xf0\x05\x01\xf2g\x86\x8c2\xf5\xf4\x9c5\xf4u\x03\x01" +
+	"\x0e\x17\x87\xeeA\xc7\x0c\x19\x07\x01H\xe8\xa27N\x13" +
+	"PL\xc0\xe0O\x0d\x19\x07S\x8b\x84\x01^\x17\x8f\xad" +
+	"\x17\x100\xe4\x13CF\xea\xd1\x8cf\xc0\x08\x02\x1a\x08" +
+	"(\xfe\xd8\x90\xb1\x98\x9a\x96.\x9ac\"\x01\xb3\x08(" +
+	"\xfb\xc8\x90q(\xb9\x98\x8bT2\x9d\x80n\x02\xca\x8f" +
+	"\x1a2\x96\x90\xe5]\x93\xc9\xf2\x04\xdcA\xc0\xb0\xff1" +
+	"d,%]1V\xcb\x08\xb8\x93\x80\x8a\x0f\x0d\x19\xcb" +
+	"\xe8\xbb\x0f\x06\xac \xe0n\x02\xce;b\xc8XN_" +
+	"\xc4\xb8\xda\x00Z\xef$`\x03\x01\xc3?0d\x1cF" +
+	"M3\xd74\x80\xd6G\x08x\x86\x80\xca\xf7\x0d\x19+" +
+	"H\xbb\xaek\x00Z\x9f$`+\x01?y\xcf\x90\xf1" +
+	"<\xfaL\xc05\x13\xa0\xf5y\x02v\xb88,\x1fq" +
+	"\x98<h8\xb5\xa7\x98\xbc[\x09\xd9E\xaf\x8c|\xd7" +
+	"\x90\xb1\x92\x0c\xc2V\xf8\x12\x01\xaf\x11p\xfe;\x86\x8c" +
+	"?\x01\x90v3`\x07\x01o\x10\xf0\xd3?\x1b2\x8e" +
+	"\xa0\xfe\x90\x8b\x9cq\x17\x01\xfb\x09\x18u\xc8\x90q$" +
+	"u\x03\x99\xbco\x10\xf09\x01\xa3\x0f\x1a2\x9eO\xdf" +
+	"&0\xe0\x13\x02\x1c\x02\x87\xee\xa1o\x1b2\xfe\x94L" +
+	"(\x90T\xa7\x09(&\xa0\xe4-C\xc6QdB\x06" +
+	"x\x052!\x01\xa5o\x1a2\x8e&\x132`\x04\x01" +
+	"\x0d\x04\xf8\x0e\x18h\xfb\xc8@\xbaD\xa8\x04\xce]\xb5" +
+	"\xdf\x90\xf1\x02\x00i\xa4@\x8b8/\xc9\xe7\x82\xff6" +
+	"d\x1c\xc3\xf8L\x1a\xc0g\xcc>C\xc6\xb1\xe4\x0a\x0c" +
+	"\x98H@\x13\x01c\xf7\x1a2^H\xcdW\x81t\xdb" +
+	"@\xc0t\x02.|\xc3\x90\xb1\x1a@\x9a\xcaDj\"" +
+	"\xa0\x85\x80\xea\xd7\x0d\x19\xa9\x938C\x88\x90\xf3\x10p" +
+	"\x95\x90<\xd9\xf8E\x8b\xd0k\xf5 \xcd\x03\xacn|" +
+	"\xb28\xd5Y[C\x85o\xcc\x03\x14\xfcu\xe3\xcd\x1c" +
+	"O\xf0\xd7\xd6\x98\xd9\x9c\xe0\x1fWg\xe6T\xbc\x7f\"" +
+	"r\xc0!\x07(\xc4\xea\xc6\x9be\x1b!V[cV" +
+	"b\x85\xd8\xb8:\x14\x80C\x01\x90\x8fM43(O" +
+	"[(\x1403B{e\x1e=m\x81P\x9bY\xb5" +
+	"\xa8\xef\xac\x1bo\xab&\x9a\xf5\xb6\xce\xda\x1a\xdbh^" +
+	"b\xd4?\x80\xd6i\x8e\x0e\xa0u\x98\xa3\xe3\xeal\xa3" +
+	"||\xb4\xc2?\xd16\xc8%Hc\x03\xd8\xe6\x9a\xa3" +
+	"\x03\xd8\xe6\x98\xa3\x03\xd8\x0a\x09\xb61;[W|\xd0" +
+	"\xb3h@\x95\xd4n\x14j\x0d\x12j#\xc8FW\xb1" +
+	"\x88\x92\xe5\xb4#.>N\x9d\xa9\xe4\x17')g%" +
+	"\x0c,\xd2\xa6\x14,-2\x80\x14\x94\x98D\x12%[" +
+	"\xe0CA\xf4Z\xdf\xf0$`V\x0dmS\xa3\x80\x19" +
+	"2\xc7%j<\x11J\xb9Pz\x00\xff_\xf2.\xa6" +
+	"\x8f\x05Z\xbb\xad\xdb\x9a\xdaI\x8b\x13\xa9\xa9DvM" +
+	"\xb1\xe6l(\x14\xb0\xe9(\xd1\x9c]\x92x\xd5\x1cv" +
+	"'\x86\xc9\x83m\xc3\x89\xdcG\xe8\x8a\x84\xb3\xb4\x15\xea" +
+	"i\xd8\xba^\x0f\xc4\xc9\x95\xd4`_\x8b\x1eI\x96\x06" +
+	"\xd5`\x1f+\xbe\x03\xeaX\xe4@@\x1a]\xa2\x06\xd9" +
+	"m\x03\x8b\x1c\x1c\xa0Iw\xa9\x1aV\xdb\xa0\xc2\x1f\xf0" +
+	"\xeb}X\xe4\xe0M$5\xa96k\xa9\x15\xec\xb2\xc9" +
+	"*\x15\xd6\x87PXSq\x99_\x0btdK\xe1;" +
+	"\x09\xb4\x15\xcf\x92ofI\xe1\xaf\xd0\xa2\xba\x16\x19w" +
+	"\xa9\xca\xa7\xb5\x0e|\x16[OT\x8fD\xb3%\x90g" +
+	"i\x0e\xa5\x96k\xb9\xd4\xa2:\xaa\xd9\xbbVV\xa1\xb3" +
+	"\xd2\xd6\xb6\xcaR\x19K\xefa\xcc\x99\xa5E\xa9+\x82" +
+	"\xa9k\xbb&Qv\x9c\xc8\xa1\x11\xd4\xa2\xfa\x0cU\x8f" +
+	"\x00\xef\xefM\xdb\xd8gk\x93$\xdbC\xa8~Ow" +
+	"\xd7&\xf0\xff\x0d\x00\xfd\xc1zf"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
@@ -0,0 +1,733 @@
+// Package dynval converts Cap'n Proto structs to and from generic Go
+// values, using schemas looked up at runtime.  It is the schema-driven
+// half of the converters for self-describing formats such as CBOR and
+// MessagePack, which only need to map the generic values to and from
+// their wire format.
+//
+// A generic value is one of:
+//
+//	nil                  Void, or a null pointer
+//	bool                 Bool
+//	int64                Int8, Int16, Int32 and Int64
+//	uint64               UInt8, UInt16, UInt32, UInt64 and enums
+//	float32, float64     Float32 and Float64
+//	string               Text
+//	[]byte               Data
+//	[]any                lists
+//	Struct               structs and groups
+//
+// When converting to a struct, integers of either signedness are
+// accepted for any integer type that can hold their value, enumerants
+// may also be given by name, integers are accepted for floats, and
+// strings are accepted for Data.  Capabilities and AnyPointer values
+// cannot be converted, and must be null.  Untyped scalars are accepted
+// for any type that can be parsed from text.
+package dynval
+
+import (
+	"errors"
+	"math"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// A Struct is the generic value of a struct: its fields, in code order
+// when converted from a struct.
+type Struct []Field
+
+// A Field is a named field value.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// A Converter converts between structs and generic values.  The zero
+// value uses schemas.DefaultRegistry.
+type Converter struct {
+	nodes nodemap.Map
+}
+
+// UseRegistry changes the registry that the converter consults for
+// schemas from the default registry.
+func (c *Converter) UseRegistry(reg *schemas.Registry) {
+	c.nodes.UseRegistry(reg)
+}
+
+func (c *Converter) findStruct(typeID uint64) (schema.Node, error) {
+	n, err := c.nodes.Find(typeID)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_structNode {
+		return schema.Node{}, errors.New("cannot find struct type " + str.UToHex(typeID))
+	}
+	return n, nil
+}
+
+func (c *Converter) structSize(typeID uint64) (capnp.ObjectSize, error) {
+	n, err := c.findStruct(typeID)
+	if err != nil {
+		return capnp.ObjectSize{}, err
+	}
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructNode().DataWordCount()) * 8,
+		PointerCount: n.StructNode().PointerCount(),
+	}, nil
+}
+
+// FromStruct returns the generic value of s, a struct of the given type.
+// Fields that are not set in the active member of a union are left out.
+func (c *Converter) FromStruct(typeID uint64, s capnp.Struct) (Struct, error) {
+	n, err := c.findStruct(typeID)
+	if err != nil {
+		return nil, err
+	}
+	var discriminant uint16
+	if n.StructNode().DiscriminantCount() > 0 {
+		discriminant = s.Uint16(capnp.DataOffset(n.StructNode().DiscriminantOffset() * 2))
+	}
+	list, err := n.StructNode().Fields()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]schema.Field, list.Len())
+	for i := range fields {
+		f := list.At(i)
+		fields[f.CodeOrder()] = f
+	}
+	v := make(Struct, 0, len(fields))
+	for _, f := range fields {
+		if dv := f.DiscriminantValue(); !(dv == schema.Field_noDiscriminant || dv == discriminant) {
+			continue
+		}
+		name, err := f.Name()
+		if err != nil {
+			return nil, err
+		}
+		var fv any
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			fv, err = c.fromField(s, f)
+		case schema.Field_Which_group:
+			fv, err = c.FromStruct(f.Group().TypeId(), s)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, errors.New("field " + name + ": " + err.Error())
+		}
+		v = append(v, Field{Name: name, Value: fv})
+	}
+	return v, nil
+}
+
+func (c *Converter) fromField(s capnp.Struct, f schema.Field) (any, error) {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return nil, err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return nil, err
+	}
+	if dv.IsValid() && int(typ.Which()) != int(dv.Which()) {
+		return nil, errors.New("default value is a " + dv.Which().String() + ", want " + typ.Which().String())
+	}
+	off := f.Slot().Offset()
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		return nil, nil
+	case schema.Type_Which_bool:
+		return s.Bit(capnp.BitOffset(off)) != dv.Bool(), nil
+	case schema.Type_Which_int8:
+		return int64(int8(s.Uint8(capnp.DataOffset(off)) ^ uint8(dv.Int8()))), nil
+	case schema.Type_Which_int16:
+		return int64(int16(s.Uint16(capnp.DataOffset(off*2)) ^ uint16(dv.Int16()))), nil
+	case schema.Type_Which_int32:
+		return int64(int32(s.Uint32(capnp.DataOffset(off*4)) ^ uint32(dv.Int32()))), nil
+	case schema.Type_Which_int64:
+		return int64(s.Uint64(capnp.DataOffset(off*8)) ^ uint64(dv.Int64())), nil
+	case schema.Type_Which_uint8:
+		return uint64(s.Uint8(capnp.DataOffset(off)) ^ dv.Uint8()), nil
+	case schema.Type_Which_uint16:
+		return uint64(s.Uint16(capnp.DataOffset(off*2)) ^ dv.Uint16()), nil
+	case schema.Type_Which_uint32:
+		return uint64(s.Uint32(capnp.DataOffset(off*4)) ^ dv.Uint32()), nil
+	case schema.Type_Which_uint64:
+		return s.Uint64(capnp.DataOffset(off*8)) ^ dv.Uint64(), nil
+	case schema.Type_Which_float32:
+		return math.Float32frombits(s.Uint32(capnp.DataOffset(off*4)) ^ math.Float32bits(dv.Float32())), nil
+	case schema.Type_Which_float64:
+		return math.Float64frombits(s.Uint64(capnp.DataOffset(off*8)) ^ math.Float64bits(dv.Float64())), nil
+	case schema.Type_Which_enum:
+		return uint64(s.Uint16(capnp.DataOffset(off*2)) ^ dv.Enum()), nil
+	case schema.Type_Which_text:
+		p, err := s.Ptr(uint16(off))
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsValid() {
+			return dv.Text()
+		}
+		return p.Text(), nil
+	case schema.Type_Which_data:
+		p, err := s.Ptr(uint16(off))
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsValid() {
+			return dv.Data()
+		}
+		return p.Data(), nil
+	case schema.Type_Which_structType:
+		p, err := s.Ptr(uint16(off))
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsValid() {
+			if p, _ = dv.StructValue(); !p.IsValid() {
+				return nil, nil
+			}
+		}
+		return c.FromStruct(typ.StructType().TypeId(), p.Struct())
+	case schema.Type_Which_list:
+		p, err := s.Ptr(uint16(off))
+		if err != nil {
+			return nil, err
+		}
+		if !p.IsValid() {
+			if p, _ = dv.List(); !p.IsValid() {
+				return nil, nil
+			}
+		}
+		elem, err := typ.List().ElementType()
+		if err != nil {
+			return nil, err
+		}
+		return c.fromList(elem, p.List())
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		if s.HasPtr(uint16(off)) {
+			return nil, errors.New("cannot convert a non-null " + typ.Which().String())
+		}
+		return nil, nil
+	default:
+		return nil, errors.New("unknown field type " + typ.Which().String())
+	}
+}
+
+func (c *Converter) fromList(elem schema.Type, l capnp.List) ([]any, error) {
+	v := make([]any, l.Len())
+	for i := range v {
+		var err error
+		switch elem.Which() {
+		case schema.Type_Which_void:
+			v[i] = nil
+		case schema.Type_Which_bool:
+			v[i] = capnp.BitList(l).At(i)
+		case schema.Type_Which_int8:
+			v[i] = int64(capnp.Int8List(l).At(i))
+		case schema.Type_Which_int16:
+			v[i] = int64(capnp.Int16List(l).At(i))
+		case schema.Type_Which_int32:
+			v[i] = int64(capnp.Int32List(l).At(i))
+		case schema.Type_Which_int64:
+			v[i] = capnp.Int64List(l).At(i)
+		case schema.Type_Which_uint8:
+			v[i] = uint64(capnp.UInt8List(l).At(i))
+		case schema.Type_Which_uint16, schema.Type_Which_enum:
+			v[i] = uint64(capnp.UInt16List(l).At(i))
+		case schema.Type_Which_uint32:
+			v[i] = uint64(capnp.UInt32List(l).At(i))
+		case schema.Type_Which_uint64:
+			v[i] = capnp.UInt64List(l).At(i)
+		case schema.Type_Which_float32:
+			v[i] = capnp.Float32List(l).At(i)
+		case schema.Type_Which_float64:
+			v[i] = capnp.Float64List(l).At(i)
+		case schema.Type_Which_text:
+			v[i], err = capnp.TextList(l).At(i)
+		case schema.Type_Which_data:
+			v[i], err = capnp.DataList(l).At(i)
+		case schema.Type_Which_structType:
+			v[i], err = c.FromStruct(elem.StructType().TypeId(), l.Struct(i))
+		case schema.Type_Which_list:
+			var p capnp.Ptr
+			p, err = capnp.PointerList(l).At(i)
+			if err != nil || !p.IsValid() {
+				break
+			}
+			var ee schema.Type
+			if ee, err = elem.List().ElementType(); err == nil {
+				v[i], err = c.fromList(ee, p.List())
+			}
+		case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+			var p capnp.Ptr
+			p, err = capnp.PointerList(l).At(i)
+			if err == nil && p.IsValid() {
+				err = errors.New("cannot convert a non-null " + elem.Which().String())
+			}
+		default:
+			err = errors.New("unknown list type " + elem.Which().String())
+		}
+		if err != nil {
+			return nil, errors.New("element " + str.Itod(i) + ": " + err.Error())
+		}
+	}
+	return v, nil
+}
+
+// ToStruct stores v in s, a struct of the given type that must be large
+// enough to hold it.  Fields that are not in v are left unchanged.
+func (c *Converter) ToStruct(typeID uint64, s capnp.Struct, v Struct) error {
+	n, err := c.findStruct(typeID)
+	if err != nil {
+		return err
+	}
+	fields, err := n.StructNode().Fields()
+	if err != nil {
+		return err
+	}
+	unionSet := ""
+	for _, fv := range v {
+		f, ok := findField(fields, fv.Name)
+		if !ok {
+			return errors.New("unknown field " + fv.Name)
+		}
+		if dv := f.DiscriminantValue(); dv != schema.Field_noDiscriminant {
+			if unionSet != "" {
+				return errors.New("fields " + unionSet + " and " + fv.Name + " are in the same union")
+			}
+			unionSet = fv.Name
+			s.SetUint16(capnp.DataOffset(n.StructNode().DiscriminantOffset()*2), dv)
+		}
+		switch f.Which() {
+		case schema.Field_Which_slot:
+			err = c.toField(s, f, fv.Value)
+		case schema.Field_Which_group:
+			g, ok := fv.Value.(Struct)
+			if !ok {
+				err = errors.New("cannot use " + describe(fv.Value) + " as group")
+			} else {
+				err = c.ToStruct(f.Group().TypeId(), s, g)
+			}
+		}
+		if err != nil {
+			return errors.New("field " + fv.Name + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+func findField(fields schema.Field_List, name string) (schema.Field, bool) {
+	for i := 0; i < fields.Len(); i++ {
+		f := fields.At(i)
+		if fname, _ := f.Name(); fname == name {
+			return f, true
+		}
+	}
+	return schema.Field{}, false
+}
+
+func (c *Converter) toField(s capnp.Struct, f schema.Field, v any) error {
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	if u, ok := v.(Untyped); ok {
+		if v, err = resolve(typ.Which(), u); err != nil {
+			return err
+		}
+	}
+	off := f.Slot().Offset()
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		if v != nil {
+			return errors.New("cannot use " + describe(v) + " as void")
+		}
+	case schema.Type_Which_bool:
+		b, ok := v.(bool)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as bool")
+		}
+		s.SetBit(capnp.BitOffset(off), b != dv.Bool())
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64,
+		schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		x, err := toInt(typ.Which(), v)
+		if err != nil {
+			return err
+		}
+		switch typ.Which() {
+		case schema.Type_Which_int8:
+			s.SetUint8(capnp.DataOffset(off), uint8(x)^uint8(dv.Int8()))
+		case schema.Type_Which_int16:
+			s.SetUint16(capnp.DataOffset(off*2), uint16(x)^uint16(dv.Int16()))
+		case schema.Type_Which_int32:
+			s.SetUint32(capnp.DataOffset(off*4), uint32(x)^uint32(dv.Int32()))
+		case schema.Type_Which_int64:
+			s.SetUint64(capnp.DataOffset(off*8), x^uint64(dv.Int64()))
+		case schema.Type_Which_uint8:
+			s.SetUint8(capnp.DataOffset(off), uint8(x)^dv.Uint8())
+		case schema.Type_Which_uint16:
+			s.SetUint16(capnp.DataOffset(off*2), uint16(x)^dv.Uint16())
+		case schema.Type_Which_uint32:
+			s.SetUint32(capnp.DataOffset(off*4), uint32(x)^dv.Uint32())
+		case schema.Type_Which_uint64:
+			s.SetUint64(capnp.DataOffset(off*8), x^dv.Uint64())
+		}
+	case schema.Type_Which_float32:
+		x, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), math.Float32bits(float32(x))^math.Float32bits(dv.Float32()))
+	case schema.Type_Which_float64:
+		x, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), math.Float64bits(x)^math.Float64bits(dv.Float64()))
+	case schema.Type_Which_enum:
+		e, err := c.toEnum(typ.Enum().TypeId(), v)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), e^dv.Enum())
+	case schema.Type_Which_text:
+		if v == nil {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		t, ok := v.(string)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as text")
+		}
+		return s.SetNewText(uint16(off), t)
+	case schema.Type_Which_data:
+		if v == nil {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		b, err := toData(v)
+		if err != nil {
+			return err
+		}
+		return s.SetData(uint16(off), b)
+	case schema.Type_Which_structType:
+		if v == nil {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		sv, ok := v.(Struct)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as struct")
+		}
+		id := typ.StructType().TypeId()
+		sz, err := c.structSize(id)
+		if err != nil {
+			return err
+		}
+		ss, err := capnp.NewStruct(s.Segment(), sz)
+		if err != nil {
+			return err
+		}
+		if err := s.SetPtr(uint16(off), ss.ToPtr()); err != nil {
+			return err
+		}
+		return c.ToStruct(id, ss, sv)
+	case schema.Type_Which_list:
+		if v == nil {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		l, err := c.newList(s.Segment(), typ, v)
+		if err != nil {
+			return err
+		}
+		return s.SetPtr(uint16(off), l.ToPtr())
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		if v != nil {
+			return errors.New("cannot convert a non-null " + typ.Which().String())
+		}
+		return s.SetPtr(uint16(off), capnp.Ptr{})
+	default:
+		return errors.New("unknown field type " + typ.Which().String())
+	}
+	return nil
+}
+
+// newList allocates a list of type typ in seg and fills it from v.
+func (c *Converter) newList(seg *capnp.Segment, typ schema.Type, v any) (capnp.List, error) {
+	elems, ok := v.([]any)
+	if !ok {
+		return capnp.List{}, errors.New("cannot use " + describe(v) + " as list")
+	}
+	elem, err := typ.List().ElementType()
+	if err != nil {
+		return capnp.List{}, err
+	}
+	n := int32(len(elems))
+	var l capnp.List
+	switch elem.Which() {
+	case schema.Type_Which_void:
+		l = capnp.List(capnp.NewVoidList(seg, n))
+	case schema.Type_Which_bool:
+		var bl capnp.BitList
+		bl, err = capnp.NewBitList(seg, n)
+		l = capnp.List(bl)
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		var il capnp.UInt8List
+		il, err = capnp.NewUInt8List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int16, schema.Type_Which_uint16, schema.Type_Which_enum:
+		var il capnp.UInt16List
+		il, err = capnp.NewUInt16List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int32, schema.Type_Which_uint32, schema.Type_Which_float32:
+		var il capnp.UInt32List
+		il, err = capnp.NewUInt32List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int64, schema.Type_Which_uint64, schema.Type_Which_float64:
+		var il capnp.UInt64List
+		il, err = capnp.NewUInt64List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_text, schema.Type_Which_data, schema.Type_Which_list,
+		schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		var pl capnp.PointerList
+		pl, err = capnp.NewPointerList(seg, n)
+		l = capnp.List(pl)
+	case schema.Type_Which_structType:
+		var sz capnp.ObjectSize
+		if sz, err = c.structSize(elem.StructType().TypeId()); err == nil {
+			l, err = capnp.NewCompositeList(seg, sz, n)
+		}
+	default:
+		return capnp.List{}, errors.New("unknown list type " + elem.Which().String())
+	}
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i, ev := range elems {
+		if err := c.setElem(seg, elem, l, i, ev); err != nil {
+			return capnp.List{}, errors.New("element " + str.Itod(i) + ": " + err.Error())
+		}
+	}
+	return l, nil
+}
+
+func (c *Converter) setElem(seg *capnp.Segment, elem schema.Type, l capnp.List, i int, v any) error {
+	if u, ok := v.(Untyped); ok {
+		var err error
+		if v, err = resolve(elem.Which(), u); err != nil {
+			return err
+		}
+	}
+	switch elem.Which() {
+	case schema.Type_Which_void:
+		if v != nil {
+			return errors.New("cannot use " + describe(v) + " as void")
+		}
+	case schema.Type_Which_bool:
+		b, ok := v.(bool)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as bool")
+		}
+		capnp.BitList(l).Set(i, b)
+	case schema.Type_Which_int8, schema.Type_Which_uint8:
+		x, err := toInt(elem.Which(), v)
+		if err != nil {
+			return err
+		}
+		capnp.UInt8List(l).Set(i, uint8(x))
+	case schema.Type_Which_int16, schema.Type_Which_uint16:
+		x, err := toInt(elem.Which(), v)
+		if err != nil {
+			return err
+		}
+		capnp.UInt16List(l).Set(i, uint16(x))
+	case schema.Type_Which_int32, schema.Type_Which_uint32:
+		x, err := toInt(elem.Which(), v)
+		if err != nil {
+			return err
+		}
+		capnp.UInt32List(l).Set(i, uint32(x))
+	case schema.Type_Which_int64, schema.Type_Which_uint64:
+		x, err := toInt(elem.Which(), v)
+		if err != nil {
+			return err
+		}
+		capnp.UInt64List(l).Set(i, x)
+	case schema.Type_Which_float32:
+		x, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		capnp.Float32List(l).Set(i, float32(x))
+	case schema.Type_Which_float64:
+		x, err := toFloat(v)
+		if err != nil {
+			return err
+		}
+		capnp.Float64List(l).Set(i, x)
+	case schema.Type_Which_enum:
+		e, err := c.toEnum(elem.Enum().TypeId(), v)
+		if err != nil {
+			return err
+		}
+		capnp.UInt16List(l).Set(i, e)
+	case schema.Type_Which_text:
+		t, ok := v.(string)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as text")
+		}
+		return capnp.TextList(l).Set(i, t)
+	case schema.Type_Which_data:
+		b, err := toData(v)
+		if err != nil {
+			return err
+		}
+		return capnp.DataList(l).Set(i, b)
+	case schema.Type_Which_structType:
+		sv, ok := v.(Struct)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as struct")
+		}
+		return c.ToStruct(elem.StructType().TypeId(), l.Struct(i), sv)
+	case schema.Type_Which_list:
+		if v == nil {
+			return nil
+		}
+		li, err := c.newList(seg, elem, v)
+		if err != nil {
+			return err
+		}
+		return capnp.PointerList(l).Set(i, li.ToPtr())
+	case schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		if v != nil {
+			return errors.New("cannot convert a non-null " + elem.Which().String())
+		}
+	}
+	return nil
+}
+
+func (c *Converter) toEnum(typeID uint64, v any) (uint16, error) {
+	name, ok := v.(string)
+	if !ok {
+		x, err := toInt(schema.Type_Which_uint16, v)
+		return uint16(x), err
+	}
+	n, err := c.nodes.Find(typeID)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsValid() || n.Which() != schema.Node_Which_enum {
+		return 0, errors.New("type @" + str.UToHex(typeID) + " is not an enum")
+	}
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < enums.Len(); i++ {
+		if ename, _ := enums.At(i).Name(); ename == name {
+			return uint16(i), nil
+		}
+	}
+	return 0, errors.New("unknown enumerant " + name)
+}
+
+// toInt converts v to an integer of type which, returning its two's
+// complement bits.
+func toInt(which schema.Type_Which, v any) (uint64, error) {
+	var (
+		lo   int64
+		hi   uint64
+		name string
+	)
+	switch which {
+	case schema.Type_Which_int8:
+		lo, hi, name = math.MinInt8, math.MaxInt8, "Int8"
+	case schema.Type_Which_int16:
+		lo, hi, name = math.MinInt16, math.MaxInt16, "Int16"
+	case schema.Type_Which_int32:
+		lo, hi, name = math.MinInt32, math.MaxInt32, "Int32"
+	case schema.Type_Which_int64:
+		lo, hi, name = math.MinInt64, math.MaxInt64, "Int64"
+	case schema.Type_Which_uint8:
+		hi, name = math.MaxUint8, "UInt8"
+	case schema.Type_Which_uint16:
+		hi, name = math.MaxUint16, "UInt16"
+	case schema.Type_Which_uint32:
+		hi, name = math.MaxUint32, "UInt32"
+	default:
+		hi, name = math.MaxUint64, "UInt64"
+	}
+	switch x := v.(type) {
+	case int64:
+		if x < lo || x > 0 && uint64(x) > hi {
+			return 0, errors.New(str.Itod(x) + " overflows " + name)
+		}
+		return uint64(x), nil
+	case uint64:
+		if x > hi {
+			return 0, errors.New(str.Utod(x) + " overflows " + name)
+		}
+		return x, nil
+	default:
+		return 0, errors.New("cannot use " + describe(v) + " as " + name)
+	}
+}
+
+func toFloat(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case uint64:
+		return float64(x), nil
+	default:
+		return 0, errors.New("cannot use " + describe(v) + " as float")
+	}
+}
+
+func toData(v any) ([]byte, error) {
+	switch x := v.(type) {
+	case []byte:
+		return x, nil
+	case string:
+		return []byte(x), nil
+	default:
+		return nil, errors.New("cannot use " + describe(v) + " as data")
+	}
+}
+
+// describe returns the kind of the generic value v, for error messages.
+func describe(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case int64, uint64:
+		return "integer"
+	case float32, float64:
+		return "float"
+	case string:
+		return "string"
+	case []byte:
+		return "bytes"
+	case []any:
+		return "list"
+	case Struct:
+		return "struct"
+	case Untyped:
+		return "scalar"
+	default:
+		return "unknown value"
+	}
+}
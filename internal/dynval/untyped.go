@@ -0,0 +1,81 @@
+package dynval
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"capnproto.org/go/capnp/v3/internal/schema"
+)
+
+// An Untyped is a scalar from a format that does not tell strings from
+// other scalars, such as a plain YAML scalar.  It is parsed according
+// to the type of the field or list element that it is stored in, so
+// that "1.0" can be both a Float64 and a Text.
+//
+// The YAML null literals, including the empty string, are null for
+// every type.  Bools are "true" or "false" in any case, integers may
+// have a 0x, 0o or 0b prefix, floats may
+// also be ".inf", "-.inf" or ".nan", and enumerants are given by name
+// or number.
+type Untyped string
+
+// resolve parses u as a value of the given type.
+func resolve(which schema.Type_Which, u Untyped) (any, error) {
+	s := string(u)
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	}
+	switch which {
+	case schema.Type_Which_bool:
+		switch strings.ToLower(s) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, errors.New("cannot use " + strconv.Quote(s) + " as bool")
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64,
+		schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		return parseInt(s)
+	case schema.Type_Which_enum:
+		if x, err := parseInt(s); err == nil {
+			return x, nil
+		}
+		return s, nil
+	case schema.Type_Which_float32, schema.Type_Which_float64:
+		switch strings.ToLower(s) {
+		case ".inf", "+.inf":
+			return math.Inf(1), nil
+		case "-.inf":
+			return math.Inf(-1), nil
+		case ".nan":
+			return math.NaN(), nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.New("cannot use " + strconv.Quote(s) + " as float")
+		}
+		return f, nil
+	case schema.Type_Which_structType, schema.Type_Which_list:
+		return nil, errors.New("cannot use scalar " + strconv.Quote(s) + " as " + which.String())
+	default:
+		return s, nil
+	}
+}
+
+func parseInt(s string) (any, error) {
+	base := 10
+	if digits := strings.TrimLeft(s, "+-"); len(digits) > 2 && digits[0] == '0' && strings.IndexByte("xXoObB", digits[1]) >= 0 {
+		base = 0
+	}
+	if i, err := strconv.ParseInt(s, base, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), base, 64); err == nil {
+		return u, nil
+	}
+	return nil, errors.New("cannot use " + strconv.Quote(s) + " as integer")
+}
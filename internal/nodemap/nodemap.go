@@ -27,6 +27,15 @@ func (m *Map) UseRegistry(reg *schemas.Registry) {
 	m.nodes = make(map[uint64]schema.Node)
 }
 
+// Add makes m return n for its ID, instead of looking it up in the
+// registry.
+func (m *Map) Add(n schema.Node) {
+	if m.nodes == nil {
+		m.nodes = make(map[uint64]schema.Node)
+	}
+	m.nodes[n.Id()] = n
+}
+
 // Find returns the node for the given ID.
 func (m *Map) Find(id uint64) (schema.Node, error) {
 	if n := m.nodes[id]; n.IsValid() {
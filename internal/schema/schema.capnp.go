@@ -50,6 +50,18 @@ func (w Node_Which) String() string {
 	return "Node_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Node_Visitor handles every variant of the Node union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Node_Visitor interface {
+	VisitFile(Node) error
+	VisitStructNode(Node) error
+	VisitEnum(Node) error
+	VisitInterface(Node) error
+	VisitConst(Node) error
+	VisitAnnotation(Node) error
+}
+
 // Node_TypeID is the unique identifier for the type Node.
 const Node_TypeID = 0xe682ab4cf923a417
 
@@ -83,6 +95,26 @@ func (s Node) ToPtr() capnp.Ptr {
 func (s Node) Which() Node_Which {
 	return Node_Which(capnp.Struct(s).Uint16(12))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Node) Visit(v Node_Visitor) error {
+	switch w := s.Which(); w {
+	case Node_Which_file:
+		return v.VisitFile(s)
+	case Node_Which_structNode:
+		return v.VisitStructNode(s)
+	case Node_Which_enum:
+		return v.VisitEnum(s)
+	case Node_Which_interface:
+		return v.VisitInterface(s)
+	case Node_Which_const:
+		return v.VisitConst(s)
+	case Node_Which_annotation:
+		return v.VisitAnnotation(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Node) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -959,6 +991,13 @@ func (w Field_Which) String() string {
 	return "Field_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Field_Visitor handles every variant of the Field union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Field_Visitor interface {
+	VisitSlot(Field) error
+	VisitGroup(Field) error
+}
 type Field_ordinal_Which uint16
 
 const (
@@ -978,6 +1017,14 @@ func (w Field_ordinal_Which) String() string {
 	return "Field_ordinal_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Field_ordinal_Visitor handles every variant of the Field_ordinal union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Field_ordinal_Visitor interface {
+	VisitImplicit(Field_ordinal) error
+	VisitExplicit(Field_ordinal) error
+}
+
 // Field_TypeID is the unique identifier for the type Field.
 const Field_TypeID = 0x9aad50a41f4af45f
 
@@ -1011,6 +1058,18 @@ func (s Field) ToPtr() capnp.Ptr {
 func (s Field) Which() Field_Which {
 	return Field_Which(capnp.Struct(s).Uint16(8))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Field) Visit(v Field_Visitor) error {
+	switch w := s.Which(); w {
+	case Field_Which_slot:
+		return v.VisitSlot(s)
+	case Field_Which_group:
+		return v.VisitGroup(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Field) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -1190,6 +1249,18 @@ func (s Field) Ordinal() Field_ordinal { return Field_ordinal(s) }
 func (s Field_ordinal) Which() Field_ordinal_Which {
 	return Field_ordinal_Which(capnp.Struct(s).Uint16(10))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Field_ordinal) Visit(v Field_ordinal_Visitor) error {
+	switch w := s.Which(); w {
+	case Field_ordinal_Which_implicit:
+		return v.VisitImplicit(s)
+	case Field_ordinal_Which_explicit:
+		return v.VisitExplicit(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Field_ordinal) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -1678,6 +1749,30 @@ func (w Type_Which) String() string {
 	return "Type_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Type_Visitor handles every variant of the Type union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Type_Visitor interface {
+	VisitVoid(Type) error
+	VisitBool(Type) error
+	VisitInt8(Type) error
+	VisitInt16(Type) error
+	VisitInt32(Type) error
+	VisitInt64(Type) error
+	VisitUint8(Type) error
+	VisitUint16(Type) error
+	VisitUint32(Type) error
+	VisitUint64(Type) error
+	VisitFloat32(Type) error
+	VisitFloat64(Type) error
+	VisitText(Type) error
+	VisitData(Type) error
+	VisitList(Type) error
+	VisitEnum(Type) error
+	VisitStructType(Type) error
+	VisitInterface(Type) error
+	VisitAnyPointer(Type) error
+}
 type Type_anyPointer_Which uint16
 
 const (
@@ -1700,6 +1795,14 @@ func (w Type_anyPointer_Which) String() string {
 	return "Type_anyPointer_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Type_anyPointer_Visitor handles every variant of the Type_anyPointer union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Type_anyPointer_Visitor interface {
+	VisitUnconstrained(Type_anyPointer) error
+	VisitParameter(Type_anyPointer) error
+	VisitImplicitMethodParameter(Type_anyPointer) error
+}
 type Type_anyPointer_unconstrained_Which uint16
 
 const (
@@ -1725,6 +1828,16 @@ func (w Type_anyPointer_unconstrained_Which) String() string {
 	return "Type_anyPointer_unconstrained_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Type_anyPointer_unconstrained_Visitor handles every variant of the Type_anyPointer_unconstrained union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Type_anyPointer_unconstrained_Visitor interface {
+	VisitAnyKind(Type_anyPointer_unconstrained) error
+	VisitStruct(Type_anyPointer_unconstrained) error
+	VisitList(Type_anyPointer_unconstrained) error
+	VisitCapability(Type_anyPointer_unconstrained) error
+}
+
 // Type_TypeID is the unique identifier for the type Type.
 const Type_TypeID = 0xd07378ede1f9cc60
 
@@ -1758,6 +1871,52 @@ func (s Type) ToPtr() capnp.Ptr {
 func (s Type) Which() Type_Which {
 	return Type_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Type) Visit(v Type_Visitor) error {
+	switch w := s.Which(); w {
+	case Type_Which_void:
+		return v.VisitVoid(s)
+	case Type_Which_bool:
+		return v.VisitBool(s)
+	case Type_Which_int8:
+		return v.VisitInt8(s)
+	case Type_Which_int16:
+		return v.VisitInt16(s)
+	case Type_Which_int32:
+		return v.VisitInt32(s)
+	case Type_Which_int64:
+		return v.VisitInt64(s)
+	case Type_Which_uint8:
+		return v.VisitUint8(s)
+	case Type_Which_uint16:
+		return v.VisitUint16(s)
+	case Type_Which_uint32:
+		return v.VisitUint32(s)
+	case Type_Which_uint64:
+		return v.VisitUint64(s)
+	case Type_Which_float32:
+		return v.VisitFloat32(s)
+	case Type_Which_float64:
+		return v.VisitFloat64(s)
+	case Type_Which_text:
+		return v.VisitText(s)
+	case Type_Which_data:
+		return v.VisitData(s)
+	case Type_Which_list:
+		return v.VisitList(s)
+	case Type_Which_enum:
+		return v.VisitEnum(s)
+	case Type_Which_structType:
+		return v.VisitStructType(s)
+	case Type_Which_interface:
+		return v.VisitInterface(s)
+	case Type_Which_anyPointer:
+		return v.VisitAnyPointer(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Type) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2036,6 +2195,20 @@ func (s Type) SetAnyPointer() {
 func (s Type_anyPointer) Which() Type_anyPointer_Which {
 	return Type_anyPointer_Which(capnp.Struct(s).Uint16(8))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Type_anyPointer) Visit(v Type_anyPointer_Visitor) error {
+	switch w := s.Which(); w {
+	case Type_anyPointer_Which_unconstrained:
+		return v.VisitUnconstrained(s)
+	case Type_anyPointer_Which_parameter:
+		return v.VisitParameter(s)
+	case Type_anyPointer_Which_implicitMethodParameter:
+		return v.VisitImplicitMethodParameter(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Type_anyPointer) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2058,6 +2231,22 @@ func (s Type_anyPointer) SetUnconstrained() {
 func (s Type_anyPointer_unconstrained) Which() Type_anyPointer_unconstrained_Which {
 	return Type_anyPointer_unconstrained_Which(capnp.Struct(s).Uint16(10))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Type_anyPointer_unconstrained) Visit(v Type_anyPointer_unconstrained_Visitor) error {
+	switch w := s.Which(); w {
+	case Type_anyPointer_unconstrained_Which_anyKind:
+		return v.VisitAnyKind(s)
+	case Type_anyPointer_unconstrained_Which_struct:
+		return v.VisitStruct(s)
+	case Type_anyPointer_unconstrained_Which_list:
+		return v.VisitList(s)
+	case Type_anyPointer_unconstrained_Which_capability:
+		return v.VisitCapability(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Type_anyPointer_unconstrained) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2253,6 +2442,14 @@ func (w Brand_Scope_Which) String() string {
 	return "Brand_Scope_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Brand_Scope_Visitor handles every variant of the Brand_Scope union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Brand_Scope_Visitor interface {
+	VisitBind(Brand_Scope) error
+	VisitInherit(Brand_Scope) error
+}
+
 // Brand_Scope_TypeID is the unique identifier for the type Brand_Scope.
 const Brand_Scope_TypeID = 0xabd73485a9636bc9
 
@@ -2286,6 +2483,18 @@ func (s Brand_Scope) ToPtr() capnp.Ptr {
 func (s Brand_Scope) Which() Brand_Scope_Which {
 	return Brand_Scope_Which(capnp.Struct(s).Uint16(8))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Brand_Scope) Visit(v Brand_Scope_Visitor) error {
+	switch w := s.Which(); w {
+	case Brand_Scope_Which_bind:
+		return v.VisitBind(s)
+	case Brand_Scope_Which_inherit:
+		return v.VisitInherit(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Brand_Scope) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2370,6 +2579,14 @@ func (w Brand_Binding_Which) String() string {
 	return "Brand_Binding_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Brand_Binding_Visitor handles every variant of the Brand_Binding union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Brand_Binding_Visitor interface {
+	VisitUnbound(Brand_Binding) error
+	VisitType(Brand_Binding) error
+}
+
 // Brand_Binding_TypeID is the unique identifier for the type Brand_Binding.
 const Brand_Binding_TypeID = 0xc863cd16969ee7fc
 
@@ -2403,6 +2620,18 @@ func (s Brand_Binding) ToPtr() capnp.Ptr {
 func (s Brand_Binding) Which() Brand_Binding_Which {
 	return Brand_Binding_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Brand_Binding) Visit(v Brand_Binding_Visitor) error {
+	switch w := s.Which(); w {
+	case Brand_Binding_Which_unbound:
+		return v.VisitUnbound(s)
+	case Brand_Binding_Which_type:
+		return v.VisitType(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Brand_Binding) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2531,6 +2760,31 @@ func (w Value_Which) String() string {
 	return "Value_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Value_Visitor handles every variant of the Value union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Value_Visitor interface {
+	VisitVoid(Value) error
+	VisitBool(Value) error
+	VisitInt8(Value) error
+	VisitInt16(Value) error
+	VisitInt32(Value) error
+	VisitInt64(Value) error
+	VisitUint8(Value) error
+	VisitUint16(Value) error
+	VisitUint32(Value) error
+	VisitUint64(Value) error
+	VisitFloat32(Value) error
+	VisitFloat64(Value) error
+	VisitText(Value) error
+	VisitData(Value) error
+	VisitList(Value) error
+	VisitEnum(Value) error
+	VisitStructValue(Value) error
+	VisitInterface(Value) error
+	VisitAnyPointer(Value) error
+}
+
 // Value_TypeID is the unique identifier for the type Value.
 const Value_TypeID = 0xce23dcd2d7b00c9b
 
@@ -2564,6 +2818,52 @@ func (s Value) ToPtr() capnp.Ptr {
 func (s Value) Which() Value_Which {
 	return Value_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Value) Visit(v Value_Visitor) error {
+	switch w := s.Which(); w {
+	case Value_Which_void:
+		return v.VisitVoid(s)
+	case Value_Which_bool:
+		return v.VisitBool(s)
+	case Value_Which_int8:
+		return v.VisitInt8(s)
+	case Value_Which_int16:
+		return v.VisitInt16(s)
+	case Value_Which_int32:
+		return v.VisitInt32(s)
+	case Value_Which_int64:
+		return v.VisitInt64(s)
+	case Value_Which_uint8:
+		return v.VisitUint8(s)
+	case Value_Which_uint16:
+		return v.VisitUint16(s)
+	case Value_Which_uint32:
+		return v.VisitUint32(s)
+	case Value_Which_uint64:
+		return v.VisitUint64(s)
+	case Value_Which_float32:
+		return v.VisitFloat32(s)
+	case Value_Which_float64:
+		return v.VisitFloat64(s)
+	case Value_Which_text:
+		return v.VisitText(s)
+	case Value_Which_data:
+		return v.VisitData(s)
+	case Value_Which_list:
+		return v.VisitList(s)
+	case Value_Which_enum:
+		return v.VisitEnum(s)
+	case Value_Which_structValue:
+		return v.VisitStructValue(s)
+	case Value_Which_interface:
+		return v.VisitInterface(s)
+	case Value_Which_anyPointer:
+		return v.VisitAnyPointer(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Value) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -0,0 +1,193 @@
+// Package lease grants access to capabilities for a limited time.
+//
+// A client returned by New forwards calls to its target until the
+// lease expires.  After that, calls fail with an exception wrapping
+// ErrExpired and the target is released, so access granted for a
+// session does not outlive it even if the holder never releases the
+// client.  The grantor can extend the lease with Lease.Renew, or end
+// it early with Lease.Revoke.
+package lease // import "capnproto.org/go/capnp/v3/lease"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+)
+
+// ErrExpired is the cause of the error returned for calls made on a
+// leased client after its lease has expired or been revoked.  Calls
+// made over a Conn see a disconnected exception with the same message.
+var ErrExpired = errors.New("capability lease expired")
+
+// excExpired is the error returned for calls on an expired lease.
+var excExpired = exc.Annotator("lease").Disconnected(ErrExpired)
+
+// Options configures a Lease.
+type Options struct {
+	// Clock is used to expire the lease.  If nil, clock.System is used.
+	Clock clock.Clock
+}
+
+// A Lease controls how long a client returned by New forwards calls to
+// its target.  It is safe to use from multiple goroutines.
+type Lease struct {
+	clock clock.Clock
+	timer clock.Timer
+	done  chan struct{}
+
+	mu      sync.Mutex
+	expires time.Time
+	target  capnp.Client
+	revoked bool // set once target has been released
+}
+
+// New returns a client that forwards calls to target for ttl, and a
+// Lease to control it.  New takes ownership of target: it is released
+// when the lease expires or the returned client is shut down, whichever
+// happens first.  If opts is nil, the defaults are used.
+func New(target capnp.Client, ttl time.Duration, opts *Options) (capnp.Client, *Lease) {
+	l := &Lease{
+		clock:  clock.System,
+		done:   make(chan struct{}),
+		target: target,
+	}
+	if opts != nil && opts.Clock != nil {
+		l.clock = opts.Clock
+	}
+	l.expires = l.clock.Now().Add(ttl)
+	l.timer = l.clock.NewTimer(ttl)
+	go l.watch()
+	return capnp.NewClient(&leaseHook{l}), l
+}
+
+// watch expires l once its timer fires after the expiry time.
+func (l *Lease) watch() {
+	for {
+		select {
+		case <-l.timer.Chan():
+		case <-l.done:
+			return
+		}
+		l.mu.Lock()
+		left := l.expires.Sub(l.clock.Now())
+		if left > 0 {
+			// Renewed since the timer was set.
+			l.timer.Reset(left)
+			l.mu.Unlock()
+			continue
+		}
+		l.mu.Unlock()
+		l.Revoke()
+		return
+	}
+}
+
+// Expires returns the time at which the lease expires.
+func (l *Lease) Expires() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expires
+}
+
+// Renew extends the lease so that it expires ttl from now.  It reports
+// false if the lease had already expired, in which case it stays
+// expired.
+func (l *Lease) Renew(ttl time.Duration) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.expiredLocked() {
+		return false
+	}
+	l.expires = l.clock.Now().Add(ttl)
+	l.timer.Reset(ttl)
+	return true
+}
+
+// Revoke ends the lease immediately, releasing the target.  It is a
+// no-op if the lease has already expired.
+func (l *Lease) Revoke() {
+	l.mu.Lock()
+	if l.revoked {
+		l.mu.Unlock()
+		return
+	}
+	l.revoked = true
+	target := l.target
+	l.target = capnp.Client{}
+	if now := l.clock.Now(); now.Before(l.expires) {
+		l.expires = now
+	}
+	close(l.done)
+	l.mu.Unlock()
+
+	l.timer.Stop()
+	target.Release()
+}
+
+// Done returns a channel that is closed once the lease has expired or
+// been revoked.
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// acquire returns a new reference to the target, or false if the lease
+// has expired.
+func (l *Lease) acquire() (capnp.Client, bool) {
+	l.mu.Lock()
+	if !l.expiredLocked() {
+		defer l.mu.Unlock()
+		return l.target.AddRef(), true
+	}
+	l.mu.Unlock()
+
+	// The timer may not have fired yet.
+	l.Revoke()
+	return capnp.Client{}, false
+}
+
+// expiredLocked reports whether l has expired.  The caller must hold
+// l.mu.
+func (l *Lease) expiredLocked() bool {
+	return l.revoked || !l.clock.Now().Before(l.expires)
+}
+
+// A leaseHook is the capnp.ClientHook behind a client returned by New.
+type leaseHook struct {
+	l *Lease
+}
+
+func (h *leaseHook) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	target, ok := h.l.acquire()
+	if !ok {
+		return capnp.ErrorAnswer(s.Method, excExpired), func() {}
+	}
+	defer target.Release()
+	return target.SendCall(ctx, s)
+}
+
+func (h *leaseHook) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	target, ok := h.l.acquire()
+	if !ok {
+		r.Reject(excExpired)
+		return nil
+	}
+	defer target.Release()
+	return target.RecvCall(ctx, r)
+}
+
+func (h *leaseHook) Brand() capnp.Brand {
+	return capnp.Brand{}
+}
+
+func (h *leaseHook) Shutdown() {
+	h.l.Revoke()
+}
+
+func (h *leaseHook) String() string {
+	return "lease@" + h.l.Expires().String()
+}
@@ -0,0 +1,106 @@
+package lease_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/lease"
+)
+
+// echoImpl is an Echo server that closes shutdown when it is released.
+type echoImpl struct {
+	shutdown chan struct{}
+}
+
+func (echoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + in)
+}
+
+func (e echoImpl) Shutdown() {
+	close(e.shutdown)
+}
+
+func echo(ctx context.Context, e air.Echo, in string) (string, error) {
+	ans, release := e.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn(in)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+	return res.Out()
+}
+
+func TestLease(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clk := clock.NewManual(time.Unix(1000, 0))
+	impl := echoImpl{shutdown: make(chan struct{})}
+	c, l := lease.New(capnp.Client(air.Echo_ServerToClient(impl)), time.Minute, &lease.Options{Clock: clk})
+	e := air.Echo(c)
+	defer e.Release()
+
+	out, err := echo(ctx, e, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foofoo", out)
+
+	// Renewing pushes the expiry back.
+	clk.Advance(30 * time.Second)
+	require.True(t, l.Renew(time.Minute))
+	assert.Equal(t, clk.Now().Add(time.Minute), l.Expires())
+	clk.Advance(45 * time.Second)
+	_, err = echo(ctx, e, "foo")
+	require.NoError(t, err, "call after renewal should succeed")
+
+	clk.Advance(15 * time.Second)
+	<-l.Done()
+	<-impl.shutdown
+
+	_, err = echo(ctx, e, "foo")
+	assert.ErrorIs(t, err, lease.ErrExpired)
+	assert.True(t, exc.IsType(err, exc.Disconnected), "error should be disconnected: %v", err)
+	assert.False(t, l.Renew(time.Minute), "expired lease should not renew")
+}
+
+func TestLeaseRevoke(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	impl := echoImpl{shutdown: make(chan struct{})}
+	c, l := lease.New(capnp.Client(air.Echo_ServerToClient(impl)), time.Hour, nil)
+	e := air.Echo(c)
+	defer e.Release()
+
+	l.Revoke()
+	<-impl.shutdown
+	_, err := echo(ctx, e, "foo")
+	assert.ErrorIs(t, err, lease.ErrExpired)
+}
+
+func TestLeaseRelease(t *testing.T) {
+	t.Parallel()
+
+	impl := echoImpl{shutdown: make(chan struct{})}
+	c, l := lease.New(capnp.Client(air.Echo_ServerToClient(impl)), time.Hour, nil)
+	c.Release()
+	<-l.Done()
+	<-impl.shutdown
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"io"
 	"sync"
-	"sync/atomic"
 
 	"capnproto.org/go/capnp/v3/exc"
 	"capnproto.org/go/capnp/v3/internal/str"
@@ -31,9 +30,9 @@ const maxDepth = ^uint(0)
 // A message must be set up with a fully valid Arena when reading or with
 // a valid and empty arena by calling NewArena.
 type Message struct {
-	// rlimit must be first so that it is 64-bit aligned.
-	// See sync/atomic docs.
-	rlimit     atomic.Uint64
+	// rlimit is the read limiter used when ReadLimiter is nil.  It
+	// must be first so that it is 64-bit aligned.  See sync/atomic docs.
+	rlimit     readCounter
 	rlimitInit sync.Once
 
 	Arena Arena
@@ -48,12 +47,28 @@ type Message struct {
 	// errors. See https://capnproto.org/encoding.html#amplification-attack
 	// for more details on this security measure.
 	//
-	// If not set, this defaults to 64 MiB.
+	// If not set, this defaults to 64 MiB.  It is ignored if ReadLimiter
+	// is set.
 	TraverseLimit uint64
 
+	// ReadLimiter, if not nil, limits the bytes traversed while reading
+	// instead of TraverseLimit.  See ReadLimiter for details.  Reset
+	// keeps the limiter.
+	ReadLimiter ReadLimiter
+
 	// DepthLimit limits how deeply-nested a message structure can be.
 	// If not set, this defaults to 64.
 	DepthLimit uint
+
+	// ReadBudget, if not nil, is charged for the bytes traversed
+	// while reading, in addition to TraverseLimit, and reads fail once
+	// it runs out.  A budget can be shared by many messages, such as
+	// all the messages received from one peer.  Reset keeps the
+	// budget, and does not give back the bytes charged to it.
+	ReadBudget *ReadBudget
+
+	// owner is the goroutine that owns the message; see Handoff.
+	owner messageOwner
 }
 
 // NewMessage creates a message with a new root and returns the first segment.
@@ -109,7 +124,9 @@ func (m *Message) Reset(arena Arena) (first *Segment, err error) {
 	*m = Message{
 		Arena:         arena,
 		TraverseLimit: m.TraverseLimit,
+		ReadLimiter:   m.ReadLimiter,
 		DepthLimit:    m.DepthLimit,
+		ReadBudget:    m.ReadBudget,
 		capTable:      m.capTable,
 	}
 
@@ -136,39 +153,66 @@ func (m *Message) Reset(arena Arena) (first *Segment, err error) {
 
 func (m *Message) initReadLimit() {
 	if m.TraverseLimit == 0 {
-		m.rlimit.Store(defaultTraverseLimit)
+		m.rlimit.n.Store(defaultTraverseLimit)
 		return
 	}
-	m.rlimit.Store(m.TraverseLimit)
+	m.rlimit.n.Store(m.TraverseLimit)
 }
 
+var errReadLimit = errors.New("read traversal limit reached")
+
 // canRead reports whether the amount of bytes can be stored safely.
-func (m *Message) canRead(sz Size) (ok bool) {
-	m.rlimitInit.Do(m.initReadLimit)
-	for {
-		curr := m.rlimit.Load()
+func (m *Message) canRead(sz Size) bool {
+	return m.chargeRead(sz) == nil
+}
 
-		var new uint64
-		if ok = curr >= uint64(sz); ok {
-			new = curr - uint64(sz)
+// chargeRead deducts sz from the message's read limit and ReadBudget.
+// If either does not allow sz more bytes, it returns an error and
+// deducts nothing.
+func (m *Message) chargeRead(sz Size) error {
+	// The default limiter is called directly, rather than through the
+	// interface, since this is on the path of every pointer read.
+	if m.ReadLimiter != nil {
+		if !m.ReadLimiter.CanRead(sz) {
+			return errReadLimit
 		}
-
-		if m.rlimit.CompareAndSwap(curr, new) {
-			return
+	} else {
+		m.rlimitInit.Do(m.initReadLimit)
+		if !m.rlimit.CanRead(sz) {
+			return errReadLimit
 		}
 	}
+	if m.ReadBudget != nil && !m.ReadBudget.take(sz) {
+		m.unreadLimit(sz)
+		return ErrReadBudgetExhausted
+	}
+	return nil
 }
 
-// ResetReadLimit sets the number of bytes allowed to be read from this message.
+// ResetReadLimit sets the number of bytes allowed to be read from this
+// message.  It has no effect on the message's ReadLimiter, if any.
 func (m *Message) ResetReadLimit(limit uint64) {
 	m.rlimitInit.Do(func() {})
-	m.rlimit.Store(limit)
+	m.rlimit.n.Store(limit)
 }
 
-// Unread increases the read limit by sz.
+// Unread increases the read limit by sz, and returns sz to the
+// message's ReadBudget, if any.
 func (m *Message) Unread(sz Size) {
+	m.unreadLimit(sz)
+	if m.ReadBudget != nil {
+		m.ReadBudget.Add(uint64(sz))
+	}
+}
+
+// unreadLimit gives back sz to the message's read limiter.
+func (m *Message) unreadLimit(sz Size) {
+	if m.ReadLimiter != nil {
+		m.ReadLimiter.Unread(sz)
+		return
+	}
 	m.rlimitInit.Do(m.initReadLimit)
-	m.rlimit.Add(uint64(sz))
+	m.rlimit.Unread(sz)
 }
 
 func (m *Message) allocRootPointerSpace() (*Segment, error) {
@@ -224,6 +268,7 @@ func (m *Message) Root() (Ptr, error) {
 
 // SetRoot sets the message's root object to p.
 func (m *Message) SetRoot(p Ptr) error {
+	m.checkOwner("set root")
 	// TODO: enforcement of root pointer space is only needed here because
 	// a single call in package capnpc-go (in file fileparts.go) calls
 	// SetRoot() without first allocating space. This is likely an erroneous
@@ -301,64 +346,73 @@ func (m *Message) WriteTo(w io.Writer) (int64, error) {
 // Marshal concatenates the segments in the message into a single byte
 // slice including framing.
 func (m *Message) Marshal() ([]byte, error) {
-	// Compute buffer size.
+	hdr, segs, total, err := m.framing()
+	if err != nil {
+		return nil, exc.WrapError("marshal", err)
+	}
+	buf := make([]byte, 0, total)
+	buf = append(buf, hdr...)
+	for _, data := range segs {
+		buf = append(buf, data...)
+	}
+	return buf, nil
+}
+
+// MarshalPacked marshals the message in packed form.  The segments are
+// packed directly, without marshaling the message first.
+func (m *Message) MarshalPacked() ([]byte, error) {
+	hdr, segs, _, err := m.framing()
+	if err != nil {
+		return nil, exc.WrapError("marshal", err)
+	}
+	size := packed.EstimateSize(hdr)
+	for _, data := range segs {
+		size += packed.EstimateSize(data)
+	}
+	buf := make([]byte, 0, size)
+	buf = packed.Pack(buf, hdr)
+	for _, data := range segs {
+		buf = packed.Pack(buf, data)
+	}
+	return buf, nil
+}
+
+// framing returns the stream header of the message, the data of its
+// segments and the total size of the serialized message.
+func (m *Message) framing() (hdr []byte, segs [][]byte, total int, err error) {
+	m.checkOwner("marshal")
 	nsegs := m.NumSegments()
 	if nsegs == 0 {
-		return nil, errors.New("marshal: message has no segments")
+		return nil, nil, 0, errors.New("message has no segments")
 	}
 	hdrSize := streamHeaderSize(SegmentID(nsegs - 1))
 	if hdrSize > uint64(maxInt) {
-		return nil, errors.New("marshal: header size overflows int")
+		return nil, nil, 0, errors.New("header size overflows int")
 	}
-	var dataSize uint64
+	hdr = make([]byte, int(hdrSize))
+	binary.LittleEndian.PutUint32(hdr, uint32(nsegs-1))
+	segs = make([][]byte, 0, nsegs)
+	size := hdrSize
 	for i := int64(0); i < nsegs; i++ {
 		s, err := m.Segment(SegmentID(i))
 		if err != nil {
-			return nil, exc.WrapError("marshal", err)
+			return nil, nil, 0, err
 		}
 		n := uint64(len(s.data))
 		if n%uint64(wordSize) != 0 {
-			return nil, errors.New("marshal: segment " + str.Itod(i) + " not word-aligned")
+			return nil, nil, 0, errors.New("segment " + str.Itod(i) + " not word-aligned")
 		}
 		if n > uint64(maxSegmentSize) {
-			return nil, errors.New("marshal: segment " + str.Itod(i) + " too large")
-		}
-		dataSize += n
-		if dataSize > uint64(maxInt) {
-			return nil, errors.New("marshal: message size overflows int")
-		}
-	}
-	total := hdrSize + dataSize
-	if total > uint64(maxInt) {
-		return nil, errors.New("marshal: message size overflows int")
-	}
-
-	// Fill buffer.
-	buf := make([]byte, int(hdrSize), int(total))
-	binary.LittleEndian.PutUint32(buf, uint32(nsegs-1))
-	for i := int64(0); i < nsegs; i++ {
-		s, err := m.Segment(SegmentID(i))
-		if err != nil {
-			return nil, exc.WrapError("marshal", err)
+			return nil, nil, 0, errors.New("segment " + str.Itod(i) + " too large")
 		}
-		if len(s.data)%int(wordSize) != 0 {
-			return nil, errors.New("marshal: segment " + str.Itod(i) + " not word-aligned")
+		size += n
+		if size > uint64(maxInt) {
+			return nil, nil, 0, errors.New("message size overflows int")
 		}
-		binary.LittleEndian.PutUint32(buf[int(i+1)*4:], uint32(len(s.data)/int(wordSize)))
-		buf = append(buf, s.data...)
+		binary.LittleEndian.PutUint32(hdr[int(i+1)*4:], uint32(n/uint64(wordSize)))
+		segs = append(segs, s.data)
 	}
-	return buf, nil
-}
-
-// MarshalPacked marshals the message in packed form.
-func (m *Message) MarshalPacked() ([]byte, error) {
-	data, err := m.Marshal()
-	if err != nil {
-		return nil, err
-	}
-	buf := make([]byte, 0, len(data))
-	buf = packed.Pack(buf, data)
-	return buf, nil
+	return hdr, segs, int(size), nil
 }
 
 type writeCounter struct {
@@ -385,6 +439,7 @@ func alloc(s *Segment, sz Size) (*Segment, address, error) {
 	if msg == nil {
 		return nil, 0, errors.New("segment does not have a message assotiated with it")
 	}
+	msg.checkOwner("allocate")
 	if msg.Arena == nil {
 		return nil, 0, errors.New("message does not have an arena")
 	}
@@ -10,6 +10,7 @@ import (
 	"testing/quick"
 
 	"capnproto.org/go/capnp/v3/exp/bufferpool"
+	"capnproto.org/go/capnp/v3/packed"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -321,6 +322,31 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshalPacked(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range serializeTests {
+		if test.decodeFails || test.newMessageFails || test.encodeFails {
+			continue
+		}
+		msg, _, err := NewMessage(test.arena())
+		require.NoError(t, err)
+		out, err := msg.MarshalPacked()
+		if err != nil {
+			t.Errorf("serializeTests[%d] %s: MarshalPacked error: %v", i, test.name, err)
+			continue
+		}
+		unpacked, err := packed.Unpack(nil, out)
+		if err != nil {
+			t.Errorf("serializeTests[%d] %s: Unpack error: %v", i, test.name, err)
+			continue
+		}
+		if !bytes.Equal(unpacked, test.out) {
+			t.Errorf("serializeTests[%d] - %s: unpacked MarshalPacked = % 02x; want % 02x", i, test.name, unpacked, test.out)
+		}
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,104 @@
+package capnp
+
+import "sync"
+
+// MethodInfo describes a method of an interface declared in a schema.
+type MethodInfo struct {
+	// Method identifies the method.  InterfaceName and MethodName are
+	// always set.
+	Method
+
+	// ParamsTypeID and ResultsTypeID are the IDs of the method's
+	// parameter and result struct types.
+	ParamsTypeID  uint64
+	ResultsTypeID uint64
+
+	// ResultsSize is the size of the method's result struct.
+	ResultsSize ObjectSize
+}
+
+// methodRegistry holds the methods passed to RegisterMethods, indexed by
+// interface ID and then by method ID, and the fingerprints passed to
+// RegisterFingerprint, indexed by interface ID.
+var methodRegistry struct {
+	mu           sync.RWMutex
+	methods      map[uint64][]MethodInfo
+	fingerprints map[uint64]uint64
+}
+
+// RegisterMethods adds methods to the process-wide method registry.
+// Code generated by capnpc-go registers the methods of each interface
+// it declares, so most programs will not need to call this directly.
+// Registering a method again replaces the previous entry.
+func RegisterMethods(methods ...MethodInfo) {
+	methodRegistry.mu.Lock()
+	defer methodRegistry.mu.Unlock()
+	if methodRegistry.methods == nil {
+		methodRegistry.methods = make(map[uint64][]MethodInfo)
+	}
+	for _, m := range methods {
+		ms := methodRegistry.methods[m.InterfaceID]
+		for len(ms) <= int(m.MethodID) {
+			ms = append(ms, MethodInfo{})
+		}
+		ms[m.MethodID] = m
+		methodRegistry.methods[m.InterfaceID] = ms
+	}
+}
+
+// LookupMethod returns the registered information about a method.
+func LookupMethod(interfaceID uint64, methodID uint16) (MethodInfo, bool) {
+	methodRegistry.mu.RLock()
+	defer methodRegistry.mu.RUnlock()
+	ms := methodRegistry.methods[interfaceID]
+	if int(methodID) >= len(ms) || ms[methodID].MethodName == "" {
+		return MethodInfo{}, false
+	}
+	return ms[methodID], true
+}
+
+// InterfaceMethods returns the registered methods of an interface,
+// ordered by method ID, or nil if none are registered.  Methods
+// inherited from superclasses are not included.
+func InterfaceMethods(interfaceID uint64) []MethodInfo {
+	methodRegistry.mu.RLock()
+	defer methodRegistry.mu.RUnlock()
+	var out []MethodInfo
+	for _, m := range methodRegistry.methods[interfaceID] {
+		if m.MethodName != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// RegisterFingerprint records the schema fingerprint of an interface in
+// the process-wide method registry.  Code generated by capnpc-go
+// registers the fingerprint of each interface it declares, which is
+// also available as the interface's Fingerprint constant.
+//
+// The fingerprint is a hash of the interface's methods, including
+// inherited ones, and of the layout of their parameter and result
+// structs.  It does not depend on the path the schema was compiled
+// from, so two programs generated from the same schema agree on it,
+// while a change to the methods or their parameters or results
+// changes it.  Comparing fingerprints between peers detects programs
+// that were built from different versions of a schema; see the
+// std/reflection package.
+func RegisterFingerprint(interfaceID, fingerprint uint64) {
+	methodRegistry.mu.Lock()
+	defer methodRegistry.mu.Unlock()
+	if methodRegistry.fingerprints == nil {
+		methodRegistry.fingerprints = make(map[uint64]uint64)
+	}
+	methodRegistry.fingerprints[interfaceID] = fingerprint
+}
+
+// LookupFingerprint returns the registered schema fingerprint of an
+// interface.
+func LookupFingerprint(interfaceID uint64) (uint64, bool) {
+	methodRegistry.mu.RLock()
+	defer methodRegistry.mu.RUnlock()
+	fp, ok := methodRegistry.fingerprints[interfaceID]
+	return fp, ok
+}
@@ -0,0 +1,76 @@
+package capnp_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+)
+
+func TestGeneratedMethodInfo(t *testing.T) {
+	t.Parallel()
+
+	info, ok := capnp.LookupMethod(air.Echo_TypeID, 0)
+	require.True(t, ok, "Echo.echo is registered")
+	assert.Equal(t, capnp.MethodInfo{
+		Method: capnp.Method{
+			InterfaceID:   air.Echo_TypeID,
+			MethodID:      0,
+			InterfaceName: "aircraft.capnp:Echo",
+			MethodName:    "echo",
+		},
+		ParamsTypeID:  air.Echo_echo_Params_TypeID,
+		ResultsTypeID: air.Echo_echo_Results_TypeID,
+		ResultsSize:   capnp.ObjectSize{PointerCount: 1},
+	}, info)
+
+	_, ok = capnp.LookupMethod(air.Echo_TypeID, 1)
+	assert.False(t, ok, "Echo has a single method")
+	assert.Len(t, capnp.InterfaceMethods(air.Echo_TypeID), 1)
+}
+
+func TestRegisterMethods(t *testing.T) {
+	t.Parallel()
+
+	const ifaceID = 0xbc6dbaf66a4d7a3e // not declared in any schema
+	m := capnp.Method{InterfaceID: ifaceID, MethodID: 2}
+	assert.Equal(t, "@0xbc6dbaf66a4d7a3e.@2", m.String())
+
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   ifaceID,
+				MethodID:      2,
+				InterfaceName: "test.capnp:Foo",
+				MethodName:    "bar",
+			},
+			ParamsTypeID:  1,
+			ResultsTypeID: 2,
+		},
+	)
+	assert.Equal(t, "test.capnp:Foo.bar", m.String(), "names are looked up")
+	named := capnp.Method{InterfaceID: ifaceID, MethodID: 2, MethodName: "baz"}
+	assert.Equal(t, "test.capnp:Foo.baz", named.String(), "names already set take precedence")
+
+	_, ok := capnp.LookupMethod(ifaceID, 0)
+	assert.False(t, ok, "unregistered method in a registered interface")
+	ms := capnp.InterfaceMethods(ifaceID)
+	require.Len(t, ms, 1)
+	assert.Equal(t, uint64(1), ms[0].ParamsTypeID)
+	assert.Equal(t, uint64(2), ms[0].ResultsTypeID)
+}
+
+func TestGeneratedFingerprint(t *testing.T) {
+	t.Parallel()
+
+	fp, ok := capnp.LookupFingerprint(air.Echo_TypeID)
+	require.True(t, ok, "Echo's fingerprint is registered")
+	assert.Equal(t, uint64(air.Echo_Fingerprint), fp)
+	assert.NotEqual(t, uint64(air.Echo_Fingerprint), uint64(air.CallSequence_Fingerprint))
+
+	_, ok = capnp.LookupFingerprint(0xbc6dbaf66a4d7a3f)
+	assert.False(t, ok)
+}
@@ -0,0 +1,109 @@
+package capnp
+
+import "errors"
+
+// An Orphan is an object in a message that is not referenced by any
+// pointer in the message, such as a struct or list that was detached
+// from its parent with Disown.  Adopting an Orphan links the object back
+// into the message without copying it, so subtrees can be moved around
+// or assembled before the parent that will hold them exists.
+//
+// Orphans can only be adopted into the message they belong to.  The
+// space of an Orphan that is never adopted is not reclaimed until the
+// message is reset.  The zero value is a null orphan.
+type Orphan struct {
+	ptr Ptr
+}
+
+// NewOrphan returns an Orphan for p, an object that has been allocated
+// in a message but not linked into it, such as a struct returned by
+// NewStruct or a list returned by NewCompositeList.  The caller must
+// not also store p in the message.
+func NewOrphan(p Ptr) Orphan {
+	return Orphan{ptr: p}
+}
+
+// IsValid reports whether o is not null.
+func (o Orphan) IsValid() bool {
+	return o.ptr.IsValid()
+}
+
+// Ptr returns the orphaned object.  It may be read and modified as
+// usual while it is orphaned.
+func (o Orphan) Ptr() Ptr {
+	return o.ptr
+}
+
+// Message returns the message that o belongs to, or nil if o is null.
+func (o Orphan) Message() *Message {
+	return o.ptr.Message()
+}
+
+// Disown detaches the i'th pointer of p, leaving it null, and returns
+// the object it pointed to as an Orphan.
+func (p Struct) Disown(i uint16) (Orphan, error) {
+	ptr, err := p.Ptr(i)
+	if err != nil {
+		return Orphan{}, err
+	}
+	if err := p.SetPtr(i, Ptr{}); err != nil {
+		return Orphan{}, err
+	}
+	return Orphan{ptr: ptr}, nil
+}
+
+// Adopt sets the i'th pointer of p to the object held by o, without
+// copying it, and clears o.  It returns an error if o belongs to a
+// different message, or if it is a struct inside a list, which can't be
+// pointed to.  Adopting a null Orphan sets the pointer to null.
+func (p Struct) Adopt(i uint16, o *Orphan) error {
+	if err := o.check(p.Message()); err != nil {
+		return err
+	}
+	if err := p.SetPtr(i, o.ptr); err != nil {
+		return err
+	}
+	*o = Orphan{}
+	return nil
+}
+
+// Disown detaches the i'th element of p, leaving it null, and returns
+// the object it pointed to as an Orphan.
+func (p PointerList) Disown(i int) (Orphan, error) {
+	ptr, err := p.At(i)
+	if err != nil {
+		return Orphan{}, err
+	}
+	if err := p.Set(i, Ptr{}); err != nil {
+		return Orphan{}, err
+	}
+	return Orphan{ptr: ptr}, nil
+}
+
+// Adopt sets the i'th element of p to the object held by o, like
+// Struct.Adopt.
+func (p PointerList) Adopt(i int, o *Orphan) error {
+	if err := o.check(p.Message()); err != nil {
+		return err
+	}
+	if err := p.Set(i, o.ptr); err != nil {
+		return err
+	}
+	*o = Orphan{}
+	return nil
+}
+
+// check returns an error if o can't be adopted into msg without being
+// copied.
+func (o *Orphan) check(msg *Message) error {
+	if !o.ptr.IsValid() {
+		return nil
+	}
+	if o.ptr.Message() != msg {
+		return errors.New("adopt: orphan belongs to a different message")
+	}
+	if s := o.ptr.Struct(); s.IsValid() && s.flags&isListMember != 0 {
+		return errors.New("adopt: orphan is a struct inside a list")
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package capnp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphan(t *testing.T) {
+	t.Parallel()
+
+	newRoot := func(t *testing.T) (*Message, Struct) {
+		msg, seg := NewSingleSegmentMessage(nil)
+		root, err := NewRootStruct(seg, ObjectSize{PointerCount: 2})
+		require.NoError(t, err)
+		return msg, root
+	}
+	size := func(t *testing.T, msg *Message) uint64 {
+		n, err := msg.TotalSize()
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("Move", func(t *testing.T) {
+		msg, root := newRoot(t)
+		child, err := NewStruct(root.Segment(), ObjectSize{DataSize: 8})
+		require.NoError(t, err)
+		child.SetUint64(0, 42)
+		require.NoError(t, root.SetPtr(0, child.ToPtr()))
+		before := size(t, msg)
+
+		o, err := root.Disown(0)
+		require.NoError(t, err)
+		assert.False(t, root.HasPtr(0), "disowned pointer not cleared")
+		assert.Equal(t, uint64(42), o.Ptr().Struct().Uint64(0))
+
+		require.NoError(t, root.Adopt(1, &o))
+		assert.False(t, o.IsValid(), "adopted orphan not cleared")
+		p, err := root.Ptr(1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), p.Struct().Uint64(0))
+		assert.Equal(t, before, size(t, msg), "adopting copied the struct")
+	})
+
+	t.Run("BuildChildFirst", func(t *testing.T) {
+		msg, seg := NewSingleSegmentMessage(nil)
+		child, err := NewStruct(seg, ObjectSize{DataSize: 8})
+		require.NoError(t, err)
+		child.SetUint64(0, 7)
+		o := NewOrphan(child.ToPtr())
+		assert.Equal(t, msg, o.Message())
+
+		root, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+		require.NoError(t, err)
+		require.NoError(t, root.Adopt(0, &o))
+		p, err := root.Ptr(0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), p.Struct().Uint64(0))
+	})
+
+	t.Run("PointerList", func(t *testing.T) {
+		_, root := newRoot(t)
+		l, err := NewTextList(root.Segment(), 2)
+		require.NoError(t, err)
+		require.NoError(t, l.Set(0, "hello"))
+		pl := PointerList(l)
+
+		o, err := pl.Disown(0)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", o.Ptr().Text())
+		s, err := l.At(0)
+		require.NoError(t, err)
+		assert.Empty(t, s)
+
+		require.NoError(t, pl.Adopt(1, &o))
+		s, err = l.At(1)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", s)
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		_, root := newRoot(t)
+		o, err := root.Disown(0)
+		require.NoError(t, err)
+		assert.False(t, o.IsValid())
+		assert.Nil(t, o.Message())
+		require.NoError(t, root.Adopt(1, &o))
+		assert.False(t, root.HasPtr(1))
+	})
+
+	t.Run("OtherMessage", func(t *testing.T) {
+		_, root := newRoot(t)
+		_, other := newRoot(t)
+		o := NewOrphan(other.ToPtr())
+		assert.Error(t, root.Adopt(0, &o))
+		assert.True(t, o.IsValid(), "orphan cleared after failed adopt")
+	})
+
+	t.Run("ListMember", func(t *testing.T) {
+		_, root := newRoot(t)
+		l, err := NewCompositeList(root.Segment(), ObjectSize{DataSize: 8}, 2)
+		require.NoError(t, err)
+		o := NewOrphan(l.Struct(1).ToPtr())
+		assert.Error(t, root.Adopt(0, &o))
+	})
+}
@@ -0,0 +1,18 @@
+package capnp
+
+// Handoff gives up the calling goroutine's ownership of m, so that the
+// next goroutine to modify or encode m becomes its owner.  Call it
+// before passing a message that is still being built to another
+// goroutine, such as one that encodes it.
+//
+// Ownership is only tracked when the program is built with the
+// capnp_ownership build tag.  Then a message is owned by the first
+// goroutine to modify or encode it after it was created, reset or
+// handed off, and modifying or encoding it on any other goroutine
+// panics.  This catches a message being changed after it was handed
+// to an encoder running elsewhere.  Reading a message is not checked,
+// since it is safe to read from multiple goroutines.  Without the tag,
+// Handoff does nothing.
+func (m *Message) Handoff() {
+	m.owner.release()
+}
@@ -0,0 +1,54 @@
+//go:build capnp_ownership
+
+package capnp
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+// messageOwner records the ID of the goroutine that owns a message, or
+// zero if the message is not owned.
+type messageOwner struct {
+	id atomic.Int64
+}
+
+func (o *messageOwner) release() {
+	o.id.Store(0)
+}
+
+// checkOwner panics if m is owned by a goroutine other than the calling
+// one.  If m is not owned, the calling goroutine becomes its owner.
+func (m *Message) checkOwner(op string) {
+	if m == nil {
+		return
+	}
+	g := goroutineID()
+	if m.owner.id.CompareAndSwap(0, g) {
+		return
+	}
+	if owner := m.owner.id.Load(); owner != g {
+		panic("capnp: " + op + " on goroutine " + strconv.FormatInt(g, 10) +
+			", but message is owned by goroutine " + strconv.FormatInt(owner, 10) +
+			"; call Message.Handoff before passing a message to another goroutine")
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, as printed in
+// stack traces.  It is slow, but ownership tracking is only for
+// debugging.
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		panic("capnp: cannot parse goroutine ID: " + err.Error())
+	}
+	return id
+}
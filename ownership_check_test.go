@@ -0,0 +1,52 @@
+//go:build capnp_ownership
+
+package capnp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onOtherGoroutine calls f on a new goroutine and returns the value it
+// panicked with, if any.
+func onOtherGoroutine(f func()) (panicked any) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { panicked = recover() }()
+		f()
+	}()
+	<-done
+	return panicked
+}
+
+func TestOwnership(t *testing.T) {
+	t.Parallel()
+
+	msg, seg := NewSingleSegmentMessage(nil)
+	s, err := NewRootStruct(seg, ObjectSize{DataSize: 8})
+	require.NoError(t, err)
+	s.SetUint64(0, 42)
+
+	p := onOtherGoroutine(func() {
+		NewEncoder(&bytes.Buffer{}).Encode(msg)
+	})
+	assert.Contains(t, p, "capnp: encode on goroutine", "encoding without a handoff should panic")
+
+	msg.Handoff()
+	assert.Nil(t, onOtherGoroutine(func() {
+		NewEncoder(&bytes.Buffer{}).Encode(msg)
+	}), "encoding after a handoff should not panic")
+
+	assert.Panics(t, func() { s.SetUint64(0, 7) },
+		"previous owner should not modify the message after handing it off")
+	_ = s.Uint64(0) // reading is not checked
+
+	seg, err = msg.Reset(SingleSegment(nil))
+	require.NoError(t, err)
+	_, err = NewRootStruct(seg, ObjectSize{DataSize: 8})
+	assert.NoError(t, err, "a reset message is not owned")
+}
@@ -0,0 +1,11 @@
+//go:build !capnp_ownership
+
+package capnp
+
+// messageOwner is empty unless the capnp_ownership build tag is set;
+// see Message.Handoff.
+type messageOwner struct{}
+
+func (o *messageOwner) release() {}
+
+func (m *Message) checkOwner(op string) {}
@@ -4,8 +4,10 @@ package packed
 
 import (
 	"bufio"
+	"encoding/binary"
 	"errors"
 	"io"
+	"math/bits"
 )
 
 const wordSize = 8
@@ -18,64 +20,187 @@ const (
 
 // Pack appends the packed version of src to dst and returns the
 // resulting slice.  len(src) must be a multiple of 8 or Pack panics.
+//
+// Pack reserves room in dst for the worst case, which is 10 bytes for
+// every 8 bytes of src, so callers that pack repeatedly should reuse dst.
 func Pack(dst, src []byte) []byte {
 	if len(src)%wordSize != 0 {
 		panic("packed.Pack len(src) must be a multiple of 8")
 	}
-	var buf [wordSize]byte
+	n := len(dst)
+	dst = grow(dst, len(src)/wordSize*maxPackedWord)
+	buf := dst[:cap(dst)]
 	for len(src) > 0 {
-		var hdr byte
-		n := 0
-		for i := uint(0); i < wordSize; i++ {
-			if src[i] != 0 {
-				hdr |= 1 << i
-				buf[n] = src[i]
-				n++
-			}
-		}
-		dst = append(dst, hdr)
-		dst = append(dst, buf[:n]...)
+		tag := tagOf(binary.LittleEndian.Uint64(src))
+		buf[n] = tag
+		n++
+
+		// Store all eight bytes, but only advance past the non-zero
+		// ones, so that the loop has no data-dependent branches.
+		// The room reserved by grow covers the bytes stored past n.
+		out := buf[n : n+wordSize]
+		w := src[:wordSize]
+		i := 0
+		out[i] = w[0]
+		i += int(tag & 1)
+		out[i] = w[1]
+		i += int(tag >> 1 & 1)
+		out[i] = w[2]
+		i += int(tag >> 2 & 1)
+		out[i] = w[3]
+		i += int(tag >> 3 & 1)
+		out[i] = w[4]
+		i += int(tag >> 4 & 1)
+		out[i] = w[5]
+		i += int(tag >> 5 & 1)
+		out[i] = w[6]
+		i += int(tag >> 6 & 1)
+		out[i&7] = w[7]
+		i += int(tag >> 7 & 1)
+		n += i
 		src = src[wordSize:]
 
-		switch hdr {
+		switch tag {
 		case zeroTag:
-			z := min(numZeroWords(src), 0xff)
-			dst = append(dst, byte(z))
+			z := numZeroWords(src)
+			buf[n] = byte(z)
+			n++
 			src = src[z*wordSize:]
 		case unpackedTag:
-			i := 0
-			end := min(len(src), 0xff*wordSize)
-			for i < end {
-				zeros := 0
-				for _, b := range src[i : i+wordSize] {
-					if b == 0 {
-						zeros++
-					}
-				}
+			l := numLiteralWords(src) * wordSize
+			buf[n] = byte(l / wordSize)
+			n++
+			n += copy(buf[n:], src[:l])
+			src = src[l:]
+		}
+	}
+	return buf[:n]
+}
 
-				if zeros > 1 {
-					break
-				}
-				i += wordSize
-			}
+// maxPackedWord is the largest number of bytes that a word of input
+// contributes to Pack's output: a tag, eight literal bytes and a run
+// length.
+const maxPackedWord = 1 + wordSize + 1
 
-			rawWords := byte(i / wordSize)
-			dst = append(dst, rawWords)
-			dst = append(dst, src[:i]...)
-			src = src[i:]
-		}
+// grow returns b with room for at least n more bytes.
+func grow(b []byte, n int) []byte {
+	if cap(b)-len(b) >= n {
+		return b
 	}
-	return dst
+	nb := make([]byte, len(b), len(b)+n)
+	copy(nb, b)
+	return nb
+}
+
+// tagOf returns the tag of the little-endian word w, which has bit i
+// set if byte i of w is non-zero.  It is computed without branches.
+func tagOf(w uint64) byte {
+	const (
+		low7 = 0x7f7f7f7f7f7f7f7f
+		lsbs = 0x0101010101010101
+	)
+	// Adding 0x7f to the low seven bits of a byte carries into its
+	// high bit exactly when they are non-zero, and no byte overflows
+	// into the next.
+	hi := ((w & low7) + low7 | w) >> 7 & lsbs
+	// Gather the eight bits into the top byte.
+	return byte(hi * 0x0102040810204080 >> 56)
 }
 
-// numZeroWords returns the number of leading zero words in b.
+// numZeroWords returns the number of leading zero words in b, up to
+// the 255 that a run of zero words can hold.
 func numZeroWords(b []byte) int {
-	for i, bb := range b {
-		if bb != 0 {
-			return i / wordSize
+	n := 0
+	for ; n < 0xff && len(b) >= wordSize; n++ {
+		if binary.LittleEndian.Uint64(b) != 0 {
+			break
+		}
+		b = b[wordSize:]
+	}
+	return n
+}
+
+// numLiteralWords returns the number of leading words in b that have
+// at most one zero byte, up to the 255 that a run of literal words can
+// hold.  Those words are smaller copied verbatim than packed.
+func numLiteralWords(b []byte) int {
+	n := 0
+	for ; n < 0xff && len(b) >= wordSize; n++ {
+		if bits.OnesCount8(tagOf(binary.LittleEndian.Uint64(b))) < wordSize-1 {
+			break
 		}
+		b = b[wordSize:]
 	}
-	return len(b) / wordSize
+	return n
+}
+
+// EstimateSize returns the size of the packed version of src, as
+// produced by Pack, without packing it.  It lets callers decide whether
+// packing is worthwhile, and size buffers for Pack.  len(src) must be a
+// multiple of 8 or EstimateSize panics.
+func EstimateSize(src []byte) int {
+	if len(src)%wordSize != 0 {
+		panic("packed.EstimateSize len(src) must be a multiple of 8")
+	}
+	n := 0
+	for len(src) > 0 {
+		tag := tagOf(binary.LittleEndian.Uint64(src))
+		n += 1 + bits.OnesCount8(tag)
+		src = src[wordSize:]
+
+		switch tag {
+		case zeroTag:
+			z := numZeroWords(src)
+			n++
+			src = src[z*wordSize:]
+		case unpackedTag:
+			l := numLiteralWords(src) * wordSize
+			n += 1 + l
+			src = src[l:]
+		}
+	}
+	return n
+}
+
+// unpackWord stores in p the word with the given tag whose non-zero
+// bytes start b, and returns the number of bytes of b that it used.
+// len(p) and len(b) must be at least 8.  Like Pack, it reads a byte for
+// every position and only advances past the ones that the tag marks as
+// present.
+func unpackWord(p []byte, tag byte, b []byte) int {
+	p = p[:wordSize]
+	b = b[:wordSize]
+	n := 0
+	nz := tag & 1
+	p[0] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 1 & 1
+	p[1] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 2 & 1
+	p[2] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 3 & 1
+	p[3] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 4 & 1
+	p[4] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 5 & 1
+	p[5] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 6 & 1
+	p[6] = b[n] & -nz
+	n += int(nz)
+	nz = tag >> 7 & 1
+	p[7] = b[n&7] & -nz
+	n += int(nz)
+	return n
+}
+
+// growWords returns p with room for at least one more word.
+func growWords(p []byte) []byte {
+	return allocWords(p, 1)[:len(p)]
 }
 
 // Unpack appends the unpacked version of src to dst and returns the
@@ -85,37 +210,18 @@ func Unpack(dst, src []byte) ([]byte, error) {
 		tag := src[0]
 		src = src[1:]
 
-		pstart := len(dst)
-		dst = allocWords(dst, 1)
-		p := dst[pstart : pstart+wordSize]
 		if len(src) >= wordSize {
-			i := 0
-			nz := tag & 1
-			p[0] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 1 & 1
-			p[1] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 2 & 1
-			p[2] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 3 & 1
-			p[3] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 4 & 1
-			p[4] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 5 & 1
-			p[5] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 6 & 1
-			p[6] = src[i] & -nz
-			i += int(nz)
-			nz = tag >> 7 & 1
-			p[7] = src[i] & -nz
-			i += int(nz)
-			src = src[i:]
+			if cap(dst)-len(dst) < wordSize {
+				dst = growWords(dst)
+			}
+			start := len(dst)
+			dst = dst[:start+wordSize]
+			n := unpackWord(dst[start:], tag, src)
+			src = src[n:]
 		} else {
+			pstart := len(dst)
+			dst = allocWords(dst, 1)
+			p := dst[pstart : pstart+wordSize]
 			for i := uint(0); i < wordSize; i++ {
 				if tag&(1<<i) == 0 {
 					continue
@@ -251,32 +357,7 @@ func (r *Reader) ReadWord(p []byte) error {
 	} else {
 		b, _ := r.rd.Peek(wordSize + 1)
 		tag = b[0]
-		i := 1
-		nz := tag & 1
-		p[0] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 1 & 1
-		p[1] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 2 & 1
-		p[2] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 3 & 1
-		p[3] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 4 & 1
-		p[4] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 5 & 1
-		p[5] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 6 & 1
-		p[6] = b[i] & -nz
-		i += int(nz)
-		nz = tag >> 7 & 1
-		p[7] = b[i] & -nz
-		i += int(nz)
-		r.rd.Discard(i)
+		r.rd.Discard(1 + unpackWord(p, tag, b[1:]))
 	}
 	switch tag {
 	case zeroTag:
@@ -312,6 +393,12 @@ func (r *Reader) Read(p []byte) (n int, err error) {
 		r.wordIdx += n
 	}
 	for n < len(p) {
+		if r.err == nil {
+			n += r.readBuffered(p[n:])
+			if n == len(p) {
+				break
+			}
+		}
 		if r.rd.Buffered() < wordSize+1 && n > 0 {
 			return n, nil
 		}
@@ -333,12 +420,88 @@ func (r *Reader) Read(p []byte) (n int, err error) {
 	return n, nil
 }
 
+// readBuffered decompresses as many whole words into p as it can
+// without reading more input than is already buffered, and returns the
+// number of bytes it wrote.  It decodes straight from the buffer, which
+// is much faster than calling ReadWord for each word.
+func (r *Reader) readBuffered(p []byte) int {
+	buf, _ := r.rd.Peek(r.rd.Buffered())
+	n, used := 0, 0
+	for len(p)-n >= wordSize {
+		switch {
+		case r.zeroes > 0:
+			k := min(r.zeroes, (len(p)-n)/wordSize) * wordSize
+			zero := p[n : n+k]
+			for i := range zero {
+				zero[i] = 0
+			}
+			n += k
+			r.zeroes -= k / wordSize
+		case r.literal > 0:
+			k := min(r.literal, min(len(p)-n, len(buf)-used)/wordSize) * wordSize
+			if k == 0 {
+				r.rd.Discard(used)
+				return n
+			}
+			copy(p[n:n+k], buf[used:])
+			n += k
+			used += k
+			r.literal -= k / wordSize
+		default:
+			// A tag, its word and a run length.
+			if len(buf)-used < maxPackedWord {
+				r.rd.Discard(used)
+				return n
+			}
+			tag := buf[used]
+			used += 1 + unpackWord(p[n:], tag, buf[used+1:])
+			n += wordSize
+			switch tag {
+			case zeroTag:
+				r.zeroes = int(buf[used])
+				used++
+			case unpackedTag:
+				r.literal = int(buf[used])
+				used++
+			}
+		}
+	}
+	r.rd.Discard(used)
+	return n
+}
+
+// writeChunkSize is the number of bytes a Writer packs at a time.
+const writeChunkSize = 32 << 10
+
+// A Writer packs the data written to it and writes the result to the
+// underlying Writer.  Large writes are packed and written in chunks, so
+// the Writer does not need a buffer the size of the data.  The zero
+// value is not usable: Writer must be set, or NewWriter used.
 type Writer struct {
 	io.Writer
 	buf []byte
 }
 
+// NewWriter returns a Writer that writes packed data to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{Writer: w}
+}
+
+// Write packs b and writes it to the underlying Writer.  len(b) must be
+// a multiple of 8.  On success, Write returns len(b).
 func (w *Writer) Write(b []byte) (int, error) {
-	w.buf = Pack(w.buf[:0], b)
-	return w.Writer.Write(w.buf)
+	if len(b)%wordSize != 0 {
+		return 0, errors.New("packed: write of partial word")
+	}
+	n := 0
+	for len(b) > 0 {
+		chunk := b[:min(len(b), writeChunkSize)]
+		w.buf = Pack(w.buf[:0], chunk)
+		if _, err := w.Writer.Write(w.buf); err != nil {
+			return n, err
+		}
+		n += len(chunk)
+		b = b[len(chunk):]
+	}
+	return n, nil
 }
@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"strings"
 	"testing"
 	"testing/iotest"
@@ -276,6 +278,154 @@ func TestPack_wordsize(t *testing.T) {
 	}, "should panic if len(src) is not a multiple of 8")
 }
 
+func TestEstimateSize(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range compressionTests {
+		t.Run(test.name, func(t *testing.T) {
+			if testing.Short() && test.long {
+				t.Skip("skipping long test due to -short")
+			}
+			assert.Equal(t, len(test.compressed), EstimateSize(test.original))
+		})
+	}
+	assert.Panics(t, func() {
+		EstimateSize(make([]byte, 1))
+	}, "should panic if len(src) is not a multiple of 8")
+}
+
+func TestTagOf(t *testing.T) {
+	t.Parallel()
+
+	for tag := 0; tag < 256; tag++ {
+		// Try both a byte with only its high bit set and one with only
+		// low bits set, since they take different paths.
+		for _, b := range []byte{0x80, 0x01, 0xff} {
+			var w [wordSize]byte
+			for i := range w {
+				if tag&(1<<i) != 0 {
+					w[i] = b
+				}
+			}
+			got := tagOf(binary.LittleEndian.Uint64(w[:]))
+			require.Equal(t, byte(tag), got, "word % x", w)
+		}
+	}
+}
+
+// packReference is a byte-at-a-time implementation of Pack, written
+// for clarity rather than speed.
+func packReference(dst, src []byte) []byte {
+	for len(src) > 0 {
+		var hdr byte
+		var lit []byte
+		for i, b := range src[:wordSize] {
+			if b != 0 {
+				hdr |= 1 << i
+				lit = append(lit, b)
+			}
+		}
+		dst = append(dst, hdr)
+		dst = append(dst, lit...)
+		src = src[wordSize:]
+
+		switch hdr {
+		case zeroTag:
+			z := 0
+			for z < 0xff && z*wordSize < len(src) && bytes.Equal(src[z*wordSize:(z+1)*wordSize], make([]byte, wordSize)) {
+				z++
+			}
+			dst = append(dst, byte(z))
+			src = src[z*wordSize:]
+		case unpackedTag:
+			z := 0
+			for z < 0xff && z*wordSize < len(src) && bytes.Count(src[z*wordSize:(z+1)*wordSize], []byte{0}) <= 1 {
+				z++
+			}
+			dst = append(dst, byte(z))
+			dst = append(dst, src[:z*wordSize]...)
+			src = src[z*wordSize:]
+		}
+	}
+	return dst
+}
+
+func TestPack_random(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		// Skew towards zero bytes and long runs, so that both kinds of
+		// run reach their 255 word limit.
+		src := make([]byte, wordSize*rng.Intn(600))
+		for i := 0; i < len(src); {
+			n := wordSize * (1 + rng.Intn(300))
+			if n > len(src)-i {
+				n = len(src) - i
+			}
+			switch rng.Intn(3) {
+			case 0:
+				// zero run
+			case 1:
+				rng.Read(src[i : i+n])
+			default:
+				for j := i; j < i+n; j++ {
+					if rng.Intn(4) == 0 {
+						src[j] = byte(rng.Intn(256))
+					}
+				}
+			}
+			i += n
+		}
+
+		prefix := []byte{0xaa}
+		want := packReference(append([]byte(nil), prefix...), src)
+		got := Pack(append([]byte(nil), prefix...), src)
+		require.Equal(t, want, got, "iteration %d", iter)
+		require.Equal(t, len(want)-len(prefix), EstimateSize(src), "iteration %d", iter)
+
+		unpacked, err := Unpack(nil, got[len(prefix):])
+		require.NoError(t, err)
+		require.Equal(t, src, unpacked, "iteration %d", iter)
+
+		r := NewReader(bufio.NewReaderSize(bytes.NewReader(got[len(prefix):]), 16+rng.Intn(256)))
+		read, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, src, read, "iteration %d", iter)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	// Larger than a chunk, with long runs of zero and literal words.
+	src := bytes.Repeat([]byte{
+		1, 2, 3, 4, 5, 6, 7, 8,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 1, 0, 2, 0, 3, 0, 0,
+	}, writeChunkSize/8)
+	src = append(src, make([]byte, 4096*wordSize)...)
+	src = append(src, bytes.Repeat([]byte{9}, 4096*wordSize)...)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	n, err := w.Write(src[:64])
+	require.NoError(t, err)
+	assert.Equal(t, 64, n)
+	n, err = w.Write(src[64:])
+	require.NoError(t, err)
+	assert.Equal(t, len(src)-64, n)
+	assert.Less(t, cap(w.buf), len(src)/2, "writer should pack in chunks")
+
+	unpacked, err := Unpack(nil, buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, src, unpacked)
+
+	_, err = w.Write(make([]byte, 3))
+	assert.Error(t, err, "should fail if len(b) is not a multiple of 8")
+}
+
 func TestUnpack(t *testing.T) {
 	t.Parallel()
 	t.Helper()
@@ -411,6 +561,70 @@ func BenchmarkPack(b *testing.B) {
 	result = dst
 }
 
+// benchMessage returns an unpacked message of about 1 MiB, made of
+// structs with a mix of small integers, text and zero padding, like
+// typical Cap'n Proto data.
+func benchMessage() []byte {
+	rng := rand.New(rand.NewSource(1))
+	var src []byte
+	for len(src) < 1<<20 {
+		var w [wordSize]byte
+		switch rng.Intn(4) {
+		case 0:
+			binary.LittleEndian.PutUint64(w[:], uint64(rng.Intn(1000)))
+		case 1:
+			rng.Read(w[:])
+		case 2:
+			copy(w[:], "Cap'n Proto"[rng.Intn(4):])
+		}
+		src = append(src, w[:]...)
+	}
+	return src
+}
+
+func BenchmarkPack_Large(b *testing.B) {
+	src := benchMessage()
+	dst := Pack(nil, src)
+	b.SetBytes(int64(len(src)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = Pack(dst[:0], src)
+	}
+	result = dst
+}
+
+func BenchmarkEstimateSize(b *testing.B) {
+	src := benchMessage()
+	b.SetBytes(int64(len(src)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EstimateSize(src)
+	}
+}
+
+func BenchmarkWriter(b *testing.B) {
+	src := benchMessage()
+	w := NewWriter(io.Discard)
+	b.SetBytes(int64(len(src)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(src); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnpack_Message(b *testing.B) {
+	benchUnpack(b, Pack(nil, benchMessage()))
+}
+
+func BenchmarkReader_Message(b *testing.B) {
+	benchReader(b, Pack(nil, benchMessage()))
+}
+
 func benchUnpack(b *testing.B, src []byte) {
 	var unpackedSize int
 	{
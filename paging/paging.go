@@ -0,0 +1,116 @@
+// Package paging iterates over the results of paged RPC methods.
+//
+// Interfaces often return long result sets a page at a time, with a
+// method like
+//
+//	getPage @0 (cursor :Data) -> (items :List(Item), next :Data);
+//
+// that the client calls repeatedly, passing the cursor returned with
+// each page to fetch the next one.  Items turns such a method into a
+// sequence of items.  It requests the next page as soon as the current
+// one arrives, so the round trip for it overlaps with the caller's
+// processing of the current page.
+//
+// The sequence returned by Items has the signature of an
+// iter.Seq2[T, error], so in modules that use Go 1.23 or later it can be
+// ranged over directly:
+//
+//	for item, err := range paging.Items(ctx, nil, fetch) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+package paging // import "capnproto.org/go/capnp/v3/paging"
+
+import (
+	"context"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// A Page is one page of results of a paged method.
+type Page[T, C any] struct {
+	// Items are the results in the page.  They may point into the
+	// message holding the results, so they are only valid until the
+	// page is released.
+	Items []T
+
+	// Next is the cursor to pass to fetch the following page.
+	Next C
+
+	// Last is true if there are no more pages after this one.
+	Last bool
+}
+
+// A Fetcher starts fetching the page of results at cursor.  It returns
+// a function that waits for the page to arrive, and a function that
+// releases the page's results or cancels the request.  Neither function
+// is called more than once, and release is called even if wait is not.
+//
+// A Fetcher usually calls a method on a generated client and converts
+// its results:
+//
+//	fetch := func(ctx context.Context, cursor []byte) (func() (paging.Page[Item, []byte], error), capnp.ReleaseFunc) {
+//		fut, release := store.GetPage(ctx, func(p Store_getPage_Params) error {
+//			return p.SetCursor(cursor)
+//		})
+//		return func() (paging.Page[Item, []byte], error) {
+//			res, err := fut.Struct()
+//			if err != nil {
+//				return paging.Page[Item, []byte]{}, err
+//			}
+//			...
+//		}, release
+//	}
+type Fetcher[T, C any] func(ctx context.Context, cursor C) (wait func() (Page[T, C], error), release capnp.ReleaseFunc)
+
+// Items returns a sequence of the items in the pages fetched by fetch,
+// starting at the page at cursor start.  Each time the sequence is
+// iterated, it fetches the pages again.
+//
+// While the items of a page are yielded, the next page is already being
+// fetched.  Each page is released once all of its items have been
+// yielded, so items must not be used after the next one is received.
+// If fetching a page fails, the error is yielded with the zero T, and
+// iteration stops.  Stopping the iteration early cancels the fetch in
+// progress.
+func Items[T, C any](ctx context.Context, start C, fetch Fetcher[T, C]) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		wait, release := fetch(ctx, start)
+		defer func() { release() }()
+		for {
+			page, err := wait()
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			// Pipeline the request for the next page with the
+			// processing of this one.
+			cur := release
+			if page.Last {
+				release = func() {}
+			} else {
+				wait, release = fetch(ctx, page.Next)
+			}
+			ok := yieldAll(page.Items, yield)
+			cur()
+			if !ok || page.Last {
+				return
+			}
+		}
+	}
+}
+
+// yieldAll yields each item with a nil error, and reports whether the
+// caller wants more.
+func yieldAll[T any](items []T, yield func(T, error) bool) bool {
+	for _, item := range items {
+		if !yield(item, nil) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package paging_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/paging"
+)
+
+// pager serves pages of pageSize numbers, counting up from zero to
+// total, and records what its Fetcher was asked to do.
+type pager struct {
+	pageSize, total int
+	failAt          int // cursor at which fetching fails, or -1
+	events          []string
+}
+
+func (p *pager) fetch(ctx context.Context, cursor int) (func() (paging.Page[int, int], error), capnp.ReleaseFunc) {
+	p.events = append(p.events, fmt.Sprintf("fetch %d", cursor))
+	wait := func() (paging.Page[int, int], error) {
+		if cursor == p.failAt {
+			return paging.Page[int, int]{}, errors.New("page unavailable")
+		}
+		var page paging.Page[int, int]
+		for i := cursor; i < cursor+p.pageSize && i < p.total; i++ {
+			page.Items = append(page.Items, i)
+		}
+		page.Next = cursor + p.pageSize
+		page.Last = page.Next >= p.total
+		return page, nil
+	}
+	return wait, func() {
+		p.events = append(p.events, fmt.Sprintf("release %d", cursor))
+	}
+}
+
+func TestItems(t *testing.T) {
+	t.Parallel()
+
+	p := &pager{pageSize: 2, total: 5, failAt: -1}
+	var got []int
+	paging.Items(context.Background(), 0, p.fetch)(func(n int, err error) bool {
+		assert.NoError(t, err)
+		p.events = append(p.events, fmt.Sprintf("item %d", n))
+		got = append(got, n)
+		return true
+	})
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, got)
+	assert.Equal(t, []string{
+		"fetch 0",
+		"fetch 2", // requested before the items of page 0 are yielded
+		"item 0", "item 1",
+		"release 0",
+		"fetch 4",
+		"item 2", "item 3",
+		"release 2",
+		"item 4",
+		"release 4",
+	}, p.events)
+}
+
+func TestItemsBreak(t *testing.T) {
+	t.Parallel()
+
+	p := &pager{pageSize: 2, total: 10, failAt: -1}
+	paging.Items(context.Background(), 0, p.fetch)(func(n int, err error) bool {
+		return n < 2
+	})
+	assert.Equal(t, []string{
+		"fetch 0", "fetch 2", "release 0",
+		"fetch 4", "release 2",
+		"release 4", // the prefetched page is released too
+	}, p.events)
+}
+
+func TestItemsError(t *testing.T) {
+	t.Parallel()
+
+	p := &pager{pageSize: 2, total: 10, failAt: 2}
+	var (
+		got  []int
+		errs []error
+	)
+	paging.Items(context.Background(), 0, p.fetch)(func(n int, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			got = append(got, n)
+		}
+		return true
+	})
+	assert.Equal(t, []int{0, 1}, got)
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], "page unavailable")
+	}
+	assert.Equal(t, "release 2", p.events[len(p.events)-1])
+}
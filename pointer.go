@@ -1,10 +1,7 @@
 package capnp
 
 import (
-	"bytes"
-
 	"capnproto.org/go/capnp/v3/exc"
-	"capnproto.org/go/capnp/v3/internal/str"
 )
 
 // A Ptr is a reference to a Cap'n Proto struct, list, or interface.
@@ -170,6 +167,19 @@ func (p Ptr) DataDefault(def []byte) []byte {
 	return b
 }
 
+// readSize returns the size of the object p points to for the purposes
+// of read limit accounting.
+func (p Ptr) readSize() Size {
+	switch p.flags.ptrType() {
+	case structPtrType:
+		return p.Struct().readSize()
+	case listPtrType:
+		return p.List().readSize()
+	default:
+		return 0
+	}
+}
+
 // IsValid reports whether p is valid.
 func (p Ptr) IsValid() bool {
 	return p.seg != nil
@@ -284,105 +294,12 @@ func isZeroFilled(b []byte) bool {
 //     populated.
 //   - Two null pointers are equal.
 //   - All other combinations of things are not equal.
+//
+// Diff reports where two pointers that are not equal differ.
 func Equal(p1, p2 Ptr) (bool, error) {
-	if !p1.IsValid() && !p2.IsValid() {
-		return true, nil
-	}
-	if !p1.IsValid() || !p2.IsValid() {
-		return false, nil
-	}
-	pt := p1.flags.ptrType()
-	if pt != p2.flags.ptrType() {
-		return false, nil
-	}
-	switch pt {
-	case structPtrType:
-		s1, s2 := p1.Struct(), p2.Struct()
-		data1 := s1.seg.slice(s1.off, s1.size.DataSize)
-		data2 := s2.seg.slice(s2.off, s2.size.DataSize)
-		switch {
-		case len(data1) < len(data2):
-			if !bytes.Equal(data1, data2[:len(data1)]) {
-				return false, nil
-			}
-			if !isZeroFilled(data2[len(data1):]) {
-				return false, nil
-			}
-		case len(data1) > len(data2):
-			if !bytes.Equal(data1[:len(data2)], data2) {
-				return false, nil
-			}
-			if !isZeroFilled(data1[len(data2):]) {
-				return false, nil
-			}
-		default:
-			if !bytes.Equal(data1, data2) {
-				return false, nil
-			}
-		}
-		n := int(s1.size.PointerCount)
-		if n2 := int(s2.size.PointerCount); n2 < n {
-			n = n2
-		}
-		for i := 0; i < n; i++ {
-			sp1, err := s1.Ptr(uint16(i))
-			if err != nil {
-				return false, exc.WrapError("equal", err)
-			}
-			sp2, err := s2.Ptr(uint16(i))
-			if err != nil {
-				return false, exc.WrapError("equal", err)
-			}
-			if ok, err := Equal(sp1, sp2); !ok || err != nil {
-				return false, err
-			}
-		}
-		for i := n; i < int(s1.size.PointerCount); i++ {
-			if s1.HasPtr(uint16(i)) {
-				return false, nil
-			}
-		}
-		for i := n; i < int(s2.size.PointerCount); i++ {
-			if s2.HasPtr(uint16(i)) {
-				return false, nil
-			}
-		}
-		return true, nil
-	case listPtrType:
-		l1, l2 := p1.List(), p2.List()
-		if l1.Len() != l2.Len() {
-			return false, nil
-		}
-		if l1.flags&isCompositeList == 0 && l2.flags&isCompositeList == 0 && l1.size != l2.size {
-			return false, nil
-		}
-		if l1.size.PointerCount == 0 && l2.size.PointerCount == 0 && l1.size.DataSize == l2.size.DataSize {
-			// Optimization: pure data lists can be compared bytewise.
-			sz, _ := l1.size.totalSize().times(l1.length) // both list bounds have been validated
-			return bytes.Equal(l1.seg.slice(l1.off, sz), l2.seg.slice(l2.off, sz)), nil
-		}
-		for i := 0; i < l1.Len(); i++ {
-			e1, e2 := l1.Struct(i), l2.Struct(i)
-			if ok, err := Equal(e1.ToPtr(), e2.ToPtr()); err != nil {
-				return false, exc.WrapError("equal: list element "+str.Itod(i), err)
-			} else if !ok {
-				return false, nil
-			}
-		}
-		return true, nil
-	case interfacePtrType:
-		i1, i2 := p1.Interface(), p2.Interface()
-		if i1.Message() == i2.Message() {
-			if i1.Capability() == i2.Capability() {
-				return true, nil
-			}
-
-			if !i1.Message().CapTable().Contains(i1) || !i1.Message().CapTable().Contains(i2) {
-				return false, nil
-			}
-		}
-		return i1.Client().IsSame(i2.Client()), nil
-	default:
-		panic("unreachable")
+	d, err := Diff(p1, p2)
+	if err != nil {
+		return false, err
 	}
+	return d == nil, nil
 }
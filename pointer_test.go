@@ -64,6 +64,11 @@ func TestEqual(t *testing.T) {
 	list456Struct.Struct(0).SetUint32(0, 4)
 	list456Struct.Struct(1).SetUint32(0, 5)
 	list456Struct.Struct(2).SetUint32(0, 6)
+	bitsTT, _ := NewBitList(seg, 2)
+	bitsTT.Set(0, true)
+	bitsTT.Set(1, true)
+	bitsTF, _ := NewBitList(seg, 2)
+	bitsTF.Set(0, true)
 	plistA1, _ := NewPointerList(seg, 1)
 	plistA1.Set(0, structA1.ToPtr())
 	plistA2, _ := NewPointerList(seg, 1)
@@ -118,6 +123,8 @@ func TestEqual(t *testing.T) {
 		{"List123Struct_List123Int", list123Struct.ToPtr(), list123Int.ToPtr(), true},
 		{"List123Int_List12Int", list123Int.ToPtr(), list12Int.ToPtr(), false},
 		{"List123Struct_List12Struct", list123Struct.ToPtr(), list12Struct.ToPtr(), false},
+		{"BitListTT_BitListTT", bitsTT.ToPtr(), bitsTT.ToPtr(), true},
+		{"BitListTT_BitListTF", bitsTT.ToPtr(), bitsTF.ToPtr(), false},
 		{"PointerListA1_PointerListA2", plistA1.ToPtr(), plistA2.ToPtr(), true},
 		{"PointerListA2_PointerListA1", plistA2.ToPtr(), plistA1.ToPtr(), true},
 		{"PointerListA_PointerListB", plistA1.ToPtr(), plistB.ToPtr(), false},
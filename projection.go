@@ -0,0 +1,161 @@
+package capnp
+
+import (
+	"errors"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A Projection reads a fixed set of objects out of messages without
+// traversing the rest of them.  It is meant for code that inspects a
+// few fields of large messages, such as a broker routing on a header
+// field, where reading through the generated accessors would charge
+// whole lists to the message's read limit just to reach one element.
+//
+// Each path of a projection selects an object by following pointers
+// from the message's root.  A step in a path is interpreted according
+// to the object it is applied to: in a struct, it is the index of a
+// pointer field, which is the field's slot offset in the schema; in a
+// list of structs or of pointers, it is the index of an element.
+//
+// Only the pointers along the paths are validated.  The message's read
+// limit is charged for the structs along the paths and for the
+// selected objects, but lists along the paths are only charged for the
+// selected elements.  Paths that share a prefix read it once.
+//
+// A Projection is safe to use from multiple goroutines.
+type Projection struct {
+	root projNode
+	n    int
+}
+
+// projNode is a node in the trie of a Projection's paths.
+type projNode struct {
+	step     int
+	children []*projNode
+	results  []int // indices of the paths that end at this node
+}
+
+// NewProjection returns a Projection that selects the objects at paths.
+// NewProjection panics if a step is negative.
+func NewProjection(paths ...[]int) *Projection {
+	p := &Projection{n: len(paths)}
+	for i, path := range paths {
+		n := &p.root
+	steps:
+		for _, step := range path {
+			if step < 0 {
+				panic("capnp: negative step in projection path")
+			}
+			for _, c := range n.children {
+				if c.step == step {
+					n = c
+					continue steps
+				}
+			}
+			c := &projNode{step: step}
+			n.children = append(n.children, c)
+			n = c
+		}
+		n.results = append(n.results, i)
+	}
+	return p
+}
+
+// Len returns the number of paths in p.
+func (p *Projection) Len() int {
+	return p.n
+}
+
+// Read returns the objects selected by p in msg's root, in the order of
+// the paths passed to NewProjection.  The object of a path that goes
+// through a null pointer, a field that is not present in the struct or
+// an element past the end of a list is the null pointer.  The returned
+// objects may be read as usual: for example, a struct can be converted
+// to its generated type to access its data fields.
+func (p *Projection) Read(msg *Message) ([]Ptr, error) {
+	s, err := msg.Segment(0)
+	if err != nil {
+		return nil, exc.WrapError("projection", err)
+	}
+	root := s.root()
+	if root.seg == nil {
+		return nil, errors.New("projection: message does not contain root pointer")
+	}
+	obj, err := s.readPtrUnaccounted(0, root.depthLimit)
+	if err != nil {
+		return nil, exc.WrapError("projection", err)
+	}
+	res := make([]Ptr, p.n)
+	if err := p.root.read(obj, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// read charges obj to its message's read limit and reads n's subtree
+// from it into res.
+func (n *projNode) read(obj Ptr, res []Ptr) error {
+	if !obj.IsValid() {
+		return nil
+	}
+	var sz Size
+	if obj.flags.ptrType() == structPtrType || len(n.results) > 0 {
+		sz = obj.readSize()
+	}
+	if err := obj.Message().chargeRead(sz); err != nil {
+		return exc.WrapError("projection", err)
+	}
+	for _, i := range n.results {
+		res[i] = obj
+	}
+	for _, c := range n.children {
+		child, err := c.child(obj)
+		if err != nil {
+			return err
+		}
+		if err := c.read(child, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// child returns the object that n's step selects in its parent.
+func (n *projNode) child(parent Ptr) (Ptr, error) {
+	switch parent.flags.ptrType() {
+	case structPtrType:
+		s := parent.Struct()
+		if n.step >= int(s.size.PointerCount) {
+			return Ptr{}, nil
+		}
+		p, err := s.seg.readPtrUnaccounted(s.pointerAddress(uint16(n.step)), s.depthLimit)
+		if err != nil {
+			return Ptr{}, exc.WrapError("projection: pointer "+str.Itod(n.step), err)
+		}
+		return p, nil
+	case listPtrType:
+		l := parent.List()
+		if n.step >= l.Len() {
+			return Ptr{}, nil
+		}
+		if l.flags&isCompositeList != 0 {
+			if l.depthLimit == 0 {
+				return Ptr{}, errors.New("projection: depth limit reached")
+			}
+			return l.Struct(n.step).ToPtr(), nil
+		}
+		addr, err := l.primitiveElem(n.step, ObjectSize{PointerCount: 1})
+		if err != nil {
+			return Ptr{}, errors.New("projection: element " + str.Itod(n.step) + " of list is not a pointer")
+		}
+		p, err := l.seg.readPtrUnaccounted(addr, l.depthLimit)
+		if err != nil {
+			return Ptr{}, exc.WrapError("projection: element "+str.Itod(n.step), err)
+		}
+		return p, nil
+	default:
+		return Ptr{}, nil
+	}
+}
@@ -0,0 +1,90 @@
+package capnp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProjectionMessage returns a message whose root struct has a text
+// header in pointer 0 and a long list of structs, each with a text in
+// pointer 0, in pointer 1.
+func newProjectionMessage(t *testing.T) []byte {
+	msg, seg := NewSingleSegmentMessage(nil)
+	root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 2})
+	require.NoError(t, err)
+	header, err := NewText(seg, "route-me")
+	require.NoError(t, err)
+	require.NoError(t, root.SetPtr(0, header.ToPtr()))
+	items, err := NewCompositeList(seg, ObjectSize{PointerCount: 1}, 1000)
+	require.NoError(t, err)
+	for i := 0; i < items.Len(); i++ {
+		name, err := NewText(seg, "item")
+		require.NoError(t, err)
+		require.NoError(t, items.Struct(i).SetPtr(0, name.ToPtr()))
+	}
+	require.NoError(t, root.SetPtr(1, items.ToPtr()))
+	b, err := msg.Marshal()
+	require.NoError(t, err)
+	return b
+}
+
+func TestProjection(t *testing.T) {
+	t.Parallel()
+
+	b := newProjectionMessage(t)
+	proj := NewProjection(
+		[]int{0},
+		[]int{1, 500, 0},
+		[]int{1, 500},
+		[]int{1, 5000},
+		[]int{7},
+	)
+	assert.Equal(t, 5, proj.Len())
+
+	msg, err := Unmarshal(b)
+	require.NoError(t, err)
+	msg.ResetReadLimit(128)
+	res, err := proj.Read(msg)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	assert.Equal(t, "route-me", res[0].Text())
+	assert.Equal(t, "item", res[1].Text())
+	name, err := res[2].Struct().Ptr(0)
+	require.NoError(t, err)
+	assert.Equal(t, "item", name.Text())
+	assert.False(t, res[3].IsValid(), "element past the end of the list")
+	assert.False(t, res[4].IsValid(), "pointer past the end of the struct")
+
+	// Reading the same element through the list charges the whole list.
+	msg, err = Unmarshal(b)
+	require.NoError(t, err)
+	msg.ResetReadLimit(128)
+	root, err := msg.Root()
+	require.NoError(t, err)
+	_, err = root.Struct().Ptr(1)
+	assert.ErrorContains(t, err, "read traversal limit reached")
+}
+
+func TestProjectionNonPointerList(t *testing.T) {
+	t.Parallel()
+
+	b := newProjectionMessage(t)
+	msg, err := Unmarshal(b)
+	require.NoError(t, err)
+	_, err = NewProjection([]int{0, 3, 0}).Read(msg)
+	assert.ErrorContains(t, err, "element 3 of list is not a pointer")
+}
+
+func TestProjectionReadLimit(t *testing.T) {
+	t.Parallel()
+
+	b := newProjectionMessage(t)
+	msg, err := Unmarshal(b)
+	require.NoError(t, err)
+	msg.ResetReadLimit(64)
+	_, err = NewProjection([]int{1}).Read(msg)
+	assert.ErrorContains(t, err, "read traversal limit reached",
+		"selecting a whole list charges all of it")
+}
@@ -0,0 +1,152 @@
+// Package provenance records how capabilities were delegated.
+//
+// When a vat hands a capability on to another party, it can wrap it
+// with Delegate to record who delegated it to whom, and when.  Wrapping
+// an already delegated capability extends its chain, so the chain of a
+// capability that passed through several hands tells the whole story.
+// The chain can be read from a client with ChainOf, and from the
+// context of every call made through it with FromContext; server.LogCalls
+// includes it in its log entries.  This supports forensic analysis of
+// who could reach a capability, which is otherwise hard in
+// capability-based systems.
+package provenance // import "capnproto.org/go/capnp/v3/provenance"
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// A Delegation records that From handed a capability to To at Time.
+// From and To are application-defined names, such as user or vat
+// identifiers.
+type Delegation struct {
+	From string
+	To   string
+	Time time.Time
+}
+
+// String returns d in the form "from->to@time", with the time in
+// RFC 3339 format.
+func (d Delegation) String() string {
+	return d.From + "->" + d.To + "@" + d.Time.UTC().Format(time.RFC3339)
+}
+
+// A Chain lists the delegations of a capability, oldest first.
+type Chain []Delegation
+
+// String returns the delegations in c, separated by commas.
+func (c Chain) String() string {
+	s := make([]string, len(c))
+	for i, d := range c {
+		s[i] = d.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// Delegate returns a client that forwards calls to c and records that
+// it was delegated as described by d.  If d.Time is zero, the current
+// time is used.  If c was returned by Delegate, d is appended to its
+// chain.  Delegate takes ownership of c: it is released when the
+// returned client is shut down.
+func Delegate(c capnp.Client, d Delegation) capnp.Client {
+	if d.Time.IsZero() {
+		d.Time = time.Now()
+	}
+	n := &node{d: d, parent: nodeOf(c)}
+	return capnp.NewClient(&delegateHook{target: c, n: n})
+}
+
+// ChainOf returns the delegation chain of c, or nil if c was not
+// returned by Delegate.  Chains of capabilities imported from other
+// vats are not visible.
+func ChainOf(c capnp.Client) Chain {
+	return nodeOf(c).chain()
+}
+
+// FromContext returns the delegation chain of the capability through
+// which a call was made, given the context passed to the call's
+// implementation, or nil if the call was not made through a client
+// returned by Delegate.
+func FromContext(ctx context.Context) Chain {
+	n, _ := ctx.Value(nodeKey{}).(*node)
+	return n.chain()
+}
+
+// A node is a link in a delegation chain.  Nodes are immutable, so
+// chains with a common history share their ancestors.
+type node struct {
+	d      Delegation
+	parent *node
+}
+
+func nodeOf(c capnp.Client) *node {
+	s := c.Snapshot()
+	defer s.Release()
+	if h, ok := s.Brand().Value.(*delegateHook); ok {
+		return h.n
+	}
+	return nil
+}
+
+func (n *node) chain() Chain {
+	var c Chain
+	for ; n != nil; n = n.parent {
+		c = append(c, n.d)
+	}
+	for i, j := 0, len(c)-1; i < j; i, j = i+1, j-1 {
+		c[i], c[j] = c[j], c[i]
+	}
+	return c
+}
+
+// descends reports whether n is anc or one of its descendants.
+func (n *node) descends(anc *node) bool {
+	for ; n != nil; n = n.parent {
+		if n == anc {
+			return true
+		}
+	}
+	return false
+}
+
+type nodeKey struct{}
+
+// withNode returns ctx carrying n, unless ctx already carries a longer
+// chain that n is part of.  This happens when a call passes through
+// several delegations, and keeps the outermost, most complete chain.
+func withNode(ctx context.Context, n *node) context.Context {
+	if cur, ok := ctx.Value(nodeKey{}).(*node); ok && cur.descends(n) {
+		return ctx
+	}
+	return context.WithValue(ctx, nodeKey{}, n)
+}
+
+// A delegateHook is the capnp.ClientHook behind a client returned by
+// Delegate.
+type delegateHook struct {
+	target capnp.Client
+	n      *node
+}
+
+func (h *delegateHook) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	return h.target.SendCall(withNode(ctx, h.n), s)
+}
+
+func (h *delegateHook) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	return h.target.RecvCall(withNode(ctx, h.n), r)
+}
+
+func (h *delegateHook) Brand() capnp.Brand {
+	return capnp.Brand{Value: h}
+}
+
+func (h *delegateHook) Shutdown() {
+	h.target.Release()
+}
+
+func (h *delegateHook) String() string {
+	return "delegated(" + h.target.String() + ")"
+}
@@ -0,0 +1,52 @@
+package provenance_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/provenance"
+)
+
+// chainEcho is an Echo server that replies with the delegation chain
+// of the capability the call was made through.
+type chainEcho struct{}
+
+func (chainEcho) Echo(ctx context.Context, call air.Echo_echo) error {
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(provenance.FromContext(ctx).String())
+}
+
+func TestDelegate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	d1 := provenance.Delegation{From: "server", To: "alice", Time: time.Unix(0, 0)}
+	d2 := provenance.Delegation{From: "alice", To: "bob", Time: time.Unix(60, 0)}
+
+	root := capnp.Client(air.Echo_ServerToClient(chainEcho{}))
+	assert.Nil(t, provenance.ChainOf(root))
+	alice := provenance.Delegate(root, d1)
+	bob := air.Echo(provenance.Delegate(alice.AddRef(), d2))
+	defer bob.Release()
+	defer alice.Release()
+
+	assert.Equal(t, provenance.Chain{d1}, provenance.ChainOf(alice))
+	assert.Equal(t, provenance.Chain{d1, d2}, provenance.ChainOf(capnp.Client(bob)))
+
+	ans, release := bob.Echo(ctx, nil)
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	out, err := res.Out()
+	require.NoError(t, err)
+	assert.Equal(t, "server->alice@1970-01-01T00:00:00Z, alice->bob@1970-01-01T00:01:00Z", out)
+}
@@ -0,0 +1,55 @@
+package capnp
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrReadBudgetExhausted is returned by reads from a message whose
+// ReadBudget has run out.
+var ErrReadBudgetExhausted = errors.New("read budget exhausted")
+
+// A ReadBudget limits the bytes traversed while reading any of the
+// messages it is attached to; see Message.ReadBudget.  A message's
+// TraverseLimit only bounds the work done for that message, so a peer
+// can make a receiver do unbounded work by sending many small messages
+// that each stay under the limit.  Attaching one ReadBudget to all the
+// messages from a peer bounds the work done for the peer as a whole.
+//
+// A ReadBudget is never replenished on its own: call Add to grant more
+// bytes, for example periodically or as the peer's requests complete.
+// It is safe to use from multiple goroutines.
+type ReadBudget struct {
+	remaining atomic.Uint64
+}
+
+// NewReadBudget returns a budget that allows limit bytes to be read.
+func NewReadBudget(limit uint64) *ReadBudget {
+	b := new(ReadBudget)
+	b.remaining.Store(limit)
+	return b
+}
+
+// Remaining returns the number of bytes that may still be read.
+func (b *ReadBudget) Remaining() uint64 {
+	return b.remaining.Load()
+}
+
+// Add allows n more bytes to be read.
+func (b *ReadBudget) Add(n uint64) {
+	b.remaining.Add(n)
+}
+
+// take deducts sz from the budget, reporting false without deducting
+// anything if the budget does not allow sz more bytes.
+func (b *ReadBudget) take(sz Size) bool {
+	for {
+		curr := b.remaining.Load()
+		if curr < uint64(sz) {
+			return false
+		}
+		if b.remaining.CompareAndSwap(curr, curr-uint64(sz)) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package capnp
+
+import (
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// ReaderAtArena is a read-only Arena that loads the segments of a
+// message from an io.ReaderAt as they are accessed, so that a large
+// message, such as one in a multi-gigabyte file, can be read without
+// loading all of its segments into memory.
+//
+// Loaded segments are kept in a cache, and the least recently used
+// ones are dropped once their total size exceeds the arena's cache
+// budget.  A dropped segment is read again if it is needed later.
+// Objects that were read from a dropped segment remain valid, but keep
+// its data in memory until they are no longer referenced, so the budget
+// bounds the cache rather than the memory held by the message's users.
+//
+// A ReaderAtArena is safe to use from multiple goroutines.
+type ReaderAtArena struct {
+	r      io.ReaderAt
+	offs   []int64 // the offset in r of each segment
+	sizes  []Size
+	size   int64 // size of the framed message, header included
+	budget int64
+
+	mu     sync.Mutex
+	cache  map[SegmentID]*list.Element // values are *Segment
+	lru    list.List                   // most recently used first
+	cached int64                       // sum of the cached segments' sizes
+	err    error
+}
+
+// NewReaderAtArena reads the stream framing header of the message at
+// offset off in r and returns an arena that reads the message's
+// segments on demand.  The arena caches up to cacheBudget bytes of
+// segments; it always caches at least the last segment it loaded, even
+// if that segment is larger than the budget.
+func NewReaderAtArena(r io.ReaderAt, off int64, cacheBudget int64) (*ReaderAtArena, error) {
+	var word [wordSize]byte
+	if err := readFullAt(r, word[:], off); err != nil {
+		return nil, exc.WrapError("reader arena: read header", err)
+	}
+	maxSeg := SegmentID(binary.LittleEndian.Uint32(word[:]))
+	if maxSeg > maxStreamSegments {
+		return nil, errSegIDTooLarge{id: maxSeg, max: maxStreamSegments + 1}
+	}
+	hdr := make(streamHeader, streamHeaderSize(maxSeg))
+	copy(hdr, word[:])
+	if len(hdr) > len(word) {
+		if err := readFullAt(r, hdr[len(word):], off+int64(len(word))); err != nil {
+			return nil, exc.WrapError("reader arena: read header", err)
+		}
+	}
+
+	a := &ReaderAtArena{
+		r:      r,
+		offs:   make([]int64, maxSeg+1),
+		sizes:  make([]Size, maxSeg+1),
+		budget: cacheBudget,
+		cache:  make(map[SegmentID]*list.Element),
+	}
+	pos := off + int64(len(hdr))
+	for i := range a.sizes {
+		sz, err := hdr.segmentSize(SegmentID(i))
+		if err != nil {
+			return nil, exc.WrapError("reader arena", err)
+		}
+		a.offs[i] = pos
+		a.sizes[i] = sz
+		pos += int64(sz)
+	}
+	a.size = pos - off
+	return a, nil
+}
+
+// Size returns the size in bytes of the framed message, including its
+// header.  The next message in a stream of messages starts at the
+// offset passed to NewReaderAtArena plus Size.
+func (a *ReaderAtArena) Size() int64 {
+	return a.size
+}
+
+// Err returns the first error encountered while loading a segment.
+// Segment returns nil for a segment that cannot be loaded, which a
+// Message reports as an out of bounds segment; Err reports why.
+func (a *ReaderAtArena) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// NumSegments returns the number of segments in the message.
+func (a *ReaderAtArena) NumSegments() int64 {
+	return int64(len(a.sizes))
+}
+
+// Segment returns the segment with the given ID, reading it from the
+// underlying io.ReaderAt if it is not cached.  It returns nil if the
+// segment does not exist or cannot be read.
+func (a *ReaderAtArena) Segment(id SegmentID) *Segment {
+	if int64(id) >= int64(len(a.sizes)) {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if e, ok := a.cache[id]; ok {
+		a.lru.MoveToFront(e)
+		return e.Value.(*Segment)
+	}
+
+	data := make([]byte, a.sizes[id])
+	if err := readFullAt(a.r, data, a.offs[id]); err != nil {
+		if a.err == nil {
+			a.err = exc.WrapError("reader arena: read segment "+str.Utod(id), err)
+		}
+		return nil
+	}
+	seg := &Segment{id: id, data: data}
+	a.cache[id] = a.lru.PushFront(seg)
+	a.cached += int64(len(data))
+	for a.cached > a.budget && a.lru.Len() > 1 {
+		e := a.lru.Back()
+		old := a.lru.Remove(e).(*Segment)
+		delete(a.cache, old.id)
+		a.cached -= int64(len(old.data))
+	}
+	return seg
+}
+
+// Allocate always returns an error: a ReaderAtArena is read-only.
+func (a *ReaderAtArena) Allocate(Size, *Message, *Segment) (*Segment, address, error) {
+	return nil, 0, errors.New("reader arena: cannot allocate in a read-only arena")
+}
+
+// Release drops the cached segments.  It does not close the underlying
+// io.ReaderAt.
+func (a *ReaderAtArena) Release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cache = make(map[SegmentID]*list.Element)
+	a.lru.Init()
+	a.cached = 0
+}
+
+// readFullAt reads len(b) bytes at off.  Unlike io.ReaderAt.ReadAt, it
+// does not return io.EOF if it reads all of b.
+func readFullAt(r io.ReaderAt, b []byte, off int64) error {
+	n, err := r.ReadAt(b, off)
+	if n == len(b) {
+		return nil
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (a *ReaderAtArena) String() string {
+	return "reader arena: " + str.Itod(len(a.sizes)) + " segments, " + str.Itod(a.size) + " bytes"
+}
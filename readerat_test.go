@@ -0,0 +1,119 @@
+package capnp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReaderAt counts the calls to ReadAt.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads atomic.Int64
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	c.reads.Add(1)
+	return c.r.ReadAt(b, off)
+}
+
+// newReaderAtTestMessage returns a serialized multi-segment message
+// whose root is a list of n texts, the i'th of which repeats the letter
+// 'a'+i 1000 times.
+func newReaderAtTestMessage(t *testing.T, n int) ([]byte, int64) {
+	msg, seg := NewMultiSegmentMessage(nil)
+	defer msg.Release()
+	l, err := NewTextList(seg, int32(n))
+	require.NoError(t, err)
+	for i := 0; i < n; i++ {
+		require.NoError(t, l.Set(i, strings.Repeat(string(rune('a'+i)), 1000)))
+	}
+	require.NoError(t, msg.SetRoot(l.ToPtr()))
+	data, err := msg.Marshal()
+	require.NoError(t, err)
+	return data, msg.NumSegments()
+}
+
+func TestReaderAtArena(t *testing.T) {
+	t.Parallel()
+
+	first, nsegs := newReaderAtTestMessage(t, 8)
+	require.Greater(t, nsegs, int64(2), "test needs a multi-segment message")
+	second, _ := newReaderAtTestMessage(t, 2)
+	r := &countingReaderAt{r: bytes.NewReader(append(append([]byte(nil), first...), second...))}
+
+	// A budget of one byte caches a single segment, so that reading
+	// every text twice reloads segments.
+	arena, err := NewReaderAtArena(r, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(first)), arena.Size())
+	assert.Equal(t, nsegs, arena.NumSegments())
+	msg, _, err := NewMessage(arena)
+	require.NoError(t, err)
+
+	readAll := func() {
+		p, err := msg.Root()
+		require.NoError(t, err)
+		l := TextList(p.List())
+		require.Equal(t, 8, l.Len())
+		for i := 0; i < l.Len(); i++ {
+			s, err := l.At(i)
+			require.NoError(t, err)
+			assert.Equal(t, strings.Repeat(string(rune('a'+i)), 1000), s)
+		}
+	}
+	readAll()
+	loads := r.reads.Load()
+	readAll()
+	assert.Greater(t, r.reads.Load(), loads, "segments should be reloaded")
+	assert.NoError(t, arena.Err())
+	_, _, err = arena.Allocate(8, msg, nil)
+	assert.Error(t, err)
+	msg.Release()
+
+	// With a large budget, segments are read only once.
+	r.reads.Store(0)
+	arena, err = NewReaderAtArena(r, 0, int64(len(first)))
+	require.NoError(t, err)
+	msg, _, err = NewMessage(arena)
+	require.NoError(t, err)
+	readAll()
+	loads = r.reads.Load()
+	readAll()
+	assert.Equal(t, loads, r.reads.Load(), "segments should be cached")
+	msg.Release()
+
+	// The next message starts where the first one ends.
+	arena, err = NewReaderAtArena(r, int64(len(first)), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(second)), arena.Size())
+	msg, _, err = NewMessage(arena)
+	require.NoError(t, err)
+	p, err := msg.Root()
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.List().Len())
+	msg.Release()
+}
+
+func TestReaderAtArenaTruncated(t *testing.T) {
+	t.Parallel()
+
+	data, _ := newReaderAtTestMessage(t, 8)
+	_, err := NewReaderAtArena(bytes.NewReader(data[:4]), 0, 0)
+	assert.Error(t, err, "short header")
+
+	arena, err := NewReaderAtArena(bytes.NewReader(data[:len(data)-8]), 0, 0)
+	require.NoError(t, err)
+	msg, _, err := NewMessage(arena)
+	require.NoError(t, err)
+	defer msg.Release()
+	last := SegmentID(arena.NumSegments() - 1)
+	_, err = msg.Segment(last)
+	assert.Error(t, err)
+	assert.ErrorIs(t, arena.Err(), io.ErrUnexpectedEOF)
+}
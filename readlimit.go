@@ -0,0 +1,68 @@
+package capnp
+
+import (
+	"sync/atomic"
+)
+
+// A ReadLimiter limits the bytes traversed while reading a message, which
+// protects readers from amplification attacks; see Message.TraverseLimit.
+// A message that has no ReadLimiter uses its own counter, initialized
+// from TraverseLimit.  Setting Message.ReadLimiter replaces that counter,
+// for example with a limiter shared by all the messages received from
+// one connection, or with NoReadLimit for messages from a trusted source.
+//
+// Implementations must be safe to use from multiple goroutines.
+type ReadLimiter interface {
+	// CanRead deducts sz bytes from the limit, reporting whether the
+	// limit allowed them.  Once CanRead reports false, reads of the
+	// message fail.
+	CanRead(sz Size) bool
+
+	// Unread gives back sz bytes to the limit.
+	Unread(sz Size)
+}
+
+// NoReadLimit is a ReadLimiter that allows any number of bytes to be
+// read.  It should only be used for messages from trusted sources.
+var NoReadLimit ReadLimiter = noReadLimit{}
+
+type noReadLimit struct{}
+
+func (noReadLimit) CanRead(Size) bool { return true }
+func (noReadLimit) Unread(Size)       {}
+
+// NewReadLimiter returns a ReadLimiter that allows limit bytes to be
+// read, like the limiter a message creates from its TraverseLimit.
+// Once a read is denied, the limit is used up, so that smaller reads
+// fail too.
+func NewReadLimiter(limit uint64) ReadLimiter {
+	l := new(readCounter)
+	l.n.Store(limit)
+	return l
+}
+
+// readCounter is the default ReadLimiter.
+type readCounter struct {
+	n atomic.Uint64
+}
+
+func (l *readCounter) CanRead(sz Size) bool {
+	for {
+		curr := l.n.Load()
+		if curr < uint64(sz) {
+			// Use up the limit, so that reading smaller objects fails
+			// too from now on.
+			if l.n.CompareAndSwap(curr, 0) {
+				return false
+			}
+			continue
+		}
+		if l.n.CompareAndSwap(curr, curr-uint64(sz)) {
+			return true
+		}
+	}
+}
+
+func (l *readCounter) Unread(sz Size) {
+	l.n.Add(uint64(sz))
+}
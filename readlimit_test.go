@@ -132,3 +132,90 @@ func TestMessage_Unread(t *testing.T) {
 		assert.True(t, m.canRead(9), "should be able to read 9 bytes after unreading")
 	})
 }
+
+func TestReadBudget(t *testing.T) {
+	t.Parallel()
+
+	// newMessage returns a message with a 16-byte root struct.
+	newMessage := func(b *ReadBudget) *Message {
+		_, seg := NewSingleSegmentMessage(nil)
+		_, err := NewRootStruct(seg, ObjectSize{DataSize: 16})
+		require.NoError(t, err)
+		data, err := seg.Message().Marshal()
+		require.NoError(t, err)
+		msg, err := Unmarshal(data)
+		require.NoError(t, err)
+		msg.ReadBudget = b
+		return msg
+	}
+
+	b := NewReadBudget(40)
+	m1, m2 := newMessage(b), newMessage(b)
+
+	_, err := m1.Root()
+	require.NoError(t, err)
+	_, err = m2.Root()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), b.Remaining())
+
+	_, err = m1.Root()
+	assert.Error(t, err, "read should fail once the shared budget runs out")
+	assert.Equal(t, uint64(8), b.Remaining(), "failed read should not be charged")
+
+	m2.Unread(16)
+	assert.Equal(t, uint64(24), b.Remaining())
+	_, err = m1.Root()
+	assert.NoError(t, err)
+
+	b.Add(100)
+	_, err = m2.Root()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(92), b.Remaining())
+
+	_, err = m1.Reset(SingleSegment(nil))
+	require.NoError(t, err)
+	assert.Same(t, b, m1.ReadBudget, "Reset should keep the budget")
+}
+
+func TestReadLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Shared", func(t *testing.T) {
+		t.Parallel()
+
+		lim := NewReadLimiter(16)
+		m1 := &Message{TraverseLimit: 100, ReadLimiter: lim}
+		m2 := &Message{TraverseLimit: 100, ReadLimiter: lim}
+		require.True(t, m1.canRead(8))
+		require.True(t, m2.canRead(8))
+		assert.False(t, m1.canRead(1), "limit is shared by both messages")
+
+		m2.Unread(8)
+		assert.True(t, m1.canRead(8), "Unread gives back to the limiter")
+
+		m2.ResetReadLimit(100)
+		assert.False(t, m2.canRead(1), "ResetReadLimit does not affect the limiter")
+
+		_, err := m1.Reset(SingleSegment(nil))
+		require.NoError(t, err)
+		assert.Same(t, lim, m1.ReadLimiter, "Reset should keep the limiter")
+	})
+
+	t.Run("NoReadLimit", func(t *testing.T) {
+		t.Parallel()
+
+		m := &Message{TraverseLimit: 8, ReadLimiter: NoReadLimit}
+		assert.True(t, m.canRead(16))
+		assert.True(t, m.canRead(1<<30))
+	})
+
+	t.Run("ReadBudget", func(t *testing.T) {
+		t.Parallel()
+
+		lim := NewReadLimiter(16)
+		m := &Message{ReadLimiter: lim, ReadBudget: NewReadBudget(8)}
+		require.True(t, m.canRead(8))
+		assert.False(t, m.canRead(8), "budget is still charged")
+		assert.True(t, lim.CanRead(8), "denied read is given back to the limiter")
+	})
+}
@@ -3,7 +3,9 @@ package rpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/exc"
@@ -49,6 +51,10 @@ type ansent struct {
 	// May be nil.
 	cancel context.CancelFunc
 
+	// quotaCharged is true if the answer is counted against the Conn's
+	// Quota.
+	quotaCharged bool
+
 	// Unlike other fields in this struct, it is ok to hand out pointers
 	// to this that can be used while not holding the connection lock.
 	returner ansReturner
@@ -87,6 +93,11 @@ type ansReturner struct {
 	// to respect the invariant regarding the 4-way race condition that table
 	// entries must not path-shorten.
 	resultsCapTable []capnp.ClientSnapshot
+
+	// method is the method called and start is when the call was
+	// received, for Conn.MethodStats.
+	method capnp.Method
+	start  time.Time
 }
 
 type answerFlags uint8
@@ -116,10 +127,11 @@ func errorAnswer(c *Conn, id answerID, err error) *ansent {
 	}
 }
 
-// newReturn creates a new Return message. The returned Releaser will release the message when
+// newReturn creates a new Return message for the answer with the given
+// ID. The returned Releaser will release the message when
 // all references to it are dropped; the caller is responsible for one reference. This will not
 // happen before the message is sent, as the returned send function retains a reference.
-func (c *Conn) newReturn() (_ rpccp.Return, sendMsg func(), _ *rc.Releaser, _ error) {
+func (c *Conn) newReturn(id answerID) (_ rpccp.Return, sendMsg func(), _ *rc.Releaser, _ error) {
 	outMsg, err := c.transport.NewMessage()
 	if err != nil {
 		return rpccp.Return{}, nil, nil, rpcerr.WrapFailed("create return", err)
@@ -129,6 +141,8 @@ func (c *Conn) newReturn() (_ rpccp.Return, sendMsg func(), _ *rc.Releaser, _ er
 		outMsg.Release()
 		return rpccp.Return{}, nil, nil, rpcerr.WrapFailed("create return", err)
 	}
+	ret.SetAnswerId(uint32(id))
+	ret.SetReleaseParamCaps(false)
 
 	// Before releasing the message, we need to wait both until it is sent and
 	// until the local vat is done with it.  We therefore implement a simple
@@ -136,7 +150,11 @@ func (c *Conn) newReturn() (_ rpccp.Return, sendMsg func(), _ *rc.Releaser, _ er
 	// 'releaseMsg' is called.
 	releaser := rc.NewReleaser(2, outMsg.Release)
 
+	// The results are filled in by whichever goroutine runs the call,
+	// and the message is encoded by the send goroutine.
+	outMsg.Message().Message().Handoff()
 	return ret, func() {
+		outMsg.Message().Message().Handoff()
 		c.lk.sendTx.Send(asyncSend{
 			send:    outMsg.Send,
 			release: releaser.Decr,
@@ -204,16 +222,73 @@ func (ans *ansReturner) PrepareReturn(e error) {
 	dq := &deferred.Queue{}
 	defer dq.Run()
 
+	// If the results are over Options.MaxReturnSize, an exception is
+	// sent in a new message instead.  The message must be created
+	// without holding the lock.
+	var (
+		bigErr     error
+		newRet     rpccp.Return
+		newSend    func()
+		newRelease *rc.Releaser
+	)
+	if e == nil {
+		if bigErr = ans.checkSize(); bigErr != nil {
+			var err error
+			newRet, newSend, newRelease, err = ans.c.newReturn(ans.id)
+			if err != nil {
+				// Send the results after all; the remote vat is
+				// better served by a large return than none.
+				ans.c.er.ReportError(rpcerr.Annotate(err, "replace large return"))
+				bigErr = nil
+			}
+		}
+	}
+
 	ans.c.withLocked(func(c *lockedConn) {
-		ent := c.lk.answers[ans.id]
-		if e == nil {
+		ent := c.lk.answers.get(ans.id)
+		switch {
+		case bigErr != nil:
+			ent.replaceReturn(dq, newRet, newSend, newRelease)
+			ent.prepareSendException(dq, bigErr)
+		case e == nil:
 			ent.prepareSendReturn(dq)
-		} else {
+		default:
 			ent.prepareSendException(dq, e)
 		}
 	})
 }
 
+// checkSize returns an exception if the return message is larger than
+// Options.MaxReturnSize.
+func (ans *ansReturner) checkSize() error {
+	if ans.c.maxReturnSize == 0 || !ans.ret.IsValid() {
+		return nil
+	}
+	size, err := ans.ret.Message().TotalSize()
+	if err != nil || size <= ans.c.maxReturnSize {
+		return nil
+	}
+	return rpcerr.New(exc.Failed, fmt.Errorf("%w: %d bytes, limit is %d",
+		ErrReturnTooLarge, size, ans.c.maxReturnSize))
+}
+
+// replaceReturn discards the return message prepared so far, including
+// any results, in favor of a new one.
+//
+// The caller MUST be holding onto ans.c.lk.
+func (ans *ansent) replaceReturn(dq *deferred.Queue, ret rpccp.Return, sendMsg func(), msgReleaser *rc.Releaser) {
+	old := ans.returner.msgReleaser
+	dq.Defer(old.Decr) // reference held by sendMsg
+	dq.Defer(old.Decr) // reference held by the answer
+	if ans.returner.results.IsValid() {
+		dq.Defer(old.Decr) // reference taken by AllocResults
+	}
+	ans.returner.ret = ret
+	ans.returner.results = rpccp.Payload{}
+	ans.returner.msgReleaser = msgReleaser
+	ans.sendMsg = sendMsg
+}
+
 // Return implements capnp.Returner.Return
 func (ans *ansReturner) Return() {
 	dq := &deferred.Queue{}
@@ -222,8 +297,9 @@ func (ans *ansReturner) Return() {
 
 	var err error
 	ans.c.withLocked(func(c *lockedConn) {
-		ent := c.lk.answers[ans.id]
+		ent := c.lk.answers.get(ans.id)
 		pcallsWait = ent.pcalls.Wait
+		c.recordReturn(ans.method, c.clock.Now().Sub(ans.start), ent.err != nil)
 
 		if ent.err == nil {
 			err = ent.completeSendReturn(dq)
@@ -261,13 +337,27 @@ func (ans *ansReturner) ReleaseResults() {
 // sendReturn MUST NOT be called if sendException was previously called.
 func (ans *ansent) sendReturn(dq *deferred.Queue) error {
 	ans.prepareSendReturn(dq)
+	if ans.err != nil {
+		ans.completeSendException(dq)
+		return nil
+	}
 	return ans.completeSendReturn(dq)
 }
 
 func (ans *ansent) prepareSendReturn(dq *deferred.Queue) {
+	if ans.pipelineLoop() {
+		// The results would never be ready.
+		ans.prepareSendException(dq, ExcPipelineLoop)
+		return
+	}
 	var err error
 	c := ans.lockedConn()
-	ans.exportRefs, err = c.fillPayloadCapTable(ans.returner.results)
+	ans.exportRefs, err = c.fillPayloadCapTable(dq, ans.returner.results)
+	if errors.Is(err, ErrQuotaExceeded) {
+		// The results can't be sent, so return the exception instead.
+		ans.prepareSendException(dq, err)
+		return
+	}
 	if err != nil {
 		c.er.ReportError(rpcerr.Annotate(err, "send return"))
 	}
@@ -320,6 +410,87 @@ func (ans *ansent) completeSendReturn(dq *deferred.Queue) error {
 	return nil
 }
 
+// pipelineLoop reports whether a capability in the results is a promise
+// that depends on the results themselves, directly or through the
+// results of other answers.  Returning such results would leave calls
+// on the capability waiting forever.
+//
+// The caller MUST be holding onto ans.c.lk.
+func (ans *ansent) pipelineLoop() bool {
+	results := ans.returner.results
+	if ans.promise == nil || !results.IsValid() {
+		return false
+	}
+	c := ans.lockedConn()
+	self := ans.promise.Answer()
+	capTable := results.Message().CapTable()
+	for i := 0; i < capTable.Len(); i++ {
+		snapshot := capTable.At(i).Snapshot()
+		loop := c.dependsOn(snapshot, self)
+		snapshot.Release()
+		if loop {
+			return true
+		}
+	}
+	return false
+}
+
+// dependsOn reports whether snapshot is a promise pipelined on answer.
+// Promises pipelined on other answers are followed to the capabilities
+// they will resolve to, once those answers have prepared their results.
+// Promises on answers that have returned need no help: they take on the
+// brand of the capability they resolved to.
+//
+// The caller MUST be holding onto c.lk.
+func (c *lockedConn) dependsOn(snapshot capnp.ClientSnapshot, answer *capnp.Answer) bool {
+	seen := make(map[*capnp.Answer]bool)
+	for {
+		pc, ok := snapshot.Brand().Value.(capnp.PipelineClient)
+		if !ok || seen[pc.Answer()] {
+			return false
+		}
+		if pc.Answer() == answer {
+			return true
+		}
+		seen[pc.Answer()] = true
+		if snapshot, ok = c.pipelineTarget(pc); !ok {
+			return false
+		}
+	}
+}
+
+// pipelineTarget returns the capability that pc will resolve to, if pc
+// is pipelined on an answer whose results have been prepared but not
+// yet returned.  The snapshot is borrowed from the answer's results.
+//
+// The caller MUST be holding onto c.lk.
+func (c *lockedConn) pipelineTarget(pc capnp.PipelineClient) (capnp.ClientSnapshot, bool) {
+	var ent *ansent
+	c.lk.answers.each(func(_ answerID, a *ansent) {
+		if a.promise != nil && a.promise.Answer() == pc.Answer() {
+			ent = a
+		}
+	})
+	// resultsCapTable is set once the results are prepared, after
+	// which they are no longer written to.
+	if ent == nil || ent.err != nil || ent.returner.resultsCapTable == nil {
+		return capnp.ClientSnapshot{}, false
+	}
+	content, err := ent.returner.results.Content()
+	if err != nil {
+		return capnp.ClientSnapshot{}, false
+	}
+	ptr, err := capnp.Transform(content, pc.Transform())
+	if err != nil {
+		return capnp.ClientSnapshot{}, false
+	}
+	capID := ptr.Interface().Capability()
+	if !ptr.Interface().IsValid() || int(capID) >= len(ent.returner.resultsCapTable) {
+		return capnp.ClientSnapshot{}, false
+	}
+	return ent.returner.resultsCapTable[capID], true
+}
+
 // sendException sends an exception on the answer's return message.
 //
 // The caller MUST be holding onto ans.c.lk. sendException MUST NOT
@@ -343,6 +514,11 @@ func (ans *ansent) prepareSendException(dq *deferred.Queue, ex error) {
 		} else if err := e.MarshalError(ex); err != nil {
 			c.er.ReportError(exc.WrapError("send exception", err))
 			ans.sendMsg = nil
+		} else if c.callPath {
+			if err := setCallPathTrace(e, ex); err != nil {
+				c.er.ReportError(exc.WrapError("send exception", err))
+				ans.sendMsg = nil
+			}
 		}
 	}
 }
@@ -375,9 +551,13 @@ func (ans *ansent) completeSendException(dq *deferred.Queue) {
 func (ans *ansent) destroy(dq *deferred.Queue) error {
 	dq.Defer(ans.returner.msgReleaser.Decr)
 	c := ans.lockedConn()
-	delete(c.lk.answers, ans.returner.id)
-	for _, s := range ans.returner.resultsCapTable {
-		dq.Defer(s.Release)
+	c.lk.answers.remove(ans.returner.id)
+	if ans.quotaCharged {
+		ans.quotaCharged = false
+		c.quota.remove(0, 1)
+	}
+	for i := range ans.returner.resultsCapTable {
+		dq.Defer(ans.returner.resultsCapTable[i].Release)
 	}
 	if !ans.flags.Contains(releaseResultCapsFlag) || len(ans.exportRefs) == 0 {
 		return nil
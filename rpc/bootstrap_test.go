@@ -0,0 +1,252 @@
+package rpc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// bootstrapCountingTransport counts the Bootstrap messages it receives.
+type bootstrapCountingTransport struct {
+	rpc.Transport
+	n atomic.Int32
+}
+
+func (t *bootstrapCountingTransport) RecvMessage() (transport.IncomingMessage, error) {
+	in, err := t.Transport.RecvMessage()
+	if err == nil && in.Message().Which() == rpccp.Message_Which_bootstrap {
+		t.n.Add(1)
+	}
+	return in, err
+}
+
+func TestCacheBootstrap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	left, right := transport.NewPipe(1)
+	serverTrans := &bootstrapCountingTransport{Transport: rpc.NewTransport(right)}
+	serverConn := rpc.NewConn(serverTrans, &rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		Logger:          testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	clientConn := rpc.NewConn(rpc.NewTransport(left), &rpc.Options{
+		CacheBootstrap: true,
+		Logger:         testErrorReporter{tb: t},
+	})
+	defer clientConn.Close()
+
+	echo := func(client capnp.Client, n int64) {
+		ans, release := testcp.PingPong(client).EchoNum(ctx, func(p testcp.PingPong_echoNum_Params) error {
+			p.SetN(n)
+			return nil
+		})
+		defer release()
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, n, res.N())
+	}
+
+	first := clientConn.Bootstrap(ctx)
+	defer first.Release()
+	echo(first, 1)
+
+	// Wait until the resolved client has been cached.
+	require.Eventually(t, func() bool {
+		c := clientConn.Bootstrap(ctx)
+		defer c.Release()
+		return c.IsSame(first)
+	}, time.Second, time.Millisecond)
+	sent := serverTrans.n.Load()
+
+	for i := 0; i < 3; i++ {
+		c := clientConn.Bootstrap(ctx)
+		echo(c, int64(i))
+		c.Release()
+	}
+	assert.Equal(t, sent, serverTrans.n.Load(), "cached Bootstrap sent Bootstrap messages")
+
+	refreshed := clientConn.RefreshBootstrap(ctx)
+	defer refreshed.Release()
+	echo(refreshed, 42)
+	assert.Equal(t, sent+1, serverTrans.n.Load(), "RefreshBootstrap did not send a Bootstrap message")
+
+	// The original client keeps working.
+	echo(first, 2)
+}
+
+func TestBootstrapTimeout(t *testing.T) {
+	t.Parallel()
+
+	clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	conn, p2 := newTestConn(&rpc.Options{
+		BootstrapClient:  capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		BootstrapTimeout: time.Minute,
+		Clock:            clk,
+	})
+	defer p2.Close()
+	defer conn.Close()
+
+	// The peer never sends anything, so the connection is aborted once
+	// the timeout has passed.  The timer is started by a background
+	// goroutine, so keep advancing the clock until it fires.
+	advanced := make(chan struct{})
+	defer close(advanced)
+	go func() {
+		for {
+			select {
+			case <-advanced:
+				return
+			case <-time.After(time.Millisecond):
+				clk.Advance(time.Minute)
+			}
+		}
+	}()
+	in, err := p2.RecvMessage()
+	require.NoError(t, err)
+	defer in.Release()
+	require.Equal(t, rpccp.Message_Which_abort, in.Message().Which())
+	abort, err := in.Message().Abort()
+	require.NoError(t, err)
+	reason, err := abort.Reason()
+	require.NoError(t, err)
+	assert.Contains(t, reason, rpc.ErrBootstrapTimeout.Error())
+
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection was not shut down")
+	}
+}
+
+func TestBootstrapTimeoutMet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient:  capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		BootstrapTimeout: time.Second,
+		Logger:           testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	client := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer client.Release()
+	require.NoError(t, client.Resolve(ctx))
+
+	// The timeout only applies to the first message.
+	time.Sleep(1100 * time.Millisecond)
+	ans, release := echoNum(ctx, client, 7)
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), res.N())
+}
+
+// addPonger echoes its argument plus a constant.
+type addPonger int64
+
+func (a addPonger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	res.SetN(call.Args().N() + int64(a))
+	return nil
+}
+
+func TestExchangeBootstrap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		left, right := transport.NewPipe(1)
+		type result struct {
+			conn   *rpc.Conn
+			client capnp.Client
+			err    error
+		}
+		exchange := func(trans transport.Codec, add int64) <-chan result {
+			ch := make(chan result, 1)
+			go func() {
+				conn, client, err := rpc.ExchangeBootstrap(ctx,
+					rpc.NewTransport(trans),
+					capnp.Client(testcp.PingPong_ServerToClient(addPonger(add))),
+					&rpc.Options{Logger: testErrorReporter{tb: t}})
+				ch <- result{conn, client, err}
+			}()
+			return ch
+		}
+		leftCh, rightCh := exchange(left, 100), exchange(right, 200)
+		l, r := <-leftCh, <-rightCh
+		require.NoError(t, l.err)
+		require.NoError(t, r.err)
+		defer l.conn.Close()
+		defer r.conn.Close()
+		defer l.client.Release()
+		defer r.client.Release()
+
+		echo := func(client capnp.Client, n int64) int64 {
+			ans, release := testcp.PingPong(client).EchoNum(ctx, func(p testcp.PingPong_echoNum_Params) error {
+				p.SetN(n)
+				return nil
+			})
+			defer release()
+			res, err := ans.Struct()
+			require.NoError(t, err)
+			return res.N()
+		}
+		assert.Equal(t, int64(201), echo(l.client, 1), "left side gets the right side's bootstrap")
+		assert.Equal(t, int64(102), echo(r.client, 2), "right side gets the left side's bootstrap")
+	})
+
+	t.Run("NoRemoteBootstrap", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		left, right := transport.NewPipe(1)
+		serverConn := rpc.NewConn(rpc.NewTransport(right), nil)
+		defer serverConn.Close()
+
+		conn, client, err := rpc.ExchangeBootstrap(ctx,
+			rpc.NewTransport(left),
+			capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+			nil)
+		assert.Error(t, err)
+		assert.Nil(t, conn)
+		assert.False(t, client.IsValid())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		left, right := transport.NewPipe(1)
+		defer right.Close()
+
+		// Nobody is listening on the other end.
+		_, _, err := rpc.ExchangeBootstrap(ctx,
+			rpc.NewTransport(left),
+			capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+			nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// A CallHop is one call in the path taken by a failed call; see
+// CallPath and Options.PropagateCallPath.
+type CallHop struct {
+	// Peer describes the vat that the call was made to, as given by
+	// the calling Conn's RemotePeerID.
+	Peer string
+
+	// Method is the method that was called.
+	Method string
+}
+
+func (h CallHop) String() string {
+	return h.Method + " on " + h.Peer
+}
+
+// CallPath returns the calls through which err reached this vat, in
+// the order they were made: the first hop is the call made by this
+// vat, and the last is the call that failed.  It returns nil if err
+// did not come from a Conn with Options.PropagateCallPath set.
+func CallPath(err error) []CallHop {
+	var cpe *callPathError
+	if !errors.As(err, &cpe) {
+		return nil
+	}
+	return append([]CallHop(nil), cpe.path...)
+}
+
+// callPathError is the cause of an exception received by a Conn with
+// Options.PropagateCallPath set.  It formats as the remote reason, so
+// that the path does not repeat itself as it is passed along.
+type callPathError struct {
+	reason string
+	path   []CallHop
+}
+
+func (e *callPathError) Error() string {
+	return e.reason
+}
+
+// callPathHop returns the hop for a call to method made over c.
+func (c *lockedConn) callPathHop(method capnp.Method) CallHop {
+	var peer string
+	switch id := c.remotePeerID; {
+	case id.Value != nil:
+		peer = fmt.Sprint(id.Value)
+	case id.Addr != nil:
+		peer = id.Addr.String()
+	default:
+		peer = "unknown peer"
+	}
+	return CallHop{Peer: peer, Method: method.String()}
+}
+
+// callPathException returns the error for an exception e, received in
+// return to a call to method, with c's hop prepended to the path that
+// the remote vat put in e's trace.
+func (c *lockedConn) callPathException(e rpccp.Exception, reason string, method capnp.Method) error {
+	path := []CallHop{c.callPathHop(method)}
+	if trace, err := e.Trace(); err == nil {
+		path = append(path, parseCallPath(trace)...)
+	}
+	return &callPathError{reason: reason, path: path}
+}
+
+// setCallPathTrace records the path of err, if any, in e's trace.
+func setCallPathTrace(e rpccp.Exception, err error) error {
+	path := CallPath(err)
+	if len(path) == 0 {
+		return nil
+	}
+	return e.SetTrace(formatCallPath(path))
+}
+
+// The trace text holds one hop per line, with the peer and method
+// separated by a tab.
+
+var callPathEscaper = strings.NewReplacer("\t", " ", "\n", " ")
+
+func formatCallPath(path []CallHop) string {
+	var sb strings.Builder
+	for _, h := range path {
+		sb.WriteString(callPathEscaper.Replace(h.Peer))
+		sb.WriteByte('\t')
+		sb.WriteString(callPathEscaper.Replace(h.Method))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// parseCallPath parses a trace written by formatCallPath, ignoring
+// lines in other formats.
+func parseCallPath(trace string) []CallHop {
+	var path []CallHop
+	for _, line := range strings.Split(trace, "\n") {
+		peer, method, ok := strings.Cut(line, "\t")
+		if ok {
+			path = append(path, CallHop{Peer: peer, Method: method})
+		}
+	}
+	return path
+}
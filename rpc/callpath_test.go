@@ -0,0 +1,97 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+// failingPonger fails every call.
+type failingPonger struct{}
+
+func (failingPonger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	return exc.New(exc.Overloaded, "", "boom")
+}
+
+// relayPonger forwards calls to next.
+type relayPonger struct {
+	next testcp.PingPong
+}
+
+func (p relayPonger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	ans, release := echoNum(ctx, p.next, call.Args().N())
+	defer release()
+	_, err := ans.Struct()
+	return err
+}
+
+// callThroughRelay makes a call from vat A to vat B, which makes a call
+// to vat C, which fails.  It returns the error seen by A.
+func callThroughRelay(t *testing.T, propagate bool) error {
+	ctx := context.Background()
+
+	cConn, bcConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient:   capnp.Client(testcp.PingPong_ServerToClient(failingPonger{})),
+		PropagateCallPath: propagate,
+		Logger:            testErrorReporter{tb: t},
+	}, &rpc.Options{
+		RemotePeerID:      rpc.PeerID{Value: "c"},
+		PropagateCallPath: propagate,
+		Logger:            testErrorReporter{tb: t},
+	})
+	defer cConn.Close()
+	defer bcConn.Close()
+
+	next := testcp.PingPong(bcConn.Bootstrap(ctx))
+	defer next.Release()
+	bConn, aConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient:   capnp.Client(testcp.PingPong_ServerToClient(relayPonger{next: next})),
+		PropagateCallPath: propagate,
+		Logger:            testErrorReporter{tb: t},
+	}, &rpc.Options{
+		RemotePeerID:      rpc.PeerID{Value: "b"},
+		PropagateCallPath: propagate,
+		Logger:            testErrorReporter{tb: t},
+	})
+	defer bConn.Close()
+	defer aConn.Close()
+
+	pp := testcp.PingPong(aConn.Bootstrap(ctx))
+	defer pp.Release()
+	ans, release := echoNum(ctx, pp, 42)
+	defer release()
+	_, err := ans.Struct()
+	require.Error(t, err)
+	return err
+}
+
+func TestCallPath(t *testing.T) {
+	t.Parallel()
+
+	err := callThroughRelay(t, true)
+	assert.Equal(t, exc.Overloaded, exc.TypeOf(err), "exception type should survive")
+	assert.Contains(t, err.Error(), "boom")
+
+	method := capnp.Method{InterfaceID: testcp.PingPong_TypeID, MethodID: 0}
+	assert.Equal(t, []rpc.CallHop{
+		{Peer: "b", Method: method.String()},
+		{Peer: "c", Method: method.String()},
+	}, rpc.CallPath(err))
+}
+
+func TestCallPathDisabled(t *testing.T) {
+	t.Parallel()
+
+	err := callThroughRelay(t, false)
+	assert.Equal(t, exc.Overloaded, exc.TypeOf(err))
+	assert.Nil(t, rpc.CallPath(err))
+	assert.Nil(t, rpc.CallPath(errors.New("local")))
+}
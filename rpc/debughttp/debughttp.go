@@ -0,0 +1,286 @@
+// Package debughttp serves a web page that shows the state of live RPC
+// connections, so that operators can inspect a running vat from a
+// browser, in the spirit of golang.org/x/net/trace.
+//
+// Connections are registered with a Registry, which removes them again
+// once they shut down, and the Registry is served over HTTP:
+//
+//	conn := rpc.NewConn(transport, opts)
+//	debughttp.Register("backend", conn)
+//	http.Handle("/debug/capnp", debughttp.DefaultRegistry)
+//
+// The page lists each connection's debug snapshot, protocol deviations
+// and per-method call stats, as reported by rpc.Conn.DebugSnapshot,
+// rpc.Conn.Deviations and rpc.Conn.MethodStats.  Adding ?conn=ID to the
+// URL shows one connection along with its export and import tables, and
+// adding ?format=json returns the same information as JSON.
+//
+// A Registry is also an expvar.Var, so it can be published with
+// expvar.Publish to include its connections in /debug/vars.
+//
+// The page reveals the capabilities that each connection holds and the
+// methods that are called on them, so it should only be served to
+// trusted users.
+package debughttp // import "capnproto.org/go/capnp/v3/rpc/debughttp"
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+)
+
+// A Registry holds the connections shown by its handler.  The zero
+// value is an empty registry.  It is safe to use from multiple
+// goroutines.
+type Registry struct {
+	mu     sync.Mutex
+	nextID uint64
+	conns  map[uint64]entry
+}
+
+type entry struct {
+	name  string
+	conn  *rpc.Conn
+	added time.Time
+}
+
+// DefaultRegistry is the registry used by Register.
+var DefaultRegistry = new(Registry)
+
+// Register adds c to DefaultRegistry under name.
+func Register(name string, c *rpc.Conn) {
+	DefaultRegistry.Register(name, c)
+}
+
+// Register adds c to r under name, until c shuts down.  Names are for
+// display only and need not be unique.
+func (r *Registry) Register(name string, c *rpc.Conn) {
+	r.mu.Lock()
+	if r.conns == nil {
+		r.conns = make(map[uint64]entry)
+	}
+	r.nextID++
+	id := r.nextID
+	r.conns[id] = entry{name: name, conn: c, added: time.Now()}
+	r.mu.Unlock()
+
+	go func() {
+		<-c.Done()
+		r.mu.Lock()
+		delete(r.conns, id)
+		r.mu.Unlock()
+	}()
+}
+
+// ConnInfo is the state of a registered connection.
+type ConnInfo struct {
+	// ID identifies the connection within its registry.
+	ID uint64
+
+	// Name is the name the connection was registered under, and Peer
+	// describes the remote vat, if known.
+	Name string
+	Peer string
+
+	// Registered is when the connection was registered.
+	Registered time.Time
+
+	Snapshot   rpc.DebugSnapshot
+	Deviations map[string]uint64
+	Methods    []rpc.MethodStats
+}
+
+// Conns returns the state of the connections in r, ordered by ID.
+func (r *Registry) Conns() []ConnInfo {
+	r.mu.Lock()
+	ids := make([]uint64, 0, len(r.conns))
+	for id := range r.conns {
+		ids = append(ids, id)
+	}
+	entries := make([]entry, len(ids))
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for i, id := range ids {
+		entries[i] = r.conns[id]
+	}
+	r.mu.Unlock()
+
+	// Conn methods acquire the Conn's lock, so they are called without
+	// holding r.mu.
+	infos := make([]ConnInfo, len(entries))
+	for i, e := range entries {
+		infos[i] = ConnInfo{
+			ID:         ids[i],
+			Name:       e.name,
+			Peer:       e.conn.RemotePeerID().String(),
+			Registered: e.added,
+			Snapshot:   e.conn.DebugSnapshot(),
+			Deviations: make(map[string]uint64),
+			Methods:    e.conn.MethodStats(),
+		}
+		for d, n := range e.conn.Deviations() {
+			infos[i].Deviations[d.String()] = n
+		}
+	}
+	return infos
+}
+
+// String returns the state of the connections in r as a JSON array,
+// which makes r an expvar.Var.
+func (r *Registry) String() string {
+	b, err := json.Marshal(r.Conns())
+	if err != nil {
+		return strconv.Quote(err.Error())
+	}
+	return string(b)
+}
+
+// ServeHTTP serves a page showing the connections in r.  See the
+// package documentation for the query parameters that it accepts.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	infos := r.Conns()
+	detail := false
+	if s := req.FormValue("conn"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid conn: "+s, http.StatusBadRequest)
+			return
+		}
+		infos = filterID(infos, id)
+		if len(infos) == 0 {
+			http.Error(w, "no conn "+s, http.StatusNotFound)
+			return
+		}
+		detail = true
+	}
+
+	if req.FormValue("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if detail {
+			enc.Encode(infos[0])
+		} else {
+			enc.Encode(infos)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := page.Execute(w, pageData{
+		Path:   req.URL.Path,
+		Conns:  infos,
+		Detail: detail,
+		Now:    time.Now(),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func filterID(infos []ConnInfo, id uint64) []ConnInfo {
+	for _, info := range infos {
+		if info.ID == id {
+			return []ConnInfo{info}
+		}
+	}
+	return nil
+}
+
+type pageData struct {
+	Path   string
+	Conns  []ConnInfo
+	Detail bool
+	Now    time.Time
+}
+
+type capTable struct {
+	Title string
+	Caps  []rpc.CapInfo
+	Now   time.Time
+}
+
+var page = template.Must(template.New("page").Funcs(template.FuncMap{
+	"since": func(now, t time.Time) time.Duration {
+		if t.IsZero() {
+			return 0
+		}
+		return now.Sub(t).Round(time.Millisecond)
+	},
+	"idle": func(now time.Time, ci rpc.CapInfo) time.Duration {
+		return ci.Idle(now).Round(time.Millisecond)
+	},
+	"method": func(m capnp.Method) string {
+		return m.String()
+	},
+	"capTable": func(title string, caps []rpc.CapInfo, now time.Time) capTable {
+		return capTable{Title: title, Caps: caps, Now: now}
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Cap'n Proto connections</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+{{if .Detail}}<p><a href="{{.Path}}">All connections</a></p>{{end}}
+<h1>Cap'n Proto connections</h1>
+{{if not .Conns}}<p>No connections are registered.</p>{{end}}
+{{$now := .Now}}{{$path := .Path}}{{$detail := .Detail}}
+{{range .Conns}}
+<h2><a href="{{$path}}?conn={{.ID}}">#{{.ID}} {{.Name}}</a></h2>
+<p>Peer: {{or .Peer "unknown"}}; registered {{since $now .Registered}} ago.</p>
+<table>
+<tr><th>Questions</th><th>Answers</th><th>Exports</th><th>Imports</th><th>Queued calls</th><th>Oldest queued</th><th>RTT</th></tr>
+<tr>
+<td class="n">{{.Snapshot.Questions}}</td>
+<td class="n">{{.Snapshot.Answers}}</td>
+<td class="n">{{len .Snapshot.Exports}}</td>
+<td class="n">{{len .Snapshot.Imports}}</td>
+<td class="n">{{.Snapshot.Queued.Depth}}</td>
+<td class="n">{{.Snapshot.Queued.Oldest}}</td>
+<td class="n">{{.Snapshot.RTT}}</td>
+</tr>
+</table>
+{{if .Deviations}}
+<table>
+<tr><th>Protocol deviation</th><th>Count</th></tr>
+{{range $d, $n := .Deviations}}<tr><td>{{$d}}</td><td class="n">{{$n}}</td></tr>
+{{end}}
+</table>
+{{end}}
+{{if .Methods}}
+<table>
+<tr><th>Method received</th><th>Calls</th><th>Errors</th><th>Mean time</th><th>Max time</th></tr>
+{{range .Methods}}<tr><td>{{method .Method}}</td><td class="n">{{.Calls}}</td><td class="n">{{.Errors}}</td><td class="n">{{.Mean}}</td><td class="n">{{.MaxTime}}</td></tr>
+{{end}}
+</table>
+{{end}}
+{{if $detail}}
+{{template "caps" (capTable "Exports" .Snapshot.Exports $now)}}
+{{template "caps" (capTable "Imports" .Snapshot.Imports $now)}}
+{{end}}
+{{end}}
+</body>
+</html>
+{{define "caps"}}
+<h3>{{.Title}}</h3>
+{{if .Caps}}
+<table>
+<tr><th>ID</th><th>Refs</th><th>Age</th><th>Idle</th><th>Revoked</th></tr>
+{{$now := .Now}}{{range .Caps}}<tr><td class="n">{{.ID}}</td><td class="n">{{.Refs}}</td><td class="n">{{since $now .Created}}</td><td class="n">{{idle $now .}}</td><td>{{if .Revoked}}yes{{end}}</td></tr>
+{{end}}
+</table>
+{{else}}<p>None.</p>{{end}}
+{{end}}`))
@@ -0,0 +1,108 @@
+package debughttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/rpc/debughttp"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+type echoer struct{}
+
+func (echoer) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	results, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	results.SetN(call.Args().N())
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(echoer{})),
+	}, nil)
+	defer server.Close()
+	defer client.Close()
+
+	var reg debughttp.Registry
+	reg.Register("<server>", server)
+	reg.Register("client", client)
+
+	ctx := context.Background()
+	pp := testcp.PingPong(client.Bootstrap(ctx))
+	defer pp.Release()
+	f, release := pp.EchoNum(ctx, nil)
+	_, err := f.Struct()
+	release()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(server.MethodStats()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/capnp"+query, nil))
+		return rec
+	}
+
+	rec := get("")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "#1 &lt;server&gt;", "names are escaped")
+	assert.Contains(t, body, "#2 client")
+	assert.Contains(t, body, "Method received")
+	assert.NotContains(t, body, "<h3>Exports</h3>", "tables only shown for one conn")
+
+	rec = get("?conn=1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body = rec.Body.String()
+	assert.Contains(t, body, "<h3>Exports</h3>")
+	assert.NotContains(t, body, "#2 client")
+
+	rec = get("?format=json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var infos []debughttp.ConnInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &infos))
+	require.Len(t, infos, 2)
+	assert.Equal(t, "<server>", infos[0].Name)
+	require.Len(t, infos[0].Methods, 1)
+	assert.Equal(t, uint64(testcp.PingPong_TypeID), infos[0].Methods[0].Method.InterfaceID)
+	assert.Equal(t, uint64(1), infos[0].Methods[0].Calls)
+	assert.Len(t, infos[0].Snapshot.Exports, 1)
+	assert.Len(t, infos[1].Snapshot.Imports, 1)
+
+	rec = get("?conn=2&format=json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var info debughttp.ConnInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.Equal(t, "client", info.Name)
+
+	assert.Equal(t, http.StatusNotFound, get("?conn=3").Code)
+	assert.Equal(t, http.StatusBadRequest, get("?conn=x").Code)
+
+	// The registry is an expvar.Var.
+	require.NoError(t, json.Unmarshal([]byte(reg.String()), &infos))
+	assert.Len(t, infos, 2)
+
+	// Conns are removed when they shut down.
+	pp.Release()
+	require.NoError(t, client.Close())
+	require.Eventually(t, func() bool {
+		return len(reg.Conns()) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, strings.Contains(get("").Body.String(), "No connections are registered."))
+}
@@ -13,9 +13,27 @@ var (
 	ErrConnClosed        = errors.New("connection closed")
 	ErrNotACapability    = errors.New("not a capability")
 	ErrCapTablePopulated = errors.New("capability table already populated")
+	ErrSendQueueFull     = errors.New("send queue full")
+	ErrTooManyQuestions  = errors.New("too many outstanding questions")
+	ErrBootstrapTimeout  = errors.New("timed out waiting for first message from peer")
+	ErrExportIdle        = errors.New("export released after being idle")
+	ErrReturnTooLarge    = errors.New("return message too large")
+	ErrAuthFailed        = errors.New("peer failed authentication")
+	ErrMuxClosed         = errors.New("listener mux closed")
+	ErrQuotaExceeded     = errors.New("peer quota exceeded")
+	ErrForwardLoop       = errors.New("too many forwarded calls outstanding; possible forwarding loop")
+	ErrTransformTooDeep  = errors.New("promised answer transform too deep")
+	ErrPipelineLoop      = errors.New("promise is pipelined on its own answer")
 
 	// RPC exceptions
-	ExcClosed = rpcerr.Disconnected(ErrConnClosed)
+	ExcClosed           = rpcerr.Disconnected(ErrConnClosed)
+	ExcOverloaded       = rpcerr.New(exc.Overloaded, ErrSendQueueFull)
+	ExcTooManyQuestions = rpcerr.New(exc.Overloaded, ErrTooManyQuestions)
+	ExcExportIdle       = rpcerr.Disconnected(ErrExportIdle)
+	ExcQuotaExceeded    = rpcerr.New(exc.Overloaded, ErrQuotaExceeded)
+	ExcForwardLoop      = rpcerr.New(exc.Overloaded, ErrForwardLoop)
+	ExcTransformTooDeep = rpcerr.Failed(ErrTransformTooDeep)
+	ExcPipelineLoop     = rpcerr.Failed(ErrPipelineLoop)
 )
 
 type errReporter struct {
@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"context"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// ExchangeBootstrap creates a Conn for a connection where both vats
+// offer a bootstrap capability and use each other's, as is common when
+// the two sides act as both client and server.  It returns the Conn
+// and the remote vat's resolved bootstrap capability, which the caller
+// is responsible for releasing.
+//
+// bootstrap is offered to the remote vat from the moment the Conn is
+// created, so it is available however the two sides' bootstrap
+// requests are ordered.  ExchangeBootstrap steals bootstrap, which
+// replaces opts.BootstrapClient; if the latter is set, it is released.
+// Other options are used as given.
+//
+// If the remote bootstrap capability cannot be obtained before ctx is
+// done, ExchangeBootstrap closes the Conn and returns an error.
+func ExchangeBootstrap(ctx context.Context, t Transport, bootstrap capnp.Client, opts *Options) (*Conn, capnp.Client, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	o.BootstrapClient.Release()
+	o.BootstrapClient = bootstrap
+	c := NewConn(t, &o)
+
+	theirs := c.Bootstrap(ctx)
+	err := theirs.Resolve(ctx)
+	if err == nil {
+		snapshot := theirs.Snapshot()
+		err, _ = snapshot.Brand().Value.(error)
+		snapshot.Release()
+	}
+	if err != nil {
+		// Releasing an unresolved bootstrap client waits for its
+		// answer, so shut down the Conn first.
+		c.Close()
+		theirs.Release()
+		return nil, capnp.Client{}, rpcerr.Annotate(err, "exchange bootstrap")
+	}
+	return c, theirs, nil
+}
@@ -3,6 +3,8 @@ package rpc
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/exc"
@@ -22,6 +24,16 @@ type expent struct {
 
 	// Should be called when removing this entry from the exports table:
 	cancel context.CancelFunc
+
+	// Lifetime tracking; see Conn.DebugSnapshot and IdlePolicy.
+	created      time.Time
+	lastCall     time.Time
+	idleReported bool // reset by each call
+	revoked      bool // snapshot was replaced by IdlePolicy
+
+	// gate holds calls to a promise until its Resolve message has been
+	// sent; see Options.StrictResolveOrder.  nil if not in use.
+	gate *resolveGate
 }
 
 // A key for use in a client's Metadata, whose value is the export
@@ -50,10 +62,7 @@ func (c *lockedConn) clearExportID(m *capnp.Metadata) {
 // findExport returns the export entry with the given ID or nil if
 // couldn't be found. The caller must be holding c.mu
 func (c *lockedConn) findExport(id exportID) *expent {
-	if int64(id) >= int64(len(c.lk.exports)) {
-		return nil
-	}
-	return c.lk.exports[id] // might be nil
+	return c.lk.exports.get(id) // might be nil
 }
 
 // releaseExport decreases the number of wire references to an export
@@ -69,8 +78,9 @@ func (c *lockedConn) releaseExport(dq *deferred.Queue, id exportID, count uint32
 	case count == ent.wireRefs:
 		defer ent.cancel()
 		snapshot := ent.snapshot
-		c.lk.exports[id] = nil
+		c.lk.exports.remove(id)
 		c.lk.exportID.remove(id)
+		c.quota.remove(1, 0)
 		metadata := snapshot.Metadata()
 		if metadata != nil {
 			syncutil.With(metadata, func() {
@@ -108,9 +118,17 @@ func (c *lockedConn) sendCap(d rpccp.CapDescriptor, snapshot capnp.ClientSnapsho
 
 	defer snapshot.Release()
 	bv := snapshot.Brand().Value
+	if c.noShortening {
+		// Skip the checks below and export the capability.
+		bv = nil
+	}
+	if fw, ok := bv.(*forwarder); ok && fw.src.c == (*Conn)(c) {
+		// Sending a forwarder back to where its calls go.
+		bv = fw.src
+	}
 	if ic, ok := bv.(*importClient); ok {
 		if ic.c == (*Conn)(c) {
-			if ent := c.lk.imports[ic.id]; ent != nil && ent.generation == ic.generation {
+			if ent := c.lk.imports.get(ic.id); ent != nil && ent.generation == ic.generation {
 				d.SetReceiverHosted(uint32(ic.id))
 				return 0, false, nil
 			}
@@ -151,21 +169,22 @@ func (c *lockedConn) sendCap(d rpccp.CapDescriptor, snapshot capnp.ClientSnapsho
 	id, ok := c.findExportID(metadata)
 	var ee *expent
 	if ok {
-		ee = c.lk.exports[id]
+		ee = c.lk.exports.get(id)
 		ee.wireRefs++
 	} else {
 		// Not already present; allocate an export id for it:
+		if !c.quota.addExport() {
+			return 0, false, ExcQuotaExceeded
+		}
 		ee = &expent{
 			snapshot: snapshot.AddRef(),
 			wireRefs: 1,
 			cancel:   func() {},
+			created:  c.clock.Now(),
 		}
 		id = c.lk.exportID.next()
-		if int64(id) == int64(len(c.lk.exports)) {
-			c.lk.exports = append(c.lk.exports, ee)
-		} else {
-			c.lk.exports[id] = ee
-		}
+		c.decide(DecisionExportID, uint32(id))
+		c.lk.exports.set(id, ee)
 		c.setExportID(metadata, id)
 	}
 	if ee.snapshot.IsPromise() {
@@ -180,10 +199,15 @@ func (c *lockedConn) sendCap(d rpccp.CapDescriptor, snapshot capnp.ClientSnapsho
 func (c *lockedConn) sendSenderPromise(id exportID, d rpccp.CapDescriptor) {
 	// Send a promise, wait for the resolution asynchronously, then send
 	// a resolve message:
-	ee := c.lk.exports[id]
+	ee := c.lk.exports.get(id)
 	d.SetSenderPromise(uint32(id))
 	ctx, cancel := context.WithCancel(c.bgctx)
 	ee.cancel = cancel
+	var gate *resolveGate
+	if c.strictResolve && ee.gate == nil {
+		gate = new(resolveGate)
+		ee.gate = gate
+	}
 	waitRef := ee.snapshot.AddRef()
 	go func() {
 		defer cancel()
@@ -194,14 +218,16 @@ func (c *lockedConn) sendSenderPromise(id exportID, d rpccp.CapDescriptor) {
 		unlockedConn := (*Conn)(c)
 
 		waitErr := waitRef.Resolve1(ctx)
+		sent := false
 		unlockedConn.withLocked(func(c *lockedConn) {
-			if len(c.lk.exports) <= int(id) || c.lk.exports[id] != ee {
+			if c.lk.exports.get(id) != ee {
 				// Export was removed from the table at some point;
 				// remote peer is uninterested in the resolution, so
 				// drop the reference and we're done
 				return
 			}
 
+			sent = true
 			sendRef := waitRef.AddRef()
 			var (
 				resolvedID exportID
@@ -225,9 +251,23 @@ func (c *lockedConn) sendSenderPromise(id exportID, d rpccp.CapDescriptor) {
 					return err
 				}
 				resolvedID, isExport, err = c.sendCap(desc, sendRef)
+				if errors.Is(err, ErrQuotaExceeded) {
+					// Resolve the promise to the error instead, so
+					// the remote vat doesn't wait on it forever.
+					ex, err := res.NewException()
+					if err != nil {
+						return err
+					}
+					return ex.MarshalError(ExcQuotaExceeded)
+				}
 				return err
 			}, func(err error) {
 				sendRef.Release()
+				if gate != nil {
+					// Called from the send goroutine, which
+					// forwarding the held calls may need.
+					go gate.open()
+				}
 				if err != nil && isExport {
 					dq := &deferred.Queue{}
 					defer dq.Run()
@@ -246,13 +286,86 @@ func (c *lockedConn) sendSenderPromise(id exportID, d rpccp.CapDescriptor) {
 				}
 			})
 		})
+		if !sent && gate != nil {
+			gate.open()
+		}
 	}()
 }
 
+// findResolveGate returns the gate of the export through which snapshot
+// is exposed, or nil if there is none.
+func (c *lockedConn) findResolveGate(snapshot capnp.ClientSnapshot) *resolveGate {
+	if !c.strictResolve || !snapshot.IsValid() {
+		return nil
+	}
+	metadata := snapshot.Metadata()
+	metadata.Lock()
+	id, ok := c.findExportID(metadata)
+	metadata.Unlock()
+	if !ok {
+		return nil
+	}
+	if ee := c.lk.exports.get(id); ee != nil {
+		return ee.gate
+	}
+	return nil
+}
+
+// A resolveGate holds the calls received for an exported promise until
+// the Resolve message for the promise has been written to the
+// transport, so that the remote vat sees the Resolve before any of
+// those calls that the promise forwards back to it.  A nil *resolveGate
+// is always open.
+type resolveGate struct {
+	mu     sync.Mutex
+	isOpen bool
+	held   []func()
+}
+
+// deliver calls f with snapshot, or, if g is closed, with a new
+// reference to snapshot once g opens.  Calls are delivered in the
+// order deliver was called.
+func (g *resolveGate) deliver(snapshot capnp.ClientSnapshot, f func(capnp.ClientSnapshot)) {
+	if g != nil {
+		g.mu.Lock()
+		if !g.isOpen {
+			ref := snapshot.AddRef()
+			g.held = append(g.held, func() {
+				defer ref.Release()
+				f(ref)
+			})
+			g.mu.Unlock()
+			return
+		}
+		g.mu.Unlock()
+	}
+	f(snapshot)
+}
+
+// open delivers the held calls and lets later calls through.
+func (g *resolveGate) open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for len(g.held) > 0 {
+		// Deliver without the lock, so that calls that arrive
+		// meanwhile queue up behind these instead of overtaking them.
+		held := g.held
+		g.held = nil
+		g.mu.Unlock()
+		for _, f := range held {
+			f()
+		}
+		g.mu.Lock()
+	}
+	g.isOpen = true
+}
+
 // fillPayloadCapTable adds descriptors of payload's message's
 // capabilities into payload's capability table and returns the
-// reference counts that have been added to the exports table.
-func (c *lockedConn) fillPayloadCapTable(payload rpccp.Payload) (map[exportID]uint32, error) {
+// reference counts that have been added to the exports table.  If the
+// exports would exceed the Conn's Quota, fillPayloadCapTable releases
+// the references added so far using dq and returns ExcQuotaExceeded.
+func (c *lockedConn) fillPayloadCapTable(dq *deferred.Queue, payload rpccp.Payload) (map[exportID]uint32, error) {
 	if !payload.IsValid() {
 		return nil, nil
 	}
@@ -267,6 +380,12 @@ func (c *lockedConn) fillPayloadCapTable(payload rpccp.Payload) (map[exportID]ui
 	var refs map[exportID]uint32
 	for i := 0; i < clients.Len(); i++ {
 		id, isExport, err := c.sendCap(list.At(i), clients.At(i).Snapshot())
+		if errors.Is(err, ErrQuotaExceeded) {
+			// Give back the exports made so far, so that the
+			// message can be dropped.
+			c.er.ReportError(c.releaseExportRefs(dq, refs))
+			return nil, err
+		}
 		if err != nil {
 			return nil, rpcerr.WrapFailed("Serializing capability", err)
 		}
@@ -299,6 +418,7 @@ func (e embargo) String() string {
 // The caller must be holding onto c.mu.
 func (c *lockedConn) embargo(client capnp.Client) (embargoID, capnp.Client) {
 	id := c.lk.embargoID.next()
+	c.decide(DecisionEmbargoID, uint32(id))
 	e := newEmbargo(client)
 	if int64(id) == int64(len(c.lk.embargoes)) {
 		c.lk.embargoes = append(c.lk.embargoes, e)
@@ -0,0 +1,204 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+)
+
+// featureMethod is the method that Negotiate calls on the remote
+// bootstrap capability.  Its interface ID is reserved for this purpose;
+// both its params and results are a struct whose only pointer is a
+// List(Text) of feature names.  Peers that don't negotiate fail the
+// call as unimplemented, like any other unknown method.
+var featureMethod = capnp.Method{
+	InterfaceID:   0xc9a3f0d62b1e8475,
+	MethodID:      0,
+	InterfaceName: "rpc.Features",
+	MethodName:    "negotiate",
+}
+
+var featuresSize = capnp.ObjectSize{PointerCount: 1}
+
+// Negotiate exchanges Options.Features with the remote vat and returns
+// the features that both vats support, in sorted order.  If the remote
+// vat does not negotiate, because it predates negotiation or was not
+// given any features, Negotiate returns no features and a nil error,
+// so that callers can fall back to the base protocol.  Only one side
+// of a connection needs to call Negotiate; the other side learns the
+// caller's features from the call.
+func (c *Conn) Negotiate(ctx context.Context) ([]string, error) {
+	boot := c.Bootstrap(ctx)
+	defer boot.Release()
+	ans, release := boot.SendCall(ctx, capnp.Send{
+		Method:   featureMethod,
+		ArgsSize: featuresSize,
+		PlaceArgs: func(s capnp.Struct) error {
+			return writeFeatures(s, c.features)
+		},
+	})
+	defer release()
+	res, err := ans.Struct()
+	if exc.IsType(err, exc.Unimplemented) {
+		c.setRemoteFeatures(nil)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	remote, err := readFeatures(res)
+	if err != nil {
+		return nil, err
+	}
+	c.setRemoteFeatures(remote)
+	return c.commonFeatures(remote), nil
+}
+
+// HasFeature reports whether both vats support the named feature.  It
+// returns false until features have been exchanged by a call to
+// Negotiate on either side of the connection.
+func (c *Conn) HasFeature(name string) bool {
+	remote, ok := c.RemoteFeatures()
+	if !ok {
+		return false
+	}
+	for _, f := range c.commonFeatures(remote) {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteFeatures returns the features advertised by the remote vat,
+// and whether they are known yet.
+func (c *Conn) RemoteFeatures() ([]string, bool) {
+	return withLockedConn2(c, func(c *lockedConn) ([]string, bool) {
+		return c.lk.remoteFeatures, c.lk.featuresKnown
+	})
+}
+
+func (c *Conn) setRemoteFeatures(remote []string) {
+	c.withLocked(func(c *lockedConn) {
+		c.lk.remoteFeatures = remote
+		c.lk.featuresKnown = true
+	})
+}
+
+// commonFeatures returns the features in remote that c supports.
+func (c *Conn) commonFeatures(remote []string) []string {
+	var common []string
+	for _, r := range remote {
+		for _, f := range c.features {
+			if r == f {
+				common = append(common, r)
+				break
+			}
+		}
+	}
+	sort.Strings(common)
+	return common
+}
+
+func writeFeatures(s capnp.Struct, features []string) error {
+	l, err := capnp.NewTextList(s.Segment(), int32(len(features)))
+	if err != nil {
+		return err
+	}
+	for i, f := range features {
+		if err := l.Set(i, f); err != nil {
+			return err
+		}
+	}
+	return s.SetPtr(0, l.ToPtr())
+}
+
+func readFeatures(s capnp.Struct) ([]string, error) {
+	p, err := s.Ptr(0)
+	if err != nil {
+		return nil, err
+	}
+	l := capnp.TextList(p.List())
+	features := make([]string, 0, l.Len())
+	for i := 0; i < l.Len(); i++ {
+		f, err := l.At(i)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, f)
+	}
+	return features, nil
+}
+
+// reservedHook wraps the bootstrap capability of a Conn with
+// Options.Features or Options.Manifest, answering the reserved
+// negotiation and manifest calls and passing all other calls through.
+type reservedHook struct {
+	c        *Conn
+	boot     capnp.Client
+	manifest map[string]capnp.Client
+}
+
+func (h *reservedHook) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	return h.boot.SendCall(ctx, s)
+}
+
+func (h *reservedHook) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	var handle func(capnp.Recv) error
+	switch r.Method.InterfaceID {
+	case featureMethod.InterfaceID:
+		if r.Method.MethodID == featureMethod.MethodID && len(h.c.features) > 0 {
+			handle = h.negotiate
+		}
+	case manifestMethod.InterfaceID:
+		if r.Method.MethodID == manifestMethod.MethodID && h.manifest != nil {
+			handle = h.writeManifest
+		}
+	default:
+		return h.boot.RecvCall(ctx, r)
+	}
+	err := capnp.Unimplemented("unimplemented method " + r.Method.String())
+	if handle != nil {
+		err = handle(r)
+	}
+	r.ReleaseArgs()
+	r.Returner.PrepareReturn(err)
+	r.Returner.Return()
+	r.Returner.ReleaseResults()
+	return nil
+}
+
+// negotiate answers a Negotiate call.
+func (h *reservedHook) negotiate(r capnp.Recv) error {
+	remote, err := readFeatures(r.Args)
+	if err != nil {
+		return err
+	}
+	h.c.setRemoteFeatures(remote)
+	res, err := r.AllocResults(featuresSize)
+	if err != nil {
+		return err
+	}
+	return writeFeatures(res, h.c.features)
+}
+
+// Brand returns the brand of the wrapped bootstrap capability, so that
+// checks like server.IsServer still see through the hook.
+func (h *reservedHook) Brand() capnp.Brand {
+	snapshot := h.boot.Snapshot()
+	defer snapshot.Release()
+	return snapshot.Brand()
+}
+
+func (h *reservedHook) Shutdown() {
+	h.boot.Release()
+	for _, c := range h.manifest {
+		c.Release()
+	}
+}
+
+func (h *reservedHook) String() string {
+	return "rpc.reservedHook(" + h.boot.String() + ")"
+}
@@ -0,0 +1,79 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("BothSides", func(t *testing.T) {
+		peers := make(chan rpc.PeerID, 1)
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(peerRecorder{peers})),
+			Features:        []string{"metadata", "compress/zstd", "level3"},
+			Logger:          testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Features: []string{"level3", "compress/zstd", "compress/lz4"},
+			Logger:   testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		defer client.Close()
+
+		_, ok := client.RemoteFeatures()
+		assert.False(t, ok, "features known before negotiation")
+		assert.False(t, client.HasFeature("level3"))
+
+		common, err := client.Negotiate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compress/zstd", "level3"}, common)
+		assert.True(t, client.HasFeature("compress/zstd"))
+		assert.False(t, client.HasFeature("compress/lz4"))
+		assert.False(t, client.HasFeature("metadata"))
+
+		remote, ok := server.RemoteFeatures()
+		require.True(t, ok, "server did not learn the client's features")
+		assert.Equal(t, []string{"level3", "compress/zstd", "compress/lz4"}, remote)
+		assert.True(t, server.HasFeature("level3"))
+		assert.False(t, server.HasFeature("metadata"))
+
+		// Other calls still reach the bootstrap capability.
+		pp := testcp.PingPong(client.Bootstrap(ctx))
+		defer pp.Release()
+		ans, release := pp.EchoNum(ctx, nil)
+		defer release()
+		_, err = ans.Struct()
+		require.NoError(t, err)
+		<-peers
+	})
+
+	t.Run("RemoteWithoutFeatures", func(t *testing.T) {
+		peers := make(chan rpc.PeerID, 1)
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(peerRecorder{peers})),
+			Logger:          testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Features: []string{"level3"},
+			Logger:   testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		defer client.Close()
+
+		common, err := client.Negotiate(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, common)
+		remote, ok := client.RemoteFeatures()
+		assert.True(t, ok)
+		assert.Empty(t, remote)
+		assert.False(t, client.HasFeature("level3"))
+	})
+}
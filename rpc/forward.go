@@ -0,0 +1,143 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// DefaultMaxForwardCalls is the default of ForwardOptions.MaxCalls.
+const DefaultMaxForwardCalls = 1024
+
+// ForwardOptions configures Forward.
+type ForwardOptions struct {
+	// MaxCalls limits the calls that a forwarding capability has
+	// outstanding at once.  If capabilities are forwarded in a loop,
+	// so that calls come back to a forwarder they already went
+	// through, calls pile up without end; once the limit is reached,
+	// further calls fail with an overloaded exception wrapping
+	// ErrForwardLoop.  If zero, DefaultMaxForwardCalls is used.
+	MaxCalls int
+}
+
+// Forward returns a client that is efficient to export on dst for c, a
+// capability that this vat imported from another Conn.  It is a manual
+// fallback for three-party handoff, which this package does not
+// implement yet: the remote vat of dst can't reach c directly, so its
+// calls are forwarded through this vat.
+//
+// Forward waits for c to resolve, and then shortens the path where it
+// can:
+//
+//   - If c is hosted by the remote vat of dst, Forward returns c, which
+//     dst sends back to that vat as its own capability.
+//   - If c is hosted in this vat, Forward returns c, since no forwarding
+//     is needed.
+//   - If c was returned by an earlier call to Forward, the new client
+//     forwards to the original capability instead of adding a hop.
+//
+// Otherwise, the returned client forwards each call to c as soon as it
+// is received, and pipelined calls on the results follow it to c's Conn
+// without waiting for the results, much like a tail call.  If the
+// client is later sent back over c's Conn, it is sent as the remote
+// vat's own capability.
+//
+// Forward does not take ownership of c.  The caller must release the
+// returned client.
+func Forward(ctx context.Context, dst *Conn, c capnp.Client, opts *ForwardOptions) (capnp.Client, error) {
+	if err := c.Resolve(ctx); err != nil {
+		return capnp.Client{}, err
+	}
+	snapshot := c.Snapshot()
+	defer snapshot.Release()
+
+	target := c
+	var src *importClient
+	switch bv := snapshot.Brand().Value.(type) {
+	case *forwarder:
+		target, src = bv.target, bv.src
+	case *importClient:
+		src = bv
+	}
+	if src == nil || src.c == dst {
+		return target.AddRef(), nil
+	}
+
+	maxCalls := DefaultMaxForwardCalls
+	if opts != nil && opts.MaxCalls > 0 {
+		maxCalls = opts.MaxCalls
+	}
+	return capnp.NewClient(&forwarder{
+		target:   target.AddRef(),
+		src:      src,
+		maxCalls: int64(maxCalls),
+	}), nil
+}
+
+// A forwarder is the capnp.ClientHook behind a client returned by
+// Forward.
+type forwarder struct {
+	target capnp.Client
+	// src is the import that target resolved to.  It stays valid as
+	// long as target holds a reference to it.
+	src *importClient
+
+	maxCalls int64
+	calls    atomic.Int64
+}
+
+// startCall counts a call, reporting false if it would exceed the limit.
+func (f *forwarder) startCall() bool {
+	if f.calls.Add(1) > f.maxCalls {
+		f.calls.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (f *forwarder) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	if !f.startCall() {
+		return capnp.ErrorAnswer(s.Method, ExcForwardLoop), func() {}
+	}
+	ans, release := f.target.SendCall(ctx, s)
+	var done atomic.Bool
+	return ans, func() {
+		if done.CompareAndSwap(false, true) {
+			f.calls.Add(-1)
+		}
+		release()
+	}
+}
+
+func (f *forwarder) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	if !f.startCall() {
+		r.Reject(ExcForwardLoop)
+		return nil
+	}
+	r.Returner = forwardReturner{Returner: r.Returner, f: f}
+	return f.target.RecvCall(ctx, r)
+}
+
+func (f *forwarder) Brand() capnp.Brand {
+	return capnp.Brand{Value: f}
+}
+
+func (f *forwarder) Shutdown() {
+	f.target.Release()
+}
+
+func (f *forwarder) String() string {
+	return "forward(" + f.target.String() + ")"
+}
+
+// forwardReturner ends a call received by a forwarder when it returns.
+type forwardReturner struct {
+	capnp.Returner
+	f *forwarder
+}
+
+func (r forwardReturner) Return() {
+	r.Returner.Return()
+	r.f.calls.Add(-1)
+}
@@ -0,0 +1,159 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+func TestForward(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// origin <-> middle <-> edge: the middle vat forwards the origin's
+	// bootstrap capability to the edge vat.
+	origin := &selfChecker{got: make(chan bool, 1)}
+	originConn, toOrigin := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.CapArgsTest_ServerToClient(origin)),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer originConn.Close()
+	defer toOrigin.Close()
+
+	boot, resolver := capnp.NewLocalPromise[testcp.CapArgsTest]()
+	toEdge, edgeConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(boot),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer toEdge.Close()
+	defer edgeConn.Close()
+
+	imported := toOrigin.Bootstrap(ctx)
+	defer imported.Release()
+	fwd, err := rpc.Forward(ctx, toEdge, imported, nil)
+	require.NoError(t, err)
+	resolver.Fulfill(testcp.CapArgsTest(fwd))
+
+	t.Run("Calls", func(t *testing.T) {
+		c := testcp.CapArgsTest(edgeConn.Bootstrap(ctx))
+		defer c.Release()
+
+		// A pipelined call on the results goes through too.
+		f, release := c.Self(ctx, nil)
+		defer release()
+		f2, release2 := f.Self().Self(ctx, nil)
+		defer release2()
+		_, err := f2.Struct()
+		require.NoError(t, err)
+
+		// The edge vat passes the forwarder back through the middle
+		// vat, which sends the origin its own capability.
+		f3, release3 := c.Call(ctx, func(p testcp.CapArgsTest_call_Params) error {
+			return p.SetCap(capnp.Client(c.AddRef()))
+		})
+		defer release3()
+		_, err = f3.Struct()
+		require.NoError(t, err)
+		assert.True(t, <-origin.got, "origin did not receive its own capability")
+	})
+
+	t.Run("Shortening", func(t *testing.T) {
+		same, err := rpc.Forward(ctx, toOrigin, imported, nil)
+		require.NoError(t, err)
+		defer same.Release()
+		assert.True(t, same.IsSame(imported), "capability of dst's peer should not be forwarded")
+
+		local := capnp.Client(testcp.CapArgsTest_ServerToClient(origin))
+		defer local.Release()
+		same, err = rpc.Forward(ctx, toEdge, local, nil)
+		require.NoError(t, err)
+		defer same.Release()
+		assert.True(t, same.IsSame(local), "local capability should not be forwarded")
+
+		again, err := rpc.Forward(ctx, edgeConn, fwd, nil)
+		require.NoError(t, err)
+		defer again.Release()
+		assert.Equal(t, fwd.String(), again.String(), "forwarder should not be forwarded again")
+	})
+}
+
+func TestForwardMaxCalls(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	unblock := make(chan struct{})
+	originConn, toOrigin := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(blockingPinger{unblock})),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer originConn.Close()
+	defer toOrigin.Close()
+	_, other := transport.NewPipe(1)
+	toEdge := rpc.NewConn(rpc.NewTransport(other), nil)
+	defer toEdge.Close()
+
+	imported := toOrigin.Bootstrap(ctx)
+	defer imported.Release()
+	fwd, err := rpc.Forward(ctx, toEdge, imported, &rpc.ForwardOptions{MaxCalls: 1})
+	require.NoError(t, err)
+	pp := testcp.PingPong(fwd)
+	defer pp.Release()
+
+	f1, release1 := pp.EchoNum(ctx, nil)
+	f2, release2 := pp.EchoNum(ctx, nil)
+	_, err = f2.Struct()
+	release2()
+	assert.ErrorIs(t, err, rpc.ErrForwardLoop)
+	assert.True(t, exc.IsType(err, exc.Overloaded), "got %v; want overloaded", err)
+
+	close(unblock)
+	_, err = f1.Struct()
+	require.NoError(t, err)
+	release1()
+
+	f3, release3 := pp.EchoNum(ctx, nil)
+	defer release3()
+	_, err = f3.Struct()
+	assert.NoError(t, err, "released call should not count")
+}
+
+// selfChecker reports whether the capabilities passed to Call are
+// itself.
+type selfChecker struct {
+	got chan bool
+}
+
+func (s *selfChecker) Self(ctx context.Context, call testcp.CapArgsTest_self) error {
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetSelf(testcp.CapArgsTest_ServerToClient(s))
+}
+
+func (s *selfChecker) Call(ctx context.Context, call testcp.CapArgsTest_call) error {
+	c := call.Args().Cap()
+	if err := c.Resolve(ctx); err != nil {
+		return err
+	}
+	snapshot := c.Snapshot()
+	defer snapshot.Release()
+	brand, ok := server.IsServer(snapshot.Brand())
+	s.got <- ok && brand == s
+	return nil
+}
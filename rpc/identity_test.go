@@ -0,0 +1,67 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestIsSameResolvedImports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	empty := testcp.Empty_ServerToClient(struct{}{})
+	defer empty.Release()
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.EmptyProvider_ServerToClient(sameEmptyProvider{empty})),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer server.Close()
+	defer client.Close()
+
+	provider := testcp.EmptyProvider(client.Bootstrap(ctx))
+	defer provider.Release()
+
+	// e1 is a pipelined promise, e2 is read from the results.
+	f1, release1 := provider.GetEmpty(ctx, nil)
+	defer release1()
+	e1 := f1.Empty()
+	f2, release2 := provider.GetEmpty(ctx, nil)
+	defer release2()
+	r2, err := f2.Struct()
+	require.NoError(t, err)
+	e2 := r2.Empty()
+
+	same, err := capnp.Client(e1).IsSameResolved(ctx, capnp.Client(e2))
+	require.NoError(t, err)
+	assert.True(t, same, "both calls return the same remote capability")
+
+	same, err = capnp.Client(e1).IsSameResolved(ctx, capnp.Client(provider))
+	require.NoError(t, err)
+	assert.False(t, same, "result is not the same as the bootstrap capability")
+
+	same, err = capnp.Client(e1).IsSameResolved(ctx, capnp.Client(empty))
+	require.NoError(t, err)
+	assert.False(t, same, "import is not the same as the local capability it proxies")
+}
+
+// sameEmptyProvider returns the same capability from every call.
+type sameEmptyProvider struct {
+	empty testcp.Empty
+}
+
+func (p sameEmptyProvider) GetEmpty(ctx context.Context, call testcp.EmptyProvider_getEmpty) error {
+	results, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return results.SetEmpty(p.empty.AddRef())
+}
@@ -3,11 +3,13 @@ package rpc
 import (
 	"context"
 	"errors"
+	"time"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/internal/str"
 	"capnproto.org/go/capnp/v3/internal/syncutil"
 	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+	"capnproto.org/go/capnp/v3/util/deferred"
 )
 
 // An importID is an index into the imports table.
@@ -50,6 +52,15 @@ type impent struct {
 	// CapDescriptor_Which_senderPromise), and when a resolve message
 	// arrives we should use this to fulfill the promise locally.
 	resolver capnp.Resolver[capnp.Client]
+
+	// resolved is set once a resolve message has been received for the
+	// promise, so that redundant ones are ignored.
+	resolved bool
+
+	// created and lastCall track the import's lifetime; see
+	// Conn.DebugSnapshot.
+	created  time.Time
+	lastCall time.Time
 }
 
 // addImport returns a client that represents the given import,
@@ -58,7 +69,7 @@ type impent struct {
 //
 // The caller must be holding onto c.mu.
 func (c *lockedConn) addImport(id importID, isPromise bool) capnp.Client {
-	if ent := c.lk.imports[id]; ent != nil {
+	if ent := c.lk.imports.get(id); ent != nil {
 		ent.wireRefs++
 		client, ok := ent.wc.AddRef()
 		if !ok {
@@ -85,11 +96,12 @@ func (c *lockedConn) addImport(id importID, isPromise bool) capnp.Client {
 	} else {
 		client = capnp.NewClient(hook)
 	}
-	c.lk.imports[id] = &impent{
+	c.lk.imports.set(id, &impent{
 		wc:       client.WeakRef(),
 		wireRefs: 1,
 		resolver: resolver,
-	}
+		created:  c.clock.Now(),
+	})
 	return client
 }
 
@@ -105,24 +117,36 @@ func (ic *importClient) String() string {
 }
 
 func (ic *importClient) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	if err := ic.c.admitCall(ctx); err != nil {
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+	if err := ic.c.admitQuestion(ctx); err != nil {
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+	dq := &deferred.Queue{}
+	defer dq.Run()
 	return withLockedConn2(ic.c, func(c *lockedConn) (*capnp.Answer, capnp.ReleaseFunc) {
 		if !c.startTask() {
+			ic.c.freeQuestionSlot()
 			return capnp.ErrorAnswer(s.Method, ExcClosed), func() {}
 		}
 		defer c.tasks.Done()
-		ent := c.lk.imports[ic.id]
+		ent := c.lk.imports.get(ic.id)
 		if ent == nil || ic.generation != ent.generation {
+			ic.c.freeQuestionSlot()
 			return capnp.ErrorAnswer(s.Method, rpcerr.Disconnected(errors.New("send on closed import"))), func() {}
 		}
+		ent.lastCall = c.clock.Now()
 		q := c.newQuestion(s.Method)
+		q.holdsSlot = c.questionSlots != nil
 
 		// Send call message.
 		c.sendMessage(ctx, func(m rpccp.Message) error {
-			return c.newImportCallMessage(m, ic.id, q.id, s)
+			return c.newImportCallMessage(dq, m, ic.id, q.id, s)
 		}, func(err error) {
 			if err != nil {
-				syncutil.With(&ic.c.lk, func() {
-					ic.c.lk.questions[q.id] = nil
+				ic.c.withLocked(func(c *lockedConn) {
+					c.removeQuestion(q)
 				})
 				q.p.Reject(rpcerr.WrapFailed("send message", err))
 				syncutil.With(&ic.c.lk, func() {
@@ -148,7 +172,7 @@ func (ic *importClient) Send(ctx context.Context, s capnp.Send) (*capnp.Answer,
 }
 
 // newImportCallMessage builds a Call message targeted to an import.
-func (c *lockedConn) newImportCallMessage(msg rpccp.Message, imp importID, qid questionID, s capnp.Send) error {
+func (c *lockedConn) newImportCallMessage(dq *deferred.Queue, msg rpccp.Message, imp importID, qid questionID, s capnp.Send) error {
 	call, err := msg.NewCall()
 	if err != nil {
 		return rpcerr.WrapFailed("build call message", err)
@@ -180,7 +204,7 @@ func (c *lockedConn) newImportCallMessage(msg rpccp.Message, imp importID, qid q
 		return rpcerr.WrapFailed("place arguments", err)
 	}
 	// TODO(soon): save param refs
-	_, err = c.fillPayloadCapTable(payload)
+	_, err = c.fillPayloadCapTable(dq, payload)
 	if err != nil {
 		return rpcerr.Annotate(err, "build call message")
 	}
@@ -236,6 +260,18 @@ func (ic *importClient) Brand() capnp.Brand {
 	return capnp.Brand{Value: ic}
 }
 
+// importIdentity identifies an import on the wire.
+type importIdentity struct {
+	c  *Conn
+	id importID
+}
+
+// CapIdentity implements capnp.CapIdentifier.  Hooks for the same import
+// on the same Conn refer to the same remote capability.
+func (ic *importClient) CapIdentity() any {
+	return importIdentity{c: ic.c, id: ic.id}
+}
+
 func (ic *importClient) Shutdown() {
 	ic.c.withLocked(func(c *lockedConn) {
 		if !c.startTask() {
@@ -243,13 +279,13 @@ func (ic *importClient) Shutdown() {
 		}
 		defer c.tasks.Done()
 
-		ent := c.lk.imports[ic.id]
+		ent := c.lk.imports.get(ic.id)
 		if ic.generation != ent.generation {
 			// A new reference was added concurrently with the Shutdown.  See
 			// impent.generation documentation for an explanation.
 			return
 		}
-		delete(ic.c.lk.imports, ic.id)
+		ic.c.lk.imports.remove(ic.id)
 		c.sendMessage(c.bgctx, func(msg rpccp.Message) error {
 			rel, err := msg.NewRelease()
 			if err == nil {
@@ -0,0 +1,44 @@
+package rpc
+
+import "capnproto.org/go/capnp/v3"
+
+// maxInlineSize is the largest results struct, in bytes, that is
+// copied out of its Return message; see inlinesResults.
+const maxInlineSize = 64
+
+// inlinesResults reports whether the results of calls to m are small
+// and data-only according to the method's schema, if it is registered.
+// Such results are copied into a pooled message when they arrive, so
+// that the Return message, whose arena may be much larger, is released
+// right away instead of once the caller releases the answer, which may
+// be long-lived.
+func inlinesResults(m capnp.Method) bool {
+	info, ok := capnp.LookupMethod(m.InterfaceID, m.MethodID)
+	return ok && info.ResultsSize.PointerCount == 0 && info.ResultsSize.DataSize <= maxInlineSize
+}
+
+// inlineResults copies content, the results of q, into a message from
+// the pool of single-segment arenas, if q's method inlines its results,
+// and returns the copy and a function that releases it.  It reports
+// false if content has pointers or is too large, which may happen if
+// the remote vat uses a newer version of the schema.
+func (q *question) inlineResults(content capnp.Ptr) (capnp.Ptr, capnp.ReleaseFunc, bool) {
+	if !q.inline {
+		return capnp.Ptr{}, nil, false
+	}
+	sz := content.Struct().Size()
+	if !content.IsValid() || sz.PointerCount != 0 || sz.DataSize > maxInlineSize {
+		return capnp.Ptr{}, nil, false
+	}
+	msg, _ := capnp.NewSingleSegmentMessage(nil)
+	if err := msg.SetRoot(content); err != nil {
+		msg.Release()
+		return capnp.Ptr{}, nil, false
+	}
+	p, err := msg.Root()
+	if err != nil {
+		msg.Release()
+		return capnp.Ptr{}, nil, false
+	}
+	return p, msg.Release, true
+}
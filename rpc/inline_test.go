@@ -0,0 +1,40 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestInlineResults(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer pp.Release()
+
+	// PingPong.echoNum returns a single integer, so its results are
+	// copied out of the Return message into a message of their own.
+	ans, release := echoNum(ctx, pp, 42)
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), res.N())
+
+	root, err := res.Message().Root()
+	require.NoError(t, err)
+	assert.Equal(t, capnp.ObjectSize{DataSize: 8}, root.Struct().Size(),
+		"results should be the root of their message")
+}
@@ -17,6 +17,10 @@ type Empty capnp.Client
 // Empty_TypeID is the unique identifier for the type Empty.
 const Empty_TypeID = 0xc8b14e937b2cb741
 
+// Empty_Fingerprint is a hash of the schema of Empty's methods,
+// used to detect peers built from a different version of the schema.
+const Empty_Fingerprint = 0x46f36688323296cc
+
 func (c Empty) WaitStreaming() error {
 	return capnp.Client(c).WaitStreaming()
 }
@@ -92,6 +96,11 @@ func (c Empty) GetFlowLimiter() fc.FlowLimiter {
 type Empty_Server interface {
 }
 
+// Empty_UnimplementedServer can be embedded in an implementation of
+// Empty_Server, including those of interfaces that extend Empty, to fail
+// the methods it does not define with an unimplemented exception.
+type Empty_UnimplementedServer struct{}
+
 // Empty_NewServer creates a new Server from an implementation of Empty_Server.
 func Empty_NewServer(s Empty_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -104,6 +113,19 @@ func Empty_ServerToClient(s Empty_Server) Empty {
 	return Empty(capnp.NewClient(Empty_NewServer(s)))
 }
 
+// Empty_NewServerWithOptions is like Empty_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func Empty_NewServerWithOptions(s Empty_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(Empty_Methods(nil, s), s, c, opts)
+}
+
+// Empty_ServerToClientWithOptions is like Empty_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func Empty_ServerToClientWithOptions(s Empty_Server, opts *server.Options) Empty {
+	return Empty(capnp.NewClient(Empty_NewServerWithOptions(s, opts)))
+}
+
 // Empty_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func Empty_Methods(methods []server.Method, s Empty_Server) []server.Method {
@@ -117,17 +139,25 @@ func Empty_Methods(methods []server.Method, s Empty_Server) []server.Method {
 // Empty_List is a list of Empty.
 type Empty_List = capnp.CapList[Empty]
 
-// NewEmpty creates a new list of Empty.
+// NewEmpty_List creates a new list of Empty.
 func NewEmpty_List(s *capnp.Segment, sz int32) (Empty_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[Empty](l), err
 }
 
+func init() {
+	capnp.RegisterFingerprint(Empty_TypeID, Empty_Fingerprint)
+}
+
 type EmptyProvider capnp.Client
 
 // EmptyProvider_TypeID is the unique identifier for the type EmptyProvider.
 const EmptyProvider_TypeID = 0xea38d4d6dca1e80e
 
+// EmptyProvider_Fingerprint is a hash of the schema of EmptyProvider's methods,
+// used to detect peers built from a different version of the schema.
+const EmptyProvider_Fingerprint = 0x033a3bf05be3cee9
+
 func (c EmptyProvider) GetEmpty(ctx context.Context, params func(EmptyProvider_getEmpty_Params) error) (EmptyProvider_getEmpty_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -224,6 +254,16 @@ type EmptyProvider_Server interface {
 	GetEmpty(context.Context, EmptyProvider_getEmpty) error
 }
 
+// EmptyProvider_UnimplementedServer can be embedded in an implementation of
+// EmptyProvider_Server, including those of interfaces that extend EmptyProvider, to fail
+// the methods it does not define with an unimplemented exception.
+type EmptyProvider_UnimplementedServer struct{}
+
+// GetEmpty returns an unimplemented exception.
+func (EmptyProvider_UnimplementedServer) GetEmpty(context.Context, EmptyProvider_getEmpty) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:EmptyProvider.getEmpty")
+}
+
 // EmptyProvider_NewServer creates a new Server from an implementation of EmptyProvider_Server.
 func EmptyProvider_NewServer(s EmptyProvider_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -236,6 +276,19 @@ func EmptyProvider_ServerToClient(s EmptyProvider_Server) EmptyProvider {
 	return EmptyProvider(capnp.NewClient(EmptyProvider_NewServer(s)))
 }
 
+// EmptyProvider_NewServerWithOptions is like EmptyProvider_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func EmptyProvider_NewServerWithOptions(s EmptyProvider_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(EmptyProvider_Methods(nil, s), s, c, opts)
+}
+
+// EmptyProvider_ServerToClientWithOptions is like EmptyProvider_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func EmptyProvider_ServerToClientWithOptions(s EmptyProvider_Server, opts *server.Options) EmptyProvider {
+	return EmptyProvider(capnp.NewClient(EmptyProvider_NewServerWithOptions(s, opts)))
+}
+
 // EmptyProvider_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func EmptyProvider_Methods(methods []server.Method, s EmptyProvider_Server) []server.Method {
@@ -278,12 +331,29 @@ func (c EmptyProvider_getEmpty) AllocResults() (EmptyProvider_getEmpty_Results,
 // EmptyProvider_List is a list of EmptyProvider.
 type EmptyProvider_List = capnp.CapList[EmptyProvider]
 
-// NewEmptyProvider creates a new list of EmptyProvider.
+// NewEmptyProvider_List creates a new list of EmptyProvider.
 func NewEmptyProvider_List(s *capnp.Segment, sz int32) (EmptyProvider_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[EmptyProvider](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xea38d4d6dca1e80e,
+				MethodID:      0,
+				InterfaceName: "test.capnp:EmptyProvider",
+				MethodName:    "getEmpty",
+			},
+			ParamsTypeID:  0x9a27082d77b8c289,
+			ResultsTypeID: 0x93281cc60d6060cd,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+		},
+	)
+	capnp.RegisterFingerprint(EmptyProvider_TypeID, EmptyProvider_Fingerprint)
+}
+
 type EmptyProvider_getEmpty_Params capnp.Struct
 
 // EmptyProvider_getEmpty_Params_TypeID is the unique identifier for the type EmptyProvider_getEmpty_Params.
@@ -439,6 +509,10 @@ type PingPong capnp.Client
 // PingPong_TypeID is the unique identifier for the type PingPong.
 const PingPong_TypeID = 0xf004c474c2f8ee7a
 
+// PingPong_Fingerprint is a hash of the schema of PingPong's methods,
+// used to detect peers built from a different version of the schema.
+const PingPong_Fingerprint = 0x41205252c4fef17e
+
 func (c PingPong) EchoNum(ctx context.Context, params func(PingPong_echoNum_Params) error) (PingPong_echoNum_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -535,6 +609,16 @@ type PingPong_Server interface {
 	EchoNum(context.Context, PingPong_echoNum) error
 }
 
+// PingPong_UnimplementedServer can be embedded in an implementation of
+// PingPong_Server, including those of interfaces that extend PingPong, to fail
+// the methods it does not define with an unimplemented exception.
+type PingPong_UnimplementedServer struct{}
+
+// EchoNum returns an unimplemented exception.
+func (PingPong_UnimplementedServer) EchoNum(context.Context, PingPong_echoNum) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:PingPong.echoNum")
+}
+
 // PingPong_NewServer creates a new Server from an implementation of PingPong_Server.
 func PingPong_NewServer(s PingPong_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -547,6 +631,19 @@ func PingPong_ServerToClient(s PingPong_Server) PingPong {
 	return PingPong(capnp.NewClient(PingPong_NewServer(s)))
 }
 
+// PingPong_NewServerWithOptions is like PingPong_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func PingPong_NewServerWithOptions(s PingPong_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(PingPong_Methods(nil, s), s, c, opts)
+}
+
+// PingPong_ServerToClientWithOptions is like PingPong_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func PingPong_ServerToClientWithOptions(s PingPong_Server, opts *server.Options) PingPong {
+	return PingPong(capnp.NewClient(PingPong_NewServerWithOptions(s, opts)))
+}
+
 // PingPong_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func PingPong_Methods(methods []server.Method, s PingPong_Server) []server.Method {
@@ -589,12 +686,29 @@ func (c PingPong_echoNum) AllocResults() (PingPong_echoNum_Results, error) {
 // PingPong_List is a list of PingPong.
 type PingPong_List = capnp.CapList[PingPong]
 
-// NewPingPong creates a new list of PingPong.
+// NewPingPong_List creates a new list of PingPong.
 func NewPingPong_List(s *capnp.Segment, sz int32) (PingPong_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[PingPong](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xf004c474c2f8ee7a,
+				MethodID:      0,
+				InterfaceName: "test.capnp:PingPong",
+				MethodName:    "echoNum",
+			},
+			ParamsTypeID:  0xd797e0a99edf0921,
+			ResultsTypeID: 0x85ddfd96db252600,
+			ResultsSize:   capnp.ObjectSize{DataSize: 8, PointerCount: 0},
+		},
+	)
+	capnp.RegisterFingerprint(PingPong_TypeID, PingPong_Fingerprint)
+}
+
 type PingPong_echoNum_Params capnp.Struct
 
 // PingPong_echoNum_Params_TypeID is the unique identifier for the type PingPong_echoNum_Params.
@@ -744,6 +858,10 @@ type StreamTest capnp.Client
 // StreamTest_TypeID is the unique identifier for the type StreamTest.
 const StreamTest_TypeID = 0xbb3ca85b01eea465
 
+// StreamTest_Fingerprint is a hash of the schema of StreamTest's methods,
+// used to detect peers built from a different version of the schema.
+const StreamTest_Fingerprint = 0x389624ba437551be
+
 func (c StreamTest) Push(ctx context.Context, params func(StreamTest_push_Params) error) error {
 	s := capnp.Send{
 		Method: capnp.Method{
@@ -838,6 +956,16 @@ type StreamTest_Server interface {
 	Push(context.Context, StreamTest_push) error
 }
 
+// StreamTest_UnimplementedServer can be embedded in an implementation of
+// StreamTest_Server, including those of interfaces that extend StreamTest, to fail
+// the methods it does not define with an unimplemented exception.
+type StreamTest_UnimplementedServer struct{}
+
+// Push returns an unimplemented exception.
+func (StreamTest_UnimplementedServer) Push(context.Context, StreamTest_push) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:StreamTest.push")
+}
+
 // StreamTest_NewServer creates a new Server from an implementation of StreamTest_Server.
 func StreamTest_NewServer(s StreamTest_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -850,6 +978,19 @@ func StreamTest_ServerToClient(s StreamTest_Server) StreamTest {
 	return StreamTest(capnp.NewClient(StreamTest_NewServer(s)))
 }
 
+// StreamTest_NewServerWithOptions is like StreamTest_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func StreamTest_NewServerWithOptions(s StreamTest_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(StreamTest_Methods(nil, s), s, c, opts)
+}
+
+// StreamTest_ServerToClientWithOptions is like StreamTest_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func StreamTest_ServerToClientWithOptions(s StreamTest_Server, opts *server.Options) StreamTest {
+	return StreamTest(capnp.NewClient(StreamTest_NewServerWithOptions(s, opts)))
+}
+
 // StreamTest_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func StreamTest_Methods(methods []server.Method, s StreamTest_Server) []server.Method {
@@ -892,12 +1033,29 @@ func (c StreamTest_push) AllocResults() (stream.StreamResult, error) {
 // StreamTest_List is a list of StreamTest.
 type StreamTest_List = capnp.CapList[StreamTest]
 
-// NewStreamTest creates a new list of StreamTest.
+// NewStreamTest_List creates a new list of StreamTest.
 func NewStreamTest_List(s *capnp.Segment, sz int32) (StreamTest_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[StreamTest](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xbb3ca85b01eea465,
+				MethodID:      0,
+				InterfaceName: "test.capnp:StreamTest",
+				MethodName:    "push",
+			},
+			ParamsTypeID:  0xf838dca6c8721bdb,
+			ResultsTypeID: 0x995f9a3377c0b16e,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 0},
+		},
+	)
+	capnp.RegisterFingerprint(StreamTest_TypeID, StreamTest_Fingerprint)
+}
+
 type StreamTest_push_Params capnp.Struct
 
 // StreamTest_push_Params_TypeID is the unique identifier for the type StreamTest_push_Params.
@@ -980,6 +1138,10 @@ type CapArgsTest capnp.Client
 // CapArgsTest_TypeID is the unique identifier for the type CapArgsTest.
 const CapArgsTest_TypeID = 0xb86bce7f916a10cc
 
+// CapArgsTest_Fingerprint is a hash of the schema of CapArgsTest's methods,
+// used to detect peers built from a different version of the schema.
+const CapArgsTest_Fingerprint = 0x2a4bde079fd9f0a8
+
 func (c CapArgsTest) Call(ctx context.Context, params func(CapArgsTest_call_Params) error) (CapArgsTest_call_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -1098,6 +1260,21 @@ type CapArgsTest_Server interface {
 	Self(context.Context, CapArgsTest_self) error
 }
 
+// CapArgsTest_UnimplementedServer can be embedded in an implementation of
+// CapArgsTest_Server, including those of interfaces that extend CapArgsTest, to fail
+// the methods it does not define with an unimplemented exception.
+type CapArgsTest_UnimplementedServer struct{}
+
+// Call returns an unimplemented exception.
+func (CapArgsTest_UnimplementedServer) Call(context.Context, CapArgsTest_call) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:CapArgsTest.call")
+}
+
+// Self returns an unimplemented exception.
+func (CapArgsTest_UnimplementedServer) Self(context.Context, CapArgsTest_self) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:CapArgsTest.self")
+}
+
 // CapArgsTest_NewServer creates a new Server from an implementation of CapArgsTest_Server.
 func CapArgsTest_NewServer(s CapArgsTest_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -1110,6 +1287,19 @@ func CapArgsTest_ServerToClient(s CapArgsTest_Server) CapArgsTest {
 	return CapArgsTest(capnp.NewClient(CapArgsTest_NewServer(s)))
 }
 
+// CapArgsTest_NewServerWithOptions is like CapArgsTest_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func CapArgsTest_NewServerWithOptions(s CapArgsTest_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(CapArgsTest_Methods(nil, s), s, c, opts)
+}
+
+// CapArgsTest_ServerToClientWithOptions is like CapArgsTest_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func CapArgsTest_ServerToClientWithOptions(s CapArgsTest_Server, opts *server.Options) CapArgsTest {
+	return CapArgsTest(capnp.NewClient(CapArgsTest_NewServerWithOptions(s, opts)))
+}
+
 // CapArgsTest_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func CapArgsTest_Methods(methods []server.Method, s CapArgsTest_Server) []server.Method {
@@ -1181,12 +1371,40 @@ func (c CapArgsTest_self) AllocResults() (CapArgsTest_self_Results, error) {
 // CapArgsTest_List is a list of CapArgsTest.
 type CapArgsTest_List = capnp.CapList[CapArgsTest]
 
-// NewCapArgsTest creates a new list of CapArgsTest.
+// NewCapArgsTest_List creates a new list of CapArgsTest.
 func NewCapArgsTest_List(s *capnp.Segment, sz int32) (CapArgsTest_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[CapArgsTest](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xb86bce7f916a10cc,
+				MethodID:      0,
+				InterfaceName: "test.capnp:CapArgsTest",
+				MethodName:    "call",
+			},
+			ParamsTypeID:  0x80087e4e698768a2,
+			ResultsTypeID: 0x96fbc50dc2f0200d,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 0},
+		},
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xb86bce7f916a10cc,
+				MethodID:      1,
+				InterfaceName: "test.capnp:CapArgsTest",
+				MethodName:    "self",
+			},
+			ParamsTypeID:  0xe2553e5a663abb7d,
+			ResultsTypeID: 0x9746cc05cbff1132,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+		},
+	)
+	capnp.RegisterFingerprint(CapArgsTest_TypeID, CapArgsTest_Fingerprint)
+}
+
 type CapArgsTest_call_Params capnp.Struct
 
 // CapArgsTest_call_Params_TypeID is the unique identifier for the type CapArgsTest_call_Params.
@@ -1492,6 +1710,10 @@ type PingPongProvider capnp.Client
 // PingPongProvider_TypeID is the unique identifier for the type PingPongProvider.
 const PingPongProvider_TypeID = 0x95b6142577e93239
 
+// PingPongProvider_Fingerprint is a hash of the schema of PingPongProvider's methods,
+// used to detect peers built from a different version of the schema.
+const PingPongProvider_Fingerprint = 0x55ef4e3faccb680a
+
 func (c PingPongProvider) PingPong(ctx context.Context, params func(PingPongProvider_pingPong_Params) error) (PingPongProvider_pingPong_Results_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -1588,6 +1810,16 @@ type PingPongProvider_Server interface {
 	PingPong(context.Context, PingPongProvider_pingPong) error
 }
 
+// PingPongProvider_UnimplementedServer can be embedded in an implementation of
+// PingPongProvider_Server, including those of interfaces that extend PingPongProvider, to fail
+// the methods it does not define with an unimplemented exception.
+type PingPongProvider_UnimplementedServer struct{}
+
+// PingPong returns an unimplemented exception.
+func (PingPongProvider_UnimplementedServer) PingPong(context.Context, PingPongProvider_pingPong) error {
+	return capnp.Unimplemented("unimplemented method test.capnp:PingPongProvider.pingPong")
+}
+
 // PingPongProvider_NewServer creates a new Server from an implementation of PingPongProvider_Server.
 func PingPongProvider_NewServer(s PingPongProvider_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -1600,6 +1832,19 @@ func PingPongProvider_ServerToClient(s PingPongProvider_Server) PingPongProvider
 	return PingPongProvider(capnp.NewClient(PingPongProvider_NewServer(s)))
 }
 
+// PingPongProvider_NewServerWithOptions is like PingPongProvider_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func PingPongProvider_NewServerWithOptions(s PingPongProvider_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(PingPongProvider_Methods(nil, s), s, c, opts)
+}
+
+// PingPongProvider_ServerToClientWithOptions is like PingPongProvider_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func PingPongProvider_ServerToClientWithOptions(s PingPongProvider_Server, opts *server.Options) PingPongProvider {
+	return PingPongProvider(capnp.NewClient(PingPongProvider_NewServerWithOptions(s, opts)))
+}
+
 // PingPongProvider_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func PingPongProvider_Methods(methods []server.Method, s PingPongProvider_Server) []server.Method {
@@ -1642,12 +1887,29 @@ func (c PingPongProvider_pingPong) AllocResults() (PingPongProvider_pingPong_Res
 // PingPongProvider_List is a list of PingPongProvider.
 type PingPongProvider_List = capnp.CapList[PingPongProvider]
 
-// NewPingPongProvider creates a new list of PingPongProvider.
+// NewPingPongProvider_List creates a new list of PingPongProvider.
 func NewPingPongProvider_List(s *capnp.Segment, sz int32) (PingPongProvider_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[PingPongProvider](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0x95b6142577e93239,
+				MethodID:      0,
+				InterfaceName: "test.capnp:PingPongProvider",
+				MethodName:    "pingPong",
+			},
+			ParamsTypeID:  0xd4e835c17f1ef32c,
+			ResultsTypeID: 0xf269473b6db8d0eb,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+		},
+	)
+	capnp.RegisterFingerprint(PingPongProvider_TypeID, PingPongProvider_Fingerprint)
+}
+
 type PingPongProvider_pingPong_Params capnp.Struct
 
 // PingPongProvider_pingPong_Params_TypeID is the unique identifier for the type PingPongProvider_pingPong_Params.
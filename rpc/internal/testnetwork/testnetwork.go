@@ -72,7 +72,7 @@ func (j *Joiner) getAcceptQueue(id PeerID) spsc.Queue[PeerID] {
 }
 
 func (n network) LocalID() rpc.PeerID {
-	return rpc.PeerID{n.myID}
+	return rpc.PeerID{Value: n.myID}
 }
 
 func (n network) Dial(dst rpc.PeerID, opts *rpc.Options) (*rpc.Conn, error) {
@@ -120,7 +120,7 @@ func (n network) Accept(ctx context.Context, opts *rpc.Options) (*rpc.Conn, erro
 		return nil, err
 	}
 	opts.Network = n
-	opts.RemotePeerID = rpc.PeerID{incoming}
+	opts.RemotePeerID = rpc.PeerID{Value: incoming}
 	n.global.mu.Lock()
 	defer n.global.mu.Unlock()
 	edge := edge{
@@ -172,7 +172,7 @@ func (n network) DialIntroduced(capID rpc.ThirdPartyCapID, introducedBy *rpc.Con
 	pid.SetPeerId(uint64(introducedBy.RemotePeerID().Value.(PeerID)))
 	pid.SetNonce(cid.Nonce())
 
-	conn, err := n.Dial(rpc.PeerID{PeerID(cid.PeerId())}, nil)
+	conn, err := n.Dial(rpc.PeerID{Value: PeerID(cid.PeerId())}, nil)
 	return conn, rpc.ProvisionID(pid.ToPtr()), err
 }
 func (n network) AcceptIntroduced(recipientID rpc.RecipientID, introducedBy *rpc.Conn) (*rpc.Conn, error) {
@@ -18,6 +18,7 @@ import (
 	"capnproto.org/go/capnp/v3/pogs"
 	"capnproto.org/go/capnp/v3/rpc"
 	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/rpc/rpctest"
 	"capnproto.org/go/capnp/v3/rpc/transport"
 	"capnproto.org/go/capnp/v3/schemas"
 	"capnproto.org/go/capnp/v3/server"
@@ -743,7 +744,7 @@ func TestSendBootstrapPipelineCall(t *testing.T) {
 			if rmsg.Call.Target.PromisedAnswer.QuestionID != bootstrapQID {
 				t.Errorf("call.target.promisedAnswer.questionID = %d; want %d", rmsg.Call.Target.PromisedAnswer.QuestionID, bootstrapQID)
 			}
-			if !rmsg.Call.Target.PromisedAnswer.transformEquals() {
+			if !rmsg.Call.Target.PromisedAnswer.TransformEquals() {
 				t.Errorf("call.target.promisedAnswer.transform = %v; want []", rmsg.Call.Target.PromisedAnswer.Transform)
 			}
 		}
@@ -867,6 +868,54 @@ func TestSendBootstrapPipelineCall(t *testing.T) {
 	}
 }
 
+// TestSendPriorityPipelineCall checks that a high priority call on a
+// promised answer is not sent ahead of the call that creates the
+// question it targets.
+func TestSendPriorityPipelineCall(t *testing.T) {
+	t.Parallel()
+
+	// An unbuffered pipe keeps the send goroutine blocked on the
+	// bootstrap message, so the calls below are queued together.
+	left, right := transport.NewPipe(0)
+	p1, p2 := rpc.NewTransport(left), rpc.NewTransport(right)
+
+	conn := rpc.NewConn(p1, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+
+	ctx := context.Background()
+	method := capnp.Method{InterfaceID: interfaceID, MethodID: methodID}
+
+	client := conn.Bootstrap(ctx)
+	defer client.Release()
+	ans, release := client.SendCall(ctx, capnp.Send{Method: method})
+	defer release()
+	_, releaseHigh := ans.PipelineSend(rpc.WithPriority(ctx, rpc.PriorityHigh), nil, capnp.Send{Method: method})
+	defer releaseHigh()
+	// The calls are never answered, so shut down before releasing them.
+	defer finishTest(t, conn, p2)
+
+	rmsg, release, err := recvMessage(ctx, p2)
+	require.NoError(t, err)
+	defer release()
+	require.Equal(t, rpccp.Message_Which_bootstrap, rmsg.Which)
+
+	rmsg, release, err = recvMessage(ctx, p2)
+	require.NoError(t, err)
+	defer release()
+	require.Equal(t, rpccp.Message_Which_call, rmsg.Which)
+	require.Equal(t, rpccp.MessageTarget_Which_promisedAnswer, rmsg.Call.Target.Which)
+	qid := rmsg.Call.QuestionID
+
+	rmsg, release, err = recvMessage(ctx, p2)
+	require.NoError(t, err)
+	defer release()
+	require.Equal(t, rpccp.Message_Which_call, rmsg.Which)
+	require.Equal(t, rpccp.MessageTarget_Which_promisedAnswer, rmsg.Call.Target.Which)
+	assert.Equal(t, qid, rmsg.Call.Target.PromisedAnswer.QuestionID,
+		"high priority call sent before the call creating its target")
+}
+
 // TestRecvBootstrapError does not set Options.BootstrapClient and
 // receives a Bootstrap message.  It checks that an exception was sent
 // back.  Level 0 requirement.
@@ -2012,6 +2061,15 @@ func (pingPonger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) err
 }
 
 // finishTest drains both sides of a pipe and reports any errors to t.
+// newTestConn returns a Conn created with opts, and the transport for
+// the other end of its connection, for tests that play the remote vat
+// by hand.  Pass both to finishTest to shut them down.
+func newTestConn(opts *rpc.Options) (*rpc.Conn, rpc.Transport) {
+	left, right := transport.NewPipe(1)
+	p1, p2 := rpc.NewTransport(left), rpc.NewTransport(right)
+	return rpc.NewConn(p1, opts), p2
+}
+
 func finishTest(t errorfer, conn *rpc.Conn, p2 rpc.Transport) {
 	ctx, cancel := context.WithCancel(context.Background())
 	drained := make(chan struct{})
@@ -2072,172 +2130,32 @@ func (f shutdownFunc) Shutdown() {
 	f()
 }
 
-type rpcMessage struct {
-	Which         rpccp.Message_Which
-	Unimplemented *rpcMessage
-	Abort         *rpcException
-	Bootstrap     *rpcBootstrap
-	Call          *rpcCall
-	Return        *rpcReturn
-	Finish        *rpcFinish
-	Resolve       *rpcResolve
-	Release       *rpcRelease
-	Disembargo    *rpcDisembargo
-}
-
-func sendMessage(ctx context.Context, t rpc.Transport, msg *rpcMessage) error {
-	outMsg, err := t.NewMessage()
-	if err != nil {
-		return fmt.Errorf("send message: %v", err)
-	}
-	defer outMsg.Release()
-	if err := pogs.Insert(rpccp.Message_TypeID, capnp.Struct(outMsg.Message()), msg); err != nil {
-		return fmt.Errorf("send message: %v", err)
-	}
-	if err := outMsg.Send(); err != nil {
-		return fmt.Errorf("send message: %v", err)
-	}
-	return nil
-}
-
-func recvMessage(ctx context.Context, t rpc.Transport) (*rpcMessage, capnp.ReleaseFunc, error) {
-	inMsg, err := t.RecvMessage()
-	if err != nil {
-		return nil, nil, err
-	}
-	r := new(rpcMessage)
-	if err := pogs.Extract(r, rpccp.Message_TypeID, capnp.Struct(inMsg.Message())); err != nil {
-		inMsg.Release()
-		return nil, nil, fmt.Errorf("extract RPC message: %v", err)
-	}
-	if r.Which == rpccp.Message_Which_abort ||
-		r.Which == rpccp.Message_Which_bootstrap ||
-		r.Which == rpccp.Message_Which_finish ||
-		r.Which == rpccp.Message_Which_resolve ||
-		r.Which == rpccp.Message_Which_release ||
-		r.Which == rpccp.Message_Which_disembargo {
-		// These messages are guaranteed to not contain pointers back to
-		// the original message, so we can release them early.
-		inMsg.Release()
-		return r, func() {}, nil
-	}
-	return r, inMsg.Release, nil
-}
-
-type rpcException struct {
-	Reason string
-	Type   rpccp.Exception_Type
-}
-
-type rpcBootstrap struct {
-	QuestionID uint32 `capnp:"questionId"`
-}
-
-type rpcCall struct {
-	QuestionID              uint32 `capnp:"questionId"`
-	Target                  rpcMessageTarget
-	InterfaceID             uint64 `capnp:"interfaceId"`
-	MethodID                uint16 `capnp:"methodId"`
-	AllowThirdPartyTailCall bool
-	Params                  rpcPayload
-	SendResultsTo           rpcCallSendResultsTo
-}
-
-type rpcCallSendResultsTo struct {
-	Which rpccp.Call_sendResultsTo_Which
-}
-
-type rpcReturn struct {
-	AnswerID         uint32 `capnp:"answerId"`
-	ReleaseParamCaps bool
-
-	Which                 rpccp.Return_Which
-	Results               *rpcPayload
-	Exception             *rpcException
-	TakeFromOtherQuestion uint32
-}
-
-type rpcFinish struct {
-	QuestionID        uint32 `capnp:"questionId"`
-	ReleaseResultCaps bool
-}
-
-type rpcMessageTarget struct {
-	Which          rpccp.MessageTarget_Which
-	ImportedCap    uint32
-	PromisedAnswer *rpcPromisedAnswer
-}
-
-type rpcPayload struct {
-	Content  capnp.Ptr
-	CapTable []rpcCapDescriptor
-}
-
-type rpcCapDescriptor struct {
-	Which          rpccp.CapDescriptor_Which
-	SenderHosted   uint32
-	SenderPromise  uint32
-	ReceiverHosted uint32
-	ReceiverAnswer *rpcPromisedAnswer
-}
-
-type rpcPromisedAnswer struct {
-	QuestionID uint32 `capnp:"questionId"`
-	Transform  []rpcPromisedAnswerOp
-}
-
-func (pa *rpcPromisedAnswer) transformEquals(path ...uint16) bool {
-	for _, op := range pa.Transform {
-		switch op.Which {
-		case rpccp.PromisedAnswer_Op_Which_noop:
-			// Skip.
-		case rpccp.PromisedAnswer_Op_Which_getPointerField:
-			if len(path) == 0 || path[0] != op.GetPointerField {
-				return false
-			}
-			path = path[1:]
-		default:
-			return false
-		}
-	}
-	return len(path) == 0
-}
-
-type rpcPromisedAnswerOp struct {
-	Which           rpccp.PromisedAnswer_Op_Which
-	GetPointerField uint16
-}
+// Shorter names for the protocol message helpers, which are used
+// throughout the tests.
+type (
+	rpcMessage           = rpctest.Message
+	rpcException         = rpctest.Exception
+	rpcBootstrap         = rpctest.Bootstrap
+	rpcCall              = rpctest.Call
+	rpcCallSendResultsTo = rpctest.CallSendResultsTo
+	rpcReturn            = rpctest.Return
+	rpcFinish            = rpctest.Finish
+	rpcResolve           = rpctest.Resolve
+	rpcRelease           = rpctest.Release
+	rpcDisembargo        = rpctest.Disembargo
+	rpcDisembargoContext = rpctest.DisembargoContext
+	rpcMessageTarget     = rpctest.MessageTarget
+	rpcPayload           = rpctest.Payload
+	rpcCapDescriptor     = rpctest.CapDescriptor
+	rpcPromisedAnswer    = rpctest.PromisedAnswer
+	rpcPromisedAnswerOp  = rpctest.PromisedAnswerOp
+)
 
-func recvBootstrapReturn(ctx context.Context, t rpc.Transport, qid uint32) (uint32, error) {
-	rmsg, release, err := recvMessage(ctx, t)
-	if err != nil {
-		return 0, fmt.Errorf("receive bootstrap: %v", err)
-	}
-	defer release()
-	if rmsg.Which != rpccp.Message_Which_return {
-		return 0, fmt.Errorf("received %v message; want return (for bootstrap)", rmsg.Which)
-	}
-	if rmsg.Return.AnswerID != qid {
-		return 0, fmt.Errorf("received return for answer %d; want %d (bootstrap)", rmsg.Return.AnswerID, qid)
-	}
-	if rmsg.Return.Which != rpccp.Return_Which_results {
-		return 0, fmt.Errorf("bootstrap return which = %v; want results", rmsg.Return.Which)
-	}
-	iface := rmsg.Return.Results.Content.Interface()
-	if !iface.IsValid() {
-		return 0, errors.New("parse bootstrap return: content is not an interface pointer")
-	}
-	ctab := rmsg.Return.Results.CapTable
-	if iface.Capability() != 0 || len(ctab) != 1 {
-		// This is a bit more restrictive than necessary, but we don't need
-		// the flexibility.
-		return 0, fmt.Errorf("parse bootstrap return: capability index, table length = %d, %d; want 0, 1", iface.Capability(), len(ctab))
-	}
-	if ctab[0].Which != rpccp.CapDescriptor_Which_senderHosted {
-		return 0, fmt.Errorf("parse bootstrap return: received %v capability; want senderHosted", ctab[0].Which)
-	}
-	return ctab[0].SenderHosted, nil
-}
+var (
+	sendMessage         = rpctest.SendMessage
+	recvMessage         = rpctest.RecvMessage
+	recvBootstrapReturn = rpctest.RecvBootstrapReturn
+)
 
 func canceledContext(parent context.Context) context.Context {
 	ctx, cancel := context.WithCancel(parent)
@@ -225,7 +225,7 @@ func testSendDisembargo(t *testing.T, sendPrimeTo rpccp.Call_sendResultsTo_Which
 		if msg.Call.Target.PromisedAnswer.QuestionID != qidA {
 			t.Errorf("call.target.promisedAnswer.questionID = %d; want %d (call A)", msg.Call.Target.PromisedAnswer.QuestionID, qidA)
 		}
-		if !msg.Call.Target.PromisedAnswer.transformEquals(0) {
+		if !msg.Call.Target.PromisedAnswer.TransformEquals(0) {
 			want := []rpcPromisedAnswerOp{
 				{Which: rpccp.PromisedAnswer_Op_Which_getPointerField, GetPointerField: 0},
 			}
@@ -294,7 +294,7 @@ func testSendDisembargo(t *testing.T, sendPrimeTo rpccp.Call_sendResultsTo_Which
 		if msg.Disembargo.Target.PromisedAnswer.QuestionID != qidA {
 			t.Errorf("disembargo.target.promisedAnswer.questionId = %d; want %d (call A)", msg.Disembargo.Target.PromisedAnswer.QuestionID, qidA)
 		}
-		if !msg.Disembargo.Target.PromisedAnswer.transformEquals(0) {
+		if !msg.Disembargo.Target.PromisedAnswer.TransformEquals(0) {
 			want := []rpcPromisedAnswerOp{
 				{Which: rpccp.PromisedAnswer_Op_Which_getPointerField, GetPointerField: 0},
 			}
@@ -1031,27 +1031,3 @@ func TestIssue3(t *testing.T) {
 		}
 	}
 }
-
-type rpcResolve struct {
-	PromiseID uint32 `capnp:"promiseId"`
-	Which     rpccp.Resolve_Which
-	Cap       *rpcCapDescriptor
-	Exception *rpcException
-}
-
-type rpcRelease struct {
-	ID             uint32 `capnp:"id"`
-	ReferenceCount uint32
-}
-
-type rpcDisembargo struct {
-	Target  rpcMessageTarget
-	Context rpcDisembargoContext
-}
-
-type rpcDisembargoContext struct {
-	Which            rpccp.Disembargo_context_Which
-	SenderLoopback   uint32
-	ReceiverLoopback uint32
-	Provide          uint32
-}
@@ -0,0 +1,222 @@
+package rpc
+
+import (
+	"sort"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/syncutil"
+	"capnproto.org/go/capnp/v3/util/deferred"
+)
+
+// CapInfo describes an entry in a Conn's export or import table.
+type CapInfo struct {
+	// ID is the export or import ID on the wire.
+	ID uint32
+
+	// Refs is the number of references to the capability that have
+	// been sent (for exports) or received (for imports) and not yet
+	// released.
+	Refs int
+
+	// Created is when the entry was added to the table.
+	Created time.Time
+
+	// LastCall is when the capability last received (for exports) or
+	// sent (for imports) a call.  It is zero if there was no call.
+	LastCall time.Time
+
+	// Revoked is true if the export was released by an IdlePolicy.
+	// The entry stays in the table until the remote vat releases it,
+	// but calls to it fail with ExcExportIdle.
+	Revoked bool
+}
+
+// Idle returns how long the capability has gone without calls as of
+// now, counting from its creation if it was never called.
+func (ci CapInfo) Idle(now time.Time) time.Duration {
+	if ci.LastCall.IsZero() {
+		return now.Sub(ci.Created)
+	}
+	return now.Sub(ci.LastCall)
+}
+
+// A DebugSnapshot is a point-in-time view of a Conn's tables, intended
+// for debugging and monitoring.
+type DebugSnapshot struct {
+	// Questions and Answers are the number of outstanding calls made
+	// by this vat and by the remote vat, respectively.
+	Questions int
+	Answers   int
+
+	// RTT is the round-trip time measured by the latest successful
+	// call to Conn.Ping, or zero if there was none.
+	RTT time.Duration
+
+	// Queued describes the calls pipelined by the remote vat that are
+	// waiting for local calls to return, such as calls to a server
+	// method that has not returned yet.
+	Queued capnp.AnswerQueueStats
+
+	// Exports and Imports list the capabilities exported to and
+	// imported from the remote vat, ordered by ID.
+	Exports []CapInfo
+	Imports []CapInfo
+}
+
+// DebugSnapshot returns a snapshot of c's tables.  Long-lived exports
+// with an old LastCall are a common sign of capabilities that the
+// remote vat forgot to release.
+func (c *Conn) DebugSnapshot() DebugSnapshot {
+	return withLockedConn1(c, func(c *lockedConn) DebugSnapshot {
+		s := DebugSnapshot{RTT: time.Duration(c.rtt.Load())}
+		c.lk.questions.each(func(questionID, *question) { s.Questions++ })
+		var queues []*capnp.AnswerQueue
+		c.lk.answers.each(func(_ answerID, ans *ansent) {
+			s.Answers++
+			if aq := answerQueueOf(ans.pcall); aq != nil {
+				queues = append(queues, aq)
+			}
+		})
+		for _, aq := range queues {
+			st := aq.Stats()
+			s.Queued.Queues += st.Queues
+			s.Queued.Depth += st.Depth
+			if st.Oldest > s.Queued.Oldest {
+				s.Queued.Oldest = st.Oldest
+			}
+		}
+		c.lk.exports.each(func(id exportID, ent *expent) {
+			s.Exports = append(s.Exports, CapInfo{
+				ID:       uint32(id),
+				Refs:     int(ent.wireRefs),
+				Created:  ent.created,
+				LastCall: ent.lastCall,
+				Revoked:  ent.revoked,
+			})
+		})
+		c.lk.imports.each(func(id importID, ent *impent) {
+			s.Imports = append(s.Imports, CapInfo{
+				ID:       uint32(id),
+				Refs:     ent.wireRefs,
+				Created:  ent.created,
+				LastCall: ent.lastCall,
+			})
+		})
+		sort.Slice(s.Exports, func(i, j int) bool { return s.Exports[i].ID < s.Exports[j].ID })
+		sort.Slice(s.Imports, func(i, j int) bool { return s.Imports[i].ID < s.Imports[j].ID })
+		return s
+	})
+}
+
+// An IdlePolicy determines what a Conn does with exports that have not
+// received calls for a while.  See Options.IdleExports.
+type IdlePolicy struct {
+	// Threshold is how long an export may go without calls before it
+	// is considered idle.  It must be positive.
+	Threshold time.Duration
+
+	// Interval is how often the exports are checked.  If zero,
+	// Threshold / 2 is used.
+	Interval time.Duration
+
+	// Release makes the Conn release idle exports instead of only
+	// logging a warning about them.  A released export keeps its ID
+	// until the remote vat releases it, but calls to it fail with
+	// ExcExportIdle.
+	Release bool
+}
+
+func (p *IdlePolicy) interval() time.Duration {
+	if p.Interval > 0 {
+		return p.Interval
+	}
+	return p.Threshold / 2
+}
+
+// watchIdleExports periodically applies p to c's exports until c is
+// shut down.
+func (c *Conn) watchIdleExports(p IdlePolicy) {
+	timer := c.clock.NewTimer(p.interval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.Chan():
+		case <-c.bgctx.Done():
+			return
+		}
+		c.checkIdleExports(p)
+		timer.Reset(p.interval())
+	}
+}
+
+// checkIdleExports warns about, or releases, the exports that have
+// been idle for longer than p.Threshold.  Each export is reported once
+// per idle period.
+func (c *Conn) checkIdleExports(p IdlePolicy) {
+	type idleExport struct {
+		id   exportID
+		idle time.Duration
+	}
+	var idle []idleExport
+
+	dq := &deferred.Queue{}
+	defer dq.Run()
+	now := c.clock.Now()
+	c.withLocked(func(c *lockedConn) {
+		c.lk.exports.each(func(id exportID, ent *expent) {
+			if ent.revoked || ent.idleReported {
+				return
+			}
+			last := ent.lastCall
+			if last.IsZero() {
+				last = ent.created
+			}
+			if d := now.Sub(last); d >= p.Threshold {
+				ent.idleReported = true
+				idle = append(idle, idleExport{id, d})
+				if p.Release {
+					c.revokeExport(dq, ent)
+				}
+			}
+		})
+	})
+
+	for _, e := range idle {
+		if p.Release {
+			c.er.Warn("rpc: released idle export", "id", uint32(e.id), "idle", e.idle)
+		} else {
+			c.er.Warn("rpc: export is idle", "id", uint32(e.id), "idle", e.idle)
+		}
+	}
+}
+
+// revokeExport releases the capability behind ent, replacing it with
+// one that fails every call.  The entry itself stays in the table so
+// that the export ID is not reused before the remote vat releases it.
+func (c *lockedConn) revokeExport(dq *deferred.Queue, ent *expent) {
+	snapshot := ent.snapshot
+	if metadata := snapshot.Metadata(); metadata != nil {
+		syncutil.With(metadata, func() {
+			c.clearExportID(metadata)
+		})
+	}
+	ent.snapshot = capnp.ErrorClient(ExcExportIdle).Snapshot()
+	ent.revoked = true
+	dq.Defer(ent.cancel)
+	dq.Defer(snapshot.Release)
+}
+
+// answerQueueOf returns the AnswerQueue behind pcall, if any.
+func answerQueueOf(pcall capnp.PipelineCaller) *capnp.AnswerQueue {
+	if p, ok := pcall.(*promisedPipelineCaller); ok {
+		select {
+		case <-p.ready:
+			pcall = p.underlying
+		default:
+			return nil
+		}
+	}
+	aq, _ := pcall.(*capnp.AnswerQueue)
+	return aq
+}
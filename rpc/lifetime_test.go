@@ -0,0 +1,205 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+// warnLogger records the messages logged with Warn.
+type warnLogger struct {
+	testErrorReporter
+	warnings chan string
+}
+
+func (l warnLogger) Warn(msg string, args ...any) {
+	l.testErrorReporter.Warn(msg, args...)
+	l.warnings <- msg
+}
+
+func newLifetimeTestConns(t *testing.T, clk clock.Clock, idle *rpc.IdlePolicy) (pp testcp.PingPong, server, client *rpc.Conn, warnings <-chan string) {
+	ctx := context.Background()
+	logger := warnLogger{
+		testErrorReporter: testErrorReporter{tb: t},
+		warnings:          make(chan string, 10),
+	}
+	server, client = rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		Logger:          logger,
+		Clock:           clk,
+		IdleExports:     idle,
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+		Clock:  clk,
+	})
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	pp = testcp.PingPong(client.Bootstrap(ctx))
+	require.NoError(t, pp.Resolve(ctx))
+	return pp, server, client, logger.warnings
+}
+
+func TestDebugSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewManual(start)
+	pp, server, client, _ := newLifetimeTestConns(t, clk, nil)
+	defer pp.Release()
+
+	exports := server.DebugSnapshot().Exports
+	require.Len(t, exports, 1)
+	assert.Equal(t, 1, exports[0].Refs)
+	assert.Equal(t, start, exports[0].Created)
+	assert.True(t, exports[0].LastCall.IsZero())
+
+	clk.Advance(time.Minute)
+	assert.Equal(t, time.Minute, exports[0].Idle(clk.Now()))
+	ans, release := echoNum(ctx, pp, 42)
+	_, err := ans.Struct()
+	release()
+	require.NoError(t, err)
+
+	snap := server.DebugSnapshot()
+	require.Len(t, snap.Exports, 1)
+	assert.Equal(t, start.Add(time.Minute), snap.Exports[0].LastCall)
+	assert.Zero(t, snap.Exports[0].Idle(clk.Now()))
+	assert.Empty(t, snap.Imports)
+
+	snap = client.DebugSnapshot()
+	require.Len(t, snap.Imports, 1)
+	assert.Equal(t, start, snap.Imports[0].Created)
+	assert.Equal(t, start.Add(time.Minute), snap.Imports[0].LastCall)
+	assert.Empty(t, snap.Exports)
+	assert.Zero(t, snap.Questions)
+}
+
+func TestIdleExports(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Warn", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		pp, server, _, warnings := newLifetimeTestConns(t, clk, &rpc.IdlePolicy{
+			Threshold: time.Minute,
+		})
+		defer pp.Release()
+
+		clk.Advance(30 * time.Second)
+		ans, release := echoNum(ctx, pp, 1)
+		_, err := ans.Struct()
+		release()
+		require.NoError(t, err)
+		clk.Advance(30 * time.Second)
+		assert.Empty(t, warnings, "export used 30s ago is not idle")
+
+		clk.Advance(time.Minute)
+		assert.Equal(t, "rpc: export is idle", <-warnings)
+		clk.Advance(30 * time.Second)
+		assert.Empty(t, warnings, "idle export is reported only once")
+
+		// The export still works.
+		ans, release = echoNum(ctx, pp, 2)
+		defer release()
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.N())
+		assert.False(t, server.DebugSnapshot().Exports[0].Revoked)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		pp, server, _, warnings := newLifetimeTestConns(t, clk, &rpc.IdlePolicy{
+			Threshold: time.Minute,
+			Interval:  time.Minute,
+			Release:   true,
+		})
+
+		clk.Advance(time.Minute)
+		assert.Equal(t, "rpc: released idle export", <-warnings)
+		exports := server.DebugSnapshot().Exports
+		require.Len(t, exports, 1)
+		assert.True(t, exports[0].Revoked)
+
+		ans, release := echoNum(ctx, pp, 1)
+		defer release()
+		_, err := ans.Struct()
+		assert.True(t, exc.IsType(err, exc.Disconnected), "call to released export fails with disconnected; got %v", err)
+
+		// The remote vat can still release the export cleanly.
+		pp.Release()
+		require.Eventually(t, func() bool {
+			return len(server.DebugSnapshot().Exports) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestDebugSnapshotQueued(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	unblock := make(chan struct{})
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.EmptyProvider_ServerToClient(blockingEmptyProvider{unblock})),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer server.Close()
+	defer client.Close()
+
+	provider := testcp.EmptyProvider(client.Bootstrap(ctx))
+	defer provider.Release()
+	f, release := provider.GetEmpty(ctx, nil)
+	defer release()
+
+	// Pipeline a call on the result, which waits in the server's answer
+	// queue until getEmpty returns.
+	ans, releaseCall := capnp.Client(f.Empty()).SendCall(ctx, capnp.Send{
+		Method: capnp.Method{InterfaceID: testcp.Empty_TypeID},
+	})
+	defer releaseCall()
+	require.Eventually(t, func() bool {
+		return server.DebugSnapshot().Queued.Depth == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, server.DebugSnapshot().Queued.Queues)
+	assert.Zero(t, client.DebugSnapshot().Queued)
+
+	close(unblock)
+	<-ans.Done()
+	assert.Zero(t, server.DebugSnapshot().Queued)
+}
+
+// blockingEmptyProvider returns a null capability from getEmpty once
+// unblock is closed.
+type blockingEmptyProvider struct {
+	unblock <-chan struct{}
+}
+
+func (p blockingEmptyProvider) GetEmpty(ctx context.Context, call testcp.EmptyProvider_getEmpty) error {
+	call.Go()
+	select {
+	case <-p.unblock:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	_, err := call.AllocResults()
+	return err
+}
@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"net"
+	"sync"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// A ListenerMux serves Cap'n Proto RPC on several listeners at once,
+// creating every Conn from the same Options and bootstrap factory.  This
+// lets a server expose the same capabilities over, say, TCP, a Unix
+// socket and WebSockets, without repeating the setup for each.
+//
+// Any net.Listener can be served.  For WebSockets, use a listener that
+// yields each accepted WebSocket as a net.Conn, such as one built with
+// the NetConn adapter of a WebSocket library.
+//
+// The zero value is ready to use, but serves no bootstrap capability.
+// A ListenerMux is safe to use from multiple goroutines, but its
+// fields must not be changed once Serve has been called.
+type ListenerMux struct {
+	// Bootstrap, if not nil, is called for each accepted connection to
+	// get the bootstrap capability to serve to the peer.  The Conn takes
+	// ownership of the returned client.  The PeerID describes the
+	// accepted net.Conn; see PeerIDFromNetConn.
+	Bootstrap func(PeerID) capnp.Client
+
+	// Options, if not nil, is the template for the Options of each
+	// Conn.  BootstrapClient and RemotePeerID are filled in for each
+	// connection.  Logger also receives errors from accepting
+	// connections.
+	Options *Options
+
+	mu        sync.Mutex
+	closed    bool
+	listeners map[net.Listener]struct{}
+	conns     map[*Conn]struct{}
+}
+
+// Serve accepts connections on lis and creates a Conn for each of
+// them, until lis fails or m is closed.  Serve takes ownership of lis.
+// opts select the transport to use for lis and may override the
+// Authenticator.  Serve may be called concurrently with different
+// listeners.
+//
+// Serve always returns a non-nil error.  After Close, it returns
+// ErrMuxClosed.
+func (m *ListenerMux) Serve(lis net.Listener, opts ...ServeOption) error {
+	if !m.track(lis) {
+		_ = lis.Close()
+		return ErrMuxClosed
+	}
+	defer m.untrack(lis)
+
+	options := defaultServeOpts()
+	for _, o := range opts {
+		o(&options)
+	}
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if m.isClosed() {
+				return ErrMuxClosed
+			}
+			_ = lis.Close()
+			return err
+		}
+		go m.serveConn(conn, options)
+	}
+}
+
+// serveConn creates a Conn on an accepted net.Conn and tracks it until
+// it shuts down.
+func (m *ListenerMux) serveConn(conn net.Conn, options serveOpts) {
+	var opts Options
+	if m.Options != nil {
+		opts = *m.Options
+	}
+	c, err := serveConn(conn, opts, options, m.Bootstrap)
+	if err != nil {
+		errReporter{opts.Logger}.Warn("accepted connection failed",
+			"remote", conn.RemoteAddr(),
+			"error", err,
+		)
+		return
+	}
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		_ = c.Close()
+		return
+	}
+	m.conns[c] = struct{}{}
+	m.mu.Unlock()
+
+	<-c.Done()
+	m.mu.Lock()
+	delete(m.conns, c)
+	m.mu.Unlock()
+}
+
+// NumConns returns the number of Conns created by m that have not shut
+// down yet.
+func (m *ListenerMux) NumConns() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.conns)
+}
+
+// Close closes all listeners being served by m and all Conns they
+// created, and waits for the Conns to shut down.  Subsequent calls to
+// Serve fail with ErrMuxClosed.
+func (m *ListenerMux) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	listeners := make([]net.Listener, 0, len(m.listeners))
+	for lis := range m.listeners {
+		listeners = append(listeners, lis)
+	}
+	conns := make([]*Conn, 0, len(m.conns))
+	for c := range m.conns {
+		conns = append(conns, c)
+	}
+	m.conns = make(map[*Conn]struct{})
+	m.mu.Unlock()
+
+	var err error
+	for _, lis := range listeners {
+		if e := lis.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, c := range conns {
+		_ = c.Close()
+	}
+	return err
+}
+
+// track adds lis to the listeners closed by Close.  It returns false if
+// m is already closed.
+func (m *ListenerMux) track(lis net.Listener) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return false
+	}
+	if m.listeners == nil {
+		m.listeners = make(map[net.Listener]struct{})
+		m.conns = make(map[*Conn]struct{})
+	}
+	m.listeners[lis] = struct{}{}
+	return true
+}
+
+func (m *ListenerMux) untrack(lis net.Listener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.listeners, lis)
+}
+
+func (m *ListenerMux) isClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
@@ -0,0 +1,76 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestListenerMux(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unix, err := net.Listen("unix", filepath.Join(t.TempDir(), "capnp.sock"))
+	require.NoError(t, err)
+
+	peers := make(chan rpc.PeerID, 2)
+	mux := &rpc.ListenerMux{
+		Bootstrap: func(peer rpc.PeerID) capnp.Client {
+			peers <- peer
+			return capnp.Client(testcp.PingPong_ServerToClient(pingPongServer{}))
+		},
+		Options: &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		},
+	}
+	errs := make(chan error, 2)
+	go func() { errs <- mux.Serve(tcp) }()
+	go func() { errs <- mux.Serve(unix, rpc.WithPackedStreamingTransport()) }()
+
+	dial := func(network, addr string, newTransport rpc.NewTransportFunc) *rpc.Conn {
+		nc, err := net.Dial(network, addr)
+		require.NoError(t, err)
+		conn := rpc.NewConn(newTransport(nc), nil)
+		pp := testcp.PingPong(conn.Bootstrap(ctx))
+		defer pp.Release()
+		ans, release := pp.EchoNum(ctx, func(p testcp.PingPong_echoNum_Params) error {
+			p.SetN(42)
+			return nil
+		})
+		defer release()
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), res.N())
+		return conn
+	}
+	tcpConn := dial("tcp", tcp.Addr().String(), rpc.NewStreamTransport)
+	defer tcpConn.Close()
+	unixConn := dial("unix", unix.Addr().String(), rpc.NewPackedStreamTransport)
+	defer unixConn.Close()
+
+	for i := 0; i < 2; i++ {
+		assert.NotNil(t, (<-peers).Addr, "PeerID records the remote address")
+	}
+	assert.Equal(t, 2, mux.NumConns())
+
+	require.NoError(t, mux.Close())
+	assert.ErrorIs(t, <-errs, rpc.ErrMuxClosed)
+	assert.ErrorIs(t, <-errs, rpc.ErrMuxClosed)
+	assert.Equal(t, 0, mux.NumConns())
+	<-tcpConn.Done()
+	<-unixConn.Done()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	assert.ErrorIs(t, mux.Serve(lis), rpc.ErrMuxClosed)
+}
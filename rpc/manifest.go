@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"sort"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+)
+
+// manifestMethod is the method that Manifest calls on the remote
+// bootstrap capability.  Its interface ID is reserved for this purpose.
+// It takes no params, and its results are a struct whose only pointer
+// is a list of entries, in name order, each a struct with the entry's
+// name as Text in pointer 0 and its capability in pointer 1.
+var manifestMethod = capnp.Method{
+	InterfaceID:   0xc9a3f0d62b1e8476,
+	MethodID:      0,
+	InterfaceName: "rpc.Manifest",
+	MethodName:    "get",
+}
+
+var (
+	manifestSize      = capnp.ObjectSize{PointerCount: 1}
+	manifestEntrySize = capnp.ObjectSize{PointerCount: 2}
+)
+
+// remoteManifest is the remote vat's manifest, fetched once per Conn.
+type remoteManifest struct {
+	done chan struct{} // closed once caps and err are set
+
+	// caps is guarded by the Conn's lock, since shutdown releases it.
+	caps map[string]capnp.Client
+	err  error
+}
+
+// Manifest returns the capabilities in the remote vat's
+// Options.Manifest, by name.  The manifest is fetched once, with a call
+// pipelined on the bootstrap capability, so it arrives together with
+// the bootstrap capability itself; see also Options.PrefetchManifest.
+// If the remote vat has no manifest, Manifest returns an empty map and
+// a nil error; if it has no bootstrap capability, Manifest fails.  The caller must release the returned clients.
+func (c *Conn) Manifest(ctx context.Context) (map[string]capnp.Client, error) {
+	m := c.startManifest()
+	select {
+	case <-m.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return withLockedConn2(c, func(c *lockedConn) (map[string]capnp.Client, error) {
+		if c.lk.closing {
+			return nil, ExcClosed
+		}
+		caps := make(map[string]capnp.Client, len(m.caps))
+		for name, cap := range m.caps {
+			caps[name] = cap.AddRef()
+		}
+		return caps, nil
+	})
+}
+
+// startManifest asks the remote vat for its manifest, unless that has
+// already been done, and returns the manifest to wait on.
+func (c *Conn) startManifest() *remoteManifest {
+	var start bool
+	m := withLockedConn1(c, func(c *lockedConn) *remoteManifest {
+		if c.lk.manifest == nil {
+			c.lk.manifest = &remoteManifest{done: make(chan struct{})}
+			start = c.startTask()
+			if !start {
+				c.lk.manifest.err = ExcClosed
+				close(c.lk.manifest.done)
+			}
+		}
+		return c.lk.manifest
+	})
+	if start {
+		go c.fetchManifest(m)
+	}
+	return m
+}
+
+// fetchManifest gets the remote vat's manifest and stores it in m.
+func (c *Conn) fetchManifest(m *remoteManifest) {
+	defer c.tasks.Done()
+	defer close(m.done)
+
+	ctx := c.bgctx
+	boot := c.Bootstrap(ctx)
+	defer boot.Release()
+	ans, release := boot.SendCall(ctx, capnp.Send{Method: manifestMethod})
+	defer release()
+
+	var caps map[string]capnp.Client
+	res, err := ans.Struct()
+	switch {
+	case exc.IsType(err, exc.Unimplemented):
+		caps, err = map[string]capnp.Client{}, nil
+	case err == nil:
+		caps, err = readManifest(res)
+	}
+	c.withLocked(func(c *lockedConn) {
+		if err == nil && c.lk.closing {
+			err = ExcClosed
+		}
+		if err == nil {
+			m.caps, caps = caps, nil
+		}
+		m.err = err
+	})
+	for _, cap := range caps {
+		cap.Release()
+	}
+}
+
+// readManifest returns the capabilities in the results of a manifest
+// call, adding a reference to each.
+func readManifest(res capnp.Struct) (map[string]capnp.Client, error) {
+	p, err := res.Ptr(0)
+	if err != nil {
+		return nil, err
+	}
+	l := capnp.StructList[capnp.Struct](p.List())
+	caps := make(map[string]capnp.Client, l.Len())
+	for i := 0; i < l.Len(); i++ {
+		e := l.At(i)
+		name, err := e.Ptr(0)
+		if err != nil {
+			return caps, err
+		}
+		cap, err := e.Ptr(1)
+		if err != nil {
+			return caps, err
+		}
+		caps[name.Text()] = cap.Interface().Client().AddRef()
+	}
+	return caps, nil
+}
+
+// writeManifest answers a Manifest call.
+func (h *reservedHook) writeManifest(r capnp.Recv) error {
+	res, err := r.AllocResults(manifestSize)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(h.manifest))
+	for name := range h.manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seg := res.Segment()
+	l, err := capnp.NewCompositeList(seg, manifestEntrySize, int32(len(names)))
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		e := l.Struct(i)
+		if err := e.SetNewText(0, name); err != nil {
+			return err
+		}
+		if cap := h.manifest[name]; cap.IsValid() {
+			if err := e.SetPtr(1, cap.AddRef().EncodeAsPtr(seg)); err != nil {
+				return err
+			}
+		}
+	}
+	return res.SetPtr(0, l.ToPtr())
+}
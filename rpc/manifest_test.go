@@ -0,0 +1,115 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestManifest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	echo := func(t *testing.T, c capnp.Client, n int64) int64 {
+		ans, release := testcp.PingPong(c).EchoNum(ctx, func(p testcp.PingPong_echoNum_Params) error {
+			p.SetN(n)
+			return nil
+		})
+		defer release()
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		return res.N()
+	}
+
+	t.Run("Prefetch", func(t *testing.T) {
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(addPonger(0))),
+			Manifest: map[string]capnp.Client{
+				"plusOne": capnp.Client(testcp.PingPong_ServerToClient(addPonger(1))),
+				"plusTwo": capnp.Client(testcp.PingPong_ServerToClient(addPonger(2))),
+			},
+			Logger: testErrorReporter{tb: t},
+		}, &rpc.Options{
+			PrefetchManifest: true,
+			Logger:           testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		defer client.Close()
+
+		caps, err := client.Manifest(ctx)
+		require.NoError(t, err)
+		require.Len(t, caps, 2)
+		assert.Equal(t, int64(11), echo(t, caps["plusOne"], 10))
+		assert.Equal(t, int64(12), echo(t, caps["plusTwo"], 10))
+		for _, c := range caps {
+			c.Release()
+		}
+
+		// The manifest is fetched once, and each call returns new
+		// references.
+		again, err := client.Manifest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(11), echo(t, again["plusOne"], 10))
+		for _, c := range again {
+			c.Release()
+		}
+
+		// The bootstrap capability is still served.
+		boot := client.Bootstrap(ctx)
+		defer boot.Release()
+		assert.Equal(t, int64(10), echo(t, boot, 10))
+	})
+
+	t.Run("RemoteWithoutManifest", func(t *testing.T) {
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(addPonger(0))),
+			Logger:          testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		defer client.Close()
+
+		caps, err := client.Manifest(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, caps)
+	})
+
+	t.Run("RemoteWithoutBootstrap", func(t *testing.T) {
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			Manifest: map[string]capnp.Client{
+				"plusOne": capnp.Client(testcp.PingPong_ServerToClient(addPonger(1))),
+			},
+			Logger: testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		defer client.Close()
+
+		_, err := client.Manifest(ctx)
+		assert.Error(t, err, "manifest served without a bootstrap capability")
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		server, client := rpc.NewLocalPair(&rpc.Options{
+			Manifest: map[string]capnp.Client{
+				"plusOne": capnp.Client(testcp.PingPong_ServerToClient(addPonger(1))),
+			},
+			Logger: testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		})
+		defer server.Close()
+		require.NoError(t, client.Close())
+
+		_, err := client.Manifest(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+// TestIncomingCallMethodNames checks that the receiving side of a call
+// names the method in errors, even though only IDs go over the wire.
+func TestIncomingCallMethodNames(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	srv, conn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.NewClient(server.New(nil, nil, nil)),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer srv.Close()
+	defer conn.Close()
+
+	boot := conn.Bootstrap(ctx)
+	defer boot.Release()
+	ans, release := boot.SendCall(ctx, capnp.Send{
+		Method: capnp.Method{
+			InterfaceID: testcp.PingPong_TypeID,
+			MethodID:    0,
+		},
+	})
+	defer release()
+	_, err := ans.Struct()
+	require.Error(t, err)
+	assert.True(t, capnp.IsUnimplemented(err), "error = %v; want unimplemented", err)
+	assert.Contains(t, err.Error(), "unimplemented method test.capnp:PingPong.echoNum")
+}
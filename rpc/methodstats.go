@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"sort"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// MethodStats summarizes the calls that a Conn received from the remote
+// vat for one method.
+type MethodStats struct {
+	// Method identifies the method.  Its names are filled in from the
+	// methods registered with capnp.RegisterMethods, if any.
+	Method capnp.Method
+
+	// Calls is the number of calls that have returned, and Errors is
+	// the number of those that returned an exception.
+	Calls  uint64
+	Errors uint64
+
+	// Time is the total time from receiving the calls to their return,
+	// and MaxTime is the longest of them.
+	Time    time.Duration
+	MaxTime time.Duration
+}
+
+// Mean returns the mean time of the calls, or zero if there were none.
+func (ms MethodStats) Mean() time.Duration {
+	if ms.Calls == 0 {
+		return 0
+	}
+	return ms.Time / time.Duration(ms.Calls)
+}
+
+type methodKey struct {
+	interfaceID uint64
+	methodID    uint16
+}
+
+// MethodStats returns the stats of the calls received by c so far, one
+// entry for each method that returned at least once, ordered by
+// interface and method ID.
+func (c *Conn) MethodStats() []MethodStats {
+	stats := withLockedConn1(c, func(c *lockedConn) []MethodStats {
+		stats := make([]MethodStats, 0, len(c.lk.methodStats))
+		for _, ms := range c.lk.methodStats {
+			stats = append(stats, *ms)
+		}
+		return stats
+	})
+	sort.Slice(stats, func(i, j int) bool {
+		mi, mj := stats[i].Method, stats[j].Method
+		if mi.InterfaceID != mj.InterfaceID {
+			return mi.InterfaceID < mj.InterfaceID
+		}
+		return mi.MethodID < mj.MethodID
+	})
+	return stats
+}
+
+// recordReturn adds a call to m that took d to the stats.
+func (c *lockedConn) recordReturn(m capnp.Method, d time.Duration, failed bool) {
+	k := methodKey{interfaceID: m.InterfaceID, methodID: m.MethodID}
+	ms := c.lk.methodStats[k]
+	if ms == nil {
+		if c.lk.methodStats == nil {
+			c.lk.methodStats = make(map[methodKey]*MethodStats)
+		}
+		ms = &MethodStats{Method: m}
+		c.lk.methodStats[k] = ms
+	}
+	ms.Calls++
+	if failed {
+		ms.Errors++
+	}
+	ms.Time += d
+	if d > ms.MaxTime {
+		ms.MaxTime = d
+	}
+}
@@ -0,0 +1,63 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestMethodStats(t *testing.T) {
+	t.Parallel()
+
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(picky{})),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer server.Close()
+	defer client.Close()
+
+	ctx := context.Background()
+	pp := testcp.PingPong(client.Bootstrap(ctx))
+	defer pp.Release()
+	assert.Empty(t, server.MethodStats())
+	for _, n := range []int64{1, 2, -1} {
+		f, release := pp.EchoNum(ctx, func(p testcp.PingPong_echoNum_Params) error {
+			p.SetN(n)
+			return nil
+		})
+		_, err := f.Struct()
+		release()
+		assert.Equal(t, n < 0, err != nil, "n = %d: err = %v", n, err)
+	}
+
+	require.Eventually(t, func() bool {
+		stats := server.MethodStats()
+		return len(stats) == 1 && stats[0].Calls == 3
+	}, time.Second, 10*time.Millisecond)
+	ms := server.MethodStats()[0]
+	assert.Equal(t, uint64(testcp.PingPong_TypeID), ms.Method.InterfaceID)
+	assert.Equal(t, uint16(0), ms.Method.MethodID)
+	assert.Equal(t, uint64(1), ms.Errors)
+	assert.GreaterOrEqual(t, ms.MaxTime, ms.Mean())
+	assert.Empty(t, client.MethodStats(), "calls made are not counted")
+}
+
+// picky echoes non-negative numbers.
+type picky struct{}
+
+func (picky) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	if call.Args().N() < 0 {
+		return errors.New("negative number")
+	}
+	return pingPonger{}.EchoNum(ctx, call)
+}
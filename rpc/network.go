@@ -2,15 +2,87 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
 
 	capnp "capnproto.org/go/capnp/v3"
 )
 
 // A PeerID identifies a peer on a Cap'n Proto network. The exact
 // format of this is network specific.
+//
+// Besides the network specific Value, a PeerID may record what the
+// transport knows about the peer and what the Conn's Authenticator
+// made of it.  All fields are optional.
 type PeerID struct {
 	// Network specific value identifying the peer.
 	Value any
+
+	// Addr is the peer's transport address, if known.
+	Addr net.Addr
+
+	// TLS is the state of the TLS connection to the peer, if the
+	// transport uses TLS.  Its PeerCertificates and VerifiedChains
+	// identify the peer.
+	TLS *tls.ConnectionState
+
+	// Auth is the value returned by the Conn's Authenticator, or nil if
+	// the Conn has no Authenticator.
+	Auth AuthInfo
+}
+
+// String returns the peer's address if it is known, or else its
+// network specific Value formatted with fmt.Sprint.  It returns "" for
+// the zero PeerID.
+func (p PeerID) String() string {
+	switch {
+	case p.Addr != nil:
+		return p.Addr.String()
+	case p.Value != nil:
+		return fmt.Sprint(p.Value)
+	default:
+		return ""
+	}
+}
+
+// AuthInfo is application-defined information about an authenticated
+// peer, such as the user or role it acts as.  Server implementations
+// can retrieve it with PeerIDFromContext to make authorization
+// decisions.
+type AuthInfo any
+
+// An Authenticator decides whether to accept a connection from a peer.
+// It is called once, when the Conn is created, before any messages are
+// processed.  If it returns an error, the connection is aborted with
+// ErrAuthFailed; otherwise, the returned AuthInfo is recorded in the
+// Conn's RemotePeerID.
+type Authenticator func(ctx context.Context, peer PeerID) (AuthInfo, error)
+
+// PeerIDFromNetConn returns a PeerID describing the remote end of conn.
+// If conn is a *tls.Conn, PeerIDFromNetConn completes the handshake, if
+// needed, and records the resulting connection state.
+func PeerIDFromNetConn(ctx context.Context, conn net.Conn) (PeerID, error) {
+	id := PeerID{Addr: conn.RemoteAddr()}
+	if tc, ok := conn.(*tls.Conn); ok {
+		if err := tc.HandshakeContext(ctx); err != nil {
+			return PeerID{}, rpcerr.WrapFailed("tls handshake", err)
+		}
+		state := tc.ConnectionState()
+		id.TLS = &state
+	}
+	return id, nil
+}
+
+type peerIDKey struct{}
+
+// PeerIDFromContext returns the PeerID of the remote vat that made a
+// call, given the context passed to the call's server implementation.
+// It returns false if the call did not arrive over a Conn.  The PeerID
+// is the Conn's RemotePeerID, so it may be the zero value.
+func PeerIDFromContext(ctx context.Context) (PeerID, bool) {
+	id, ok := ctx.Value(peerIDKey{}).(PeerID)
+	return id, ok
 }
 
 // The information needed to connect to a third party and accept a capability
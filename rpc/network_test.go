@@ -0,0 +1,195 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+// peerRecorder is a PingPong that records the caller's PeerID.
+type peerRecorder struct {
+	peers chan<- rpc.PeerID
+}
+
+func (p peerRecorder) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	id, ok := rpc.PeerIDFromContext(ctx)
+	if !ok {
+		id = rpc.PeerID{Value: "missing"}
+	}
+	p.peers <- id
+	return nil
+}
+
+func TestPeerIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, ok := rpc.PeerIDFromContext(ctx)
+	assert.False(t, ok, "context without a Conn should have no PeerID")
+
+	peers := make(chan rpc.PeerID, 1)
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(peerRecorder{peers})),
+		RemotePeerID:    rpc.PeerID{Value: "client"},
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer pp.Release()
+	ans, release := pp.EchoNum(ctx, nil)
+	defer release()
+	_, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, rpc.PeerID{Value: "client"}, <-peers)
+}
+
+// labelRecorder is a PingPong that records the pprof labels of calls.
+type labelRecorder struct {
+	labels chan<- map[string]string
+}
+
+func (l labelRecorder) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	m := make(map[string]string)
+	pprof.ForLabels(ctx, func(k, v string) bool {
+		m[k] = v
+		return true
+	})
+	l.labels <- m
+	return nil
+}
+
+func TestProfilerLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := make(chan map[string]string, 1)
+	boot := testcp.PingPong_ServerToClientWithOptions(labelRecorder{labels}, &server.Options{ProfilerLabels: true})
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(boot),
+		RemotePeerID:    rpc.PeerID{Value: "client"},
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	ctx := context.Background()
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer pp.Release()
+	ans, release := pp.EchoNum(ctx, nil)
+	defer release()
+	_, err := ans.Struct()
+	require.NoError(t, err)
+	got := <-labels
+	assert.Equal(t, "client", got["capnp.peer"])
+	assert.Equal(t, "echoNum", got["capnp.method"])
+	assert.NotEmpty(t, got["capnp.interface"])
+}
+
+func TestAuthenticator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("Accept", func(t *testing.T) {
+		t.Parallel()
+
+		var seen rpc.PeerID
+		peers := make(chan rpc.PeerID, 1)
+		serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(peerRecorder{peers})),
+			RemotePeerID:    rpc.PeerID{Value: "client"},
+			Authenticator: func(ctx context.Context, peer rpc.PeerID) (rpc.AuthInfo, error) {
+				seen = peer
+				return "alice", nil
+			},
+			Logger: testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		})
+		defer serverConn.Close()
+		defer clientConn.Close()
+		assert.Equal(t, rpc.PeerID{Value: "client"}, seen)
+		assert.Equal(t, rpc.PeerID{Value: "client", Auth: "alice"}, serverConn.RemotePeerID())
+
+		pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+		defer pp.Release()
+		ans, release := pp.EchoNum(ctx, nil)
+		defer release()
+		_, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, rpc.PeerID{Value: "client", Auth: "alice"}, <-peers)
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		t.Parallel()
+
+		// The server never reads from the pipe, but NewLocalPair
+		// makes room for everything the client sends.
+		boot := capnp.Client(testcp.PingPong_ServerToClient(pingPongServer{}))
+		serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: boot,
+			Authenticator: func(ctx context.Context, peer rpc.PeerID) (rpc.AuthInfo, error) {
+				return nil, errors.New("unknown peer")
+			},
+		}, nil)
+		defer clientConn.Close()
+		select {
+		case <-serverConn.Done():
+		default:
+			t.Error("rejected Conn is not shut down")
+		}
+		assert.False(t, boot.IsValid(), "rejected Conn did not release its bootstrap client")
+
+		// The abort was buffered in the pipe, so the client sees it
+		// as soon as it starts.
+		pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+		defer pp.Release()
+		ans, release := pp.EchoNum(ctx, nil)
+		defer release()
+		_, err := ans.Struct()
+		require.Error(t, err)
+		<-clientConn.Done()
+	})
+}
+
+func TestPeerIDFromNetConn(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := lis.Accept()
+		if err == nil {
+			accepted <- c
+		}
+		close(accepted)
+	}()
+	client, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+	server, ok := <-accepted
+	require.True(t, ok, "accept failed")
+	defer server.Close()
+
+	id, err := rpc.PeerIDFromNetConn(ctx, server)
+	require.NoError(t, err)
+	assert.Equal(t, client.LocalAddr().String(), id.Addr.String())
+	assert.Nil(t, id.TLS, "not a TLS connection")
+}
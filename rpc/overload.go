@@ -0,0 +1,98 @@
+package rpc
+
+import "context"
+
+// An OverloadPolicy determines how a Conn handles calls made while its
+// send queue is full, or while it has too many outstanding questions.
+// See Options.MaxSendQueue and Options.MaxOutstandingQuestions.
+type OverloadPolicy int
+
+const (
+	// OverloadFail makes calls fail immediately with ExcOverloaded or
+	// ExcTooManyQuestions.
+	OverloadFail OverloadPolicy = iota
+
+	// OverloadBlock makes calls wait for room in the send queue or for
+	// a question to finish, or until their Context is done.
+	OverloadBlock
+)
+
+// admitCall checks whether a new call may be added to the send queue,
+// blocking if the overload policy asks for it.  A nil return means the
+// call may proceed.  Since the check is made before the call is queued,
+// concurrent calls may briefly take the queue over its limit.
+//
+// The caller MUST NOT hold c.lk.
+func (c *Conn) admitCall(ctx context.Context) error {
+	if c.maxSendQueue <= 0 {
+		return nil
+	}
+	for c.sendQueued.Load() >= c.maxSendQueue {
+		if c.overloadPolicy != OverloadBlock {
+			return ExcOverloaded
+		}
+		select {
+		case <-c.sendSpace:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.bgctx.Done():
+			return ExcClosed
+		}
+	}
+
+	// Pass the wakeup on, in case other calls are waiting and there
+	// is room for them too.
+	if c.overloadPolicy == OverloadBlock && c.sendQueued.Load() < c.maxSendQueue-1 {
+		c.signalSendSpace()
+	}
+	return nil
+}
+
+// admitQuestion reserves one of the Conn's question slots for a new
+// call, blocking if the overload policy asks for it.  A nil return
+// means the call may proceed, and that the caller MUST either pass the
+// slot on to a question or give it back with freeQuestionSlot.
+//
+// The caller MUST NOT hold c.lk.
+func (c *Conn) admitQuestion(ctx context.Context) error {
+	if c.questionSlots == nil {
+		return nil
+	}
+	select {
+	case c.questionSlots <- struct{}{}:
+		return nil
+	default:
+	}
+	if c.overloadPolicy != OverloadBlock {
+		return ExcTooManyQuestions
+	}
+	select {
+	case c.questionSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.bgctx.Done():
+		return ExcClosed
+	}
+}
+
+// freeQuestionSlot gives back a slot reserved by admitQuestion.
+func (c *Conn) freeQuestionSlot() {
+	if c.questionSlots != nil {
+		<-c.questionSlots
+	}
+}
+
+// sendDequeued is called whenever a message leaves the send queue,
+// either because it was sent or because it was aborted.
+func (c *Conn) sendDequeued() {
+	c.sendQueued.Add(-1)
+	c.signalSendSpace()
+}
+
+func (c *Conn) signalSendSpace() {
+	select {
+	case c.sendSpace <- struct{}{}:
+	default:
+	}
+}
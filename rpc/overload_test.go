@@ -0,0 +1,264 @@
+package rpc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+)
+
+// gatedTransport holds outgoing messages while it is paused.
+type gatedTransport struct {
+	rpc.Transport
+	paused atomic.Bool
+	resume chan struct{}
+}
+
+func (t *gatedTransport) NewMessage() (transport.OutgoingMessage, error) {
+	out, err := t.Transport.NewMessage()
+	return gatedMessage{OutgoingMessage: out, t: t}, err
+}
+
+type gatedMessage struct {
+	transport.OutgoingMessage
+	t *gatedTransport
+}
+
+func (m gatedMessage) Send() error {
+	if m.t.paused.Load() {
+		<-m.t.resume
+	}
+	return m.OutgoingMessage.Send()
+}
+
+func newOverloadTestConns(t *testing.T, opts *rpc.Options) (testcp.PingPong, *gatedTransport, func()) {
+	ctx := context.Background()
+	left, right := transport.NewPipe(1)
+	serverConn := rpc.NewConn(rpc.NewTransport(right), &rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		Logger:          testErrorReporter{tb: t},
+	})
+	trans := &gatedTransport{
+		Transport: rpc.NewTransport(left),
+		resume:    make(chan struct{}),
+	}
+	opts.Logger = testErrorReporter{tb: t}
+	clientConn := rpc.NewConn(trans, opts)
+
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	require.NoError(t, pp.Resolve(ctx))
+	return pp, trans, func() {
+		pp.Release()
+		clientConn.Close()
+		serverConn.Close()
+	}
+}
+
+func TestSendQueueOverloadFail(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pp, trans, cleanup := newOverloadTestConns(t, &rpc.Options{MaxSendQueue: 2})
+	defer cleanup()
+
+	trans.paused.Store(true)
+	ans1, release1 := echoNum(ctx, pp, 1)
+	defer release1()
+	ans2, release2 := echoNum(ctx, pp, 2)
+	defer release2()
+
+	ans3, release3 := echoNum(ctx, pp, 3)
+	defer release3()
+	_, err := ans3.Struct()
+	assert.True(t, exc.IsType(err, exc.Overloaded), "third call: got %v; want overloaded", err)
+
+	close(trans.resume)
+	for i, ans := range []testcp.PingPong_echoNum_Results_Future{ans1, ans2} {
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), res.N())
+	}
+
+	// Once the queue has drained, calls are accepted again.
+	ans4, release4 := echoNum(ctx, pp, 4)
+	defer release4()
+	res, err := ans4.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.N())
+}
+
+func TestSendQueueOverloadBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pp, trans, cleanup := newOverloadTestConns(t, &rpc.Options{
+		MaxSendQueue:   1,
+		OverloadPolicy: rpc.OverloadBlock,
+	})
+	defer cleanup()
+
+	trans.paused.Store(true)
+	ans1, release1 := echoNum(ctx, pp, 1)
+	defer release1()
+
+	// A call whose Context ends while it waits is abandoned.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	ans2, release2 := echoNum(timeoutCtx, pp, 2)
+	defer release2()
+	_, err := ans2.Struct()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	done := make(chan int64, 1)
+	go func() {
+		ans, release := echoNum(ctx, pp, 3)
+		defer release()
+		res, err := ans.Struct()
+		if assert.NoError(t, err) {
+			done <- res.N()
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("call did not block on full send queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(trans.resume)
+	res, err := ans1.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.N())
+	select {
+	case n := <-done:
+		assert.Equal(t, int64(3), n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked call did not complete")
+	}
+}
+
+// stalledPonger echoes once unblock is closed.
+type stalledPonger struct {
+	unblock chan struct{}
+}
+
+func (p stalledPonger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	select {
+	case <-p.unblock:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return pingPonger{}.EchoNum(ctx, call)
+}
+
+// newQuestionLimitTestConns returns a client limited to two outstanding
+// questions, whose calls return once unblock is closed.
+func newQuestionLimitTestConns(t *testing.T, policy rpc.OverloadPolicy) (testcp.PingPong, chan struct{}, func()) {
+	unblock := make(chan struct{})
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(stalledPonger{unblock: unblock})),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		MaxOutstandingQuestions: 2,
+		OverloadPolicy:          policy,
+		Logger:                  testErrorReporter{tb: t},
+	})
+	pp := testcp.PingPong(clientConn.Bootstrap(context.Background()))
+	return pp, unblock, func() {
+		pp.Release()
+		clientConn.Close()
+		serverConn.Close()
+	}
+}
+
+func TestMaxOutstandingQuestionsFail(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pp, unblock, cleanup := newQuestionLimitTestConns(t, rpc.OverloadFail)
+	defer cleanup()
+
+	ans1, release1 := echoNum(ctx, pp, 1)
+	defer release1()
+	ans2, release2 := echoNum(ctx, pp, 2)
+	defer release2()
+
+	ans3, release3 := echoNum(ctx, pp, 3)
+	defer release3()
+	_, err := ans3.Struct()
+	assert.True(t, exc.IsType(err, exc.Overloaded), "third call: got %v; want overloaded", err)
+	assert.ErrorIs(t, err, rpc.ErrTooManyQuestions)
+
+	close(unblock)
+	for i, ans := range []testcp.PingPong_echoNum_Results_Future{ans1, ans2} {
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), res.N())
+	}
+
+	// Once the questions have returned, calls are accepted again.
+	ans4, release4 := echoNum(ctx, pp, 4)
+	defer release4()
+	res, err := ans4.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), res.N())
+}
+
+func TestMaxOutstandingQuestionsBlock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pp, unblock, cleanup := newQuestionLimitTestConns(t, rpc.OverloadBlock)
+	defer cleanup()
+
+	ans1, release1 := echoNum(ctx, pp, 1)
+	defer release1()
+	ans2, release2 := echoNum(ctx, pp, 2)
+	defer release2()
+
+	// A call whose Context ends while it waits is abandoned.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	ans3, release3 := echoNum(timeoutCtx, pp, 3)
+	defer release3()
+	_, err := ans3.Struct()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	done := make(chan int64, 1)
+	go func() {
+		ans, release := echoNum(ctx, pp, 4)
+		defer release()
+		res, err := ans.Struct()
+		if assert.NoError(t, err) {
+			done <- res.N()
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("call did not block on outstanding questions")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(unblock)
+	for i, ans := range []testcp.PingPong_echoNum_Results_Future{ans1, ans2} {
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), res.N())
+	}
+	select {
+	case n := <-done:
+		assert.Equal(t, int64(4), n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("blocked call did not complete")
+	}
+}
@@ -0,0 +1,42 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+)
+
+// Ping measures the round-trip time to the remote vat.  It sends a
+// Bootstrap message, which every vat answers without involving
+// application code, and waits for the return.  Any answer counts, even
+// an exception from a vat that has no bootstrap capability.
+//
+// The result of the latest successful Ping is also reported as
+// DebugSnapshot.RTT.  Ping ignores Options.CacheBootstrap, and does not
+// change the cached bootstrap capability.
+func (c *Conn) Ping(ctx context.Context) (time.Duration, error) {
+	start := c.clock.Now()
+	bc := withLockedConn1(c, func(c *lockedConn) capnp.Client {
+		return c.newBootstrapQuestion(ctx)
+	})
+	defer bc.Release()
+
+	if err := bc.Resolve(ctx); err != nil {
+		return 0, rpcerr.Annotate(err, "ping")
+	}
+	rtt := c.clock.Now().Sub(start)
+
+	// The remote vat's exceptions are a valid answer, but local
+	// failures, such as the connection being closed or ctx being done,
+	// are not.
+	snapshot := bc.Snapshot()
+	err, _ := snapshot.Brand().Value.(error)
+	snapshot.Release()
+	if err != nil && (exc.TypeOf(err) == exc.Disconnected || ctx.Err() != nil) {
+		return 0, rpcerr.Annotate(err, "ping")
+	}
+	c.rtt.Store(int64(rtt))
+	return rtt, nil
+}
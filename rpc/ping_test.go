@@ -0,0 +1,77 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestPing(t *testing.T) {
+	t.Parallel()
+
+	newConns := func(t *testing.T, boot capnp.Client) (client, server *rpc.Conn) {
+		server, client = rpc.NewLocalPair(&rpc.Options{
+			BootstrapClient: boot,
+			Logger:          testErrorReporter{tb: t},
+		}, &rpc.Options{
+			Logger: testErrorReporter{tb: t},
+		})
+		t.Cleanup(func() {
+			client.Close()
+			server.Close()
+		})
+		return client, server
+	}
+
+	t.Run("Bootstrap", func(t *testing.T) {
+		t.Parallel()
+
+		client, server := newConns(t, capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})))
+		assert.Zero(t, client.DebugSnapshot().RTT)
+		rtt, err := client.Ping(context.Background())
+		require.NoError(t, err)
+		assert.Positive(t, rtt)
+		assert.Equal(t, rtt, client.DebugSnapshot().RTT)
+
+		// The bootstrap capability is released again.
+		require.Eventually(t, func() bool {
+			snap := server.DebugSnapshot()
+			return len(snap.Exports) == 0 && snap.Answers == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("NoBootstrap", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := newConns(t, capnp.Client{})
+		rtt, err := client.Ping(context.Background())
+		require.NoError(t, err)
+		assert.Positive(t, rtt)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := newConns(t, capnp.Client{})
+		require.NoError(t, client.Close())
+		_, err := client.Ping(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := newConns(t, capnp.Client{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.Ping(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,267 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/pogs"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/server"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func TestPipelineLimits(t *testing.T) {
+	t.Parallel()
+
+	srvShutdown := make(chan struct{})
+	srv := newServer(
+		func(ctx context.Context, call *server.Call) error {
+			resp, err := call.AllocResults(capnp.ObjectSize{DataSize: 8})
+			if err != nil {
+				return err
+			}
+			resp.SetUint64(0, 0xdeadbeef)
+			return nil
+		},
+		func() {
+			close(srvShutdown)
+		})
+	conn, p2 := newTestConn(&rpc.Options{
+		BootstrapClient:   srv,
+		Logger:            testErrorReporter{tb: t},
+		MaxTransformDepth: 2,
+	})
+	defer func() {
+		finishTest(t, conn, p2)
+		<-srvShutdown
+	}()
+	ctx := context.Background()
+
+	const bootstrapQID = 54
+	require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+		Which:     rpccp.Message_Which_bootstrap,
+		Bootstrap: &rpcBootstrap{QuestionID: bootstrapQID},
+	}))
+	_, err := recvBootstrapReturn(ctx, p2, bootstrapQID)
+	require.NoError(t, err)
+
+	// call sends a call on the promised answer target with the given
+	// transform length, and returns the Return it gets back.
+	call := func(t *testing.T, qid, target uint32, transformLen int) *rpcReturn {
+		ops := make([]rpcPromisedAnswerOp, transformLen)
+		for i := range ops {
+			ops[i].Which = rpccp.PromisedAnswer_Op_Which_noop
+		}
+		require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+			Which: rpccp.Message_Which_call,
+			Call: &rpcCall{
+				QuestionID: qid,
+				Target: rpcMessageTarget{
+					Which: rpccp.MessageTarget_Which_promisedAnswer,
+					PromisedAnswer: &rpcPromisedAnswer{
+						QuestionID: target,
+						Transform:  ops,
+					},
+				},
+				InterfaceID: interfaceID,
+				MethodID:    methodID,
+			},
+		}))
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		t.Cleanup(release)
+		require.Equal(t, rpccp.Message_Which_return, rmsg.Which)
+		require.Equal(t, qid, rmsg.Return.AnswerID)
+		require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+			Which:  rpccp.Message_Which_finish,
+			Finish: &rpcFinish{QuestionID: qid},
+		}))
+		return rmsg.Return
+	}
+
+	t.Run("Loop", func(t *testing.T) {
+		ret := call(t, 55, 55, 0)
+		require.Equal(t, rpccp.Return_Which_exception, ret.Which)
+		assert.Equal(t, rpccp.Exception_Type_failed, ret.Exception.Type)
+		assert.Contains(t, ret.Exception.Reason, rpc.ErrPipelineLoop.Error())
+	})
+
+	t.Run("TooDeep", func(t *testing.T) {
+		ret := call(t, 56, bootstrapQID, 3)
+		require.Equal(t, rpccp.Return_Which_exception, ret.Which)
+		assert.Equal(t, rpccp.Exception_Type_failed, ret.Exception.Type)
+		assert.Contains(t, ret.Exception.Reason, rpc.ErrTransformTooDeep.Error())
+	})
+
+	t.Run("ConnUsable", func(t *testing.T) {
+		ret := call(t, 57, bootstrapQID, 2)
+		assert.Equal(t, rpccp.Return_Which_results, ret.Which)
+	})
+}
+
+// TestPipelineLoop checks that a call whose results hold a promise
+// pipelined on those same results fails, instead of leaving calls on
+// the promise waiting forever.
+func TestPipelineLoop(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// gets is the number of calls that wait for a capability
+		// and return it.  sets[i] is the question whose results
+		// hold the capability passed to the ith get.
+		gets int
+		sets []uint32
+		// resolves is the number of promises returned before the
+		// loop closes.
+		resolves int
+	}{
+		// get 1 returns a promise on its own results.
+		{name: "Self", gets: 1, sets: []uint32{1}},
+		// get 1 returns a promise on get 2, which returns a promise
+		// on get 1.  Whichever returns last closes the loop.
+		{name: "TwoAnswers", gets: 2, sets: []uint32{2, 1}, resolves: 1},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// The server's calls either take a capability from
+			// their arguments and hand it to the next waiting get,
+			// or, without arguments, wait for one and return it.
+			var slots []chan capnp.Client
+			for i := 0; i < test.gets; i++ {
+				slots = append(slots, make(chan capnp.Client, 1))
+			}
+			var nget, nset int
+			srv := newServer(func(ctx context.Context, call *server.Call) error {
+				if call.Args().HasPtr(0) {
+					iface, err := call.Args().Ptr(0)
+					if err != nil {
+						return err
+					}
+					slots[nset] <- iface.Interface().Client().AddRef()
+					nset++
+					return nil
+				}
+				slot := slots[nget]
+				nget++
+				call.Go()
+				c := <-slot
+				resp, err := call.AllocResults(capnp.ObjectSize{PointerCount: 1})
+				if err != nil {
+					c.Release()
+					return err
+				}
+				capID := resp.Message().CapTable().Add(c)
+				return resp.SetPtr(0, capnp.NewInterface(resp.Segment(), capID).ToPtr())
+			}, nil)
+			conn, p2 := newTestConn(&rpc.Options{
+				BootstrapClient: srv,
+				Logger:          testErrorReporter{tb: t},
+			})
+			defer finishTest(t, conn, p2)
+			ctx := context.Background()
+
+			const bootstrapQID = 0
+			require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+				Which:     rpccp.Message_Which_bootstrap,
+				Bootstrap: &rpcBootstrap{QuestionID: bootstrapQID},
+			}))
+			bootstrapImportID, err := recvBootstrapReturn(ctx, p2, bootstrapQID)
+			require.NoError(t, err)
+
+			// call calls the bootstrap capability, passing a
+			// promise on the results of question arg if it is
+			// not zero.
+			call := func(qid, arg uint32) {
+				outMsg, err := p2.NewMessage()
+				require.NoError(t, err)
+				defer outMsg.Release()
+				var params rpcPayload
+				if arg != 0 {
+					seg := outMsg.Message().Segment()
+					args, err := capnp.NewStruct(seg, capnp.ObjectSize{PointerCount: 1})
+					require.NoError(t, err)
+					require.NoError(t, args.SetPtr(0, capnp.NewInterface(seg, 0).ToPtr()))
+					params = rpcPayload{
+						Content: args.ToPtr(),
+						CapTable: []rpcCapDescriptor{{
+							Which: rpccp.CapDescriptor_Which_receiverAnswer,
+							ReceiverAnswer: &rpcPromisedAnswer{
+								QuestionID: arg,
+								Transform: []rpcPromisedAnswerOp{{
+									Which:           rpccp.PromisedAnswer_Op_Which_getPointerField,
+									GetPointerField: 0,
+								}},
+							},
+						}},
+					}
+				}
+				require.NoError(t, pogs.Insert(rpccp.Message_TypeID, capnp.Struct(outMsg.Message()), &rpcMessage{
+					Which: rpccp.Message_Which_call,
+					Call: &rpcCall{
+						QuestionID: qid,
+						Target: rpcMessageTarget{
+							Which:       rpccp.MessageTarget_Which_importedCap,
+							ImportedCap: bootstrapImportID,
+						},
+						InterfaceID: interfaceID,
+						MethodID:    methodID,
+						Params:      params,
+					},
+				}))
+				require.NoError(t, outMsg.Send())
+			}
+
+			qid := uint32(1)
+			for i := 0; i < test.gets; i++ {
+				call(qid, 0)
+				qid++
+			}
+			for _, arg := range test.sets {
+				call(qid, arg)
+				qid++
+			}
+
+			// Every call returns; the get that closes the loop
+			// returns an exception.  Promises returned before the loop closes
+			// are resolved to that exception.
+			returns := make(map[uint32]*rpcReturn)
+			resolves := 0
+			for len(returns) < int(qid)-1 || resolves < test.resolves {
+				rmsg, release, err := recvMessage(ctx, p2)
+				require.NoError(t, err)
+				defer release()
+				switch rmsg.Which {
+				case rpccp.Message_Which_return:
+					returns[rmsg.Return.AnswerID] = rmsg.Return
+				case rpccp.Message_Which_resolve:
+					resolves++
+				default:
+					t.Fatalf("received %v message; want return or resolve", rmsg.Which)
+				}
+			}
+			var loops []uint32
+			for id, ret := range returns {
+				if ret.Which == rpccp.Return_Which_exception {
+					loops = append(loops, id)
+					assert.Contains(t, ret.Exception.Reason, rpc.ErrPipelineLoop.Error())
+				}
+			}
+			require.Len(t, loops, 1, "exactly one call should fail")
+			assert.LessOrEqual(t, loops[0], uint32(test.gets), "question %d failed; want a get", loops[0])
+			for id := uint32(1); id < qid; id++ {
+				require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+					Which:  rpccp.Message_Which_finish,
+					Finish: &rpcFinish{QuestionID: id, ReleaseResultCaps: true},
+				}))
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package rpc
+
+import (
+	"context"
+
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// A Priority is a scheduling hint for an outgoing call.  Calls with a
+// higher priority are written to the transport ahead of lower priority
+// messages that are still waiting in the Conn's send queue, so that
+// latency-sensitive calls are not stuck behind bulk traffic such as
+// streaming uploads.
+//
+// Priorities never reorder calls made on the same capability: a call
+// is only moved ahead of messages that are addressed to other targets,
+// so E-order is preserved.  Likewise, a call pipelined on a promised
+// answer is never sent before the message that asks the question.
+type Priority int
+
+const (
+	// PriorityNormal is the priority of calls that do not carry a hint.
+	PriorityNormal Priority = iota
+
+	// PriorityHigh calls are sent ahead of queued PriorityNormal
+	// messages.
+	PriorityHigh
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority p.  Calls
+// sent to a remote vat using the returned context will be scheduled
+// with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority attached to ctx by
+// WithPriority, or PriorityNormal if there is none.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// A sendTarget identifies the capability a Call or Disembargo message
+// is addressed to.  Promised answers are identified by their question
+// alone, so that calls on different transforms of the same answer are
+// kept in order with respect to each other.
+type sendTarget struct {
+	which rpccp.MessageTarget_Which
+	id    uint32
+}
+
+// messageTarget returns the target of m, if m is a Call or a Disembargo.
+func messageTarget(m rpccp.Message) (_ sendTarget, ok bool) {
+	var (
+		tgt rpccp.MessageTarget
+		err error
+	)
+	switch m.Which() {
+	case rpccp.Message_Which_call:
+		var call rpccp.Call
+		if call, err = m.Call(); err == nil {
+			tgt, err = call.Target()
+		}
+	case rpccp.Message_Which_disembargo:
+		var d rpccp.Disembargo
+		if d, err = m.Disembargo(); err == nil {
+			tgt, err = d.Target()
+		}
+	default:
+		return sendTarget{}, false
+	}
+	if err != nil {
+		return sendTarget{}, false
+	}
+
+	switch tgt.Which() {
+	case rpccp.MessageTarget_Which_importedCap:
+		return sendTarget{which: tgt.Which(), id: tgt.ImportedCap()}, true
+	case rpccp.MessageTarget_Which_promisedAnswer:
+		pa, err := tgt.PromisedAnswer()
+		if err != nil {
+			return sendTarget{}, false
+		}
+		return sendTarget{which: tgt.Which(), id: pa.QuestionId()}, true
+	default:
+		return sendTarget{}, false
+	}
+}
+
+// messageQuestion returns the promised answer of the question that m
+// creates, if m is a Bootstrap or a Call.  Calls on that answer must
+// not be sent before m.
+func messageQuestion(m rpccp.Message) (_ sendTarget, ok bool) {
+	var id uint32
+	switch m.Which() {
+	case rpccp.Message_Which_bootstrap:
+		b, err := m.Bootstrap()
+		if err != nil {
+			return sendTarget{}, false
+		}
+		id = b.QuestionId()
+	case rpccp.Message_Which_call:
+		call, err := m.Call()
+		if err != nil {
+			return sendTarget{}, false
+		}
+		id = call.QuestionId()
+	default:
+		return sendTarget{}, false
+	}
+	return sendTarget{which: rpccp.MessageTarget_Which_promisedAnswer, id: id}, true
+}
+
+// sendQueue orders outgoing messages for the send goroutine.  Messages
+// are sent in FIFO order, except that PriorityHigh messages go ahead
+// of PriorityNormal ones, unless a PriorityNormal message for the same
+// target, or one creating the question it targets, is still waiting.
+// The zero value is an empty queue.
+type sendQueue struct {
+	high, normal []asyncSend
+
+	// Number of messages in normal that target or create each target.
+	waiting map[sendTarget]int
+}
+
+func (q *sendQueue) empty() bool {
+	return len(q.high) == 0 && len(q.normal) == 0
+}
+
+func (q *sendQueue) push(as asyncSend) {
+	if as.priority > PriorityNormal && (!as.targeted || q.waiting[as.target] == 0) {
+		q.high = append(q.high, as)
+		return
+	}
+
+	if (as.targeted || as.asks) && q.waiting == nil {
+		q.waiting = make(map[sendTarget]int)
+	}
+	if as.targeted {
+		q.waiting[as.target]++
+	}
+	if as.asks {
+		q.waiting[as.question]++
+	}
+	q.normal = append(q.normal, as)
+}
+
+// pop removes and returns the next message to send.  The queue MUST
+// NOT be empty.
+func (q *sendQueue) pop() (as asyncSend) {
+	if len(q.high) > 0 {
+		as, q.high[0] = q.high[0], asyncSend{}
+		q.high = q.high[1:]
+		return as
+	}
+
+	as, q.normal[0] = q.normal[0], asyncSend{}
+	q.normal = q.normal[1:]
+	if as.targeted {
+		q.done(as.target)
+	}
+	if as.asks {
+		q.done(as.question)
+	}
+	return as
+}
+
+// done decrements the number of messages waiting for tgt.
+func (q *sendQueue) done(tgt sendTarget) {
+	if n := q.waiting[tgt] - 1; n > 0 {
+		q.waiting[tgt] = n
+	} else {
+		delete(q.waiting, tgt)
+	}
+}
@@ -0,0 +1,87 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func TestPriorityFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, PriorityNormal, PriorityFromContext(ctx))
+	assert.Equal(t, PriorityHigh, PriorityFromContext(WithPriority(ctx, PriorityHigh)))
+}
+
+func TestSendQueue(t *testing.T) {
+	var sent []string
+	msg := func(name string, p Priority, target uint32) asyncSend {
+		return asyncSend{
+			send: func() error {
+				sent = append(sent, name)
+				return nil
+			},
+			release:  func() {},
+			priority: p,
+			target:   sendTarget{which: rpccp.MessageTarget_Which_importedCap, id: target},
+			targeted: true,
+		}
+	}
+
+	var q sendQueue
+	q.push(msg("bulk1", PriorityNormal, 1))
+	q.push(msg("bulk2", PriorityNormal, 1))
+	q.push(msg("ctl1", PriorityHigh, 2))
+	q.push(msg("same", PriorityHigh, 1))
+	q.push(msg("ctl2", PriorityHigh, 2))
+	q.push(asyncSend{
+		send: func() error {
+			sent = append(sent, "untargeted")
+			return nil
+		},
+		release: func() {},
+	})
+	for !q.empty() {
+		q.pop().Send()
+	}
+
+	assert.Equal(t, []string{"ctl1", "ctl2", "bulk1", "bulk2", "same", "untargeted"}, sent)
+	assert.Empty(t, q.waiting, "waiting counts not cleared")
+}
+
+func TestSendQueueQuestion(t *testing.T) {
+	var sent []string
+	msg := func(name string, p Priority) asyncSend {
+		return asyncSend{
+			send: func() error {
+				sent = append(sent, name)
+				return nil
+			},
+			release:  func() {},
+			priority: p,
+		}
+	}
+	answer := func(id uint32) sendTarget {
+		return sendTarget{which: rpccp.MessageTarget_Which_promisedAnswer, id: id}
+	}
+
+	var q sendQueue
+	boot := msg("bootstrap", PriorityNormal)
+	boot.question, boot.asks = answer(1), true
+	q.push(boot)
+	call := msg("call", PriorityHigh)
+	call.target, call.targeted = answer(1), true
+	call.question, call.asks = answer(2), true
+	q.push(call)
+	pipelined := msg("pipelined", PriorityHigh)
+	pipelined.target, pipelined.targeted = answer(2), true
+	q.push(pipelined)
+	for !q.empty() {
+		q.pop().Send()
+	}
+
+	assert.Equal(t, []string{"bootstrap", "call", "pipelined"}, sent)
+	assert.Empty(t, q.waiting, "waiting counts not cleared")
+}
@@ -6,23 +6,27 @@ import (
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/internal/syncutil"
 	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+	"capnproto.org/go/capnp/v3/util/deferred"
 )
 
 // A questionID is an index into the questions table.
 type questionID uint32
 
 type question struct {
-	c  *Conn
-	id questionID
+	c      *Conn
+	id     questionID
+	method capnp.Method
 
 	p       *capnp.Promise
 	release capnp.ReleaseFunc // written before resolving p
+	inline  bool              // see inlinesResults
 
 	// Protected by c.mu:
 
 	flags         questionFlags
 	finishMsgSend chan struct{}        // closed after attempting to send the Finish message
 	called        [][]capnp.PipelineOp // paths to called clients
+	holdsSlot     bool                 // see Conn.admitQuestion
 }
 
 // questionFlags is a bitmask of which events have occurred in a question's
@@ -52,19 +56,28 @@ func (c *lockedConn) newQuestion(method capnp.Method) *question {
 	q := &question{
 		c:             (*Conn)(c),
 		id:            c.lk.questionID.next(),
+		method:        method,
 		release:       func() {},
+		inline:        inlinesResults(method),
 		finishMsgSend: make(chan struct{}),
 	}
+	c.decide(DecisionQuestionID, uint32(q.id))
 	q.p = capnp.NewPromise(method, q, nil) // TODO(someday): customize error message for bootstrap
 	c.setAnswerQuestion(q.p.Answer(), q)
-	if int(q.id) == len(c.lk.questions) {
-		c.lk.questions = append(c.lk.questions, q)
-	} else {
-		c.lk.questions[q.id] = q
-	}
+	c.lk.questions.set(q.id, q)
 	return q
 }
 
+// removeQuestion removes q from c's table, and gives back its question
+// slot, if any.  The question ID stays reserved.
+func (c *lockedConn) removeQuestion(q *question) {
+	c.lk.questions.remove(q.id)
+	if q.holdsSlot {
+		q.holdsSlot = false
+		(*Conn)(c).freeQuestionSlot()
+	}
+}
+
 func (c *lockedConn) getAnswerQuestion(ans *capnp.Answer) (*question, bool) {
 	m := ans.Metadata()
 	m.Lock()
@@ -131,8 +144,17 @@ func (q *question) handleCancel(ctx context.Context) {
 }
 
 func (q *question) PipelineSend(ctx context.Context, transform []capnp.PipelineOp, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	if err := q.c.admitCall(ctx); err != nil {
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+	if err := q.c.admitQuestion(ctx); err != nil {
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+	dq := &deferred.Queue{}
+	defer dq.Run()
 	return withLockedConn2(q.c, func(c *lockedConn) (*capnp.Answer, capnp.ReleaseFunc) {
 		if !c.startTask() {
+			q.c.freeQuestionSlot()
 			return capnp.ErrorAnswer(s.Method, ExcClosed), func() {}
 		}
 		defer c.tasks.Done()
@@ -145,14 +167,15 @@ func (q *question) PipelineSend(ctx context.Context, transform []capnp.PipelineO
 		// c) the worst that happens is we trade bandwidth for code simplicity.
 		q.mark(transform)
 		q2 := c.newQuestion(s.Method)
+		q2.holdsSlot = c.questionSlots != nil
 
 		// Send call message.
 		c.sendMessage(ctx, func(m rpccp.Message) error {
-			return c.newPipelineCallMessage(m, q.id, transform, q2.id, s)
+			return c.newPipelineCallMessage(dq, m, q.id, transform, q2.id, s)
 		}, func(err error) {
 			if err != nil {
-				syncutil.With(&q.c.lk, func() {
-					q.c.lk.questions[q2.id] = nil
+				q.c.withLocked(func(c *lockedConn) {
+					c.removeQuestion(q2)
 				})
 				q2.p.Reject(rpcerr.WrapFailed("send message", err))
 				syncutil.With(&q.c.lk, func() {
@@ -178,7 +201,7 @@ func (q *question) PipelineSend(ctx context.Context, transform []capnp.PipelineO
 }
 
 // newPipelineCallMessage builds a Call message targeted to a promised answer..
-func (c *lockedConn) newPipelineCallMessage(msg rpccp.Message, tgt questionID, transform []capnp.PipelineOp, qid questionID, s capnp.Send) error {
+func (c *lockedConn) newPipelineCallMessage(dq *deferred.Queue, msg rpccp.Message, tgt questionID, transform []capnp.PipelineOp, qid questionID, s capnp.Send) error {
 	call, err := msg.NewCall()
 	if err != nil {
 		return rpcerr.WrapFailed("build call message", err)
@@ -223,7 +246,7 @@ func (c *lockedConn) newPipelineCallMessage(msg rpccp.Message, tgt questionID, t
 		return rpcerr.WrapFailed("place arguments", err)
 	}
 	// TODO(soon): save param refs
-	_, err = c.fillPayloadCapTable(payload)
+	_, err = c.fillPayloadCapTable(dq, payload)
 
 	if err != nil {
 		return rpcerr.Annotate(err, "build call message")
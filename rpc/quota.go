@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"sync"
+)
+
+// PeerQuotas limits the exports and answers of the Conns whose remote
+// vats share an identity, as a whole.  Without it, the limits of a
+// multi-tenant server only apply per connection, so a single tenant
+// could exhaust the server by opening many connections.  Set it in
+// Options.PeerQuotas, or with WithPeerQuotas, along with an
+// Authenticator that establishes the identities.
+//
+// Each identity gets a Quota when its first Conn is created, and loses
+// it when its last Conn shuts down.  A PeerQuotas must not be copied
+// after first use.
+type PeerQuotas struct {
+	// MaxExports limits the number of capabilities that the Conns of
+	// an identity export to the remote vats at once.  Sending another
+	// capability fails with an error wrapping ErrQuotaExceeded; if it
+	// is sent in the results of a call, the call returns an overloaded
+	// exception instead.  If zero, exports are not limited.
+	MaxExports int
+
+	// MaxAnswers limits the number of calls from the remote vats that
+	// the Conns of an identity are answering at once.  A call is answered
+	// until the remote vat sends Finish for it.  Further calls fail
+	// with an overloaded exception wrapping ErrQuotaExceeded.  If zero,
+	// answers are not limited.
+	MaxAnswers int
+
+	// Identity returns the identity of a remote vat.  If nil, the
+	// identity is PeerID.Auth, the value returned by the Conn's
+	// Authenticator.  Identities are compared with ==, so they must be
+	// comparable.
+	Identity func(PeerID) any
+
+	mu     sync.Mutex
+	quotas map[any]*Quota
+}
+
+// A Quota is the usage of an identity's share of a PeerQuotas.
+type Quota struct {
+	key   any
+	conns int // guarded by the PeerQuotas' mu
+
+	maxExports int
+	maxAnswers int
+
+	mu      sync.Mutex
+	exports int
+	answers int
+}
+
+// Quota returns the Quota of the identity of peer, or nil if the
+// identity has no Conns.
+func (pq *PeerQuotas) Quota(peer PeerID) *Quota {
+	key := pq.identity(peer)
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.quotas[key]
+}
+
+func (pq *PeerQuotas) identity(peer PeerID) any {
+	if pq.Identity != nil {
+		return pq.Identity(peer)
+	}
+	return peer.Auth
+}
+
+// acquire returns the Quota of the identity of peer, for use by a new
+// Conn.  Each call must be paired with a call to release.
+func (pq *PeerQuotas) acquire(peer PeerID) *Quota {
+	key := pq.identity(peer)
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	q := pq.quotas[key]
+	if q == nil {
+		if pq.quotas == nil {
+			pq.quotas = make(map[any]*Quota)
+		}
+		q = &Quota{
+			key:        key,
+			maxExports: pq.MaxExports,
+			maxAnswers: pq.MaxAnswers,
+		}
+		pq.quotas[key] = q
+	}
+	q.conns++
+	return q
+}
+
+// release gives back a Quota returned by acquire, once the Conn that
+// used it has shut down.
+func (pq *PeerQuotas) release(q *Quota) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	q.conns--
+	if q.conns == 0 {
+		delete(pq.quotas, q.key)
+	}
+}
+
+// Usage returns the number of exports and answers currently charged to
+// q by its Conns.
+func (q *Quota) Usage() (exports, answers int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.exports, q.answers
+}
+
+// addExport charges an export to q, reporting whether it fit.  A nil
+// Quota has no limits.
+func (q *Quota) addExport() bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxExports > 0 && q.exports >= q.maxExports {
+		return false
+	}
+	q.exports++
+	return true
+}
+
+// addAnswer charges an answer to q, reporting whether it fit.  A nil
+// Quota has no limits.
+func (q *Quota) addAnswer() bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxAnswers > 0 && q.answers >= q.maxAnswers {
+		return false
+	}
+	q.answers++
+	return true
+}
+
+// remove gives back exports and answers charged to q.
+func (q *Quota) remove(exports, answers int) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.exports -= exports
+	q.answers -= answers
+}
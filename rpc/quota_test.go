@@ -0,0 +1,162 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+// newQuotaPair returns a client Conn talking to a server Conn that
+// authenticates its peer as "alice" and uses pq.
+func newQuotaPair(t *testing.T, pq *rpc.PeerQuotas, boot capnp.Client) (client, server *rpc.Conn) {
+	server, client = rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: boot,
+		Authenticator: func(ctx context.Context, peer rpc.PeerID) (rpc.AuthInfo, error) {
+			return "alice", nil
+		},
+		PeerQuotas: pq,
+		Logger:     testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	return client, server
+}
+
+func TestPeerQuotas(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	alice := rpc.PeerID{Auth: "alice"}
+
+	t.Run("Answers", func(t *testing.T) {
+		t.Parallel()
+
+		pq := &rpc.PeerQuotas{MaxAnswers: 2}
+		unblock := make(chan struct{})
+		c1, s1 := newQuotaPair(t, pq, capnp.Client(testcp.PingPong_ServerToClient(blockingPinger{unblock})))
+		defer c1.Close()
+		defer s1.Close()
+		c2, s2 := newQuotaPair(t, pq, capnp.Client(testcp.PingPong_ServerToClient(blockingPinger{unblock})))
+		defer c2.Close()
+		defer s2.Close()
+		q := pq.Quota(alice)
+		require.NotNil(t, q)
+
+		pp1 := testcp.PingPong(c1.Bootstrap(ctx))
+		defer pp1.Release()
+		pp2 := testcp.PingPong(c2.Bootstrap(ctx))
+		defer pp2.Release()
+
+		f1, release1 := pp1.EchoNum(ctx, nil)
+		f2, release2 := pp2.EchoNum(ctx, nil)
+		require.Eventually(t, func() bool {
+			_, answers := q.Usage()
+			return answers == 2
+		}, time.Second, 10*time.Millisecond)
+
+		// The quota is shared, so a third call fails on either Conn.
+		for _, pp := range []testcp.PingPong{pp1, pp2} {
+			f, release := pp.EchoNum(ctx, nil)
+			_, err := f.Struct()
+			release()
+			assert.True(t, exc.IsType(err, exc.Overloaded), "got %v; want overloaded", err)
+			assert.ErrorContains(t, err, rpc.ErrQuotaExceeded.Error())
+		}
+
+		close(unblock)
+		_, err := f1.Struct()
+		require.NoError(t, err)
+		_, err = f2.Struct()
+		require.NoError(t, err)
+		release1()
+		release2()
+		require.Eventually(t, func() bool {
+			_, answers := q.Usage()
+			return answers == 0
+		}, time.Second, 10*time.Millisecond, "Finish gives back the answers")
+
+		f, release := pp1.EchoNum(ctx, nil)
+		_, err = f.Struct()
+		release()
+		require.NoError(t, err)
+	})
+
+	t.Run("Exports", func(t *testing.T) {
+		t.Parallel()
+
+		// Each bootstrap capability is an export, which leaves room
+		// for one more.
+		pq := &rpc.PeerQuotas{MaxExports: 3}
+		c1, s1 := newQuotaPair(t, pq, capnp.Client(testcp.PingPongProvider_ServerToClient(pingPongProvider{})))
+		defer c1.Close()
+		defer s1.Close()
+		c2, s2 := newQuotaPair(t, pq, capnp.Client(testcp.PingPongProvider_ServerToClient(pingPongProvider{})))
+		defer c2.Close()
+		defer s2.Close()
+
+		ppp1 := testcp.PingPongProvider(c1.Bootstrap(ctx))
+		defer ppp1.Release()
+		ppp2 := testcp.PingPongProvider(c2.Bootstrap(ctx))
+		defer ppp2.Release()
+		require.NoError(t, capnp.Client(ppp1).Resolve(ctx))
+		require.NoError(t, capnp.Client(ppp2).Resolve(ctx))
+
+		f1, release1 := ppp1.PingPong(ctx, nil)
+		_, err := f1.Struct()
+		require.NoError(t, err)
+		q := pq.Quota(alice)
+		exports, _ := q.Usage()
+		assert.Equal(t, 3, exports)
+
+		f2, release2 := ppp2.PingPong(ctx, nil)
+		_, err = f2.Struct()
+		release2()
+		assert.True(t, exc.IsType(err, exc.Overloaded), "got %v; want overloaded", err)
+		assert.ErrorContains(t, err, rpc.ErrQuotaExceeded.Error())
+
+		// Releasing an export makes room for another.
+		release1()
+		require.Eventually(t, func() bool {
+			exports, _ := q.Usage()
+			return exports == 2
+		}, time.Second, 10*time.Millisecond)
+		f2, release2 = ppp2.PingPong(ctx, nil)
+		_, err = f2.Struct()
+		release2()
+		require.NoError(t, err)
+
+		// The quota goes away along with the identity's last Conn.
+		ppp1.Release()
+		ppp2.Release()
+		require.NoError(t, c1.Close())
+		require.NoError(t, c2.Close())
+		<-s1.Done()
+		<-s2.Done()
+		assert.Nil(t, pq.Quota(alice))
+		exports, answers := q.Usage()
+		assert.Zero(t, exports)
+		assert.Zero(t, answers)
+	})
+}
+
+// blockingPinger echoes numbers once unblock is closed.
+type blockingPinger struct {
+	unblock <-chan struct{}
+}
+
+func (p blockingPinger) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	call.Go()
+	select {
+	case <-p.unblock:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return pingPonger{}.EchoNum(ctx, call)
+}
@@ -0,0 +1,51 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+func TestReadBudget(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	budget := capnp.NewReadBudget(1 << 20)
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		ReadBudget:      budget,
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer pp.Release()
+
+	ans, release := echoNum(ctx, pp, 42)
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), res.N())
+	release()
+
+	spent := 1<<20 - budget.Remaining()
+	assert.NotZero(t, spent, "reading the call should be charged to the budget")
+
+	// Budget for exactly one more call: it is charged for the same
+	// reads as the first one.
+	budget.Add(spent - budget.Remaining())
+	ans, release = echoNum(ctx, pp, 43)
+	_, err = ans.Struct()
+	release()
+	require.NoError(t, err)
+
+	ans, release = echoNum(ctx, pp, 44)
+	defer release()
+	_, err = ans.Struct()
+	assert.Error(t, err, "call should fail once the budget runs out")
+}
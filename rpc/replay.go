@@ -0,0 +1,340 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// A DecisionKind identifies a kind of event in a DecisionLog.
+type DecisionKind int
+
+const (
+	// DecisionRecv records a message read from the transport.  It is
+	// the Conn's input; Data holds the encoded message.
+	DecisionRecv DecisionKind = iota
+
+	// DecisionSend records a message written to the transport.  The
+	// order of sends reflects the scheduling of the send queue; Data
+	// holds the encoded message.
+	DecisionSend
+
+	// DecisionRecvEnd records that reading from the transport failed,
+	// for example because the remote vat hung up.
+	DecisionRecvEnd
+
+	// DecisionClose records a call to Conn.Close by the local vat.
+	DecisionClose
+
+	// DecisionQuestionID, DecisionExportID and DecisionEmbargoID
+	// record the allocation of an ID, which is held in ID.
+	DecisionQuestionID
+	DecisionExportID
+	DecisionEmbargoID
+)
+
+func (k DecisionKind) String() string {
+	switch k {
+	case DecisionRecv:
+		return "recv"
+	case DecisionSend:
+		return "send"
+	case DecisionRecvEnd:
+		return "recv end"
+	case DecisionClose:
+		return "close"
+	case DecisionQuestionID:
+		return "question ID"
+	case DecisionExportID:
+		return "export ID"
+	case DecisionEmbargoID:
+		return "embargo ID"
+	default:
+		return "DecisionKind(" + str.Itod(k) + ")"
+	}
+}
+
+// A Decision is an event recorded in a DecisionLog.
+type Decision struct {
+	Kind DecisionKind
+
+	// ID is the allocated ID, for the ID kinds.
+	ID uint32
+
+	// Which is the type of the message, and Data its encoding, for
+	// DecisionRecv and DecisionSend.
+	Which rpccp.Message_Which
+	Data  []byte
+}
+
+func (d Decision) String() string {
+	switch d.Kind {
+	case DecisionRecv, DecisionSend:
+		return d.Kind.String() + " " + d.Which.String()
+	case DecisionQuestionID, DecisionExportID, DecisionEmbargoID:
+		return d.Kind.String() + " " + str.Utod(d.ID)
+	default:
+		return d.Kind.String()
+	}
+}
+
+// same reports whether d and other record the same decision.  The
+// encodings of messages are not compared, since they may legitimately
+// differ between runs, e.g. in the layout of segments.
+func (d Decision) same(other Decision) bool {
+	return d.Kind == other.Kind && d.ID == other.ID && d.Which == other.Which
+}
+
+// A DecisionLog records the messages a Conn receives, the order in which
+// it sends messages and the IDs it allocates, so that a run can be
+// replayed with Replay.  Set Options.DecisionLog to record a Conn's
+// decisions.  Recording marshals every message, so it is only meant for
+// debugging.  A DecisionLog is safe to use from multiple goroutines.
+type DecisionLog struct {
+	mu        sync.Mutex
+	decisions []Decision
+
+	// onRecord, if not nil, is called with the index of each new
+	// decision while mu is held.  It is used by Replay.
+	onRecord func(i int, d Decision)
+}
+
+// NewDecisionLog returns an empty DecisionLog.
+func NewDecisionLog() *DecisionLog {
+	return new(DecisionLog)
+}
+
+// Decisions returns the decisions recorded so far, in order.
+func (l *DecisionLog) Decisions() []Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Decision(nil), l.decisions...)
+}
+
+func (l *DecisionLog) record(d Decision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decisions = append(l.decisions, d)
+	if l.onRecord != nil {
+		l.onRecord(len(l.decisions)-1, d)
+	}
+}
+
+func (l *DecisionLog) recordMessage(kind DecisionKind, m rpccp.Message) {
+	d := Decision{Kind: kind, Which: m.Which()}
+	d.Data, _ = m.Message().Marshal()
+	l.record(d)
+}
+
+// decide records the allocation of id in c's DecisionLog, if any.
+func (c *lockedConn) decide(kind DecisionKind, id uint32) {
+	if c.decisions != nil {
+		c.decisions.record(Decision{Kind: kind, ID: id})
+	}
+}
+
+// A recordingTransport records the messages that pass through a
+// Transport in a DecisionLog.
+type recordingTransport struct {
+	Transport
+	log *DecisionLog
+}
+
+func (t recordingTransport) NewMessage() (transport.OutgoingMessage, error) {
+	out, err := t.Transport.NewMessage()
+	if err != nil {
+		return nil, err
+	}
+	return recordingOutgoingMessage{out, t.log}, nil
+}
+
+func (t recordingTransport) RecvMessage() (transport.IncomingMessage, error) {
+	in, err := t.Transport.RecvMessage()
+	if err == nil {
+		t.log.recordMessage(DecisionRecv, in.Message())
+	} else {
+		t.log.record(Decision{Kind: DecisionRecvEnd})
+	}
+	return in, err
+}
+
+type recordingOutgoingMessage struct {
+	transport.OutgoingMessage
+	log *DecisionLog
+}
+
+func (m recordingOutgoingMessage) Send() error {
+	m.log.recordMessage(DecisionSend, m.Message())
+	return m.OutgoingMessage.Send()
+}
+
+// A ReplayError reports that a replayed Conn did not make the same
+// decisions as the recorded one.
+type ReplayError struct {
+	// Index is the position of the first differing decision.
+	Index int
+
+	// Want is the recorded decision, and Got the replayed one.  Got is
+	// nil if the replay stopped making decisions before Index.
+	Want Decision
+	Got  *Decision
+}
+
+func (e *ReplayError) Error() string {
+	if e.Got == nil {
+		return "replay: decision " + str.Itod(e.Index) + ": want " + e.Want.String() + ", replay stalled"
+	}
+	return "replay: decision " + str.Itod(e.Index) + ": want " + e.Want.String() + ", got " + e.Got.String()
+}
+
+// Replay re-executes a Conn's recorded decisions.  It creates a Conn
+// with opts, whose BootstrapClient and other settings should match the
+// recorded Conn's, and feeds it the recorded incoming messages.  Each
+// message is delivered only once the replayed Conn has made all the
+// decisions that preceded it in the recording, so the interleaving of
+// incoming and outgoing messages is reproduced, as are calls to
+// Conn.Close.  Outgoing messages are discarded.
+//
+// Replay returns nil once the replayed Conn has made all the recorded
+// decisions, or a *ReplayError describing the first decision that
+// differs.  If ctx is done before the replay finishes, the error
+// reports the decision the replay was waiting for.  Calls made on the
+// recorded Conn's capabilities by the local vat are not part of the
+// recording, so only Conns whose activity is driven by the remote vat,
+// such as servers, can be replayed faithfully.
+func Replay(ctx context.Context, decisions []Decision, opts *Options) error {
+	r := &replayer{
+		want: decisions,
+		wake: make(chan struct{}),
+	}
+	log := NewDecisionLog()
+	log.onRecord = r.check
+
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	o.DecisionLog = log
+	codec := &replayCodec{r: r, ctx: ctx, closed: make(chan struct{})}
+	conn := NewConn(transport.New(codec), &o)
+	defer conn.Close()
+
+	closing := false
+	for {
+		n, err, wake := r.state()
+		if err != nil {
+			return err
+		}
+		if n >= len(decisions) {
+			return nil
+		}
+		if decisions[n].Kind == DecisionClose && !closing {
+			closing = true
+			go conn.Close()
+		}
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return &ReplayError{Index: n, Want: decisions[n]}
+		}
+	}
+}
+
+// A replayer compares the decisions of a replayed Conn with the
+// recorded ones.
+type replayer struct {
+	want []Decision
+
+	mu   sync.Mutex
+	n    int           // number of decisions made so far
+	err  error         // first divergence
+	wake chan struct{} // closed and replaced after each decision
+}
+
+// check is the onRecord callback of the replayed Conn's DecisionLog.
+func (r *replayer) check(i int, got Decision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n = i + 1
+	if r.err == nil && i < len(r.want) && !r.want[i].same(got) {
+		r.err = &ReplayError{Index: i, Want: r.want[i], Got: &got}
+	}
+	close(r.wake)
+	r.wake = make(chan struct{})
+}
+
+// state returns the number of decisions made so far, the divergence
+// found, if any, and a channel that is closed on the next decision.
+func (r *replayer) state() (n int, err error, wake <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.n, r.err, r.wake
+}
+
+// A replayCodec feeds recorded messages to a replayed Conn.
+type replayCodec struct {
+	r      *replayer
+	ctx    context.Context
+	closed chan struct{}
+	next   int // index in r.want to search for the next message from
+}
+
+// waitFor blocks until n decisions have been made, and reports false if
+// the replay diverged, ctx is done or c is closed first.
+func (c *replayCodec) waitFor(n int) bool {
+	for {
+		made, err, wake := c.r.state()
+		if err != nil {
+			return false
+		}
+		if made >= n {
+			return true
+		}
+		select {
+		case <-wake:
+		case <-c.ctx.Done():
+			return false
+		case <-c.closed:
+			return false
+		}
+	}
+}
+
+func (c *replayCodec) Encode(*capnp.Message) error {
+	return nil
+}
+
+func (c *replayCodec) Decode() (*capnp.Message, error) {
+	for ; c.next < len(c.r.want); c.next++ {
+		d := c.r.want[c.next]
+		if d.Kind != DecisionRecv && d.Kind != DecisionRecvEnd {
+			continue
+		}
+		if !c.waitFor(c.next) {
+			return nil, errReplayDone
+		}
+		c.next++
+		if d.Kind == DecisionRecvEnd {
+			return nil, io.EOF
+		}
+		return capnp.Unmarshal(d.Data)
+	}
+
+	// Keep the Conn alive until the remaining decisions have been
+	// made.
+	c.waitFor(len(c.r.want))
+	return nil, errReplayDone
+}
+
+func (c *replayCodec) Close() error {
+	close(c.closed)
+	return nil
+}
+
+var errReplayDone = errors.New("replay finished")
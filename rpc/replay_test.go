@@ -0,0 +1,99 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// recordEchoServer records the decisions of a Conn serving echoer to a
+// client that makes a few calls and hangs up.
+func recordEchoServer(t *testing.T) []rpc.Decision {
+	ctx := context.Background()
+	log := rpc.NewDecisionLog()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(air.Echo_ServerToClient(echoer{})),
+		Logger:          testErrorReporter{tb: t},
+		DecisionLog:     log,
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+
+	echo := air.Echo(clientConn.Bootstrap(ctx))
+	for _, in := range []string{"foo", "bar"} {
+		ans, release := echo.Echo(ctx, func(p air.Echo_echo_Params) error {
+			return p.SetIn(in)
+		})
+		_, err := ans.Struct()
+		require.NoError(t, err)
+		release()
+	}
+	echo.Release()
+	require.NoError(t, clientConn.Close())
+	<-serverConn.Done()
+	return log.Decisions()
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	decisions := recordEchoServer(t)
+	var recvs, sends int
+	for _, d := range decisions {
+		switch d.Kind {
+		case rpc.DecisionRecv:
+			recvs++
+			assert.NotEmpty(t, d.Data)
+		case rpc.DecisionSend:
+			sends++
+			assert.Equal(t, rpccp.Message_Which_return, d.Which)
+		}
+	}
+	assert.Equal(t, 3, sends, "one return for the bootstrap and each call")
+	assert.GreaterOrEqual(t, recvs, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := rpc.Replay(ctx, decisions, &rpc.Options{
+		BootstrapClient: capnp.Client(air.Echo_ServerToClient(echoer{})),
+		Logger:          testErrorReporter{tb: t},
+	})
+	assert.NoError(t, err)
+}
+
+func TestReplayDivergence(t *testing.T) {
+	t.Parallel()
+
+	decisions := recordEchoServer(t)
+	i := -1
+	for j, d := range decisions {
+		if d.Kind == rpc.DecisionExportID {
+			i = j
+			break
+		}
+	}
+	require.NotEqual(t, -1, i, "bootstrap should export a capability")
+	decisions[i].ID = 42
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := rpc.Replay(ctx, decisions, &rpc.Options{
+		BootstrapClient: capnp.Client(air.Echo_ServerToClient(echoer{})),
+		Logger:          testErrorReporter{tb: t},
+	})
+	var re *rpc.ReplayError
+	require.True(t, errors.As(err, &re), "error = %v", err)
+	assert.Equal(t, i, re.Index)
+	require.NotNil(t, re.Got)
+	assert.Equal(t, uint32(0), re.Got.ID)
+	assert.ErrorContains(t, err, "want export ID 42, got export ID 0")
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+func TestReservedHookBootstrap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Brand", func(t *testing.T) {
+		conn, remote := NewLocalPair(&Options{
+			BootstrapClient: capnp.NewClient(server.New(nil, "boot", nil)),
+			Features:        []string{"level3"},
+		}, nil)
+		defer remote.Close()
+		defer conn.Close()
+
+		snapshot := conn.bootstrap.Snapshot()
+		defer snapshot.Release()
+		brand, ok := server.IsServer(snapshot.Brand())
+		assert.True(t, ok, "wrapped bootstrap hides the server's brand")
+		assert.Equal(t, "boot", brand)
+	})
+
+	t.Run("NoBootstrap", func(t *testing.T) {
+		conn, remote := NewLocalPair(&Options{
+			Features: []string{"level3"},
+		}, nil)
+		defer remote.Close()
+		defer conn.Close()
+
+		assert.False(t, conn.bootstrap.IsValid(), "null bootstrap was wrapped")
+	})
+}
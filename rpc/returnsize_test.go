@@ -0,0 +1,78 @@
+package rpc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+)
+
+// echoer echoes its argument.
+type echoer struct{}
+
+func (echoer) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in)
+}
+
+func TestMaxReturnSize(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(air.Echo_ServerToClient(echoer{})),
+		Logger:          testErrorReporter{tb: t},
+		MaxReturnSize:   1024,
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	echo := air.Echo(clientConn.Bootstrap(ctx))
+	defer echo.Release()
+
+	call := func(in string) (string, error) {
+		ans, release := echo.Echo(ctx, func(p air.Echo_echo_Params) error {
+			return p.SetIn(in)
+		})
+		defer release()
+		res, err := ans.Struct()
+		if err != nil {
+			return "", err
+		}
+		return res.Out()
+	}
+
+	out, err := call("small")
+	require.NoError(t, err)
+	assert.Equal(t, "small", out)
+
+	_, err = call(strings.Repeat("x", 2048))
+	require.Error(t, err)
+	assert.Equal(t, exc.Failed, exc.TypeOf(err))
+	assert.ErrorContains(t, err, rpc.ErrReturnTooLarge.Error())
+
+	// The connection is still usable.
+	out, err = call("again")
+	require.NoError(t, err)
+	assert.Equal(t, "again", out)
+	require.Eventually(t, func() bool {
+		return serverConn.DebugSnapshot().Answers == 0
+	}, time.Second, 10*time.Millisecond)
+}
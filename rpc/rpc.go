@@ -5,13 +5,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/pprof"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"capnproto.org/go/capnp/v3"
 	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
 	"capnproto.org/go/capnp/v3/exp/spsc"
 	"capnproto.org/go/capnp/v3/internal/str"
 	"capnproto.org/go/capnp/v3/internal/syncutil"
@@ -85,9 +88,29 @@ type Conn struct {
 	remotePeerID PeerID
 	network      Network
 
-	bootstrap    capnp.Client
-	er           errReporter
-	abortTimeout time.Duration
+	bootstrap        capnp.Client
+	er               errReporter
+	abortTimeout     time.Duration
+	bootstrapTimeout time.Duration
+	cacheBootstrap   bool
+	maxReturnSize    uint64
+	maxTransform     int
+	readBudget       *capnp.ReadBudget
+	readLimiter      capnp.ReadLimiter
+	peerQuotas       *PeerQuotas
+	quota            *Quota // nil if there is no limit
+	clock            clock.Clock
+	strictProtocol   bool
+	onUnimplemented  func(rpccp.Message_Which)
+	decisions        *DecisionLog
+	noShortening     bool
+	strictResolve    bool
+	callPath         bool
+	features         []string // see Options.Features
+
+	// deviations counts the protocol deviations received from the
+	// remote vat, by kind.
+	deviations [numDeviations]atomic.Uint64
 
 	// bgctx is a Context that is canceled when shutdown starts. Note
 	// that it's parent is context.Background(), so we can rely on this
@@ -106,6 +129,32 @@ type Conn struct {
 	// touch this.
 	sendRx *spsc.Rx[asyncSend]
 
+	// Messages taken from sendRx that are waiting to be sent, ordered
+	// by priority.  Only the send goroutine may touch this.
+	sendq sendQueue
+
+	// Limit on the number of queued outgoing messages; see
+	// Options.MaxSendQueue.  sendQueued is the number of messages that
+	// are currently queued, and sendSpace is signaled whenever one of
+	// them leaves the queue.  These are only maintained if
+	// maxSendQueue > 0.
+	maxSendQueue   int64
+	overloadPolicy OverloadPolicy
+	sendQueued     atomic.Int64
+	sendSpace      chan struct{}
+
+	// questionSlots holds a value for each outstanding question made
+	// by a call; see Options.MaxOutstandingQuestions.  nil if there is
+	// no limit.
+	questionSlots chan struct{}
+
+	// rtt is the round-trip time measured by the latest Ping, in
+	// nanoseconds.
+	rtt atomic.Int64
+
+	// values holds the application values set with SetValue.
+	values sync.Map
+
 	// lk contains all the fields that need to be protected by a mutex.
 	// this makes it easy to tell at call sites whether you should or
 	// should not be holding the lock. Methods that access fields within
@@ -122,14 +171,31 @@ type Conn struct {
 		bgcancel context.CancelFunc // bgcancel cancels bgctx.
 
 		// Tables
-		questions  []*question
+		questions  table[questionID, *question]
 		questionID idgen[questionID]
-		answers    map[answerID]*ansent
-		exports    []*expent
+		answers    table[answerID, *ansent]
+		exports    table[exportID, *expent]
 		exportID   idgen[exportID]
-		imports    map[importID]*impent
+		imports    table[importID, *impent]
 		embargoes  []*embargo
 		embargoID  idgen[embargoID]
+
+		// remoteBootstrap is the resolved remote bootstrap client, if
+		// Options.CacheBootstrap is set and a Bootstrap call has resolved.
+		remoteBootstrap capnp.Client
+
+		// methodStats holds the stats of received calls that have
+		// returned, by method.  See Conn.MethodStats.
+		methodStats map[methodKey]*MethodStats
+
+		// remoteFeatures are the features advertised by the remote vat,
+		// valid once featuresKnown is set by a feature exchange.
+		remoteFeatures []string
+		featuresKnown  bool
+
+		// manifest is the remote vat's manifest, once Conn.Manifest or
+		// Options.PrefetchManifest has asked for it.
+		manifest *remoteManifest
 	}
 }
 
@@ -174,6 +240,9 @@ func withLockedConn2[A, B any](c *Conn, f func(*lockedConn) (A, B)) (a A, b B) {
 	return
 }
 
+// DefaultMaxTransformDepth is the default of Options.MaxTransformDepth.
+const DefaultMaxTransformDepth = 64
+
 // Options specifies optional parameters for creating a Conn.
 type Options struct {
 	// BootstrapClient is the capability that will be returned to the
@@ -194,14 +263,203 @@ type Options struct {
 	// RemotePeerID is the PeerID of the remote side of the connection. Can
 	// be left as the zero value for point to point connections. For >= 3
 	// party use, this should be filled in by the Network on Accept or Dial.
+	// Serve fills in the Addr and TLS fields from the accepted net.Conn.
 	// Application code should not set this.
 	RemotePeerID PeerID
 
+	// Authenticator, if not nil, is called with RemotePeerID when the
+	// Conn is created, and may reject the connection.  NewConn blocks
+	// until it returns; it is passed Context, if set.  See Authenticator
+	// for details.
+	Authenticator Authenticator
+
+	// PeerQuotas, if not nil, limits the exports and answers of all the
+	// Conns whose remote vats share an identity, which is usually
+	// established by the Authenticator.  It is meant to be shared by all
+	// the Conns of a server, so that a single tenant can't exhaust the
+	// server by opening many connections.  See PeerQuotas for details.
+	PeerQuotas *PeerQuotas
+
 	// A reference to the Network that this connection is a part of.  Can be
 	// left nil for point to point connections. Otherwise, this must be set
 	// by Dial or Accept on the Network itself; application code should not
 	// set this.
 	Network Network
+
+	// BootstrapTimeout limits how long the Conn waits for the first
+	// message from the remote vat.  If nothing arrives in time, the
+	// connection is aborted with ErrBootstrapTimeout.  This prevents
+	// peers that connect and go silent from holding on to resources.
+	// If zero, the Conn waits indefinitely.
+	BootstrapTimeout time.Duration
+
+	// CacheBootstrap makes Conn.Bootstrap reuse the remote bootstrap
+	// capability once a Bootstrap call has resolved successfully, instead
+	// of sending a new Bootstrap message every time it is called.  Use
+	// Conn.RefreshBootstrap to discard the cached capability.
+	CacheBootstrap bool
+
+	// MaxSendQueue limits the number of outgoing messages that may be
+	// waiting to be written to the transport.  Once the limit is
+	// reached, new calls are handled according to OverloadPolicy.
+	// Other messages, such as returns, are always queued, but count
+	// towards the limit.  If zero, the queue is unbounded.
+	MaxSendQueue int
+
+	// MaxOutstandingQuestions limits the number of calls made through
+	// the Conn that may be waiting for their Return message at once.
+	// Once the limit is reached, new calls are handled according to
+	// OverloadPolicy.  Unlike MaxSendQueue, which bounds the messages
+	// waiting to be written, this bounds the calls the remote vat is
+	// working on, so it caps the concurrency of the connection from
+	// end to end.  A call keeps its place until its Return arrives,
+	// even if it was canceled.  Bootstrap messages do not count.  If
+	// zero, there is no limit.
+	MaxOutstandingQuestions int
+
+	// OverloadPolicy determines what happens to calls made while the
+	// send queue is full, or while MaxOutstandingQuestions calls are
+	// outstanding.  The default is OverloadFail.
+	OverloadPolicy OverloadPolicy
+
+	// MaxReturnSize limits the size, in bytes, of the results of each
+	// call received from the remote vat.  A call whose results would
+	// take up a larger Return message fails with an exception wrapping
+	// ErrReturnTooLarge instead, and the connection stays up.  This
+	// protects remote vats with little memory from accidentally huge
+	// returns.  If zero, there is no limit.
+	MaxReturnSize uint64
+
+	// MaxTransformDepth limits the number of operations in the
+	// transform of a promised answer received from the remote vat,
+	// whether it is the target of a call or a capability in a payload.
+	// A call with a longer transform fails with an exception wrapping
+	// ErrTransformTooDeep, and the connection stays up.  If zero,
+	// DefaultMaxTransformDepth is used.
+	MaxTransformDepth int
+
+	// ReadBudget, if not nil, is attached to every message received
+	// from the remote vat, so that the bytes traversed while reading
+	// them, including call arguments read by local servers, are
+	// charged to it; see capnp.Message.ReadBudget.  Once the budget
+	// runs out, calls whose arguments can't be read fail, and the
+	// connection is aborted with an overloaded exception as soon as a
+	// message can't be read at all.  A budget may be shared by several
+	// Conns, such as all the connections of one client.
+	ReadBudget *capnp.ReadBudget
+
+	// ReadLimiter, if not nil, is set as the capnp.Message.ReadLimiter
+	// of every message received from the remote vat, replacing the
+	// traversal limit of each message.  A limiter shared by all the
+	// messages bounds the bytes traversed for the connection as a
+	// whole, while capnp.NoReadLimit skips the accounting for trusted
+	// peers.  Server read limits on call arguments, such as
+	// server.Options.ArgsTraverseLimit, have no effect on such messages.
+	ReadLimiter capnp.ReadLimiter
+
+	// NewTable, if not nil, is called to create each of the Conn's
+	// tables, instead of NewMemoryTable.
+	NewTable func(TableKind) Table
+
+	// IdleExports, if not nil, makes the Conn watch for capabilities
+	// exported to the remote vat that go without calls for longer than
+	// a threshold.  Such exports are usually capabilities that the
+	// remote vat forgot to release, and they keep server resources
+	// alive.  Idle exports are logged as warnings and, if the policy
+	// says so, released.
+	IdleExports *IdlePolicy
+
+	// Clock is used to timestamp exports and imports and to schedule
+	// IdleExports checks and the BootstrapTimeout.  If nil,
+	// clock.System is used.
+	Clock clock.Clock
+
+	// StrictProtocol makes the Conn log a warning for each deviation
+	// from the protocol it receives from the remote vat, such as
+	// messages of unknown types or redundant resolves, which are
+	// otherwise tolerated quietly.  Deviations are counted either way;
+	// see Conn.Deviations.  This is meant to help implementers test
+	// their own implementations against this one.
+	StrictProtocol bool
+
+	// OnUnimplemented, if not nil, is called with the type of each
+	// message that the Conn answers with an Unimplemented message, such
+	// as messages of unknown types or calls asking for their results to
+	// be sent to a third party.  It is called from the Conn's receive
+	// goroutine, so it must not block.
+	OnUnimplemented func(rpccp.Message_Which)
+
+	// DecisionLog, if not nil, records the messages the Conn receives
+	// and sends and the IDs it allocates, so that hard to reproduce
+	// ordering bugs can be replayed deterministically with Replay.
+	DecisionLog *DecisionLog
+
+	// DisablePathShortening makes the Conn proxy capabilities that it
+	// would otherwise hand back to the remote vat as the remote vat's
+	// own: capabilities imported from the remote vat, and promises for
+	// the results of calls made to it.  Such capabilities are exported
+	// as if they were local, so calls on them make a round trip through
+	// this vat instead of being delivered directly.  This only affects
+	// the capabilities this Conn sends; the remote vat may still
+	// shorten paths to this vat.  It is meant for debugging: if a bug
+	// goes away when set, the path shortening and embargo logic are
+	// likely involved.
+	DisablePathShortening bool
+
+	// StrictResolveOrder fixes the order in which the remote vat sees
+	// the resolution of a promise this Conn exported and the calls it
+	// made on that promise.  When a promise resolves, the Conn sends
+	// a Resolve message; any calls the remote vat made on the promise
+	// are then forwarded to what it resolved to, which may be a
+	// capability in the remote vat.  By default, calls that were
+	// waiting on the promise are forwarded as soon as it resolves, so
+	// the remote vat may receive them before the Resolve.  If set, the
+	// Conn holds calls it receives on an unresolved exported promise
+	// until the Resolve has been written to the transport, and
+	// forwards them afterwards in the order they arrived.  This
+	// matches the ordering that the protocol's embargo mechanism
+	// assumes.  Calls pipelined on answers that have not yet returned
+	// are delivered by the answer itself, so they are not held.
+	StrictResolveOrder bool
+
+	// PropagateCallPath makes errors from calls that fail in another
+	// vat record the path of calls that led to the failure, which
+	// CallPath returns.  Each Conn with this set adds a hop, naming
+	// its RemotePeerID and the method called, to the exceptions it
+	// receives, and passes the path of an error returned by a local
+	// call on to the remote vat in the exception's trace field.  For
+	// the full path, every Conn along the way must set it.  It is off
+	// by default because it tells callers about vats they may not
+	// otherwise know of.
+	PropagateCallPath bool
+
+	// Context, if not nil, bounds the lifetime of the Conn: once it is
+	// done, the Conn is shut down as if by calling Close.  Use Conn.Done
+	// to wait for the shutdown to complete.
+	Context context.Context
+
+	// Features names the protocol extensions that this vat supports,
+	// such as "compress/zstd", to exchange with the remote vat when
+	// either side calls Conn.Negotiate.  If empty, the Conn does not
+	// answer negotiation, so the remote vat sees it as supporting no
+	// extensions.  If set, BootstrapClient is wrapped to answer the
+	// negotiation call, and other calls are passed through to it.  A
+	// vat without a BootstrapClient only negotiates as the caller.
+	Features []string
+
+	// Manifest names capabilities that the remote vat can get all at
+	// once with Conn.Manifest, in the results of a single call that is
+	// pipelined on its bootstrap capability.  This saves clients that
+	// always need the same few objects a round trip for each of them.
+	// NewConn steals these references, and wraps BootstrapClient as
+	// for Features.  The manifest is only served along with a
+	// BootstrapClient; without one, NewConn releases its clients.
+	Manifest map[string]capnp.Client
+
+	// PrefetchManifest makes the Conn ask for the remote vat's manifest
+	// as soon as it is created, so that it has arrived or is on its way
+	// by the time Conn.Manifest is called.
+	PrefetchManifest bool
 }
 
 // Logger is used for logging by the RPC system. Each method logs
@@ -239,32 +497,136 @@ func NewConn(t Transport, opts *Options) *Conn {
 	c.sendRx = &sender.Rx
 	c.lk.sendTx = &sender.Tx
 
-	c.lk.answers = make(map[answerID]*ansent)
-	c.lk.imports = make(map[importID]*impent)
-
+	newTable := NewMemoryTable
+	c.maxTransform = DefaultMaxTransformDepth
 	if opts != nil {
 		c.bootstrap = opts.BootstrapClient
 		c.er = errReporter{opts.Logger}
 		c.abortTimeout = opts.AbortTimeout
+		c.bootstrapTimeout = opts.BootstrapTimeout
 		c.network = opts.Network
 		c.remotePeerID = opts.RemotePeerID
+		c.cacheBootstrap = opts.CacheBootstrap
+		c.maxSendQueue = int64(opts.MaxSendQueue)
+		c.overloadPolicy = opts.OverloadPolicy
+		if opts.MaxOutstandingQuestions > 0 {
+			c.questionSlots = make(chan struct{}, opts.MaxOutstandingQuestions)
+		}
+		c.maxReturnSize = opts.MaxReturnSize
+		if opts.MaxTransformDepth > 0 {
+			c.maxTransform = opts.MaxTransformDepth
+		}
+		c.readBudget = opts.ReadBudget
+		c.readLimiter = opts.ReadLimiter
+		c.clock = opts.Clock
+		c.strictProtocol = opts.StrictProtocol
+		c.onUnimplemented = opts.OnUnimplemented
+		c.noShortening = opts.DisablePathShortening
+		c.strictResolve = opts.StrictResolveOrder
+		c.callPath = opts.PropagateCallPath
+		if len(opts.Features) > 0 || opts.Manifest != nil {
+			c.features = append([]string(nil), opts.Features...)
+			if c.bootstrap.IsValid() {
+				c.bootstrap = capnp.NewClient(&reservedHook{
+					c:        c,
+					boot:     c.bootstrap,
+					manifest: opts.Manifest,
+				})
+			} else {
+				for _, cap := range opts.Manifest {
+					cap.Release()
+				}
+			}
+		}
+		if opts.DecisionLog != nil {
+			c.decisions = opts.DecisionLog
+			c.transport = recordingTransport{t, opts.DecisionLog}
+		}
+		if opts.NewTable != nil {
+			newTable = opts.NewTable
+		}
+	}
+	c.lk.questions = table[questionID, *question]{newTable(QuestionTable)}
+	c.lk.answers = table[answerID, *ansent]{newTable(AnswerTable)}
+	c.lk.exports = table[exportID, *expent]{newTable(ExportTable)}
+	c.lk.imports = table[importID, *impent]{newTable(ImportTable)}
+	if c.maxSendQueue > 0 {
+		c.sendSpace = make(chan struct{}, 1)
 	}
 	if c.abortTimeout == 0 {
 		c.abortTimeout = 100 * time.Millisecond
 	}
+	if c.clock == nil {
+		c.clock = clock.System
+	}
+
+	var authErr error
+	if opts != nil && opts.Authenticator != nil {
+		authErr = c.authenticate(opts)
+	}
+
+	if authErr == nil && opts != nil && opts.PeerQuotas != nil {
+		c.peerQuotas = opts.PeerQuotas
+		c.quota = c.peerQuotas.acquire(c.remotePeerID)
+	}
 
 	c.startBackgroundTasks()
+	if authErr != nil {
+		c.er.ReportError(c.shutdown(authErr))
+		return c
+	}
+	if opts != nil && opts.Context != nil {
+		go c.closeOnDone(opts.Context)
+	}
+	if opts != nil && opts.IdleExports != nil && opts.IdleExports.Threshold > 0 {
+		go c.watchIdleExports(*opts.IdleExports)
+	}
+	if opts != nil && opts.PrefetchManifest {
+		c.startManifest()
+	}
 
 	return c
 }
 
+// authenticate runs opts.Authenticator on the remote peer, recording
+// the AuthInfo in c.remotePeerID.  If the peer is rejected, it returns
+// the error to abort the connection with.  The cause is logged, but not
+// sent to the peer.
+func (c *Conn) authenticate(opts *Options) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	auth, err := opts.Authenticator(ctx, c.remotePeerID)
+	if err != nil {
+		c.er.Warn("rejected connection", "peer", c.remotePeerID, "error", err)
+		return rpcerr.Failed(ErrAuthFailed)
+	}
+	c.remotePeerID.Auth = auth
+	return nil
+}
+
+// closeOnDone closes c when ctx is done, unless c shuts down first.
+func (c *Conn) closeOnDone(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		c.er.ReportError(c.Close())
+	case <-c.closed:
+	}
+}
+
 func (c *Conn) startBackgroundTasks() {
 	// We use an errgroup to link the lifetime of background tasks
 	// to each other.
 	ctx, cancel := context.WithCancel(context.Background())
 	g, ctx := errgroup.WithContext(ctx)
 
-	c.bgctx = ctx
+	c.bgctx = context.WithValue(ctx, peerIDKey{}, c.remotePeerID)
+	c.bgctx = context.WithValue(c.bgctx, connKey{}, c)
+	if peer := c.remotePeerID.String(); peer != "" {
+		// Servers with Options.ProfilerLabels keep this label.
+		c.bgctx = pprof.WithLabels(c.bgctx, pprof.Labels("capnp.peer", peer))
+	}
 	c.lk.bgcancel = cancel
 
 	g.Go(c.send(ctx))
@@ -311,55 +673,125 @@ func (c *Conn) RemotePeerID() PeerID {
 
 // Bootstrap returns the remote vat's bootstrap interface.  This creates
 // a new client that the caller is responsible for releasing.
+//
+// If the Conn was created with Options.CacheBootstrap, Bootstrap returns
+// a new reference to the cached bootstrap client once one has resolved,
+// without sending a Bootstrap message.  Calls made before then each send
+// their own Bootstrap message.
 func (c *Conn) Bootstrap(ctx context.Context) (bc capnp.Client) {
-	return withLockedConn1(c, func(c *lockedConn) (bc capnp.Client) {
-		// Start a background task to prevent the conn from shutting down
-		// while sending the bootstrap message.
-		if !c.startTask() {
-			return capnp.ErrorClient(rpcerr.Disconnected(errors.New("connection closed")))
+	return withLockedConn1(c, func(c *lockedConn) capnp.Client {
+		if c.lk.remoteBootstrap.IsValid() {
+			return c.lk.remoteBootstrap.AddRef()
 		}
-		defer c.tasks.Done()
+		return c.sendBootstrap(ctx)
+	})
+}
 
-		q := c.newQuestion(capnp.Method{})
-		bc = q.p.Answer().Client().AddRef()
-		bc.AttachReleaser(func() {
-			q.p.ReleaseClients()
-			q.release()
-		})
+// RefreshBootstrap discards the cached bootstrap client, if any, and
+// sends a new Bootstrap message, returning the resulting client.  Clients
+// previously returned by Bootstrap are unaffected.  If the Conn was
+// created with Options.CacheBootstrap, the new client replaces the cached
+// one once it resolves.
+func (c *Conn) RefreshBootstrap(ctx context.Context) capnp.Client {
+	var old capnp.Client
+	defer func() { old.Release() }()
+	return withLockedConn1(c, func(c *lockedConn) capnp.Client {
+		old = c.lk.remoteBootstrap
+		c.lk.remoteBootstrap = capnp.Client{}
+		return c.sendBootstrap(ctx)
+	})
+}
 
-		c.sendMessage(ctx, func(m rpccp.Message) error {
-			boot, err := m.NewBootstrap()
-			if err == nil {
-				boot.SetQuestionId(uint32(q.id))
-			}
-			return err
+// sendBootstrap sends a Bootstrap message and returns the client for its
+// answer, caching it if Options.CacheBootstrap is set.  Callers MUST hold
+// c.lk.
+func (c *lockedConn) sendBootstrap(ctx context.Context) capnp.Client {
+	bc := c.newBootstrapQuestion(ctx)
+	if c.cacheBootstrap && c.startTask() {
+		go (*Conn)(c).cacheRemoteBootstrap(bc.AddRef())
+	}
+	return bc
+}
 
-		}, func(err error) {
-			if err != nil {
-				syncutil.With(&c.lk, func() {
-					c.lk.questions[q.id] = nil
-				})
-				q.p.Reject(exc.Annotate("rpc", "bootstrap", err))
-				syncutil.With(&c.lk, func() {
-					c.lk.questionID.remove(q.id)
-				})
-				return
-			}
+// newBootstrapQuestion sends a Bootstrap message and returns the client
+// for its answer.  Callers MUST hold c.lk.
+func (c *lockedConn) newBootstrapQuestion(ctx context.Context) (bc capnp.Client) {
+	// Start a background task to prevent the conn from shutting down
+	// while sending the bootstrap message.
+	if !c.startTask() {
+		return capnp.ErrorClient(rpcerr.Disconnected(errors.New("connection closed")))
+	}
+	defer c.tasks.Done()
+
+	q := c.newQuestion(capnp.Method{})
+	bc = q.p.Answer().Client().AddRef()
+	bc.AttachReleaser(func() {
+		q.p.ReleaseClients()
+		q.release()
+	})
 
-			c.tasks.Add(1)
-			go func() {
-				defer c.tasks.Done()
-				q.handleCancel(ctx)
-			}()
-		})
+	c.sendMessage(ctx, func(m rpccp.Message) error {
+		boot, err := m.NewBootstrap()
+		if err == nil {
+			boot.SetQuestionId(uint32(q.id))
+		}
+		return err
+
+	}, func(err error) {
+		if err != nil {
+			syncutil.With(&c.lk, func() {
+				c.lk.questions.remove(q.id)
+			})
+			q.p.Reject(exc.Annotate("rpc", "bootstrap", err))
+			syncutil.With(&c.lk, func() {
+				c.lk.questionID.remove(q.id)
+			})
+			return
+		}
+
+		c.tasks.Add(1)
+		go func() {
+			defer c.tasks.Done()
+			q.handleCancel(ctx)
+		}()
+	})
 
+	return
+}
+
+// cacheRemoteBootstrap waits for bc to resolve and, if it resolved to a
+// capability rather than an error, stores it as the cached bootstrap
+// client.  It takes ownership of bc.
+func (c *Conn) cacheRemoteBootstrap(bc capnp.Client) {
+	defer c.tasks.Done()
+	defer func() { bc.Release() }()
+
+	if err := bc.Resolve(c.bgctx); err != nil {
+		return
+	}
+	snapshot := bc.Snapshot()
+	_, failed := snapshot.Brand().Value.(error)
+	snapshot.Release()
+	if failed {
 		return
+	}
+	c.withLocked(func(c *lockedConn) {
+		if !c.lk.closing && !c.lk.remoteBootstrap.IsValid() {
+			c.lk.remoteBootstrap, bc = bc, capnp.Client{}
+		}
 	})
 }
 
 // Close sends an abort to the remote vat and closes the underlying
 // transport.
 func (c *Conn) Close() error {
+	if c.decisions != nil {
+		c.withLocked(func(c *lockedConn) {
+			if !c.lk.closing {
+				c.decide(DecisionClose, 0)
+			}
+		})
+	}
 	return c.shutdown(exc.Exception{ // NOTE:  omit "rpc" prefix
 		Type:  exc.Failed,
 		Cause: ErrConnClosed,
@@ -367,7 +799,8 @@ func (c *Conn) Close() error {
 }
 
 // Done returns a channel that is closed after the connection is
-// shut down.
+// shut down, whether by Close, by the remote vat, by an error or by
+// the end of Options.Context.  By then the transport has been closed.
 func (c *Conn) Done() <-chan struct{} {
 	return c.closed
 }
@@ -408,6 +841,9 @@ func (c *Conn) shutdown(abortErr error) (err error) {
 			c.release(dq)
 		})
 		dq.Run()
+		if c.quota != nil {
+			c.peerQuotas.release(c.quota)
+		}
 		c.abort(abortErr)
 		close(readyForClose)
 	}
@@ -420,15 +856,18 @@ func (c *Conn) shutdown(abortErr error) (err error) {
 // Does not wait for tasks to finish shutting down.
 // Called by 'shutdown'.  Callers MUST hold c.lk.
 func (c *lockedConn) cancelTasks() {
-	for _, a := range c.lk.answers {
-		if a != nil && a.cancel != nil {
+	c.lk.answers.each(func(_ answerID, a *ansent) {
+		if a.cancel != nil {
 			a.cancel()
 		}
-	}
+	})
 }
 
 // caller MUST NOT hold c.lk
 func (c *Conn) drainQueue() {
+	for !c.sendq.empty() {
+		c.sendq.pop().Abort(ErrConnClosed)
+	}
 	for {
 		pending, ok := c.sendRx.TryRecv()
 		if !ok {
@@ -442,16 +881,14 @@ func (c *Conn) drainQueue() {
 // Clear all tables, and arrange for the deferred.Queue to release exported clients
 // and unfinished answers. Called by 'shutdown'.  Caller MUST hold c.lk.
 func (c *lockedConn) release(dq *deferred.Queue) {
-	exports := c.lk.exports
+	exports := c.lk.exports.clear()
 	embargoes := c.lk.embargoes
-	answers := c.lk.answers
-	questions := c.lk.questions
-	c.lk.imports = nil
-	c.lk.exports = nil
+	answers := c.lk.answers.clear()
+	questions := c.lk.questions.clear()
+	c.lk.imports.clear()
 	c.lk.embargoes = nil
-	c.lk.questions = nil
-	c.lk.answers = nil
 
+	c.releaseQuota(exports, answers)
 	c.releaseBootstrap(dq)
 	c.releaseExports(dq, exports)
 	c.liftEmbargoes(dq, embargoes)
@@ -460,9 +897,38 @@ func (c *lockedConn) release(dq *deferred.Queue) {
 
 }
 
+// releaseQuota gives back the exports and answers charged to c.quota by
+// the entries cleared from c's tables.
+func (c *lockedConn) releaseQuota(exports []*expent, answers []*ansent) {
+	if c.quota == nil {
+		return
+	}
+	nexports, nanswers := 0, 0
+	for _, e := range exports {
+		if e != nil {
+			nexports++
+		}
+	}
+	for _, a := range answers {
+		if a != nil && a.quotaCharged {
+			a.quotaCharged = false
+			nanswers++
+		}
+	}
+	c.quota.remove(nexports, nanswers)
+}
+
 func (c *lockedConn) releaseBootstrap(dq *deferred.Queue) {
 	dq.Defer(c.bootstrap.Release)
+	dq.Defer(c.lk.remoteBootstrap.Release)
 	c.bootstrap = capnp.Client{}
+	c.lk.remoteBootstrap = capnp.Client{}
+	if m := c.lk.manifest; m != nil {
+		for _, cap := range m.caps {
+			dq.Defer(cap.Release)
+		}
+		m.caps = nil
+	}
 }
 
 func (c *lockedConn) releaseExports(dq *deferred.Queue, exports []*expent) {
@@ -487,11 +953,11 @@ func (c *lockedConn) liftEmbargoes(dq *deferred.Queue, embargoes []*embargo) {
 	}
 }
 
-func (c *lockedConn) releaseAnswers(dq *deferred.Queue, answers map[answerID]*ansent) {
+func (c *lockedConn) releaseAnswers(dq *deferred.Queue, answers []*ansent) {
 	for _, a := range answers {
 		if a != nil {
-			for _, s := range a.returner.resultsCapTable {
-				dq.Defer(s.Release)
+			for i := range a.returner.resultsCapTable {
+				dq.Defer(a.returner.resultsCapTable[i].Release)
 			}
 			if a.returner.msgReleaser != nil {
 				dq.Defer(a.returner.msgReleaser.Decr)
@@ -540,12 +1006,28 @@ func (c *Conn) abort(abortErr error) {
 func (c *Conn) send(ctx context.Context) func() error {
 	return c.backgroundTask(func() error {
 		for {
-			async, err := c.sendRx.Recv(ctx)
-			if err != nil {
+			if err := ctx.Err(); err != nil {
 				return err
 			}
+			if c.sendq.empty() {
+				async, err := c.sendRx.Recv(ctx)
+				if err != nil {
+					return err
+				}
+				c.sendq.push(async)
+			}
 
-			async.Send()
+			// Pick up everything else that has been enqueued, so that
+			// higher priority messages can go ahead of it.
+			for {
+				async, ok := c.sendRx.TryRecv()
+				if !ok {
+					break
+				}
+				c.sendq.push(async)
+			}
+
+			c.sendq.pop().Send()
 		}
 	})
 }
@@ -562,6 +1044,15 @@ func (c *Conn) receive(ctx context.Context) func() error {
 		incoming := make(chan incomingMessage)
 		go c.reader(ctx, incoming)
 
+		// Fires if the remote vat does not send its first message
+		// within c.bootstrapTimeout.
+		var firstMsgTimeout <-chan time.Time
+		if c.bootstrapTimeout > 0 {
+			timer := c.clock.NewTimer(c.bootstrapTimeout)
+			defer timer.Stop()
+			firstMsgTimeout = timer.Chan()
+		}
+
 		var in transport.IncomingMessage
 		for {
 			select {
@@ -571,6 +1062,10 @@ func (c *Conn) receive(ctx context.Context) func() error {
 					return fmt.Errorf("reader: %w", inMsg.err)
 				}
 				in = inMsg.IncomingMessage
+				firstMsgTimeout = nil
+
+			case <-firstMsgTimeout:
+				return rpcerr.Failed(ErrBootstrapTimeout)
 
 			case <-ctx.Done():
 				return nil
@@ -621,19 +1116,28 @@ func (c *Conn) receive(ctx context.Context) func() error {
 					return fmt.Errorf("handle Resolve: %w", err)
 				}
 
-			case rpccp.Message_Which_accept, rpccp.Message_Which_provide:
-				if c.network != nil {
-					panic("TODO: 3PH")
-				}
-				fallthrough
-
 			default:
+				// This includes accept and provide: three-party
+				// handoff is not implemented yet.
 				c.handleUnknownMessageType(ctx, in)
 			}
 		}
 	})
 }
 
+// readError reports err, which occurred reading a message from the
+// remote vat, and returns nil so that the connection carries on.  If
+// Options.ReadBudget has run out, no more messages can be read, so it
+// returns an error that aborts the connection instead.
+func (c *Conn) readError(what string, err error) error {
+	err = exc.WrapError(what, err)
+	if errors.Is(err, capnp.ErrReadBudgetExhausted) {
+		return rpcerr.New(exc.Overloaded, err)
+	}
+	c.er.ReportError(err)
+	return nil
+}
+
 func (c *Conn) handleAbort(in transport.IncomingMessage) {
 	defer in.Release()
 
@@ -678,6 +1182,7 @@ func (c *Conn) handleUnimplemented(in transport.IncomingMessage) error {
 			case rpccp.CapDescriptor_Which_senderPromise:
 				id = exportID(desc.SenderPromise())
 			default:
+				c.deviation(DeviationIgnoredUnimplemented, "which", desc.Which())
 				return nil
 			}
 			dq := &deferred.Queue{}
@@ -689,6 +1194,7 @@ func (c *Conn) handleUnimplemented(in transport.IncomingMessage) error {
 		}
 	}
 	// For other cases we should just ignore the message.
+	c.deviation(DeviationIgnoredUnimplemented, "which", msg.Which())
 	return nil
 }
 
@@ -697,8 +1203,7 @@ func (c *Conn) handleBootstrap(in transport.IncomingMessage) error {
 
 	bootstrap, err := in.Message().Bootstrap()
 	if err != nil {
-		c.er.ReportError(exc.WrapError("read bootstrap", err))
-		return nil
+		return c.readError("read bootstrap", err)
 	}
 
 	dq := &deferred.Queue{}
@@ -711,14 +1216,10 @@ func (c *Conn) handleBootstrap(in transport.IncomingMessage) error {
 		},
 	}
 
-	ans.returner.ret, ans.sendMsg, ans.returner.msgReleaser, err = c.newReturn()
-	if err == nil {
-		ans.returner.ret.SetAnswerId(uint32(ans.returner.id))
-		ans.returner.ret.SetReleaseParamCaps(false)
-	}
+	ans.returner.ret, ans.sendMsg, ans.returner.msgReleaser, err = c.newReturn(ans.returner.id)
 
 	c.withLocked(func(c *lockedConn) {
-		if c.lk.answers[ans.returner.id] != nil {
+		if c.lk.answers.get(ans.returner.id) != nil {
 			dq.Defer(ans.returner.msgReleaser.Decr)
 			err = rpcerr.Failed(errors.New("incoming bootstrap: answer ID " + str.Utod(ans.returner.id) + " reused"))
 			return
@@ -726,12 +1227,12 @@ func (c *Conn) handleBootstrap(in transport.IncomingMessage) error {
 
 		if err != nil {
 			err = rpcerr.Annotate(err, "incoming bootstrap")
-			c.lk.answers[ans.returner.id] = errorAnswer((*Conn)(c), ans.returner.id, err)
+			c.lk.answers.set(ans.returner.id, errorAnswer((*Conn)(c), ans.returner.id, err))
 			c.er.ReportError(err)
 			return
 		}
 
-		c.lk.answers[ans.returner.id] = &ans
+		c.lk.answers.set(ans.returner.id, &ans)
 		if !c.bootstrap.IsValid() {
 			ans.sendException(dq, exc.New(exc.Failed, "", "vat does not expose a public/bootstrap interface"))
 			return
@@ -755,8 +1256,7 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 	call, err := in.Message().Call()
 	if err != nil {
 		in.Release()
-		c.er.ReportError(exc.WrapError("read call", err))
-		return nil
+		return c.readError("read call", err)
 	}
 
 	dq := &deferred.Queue{}
@@ -770,26 +1270,9 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 		c.er.ReportError(errors.New("incoming call: results destination is not caller"))
 
 		c.withLocked(func(c *lockedConn) {
-			c.sendMessage(ctx, func(m rpccp.Message) error {
-				defer in.Release()
-
-				mm, err := m.NewUnimplemented()
-				if err != nil {
-					return rpcerr.Annotate(err, "incoming call: send unimplemented")
-				}
-
-				if err = mm.SetCall(call); err != nil {
-					return rpcerr.Annotate(err, "incoming call: send unimplemented")
-				}
-
-				return nil
-			}, func(err error) {
-				if err != nil {
-					c.er.ReportError(rpcerr.Annotate(err, "incoming call: send unimplemented"))
-				}
-			})
+			c.sendUnimplemented(ctx, dq, in.Message())
 		})
-
+		in.Release()
 		return nil
 	}
 
@@ -798,7 +1281,7 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 		parseErr error
 	)
 	c.withLocked(func(c *lockedConn) {
-		if c.lk.answers[id] != nil {
+		if c.lk.answers.get(id) != nil {
 			dq.Defer(in.Release)
 			err = rpcerr.Failed(errors.New("incoming call: answer ID " + str.Utod(id) + "reused"))
 			return
@@ -811,18 +1294,16 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 	}
 
 	// Create return message.
-	ret, send, retReleaser, err := c.newReturn()
+	ret, send, retReleaser, err := c.newReturn(id)
 	if err != nil {
 		err = rpcerr.Annotate(err, "incoming call")
 		syncutil.With(&c.lk, func() {
-			c.lk.answers[id] = errorAnswer(c, id, err)
+			c.lk.answers.set(id, errorAnswer(c, id, err))
 		})
 		c.er.ReportError(err)
 		in.Release()
 		return nil
 	}
-	ret.SetAnswerId(uint32(id))
-	ret.SetReleaseParamCaps(false)
 
 	// Find target and start call.
 	ans := &ansent{
@@ -835,7 +1316,7 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 		sendMsg: send,
 	}
 	return withLockedConn1(c, func(c *lockedConn) error {
-		c.lk.answers[id] = ans
+		c.lk.answers.set(id, ans)
 		if parseErr != nil {
 			parseErr = rpcerr.Annotate(parseErr, "incoming call")
 			ans.sendException(dq, parseErr)
@@ -845,7 +1326,15 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 			})
 			return nil
 		}
+		if !c.quota.addAnswer() {
+			ans.sendException(dq, ExcQuotaExceeded)
+			dq.Defer(in.Release)
+			return nil
+		}
+		ans.quotaCharged = c.quota != nil
 
+		ans.returner.method = p.method
+		ans.returner.start = c.clock.Now()
 		recv := capnp.Recv{
 			Args:        p.args,
 			Method:      p.method,
@@ -864,19 +1353,29 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 					retReleaser.Decr()
 					in.Release()
 				})
-				return rpcerr.Failed(errors.New("incoming call: unknown export ID " + str.Utod(id)))
+				return rpcerr.Failed(errors.New("incoming call to " + p.method.String() + ": unknown export ID " + str.Utod(id)))
 			}
+			ent.lastCall = c.clock.Now()
+			ent.idleReported = false
 			c.tasks.Add(1) // will be finished by answer.Return
 			var callCtx context.Context
 			callCtx, ans.cancel = context.WithCancel(c.bgctx)
 			pcall := newPromisedPipelineCaller()
 			ans.setPipelineCaller(p.method, pcall)
 			dq.Defer(func() {
-				pcall.resolve(ent.snapshot.Recv(callCtx, recv))
+				ent.gate.deliver(ent.snapshot, func(tgt capnp.ClientSnapshot) {
+					pcall.resolve(tgt.Recv(callCtx, recv))
+				})
 			})
 			return nil
 		case rpccp.MessageTarget_Which_promisedAnswer:
-			tgtAns := c.lk.answers[p.target.promisedAnswer]
+			if p.target.promisedAnswer == id {
+				// The call would wait for its own results.
+				ans.sendException(dq, ExcPipelineLoop)
+				dq.Defer(in.Release)
+				return nil
+			}
+			tgtAns := c.lk.answers.get(p.target.promisedAnswer)
 			if tgtAns == nil || tgtAns.flags.Contains(finishReceived) {
 				ans.returner.ret = rpccp.Return{}
 				ans.sendMsg = nil
@@ -933,9 +1432,12 @@ func (c *Conn) handleCall(ctx context.Context, in transport.IncomingMessage) err
 				callCtx, ans.cancel = context.WithCancel(c.bgctx)
 				pcall := newPromisedPipelineCaller()
 				ans.setPipelineCaller(p.method, pcall)
+				gate := c.findResolveGate(tgt)
 				dq.Defer(func() {
 					defer tgt.Release()
-					pcall.resolve(tgt.Recv(callCtx, recv))
+					gate.deliver(tgt, func(tgt capnp.ClientSnapshot) {
+						pcall.resolve(tgt.Recv(callCtx, recv))
+					})
 				})
 			} else {
 				// Results not ready, use pipeline caller.
@@ -1020,6 +1522,10 @@ func (c *lockedConn) parseCall(dq *deferred.Queue, p *parsedCall, call rpccp.Cal
 		InterfaceID: call.InterfaceId(),
 		MethodID:    call.MethodId(),
 	}
+	if info, ok := capnp.LookupMethod(p.method.InterfaceID, p.method.MethodID); ok {
+		// Pick up names for error messages and logs.
+		p.method = info.Method
+	}
 	payload, err := call.Params()
 	if err != nil {
 		return rpcerr.WrapFailed("read params", err)
@@ -1033,13 +1539,13 @@ func (c *lockedConn) parseCall(dq *deferred.Queue, p *parsedCall, call rpccp.Cal
 	if err != nil {
 		return rpcerr.WrapFailed("read target", err)
 	}
-	if err := parseMessageTarget(&p.target, tgt); err != nil {
+	if err := parseMessageTarget(&p.target, tgt, c.maxTransform); err != nil {
 		return err
 	}
 	return nil
 }
 
-func parseMessageTarget(pt *parsedMessageTarget, tgt rpccp.MessageTarget) error {
+func parseMessageTarget(pt *parsedMessageTarget, tgt rpccp.MessageTarget, maxDepth int) error {
 	switch pt.which = tgt.Which(); pt.which {
 	case rpccp.MessageTarget_Which_importedCap:
 		pt.importedCap = exportID(tgt.ImportedCap())
@@ -1053,7 +1559,7 @@ func parseMessageTarget(pt *parsedMessageTarget, tgt rpccp.MessageTarget) error
 		if err != nil {
 			return rpcerr.WrapFailed("read target transform", err)
 		}
-		pt.transform, err = parseTransform(opList)
+		pt.transform, err = parseTransform(opList, maxDepth)
 		if err != nil {
 			return rpcerr.Annotate(err, "read target transform")
 		}
@@ -1064,7 +1570,12 @@ func parseMessageTarget(pt *parsedMessageTarget, tgt rpccp.MessageTarget) error
 	return nil
 }
 
-func parseTransform(list rpccp.PromisedAnswer_Op_List) ([]capnp.PipelineOp, error) {
+// parseTransform reads a promised answer transform, failing if it has
+// more than maxDepth operations.
+func parseTransform(list rpccp.PromisedAnswer_Op_List, maxDepth int) ([]capnp.PipelineOp, error) {
+	if list.Len() > maxDepth {
+		return nil, ExcTransformTooDeep
+	}
 	ops := make([]capnp.PipelineOp, 0, list.Len())
 	for i := 0; i < list.Len(); i++ {
 		li := list.At(i)
@@ -1087,8 +1598,7 @@ func (c *Conn) handleReturn(ctx context.Context, in transport.IncomingMessage) e
 	ret, err := in.Message().Return()
 	if err != nil {
 		in.Release()
-		c.er.ReportError(exc.WrapError("read return", err))
-		return nil
+		return c.readError("read return", err)
 	}
 
 	dq := &deferred.Queue{}
@@ -1101,23 +1611,17 @@ func (c *Conn) handleReturn(ctx context.Context, in transport.IncomingMessage) e
 	return withLockedConn1(c, func(c *lockedConn) error {
 
 		qid := questionID(ret.AnswerId())
-		if uint32(qid) >= uint32(len(c.lk.questions)) {
-			dq.Defer(in.Release)
-			return rpcerr.Failed(errors.New(
-				"incoming return: question " + str.Utod(qid) + " does not exist",
-			))
-		}
 		// Pop the question from the table.  Receiving the Return message
 		// will always remove the question from the table, because it's the
 		// only time the remote vat will use it.
-		q := c.lk.questions[qid]
-		c.lk.questions[qid] = nil
+		q := c.lk.questions.get(qid)
 		if q == nil {
 			dq.Defer(in.Release)
 			return rpcerr.Failed(errors.New(
 				"incoming return: question " + str.Utod(qid) + " does not exist",
 			))
 		}
+		c.removeQuestion(q)
 		canceled := q.flags.Contains(finished)
 		q.flags |= finished
 		if canceled {
@@ -1145,15 +1649,26 @@ func (c *Conn) handleReturn(ctx context.Context, in transport.IncomingMessage) e
 			}
 			return nil
 		}
-		pr := c.parseReturn(dq, ret, q.called) // fills in CapTable and adds imports to local vat
+		pr := c.parseReturn(dq, ret, q) // fills in CapTable and adds imports to local vat
 		if pr.parseFailed {
 			c.er.ReportError(rpcerr.Annotate(pr.err, "incoming return"))
 		}
+		if pr.unimplemented {
+			c.sendUnimplemented(ctx, dq, in.Message())
+		}
 
 		if pr.err == nil {
-			// The result of the message contains actual data (not just
-			// an error), so we save the ReleaseFunc for later:
-			q.release = in.Release
+			if res, release, ok := q.inlineResults(pr.result); ok {
+				// The results were copied, so the message is no
+				// longer needed.
+				pr.result = res
+				q.release = release
+				dq.Defer(in.Release)
+			} else {
+				// The result of the message contains actual data (not just
+				// an error), so we save the ReleaseFunc for later:
+				q.release = in.Release
+			}
 		}
 		// We're going to potentially block fulfilling some promises so fork
 		// off a goroutine to avoid blocking the receive loop.
@@ -1253,7 +1768,7 @@ func (c *Conn) handleReturn(ctx context.Context, in transport.IncomingMessage) e
 	})
 }
 
-func (c *lockedConn) parseReturn(dq *deferred.Queue, ret rpccp.Return, called [][]capnp.PipelineOp) parsedReturn {
+func (c *lockedConn) parseReturn(dq *deferred.Queue, ret rpccp.Return, q *question) parsedReturn {
 	switch w := ret.Which(); w {
 	case rpccp.Return_Which_results:
 		r, err := ret.Results()
@@ -1268,7 +1783,7 @@ func (c *lockedConn) parseReturn(dq *deferred.Queue, ret rpccp.Return, called []
 		var embargoCaps uintSet
 		var disembargoes []senderLoopback
 		mtab := ret.Message().CapTable()
-		for _, xform := range called {
+		for _, xform := range q.called {
 			p2, _ := capnp.Transform(content, xform)
 			iface := p2.Interface()
 			i := iface.Capability()
@@ -1302,6 +1817,12 @@ func (c *lockedConn) parseReturn(dq *deferred.Queue, ret rpccp.Return, called []
 		if err != nil {
 			return parsedReturn{err: rpcerr.WrapFailed("parse return", err), parseFailed: true}
 		}
+		if c.callPath {
+			return parsedReturn{err: &exc.Exception{
+				Type:  exc.Type(e.Type()),
+				Cause: c.callPathException(e, reason, q.method),
+			}}
+		}
 		return parsedReturn{err: exc.New(exc.Type(e.Type()), "", reason)}
 	case rpccp.Return_Which_acceptFromThirdParty:
 		// TODO: 3PH. Can wait until after the MVP, because we can keep
@@ -1329,8 +1850,7 @@ func (c *Conn) handleFinish(ctx context.Context, in transport.IncomingMessage) e
 
 	fin, err := in.Message().Finish()
 	if err != nil {
-		c.er.ReportError(exc.WrapError("read finish", err))
-		return nil
+		return c.readError("read finish", err)
 	}
 
 	id := answerID(fin.QuestionId())
@@ -1339,13 +1859,15 @@ func (c *Conn) handleFinish(ctx context.Context, in transport.IncomingMessage) e
 	dq := &deferred.Queue{}
 	defer dq.Run()
 	return withLockedConn1(c, func(c *lockedConn) error {
-		ans := c.lk.answers[id]
+		ans := c.lk.answers.get(id)
 		if ans == nil {
+			(*Conn)(c).deviation(DeviationUnknownFinish, "id", id)
 			return rpcerr.Failed(errors.New(
 				"incoming finish: unknown answer ID " + str.Utod(id),
 			))
 		}
 		if ans.flags.Contains(finishReceived) {
+			(*Conn)(c).deviation(DeviationUnknownFinish, "id", id)
 			return rpcerr.Failed(errors.New(
 				"incoming finish: answer ID " + str.Utod(id) + " already received finish",
 			))
@@ -1420,14 +1942,14 @@ func (c *lockedConn) recvCap(d rpccp.CapDescriptor) (capnp.Client, error) {
 		if err != nil {
 			return capnp.Client{}, rpcerr.WrapFailed("receive capability: reading promised answer transform", err)
 		}
-		transform, err := parseTransform(rawTransform)
+		transform, err := parseTransform(rawTransform, c.maxTransform)
 		if err != nil {
 			return capnp.Client{}, rpcerr.WrapFailed("read target transform", err)
 		}
 
 		id := answerID(promisedAnswer.QuestionId())
-		ans, ok := c.lk.answers[id]
-		if !ok {
+		ans := c.lk.answers.get(id)
+		if ans == nil {
 			return capnp.Client{}, rpcerr.Failed(errors.New(
 				"receive capability: no such question id: " + str.Utod(id),
 			))
@@ -1579,8 +2101,7 @@ func (c *Conn) handleRelease(ctx context.Context, in transport.IncomingMessage)
 
 	rel, err := in.Message().Release()
 	if err != nil {
-		c.er.ReportError(exc.WrapError("read release", err))
-		return nil
+		return c.readError("read release", err)
 	}
 
 	id := exportID(rel.Id())
@@ -1601,8 +2122,7 @@ func (c *Conn) handleDisembargo(ctx context.Context, in transport.IncomingMessag
 	d, err := in.Message().Disembargo()
 	if err != nil {
 		in.Release()
-		c.er.ReportError(exc.WrapError("read disembargo", err))
-		return nil
+		return c.readError("read disembargo", err)
 	}
 
 	dtarget, err := d.Target()
@@ -1612,7 +2132,7 @@ func (c *Conn) handleDisembargo(ctx context.Context, in transport.IncomingMessag
 	}
 
 	var tgt parsedMessageTarget
-	if err := parseMessageTarget(&tgt, dtarget); err != nil {
+	if err := parseMessageTarget(&tgt, dtarget, c.maxTransform); err != nil {
 		in.Release()
 		return rpcerr.Annotate(err, "incoming disembargo")
 	}
@@ -1742,27 +2262,16 @@ func (c *Conn) handleDisembargo(ctx context.Context, in transport.IncomingMessag
 			})
 		})
 
-	case rpccp.Disembargo_context_Which_accept, rpccp.Disembargo_context_Which_provide:
-		if c.network != nil {
-			panic("TODO: 3PH")
-		}
-		fallthrough
 	default:
+		// This includes accept and provide: three-party handoff is not
+		// implemented yet.
 		c.er.ReportError(errors.New("incoming disembargo: context " + d.Context().Which().String() + " not implemented"))
+		dq := &deferred.Queue{}
+		defer dq.Run()
 		c.withLocked(func(c *lockedConn) {
-			c.sendMessage(ctx, func(m rpccp.Message) (err error) {
-				if m, err = m.NewUnimplemented(); err == nil {
-					err = m.SetDisembargo(d)
-				}
-
-				return
-			}, func(err error) {
-				defer in.Release()
-				if err != nil {
-					c.er.ReportError(rpcerr.Annotate(err, "incoming disembargo: send unimplemented"))
-				}
-			})
+			c.sendUnimplemented(ctx, dq, in.Message())
 		})
+		in.Release()
 	}
 
 	return nil
@@ -1772,7 +2281,7 @@ func (c *lockedConn) getAnswerSnapshot(
 	id answerID,
 	transform []capnp.PipelineOp,
 ) (_ capnp.ClientSnapshot, err error) {
-	ans := c.lk.answers[id]
+	ans := c.lk.answers.get(id)
 	if ans == nil {
 		err = rpcerr.Failed(errors.New(
 			"incoming disembargo: unknown answer ID " +
@@ -1836,25 +2345,31 @@ func (c *Conn) handleResolve(ctx context.Context, in transport.IncomingMessage)
 	resolve, err := in.Message().Resolve()
 	if err != nil {
 		in.Release()
-		c.er.ReportError(exc.WrapError("read resolve", err))
-		return nil
+		return c.readError("read resolve", err)
 	}
 
 	promiseID := importID(resolve.PromiseId())
 	err = withLockedConn1(c, func(c *lockedConn) error {
-		imp, ok := c.lk.imports[promiseID]
-		if !ok {
+		imp := c.lk.imports.get(promiseID)
+		if imp == nil {
+			(*Conn)(c).deviation(DeviationUnknownResolve, "id", promiseID)
 			return errors.New(
 				"incoming resolve: no such import ID: " + str.Utod(promiseID),
 			)
 		}
 		if imp.resolver == nil {
+			(*Conn)(c).deviation(DeviationUnknownResolve, "id", promiseID)
 			return errors.New(
 				"incoming resolve: import ID " +
 					str.Utod(promiseID) +
-					"is not a promise",
+					" is not a promise",
 			)
 		}
+		if imp.resolved {
+			(*Conn)(c).deviation(DeviationRedundantResolve, "id", promiseID)
+			return nil
+		}
+		imp.resolved = true
 		switch resolve.Which() {
 		case rpccp.Resolve_Which_cap:
 			desc, err := resolve.Cap()
@@ -1912,16 +2427,35 @@ func (c *Conn) handleResolve(ctx context.Context, in transport.IncomingMessage)
 func (c *Conn) handleUnknownMessageType(ctx context.Context, in transport.IncomingMessage) {
 	err := errors.New("unknown message type " + in.Message().Which().String() + " from remote")
 	c.er.ReportError(err)
+	c.deviation(DeviationUnknownMessage, "which", in.Message().Which())
 
+	dq := &deferred.Queue{}
+	defer dq.Run()
 	c.withLocked(func(c *lockedConn) {
-		c.sendMessage(ctx, func(m rpccp.Message) error {
-			defer in.Release()
-			if err := m.SetUnimplemented(in.Message()); err != nil {
-				return rpcerr.Annotate(err, "send unimplemented")
-			}
-			return nil
-		}, nil)
+		c.sendUnimplemented(ctx, dq, in.Message())
 	})
+	in.Release()
+}
+
+// sendUnimplemented echoes msg back to the remote vat in an
+// Unimplemented message, as the protocol requires for messages that the
+// Conn does not handle.  msg is copied, so the caller may release it
+// as soon as sendUnimplemented returns.  Options.OnUnimplemented is
+// called from dq, once c.lk has been released.
+func (c *lockedConn) sendUnimplemented(ctx context.Context, dq *deferred.Queue, msg rpccp.Message) {
+	which := msg.Which()
+	c.sendMessage(ctx, func(m rpccp.Message) error {
+		return m.SetUnimplemented(msg)
+	}, func(err error) {
+		if err != nil {
+			c.er.ReportError(rpcerr.Annotate(err, "send unimplemented for "+which.String()))
+		}
+	})
+	if c.onUnimplemented != nil {
+		dq.Defer(func() {
+			c.onUnimplemented(which)
+		})
+	}
 }
 
 // startTask increments c.tasks if c is not shutting down.
@@ -1945,6 +2479,9 @@ func (c *lockedConn) startTask() (ok bool) {
 //
 // onSent will be called without holding c.lk.  Callers of
 // sendMessage MAY wish to reacquire the c.lk within the onSent.
+//
+// Call messages are scheduled with the Priority attached to ctx,
+// if any.
 func (c *lockedConn) sendMessage(ctx context.Context, build func(rpccp.Message) error, onSent func(error)) {
 	outMsg, err := c.transport.NewMessage()
 	send := outMsg.Send
@@ -1963,6 +2500,32 @@ func (c *lockedConn) sendMessage(ctx context.Context, build func(rpccp.Message)
 		}
 	}
 
+	var (
+		priority = PriorityNormal
+		target   sendTarget
+		targeted bool
+		question sendTarget
+		asks     bool
+	)
+	if err == nil {
+		target, targeted = messageTarget(outMsg.Message())
+		question, asks = messageQuestion(outMsg.Message())
+		if outMsg.Message().Which() == rpccp.Message_Which_call {
+			priority = PriorityFromContext(ctx)
+		}
+		// The message is encoded by the send goroutine.
+		outMsg.Message().Message().Handoff()
+	}
+
+	if c.maxSendQueue > 0 {
+		c.sendQueued.Add(1)
+		oldRelease := release
+		release = func() {
+			oldRelease()
+			(*Conn)(c).sendDequeued()
+		}
+	}
+
 	oldSend := send
 	send = func() error {
 		if ctx.Err() != nil {
@@ -1972,9 +2535,14 @@ func (c *lockedConn) sendMessage(ctx context.Context, build func(rpccp.Message)
 	}
 
 	c.lk.sendTx.Send(asyncSend{
-		release: release,
-		send:    send,
-		onSent:  onSent,
+		release:  release,
+		send:     send,
+		onSent:   onSent,
+		priority: priority,
+		target:   target,
+		targeted: targeted,
+		question: question,
+		asks:     asks,
 	})
 }
 
@@ -1985,6 +2553,12 @@ func (c *lockedConn) sendMessage(ctx context.Context, build func(rpccp.Message)
 func (c *Conn) reader(ctx context.Context, in chan<- incomingMessage) {
 	for {
 		inMsg, err := c.transport.RecvMessage()
+		if err == nil && c.readBudget != nil {
+			inMsg.Message().Message().ReadBudget = c.readBudget
+		}
+		if err == nil && c.readLimiter != nil {
+			inMsg.Message().Message().ReadLimiter = c.readLimiter
+		}
 		select {
 		case in <- incomingMessage{IncomingMessage: inMsg, err: err}:
 		case <-ctx.Done():
@@ -2006,6 +2580,13 @@ type asyncSend struct {
 	send    func() error
 	onSent  func(error)
 	release capnp.ReleaseFunc
+
+	// Scheduling information; see sendQueue.
+	priority Priority
+	target   sendTarget
+	targeted bool
+	question sendTarget // created by the message, if asks is set
+	asks     bool
 }
 
 func (as asyncSend) Abort(err error) {
@@ -0,0 +1,246 @@
+// Package rpctest provides plain Go representations of Cap'n Proto RPC
+// protocol messages, and helpers to send and receive them over a
+// transport.
+//
+// It is meant for tests that drive one end of a connection by hand, such
+// as conformance tests for custom transports or for other
+// implementations of the protocol.  The types mirror the structs in
+// rpc.capnp and are converted with package pogs, so only the fields that
+// tests commonly need are present; fields that are missing are left at
+// their default values when sending and ignored when receiving.
+package rpctest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/pogs"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+	"capnproto.org/go/capnp/v3/schemas"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func init() {
+	// pogs needs the schema of rpc.capnp to convert messages.
+	rpccp.RegisterSchema(schemas.DefaultRegistry)
+}
+
+// Message is an RPC protocol message.  Which selects the field that is
+// set.
+type Message struct {
+	Which         rpccp.Message_Which
+	Unimplemented *Message
+	Abort         *Exception
+	Bootstrap     *Bootstrap
+	Call          *Call
+	Return        *Return
+	Finish        *Finish
+	Resolve       *Resolve
+	Release       *Release
+	Disembargo    *Disembargo
+}
+
+// SendMessage sends msg over t.
+func SendMessage(ctx context.Context, t transport.Transport, msg *Message) error {
+	outMsg, err := t.NewMessage()
+	if err != nil {
+		return fmt.Errorf("send message: %v", err)
+	}
+	defer outMsg.Release()
+	if err := pogs.Insert(rpccp.Message_TypeID, capnp.Struct(outMsg.Message()), msg); err != nil {
+		return fmt.Errorf("send message: %v", err)
+	}
+	if err := outMsg.Send(); err != nil {
+		return fmt.Errorf("send message: %v", err)
+	}
+	return nil
+}
+
+// RecvMessage receives the next message from t.  The caller must call
+// the returned function once it is done with the message, since
+// payloads may point into the received message.
+func RecvMessage(ctx context.Context, t transport.Transport) (*Message, capnp.ReleaseFunc, error) {
+	inMsg, err := t.RecvMessage()
+	if err != nil {
+		return nil, nil, err
+	}
+	r := new(Message)
+	if err := pogs.Extract(r, rpccp.Message_TypeID, capnp.Struct(inMsg.Message())); err != nil {
+		inMsg.Release()
+		return nil, nil, fmt.Errorf("extract RPC message: %v", err)
+	}
+	if r.Which == rpccp.Message_Which_abort ||
+		r.Which == rpccp.Message_Which_bootstrap ||
+		r.Which == rpccp.Message_Which_finish ||
+		r.Which == rpccp.Message_Which_resolve ||
+		r.Which == rpccp.Message_Which_release ||
+		r.Which == rpccp.Message_Which_disembargo {
+		// These messages are guaranteed to not contain pointers back to
+		// the original message, so we can release them early.
+		inMsg.Release()
+		return r, func() {}, nil
+	}
+	return r, inMsg.Release, nil
+}
+
+// RecvBootstrapReturn receives the return for the bootstrap question
+// qid from t, and returns the export ID of the bootstrap capability.
+func RecvBootstrapReturn(ctx context.Context, t transport.Transport, qid uint32) (uint32, error) {
+	rmsg, release, err := RecvMessage(ctx, t)
+	if err != nil {
+		return 0, fmt.Errorf("receive bootstrap: %v", err)
+	}
+	defer release()
+	if rmsg.Which != rpccp.Message_Which_return {
+		return 0, fmt.Errorf("received %v message; want return (for bootstrap)", rmsg.Which)
+	}
+	if rmsg.Return.AnswerID != qid {
+		return 0, fmt.Errorf("received return for answer %d; want %d (bootstrap)", rmsg.Return.AnswerID, qid)
+	}
+	if rmsg.Return.Which != rpccp.Return_Which_results {
+		return 0, fmt.Errorf("bootstrap return which = %v; want results", rmsg.Return.Which)
+	}
+	iface := rmsg.Return.Results.Content.Interface()
+	if !iface.IsValid() {
+		return 0, errors.New("parse bootstrap return: content is not an interface pointer")
+	}
+	ctab := rmsg.Return.Results.CapTable
+	if iface.Capability() != 0 || len(ctab) != 1 {
+		// This is a bit more restrictive than necessary, but we don't need
+		// the flexibility.
+		return 0, fmt.Errorf("parse bootstrap return: capability index, table length = %d, %d; want 0, 1", iface.Capability(), len(ctab))
+	}
+	if ctab[0].Which != rpccp.CapDescriptor_Which_senderHosted {
+		return 0, fmt.Errorf("parse bootstrap return: received %v capability; want senderHosted", ctab[0].Which)
+	}
+	return ctab[0].SenderHosted, nil
+}
+
+// Exception is an RPC exception, as sent in Abort and Return messages.
+type Exception struct {
+	Reason string
+	Type   rpccp.Exception_Type
+}
+
+// Bootstrap is a Bootstrap message.
+type Bootstrap struct {
+	QuestionID uint32 `capnp:"questionId"`
+}
+
+// Call is a Call message.
+type Call struct {
+	QuestionID              uint32 `capnp:"questionId"`
+	Target                  MessageTarget
+	InterfaceID             uint64 `capnp:"interfaceId"`
+	MethodID                uint16 `capnp:"methodId"`
+	AllowThirdPartyTailCall bool
+	Params                  Payload
+	SendResultsTo           CallSendResultsTo
+}
+
+// CallSendResultsTo is the sendResultsTo union of a Call message.
+type CallSendResultsTo struct {
+	Which rpccp.Call_sendResultsTo_Which
+}
+
+// Return is a Return message.
+type Return struct {
+	AnswerID         uint32 `capnp:"answerId"`
+	ReleaseParamCaps bool
+
+	Which                 rpccp.Return_Which
+	Results               *Payload
+	Exception             *Exception
+	TakeFromOtherQuestion uint32
+}
+
+// Finish is a Finish message.
+type Finish struct {
+	QuestionID        uint32 `capnp:"questionId"`
+	ReleaseResultCaps bool
+}
+
+// Resolve is a Resolve message.
+type Resolve struct {
+	PromiseID uint32 `capnp:"promiseId"`
+	Which     rpccp.Resolve_Which
+	Cap       *CapDescriptor
+	Exception *Exception
+}
+
+// Release is a Release message.
+type Release struct {
+	ID             uint32 `capnp:"id"`
+	ReferenceCount uint32
+}
+
+// Disembargo is a Disembargo message.
+type Disembargo struct {
+	Target  MessageTarget
+	Context DisembargoContext
+}
+
+// DisembargoContext is the context union of a Disembargo message.
+type DisembargoContext struct {
+	Which            rpccp.Disembargo_context_Which
+	SenderLoopback   uint32
+	ReceiverLoopback uint32
+	Provide          uint32
+}
+
+// MessageTarget is the target of a Call or Disembargo message.
+type MessageTarget struct {
+	Which          rpccp.MessageTarget_Which
+	ImportedCap    uint32
+	PromisedAnswer *PromisedAnswer
+}
+
+// Payload is the content of a call's parameters or results, along with
+// its capability table.
+type Payload struct {
+	Content  capnp.Ptr
+	CapTable []CapDescriptor
+}
+
+// CapDescriptor describes a capability in a Payload's capability table.
+type CapDescriptor struct {
+	Which          rpccp.CapDescriptor_Which
+	SenderHosted   uint32
+	SenderPromise  uint32
+	ReceiverHosted uint32
+	ReceiverAnswer *PromisedAnswer
+}
+
+// PromisedAnswer identifies a capability in the results of a question
+// that has not returned yet.
+type PromisedAnswer struct {
+	QuestionID uint32 `capnp:"questionId"`
+	Transform  []PromisedAnswerOp
+}
+
+// TransformEquals reports whether pa's transform selects the pointer
+// field path given, ignoring no-ops.
+func (pa *PromisedAnswer) TransformEquals(path ...uint16) bool {
+	for _, op := range pa.Transform {
+		switch op.Which {
+		case rpccp.PromisedAnswer_Op_Which_noop:
+			// Skip.
+		case rpccp.PromisedAnswer_Op_Which_getPointerField:
+			if len(path) == 0 || path[0] != op.GetPointerField {
+				return false
+			}
+			path = path[1:]
+		default:
+			return false
+		}
+	}
+	return len(path) == 0
+}
+
+// PromisedAnswerOp is an operation in a PromisedAnswer's transform.
+type PromisedAnswerOp struct {
+	Which           rpccp.PromisedAnswer_Op_Which
+	GetPointerField uint16
+}
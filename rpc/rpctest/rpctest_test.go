@@ -0,0 +1,62 @@
+package rpctest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/rpc/rpctest"
+	"capnproto.org/go/capnp/v3/rpc/transport"
+	"capnproto.org/go/capnp/v3/server"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func TestBootstrap(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	p1, p2 := transport.NewPipe(1)
+	conn := rpc.NewConn(rpc.NewTransport(p1), &rpc.Options{
+		BootstrapClient: capnp.NewClient(server.New(nil, nil, nil)),
+	})
+	defer conn.Close()
+	trans := rpc.NewTransport(p2)
+	defer trans.Close()
+
+	err := rpctest.SendMessage(ctx, trans, &rpctest.Message{
+		Which:     rpccp.Message_Which_bootstrap,
+		Bootstrap: &rpctest.Bootstrap{QuestionID: 7},
+	})
+	require.NoError(t, err)
+	_, err = rpctest.RecvBootstrapReturn(ctx, trans, 7)
+	require.NoError(t, err)
+
+	err = rpctest.SendMessage(ctx, trans, &rpctest.Message{
+		Which: rpccp.Message_Which_abort,
+		Abort: &rpctest.Exception{
+			Type:   rpccp.Exception_Type_failed,
+			Reason: "done",
+		},
+	})
+	require.NoError(t, err)
+	<-conn.Done()
+}
+
+func TestTransformEquals(t *testing.T) {
+	t.Parallel()
+
+	pa := &rpctest.PromisedAnswer{
+		Transform: []rpctest.PromisedAnswerOp{
+			{Which: rpccp.PromisedAnswer_Op_Which_noop},
+			{Which: rpccp.PromisedAnswer_Op_Which_getPointerField, GetPointerField: 2},
+		},
+	}
+	assert.True(t, pa.TransformEquals(2))
+	assert.False(t, pa.TransformEquals())
+	assert.False(t, pa.TransformEquals(2, 0))
+	assert.True(t, (&rpctest.PromisedAnswer{}).TransformEquals())
+}
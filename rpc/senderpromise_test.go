@@ -513,10 +513,10 @@ func TestDisembargoSenderPromiseWithPipeline(t *testing.T) {
 	// effectively should be proxied by P1 back to P2). The question
 	// becomes: what comes first? The pipelined call or the resolve?
 	//
-	// Due to the nature of this test (and the use of NewLocalPromise), the
-	// prior `r.Fulfill()` directly triggers the pending pipelined call
-	// _before_ the resolve has a chance to be sent, so that's what we
-	// assert next.
+	// Without Options.StrictResolveOrder, the prior `r.Fulfill()`
+	// directly triggers the pending pipelined call _before_ the resolve
+	// has a chance to be sent, so that's what we assert next. See
+	// TestStrictResolveOrder for the other order.
 	var p1ToP2CallQuestionId uint32
 	{
 		// P1 sends the pipelined call back to P2.
@@ -636,6 +636,144 @@ func TestDisembargoSenderPromiseWithPipeline(t *testing.T) {
 	}
 }
 
+// TestStrictResolveOrder verifies that with Options.StrictResolveOrder,
+// a call made on an exported promise that resolves back to the remote vat
+// is forwarded only after the Resolve message.
+func TestStrictResolveOrder(t *testing.T) {
+	t.Parallel()
+
+	const interfaceID uint64 = 0xbaba001337
+	const methodID uint16 = 0x0f30
+
+	ctx := context.Background()
+	p, r := capnp.NewLocalPromise[capnp.Client]()
+
+	left, right := transport.NewPipe(1)
+	p1, p2 := rpc.NewTransport(left), rpc.NewTransport(right)
+
+	conn := rpc.NewConn(p1, &rpc.Options{
+		Logger:             testErrorReporter{tb: t},
+		BootstrapClient:    capnp.Client(p),
+		StrictResolveOrder: true,
+	})
+	defer finishTest(t, conn, p2)
+
+	// P2 bootstraps, and gets a promise for P1's bootstrap interface.
+	var promiseID uint32
+	{
+		require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+			Which:     rpccp.Message_Which_bootstrap,
+			Bootstrap: &rpcBootstrap{QuestionID: 0},
+		}))
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_return, rmsg.Which)
+		require.Equal(t, rpccp.Return_Which_results, rmsg.Return.Which)
+		require.Len(t, rmsg.Return.Results.CapTable, 1)
+		desc := rmsg.Return.Results.CapTable[0]
+		require.Equal(t, rpccp.CapDescriptor_Which_senderPromise, desc.Which)
+		promiseID = desc.SenderPromise
+	}
+
+	// P2 calls the promise; the call waits in P1.
+	const callID = 1
+	require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+		Which: rpccp.Message_Which_call,
+		Call: &rpcCall{
+			QuestionID: callID,
+			Target: rpcMessageTarget{
+				Which:       rpccp.MessageTarget_Which_importedCap,
+				ImportedCap: promiseID,
+			},
+			InterfaceID: interfaceID,
+			MethodID:    methodID,
+			SendResultsTo: rpcCallSendResultsTo{
+				Which: rpccp.Call_sendResultsTo_Which_caller,
+			},
+		},
+	}))
+
+	// P1 bootstraps P2, and P2 returns a capability it hosts.
+	bsClient := conn.Bootstrap(ctx)
+	defer bsClient.Release()
+	const hostedID = 12
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_bootstrap, rmsg.Which)
+
+		outMsg, err := p2.NewMessage()
+		require.NoError(t, err)
+		iface := capnp.NewInterface(outMsg.Message().Segment(), 0)
+		require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+			Which: rpccp.Message_Which_return,
+			Return: &rpcReturn{
+				AnswerID: rmsg.Bootstrap.QuestionID,
+				Which:    rpccp.Return_Which_results,
+				Results: &rpcPayload{
+					Content: iface.ToPtr(),
+					CapTable: []rpcCapDescriptor{{
+						Which:        rpccp.CapDescriptor_Which_senderHosted,
+						SenderHosted: hostedID,
+					}},
+				},
+			},
+		}))
+	}
+	require.NoError(t, bsClient.Resolve(ctx))
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_finish, rmsg.Which)
+	}
+
+	// Resolve P1's bootstrap promise to P2's capability.  P2 must see
+	// the Resolve before the call it made on the promise.
+	r.Fulfill(bsClient)
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_resolve, rmsg.Which)
+		assert.Equal(t, promiseID, rmsg.Resolve.PromiseID)
+		require.Equal(t, rpccp.Resolve_Which_cap, rmsg.Resolve.Which)
+		assert.Equal(t, rpccp.CapDescriptor_Which_receiverHosted, rmsg.Resolve.Cap.Which)
+		assert.Equal(t, uint32(hostedID), rmsg.Resolve.Cap.ReceiverHosted)
+	}
+	var forwardedID uint32
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_call, rmsg.Which)
+		assert.Equal(t, interfaceID, rmsg.Call.InterfaceID)
+		assert.Equal(t, methodID, rmsg.Call.MethodID)
+		assert.Equal(t, rpccp.MessageTarget_Which_importedCap, rmsg.Call.Target.Which)
+		assert.Equal(t, uint32(hostedID), rmsg.Call.Target.ImportedCap)
+		forwardedID = rmsg.Call.QuestionID
+	}
+
+	// The forwarded call's return makes its way back to P2.
+	require.NoError(t, sendMessage(ctx, p2, &rpcMessage{
+		Which: rpccp.Message_Which_return,
+		Return: &rpcReturn{
+			AnswerID: forwardedID,
+			Which:    rpccp.Return_Which_results,
+			Results:  &rpcPayload{},
+		},
+	}))
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		defer release()
+		require.Equal(t, rpccp.Message_Which_return, rmsg.Which)
+		assert.Equal(t, uint32(callID), rmsg.Return.AnswerID)
+	}
+}
+
 // TestShortensPathAfterResolve verifies that a conn collapses the path to a
 // capability if the remote promise resolves to the local vat.
 func TestShortensPathAfterResolve(t *testing.T) {
@@ -715,6 +853,59 @@ func TestShortensPathAfterResolve(t *testing.T) {
 	require.Equal(t, c2CallValue, c2CallRes.N())
 }
 
+// TestDisablePathShortening verifies that a conn with
+// DisablePathShortening keeps proxying calls to a promise that resolves
+// back to the remote vat.
+func TestDisablePathShortening(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	p, r := capnp.NewLocalPromise[testcapnp.PingPong]()
+	defer p.Release()
+
+	left, right := transport.NewPipe(1)
+	p1, p2 := rpc.NewTransport(left), rpc.NewTransport(right)
+
+	c1 := rpc.NewConn(p1, &rpc.Options{
+		Logger:                testErrorReporter{tb: t},
+		BootstrapClient:       capnp.Client(p),
+		DisablePathShortening: true,
+	})
+	ord := &echoNumOrderChecker{t: t, skipAssertNum: true}
+	c2 := rpc.NewConn(p2, &rpc.Options{
+		Logger:          testErrorReporter{tb: t},
+		BootstrapClient: capnp.Client(testcapnp.PingPong_ServerToClient(ord)),
+	})
+
+	c2PromiseToC1 := testcapnp.PingPong(c2.Bootstrap(ctx))
+	defer c2PromiseToC1.Release()
+	c1PromiseToC2 := testcapnp.PingPong(c1.Bootstrap(ctx))
+	defer c1PromiseToC2.Release()
+	require.NoError(t, c1PromiseToC2.Resolve(ctx))
+
+	// C1's bootstrap resolves to C2's bootstrap, but C1 exports it
+	// instead of telling C2 to use its own capability.
+	r.Fulfill(c1PromiseToC2)
+	require.NoError(t, c2PromiseToC1.Resolve(ctx))
+
+	const callValue int64 = 0xc2000042
+	call, release := echoNum(ctx, c2PromiseToC1, callValue)
+	res, err := call.Struct()
+	require.NoError(t, err)
+	require.Equal(t, callValue, res.N())
+	release()
+
+	// The path goes through C1, so calls fail once the conns are down.
+	require.NoError(t, c1.Close())
+	require.NoError(t, c2.Close())
+	call, release = echoNum(ctx, c2PromiseToC1, callValue)
+	defer release()
+	_, err = call.Struct()
+	require.Error(t, err)
+}
+
 // testPromiseOrderingCase tests that E-order is respected when fulfilling a
 // promise with something on the remote peer.
 //
@@ -10,7 +10,9 @@ import (
 
 // serveOpts are options for the Cap'n Proto server.
 type serveOpts struct {
-	newTransport NewTransportFunc
+	newTransport  NewTransportFunc
+	authenticator Authenticator
+	peerQuotas    *PeerQuotas
 }
 
 // defaultServeOpts returns the default server opts.
@@ -36,6 +38,24 @@ func WithPackedStreamingTransport() ServeOption {
 	}
 }
 
+// WithAuthenticator sets the Authenticator of each served connection.
+// The PeerID it is passed describes the accepted net.Conn; see
+// PeerIDFromNetConn.
+func WithAuthenticator(a Authenticator) ServeOption {
+	return func(opts *serveOpts) {
+		opts.authenticator = a
+	}
+}
+
+// WithPeerQuotas sets the PeerQuotas shared by the served connections,
+// limiting the exports and answers of each identity established by the
+// Authenticator.
+func WithPeerQuotas(pq *PeerQuotas) ServeOption {
+	return func(opts *serveOpts) {
+		opts.peerQuotas = pq
+	}
+}
+
 // Serve serves a Cap'n Proto RPC to incoming connections.
 //
 // Serve will take ownership of bootstrapClient and release it after the listener closes.
@@ -62,13 +82,43 @@ func Serve(lis net.Listener, boot capnp.Client, opts ...ServeOption) error {
 
 		// the RPC connection takes ownership of the bootstrap interface and will release it when the connection
 		// exits, so use AddRef to avoid releasing the provided bootstrap client capability.
-		opts := Options{
-			BootstrapClient: boot.AddRef(),
-		}
-		// For each new incoming connection, create a new RPC transport connection that will serve incoming RPC requests
-		transport := options.newTransport(conn)
-		_ = NewConn(transport, &opts)
+		ref := boot.AddRef()
+		go func() {
+			_, err := serveConn(conn, Options{}, options, func(PeerID) capnp.Client {
+				return ref
+			})
+			if err != nil {
+				ref.Release()
+			}
+		}()
+	}
+}
+
+// serveConn creates a Conn on an accepted net.Conn, using opts as a
+// template and boot to get the bootstrap capability for the peer.  The
+// TLS handshake and authentication happen here, rather than in the
+// accept loop, so that a slow peer does not hold up other peers.  If
+// the handshake fails, serveConn closes conn and returns the error
+// without calling boot.
+func serveConn(conn net.Conn, opts Options, options serveOpts, boot func(PeerID) capnp.Client) (*Conn, error) {
+	peer, err := PeerIDFromNetConn(context.Background(), conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	opts.RemotePeerID = peer
+	if boot != nil {
+		opts.BootstrapClient = boot(peer)
+	}
+	if options.authenticator != nil {
+		opts.Authenticator = options.authenticator
+	}
+	if options.peerQuotas != nil {
+		opts.PeerQuotas = options.peerQuotas
 	}
+	// For each new incoming connection, create a new RPC transport connection that will serve incoming RPC requests
+	transport := options.newTransport(conn)
+	return NewConn(transport, &opts), nil
 }
 
 // ListenAndServe opens a listener on the given address and serves a Cap'n Proto RPC to incoming connections
@@ -87,7 +137,7 @@ func ListenAndServe(ctx context.Context, network, addr string, bootstrapClient c
 			<-ctx.Done()
 			_ = listener.Close()
 		}()
-		err = Serve(listener, bootstrapClient)
+		err = Serve(listener, bootstrapClient, opts...)
 	}
 	return err
 }
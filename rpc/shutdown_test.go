@@ -58,3 +58,34 @@ func (s dropPingServer) EchoNum(ctx context.Context, p testcapnp.PingPong_echoNu
 	<-ctx.Done()
 	return nil
 }
+
+// TestContextShutdown verifies that a connection is shut down when the
+// context in its Options is canceled.
+func TestContextShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcapnp.PingPong_ServerToClient(pingPonger{})),
+	}, &rpc.Options{
+		Context: ctx,
+	})
+
+	client := testcapnp.PingPong(clientConn.Bootstrap(ctx))
+	defer client.Release()
+	if err := client.Resolve(ctx); err != nil {
+		t.Fatal("Resolve:", err)
+	}
+
+	select {
+	case <-clientConn.Done():
+		t.Fatal("connection shut down before context was canceled")
+	default:
+	}
+
+	cancel()
+	<-clientConn.Done()
+	<-serverConn.Done()
+}
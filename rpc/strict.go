@@ -0,0 +1,78 @@
+package rpc
+
+import "capnproto.org/go/capnp/v3/internal/str"
+
+// A Deviation is a kind of departure from the RPC protocol by the
+// remote vat that a Conn tolerates or recovers from.  Conns count the
+// deviations they see; see Conn.Deviations and Options.StrictProtocol.
+type Deviation int
+
+const (
+	// DeviationUnknownMessage is a message of a type that the Conn
+	// does not implement.  The Conn replies with an Unimplemented
+	// message.
+	DeviationUnknownMessage Deviation = iota
+
+	// DeviationIgnoredUnimplemented is an Unimplemented message for a
+	// message that needs no cleanup, or that refers to no capability
+	// this vat exported.  It is ignored.
+	DeviationIgnoredUnimplemented
+
+	// DeviationUnknownResolve is a Resolve message for an import that
+	// does not exist, or that is not a promise.
+	DeviationUnknownResolve
+
+	// DeviationRedundantResolve is a Resolve message for a promise that
+	// was already resolved.  It is ignored.
+	DeviationRedundantResolve
+
+	// DeviationUnknownFinish is a Finish message for an answer that
+	// does not exist or was already finished.  The Conn aborts the
+	// connection.
+	DeviationUnknownFinish
+
+	numDeviations
+)
+
+// String returns a short name for d, suitable for logs and metrics.
+func (d Deviation) String() string {
+	switch d {
+	case DeviationUnknownMessage:
+		return "unknown message"
+	case DeviationIgnoredUnimplemented:
+		return "ignored unimplemented"
+	case DeviationUnknownResolve:
+		return "unknown resolve"
+	case DeviationRedundantResolve:
+		return "redundant resolve"
+	case DeviationUnknownFinish:
+		return "unknown finish"
+	default:
+		return "deviation(" + str.Itod(int(d)) + ")"
+	}
+}
+
+// Deviations returns the number of protocol deviations of each kind
+// received from the remote vat so far.  Kinds that were never seen are
+// omitted.
+func (c *Conn) Deviations() map[Deviation]uint64 {
+	m := make(map[Deviation]uint64)
+	for d := range c.deviations {
+		if n := c.deviations[d].Load(); n > 0 {
+			m[Deviation(d)] = n
+		}
+	}
+	return m
+}
+
+// deviation records that the remote vat deviated from the protocol.
+// If the Conn is in strict mode, the deviation is also logged, with
+// args as extra key, value pairs.
+//
+// This does not acquire c.lk, so it may be called with or without it.
+func (c *Conn) deviation(d Deviation, args ...any) {
+	c.deviations[d].Add(1)
+	if c.strictProtocol {
+		c.er.Warn("protocol deviation", append([]any{"kind", d}, args...)...)
+	}
+}
@@ -0,0 +1,89 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func TestStrictProtocol(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := warnLogger{
+		testErrorReporter: testErrorReporter{tb: t},
+		warnings:          make(chan string, 10),
+	}
+	conn, p2 := newTestConn(&rpc.Options{
+		Logger:         logger,
+		StrictProtocol: true,
+	})
+	defer finishTest(t, conn, p2)
+
+	// 1. Send a message of an obsolete type.
+	{
+		outMsg, err := p2.NewMessage()
+		require.NoError(t, err)
+		require.NoError(t, outMsg.Message().SetObsoleteDelete(capnp.Ptr{}))
+		require.NoError(t, outMsg.Send())
+		outMsg.Release()
+	}
+	// 2. Receive unimplemented.
+	{
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err)
+		release()
+		require.Equal(t, rpccp.Message_Which_unimplemented, rmsg.Which)
+	}
+	assert.Equal(t, "protocol deviation", <-logger.warnings)
+
+	// 3. Send unimplemented for a message that needs no cleanup.
+	{
+		msg := &rpcMessage{
+			Which: rpccp.Message_Which_unimplemented,
+			Unimplemented: &rpcMessage{
+				Which:  rpccp.Message_Which_finish,
+				Finish: &rpcFinish{QuestionID: 5},
+			},
+		}
+		require.NoError(t, sendMessage(ctx, p2, msg))
+	}
+	assert.Equal(t, "protocol deviation", <-logger.warnings)
+
+	assert.Equal(t, map[rpc.Deviation]uint64{
+		rpc.DeviationUnknownMessage:       1,
+		rpc.DeviationIgnoredUnimplemented: 1,
+	}, conn.Deviations())
+}
+
+func TestDeviationsUnknownFinish(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	conn, p2 := newTestConn(&rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer conn.Close()
+
+	msg := &rpcMessage{
+		Which:  rpccp.Message_Which_finish,
+		Finish: &rpcFinish{QuestionID: 42},
+	}
+	require.NoError(t, sendMessage(ctx, p2, msg))
+
+	rmsg, release, err := recvMessage(ctx, p2)
+	require.NoError(t, err)
+	release()
+	require.Equal(t, rpccp.Message_Which_abort, rmsg.Which)
+
+	// Deviations are counted even outside of strict mode.
+	assert.Equal(t, map[rpc.Deviation]uint64{
+		rpc.DeviationUnknownFinish: 1,
+	}, conn.Deviations())
+}
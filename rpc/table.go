@@ -0,0 +1,187 @@
+package rpc
+
+import (
+	"sort"
+
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A Table holds the entries of one of the tables a Conn keeps for the
+// connection: questions, answers, exports or imports.  Entries are
+// opaque values owned by the Conn, keyed by their ID on the wire.
+//
+// The default in-memory tables are fine for almost all uses; custom
+// implementations, set with Options.NewTable, can be used to instrument
+// the tables or to keep large ones somewhere else.  A Table's methods
+// are called while the Conn's lock is held, so they must not block for
+// long and must not call back into the Conn.  A Table does not need to
+// be safe for concurrent use.
+type Table interface {
+	// Get returns the entry with the given ID, or nil if there is none.
+	Get(id uint32) any
+
+	// Set stores entry under the given ID, replacing any previous
+	// entry.  entry is never nil.
+	Set(id uint32, entry any)
+
+	// Delete removes the entry with the given ID, if any.
+	Delete(id uint32)
+
+	// Range calls f for each entry, in any order, until f returns
+	// false.  f does not modify the table.
+	Range(f func(id uint32, entry any) bool)
+}
+
+// A TableKind identifies one of a Conn's tables.
+type TableKind int
+
+const (
+	// QuestionTable holds calls made by this vat, keyed by question ID.
+	QuestionTable TableKind = iota
+
+	// AnswerTable holds calls received from the remote vat, keyed by
+	// the remote vat's question ID.
+	AnswerTable
+
+	// ExportTable holds capabilities exported to the remote vat.
+	ExportTable
+
+	// ImportTable holds capabilities imported from the remote vat.
+	ImportTable
+)
+
+func (k TableKind) String() string {
+	switch k {
+	case QuestionTable:
+		return "questions"
+	case AnswerTable:
+		return "answers"
+	case ExportTable:
+		return "exports"
+	case ImportTable:
+		return "imports"
+	default:
+		return "TableKind(" + str.Itod(int(k)) + ")"
+	}
+}
+
+// NewMemoryTable returns the in-memory Table a Conn uses by default for
+// the given kind of table.  It is useful for Table implementations that
+// wrap the default behavior.
+func NewMemoryTable(kind TableKind) Table {
+	switch kind {
+	case QuestionTable, ExportTable:
+		// IDs are allocated by this vat, and are kept dense by
+		// reusing them.
+		return &sliceTable{}
+	default:
+		// IDs are chosen by the remote vat.
+		return mapTable{}
+	}
+}
+
+// sliceTable is a Table for dense IDs.
+type sliceTable struct {
+	entries []any
+}
+
+func (t *sliceTable) Get(id uint32) any {
+	if uint64(id) >= uint64(len(t.entries)) {
+		return nil
+	}
+	return t.entries[id]
+}
+
+func (t *sliceTable) Set(id uint32, entry any) {
+	if uint64(id) < uint64(len(t.entries)) {
+		t.entries[id] = entry
+		return
+	}
+	for uint64(len(t.entries)) < uint64(id) {
+		t.entries = append(t.entries, nil)
+	}
+	t.entries = append(t.entries, entry)
+}
+
+func (t *sliceTable) Delete(id uint32) {
+	if uint64(id) < uint64(len(t.entries)) {
+		t.entries[id] = nil
+	}
+}
+
+func (t *sliceTable) Range(f func(id uint32, entry any) bool) {
+	for id, e := range t.entries {
+		if e != nil && !f(uint32(id), e) {
+			return
+		}
+	}
+}
+
+// mapTable is a Table for sparse IDs.
+type mapTable map[uint32]any
+
+func (t mapTable) Get(id uint32) any {
+	return t[id]
+}
+
+func (t mapTable) Set(id uint32, entry any) {
+	t[id] = entry
+}
+
+func (t mapTable) Delete(id uint32) {
+	delete(t, id)
+}
+
+func (t mapTable) Range(f func(id uint32, entry any) bool) {
+	for id, e := range t {
+		if !f(id, e) {
+			return
+		}
+	}
+}
+
+// table is a typed view of a Table.
+type table[K ~uint32, V comparable] struct {
+	t Table
+}
+
+// get returns the entry with the given ID, or the zero value.
+func (t table[K, V]) get(id K) V {
+	v, _ := t.t.Get(uint32(id)).(V)
+	return v
+}
+
+func (t table[K, V]) set(id K, v V) {
+	t.t.Set(uint32(id), v)
+}
+
+func (t table[K, V]) remove(id K) {
+	t.t.Delete(uint32(id))
+}
+
+// each calls f for each entry in the table.
+func (t table[K, V]) each(f func(id K, v V)) {
+	t.t.Range(func(id uint32, entry any) bool {
+		if v, ok := entry.(V); ok {
+			f(K(id), v)
+		}
+		return true
+	})
+}
+
+// clear removes all entries from the table, returning them in order of
+// ID, so that releasing them does not depend on the Table's iteration
+// order.
+func (t table[K, V]) clear() []V {
+	var ids []K
+	t.each(func(id K, v V) {
+		ids = append(ids, id)
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	entries := make([]V, len(ids))
+	for i, id := range ids {
+		entries[i] = t.get(id)
+		t.remove(id)
+	}
+	return entries
+}
@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableClearOrder(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range []TableKind{AnswerTable, ExportTable} {
+		tab := table[uint32, *int]{NewMemoryTable(kind)}
+		var want []*int
+		for id := uint32(0); id < 64; id++ {
+			v := new(int)
+			*v = int(id)
+			tab.set(id, v)
+			want = append(want, v)
+		}
+		tab.remove(10)
+		want = append(want[:10], want[11:]...)
+
+		assert.Equal(t, want, tab.clear(), "%v: entries not in order of ID", kind)
+		assert.Nil(t, tab.get(0), "%v: table not cleared", kind)
+	}
+}
@@ -0,0 +1,88 @@
+package rpc_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+)
+
+// countingTable wraps an in-memory table, counting the entries set.
+type countingTable struct {
+	rpc.Table
+	mu   *sync.Mutex
+	sets map[rpc.TableKind]int
+	kind rpc.TableKind
+}
+
+func (t countingTable) Set(id uint32, entry any) {
+	t.mu.Lock()
+	t.sets[t.kind]++
+	t.mu.Unlock()
+	t.Table.Set(id, entry)
+}
+
+func TestCustomTables(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var mu sync.Mutex
+	sets := make(map[rpc.TableKind]int)
+	newTable := func(kind rpc.TableKind) rpc.Table {
+		return countingTable{Table: rpc.NewMemoryTable(kind), mu: &mu, sets: sets, kind: kind}
+	}
+
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(testcp.PingPong_ServerToClient(pingPonger{})),
+		Logger:          testErrorReporter{tb: t},
+		NewTable:        newTable,
+	}, &rpc.Options{
+		Logger:   testErrorReporter{tb: t},
+		NewTable: newTable,
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	client := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer client.Release()
+	ans, release := echoNum(ctx, client, 42)
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), res.N())
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, kind := range []rpc.TableKind{rpc.QuestionTable, rpc.AnswerTable, rpc.ExportTable, rpc.ImportTable} {
+		assert.NotZero(t, sets[kind], "no entries set in %v table", kind)
+	}
+}
+
+func TestMemoryTable(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range []rpc.TableKind{rpc.QuestionTable, rpc.AnswerTable} {
+		tab := rpc.NewMemoryTable(kind)
+		assert.Nil(t, tab.Get(3), "%v: empty table", kind)
+		tab.Set(3, "c")
+		tab.Set(0, "a")
+		assert.Equal(t, "c", tab.Get(3), kind)
+		assert.Equal(t, "a", tab.Get(0), kind)
+		assert.Nil(t, tab.Get(1), kind)
+		tab.Delete(3)
+		assert.Nil(t, tab.Get(3), kind)
+
+		entries := map[uint32]any{}
+		tab.Range(func(id uint32, entry any) bool {
+			entries[id] = entry
+			return true
+		})
+		assert.Equal(t, map[uint32]any{0: "a"}, entries, kind)
+	}
+}
@@ -26,3 +26,21 @@ func NewPackedStreamTransport(rwc io.ReadWriteCloser) Transport {
 func NewTransport(codec Codec) Transport {
 	return transport.New(codec)
 }
+
+// localPairBufSize is the number of messages that can be in flight in
+// each direction between the Conns returned by NewLocalPair.  It is
+// large enough that a Conn rarely blocks on a peer that is busy, or
+// that has stopped reading because it is shutting down.
+const localPairBufSize = 16
+
+// NewLocalPair returns two Conns connected to each other over an
+// in-memory pipe, created with optsA and optsB respectively.  This is
+// useful in tests, and for wiring up components of a single process
+// that expect to talk over a Conn.  Either set of options may be nil.
+// The caller is responsible for closing both Conns.
+func NewLocalPair(optsA, optsB *Options) (a, b *Conn) {
+	ca, cb := transport.NewPipe(localPairBufSize)
+	a = NewConn(NewTransport(ca), optsA)
+	b = NewConn(NewTransport(cb), optsB)
+	return a, b
+}
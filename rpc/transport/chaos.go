@@ -0,0 +1,256 @@
+package transport
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+var (
+	// ErrChaosDrop is the cause of the error returned when a chaos
+	// transport drops a message.
+	ErrChaosDrop = errors.New("chaos: message dropped")
+
+	// ErrChaosClose is the cause of the error returned when a chaos
+	// transport closes the connection.
+	ErrChaosClose = errors.New("chaos: connection closed")
+)
+
+// ChaosConfig configures the faults injected by a transport returned
+// from NewChaos.  The zero value injects no faults.
+type ChaosConfig struct {
+	// Latency is added before each outgoing message is sent, plus a
+	// random extra delay of up to Jitter.
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// ReorderProbability is the probability that an outgoing message
+	// is held back and sent after the message that follows it.  Only
+	// messages that may legally be delivered out of order are held,
+	// and only swapped with each other: these are Returns that carry
+	// no capabilities.  A held message is sent anyway once
+	// ReorderWindow elapses, which defaults to 10ms.
+	ReorderProbability float64
+	ReorderWindow      time.Duration
+
+	// DropProbability is the probability that an outgoing message is
+	// dropped.  Since the RPC protocol cannot recover from a lost
+	// message, a drop also closes the transport, as if the link had
+	// failed.
+	DropProbability float64
+
+	// CloseProbability is the probability that the transport closes
+	// abruptly instead of sending an outgoing message.  If CloseAfter
+	// is positive, the transport also closes once that many messages
+	// have been sent.
+	CloseProbability float64
+	CloseAfter       int
+
+	// Seed seeds the source of randomness, so that runs with the same
+	// Seed and traffic make the same decisions.
+	Seed int64
+}
+
+// NewChaos returns a transport that forwards to t, injecting faults
+// into outgoing messages as described by cfg.  This is useful to test
+// how applications cope with degraded links.  Faults are only injected
+// in the direction from this end of the connection; wrap the transports
+// at both ends to affect both directions.
+func NewChaos(t Transport, cfg ChaosConfig) Transport {
+	if cfg.ReorderWindow <= 0 {
+		cfg.ReorderWindow = 10 * time.Millisecond
+	}
+	return &chaosTransport{
+		Transport: t,
+		cfg:       cfg,
+		rng:       rand.New(rand.NewSource(cfg.Seed)),
+	}
+}
+
+type chaosTransport struct {
+	Transport
+	cfg ChaosConfig
+
+	// mu serializes sends on the underlying transport, and protects
+	// the fields below.
+	mu     sync.Mutex
+	rng    *rand.Rand
+	held   *chaosMsg // message waiting to be reordered, if any
+	sent   int
+	closed bool
+}
+
+func (t *chaosTransport) NewMessage() (OutgoingMessage, error) {
+	out, err := t.Transport.NewMessage()
+	if err != nil {
+		return nil, err
+	}
+	return &chaosMsg{OutgoingMessage: out, t: t}, nil
+}
+
+// Close closes the underlying transport, unless the transport has
+// already closed itself to inject a fault.
+func (t *chaosTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		t.discardHeld()
+		return nil
+	}
+	t.closed = true
+	t.discardHeld()
+	return t.Transport.Close()
+}
+
+// roll returns true with probability p.  The caller must hold t.mu.
+func (t *chaosTransport) roll(p float64) bool {
+	return p > 0 && t.rng.Float64() < p
+}
+
+func (t *chaosTransport) delay() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d := t.cfg.Latency
+	if t.cfg.Jitter > 0 {
+		d += time.Duration(t.rng.Int63n(int64(t.cfg.Jitter)))
+	}
+	return d
+}
+
+// fail closes the underlying transport to inject a fault, returning
+// an error with the given cause.  The caller must hold t.mu.
+func (t *chaosTransport) fail(cause error) error {
+	if !t.closed {
+		t.closed = true
+		t.discardHeld()
+		t.Transport.Close()
+	}
+	return transporterr.WrapDisconnected("chaos transport", cause)
+}
+
+// flushHeld sends the held message, if any.  The caller must hold t.mu.
+func (t *chaosTransport) flushHeld() error {
+	m := t.held
+	if m == nil {
+		return nil
+	}
+	t.held = nil
+	m.held = false
+	err := m.OutgoingMessage.Send()
+	if m.released {
+		m.OutgoingMessage.Release()
+	}
+	return err
+}
+
+// discardHeld releases the held message, if any, without sending it.
+// The caller must hold t.mu.
+func (t *chaosTransport) discardHeld() {
+	if m := t.held; m != nil {
+		t.held = nil
+		m.held = false
+		m.OutgoingMessage.Release()
+	}
+}
+
+func (t *chaosTransport) send(m *chaosMsg) error {
+	if d := t.delay(); d > 0 {
+		time.Sleep(d)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return transporterr.WrapDisconnected("chaos transport", ErrChaosClose)
+	}
+	if t.cfg.CloseAfter > 0 && t.sent >= t.cfg.CloseAfter || t.roll(t.cfg.CloseProbability) {
+		return t.fail(ErrChaosClose)
+	}
+	if t.roll(t.cfg.DropProbability) {
+		return t.fail(ErrChaosDrop)
+	}
+	t.sent++
+
+	if !isReorderable(m.Message()) {
+		if err := t.flushHeld(); err != nil {
+			return err
+		}
+		return m.OutgoingMessage.Send()
+	}
+	if t.held != nil {
+		// Swap m with the held message.
+		if err := m.OutgoingMessage.Send(); err != nil {
+			return err
+		}
+		return t.flushHeld()
+	}
+	if t.roll(t.cfg.ReorderProbability) {
+		t.held = m
+		m.held = true
+		m.Message().Message().Handoff()
+		time.AfterFunc(t.cfg.ReorderWindow, func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.held == m {
+				t.flushHeld()
+			}
+		})
+		return nil
+	}
+	return m.OutgoingMessage.Send()
+}
+
+// isReorderable reports whether m may be delivered out of order with
+// respect to other reorderable messages.  Returns that carry no
+// capabilities answer independent questions and don't affect any
+// capability's reference count, so their relative order is immaterial.
+func isReorderable(m rpccp.Message) bool {
+	if m.Which() != rpccp.Message_Which_return {
+		return false
+	}
+	ret, err := m.Return()
+	if err != nil {
+		return false
+	}
+	switch ret.Which() {
+	case rpccp.Return_Which_results:
+		results, err := ret.Results()
+		if err != nil {
+			return false
+		}
+		caps, err := results.CapTable()
+		return err == nil && caps.Len() == 0
+	case rpccp.Return_Which_exception, rpccp.Return_Which_canceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// A chaosMsg is an outgoing message on a chaos transport.  If the
+// message is held for reordering when it is released, the release is
+// deferred until it is actually sent.
+type chaosMsg struct {
+	OutgoingMessage
+	t *chaosTransport
+
+	// Protected by t.mu.
+	held     bool
+	released bool
+}
+
+func (m *chaosMsg) Send() error {
+	return m.t.send(m)
+}
+
+func (m *chaosMsg) Release() {
+	m.t.mu.Lock()
+	defer m.t.mu.Unlock()
+	m.released = true
+	if !m.held {
+		m.OutgoingMessage.Release()
+	}
+}
@@ -0,0 +1,123 @@
+package transport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+func newChaosPipe(cfg ChaosConfig) (t1, t2 Transport) {
+	c1, c2 := NewPipe(8)
+	return NewChaos(New(c1), cfg), New(c2)
+}
+
+func sendChaosTestMessage(t *testing.T, tr Transport, ret bool, id uint32) error {
+	out, err := tr.NewMessage()
+	require.NoError(t, err)
+	defer out.Release()
+	if ret {
+		r, err := out.Message().NewReturn()
+		require.NoError(t, err)
+		r.SetAnswerId(id)
+		_, err = r.NewResults()
+		require.NoError(t, err)
+	} else {
+		boot, err := out.Message().NewBootstrap()
+		require.NoError(t, err)
+		boot.SetQuestionId(id)
+	}
+	return out.Send()
+}
+
+func recvChaosTestMessage(t *testing.T, tr Transport) uint32 {
+	in, err := tr.RecvMessage()
+	require.NoError(t, err)
+	defer in.Release()
+	switch in.Message().Which() {
+	case rpccp.Message_Which_return:
+		r, err := in.Message().Return()
+		require.NoError(t, err)
+		return r.AnswerId()
+	case rpccp.Message_Which_bootstrap:
+		boot, err := in.Message().Bootstrap()
+		require.NoError(t, err)
+		return boot.QuestionId()
+	default:
+		t.Fatalf("unexpected message %v", in.Message().Which())
+		return 0
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	t.Parallel()
+
+	testTransport(t, func() (t1, t2 Transport, err error) {
+		t1, t2 = newChaosPipe(ChaosConfig{})
+		return t1, t2, nil
+	})
+}
+
+func TestChaosReorder(t *testing.T) {
+	t.Parallel()
+
+	t1, t2 := newChaosPipe(ChaosConfig{ReorderProbability: 1})
+	defer t1.Close()
+	defer t2.Close()
+
+	// Returns without capabilities are swapped, but other messages
+	// stay in place.
+	require.NoError(t, sendChaosTestMessage(t, t1, true, 1))
+	require.NoError(t, sendChaosTestMessage(t, t1, true, 2))
+	require.NoError(t, sendChaosTestMessage(t, t1, false, 3))
+	require.NoError(t, sendChaosTestMessage(t, t1, false, 4))
+	require.NoError(t, sendChaosTestMessage(t, t1, true, 5))
+	require.NoError(t, sendChaosTestMessage(t, t1, false, 6))
+
+	var got []uint32
+	for i := 0; i < 6; i++ {
+		got = append(got, recvChaosTestMessage(t, t2))
+	}
+	assert.Equal(t, []uint32{2, 1, 3, 4, 5, 6}, got)
+
+	// A held message is sent once the reorder window elapses, even if
+	// nothing else is sent.
+	start := time.Now()
+	require.NoError(t, sendChaosTestMessage(t, t1, true, 7))
+	assert.Equal(t, uint32(7), recvChaosTestMessage(t, t2))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
+
+func TestChaosDrop(t *testing.T) {
+	t.Parallel()
+
+	t1, t2 := newChaosPipe(ChaosConfig{DropProbability: 1})
+	defer t2.Close()
+
+	err := sendChaosTestMessage(t, t1, false, 1)
+	assert.ErrorIs(t, err, ErrChaosDrop)
+	_, err = t2.RecvMessage()
+	assert.Error(t, err, "the connection should be closed after a drop")
+	assert.NoError(t, t1.Close())
+}
+
+func TestChaosCloseAfter(t *testing.T) {
+	t.Parallel()
+
+	t1, t2 := newChaosPipe(ChaosConfig{CloseAfter: 2})
+	defer t2.Close()
+
+	require.NoError(t, sendChaosTestMessage(t, t1, false, 1))
+	require.NoError(t, sendChaosTestMessage(t, t1, false, 2))
+	err := sendChaosTestMessage(t, t1, false, 3)
+	assert.ErrorIs(t, err, ErrChaosClose)
+
+	assert.Equal(t, uint32(1), recvChaosTestMessage(t, t2))
+	assert.Equal(t, uint32(2), recvChaosTestMessage(t, t2))
+	_, err = t2.RecvMessage()
+	assert.Error(t, err, "the connection should be closed")
+	assert.NoError(t, t1.Close())
+}
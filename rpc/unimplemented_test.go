@@ -0,0 +1,118 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3/rpc"
+	rpccp "capnproto.org/go/capnp/v3/std/capnp/rpc"
+)
+
+// TestUnimplementedReplies checks that messages the Conn does not
+// support are echoed back in Unimplemented messages, and reported to
+// Options.OnUnimplemented.
+func TestUnimplementedReplies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	observed := make(chan rpccp.Message_Which, 10)
+	conn, p2 := newTestConn(&rpc.Options{
+		Logger: testErrorReporter{tb: t},
+		OnUnimplemented: func(which rpccp.Message_Which) {
+			observed <- which
+		},
+	})
+	defer finishTest(t, conn, p2)
+
+	tests := []struct {
+		name  string
+		which rpccp.Message_Which
+		send  func() error
+	}{
+		{
+			name:  "join",
+			which: rpccp.Message_Which_join,
+			send: func() error {
+				outMsg, err := p2.NewMessage()
+				if err != nil {
+					return err
+				}
+				defer outMsg.Release()
+				join, err := outMsg.Message().NewJoin()
+				if err != nil {
+					return err
+				}
+				join.SetQuestionId(3)
+				return outMsg.Send()
+			},
+		},
+		{
+			name:  "accept",
+			which: rpccp.Message_Which_accept,
+			send: func() error {
+				outMsg, err := p2.NewMessage()
+				if err != nil {
+					return err
+				}
+				defer outMsg.Release()
+				accept, err := outMsg.Message().NewAccept()
+				if err != nil {
+					return err
+				}
+				accept.SetQuestionId(4)
+				return outMsg.Send()
+			},
+		},
+		{
+			name:  "call to yourself",
+			which: rpccp.Message_Which_call,
+			send: func() error {
+				return sendMessage(ctx, p2, &rpcMessage{
+					Which: rpccp.Message_Which_call,
+					Call: &rpcCall{
+						QuestionID: 5,
+						Target: rpcMessageTarget{
+							Which:       rpccp.MessageTarget_Which_importedCap,
+							ImportedCap: 0,
+						},
+						SendResultsTo: rpcCallSendResultsTo{
+							Which: rpccp.Call_sendResultsTo_Which_yourself,
+						},
+					},
+				})
+			},
+		},
+		{
+			name:  "disembargo accept",
+			which: rpccp.Message_Which_disembargo,
+			send: func() error {
+				return sendMessage(ctx, p2, &rpcMessage{
+					Which: rpccp.Message_Which_disembargo,
+					Disembargo: &rpcDisembargo{
+						Target: rpcMessageTarget{
+							Which:       rpccp.MessageTarget_Which_importedCap,
+							ImportedCap: 0,
+						},
+						Context: rpcDisembargoContext{
+							Which: rpccp.Disembargo_context_Which_accept,
+						},
+					},
+				})
+			},
+		},
+	}
+	for _, test := range tests {
+		require.NoError(t, test.send(), test.name)
+
+		rmsg, release, err := recvMessage(ctx, p2)
+		require.NoError(t, err, test.name)
+		require.Equal(t, rpccp.Message_Which_unimplemented, rmsg.Which, test.name)
+		assert.Equal(t, test.which, rmsg.Unimplemented.Which, test.name)
+		release()
+
+		assert.Equal(t, test.which, <-observed, test.name)
+	}
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+	"reflect"
+)
+
+type connKey struct{}
+
+// ConnFromContext returns the Conn that delivered a call, given the
+// context passed to the call's server implementation or to a
+// server.Interceptor.  It returns false if the call did not arrive over
+// a Conn.
+func ConnFromContext(ctx context.Context) (*Conn, bool) {
+	c, ok := ctx.Value(connKey{}).(*Conn)
+	return c, ok
+}
+
+// SetValue associates val with key for the lifetime of the Conn, so
+// that per-connection state such as a session or an authorization cache
+// can be shared by everything that serves the connection, without a
+// global map keyed by PeerID.  Setting a nil val removes the key.
+//
+// As with context.WithValue, key must be comparable, and should be of
+// an unexported type or a ConnKey to avoid collisions between packages.
+// SetValue is safe to call from multiple goroutines.
+func (c *Conn) SetValue(key, val any) {
+	if key == nil {
+		panic("rpc: nil Conn value key")
+	}
+	if !reflect.TypeOf(key).Comparable() {
+		panic("rpc: Conn value key is not comparable")
+	}
+	if val == nil {
+		c.values.Delete(key)
+		return
+	}
+	c.values.Store(key, val)
+}
+
+// Value returns the value associated with key by SetValue, or nil if
+// there is none.
+func (c *Conn) Value(key any) any {
+	val, _ := c.values.Load(key)
+	return val
+}
+
+// A ConnKey is a Conn value key that carries the type of its value, so
+// that values can be read without a type assertion.  Each ConnKey is a
+// distinct key; the name is only used for debugging.
+type ConnKey[T any] struct {
+	name string
+}
+
+// NewConnKey returns a new key for values of type T.
+func NewConnKey[T any](name string) *ConnKey[T] {
+	return &ConnKey[T]{name: name}
+}
+
+// Get returns the value stored under k on c, and whether there was one.
+func (k *ConnKey[T]) Get(c *Conn) (T, bool) {
+	val, ok := c.Value(k).(T)
+	return val, ok
+}
+
+// Set stores val under k on c.
+func (k *ConnKey[T]) Set(c *Conn, val T) {
+	c.SetValue(k, val)
+}
+
+// String returns the key's name.
+func (k *ConnKey[T]) String() string {
+	return k.name
+}
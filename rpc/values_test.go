@@ -0,0 +1,95 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	testcp "capnproto.org/go/capnp/v3/rpc/internal/testcapnp"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+// session is per-connection state set by an interceptor.
+type session struct {
+	user  string
+	calls int
+}
+
+var sessionKey = rpc.NewConnKey[*session]("session")
+
+// sessionCounter is a PingPong that counts calls in the Conn's session
+// and echoes the count.
+type sessionCounter struct{}
+
+func (sessionCounter) EchoNum(ctx context.Context, call testcp.PingPong_echoNum) error {
+	conn, ok := rpc.ConnFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	sess, ok := sessionKey.Get(conn)
+	if !ok {
+		return nil
+	}
+	sess.calls++
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	res.SetN(int64(sess.calls))
+	return nil
+}
+
+func TestConnValues(t *testing.T) {
+	t.Parallel()
+
+	_, ok := rpc.ConnFromContext(context.Background())
+	assert.False(t, ok, "context without a Conn should have no Conn")
+
+	setSession := func(ctx context.Context, call *server.Call, next func(context.Context, *server.Call) error) error {
+		if conn, ok := rpc.ConnFromContext(ctx); ok {
+			if _, ok := sessionKey.Get(conn); !ok {
+				sessionKey.Set(conn, &session{user: "alice"})
+			}
+		}
+		return next(ctx, call)
+	}
+	boot := testcp.PingPong_ServerToClientWithOptions(sessionCounter{}, &server.Options{
+		Interceptors: []server.Interceptor{setSession},
+	})
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(boot),
+		Logger:          testErrorReporter{tb: t},
+	}, &rpc.Options{
+		Logger: testErrorReporter{tb: t},
+	})
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	ctx := context.Background()
+	pp := testcp.PingPong(clientConn.Bootstrap(ctx))
+	defer pp.Release()
+	for want := int64(1); want <= 2; want++ {
+		ans, release := pp.EchoNum(ctx, nil)
+		res, err := ans.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, want, res.N())
+		release()
+	}
+
+	sess, ok := sessionKey.Get(serverConn)
+	require.True(t, ok)
+	assert.Equal(t, "alice", sess.user)
+	_, ok = sessionKey.Get(clientConn)
+	assert.False(t, ok, "values are per Conn")
+
+	type otherKey struct{}
+	serverConn.SetValue(otherKey{}, 42)
+	assert.Equal(t, 42, serverConn.Value(otherKey{}))
+	serverConn.SetValue(otherKey{}, nil)
+	assert.Nil(t, serverConn.Value(otherKey{}))
+	assert.Panics(t, func() { serverConn.SetValue([]byte("key"), 1) })
+}
@@ -0,0 +1,278 @@
+// Package rpcproxy provides a capability that transparently forwards
+// calls to another capability.
+//
+// A proxy forwards every call it receives, including calls to methods
+// it knows nothing about, while recording statistics and giving the
+// application a chance to intercept each call.  This is the building
+// block for gateways, recording proxies and fault injection tools.
+package rpcproxy // import "capnproto.org/go/capnp/v3/rpcproxy"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// An Interceptor is called before a call is forwarded to the target.
+// If it returns a non-nil error, the call fails with that error and is
+// not forwarded.  An Interceptor may block, for example to inject
+// latency, and must be safe to call from multiple goroutines.
+type Interceptor func(ctx context.Context, m capnp.Method) error
+
+// New returns a client that forwards calls to target.  Interceptors are
+// run in order before each call is forwarded.  New takes ownership of
+// target: it is released when the returned client is shut down.
+//
+// The returned Stats are updated as calls pass through the proxy.
+func New(target capnp.Client, interceptors ...Interceptor) (capnp.Client, *Stats) {
+	stats := &Stats{methods: make(map[methodKey]*MethodStats)}
+	p := &proxy{
+		target:       target,
+		interceptors: interceptors,
+		stats:        stats,
+	}
+	return capnp.NewClient(p), stats
+}
+
+// A proxy is the capnp.ClientHook behind a client returned by New.
+type proxy struct {
+	target       capnp.Client
+	interceptors []Interceptor
+	stats        *Stats
+}
+
+// intercept runs the interceptors for a call to m.
+func (p *proxy) intercept(ctx context.Context, m capnp.Method) error {
+	for _, f := range p.interceptors {
+		if err := f(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *proxy) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	start := p.stats.start(s.Method)
+	if err := p.intercept(ctx, s.Method); err != nil {
+		p.stats.finish(s.Method, start, err, true)
+		return capnp.ErrorAnswer(s.Method, err), func() {}
+	}
+
+	ans, release := p.target.SendCall(ctx, s)
+	sr := &sendRecorder{
+		stats:    p.stats,
+		method:   s.Method,
+		start:    start,
+		ans:      ans,
+		released: make(chan struct{}),
+	}
+	select {
+	case <-ans.Done():
+		sr.returned()
+	default:
+		go sr.wait()
+	}
+	return ans, sr.release(release)
+}
+
+func (p *proxy) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	start := p.stats.start(r.Method)
+	if err := p.intercept(ctx, r.Method); err != nil {
+		p.stats.finish(r.Method, start, err, true)
+		r.Reject(err)
+		return nil
+	}
+
+	r.Returner = &recordingReturner{
+		Returner: r.Returner,
+		stats:    p.stats,
+		method:   r.Method,
+		start:    start,
+	}
+	return p.target.RecvCall(ctx, r)
+}
+
+func (p *proxy) Brand() capnp.Brand {
+	return capnp.Brand{Value: p}
+}
+
+func (p *proxy) Shutdown() {
+	p.target.Release()
+}
+
+func (p *proxy) String() string {
+	return "rpcproxy(" + p.target.String() + ")"
+}
+
+// recordingReturner records the outcome of a call received by a proxy.
+type recordingReturner struct {
+	capnp.Returner
+	stats  *Stats
+	method capnp.Method
+	start  time.Time
+	err    error
+}
+
+func (rr *recordingReturner) PrepareReturn(e error) {
+	rr.err = e
+	rr.Returner.PrepareReturn(e)
+}
+
+func (rr *recordingReturner) Return() {
+	rr.Returner.Return()
+	rr.stats.finish(rr.method, rr.start, rr.err, false)
+}
+
+// errReleased is recorded for calls sent through a proxy that the
+// caller released before they returned.
+var errReleased = errors.New("rpcproxy: call released before it returned")
+
+// sendRecorder records the outcome of a call sent through a proxy.  The
+// answer is only read while the caller has not released it.
+type sendRecorder struct {
+	stats  *Stats
+	method capnp.Method
+	start  time.Time
+	ans    *capnp.Answer
+
+	mu          sync.Mutex
+	recorded    bool
+	released    chan struct{} // closed by the caller's release
+	releaseOnce sync.Once
+}
+
+// wait records the outcome of the call once it returns, unless the
+// caller releases it first.
+func (sr *sendRecorder) wait() {
+	select {
+	case <-sr.ans.Done():
+		sr.returned()
+	case <-sr.released:
+	}
+}
+
+// returned records the outcome of a call that has returned.
+func (sr *sendRecorder) returned() {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if !sr.recorded {
+		_, err := sr.ans.Future().Ptr()
+		sr.finish(err)
+	}
+}
+
+// release returns a ReleaseFunc that records the outcome of the call,
+// if that has not been done yet, before calling f.
+func (sr *sendRecorder) release(f capnp.ReleaseFunc) capnp.ReleaseFunc {
+	return func() {
+		sr.mu.Lock()
+		if !sr.recorded {
+			select {
+			case <-sr.ans.Done():
+				_, err := sr.ans.Future().Ptr()
+				sr.finish(err)
+			default:
+				sr.finish(errReleased)
+			}
+		}
+		sr.mu.Unlock()
+		sr.releaseOnce.Do(func() { close(sr.released) })
+		f()
+	}
+}
+
+// finish records err as the outcome of the call.  The caller must hold
+// sr.mu.
+func (sr *sendRecorder) finish(err error) {
+	sr.recorded = true
+	sr.stats.finish(sr.method, sr.start, err, false)
+}
+
+// methodKey identifies a method independently of the names in a
+// capnp.Method, which callers may or may not fill in.
+type methodKey struct {
+	interfaceID uint64
+	methodID    uint16
+}
+
+// Stats records the calls that passed through a proxy.  It is safe to
+// use from multiple goroutines.  The outcome of a call may be recorded
+// shortly after the caller has observed it.
+type Stats struct {
+	mu      sync.Mutex
+	total   MethodStats
+	methods map[methodKey]*MethodStats
+}
+
+// MethodStats holds counters for calls to a method, or to all methods.
+type MethodStats struct {
+	Calls       uint64        // Calls received, including ones in progress
+	Inflight    uint64        // Calls that have not returned yet
+	Errors      uint64        // Calls that returned an error
+	Intercepted uint64        // Calls failed by an Interceptor; these count as Errors too
+	Latency     time.Duration // Total time spent by calls that returned
+}
+
+// Total returns the counters for all calls.
+func (s *Stats) Total() MethodStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// Method returns the counters for calls to m.
+func (s *Stats) Method(m capnp.Method) MethodStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ms := s.methods[methodKey{m.InterfaceID, m.MethodID}]; ms != nil {
+		return *ms
+	}
+	return MethodStats{}
+}
+
+// Methods returns the counters for every method that has been called,
+// keyed by method.  Only InterfaceID and MethodID are set in the keys.
+func (s *Stats) Methods() map[capnp.Method]MethodStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	methods := make(map[capnp.Method]MethodStats, len(s.methods))
+	for k, ms := range s.methods {
+		methods[capnp.Method{InterfaceID: k.interfaceID, MethodID: k.methodID}] = *ms
+	}
+	return methods
+}
+
+func (s *Stats) start(m capnp.Method) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := methodKey{m.InterfaceID, m.MethodID}
+	ms := s.methods[k]
+	if ms == nil {
+		ms = new(MethodStats)
+		s.methods[k] = ms
+	}
+	for _, ms := range [...]*MethodStats{&s.total, ms} {
+		ms.Calls++
+		ms.Inflight++
+	}
+	return time.Now()
+}
+
+func (s *Stats) finish(m capnp.Method, start time.Time, err error, intercepted bool) {
+	d := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ms := range [...]*MethodStats{&s.total, s.methods[methodKey{m.InterfaceID, m.MethodID}]} {
+		ms.Inflight--
+		ms.Latency += d
+		if err != nil {
+			ms.Errors++
+		}
+		if intercepted {
+			ms.Intercepted++
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package rpcproxy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpcproxy"
+	"capnproto.org/go/capnp/v3/server"
+)
+
+type echoImpl struct{}
+
+func (echoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	if in == "fail" {
+		return errors.New("echo failed")
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + in)
+}
+
+func echo(ctx context.Context, e air.Echo, in string) (string, error) {
+	ans, release := e.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn(in)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+	return res.Out()
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client, stats := rpcproxy.New(capnp.Client(air.Echo_ServerToClient(echoImpl{})))
+	e := air.Echo(client)
+	defer e.Release()
+
+	out, err := echo(ctx, e, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foofoo", out)
+
+	_, err = echo(ctx, e, "fail")
+	assert.ErrorContains(t, err, "echo failed")
+
+	require.Eventually(t, func() bool {
+		return stats.Total().Inflight == 0
+	}, time.Second, time.Millisecond, "calls should finish")
+	total := stats.Total()
+	assert.Equal(t, uint64(2), total.Calls)
+	assert.Equal(t, uint64(1), total.Errors)
+	assert.Equal(t, total, stats.Method(capnp.Method{InterfaceID: air.Echo_TypeID, MethodID: 0}))
+	assert.Len(t, stats.Methods(), 1)
+}
+
+func TestProxyUnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	client, stats := rpcproxy.New(capnp.NewClient(server.New(nil, nil, nil)))
+	e := air.Echo(client)
+	defer e.Release()
+
+	_, err := echo(ctx, e, "foo")
+	assert.Error(t, err, "call to unimplemented method should fail")
+	require.Eventually(t, func() bool {
+		return stats.Total().Inflight == 0
+	}, time.Second, time.Millisecond, "call should finish")
+	assert.Equal(t, uint64(1), stats.Total().Calls)
+	assert.Equal(t, uint64(1), stats.Total().Errors)
+}
+
+func TestProxyInterceptor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errInjected := errors.New("injected failure")
+	var seen []capnp.Method
+	client, stats := rpcproxy.New(
+		capnp.Client(air.Echo_ServerToClient(echoImpl{})),
+		func(ctx context.Context, m capnp.Method) error {
+			seen = append(seen, m)
+			return nil
+		},
+		func(ctx context.Context, m capnp.Method) error {
+			return errInjected
+		},
+	)
+	e := air.Echo(client)
+	defer e.Release()
+
+	_, err := echo(ctx, e, "foo")
+	assert.ErrorIs(t, err, errInjected)
+	require.Len(t, seen, 1)
+	assert.Equal(t, uint64(air.Echo_TypeID), seen[0].InterfaceID)
+
+	total := stats.Total()
+	assert.Equal(t, uint64(1), total.Calls)
+	assert.Equal(t, uint64(1), total.Errors)
+	assert.Equal(t, uint64(1), total.Intercepted)
+}
+
+type blockingEchoImpl struct {
+	unblock chan struct{}
+}
+
+func (e blockingEchoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	<-e.unblock
+	return echoImpl{}.Echo(ctx, call)
+}
+
+func TestProxyReleaseBeforeReturn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	impl := blockingEchoImpl{unblock: make(chan struct{})}
+	client, stats := rpcproxy.New(capnp.Client(air.Echo_ServerToClient(impl)))
+	e := air.Echo(client)
+	defer e.Release()
+
+	_, release := e.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn("foo")
+	})
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		release()
+	}()
+	close(impl.unblock)
+	<-released
+
+	require.Eventually(t, func() bool {
+		return stats.Total().Inflight == 0
+	}, time.Second, time.Millisecond, "call should finish")
+	assert.Equal(t, uint64(1), stats.Total().Calls)
+}
@@ -83,18 +83,22 @@ func (s *Segment) readRawPointer(addr address) rawPointer {
 }
 
 func (s *Segment) writeUint8(addr address, val uint8) {
+	s.msg.checkOwner("write")
 	s.slice(addr, 1)[0] = val
 }
 
 func (s *Segment) writeUint16(addr address, val uint16) {
+	s.msg.checkOwner("write")
 	binary.LittleEndian.PutUint16(s.slice(addr, 2), val)
 }
 
 func (s *Segment) writeUint32(addr address, val uint32) {
+	s.msg.checkOwner("write")
 	binary.LittleEndian.PutUint32(s.slice(addr, 4), val)
 }
 
 func (s *Segment) writeUint64(addr address, val uint64) {
+	s.msg.checkOwner("write")
 	binary.LittleEndian.PutUint64(s.slice(addr, 8), val)
 }
 
@@ -126,6 +130,19 @@ func (s *Segment) lookupSegment(id SegmentID) (*Segment, error) {
 }
 
 func (s *Segment) readPtr(paddr address, depthLimit uint) (ptr Ptr, err error) {
+	ptr, err = s.readPtrUnaccounted(paddr, depthLimit)
+	if err != nil {
+		return Ptr{}, err
+	}
+	if err := s.Message().chargeRead(ptr.readSize()); err != nil {
+		return Ptr{}, exc.WrapError("read pointer", err)
+	}
+	return ptr, nil
+}
+
+// readPtrUnaccounted is like readPtr, but does not charge the object
+// to the message's read limit.
+func (s *Segment) readPtrUnaccounted(paddr address, depthLimit uint) (ptr Ptr, err error) {
 	s, base, val, err := s.resolveFarPointer(paddr)
 	if err != nil {
 		return Ptr{}, exc.WrapError("read pointer", err)
@@ -142,9 +159,6 @@ func (s *Segment) readPtr(paddr address, depthLimit uint) (ptr Ptr, err error) {
 		if err != nil {
 			return Ptr{}, exc.WrapError("read pointer", err)
 		}
-		if !s.Message().canRead(sp.readSize()) {
-			return Ptr{}, errors.New("read pointer: read traversal limit reached")
-		}
 		sp.depthLimit = depthLimit - 1
 		return sp.ToPtr(), nil
 	case listPointer:
@@ -152,9 +166,6 @@ func (s *Segment) readPtr(paddr address, depthLimit uint) (ptr Ptr, err error) {
 		if err != nil {
 			return Ptr{}, exc.WrapError("read pointer", err)
 		}
-		if !s.Message().canRead(lp.readSize()) {
-			return Ptr{}, errors.New("read pointer: read traversal limit reached")
-		}
 		lp.depthLimit = depthLimit - 1
 		return lp.ToPtr(), nil
 	case otherPointer:
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+
+	"capnproto.org/go/capnp/v3/exc"
+)
+
+// An ErrorMapper translates an error returned by a method call into
+// the exception sent to the caller.  It decides which exception type a
+// caller sees and how much of an application error leaks across the
+// vat boundary.  If it returns nil, the error is returned unchanged.
+type ErrorMapper func(err error) *exc.Exception
+
+// An ErrorRule maps errors to an exception, for use with
+// NewErrorMapper.
+type ErrorRule struct {
+	// Target selects the errors the rule applies to: those for which
+	// errors.Is(err, Target) reports true.  A nil Target matches any
+	// error, which is useful as a final catch-all rule.
+	Target error
+
+	// Type is the type of the resulting exception.
+	Type exc.Type
+
+	// Message replaces the error's message, so that details of the
+	// error are not sent to the caller.  If empty, the error's own
+	// message is kept.
+	Message string
+}
+
+// NewErrorMapper returns an ErrorMapper that applies the first rule
+// matching an error.  Errors that match no rule are returned unchanged.
+// For example:
+//
+//	server.NewErrorMapper(
+//		server.ErrorRule{Target: context.DeadlineExceeded, Type: exc.Overloaded, Message: "deadline exceeded"},
+//		server.ErrorRule{Target: ErrNotFound, Type: exc.Failed},
+//		server.ErrorRule{Type: exc.Failed, Message: "internal error"},
+//	)
+func NewErrorMapper(rules ...ErrorRule) ErrorMapper {
+	rules = append([]ErrorRule(nil), rules...)
+	return func(err error) *exc.Exception {
+		for _, r := range rules {
+			if r.Target != nil && !errors.Is(err, r.Target) {
+				continue
+			}
+			if r.Message != "" {
+				return exc.New(r.Type, "", r.Message)
+			}
+			return &exc.Exception{Type: r.Type, Cause: err}
+		}
+		return nil
+	}
+}
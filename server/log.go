@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/provenance"
+)
+
+// Logger is used by LogCalls to log method calls.  Each method logs a
+// message at a different level, followed by a sequence of key, value
+// pairs.  The methods may not block for long periods of time.
+//
+// This interface is designed such that it is satisfied by *slog.Logger.
+type Logger interface {
+	Debug(message string, args ...any)
+	Info(message string, args ...any)
+	Warn(message string, args ...any)
+	Error(message string, args ...any)
+}
+
+// LogOptions controls which calls LogCalls logs and what it records.
+// The zero value logs every call.
+type LogOptions struct {
+	// SampleRate is the fraction of successful calls that are logged,
+	// between 0 and 1.  If zero, every call is logged.  Failed calls and
+	// slow calls are always logged.
+	SampleRate float64
+
+	// SlowCall, if positive, is the duration after which a successful
+	// call is considered slow.  Slow calls are logged as warnings.
+	SlowCall time.Duration
+
+	// Peer, if not nil, returns a value identifying the caller, given
+	// the call's context.  It is logged as "peer".  For calls received
+	// over an rpc.Conn, rpc.PeerIDFromContext can be used to find the
+	// remote vat.
+	Peer func(ctx context.Context) any
+}
+
+// LogCalls returns an Interceptor that logs each method call to l once
+// it returns.  Successful calls are logged at the info level and failed
+// calls at the error level.  Each entry records the method, its
+// duration, the exception type of a failed call, the caller (see
+// LogOptions.Peer), the delegation chain of the capability the call was
+// made through, if any (see package provenance), and the sizes in bytes
+// of the messages holding the call's arguments and results.  If opts is nil, every call is logged.
+func LogCalls(l Logger, opts *LogOptions) Interceptor {
+	var o LogOptions
+	if opts != nil {
+		o = *opts
+	}
+	return func(ctx context.Context, call *Call, next func(context.Context, *Call) error) error {
+		start := time.Now()
+		argsSize := messageSize(call.Args())
+		err := next(ctx, call)
+		d := time.Since(start)
+
+		slow := o.SlowCall > 0 && d >= o.SlowCall
+		if err == nil && !slow && o.SampleRate > 0 && rand.Float64() >= o.SampleRate {
+			return nil
+		}
+
+		m := call.Method()
+		args := []any{
+			"method", m.String(),
+			"duration", d,
+			"args_size", argsSize,
+		}
+		if o.Peer != nil {
+			args = append(args, "peer", o.Peer(ctx))
+		}
+		if chain := provenance.FromContext(ctx); len(chain) > 0 {
+			args = append(args, "delegation", chain.String())
+		}
+		switch {
+		case err != nil:
+			args = append(args, "error", err, "error_type", exc.TypeOf(err).String())
+			l.Error("rpc call failed", args...)
+		case slow:
+			args = append(args, "results_size", messageSize(call.results))
+			l.Warn("slow rpc call", args...)
+		default:
+			args = append(args, "results_size", messageSize(call.results))
+			l.Info("rpc call", args...)
+		}
+		return err
+	}
+}
+
+// messageSize returns the size of the message holding s, or zero if
+// s is not in a message.
+func messageSize(s capnp.Struct) uint64 {
+	msg := s.Message()
+	if msg == nil {
+		return 0
+	}
+	n, _ := msg.TotalSize()
+	return n
+}
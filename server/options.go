@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// An Interceptor wraps the implementation of every method call made on
+// a server.  It must call next to run the method, or return an error
+// to fail the call without running it.  Interceptors are run on the
+// goroutine that handles the call, so they are subject to the same
+// ordering guarantees as method implementations.
+type Interceptor func(ctx context.Context, call *Call, next func(context.Context, *Call) error) error
+
+// Options configures the behavior of a Server.  The zero value
+// configures a Server with no interceptors that services one call at a
+// time and returns method errors unchanged.
+type Options struct {
+	// Interceptors are run, in order, around each method call.  The
+	// first Interceptor is outermost.
+	Interceptors []Interceptor
+
+	// MaxConcurrentCalls, if positive, lets the server run up to that
+	// many calls at once without method implementations having to call
+	// Call.Go.  Calls are still started in the order they were received.
+	// If zero, the server services one call at a time, as described in
+	// the documentation for New.
+	MaxConcurrentCalls int
+
+	// WorkerPool, if not nil, runs the server's calls on the pool's
+	// workers instead of on a goroutine per call.  Like
+	// MaxConcurrentCalls, it lets calls run concurrently without
+	// method implementations calling Call.Go, and the two may be
+	// combined to also bound the share of the pool used by one server.
+	// Actor overrides WorkerPool.
+	WorkerPool *WorkerPool
+
+	// Actor makes the server run like an actor: its methods are run
+	// one at a time, in the order they were received, and a method
+	// that calls Call.Go to wait on a long operation can use Call.Sync
+	// to touch the implementation's state in turn with other calls.
+	// Stateful implementations then need no locks.  Actor overrides
+	// MaxConcurrentCalls and WorkerPool, including ones set by
+	// SetDefaultOptions, so implementations that rely on Call.Sync
+	// should set it.
+	Actor bool
+
+	// ErrorMapper, if not nil, translates the error returned by each
+	// failed call, including errors returned by Interceptors, into the
+	// exception returned to the caller.
+	ErrorMapper ErrorMapper
+
+	// ArgsTraverseLimit and ArgsDepthLimit, if not zero, replace the
+	// traversal limit of the message holding the arguments of each
+	// call and the depth limit of the arguments, before the call is
+	// handed to interceptors and the method implementation.  They
+	// bound the decoding work a caller can make the server do,
+	// independently of the limits applied to returns and other
+	// messages.  See capnp.Message and capnp.Struct.WithDepthLimit for
+	// details.
+	ArgsTraverseLimit uint64
+	ArgsDepthLimit    uint
+
+	// NewResultsArena, if not nil, is called with the method being
+	// called to create the arena that Call.AllocResults allocates the
+	// results in, in place of a new MultiSegment arena.  The arena is
+	// released once the caller releases the results, so it can be
+	// drawn from a pool, with a first segment sized from the results
+	// of earlier calls to the method.  It only applies to calls made
+	// in-process: the results of a call received over an rpc.Conn are
+	// allocated in the Return message.
+	NewResultsArena func(capnp.Method) capnp.Arena
+
+	// ProfilerLabels makes the server run each call, including its
+	// interceptors, with the pprof labels "capnp.interface" and
+	// "capnp.method" set to the names of the interface and method
+	// called, so that CPU and goroutine profiles attribute their cost
+	// to methods.  Labels already on the call's Context are kept, such
+	// as "capnp.peer", which rpc.Conn sets to the remote vat's ID.
+	// Unnamed interfaces and methods are labeled with their IDs.
+	ProfilerLabels bool
+}
+
+var defaultOptions atomic.Pointer[Options]
+
+// DefaultOptions returns the options used by servers that are created
+// without explicit options, such as by New or the generated
+// ServerToClient functions.
+func DefaultOptions() Options {
+	if opts := defaultOptions.Load(); opts != nil {
+		return *opts
+	}
+	return Options{}
+}
+
+// SetDefaultOptions sets the options returned by DefaultOptions.  This
+// allows cross-cutting configuration, like logging interceptors or an
+// error policy, to be applied to every server in a process.  Servers
+// that have already been created are not affected, so this is usually
+// called during program initialization.
+func SetDefaultOptions(opts Options) {
+	opts.Interceptors = append([]Interceptor(nil), opts.Interceptors...)
+	defaultOptions.Store(&opts)
+}
+
+// chain returns an implementation that runs impl within interceptors.
+func chain(interceptors []Interceptor, impl func(context.Context, *Call) error) func(context.Context, *Call) error {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		f, next := interceptors[i], impl
+		impl = func(ctx context.Context, c *Call) error {
+			return f(ctx, c, next)
+		}
+	}
+	return impl
+}
@@ -0,0 +1,109 @@
+package server
+
+import "sync"
+
+// A WorkerPool runs method calls on a fixed number of goroutines.  By
+// default, a Server that runs calls concurrently, because of
+// Options.MaxConcurrentCalls, starts a goroutine for each call.  A
+// process serving many concurrent calls can instead share a WorkerPool
+// across its servers through Options.WorkerPool, bounding the number of
+// goroutines running methods regardless of how many capabilities it
+// exports.
+//
+// Workers are started as they are needed, up to the pool's size, and
+// then stay around until the pool is closed.  When all workers are busy,
+// the servers using the pool stop delivering calls until one is free.
+// Methods that wait on calls to other servers sharing the same pool can
+// therefore deadlock once the pool is full; such methods should use a
+// separate pool, or none.
+//
+// A WorkerPool is safe to use from multiple goroutines.
+type WorkerPool struct {
+	work chan func()
+
+	// done is closed by Close.  work is never closed, so that run can
+	// race with Close without sending on a closed channel.
+	done chan struct{}
+
+	mu      sync.Mutex
+	size    int
+	workers int
+	closed  bool
+}
+
+// errPoolClosed is the error returned for calls delivered to a closed
+// WorkerPool.
+var errPoolClosed = newError("worker pool closed")
+
+// NewWorkerPool returns a pool that runs up to size calls at once.  It
+// panics if size is not positive.
+func NewWorkerPool(size int) *WorkerPool {
+	if size <= 0 {
+		panic("server: worker pool size must be positive")
+	}
+	return &WorkerPool{
+		work: make(chan func()),
+		done: make(chan struct{}),
+		size: size,
+	}
+}
+
+// Close stops the pool's workers once they have finished the calls they
+// are running.  It should only be called once no server using the pool
+// can receive further calls, i.e. once those servers have shut down;
+// calls delivered afterwards fail without running.
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+}
+
+// run calls f on one of the pool's workers, blocking until a worker
+// is free.  It returns an error without calling f if the pool is closed.
+func (p *WorkerPool) run(f func()) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return errPoolClosed
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.work <- f:
+		return nil
+	default:
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return errPoolClosed
+	}
+	if p.workers < p.size {
+		p.workers++
+		p.mu.Unlock()
+		go p.worker(f)
+		return nil
+	}
+	p.mu.Unlock()
+	select {
+	case p.work <- f:
+		return nil
+	case <-p.done:
+		return errPoolClosed
+	}
+}
+
+func (p *WorkerPool) worker(f func()) {
+	f()
+	for {
+		select {
+		case f := <-p.work:
+			f()
+		case <-p.done:
+			return
+		}
+	}
+}
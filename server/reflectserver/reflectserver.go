@@ -0,0 +1,195 @@
+// Package reflectserver implements Cap'n Proto interfaces with plain Go
+// methods, using reflection instead of generated code.
+package reflectserver // import "capnproto.org/go/capnp/v3/server/reflectserver"
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/pogs"
+	"capnproto.org/go/capnp/v3/server"
+	"capnproto.org/go/capnp/v3/std/capnp/schema"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewFromStruct returns a server for the interface described by iface
+// whose methods are implemented by the exported methods of the Go value
+// impl, using reflection instead of generated code.  It is intended for
+// prototypes and tests.  It lives in its own package so that package
+// server, which generated code imports, does not depend on package pogs.
+//
+// A schema method named "fooBar" is served by an exported method named
+// FooBar with one of the signatures:
+//
+//	func(ctx context.Context, params P) (results R, err error)
+//	func(ctx context.Context, params P) error
+//
+// where P and R are Go structs (or pointers to Go structs) that follow
+// the mapping rules of package pogs.  Schema methods with no matching Go
+// method return an unimplemented error, as do methods of superclasses
+// that cannot be found in schemas.DefaultRegistry.  The parameter and
+// result structs must be registered in schemas.DefaultRegistry.
+//
+// If impl implements server.Shutdowner, its Shutdown method is called
+// when the server shuts down.  server.IsServer returns impl as the
+// server's brand.
+func NewFromStruct(iface schema.Node, impl any) (*server.Server, error) {
+	if iface.Which() != schema.Node_Which_interface {
+		return nil, fmt.Errorf("reflectserver: node %#x is a %v, not an interface", iface.Id(), iface.Which())
+	}
+	r := &reflector{impl: reflect.ValueOf(impl)}
+	if err := r.addInterface(iface, make(map[uint64]bool)); err != nil {
+		return nil, fmt.Errorf("reflectserver: %v", err)
+	}
+	shutdown, _ := impl.(server.Shutdowner)
+	return server.New(r.methods, impl, shutdown), nil
+}
+
+type reflector struct {
+	impl    reflect.Value
+	nodes   nodemap.Map
+	methods []server.Method
+}
+
+// addInterface adds methods for iface and its superclasses.
+func (r *reflector) addInterface(iface schema.Node, seen map[uint64]bool) error {
+	if seen[iface.Id()] {
+		return nil
+	}
+	seen[iface.Id()] = true
+	name, err := iface.DisplayName()
+	if err != nil {
+		return err
+	}
+	methods, err := iface.Interface().Methods()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for i := 0; i < methods.Len(); i++ {
+		m := methods.At(i)
+		mname, err := m.Name()
+		if err != nil {
+			return fmt.Errorf("%s: method @%d: %v", name, i, err)
+		}
+		gm := r.impl.MethodByName(exportedName(mname))
+		if !gm.IsValid() {
+			continue
+		}
+		impl, err := r.methodImpl(gm, m)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", name, mname, err)
+		}
+		r.methods = append(r.methods, server.Method{
+			Method: capnp.Method{
+				InterfaceID:   iface.Id(),
+				MethodID:      uint16(i),
+				InterfaceName: name,
+				MethodName:    mname,
+			},
+			Impl: impl,
+		})
+	}
+	supers, err := iface.Interface().Superclasses()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for i := 0; i < supers.Len(); i++ {
+		n, err := r.nodes.Find(supers.At(i).Id())
+		if err != nil {
+			// Leave the superclass's methods unimplemented.
+			continue
+		}
+		if err := r.addInterface(schema.Node(capnp.Struct(n)), seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// methodImpl checks that gm has an acceptable signature for m and
+// returns a function that calls it.
+func (r *reflector) methodImpl(gm reflect.Value, m schema.Method) (func(context.Context, *server.Call) error, error) {
+	t := gm.Type()
+	if t.NumIn() != 2 || t.In(0) != contextType || !isStructOrStructPtr(t.In(1)) {
+		return nil, fmt.Errorf("%v must take (context.Context, struct) arguments", t)
+	}
+	var resultType reflect.Type
+	switch {
+	case t.NumOut() == 1 && t.Out(0) == errorType:
+	case t.NumOut() == 2 && t.Out(1) == errorType && isStructOrStructPtr(t.Out(0)):
+		resultType = t.Out(0)
+	default:
+		return nil, fmt.Errorf("%v must return (struct, error) or error", t)
+	}
+	paramID, resultID := m.ParamStructType(), m.ResultStructType()
+	resultSize, err := r.structSize(resultID)
+	if err != nil {
+		return nil, fmt.Errorf("results: %v", err)
+	}
+	paramType := t.In(1)
+	return func(ctx context.Context, call *server.Call) error {
+		params := reflect.New(structType(paramType))
+		if err := pogs.Extract(params.Interface(), paramID, call.Args()); err != nil {
+			return err
+		}
+		if paramType.Kind() != reflect.Ptr {
+			params = params.Elem()
+		}
+		out := gm.Call([]reflect.Value{reflect.ValueOf(ctx), params})
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return err
+		}
+		results, err := call.AllocResults(resultSize)
+		if err != nil {
+			return err
+		}
+		if resultType == nil {
+			return nil
+		}
+		v := out[0]
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil
+		}
+		return pogs.Insert(resultID, results, v.Interface())
+	}, nil
+}
+
+// structSize returns the size of the struct node with the given ID.
+func (r *reflector) structSize(id uint64) (capnp.ObjectSize, error) {
+	n, err := r.nodes.Find(id)
+	if err != nil {
+		return capnp.ObjectSize{}, err
+	}
+	sn := n.StructNode()
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(sn.DataWordCount()) * 8,
+		PointerCount: sn.PointerCount(),
+	}, nil
+}
+
+// exportedName returns the Go method name for a schema method name.
+func exportedName(name string) string {
+	c, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(c)) + name[n:]
+}
+
+// structType returns the struct type t or t points to.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func isStructOrStructPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+}
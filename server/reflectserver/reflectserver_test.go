@@ -0,0 +1,120 @@
+package reflectserver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/schemas"
+	"capnproto.org/go/capnp/v3/server"
+	"capnproto.org/go/capnp/v3/server/reflectserver"
+	"capnproto.org/go/capnp/v3/std/capnp/schema"
+)
+
+func init() {
+	air.RegisterSchema(schemas.DefaultRegistry)
+}
+
+type reflectEcho struct{}
+
+func (reflectEcho) Echo(ctx context.Context, p struct{ In string }) (*struct{ Out string }, error) {
+	if p.In == "" {
+		return nil, errors.New("empty input")
+	}
+	return &struct{ Out string }{Out: p.In + p.In}, nil
+}
+
+type reflectPipeliner struct{ n uint32 }
+
+func (p *reflectPipeliner) GetNumber(ctx context.Context, _ struct{}) (struct{ N uint32 }, error) {
+	p.n++
+	return struct{ N uint32 }{p.n}, nil
+}
+
+func findNode(t *testing.T, id uint64) schema.Node {
+	data := schemas.Find(id)
+	require.NotNil(t, data, "schema for %#x not registered", id)
+	msg, err := capnp.Unmarshal(data)
+	require.NoError(t, err)
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	require.NoError(t, err)
+	nodes, err := req.Nodes()
+	require.NoError(t, err)
+	for i := 0; i < nodes.Len(); i++ {
+		if nodes.At(i).Id() == id {
+			return nodes.At(i)
+		}
+	}
+	t.Fatalf("node %#x not found", id)
+	return schema.Node{}
+}
+
+func TestNewFromStruct(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Echo", func(t *testing.T) {
+		srv, err := reflectserver.NewFromStruct(findNode(t, air.Echo_TypeID), reflectEcho{})
+		require.NoError(t, err)
+		echo := air.Echo(capnp.NewClient(srv))
+		defer echo.Release()
+
+		ans, finish := echo.Echo(ctx, func(p air.Echo_echo_Params) error {
+			return p.SetIn("foo")
+		})
+		defer finish()
+		result, err := ans.Struct()
+		require.NoError(t, err)
+		out, err := result.Out()
+		require.NoError(t, err)
+		assert.Equal(t, "foofoo", out)
+
+		ans, finish = echo.Echo(ctx, nil)
+		defer finish()
+		_, err = ans.Struct()
+		assert.ErrorContains(t, err, "empty input")
+	})
+	t.Run("Superclass", func(t *testing.T) {
+		impl := new(reflectPipeliner)
+		srv, err := reflectserver.NewFromStruct(findNode(t, air.Pipeliner_TypeID), impl)
+		require.NoError(t, err)
+		p := air.Pipeliner(capnp.NewClient(srv))
+		defer p.Release()
+
+		for want := uint32(1); want <= 2; want++ {
+			ans, finish := p.GetNumber(ctx, nil)
+			result, err := ans.Struct()
+			require.NoError(t, err)
+			assert.Equal(t, want, result.N())
+			finish()
+		}
+
+		// newPipeliner has no Go method.
+		ans, finish := p.NewPipeliner(ctx, nil)
+		defer finish()
+		_, err = ans.Struct()
+		assert.True(t, capnp.IsUnimplemented(err), "NewPipeliner error = %v; want unimplemented", err)
+
+		snapshot := capnp.Client(p).Snapshot()
+		defer snapshot.Release()
+		brand, ok := server.IsServer(snapshot.Brand())
+		assert.True(t, ok)
+		assert.Equal(t, impl, brand)
+	})
+	t.Run("BadSignature", func(t *testing.T) {
+		_, err := reflectserver.NewFromStruct(findNode(t, air.Echo_TypeID), badEcho{})
+		assert.ErrorContains(t, err, "echo")
+	})
+	t.Run("NotInterface", func(t *testing.T) {
+		_, err := reflectserver.NewFromStruct(findNode(t, air.Zdate_TypeID), reflectEcho{})
+		assert.Error(t, err)
+	})
+}
+
+type badEcho struct{}
+
+func (badEcho) Echo(in string) string { return in }
@@ -1,9 +1,64 @@
 // Package server provides runtime support for implementing Cap'n Proto
 // interfaces locally.
+//
+// # Call ordering
+//
+// A Server delivers the calls made on a capability in the order they
+// were made, and by default runs one at a time: a method does not start
+// until the previous one has returned.  Implementations that keep state
+// across calls can thus rely on that state not changing under them,
+// without locking.
+//
+// A method that may block for a long time, or that wants calls after it
+// to run in parallel, calls Call.Go.  Go hands delivery of the following
+// calls to a new goroutine, so they start right away and may run
+// concurrently with the rest of the method.  Calls are still started in
+// order; only their execution overlaps.  Methods that do not call Go keep
+// the one-at-a-time guarantee with respect to the calls before them.  A
+// typical method reads what it needs from its arguments and shared
+// state, then calls Go before doing slow work:
+//
+//	func (s *store) Fetch(ctx context.Context, call Store_fetch) error {
+//		key, err := call.Args().Key()
+//		if err != nil {
+//			return err
+//		}
+//		s.mu.Lock()
+//		src := s.sources[key]
+//		s.mu.Unlock()
+//		call.Go() // Later calls need not wait for the download.
+//		data, err := src.Download(ctx)
+//		...
+//	}
+//
+// Once Go has been called, the method runs concurrently with later
+// calls, so any state it shares with them must be synchronized, either
+// with a lock or by touching it only from functions passed to
+// Call.Sync.  Options.MaxConcurrentCalls lets a Server run calls in
+// parallel without methods calling Go; Go is then a no-op.
+// Options.WorkerPool does the same, running calls on a bounded set of
+// goroutines shared by many servers.  Call.Sync is not available on
+// such servers, since their methods never run one at a time.
+// Options.Actor rules both out, even when they are set by
+// SetDefaultOptions, for implementations that rely on calls running one
+// at a time.
+//
+// # Flow control
+//
+// Calls waiting to be delivered are queued by the Server without bound.
+// Backpressure comes from the caller's side instead: a
+// flowcontrol.FlowLimiter set with capnp.Client.SetFlowLimiter counts a
+// call as in flight until it returns, and calls received over an
+// rpc.Conn are paced by the remote vat's limiter in the same way.  A
+// method that blocks without calling Go therefore holds up every call
+// queued behind it, and their callers' limiters stay full until it
+// returns.  Calling Go early in methods that wait on I/O keeps the queue
+// moving, at the cost of running calls concurrently.
 package server // import "capnproto.org/go/capnp/v3/server"
 
 import (
 	"context"
+	"runtime/pprof"
 	"sort"
 	"sync"
 
@@ -32,6 +87,16 @@ type Call struct {
 	results capnp.Struct
 
 	acked bool
+
+	// sync, if not nil, makes this a pseudo-call queued by Call.Sync:
+	// the server runs sync in place of a method, then closes synced.
+	sync   func()
+	synced chan struct{}
+}
+
+// Method returns the method being called.
+func (c *Call) Method() capnp.Method {
+	return c.method.Method
 }
 
 // Args returns the call's arguments.  Args is not safe to
@@ -63,7 +128,9 @@ func (c *Call) AllocResults(sz capnp.ObjectSize) (capnp.Struct, error) {
 // is never more than one goroutine pulling things from the queue.
 //
 // Go need not be the first call in a function nor is it required.
-// short functions can return without calling Go.
+// short functions can return without calling Go.  Go must be called
+// from the goroutine running the method, before the method returns.
+// See the package documentation for how Go affects call ordering.
 func (c *Call) Go() {
 	if c.acked {
 		return
@@ -72,6 +139,31 @@ func (c *Call) Go() {
 	go c.srv.handleCalls()
 }
 
+// Sync runs f in turn with the server's methods, as if it were a method
+// call made now: f runs once the calls received before it have returned
+// or called Go, and calls received after it wait for f to return.  Sync
+// blocks until f has run.
+//
+// This lets a method that called Go update the server's state after a
+// long operation without locking, since the state is otherwise only
+// touched by methods before they call Go.  Before Go is called, Sync
+// simply calls f.  Like Go, Sync must be called from the goroutine
+// running the method.  Sync returns an error without running f if the
+// server has shut down, or if it runs calls concurrently because
+// Options.MaxConcurrentCalls or Options.WorkerPool is set and
+// Options.Actor is not: the method calling Sync may be holding the room
+// that the calls queued before f need in order to run.
+func (c *Call) Sync(f func()) error {
+	if c.srv.sem != nil || c.srv.pool != nil {
+		return newError("sync on a server that runs calls concurrently; set Options.Actor")
+	}
+	if !c.acked {
+		f()
+		return nil
+	}
+	return c.srv.sync(f)
+}
+
 // Shutdowner is the interface that wraps the Shutdown method.
 type Shutdowner interface {
 	Shutdown()
@@ -92,6 +184,31 @@ type Server struct {
 	// by a goroutine running handleCalls()
 	callQueue *mpsc.Queue[*Call]
 
+	// closed is set once Shutdown has closed callQueue, so that Sync
+	// does not send on it afterwards.  It is protected by closeMu.
+	closeMu sync.Mutex
+	closed  bool
+
+	interceptors []Interceptor
+	mapError     ErrorMapper
+
+	// Read limits for call arguments; see Options.ArgsTraverseLimit.
+	argsTraverseLimit uint64
+	argsDepthLimit    uint
+
+	// newResultsArena is Options.NewResultsArena.
+	newResultsArena func(capnp.Method) capnp.Arena
+
+	// profilerLabels is Options.ProfilerLabels.
+	profilerLabels bool
+
+	// sem limits the number of calls running at once, if
+	// Options.MaxConcurrentCalls is set.
+	sem chan struct{}
+
+	// pool runs concurrent calls, if Options.WorkerPool is set.
+	pool *WorkerPool
+
 	// Handler for custom behavior of unknown methods
 	HandleUnknownMethod func(m capnp.Method) *Method
 
@@ -107,12 +224,37 @@ func (s *Server) String() string {
 // If shutdown is nil then the server's shutdown is a no-op.  The server
 // guarantees message delivery order by blocking each call on the
 // return of the previous call or a call to Call.Go.
+//
+// The server is configured with DefaultOptions.
 func New(methods []Method, brand any, shutdown Shutdowner) *Server {
+	return NewWithOptions(methods, brand, shutdown, nil)
+}
+
+// NewWithOptions is like New, but configures the server with opts.
+// If opts is nil, DefaultOptions are used.
+func NewWithOptions(methods []Method, brand any, shutdown Shutdowner, opts *Options) *Server {
+	if opts == nil {
+		o := DefaultOptions()
+		opts = &o
+	}
 	srv := &Server{
-		methods:   make(sortedMethods, len(methods)),
-		brand:     brand,
-		shutdown:  shutdown,
-		callQueue: mpsc.New[*Call](),
+		methods:      make(sortedMethods, len(methods)),
+		brand:        brand,
+		shutdown:     shutdown,
+		callQueue:    mpsc.New[*Call](),
+		interceptors: append([]Interceptor(nil), opts.Interceptors...),
+		mapError:     opts.ErrorMapper,
+
+		argsTraverseLimit: opts.ArgsTraverseLimit,
+		argsDepthLimit:    opts.ArgsDepthLimit,
+		newResultsArena:   opts.NewResultsArena,
+		profilerLabels:    opts.ProfilerLabels,
+	}
+	if opts.MaxConcurrentCalls > 0 && !opts.Actor {
+		srv.sem = make(chan struct{}, opts.MaxConcurrentCalls)
+	}
+	if !opts.Actor {
+		srv.pool = opts.WorkerPool
 	}
 	copy(srv.methods, methods)
 	sort.Sort(srv.methods)
@@ -127,13 +269,18 @@ func (srv *Server) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp
 		mm = srv.HandleUnknownMethod(s.Method)
 	}
 	if mm == nil {
-		return capnp.ErrorAnswer(s.Method, capnp.Unimplemented("unimplemented")), func() {}
+		return capnp.ErrorAnswer(s.Method, capnp.Unimplemented("unimplemented method "+s.Method.String())), func() {}
 	}
 	args, err := srv.sendArgsToStruct(s)
 	if err != nil {
 		return capnp.ErrorAnswer(mm.Method, err), func() {}
 	}
 	ret := new(capnp.StructReturner)
+	if srv.newResultsArena != nil {
+		ret.NewArena = func() capnp.Arena {
+			return srv.newResultsArena(mm.Method)
+		}
+	}
 	pcaller := srv.start(ctx, mm, capnp.Recv{
 		Method: mm.Method, // pick up names from server method
 		Args:   args,
@@ -155,7 +302,7 @@ func (srv *Server) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller
 		mm = srv.HandleUnknownMethod(r.Method)
 	}
 	if mm == nil {
-		r.Reject(capnp.Unimplemented("unimplemented"))
+		r.Reject(capnp.Unimplemented("unimplemented method " + r.Method.String()))
 		return nil
 	}
 	return srv.start(ctx, mm, r)
@@ -174,6 +321,21 @@ func (srv *Server) handleCalls() {
 			return
 		}
 
+		if call.sync != nil {
+			call.sync()
+			close(call.synced)
+			srv.wg.Done()
+			continue
+		}
+
+		if srv.sem != nil || srv.pool != nil {
+			// Run the call concurrently, once there is room for it.
+			// Calling Go is unnecessary, so make it a no-op.
+			call.acked = true
+			srv.runConcurrent(call)
+			continue
+		}
+
 		srv.handleCall(call)
 		if call.acked {
 			// Another goroutine has taken over; time
@@ -183,11 +345,56 @@ func (srv *Server) handleCalls() {
 	}
 }
 
+// runConcurrent runs c on its own goroutine or on srv's worker pool,
+// blocking until MaxConcurrentCalls and the pool allow it to start.
+func (srv *Server) runConcurrent(c *Call) {
+	if srv.sem != nil {
+		srv.sem <- struct{}{}
+	}
+	f := func() {
+		if srv.sem != nil {
+			defer func() { <-srv.sem }()
+		}
+		srv.handleCall(c)
+	}
+	if srv.pool == nil {
+		go f()
+		return
+	}
+	if err := srv.pool.run(f); err != nil {
+		if srv.sem != nil {
+			<-srv.sem
+		}
+		srv.finishCall(c, err)
+		srv.wg.Done()
+	}
+}
+
 func (srv *Server) handleCall(c *Call) {
 	defer srv.wg.Done()
 
-	err := c.method.Impl(c.ctx, c)
+	impl := c.method.Impl
+	if len(srv.interceptors) > 0 {
+		impl = chain(srv.interceptors, impl)
+	}
+	var err error
+	if srv.profilerLabels {
+		pprof.Do(c.ctx, methodLabels(c.method.Method), func(ctx context.Context) {
+			err = impl(ctx, c)
+		})
+	} else {
+		err = impl(c.ctx, c)
+	}
+	if err != nil && srv.mapError != nil {
+		if mapped := srv.mapError(err); mapped != nil {
+			err = mapped
+		}
+	}
+	srv.finishCall(c, err)
+}
 
+// finishCall returns the results of c, or err if it is not nil.
+func (srv *Server) finishCall(c *Call, err error) {
 	c.recv.ReleaseArgs()
 	c.recv.Returner.PrepareReturn(err)
 	if err == nil {
@@ -199,7 +406,20 @@ func (srv *Server) handleCall(c *Call) {
 	c.recv.Returner.ReleaseResults()
 }
 
+// methodLabels returns the pprof labels for calls to m.
+func methodLabels(m capnp.Method) pprof.LabelSet {
+	iface, method := m.InterfaceName, m.MethodName
+	if iface == "" {
+		iface = "@0x" + str.UToHex(m.InterfaceID)
+	}
+	if method == "" {
+		method = "@" + str.Utod(m.MethodID)
+	}
+	return pprof.Labels("capnp.interface", iface, "capnp.method", method)
+}
+
 func (srv *Server) start(ctx context.Context, m *Method, r capnp.Recv) capnp.PipelineCaller {
+	r.Args = srv.limitArgs(r.Args)
 	srv.wg.Add(1)
 
 	aq := capnp.NewAnswerQueue(r.Method)
@@ -213,6 +433,22 @@ func (srv *Server) start(ctx context.Context, m *Method, r capnp.Recv) capnp.Pip
 	return aq
 }
 
+// limitArgs applies the server's read limits to args, and to the
+// message holding them.
+func (srv *Server) limitArgs(args capnp.Struct) capnp.Struct {
+	msg := args.Message()
+	if msg == nil {
+		return args
+	}
+	if srv.argsTraverseLimit > 0 {
+		msg.ResetReadLimit(srv.argsTraverseLimit)
+	}
+	if srv.argsDepthLimit > 0 {
+		args = args.WithDepthLimit(srv.argsDepthLimit)
+	}
+	return args
+}
+
 // Brand returns a value that will match IsServer.
 func (srv *Server) Brand() capnp.Brand {
 	return capnp.Brand{Value: serverBrand{srv.brand}}
@@ -222,9 +458,28 @@ func (srv *Server) Brand() capnp.Brand {
 // into NewServer after outstanding all calls have been serviced.
 // Shutdown must not be called more than once.
 func (srv *Server) Shutdown() {
+	srv.closeMu.Lock()
+	defer srv.closeMu.Unlock()
+	srv.closed = true
 	srv.callQueue.Close()
 }
 
+// sync queues f to run in turn with method calls, and waits for it.
+func (srv *Server) sync(f func()) error {
+	call := &Call{sync: f, synced: make(chan struct{})}
+	srv.closeMu.Lock()
+	if srv.closed {
+		srv.closeMu.Unlock()
+		return newError("sync on shut down server")
+	}
+	srv.wg.Add(1)
+	srv.callQueue.Send(call)
+	srv.closeMu.Unlock()
+
+	<-call.synced
+	return nil
+}
+
 // IsServer reports whether a brand returned by capnp.Client.Brand
 // originated from Server.Brand, and returns the brand argument passed
 // to New.
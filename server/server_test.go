@@ -3,6 +3,8 @@ package server_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime/pprof"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -12,7 +14,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/flowcontrol"
 	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/provenance"
 	"capnproto.org/go/capnp/v3/server"
 
 	"github.com/stretchr/testify/assert"
@@ -194,6 +200,134 @@ func TestServerCallOrder(t *testing.T) {
 	}
 }
 
+// syncCallSeq is a CallSequence that calls Go, and relies on Sync
+// instead of a lock to guard its state.
+type syncCallSeq struct {
+	n uint32
+}
+
+func (seq *syncCallSeq) GetNumber(ctx context.Context, call air.CallSequence_getNumber) error {
+	call.Go()
+	var n uint32
+	if err := call.Sync(func() {
+		n = seq.n
+		seq.n++
+	}); err != nil {
+		return err
+	}
+
+	r, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	r.SetN(n)
+	return nil
+}
+
+func TestServerSync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	seq := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+		air.CallSequence_Methods(nil, new(syncCallSeq)), nil, nil,
+		&server.Options{},
+	)))
+	defer seq.Release()
+
+	const n = 20
+	var futs []air.CallSequence_getNumber_Results_Future
+	for i := 0; i < n; i++ {
+		fut, release := seq.GetNumber(ctx, nil)
+		defer release()
+		futs = append(futs, fut)
+	}
+	seen := make(map[uint32]bool)
+	for _, fut := range futs {
+		res, err := fut.Struct()
+		require.NoError(t, err)
+		seen[res.N()] = true
+	}
+	assert.Len(t, seen, n, "each call should get a distinct number")
+
+	t.Run("Concurrent", func(t *testing.T) {
+		seq := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+			air.CallSequence_Methods(nil, new(syncCallSeq)), nil, nil,
+			&server.Options{MaxConcurrentCalls: 1},
+		)))
+		defer seq.Release()
+
+		fut1, release := seq.GetNumber(ctx, nil)
+		defer release()
+		fut2, release := seq.GetNumber(ctx, nil)
+		defer release()
+		_, err := fut1.Struct()
+		assert.Error(t, err, "Sync should fail instead of deadlocking")
+		_, err = fut2.Struct()
+		assert.Error(t, err, "Sync should fail instead of deadlocking")
+	})
+}
+
+// actorCallSeq is a stateful CallSequence with no lock.  Each call
+// takes a number before calling Go, then records it with Sync once its
+// long operation is done.
+type actorCallSeq struct {
+	n    uint32
+	done []uint32
+}
+
+func (seq *actorCallSeq) GetNumber(ctx context.Context, call air.CallSequence_getNumber) error {
+	n := seq.n
+	seq.n++
+	call.Go()
+	time.Sleep(time.Millisecond)
+	if err := call.Sync(func() {
+		seq.done = append(seq.done, n)
+	}); err != nil {
+		return err
+	}
+
+	r, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	r.SetN(n)
+	return nil
+}
+
+func TestServerActor(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pool := server.NewWorkerPool(4)
+	defer pool.Close()
+	impl := new(actorCallSeq)
+	seq := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+		air.CallSequence_Methods(nil, impl), nil, nil,
+		&server.Options{Actor: true, MaxConcurrentCalls: 4, WorkerPool: pool},
+	)))
+	defer seq.Release()
+
+	const n = 20
+	var futs []air.CallSequence_getNumber_Results_Future
+	for i := 0; i < n; i++ {
+		fut, release := seq.GetNumber(ctx, nil)
+		defer release()
+		futs = append(futs, fut)
+	}
+	for i, fut := range futs {
+		res, err := fut.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(i), res.N(), "calls should be numbered in order")
+	}
+
+	// Every call has returned, so it is safe to read the state.
+	want := make([]uint32, n)
+	for i := range want {
+		want[i] = uint32(i)
+	}
+	assert.ElementsMatch(t, want, impl.done, "each call should record its number once")
+}
+
 func TestServerShutdown(t *testing.T) {
 	wait := make(chan struct{})
 	echo := air.Echo_ServerToClient(blockingEchoImpl{wait})
@@ -281,6 +415,35 @@ func TestPipelineCall(t *testing.T) {
 	assert.Equal(t, uint32(1), result2.N())
 }
 
+func TestUnimplementedServer(t *testing.T) {
+	ctx := context.Background()
+	p := air.Pipeliner_ServerToClient(&partialPipeliner{})
+	defer p.Release()
+
+	ans, finish := p.GetNumber(ctx, nil)
+	defer finish()
+	result, err := ans.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), result.N())
+
+	ans2, finish := p.NewPipeliner(ctx, nil)
+	defer finish()
+	_, err = ans2.Struct()
+	assert.Equal(t, exc.Unimplemented, exc.TypeOf(err))
+	assert.ErrorContains(t, err, "aircraft.capnp:Pipeliner.newPipeliner")
+}
+
+// partialPipeliner only implements the method inherited from
+// CallSequence.
+type partialPipeliner struct {
+	air.Pipeliner_UnimplementedServer
+	seq callSeq
+}
+
+func (p *partialPipeliner) GetNumber(ctx context.Context, call air.CallSequence_getNumber) error {
+	return p.seq.GetNumber(ctx, call)
+}
+
 func TestBrokenPipelineCall(t *testing.T) {
 	wait := make(chan struct{})
 	p := air.Pipeliner_ServerToClient(brokenPipeliner{wait})
@@ -390,3 +553,596 @@ func (c *callSeqBlockN) GetNumber(ctx context.Context, p air.CallSequence_getNum
 		return ctx.Err()
 	}
 }
+
+// goOrderSeq is a CallSequence whose first call calls Go and blocks
+// until release is closed.  Later calls do not call Go, and count how
+// many of them are running at once.
+type goOrderSeq struct {
+	n       uint32
+	release chan struct{}
+
+	running int32
+	overlap int32
+}
+
+func (seq *goOrderSeq) GetNumber(ctx context.Context, call air.CallSequence_getNumber) error {
+	// seq.n is only touched before Go is called, so it needs no lock.
+	n := seq.n
+	seq.n++
+	if n == 0 {
+		call.Go()
+		<-seq.release
+	} else {
+		if atomic.AddInt32(&seq.running, 1) > 1 {
+			atomic.StoreInt32(&seq.overlap, 1)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&seq.running, -1)
+	}
+	r, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	r.SetN(n)
+	return nil
+}
+
+// Verify that calls which do not call Go run one at a time and in the
+// order they were made, even while an earlier call that called Go is
+// still running.
+func TestServerGoOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	impl := &goOrderSeq{release: make(chan struct{})}
+	client := air.CallSequence_ServerToClient(impl)
+	defer client.Release()
+
+	fut0, rel := client.GetNumber(ctx, nil)
+	defer rel()
+	var futs []air.CallSequence_getNumber_Results_Future
+	for i := 0; i < 5; i++ {
+		fut, rel := client.GetNumber(ctx, nil)
+		defer rel()
+		futs = append(futs, fut)
+	}
+	for i, fut := range futs {
+		res, err := fut.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(i+1), res.N(), "calls should run in order")
+	}
+	assert.Zero(t, atomic.LoadInt32(&impl.overlap), "calls that do not call Go should not overlap")
+
+	select {
+	case <-fut0.Done():
+		t.Fatal("first call returned before it was released")
+	default:
+	}
+	close(impl.release)
+	res, err := fut0.Struct()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), res.N())
+}
+
+// Verify that a method blocking without calling Go holds up the calls
+// queued behind it, and keeps the caller's flow limiter full until it
+// returns.
+func TestServerFlowControl(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	blockCtx, cancel := context.WithCancel(context.WithValue(ctx, blockKey{}, true))
+	defer cancel()
+
+	started := make(chan struct{}, 3)
+	client := air.CallSequence_ServerToClient(startedCallSeq{started})
+	defer client.Release()
+	capnp.Client(client).SetFlowLimiter(flowcontrol.NewMaxInflightLimiter(2))
+
+	fut1, rel := client.GetNumber(blockCtx, nil)
+	defer rel()
+	<-started
+	fut2, rel := client.GetNumber(ctx, nil)
+	defer rel()
+
+	var (
+		fut3  air.CallSequence_getNumber_Results_Future
+		rel3  capnp.ReleaseFunc
+		sent3 = make(chan struct{})
+	)
+	go func() {
+		fut3, rel3 = client.GetNumber(ctx, nil)
+		close(sent3)
+	}()
+
+	select {
+	case <-fut2.Done():
+		t.Error("second call returned while the first was blocked")
+	case <-sent3:
+		t.Error("third call was sent while the limiter was full")
+	case <-started:
+		t.Error("second call started while the first was blocked")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	_, err := fut1.Struct()
+	assert.Error(t, err, "first call should fail after cancel")
+	_, err = fut2.Struct()
+	assert.NoError(t, err)
+	<-sent3
+	defer rel3()
+	_, err = fut3.Struct()
+	assert.NoError(t, err)
+}
+
+func echoString(ctx context.Context, echo air.Echo, in string) (string, error) {
+	ans, finish := echo.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn(in)
+	})
+	defer finish()
+	result, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+	return result.Out()
+}
+
+func TestServerOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Interceptors", func(t *testing.T) {
+		var order []string
+		opts := &server.Options{
+			Interceptors: []server.Interceptor{
+				func(ctx context.Context, call *server.Call, next func(context.Context, *server.Call) error) error {
+					order = append(order, "outer:"+call.Method().MethodName)
+					return next(ctx, call)
+				},
+				func(ctx context.Context, call *server.Call, next func(context.Context, *server.Call) error) error {
+					order = append(order, "inner")
+					if in, _ := air.Echo_echo_Params(call.Args()).In(); in == "deny" {
+						return errors.New("denied")
+					}
+					return next(ctx, call)
+				},
+			},
+		}
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, opts)
+		defer echo.Release()
+
+		out, err := echoString(ctx, echo, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "foofoo", out)
+		assert.Equal(t, []string{"outer:echo", "inner"}, order)
+
+		_, err = echoString(ctx, echo, "deny")
+		assert.ErrorContains(t, err, "denied")
+	})
+	t.Run("ErrorMapper", func(t *testing.T) {
+		var got error
+		echo := air.Echo_ServerToClientWithOptions(errorEchoImpl{}, &server.Options{
+			ErrorMapper: func(err error) *exc.Exception {
+				got = err
+				return exc.New(exc.Overloaded, "", "try again later")
+			},
+		})
+		defer echo.Release()
+
+		_, err := echoString(ctx, echo, "foo")
+		assert.True(t, exc.IsType(err, exc.Overloaded), "error should be overloaded: %v", err)
+		assert.ErrorContains(t, err, "try again later")
+		assert.NotContains(t, err.Error(), "reverb stopped")
+		assert.ErrorContains(t, got, "reverb stopped")
+	})
+	t.Run("MaxConcurrentCalls", func(t *testing.T) {
+		blockCtx, cancel := context.WithCancel(context.WithValue(ctx, blockKey{}, true))
+		defer cancel()
+
+		// Without calling Go, the first call would block the second
+		// one forever.
+		client := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+			air.CallSequence_Methods(nil, blockingCallSeq{}), nil, nil,
+			&server.Options{MaxConcurrentCalls: 2},
+		)))
+		defer client.Release()
+
+		fut1, rel := client.GetNumber(blockCtx, nil)
+		defer rel()
+		fut2, rel := client.GetNumber(ctx, nil)
+		defer rel()
+
+		res2, err := fut2.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(42), res2.N())
+
+		cancel()
+		_, err = fut1.Struct()
+		assert.Error(t, err, "first call should fail after cancel")
+	})
+	t.Run("WorkerPool", func(t *testing.T) {
+		blockCtx, cancel := context.WithCancel(context.WithValue(ctx, blockKey{}, true))
+		defer cancel()
+
+		pool := server.NewWorkerPool(2)
+		defer pool.Close()
+		opts := &server.Options{WorkerPool: pool}
+		started := make(chan struct{}, 3)
+		client1 := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+			air.CallSequence_Methods(nil, startedCallSeq{started}), nil, nil, opts,
+		)))
+		defer client1.Release()
+		client2 := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+			air.CallSequence_Methods(nil, startedCallSeq{started}), nil, nil, opts,
+		)))
+		defer client2.Release()
+
+		// Calls on both servers run concurrently, without calling Go,
+		// until the pool is full.
+		fut1, rel := client1.GetNumber(blockCtx, nil)
+		defer rel()
+		fut2, rel := client2.GetNumber(blockCtx, nil)
+		defer rel()
+		<-started
+		<-started
+		fut3, rel := client1.GetNumber(ctx, nil)
+		defer rel()
+		select {
+		case <-fut3.Done():
+			cancel()
+			t.Fatal("call finished while the pool was full")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		cancel()
+		_, err := fut1.Struct()
+		assert.Error(t, err, "first call should fail after cancel")
+		_, err = fut2.Struct()
+		assert.Error(t, err, "second call should fail after cancel")
+		<-started
+		res3, err := fut3.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(42), res3.N())
+	})
+	t.Run("WorkerPoolClosed", func(t *testing.T) {
+		pool := server.NewWorkerPool(1)
+		client := air.CallSequence(capnp.NewClient(server.NewWithOptions(
+			air.CallSequence_Methods(nil, blockingCallSeq{}), nil, nil,
+			&server.Options{WorkerPool: pool, MaxConcurrentCalls: 1},
+		)))
+		defer client.Release()
+
+		fut, rel := client.GetNumber(ctx, nil)
+		defer rel()
+		res, err := fut.Struct()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(42), res.N())
+
+		// Calls delivered after Close fail instead of panicking, and
+		// do not hold on to their MaxConcurrentCalls slot.
+		pool.Close()
+		for i := 0; i < 2; i++ {
+			fut, rel := client.GetNumber(ctx, nil)
+			_, err = fut.Struct()
+			rel()
+			assert.ErrorContains(t, err, "worker pool closed")
+		}
+	})
+	t.Run("ArgsTraverseLimit", func(t *testing.T) {
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, &server.Options{
+			ArgsTraverseLimit: 16,
+		})
+		defer echo.Release()
+
+		out, err := echoString(ctx, echo, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "foofoo", out)
+
+		_, err = echoString(ctx, echo, strings.Repeat("x", 64))
+		assert.ErrorContains(t, err, "read traversal limit reached")
+	})
+	t.Run("NewResultsArena", func(t *testing.T) {
+		var methods []capnp.Method
+		arena := &releaseCountingArena{Arena: capnp.SingleSegment(nil)}
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, &server.Options{
+			NewResultsArena: func(m capnp.Method) capnp.Arena {
+				methods = append(methods, m)
+				return arena
+			},
+		})
+		defer echo.Release()
+
+		out, err := echoString(ctx, echo, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "foofoo", out)
+		require.Len(t, methods, 1)
+		assert.Equal(t, uint64(air.Echo_TypeID), methods[0].InterfaceID)
+		assert.Equal(t, 1, arena.released, "arena should be released with the results")
+	})
+	t.Run("ProfilerLabels", func(t *testing.T) {
+		var got map[string]string
+		opts := &server.Options{
+			Interceptors: []server.Interceptor{
+				func(ctx context.Context, call *server.Call, next func(context.Context, *server.Call) error) error {
+					got = make(map[string]string)
+					pprof.ForLabels(ctx, func(k, v string) bool {
+						got[k] = v
+						return true
+					})
+					return next(ctx, call)
+				},
+			},
+			ProfilerLabels: true,
+		}
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, opts)
+		defer echo.Release()
+
+		// Labels from the caller's context are kept.
+		_, err := echoString(pprof.WithLabels(ctx, pprof.Labels("caller", "test")), echo, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"capnp.interface": "aircraft.capnp:Echo",
+			"capnp.method":    "echo",
+			"caller":          "test",
+		}, got)
+	})
+	t.Run("Default", func(t *testing.T) {
+		defer server.SetDefaultOptions(server.Options{})
+		server.SetDefaultOptions(server.Options{
+			ErrorMapper: server.NewErrorMapper(server.ErrorRule{
+				Type:    exc.Failed,
+				Message: "mapped by default",
+			}),
+		})
+
+		echo := air.Echo_ServerToClient(errorEchoImpl{})
+		defer echo.Release()
+		_, err := echoString(ctx, echo, "foo")
+		assert.ErrorContains(t, err, "mapped by default")
+
+		// Explicit options replace the defaults.
+		echo2 := air.Echo_ServerToClientWithOptions(errorEchoImpl{}, &server.Options{})
+		defer echo2.Release()
+		_, err = echoString(ctx, echo2, "foo")
+		assert.ErrorContains(t, err, "reverb stopped")
+	})
+}
+
+type blockKey struct{}
+
+// blockingCallSeq is a CallSequence whose calls block until their
+// context is canceled, without calling Go, if the context carries
+// blockKey.
+type blockingCallSeq struct{}
+
+func (blockingCallSeq) GetNumber(ctx context.Context, p air.CallSequence_getNumber) error {
+	if ctx.Value(blockKey{}) != nil {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	res, err := p.AllocResults()
+	if err != nil {
+		return err
+	}
+	res.SetN(42)
+	return nil
+}
+
+// startedCallSeq is like blockingCallSeq, but sends on started when
+// each call starts.
+type startedCallSeq struct {
+	started chan<- struct{}
+}
+
+func (seq startedCallSeq) GetNumber(ctx context.Context, p air.CallSequence_getNumber) error {
+	seq.started <- struct{}{}
+	return blockingCallSeq{}.GetNumber(ctx, p)
+}
+
+func TestNewErrorMapper(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := errors.New("not found")
+	mapper := server.NewErrorMapper(
+		server.ErrorRule{Target: context.DeadlineExceeded, Type: exc.Overloaded, Message: "deadline exceeded"},
+		server.ErrorRule{Target: errNotFound, Type: exc.Failed},
+	)
+
+	e := mapper(fmt.Errorf("query: %w", context.DeadlineExceeded))
+	require.NotNil(t, e)
+	assert.Equal(t, exc.Overloaded, e.Type)
+	assert.Equal(t, "deadline exceeded", e.Error())
+
+	e = mapper(fmt.Errorf("lookup secret-key: %w", errNotFound))
+	require.NotNil(t, e)
+	assert.Equal(t, exc.Failed, e.Type)
+	assert.ErrorIs(t, e, errNotFound)
+
+	assert.Nil(t, mapper(errors.New("other")), "unmatched errors should be unchanged")
+
+	catchAll := server.NewErrorMapper(server.ErrorRule{Type: exc.Failed, Message: "internal error"})
+	e = catchAll(errors.New("password=hunter2"))
+	require.NotNil(t, e)
+	assert.Equal(t, "internal error", e.Error())
+}
+
+// recordingLogger records the entries logged to it.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+type logEntry struct {
+	level, msg string
+	attrs      map[string]any
+}
+
+func (l *recordingLogger) log(level, msg string, args []any) {
+	attrs := make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level, msg, attrs})
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.log("debug", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.log("info", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.log("warn", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.log("error", msg, args) }
+
+func TestLogCalls(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	t.Run("All", func(t *testing.T) {
+		l := new(recordingLogger)
+		opts := &server.Options{
+			Interceptors: []server.Interceptor{server.LogCalls(l, &server.LogOptions{
+				Peer: func(context.Context) any { return "peer-1" },
+			})},
+		}
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, opts)
+		defer echo.Release()
+		failing := air.Echo_ServerToClientWithOptions(errorEchoImpl{}, opts)
+		defer failing.Release()
+
+		_, err := echoString(ctx, echo, "foo")
+		require.NoError(t, err)
+		_, err = echoString(ctx, failing, "foo")
+		require.Error(t, err)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		require.Len(t, l.entries, 2)
+		ok := l.entries[0]
+		assert.Equal(t, "info", ok.level)
+		assert.Equal(t, "aircraft.capnp:Echo.echo", ok.attrs["method"])
+		assert.Equal(t, "peer-1", ok.attrs["peer"])
+		assert.NotZero(t, ok.attrs["args_size"])
+		assert.NotZero(t, ok.attrs["results_size"])
+		assert.Contains(t, ok.attrs, "duration")
+
+		failed := l.entries[1]
+		assert.Equal(t, "error", failed.level)
+		assert.Equal(t, "failed", failed.attrs["error_type"])
+		assert.ErrorContains(t, failed.attrs["error"].(error), "reverb stopped")
+	})
+	t.Run("Sampling", func(t *testing.T) {
+		l := new(recordingLogger)
+		opts := &server.Options{
+			Interceptors: []server.Interceptor{server.LogCalls(l, &server.LogOptions{
+				SampleRate: 1e-12,
+			})},
+		}
+		echo := air.Echo_ServerToClientWithOptions(echoImpl{}, opts)
+		defer echo.Release()
+		failing := air.Echo_ServerToClientWithOptions(errorEchoImpl{}, opts)
+		defer failing.Release()
+
+		for i := 0; i < 10; i++ {
+			_, err := echoString(ctx, echo, "foo")
+			require.NoError(t, err)
+		}
+		_, err := echoString(ctx, failing, "foo")
+		require.Error(t, err)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		require.Len(t, l.entries, 1, "only the failed call should be logged")
+		assert.Equal(t, "error", l.entries[0].level)
+	})
+	t.Run("Delegation", func(t *testing.T) {
+		l := new(recordingLogger)
+		opts := &server.Options{
+			Interceptors: []server.Interceptor{server.LogCalls(l, nil)},
+		}
+		d := provenance.Delegation{From: "alice", To: "bob", Time: time.Unix(0, 0)}
+		echo := air.Echo(provenance.Delegate(
+			capnp.Client(air.Echo_ServerToClientWithOptions(echoImpl{}, opts)), d,
+		))
+		defer echo.Release()
+
+		_, err := echoString(ctx, echo, "foo")
+		require.NoError(t, err)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		require.Len(t, l.entries, 1)
+		assert.Equal(t, d.String(), l.entries[0].attrs["delegation"])
+	})
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clk := clock.NewManual(time.Unix(0, 0))
+	l := new(recordingLogger)
+	var onSlow atomic.Int32
+	w := server.NewWatchdog(l, &server.WatchdogOptions{
+		Threshold: time.Second,
+		Repeat:    time.Second,
+		OnSlow: func(m capnp.Method, elapsed time.Duration) {
+			onSlow.Add(1)
+		},
+		Clock: clk,
+	})
+	opts := &server.Options{Interceptors: []server.Interceptor{w.Interceptor()}}
+
+	// Fast calls are not reported.
+	echo := air.Echo_ServerToClientWithOptions(echoImpl{}, opts)
+	defer echo.Release()
+	_, err := echoString(ctx, echo, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, server.WatchdogStats{}, w.Stats())
+
+	wait := make(chan struct{})
+	blocking := air.Echo_ServerToClientWithOptions(blockingEchoImpl{wait}, opts)
+	defer blocking.Release()
+	ans, finish := blocking.Echo(ctx, nil)
+	defer finish()
+	require.Eventually(t, func() bool { return w.Stats().Running == 1 }, time.Second, time.Millisecond)
+
+	clk.Advance(1500 * time.Millisecond)
+	require.Eventually(t, func() bool { return w.Stats().Slow == 1 }, time.Second, time.Millisecond)
+	clk.Advance(time.Second)
+	require.Eventually(t, func() bool { return onSlow.Load() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, server.WatchdogStats{
+		Running:  1,
+		Slow:     1,
+		Oldest:   2500 * time.Millisecond,
+		Reported: 1,
+	}, w.Stats())
+
+	close(wait)
+	_, err = ans.Struct()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return w.Stats().Running == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), w.Stats().Reported)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	require.Len(t, l.entries, 3)
+	for i, e := range l.entries[:2] {
+		assert.Equal(t, "warn", e.level, "entry %d", i)
+		assert.Equal(t, "slow rpc handler", e.msg, "entry %d", i)
+		assert.Equal(t, "aircraft.capnp:Echo.echo", e.attrs["method"], "entry %d", i)
+	}
+	assert.Equal(t, 1500*time.Millisecond, l.entries[0].attrs["elapsed"])
+	assert.Equal(t, 2500*time.Millisecond, l.entries[1].attrs["elapsed"])
+	assert.Equal(t, "info", l.entries[2].level)
+	assert.Equal(t, "slow rpc handler returned", l.entries[2].msg)
+}
+
+// releaseCountingArena counts the calls to Release.
+type releaseCountingArena struct {
+	capnp.Arena
+	released int
+}
+
+func (a *releaseCountingArena) Release() {
+	a.released++
+	a.Arena.Release()
+}
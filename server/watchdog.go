@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exp/clock"
+)
+
+// WatchdogOptions configures a Watchdog.
+type WatchdogOptions struct {
+	// Threshold is how long a call may run before it is reported as
+	// slow.  If zero, 10 seconds is used.
+	Threshold time.Duration
+
+	// Repeat, if positive, reports a call that is still running again
+	// each time it has run for another Repeat past Threshold.
+	Repeat time.Duration
+
+	// OnSlow, if not nil, is called each time a call is reported, for
+	// example to update a metric.  It must not block for long.
+	OnSlow func(m capnp.Method, elapsed time.Duration)
+
+	// Clock is used to measure how long calls run.  If nil,
+	// clock.System is used.
+	Clock clock.Clock
+}
+
+// A Watchdog reports method calls that run for longer than a threshold,
+// while they are still running.  Such calls hold on to their answer and
+// to their share of the caller's flow control window, and, unless they
+// call Call.Go, block the calls queued behind them.
+//
+// A Watchdog is installed on servers through its Interceptor; one
+// Watchdog may watch many servers.  It is safe to use from multiple
+// goroutines.
+type Watchdog struct {
+	l    Logger
+	opts WatchdogOptions
+
+	mu      sync.Mutex
+	running map[*watchedCall]struct{}
+
+	reported atomic.Uint64
+}
+
+// WatchdogStats is a snapshot of the calls seen by a Watchdog.
+type WatchdogStats struct {
+	// Running is the number of calls that are running.
+	Running int
+
+	// Slow is the number of running calls that have been reported as
+	// slow.
+	Slow int
+
+	// Oldest is how long the longest-running call has been running, or
+	// zero if no calls are running.
+	Oldest time.Duration
+
+	// Reported is the total number of calls that have been reported as
+	// slow, including calls that have since returned.
+	Reported uint64
+}
+
+// NewWatchdog returns a Watchdog that logs slow calls to l as
+// warnings.  If l is nil, slow calls are only passed to
+// WatchdogOptions.OnSlow.  If opts is nil, the defaults are used.
+func NewWatchdog(l Logger, opts *WatchdogOptions) *Watchdog {
+	w := &Watchdog{
+		l:       l,
+		running: make(map[*watchedCall]struct{}),
+	}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if w.opts.Threshold <= 0 {
+		w.opts.Threshold = 10 * time.Second
+	}
+	if w.opts.Clock == nil {
+		w.opts.Clock = clock.System
+	}
+	return w
+}
+
+// A watchedCall is a call that is being run under a Watchdog.
+type watchedCall struct {
+	method capnp.Method
+	start  time.Time
+
+	// mu serializes reports about the call, so that the report that
+	// it returned comes after any report that it is slow.
+	mu       sync.Mutex
+	slow     bool
+	returned bool
+}
+
+// Interceptor returns an Interceptor that watches each call it runs.
+func (w *Watchdog) Interceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next func(context.Context, *Call) error) error {
+		wc := &watchedCall{
+			method: call.Method(),
+			start:  w.opts.Clock.Now(),
+		}
+		w.mu.Lock()
+		w.running[wc] = struct{}{}
+		w.mu.Unlock()
+
+		timer := w.opts.Clock.NewTimer(w.opts.Threshold)
+		done := make(chan struct{})
+		go w.watch(wc, timer, done)
+		defer w.finish(wc, done)
+		return next(ctx, call)
+	}
+}
+
+// watch reports wc each time timer fires, until done is closed.
+func (w *Watchdog) watch(wc *watchedCall, timer clock.Timer, done <-chan struct{}) {
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.Chan():
+		case <-done:
+			return
+		}
+		if !w.report(wc) || w.opts.Repeat <= 0 {
+			return
+		}
+		timer.Reset(w.opts.Repeat)
+	}
+}
+
+// report reports wc as slow, unless it has already returned.
+func (w *Watchdog) report(wc *watchedCall) bool {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if wc.returned {
+		return false
+	}
+	elapsed := w.opts.Clock.Now().Sub(wc.start)
+	if !wc.slow {
+		wc.slow = true
+		w.reported.Add(1)
+	}
+	if w.l != nil {
+		w.l.Warn("slow rpc handler",
+			"method", wc.method.String(),
+			"elapsed", elapsed,
+		)
+	}
+	if w.opts.OnSlow != nil {
+		w.opts.OnSlow(wc.method, elapsed)
+	}
+	return true
+}
+
+// finish records that wc has returned.
+func (w *Watchdog) finish(wc *watchedCall, done chan<- struct{}) {
+	close(done)
+	w.mu.Lock()
+	delete(w.running, wc)
+	w.mu.Unlock()
+
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	wc.returned = true
+	if wc.slow && w.l != nil {
+		w.l.Info("slow rpc handler returned",
+			"method", wc.method.String(),
+			"duration", w.opts.Clock.Now().Sub(wc.start),
+		)
+	}
+}
+
+// Stats returns a snapshot of the calls seen by the Watchdog.
+func (w *Watchdog) Stats() WatchdogStats {
+	now := w.opts.Clock.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stats := WatchdogStats{
+		Running:  len(w.running),
+		Reported: w.reported.Load(),
+	}
+	for wc := range w.running {
+		wc.mu.Lock()
+		if wc.slow {
+			stats.Slow++
+		}
+		wc.mu.Unlock()
+		if d := now.Sub(wc.start); d > stats.Oldest {
+			stats.Oldest = d
+		}
+	}
+	return stats
+}
@@ -0,0 +1,153 @@
+package capnp
+
+import (
+	"errors"
+
+	"capnproto.org/go/capnp/v3/exp/bufferpool"
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A SpillAllocator provides the storage for the segments that a
+// SpillArena allocates once its message has grown past the arena's
+// threshold.
+type SpillAllocator interface {
+	// Alloc returns a zero-filled buffer of n bytes.
+	Alloc(n int) ([]byte, error)
+
+	// Free releases a buffer returned by Alloc.
+	Free(b []byte)
+}
+
+// SpillArena is an Arena for building messages that may not fit in
+// memory.  Like MultiSegmentArena, it allocates new segments as the
+// message grows, but once the message would grow past a threshold,
+// further segments are allocated from a SpillAllocator, such as a
+// TempFileSpill, instead of the heap.  Since Encoder writes a
+// message's segments one by one, such a message can be written out
+// without ever being held in memory as a whole.
+//
+// A SpillArena is meant for writing: it cannot be used to read a
+// message.
+type SpillArena struct {
+	threshold int64
+	spill     SpillAllocator
+	bp        *bufferpool.Pool
+
+	segs    []*Segment
+	bufs    [][]byte // the buffer backing each segment
+	spilled []bool
+	total   int64 // sum of the segments' capacities
+}
+
+// NewSpillArena returns an arena that keeps up to threshold bytes of
+// segments in memory, and allocates the rest from spill.
+func NewSpillArena(threshold int64, spill SpillAllocator) *SpillArena {
+	return &SpillArena{
+		threshold: threshold,
+		spill:     spill,
+		bp:        &bufferpool.Default,
+	}
+}
+
+func (sa *SpillArena) NumSegments() int64 {
+	return int64(len(sa.segs))
+}
+
+func (sa *SpillArena) Segment(id SegmentID) *Segment {
+	if int64(id) >= int64(len(sa.segs)) {
+		return nil
+	}
+	return sa.segs[id]
+}
+
+func (sa *SpillArena) Allocate(sz Size, msg *Message, seg *Segment) (*Segment, address, error) {
+	if seg != nil && hasCapacity(seg.data, sz) {
+		if seg.msg != nil && seg.msg != msg {
+			return nil, 0, errors.New("attempt to allocate in segment for different message")
+		}
+		if int64(seg.id) >= int64(len(sa.segs)) || sa.segs[seg.id] != seg {
+			return nil, 0, errors.New("preferred segment is not part of the arena")
+		}
+		return sa.extend(seg, sz, msg)
+	}
+	for _, s := range sa.segs {
+		if hasCapacity(s.data, sz) {
+			return sa.extend(s, sz, msg)
+		}
+	}
+
+	// See MultiSegmentArena.Allocate: the first segment must not be
+	// empty.
+	req := sz
+	if len(sa.segs) == 0 && req == 0 {
+		req = wordSize
+	}
+	n, err := nextAlloc(sa.total, 1<<63-1, req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf []byte
+	spilled := sa.total+int64(n) > sa.threshold
+	if spilled {
+		if buf, err = sa.spill.Alloc(n); err != nil {
+			return nil, 0, errors.New("alloc " + sz.String() + ": spill: " + err.Error())
+		}
+		if len(buf) != n {
+			sa.spill.Free(buf)
+			return nil, 0, errors.New("alloc " + sz.String() + ": spill returned " + str.Itod(len(buf)) + " bytes, want " + str.Itod(n))
+		}
+	} else {
+		buf = sa.bp.Get(n)
+	}
+	s := &Segment{
+		data: buf[:sz],
+		id:   SegmentID(len(sa.segs)),
+	}
+	s.BindTo(msg)
+	sa.segs = append(sa.segs, s)
+	sa.bufs = append(sa.bufs, buf)
+	sa.spilled = append(sa.spilled, spilled)
+	sa.total += int64(n)
+	return s, 0, nil
+}
+
+// extend allocates sz bytes at the end of s, which has the capacity.
+func (sa *SpillArena) extend(s *Segment, sz Size, msg *Message) (*Segment, address, error) {
+	addr := address(len(s.data))
+	s.data = s.data[:int(addr)+int(sz)]
+	s.BindTo(msg)
+	return s, addr, nil
+}
+
+// Spilled returns the number of bytes allocated from the arena's
+// SpillAllocator.
+func (sa *SpillArena) Spilled() int64 {
+	var n int64
+	for i, buf := range sa.bufs {
+		if sa.spilled[i] {
+			n += int64(len(buf))
+		}
+	}
+	return n
+}
+
+// Release returns the in-memory segments to the buffer pool and frees
+// the spilled ones.
+func (sa *SpillArena) Release() {
+	for i, buf := range sa.bufs {
+		if sa.spilled[i] {
+			sa.spill.Free(buf)
+		} else {
+			zeroSlice(buf)
+			sa.bp.Put(buf)
+		}
+		sa.segs[i].data = nil
+		sa.segs[i].BindTo(nil)
+	}
+	sa.segs, sa.bufs, sa.spilled, sa.total = nil, nil, nil, 0
+}
+
+func (sa *SpillArena) String() string {
+	return "spill arena [" + str.Itod(len(sa.segs)) + " segments, " + str.Itod(sa.Spilled()) + " bytes spilled]"
+}
@@ -0,0 +1,90 @@
+package capnp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// heapSpill is a SpillAllocator that records its buffers.
+type heapSpill struct {
+	live map[*byte]int
+}
+
+func (s *heapSpill) Alloc(n int) ([]byte, error) {
+	b := make([]byte, n)
+	s.live[&b[0]] = n
+	return b, nil
+}
+
+func (s *heapSpill) Free(b []byte) {
+	delete(s.live, &b[0])
+}
+
+// fillSpillMessage builds a message with n data lists of 4 KiB in arena.
+func fillSpillMessage(t *testing.T, arena Arena, n int) *Message {
+	msg, seg, err := NewMessage(arena)
+	require.NoError(t, err)
+	root, err := NewRootStruct(seg, ObjectSize{PointerCount: 1})
+	require.NoError(t, err)
+	list, err := NewPointerList(seg, int32(n))
+	require.NoError(t, err)
+	require.NoError(t, root.SetPtr(0, list.ToPtr()))
+	for i := 0; i < n; i++ {
+		data, err := NewData(seg, bytes.Repeat([]byte{byte(i)}, 4096))
+		require.NoError(t, err)
+		require.NoError(t, list.Set(i, data.ToPtr()))
+	}
+	return msg
+}
+
+func checkSpillMessage(t *testing.T, b []byte, n int) {
+	msg, err := Unmarshal(b)
+	require.NoError(t, err)
+	root, err := msg.Root()
+	require.NoError(t, err)
+	p, err := root.Struct().Ptr(0)
+	require.NoError(t, err)
+	list := PointerList(p.List())
+	require.Equal(t, n, list.Len())
+	for i := 0; i < n; i++ {
+		data, err := list.At(i)
+		require.NoError(t, err)
+		assert.Equal(t, bytes.Repeat([]byte{byte(i)}, 4096), data.Data())
+	}
+}
+
+func TestSpillArena(t *testing.T) {
+	t.Parallel()
+
+	spill := &heapSpill{live: make(map[*byte]int)}
+	arena := NewSpillArena(16<<10, spill)
+	msg := fillSpillMessage(t, arena, 64)
+	assert.Greater(t, arena.NumSegments(), int64(1))
+	assert.NotEmpty(t, spill.live)
+	var spilled int64
+	for _, n := range spill.live {
+		spilled += int64(n)
+	}
+	assert.Equal(t, spilled, arena.Spilled())
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).Encode(msg))
+	checkSpillMessage(t, buf.Bytes(), 64)
+
+	msg.Release()
+	assert.Empty(t, spill.live, "release should free spilled buffers")
+}
+
+func TestSpillArenaBelowThreshold(t *testing.T) {
+	t.Parallel()
+
+	spill := &heapSpill{live: make(map[*byte]int)}
+	arena := NewSpillArena(1<<20, spill)
+	msg := fillSpillMessage(t, arena, 4)
+	assert.Empty(t, spill.live)
+	assert.Zero(t, arena.Spilled())
+	msg.Release()
+}
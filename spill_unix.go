@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package capnp
+
+import (
+	"os"
+	"syscall"
+)
+
+// TempFileSpill is a SpillAllocator that backs each buffer with a
+// memory-mapped temporary file, so that the operating system can page
+// spilled segments out to disk.  The files are removed as soon as they
+// are mapped.
+type TempFileSpill struct {
+	// Dir is the directory to create the files in.  If empty, the
+	// default directory for temporary files is used.
+	Dir string
+}
+
+// Alloc implements SpillAllocator.
+func (s TempFileSpill) Alloc(n int) ([]byte, error) {
+	f, err := os.CreateTemp(s.Dir, "capnp-spill-")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	defer os.Remove(f.Name())
+	if err := f.Truncate(int64(n)); err != nil {
+		return nil, err
+	}
+	return syscall.Mmap(int(f.Fd()), 0, n, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// Free implements SpillAllocator.
+func (TempFileSpill) Free(b []byte) {
+	syscall.Munmap(b)
+}
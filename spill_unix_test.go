@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package capnp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTempFileSpill(t *testing.T) {
+	t.Parallel()
+
+	arena := NewSpillArena(16<<10, TempFileSpill{Dir: t.TempDir()})
+	msg := fillSpillMessage(t, arena, 64)
+	assert.NotZero(t, arena.Spilled())
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEncoder(&buf).Encode(msg))
+	checkSpillMessage(t, buf.Bytes(), 64)
+	msg.Release()
+}
@@ -0,0 +1,552 @@
+package json
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Marshal returns the JSON encoding of s, a struct of the given type.
+// See Encoder for how structs are encoded.
+func Marshal(typeID uint64, s capnp.Struct) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(typeID, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// A Marshaler is a struct along with its type.  It implements the
+// Marshaler interface of package encoding/json, so that structs can be
+// embedded in values passed to encoding/json.Marshal, such as the
+// responses of a web handler.  Schemas are looked up in
+// schemas.DefaultRegistry.
+type Marshaler struct {
+	TypeID uint64
+	Struct capnp.Struct
+}
+
+// MarshalJSON returns the JSON encoding of m.Struct.
+func (m Marshaler) MarshalJSON() ([]byte, error) {
+	return Marshal(m.TypeID, m.Struct)
+}
+
+// An Encoder writes the JSON encoding of structs to an output stream,
+// using their schemas, in the same way as the C++ implementation's
+// JsonCodec.
+//
+// Structs and groups are encoded as objects.  Fields that are not set
+// in the active member of a union are left out, as are null pointers
+// outside of unions; other fields are written even if they have their
+// default value.  Int64 and UInt64 values are written as strings,
+// since JavaScript numbers can't hold all of them, and infinite and NaN
+// floats as the strings "Infinity", "-Infinity" and "NaN".  Enumerants
+// are written as their names, Data as an array of bytes and Void as
+// null.  Capabilities and AnyPointer values can't be encoded, so
+// encoding fails if one is set.
+//
+// The annotations of json.capnp change the encoding:
+//
+//   - $name renames a field or enumerant.
+//   - $flatten writes the fields of a group or struct field into the
+//     object that holds it, with an optional prefix.
+//   - $discriminator, on a struct or union, writes the name of the
+//     active member of the union in a separate field, so that members
+//     may be flattened or share a field name given by valueName.
+//   - $base64 and $hex write a Data field as a string.
+type Encoder struct {
+	w     io.Writer
+	nodes nodemap.Map
+	buf   bytes.Buffer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// UseRegistry changes the registry that the encoder consults for
+// schemas from the default registry.
+func (enc *Encoder) UseRegistry(reg *schemas.Registry) {
+	enc.nodes.UseRegistry(reg)
+}
+
+// Encode writes the JSON encoding of s, a struct of the given type, to
+// the stream.
+func (enc *Encoder) Encode(typeID uint64, s capnp.Struct) error {
+	enc.buf.Reset()
+	if err := enc.encodeStruct(typeID, s); err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	_, err := enc.w.Write(enc.buf.Bytes())
+	return err
+}
+
+// annotations holds the json.capnp annotations of a node or field.
+type annotations struct {
+	name          string
+	hasName       bool
+	flatten       bool
+	prefix        string
+	discriminator *discriminator
+	base64        bool
+	hex           bool
+}
+
+type discriminator struct {
+	name      string
+	valueName string
+}
+
+func parseAnnotations(list schema.Annotation_List) (annotations, error) {
+	var a annotations
+	for i := 0; i < list.Len(); i++ {
+		ann := list.At(i)
+		v, err := ann.Value()
+		if err != nil {
+			return a, err
+		}
+		switch ann.Id() {
+		case Name_:
+			a.name, err = v.Text()
+			a.hasName = true
+		case Flatten_:
+			var p capnp.Ptr
+			p, err = v.StructValue()
+			if err == nil {
+				a.flatten = true
+				a.prefix, err = FlattenOptions(p.Struct()).Prefix()
+			}
+		case Discriminator_:
+			var p capnp.Ptr
+			p, err = v.StructValue()
+			if err == nil {
+				opts := DiscriminatorOptions(p.Struct())
+				a.discriminator = new(discriminator)
+				a.discriminator.name, _ = opts.Name()
+				a.discriminator.valueName, _ = opts.ValueName()
+			}
+		case Base64_:
+			a.base64 = true
+		case Hex_:
+			a.hex = true
+		}
+		if err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+func (enc *Encoder) findNode(id uint64, which schema.Node_Which) (schema.Node, error) {
+	n, err := enc.nodes.Find(id)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	if !n.IsValid() || n.Which() != which {
+		return schema.Node{}, fmt.Errorf("cannot find %v type %#x", which, id)
+	}
+	return n, nil
+}
+
+func (enc *Encoder) encodeStruct(typeID uint64, s capnp.Struct) error {
+	n, err := enc.findNode(typeID, schema.Node_Which_structNode)
+	if err != nil {
+		return err
+	}
+	anns, _ := n.Annotations()
+	a, err := parseAnnotations(anns)
+	if err != nil {
+		return err
+	}
+	o := object{enc: enc}
+	enc.buf.WriteByte('{')
+	if err := o.members(n, s, "", a.discriminator, ""); err != nil {
+		return err
+	}
+	enc.buf.WriteByte('}')
+	return nil
+}
+
+// An object writes the members of a JSON object.
+type object struct {
+	enc *Encoder
+	n   int
+}
+
+func (o *object) key(name string) {
+	if o.n > 0 {
+		o.enc.buf.WriteByte(',')
+	}
+	o.n++
+	encodeString(&o.enc.buf, name)
+	o.enc.buf.WriteByte(':')
+}
+
+// members writes the fields of n, a struct or group read from s, with
+// their names prefixed by prefix.  disc is the discriminator of n's
+// union, if it has one, and unionName is the default name of the
+// discriminator field.
+func (o *object) members(n schema.Node, s capnp.Struct, prefix string, disc *discriminator, unionName string) error {
+	var active uint16
+	if n.StructNode().DiscriminantCount() > 0 {
+		active = s.Uint16(capnp.DataOffset(n.StructNode().DiscriminantOffset() * 2))
+	}
+	for _, f := range codeOrderFields(n.StructNode()) {
+		inUnion := f.DiscriminantValue() != schema.Field_noDiscriminant
+		if inUnion && f.DiscriminantValue() != active {
+			continue
+		}
+		fname, err := f.Name()
+		if err != nil {
+			return err
+		}
+		anns, _ := f.Annotations()
+		a, err := parseAnnotations(anns)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", fname, err)
+		}
+		if a.hasName {
+			fname = a.name
+		}
+		name := prefix + fname
+		if inUnion && disc != nil {
+			tag := disc.name
+			if tag == "" {
+				tag = unionName
+			}
+			if tag == "" {
+				return errors.New("discriminator of anonymous union needs a name")
+			}
+			o.key(prefix + tag)
+			encodeString(&o.enc.buf, fname)
+			if f.Which() == schema.Field_Which_slot {
+				if t, _ := f.Slot().Type(); t.Which() == schema.Type_Which_void {
+					continue
+				}
+			}
+			if disc.valueName != "" {
+				name = prefix + disc.valueName
+				a.flatten = false
+			}
+		}
+		if err := o.field(s, f, name, prefix, a, inUnion); err != nil {
+			return fmt.Errorf("field %s: %v", fname, err)
+		}
+	}
+	return nil
+}
+
+// field writes field f of s under the given name, or flattens it.
+func (o *object) field(s capnp.Struct, f schema.Field, name, prefix string, a annotations, inUnion bool) error {
+	enc := o.enc
+	if f.Which() == schema.Field_Which_group {
+		gn, err := enc.findNode(f.Group().TypeId(), schema.Node_Which_structNode)
+		if err != nil {
+			return err
+		}
+		if a.flatten {
+			return o.members(gn, s, prefix+a.prefix, a.discriminator, name)
+		}
+		o.key(name)
+		g := object{enc: enc}
+		enc.buf.WriteByte('{')
+		if err := g.members(gn, s, "", a.discriminator, name); err != nil {
+			return err
+		}
+		enc.buf.WriteByte('}')
+		return nil
+	}
+	if f.Which() != schema.Field_Which_slot {
+		return nil
+	}
+
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	if dv.IsValid() && int(typ.Which()) != int(dv.Which()) {
+		return errors.New("default value is a " + dv.Which().String() + ", want " + typ.Which().String())
+	}
+	off := f.Slot().Offset()
+	if !isPointer(typ) {
+		o.key(name)
+		return enc.encodeData(s, typ, dv, off)
+	}
+
+	p, err := s.Ptr(uint16(off))
+	if err != nil {
+		return err
+	}
+	if !p.IsValid() {
+		if !inUnion || a.flatten {
+			return nil
+		}
+		o.key(name)
+		enc.buf.WriteString("null")
+		return nil
+	}
+	if a.flatten {
+		if typ.Which() != schema.Type_Which_structType {
+			return errors.New("cannot flatten a " + typ.Which().String())
+		}
+		sn, err := enc.findNode(typ.StructType().TypeId(), schema.Node_Which_structNode)
+		if err != nil {
+			return err
+		}
+		sanns, _ := sn.Annotations()
+		sa, err := parseAnnotations(sanns)
+		if err != nil {
+			return err
+		}
+		return o.members(sn, p.Struct(), prefix+a.prefix, sa.discriminator, "")
+	}
+	o.key(name)
+	if typ.Which() == schema.Type_Which_data {
+		enc.encodeBytes(p.Data(), a)
+		return nil
+	}
+	return enc.encodePtr(typ, p)
+}
+
+func isPointer(t schema.Type) bool {
+	switch t.Which() {
+	case schema.Type_Which_text, schema.Type_Which_data, schema.Type_Which_list,
+		schema.Type_Which_structType, schema.Type_Which_interface, schema.Type_Which_anyPointer:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeData writes the value of a field of type typ stored in the data
+// section of s, at offset off in units of its size.
+func (enc *Encoder) encodeData(s capnp.Struct, typ schema.Type, dv schema.Value, off uint32) error {
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		enc.buf.WriteString("null")
+	case schema.Type_Which_bool:
+		v := s.Bit(capnp.BitOffset(off))
+		enc.encodeBool(v != dv.Bool())
+	case schema.Type_Which_int8:
+		enc.encodeInt(int64(int8(s.Uint8(capnp.DataOffset(off)) ^ uint8(dv.Int8()))))
+	case schema.Type_Which_int16:
+		enc.encodeInt(int64(int16(s.Uint16(capnp.DataOffset(off*2)) ^ uint16(dv.Int16()))))
+	case schema.Type_Which_int32:
+		enc.encodeInt(int64(int32(s.Uint32(capnp.DataOffset(off*4)) ^ uint32(dv.Int32()))))
+	case schema.Type_Which_int64:
+		enc.encodeInt64(int64(s.Uint64(capnp.DataOffset(off*8)) ^ uint64(dv.Int64())))
+	case schema.Type_Which_uint8:
+		enc.encodeUint(uint64(s.Uint8(capnp.DataOffset(off)) ^ dv.Uint8()))
+	case schema.Type_Which_uint16:
+		enc.encodeUint(uint64(s.Uint16(capnp.DataOffset(off*2)) ^ dv.Uint16()))
+	case schema.Type_Which_uint32:
+		enc.encodeUint(uint64(s.Uint32(capnp.DataOffset(off*4)) ^ dv.Uint32()))
+	case schema.Type_Which_uint64:
+		enc.encodeUint64(s.Uint64(capnp.DataOffset(off*8)) ^ dv.Uint64())
+	case schema.Type_Which_float32:
+		v := s.Uint32(capnp.DataOffset(off*4)) ^ math.Float32bits(dv.Float32())
+		enc.encodeFloat(float64(math.Float32frombits(v)), 32)
+	case schema.Type_Which_float64:
+		v := s.Uint64(capnp.DataOffset(off*8)) ^ math.Float64bits(dv.Float64())
+		enc.encodeFloat(math.Float64frombits(v), 64)
+	case schema.Type_Which_enum:
+		v := s.Uint16(capnp.DataOffset(off*2)) ^ dv.Enum()
+		return enc.encodeEnum(typ.Enum().TypeId(), v)
+	default:
+		return errors.New("unknown field type " + typ.Which().String())
+	}
+	return nil
+}
+
+// encodePtr writes p, a non-null pointer of type typ.
+func (enc *Encoder) encodePtr(typ schema.Type, p capnp.Ptr) error {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		encodeString(&enc.buf, p.Text())
+	case schema.Type_Which_data:
+		enc.encodeBytes(p.Data(), annotations{})
+	case schema.Type_Which_structType:
+		return enc.encodeStruct(typ.StructType().TypeId(), p.Struct())
+	case schema.Type_Which_list:
+		elem, err := typ.List().ElementType()
+		if err != nil {
+			return err
+		}
+		return enc.encodeList(elem, p.List())
+	case schema.Type_Which_interface:
+		return errors.New("cannot encode capability")
+	case schema.Type_Which_anyPointer:
+		return errors.New("cannot encode AnyPointer")
+	default:
+		return errors.New("unknown field type " + typ.Which().String())
+	}
+	return nil
+}
+
+func (enc *Encoder) encodeList(elem schema.Type, l capnp.List) error {
+	enc.buf.WriteByte('[')
+	for i := 0; i < l.Len(); i++ {
+		if i > 0 {
+			enc.buf.WriteByte(',')
+		}
+		switch elem.Which() {
+		case schema.Type_Which_void:
+			enc.buf.WriteString("null")
+		case schema.Type_Which_bool:
+			enc.encodeBool(capnp.BitList(l).At(i))
+		case schema.Type_Which_int8:
+			enc.encodeInt(int64(capnp.Int8List(l).At(i)))
+		case schema.Type_Which_int16:
+			enc.encodeInt(int64(capnp.Int16List(l).At(i)))
+		case schema.Type_Which_int32:
+			enc.encodeInt(int64(capnp.Int32List(l).At(i)))
+		case schema.Type_Which_int64:
+			enc.encodeInt64(capnp.Int64List(l).At(i))
+		case schema.Type_Which_uint8:
+			enc.encodeUint(uint64(capnp.UInt8List(l).At(i)))
+		case schema.Type_Which_uint16:
+			enc.encodeUint(uint64(capnp.UInt16List(l).At(i)))
+		case schema.Type_Which_uint32:
+			enc.encodeUint(uint64(capnp.UInt32List(l).At(i)))
+		case schema.Type_Which_uint64:
+			enc.encodeUint64(capnp.UInt64List(l).At(i))
+		case schema.Type_Which_float32:
+			enc.encodeFloat(float64(capnp.Float32List(l).At(i)), 32)
+		case schema.Type_Which_float64:
+			enc.encodeFloat(capnp.Float64List(l).At(i), 64)
+		case schema.Type_Which_enum:
+			if err := enc.encodeEnum(elem.Enum().TypeId(), capnp.UInt16List(l).At(i)); err != nil {
+				return err
+			}
+		case schema.Type_Which_structType:
+			if err := enc.encodeStruct(elem.StructType().TypeId(), l.Struct(i)); err != nil {
+				return err
+			}
+		default:
+			p, err := capnp.PointerList(l).At(i)
+			if err != nil {
+				return err
+			}
+			if !p.IsValid() {
+				enc.buf.WriteString("null")
+				continue
+			}
+			if err := enc.encodePtr(elem, p); err != nil {
+				return err
+			}
+		}
+	}
+	enc.buf.WriteByte(']')
+	return nil
+}
+
+func (enc *Encoder) encodeBool(v bool) {
+	enc.buf.WriteString(strconv.FormatBool(v))
+}
+
+func (enc *Encoder) encodeInt(v int64) {
+	enc.buf.WriteString(strconv.FormatInt(v, 10))
+}
+
+func (enc *Encoder) encodeUint(v uint64) {
+	enc.buf.WriteString(strconv.FormatUint(v, 10))
+}
+
+func (enc *Encoder) encodeInt64(v int64) {
+	enc.buf.WriteByte('"')
+	enc.encodeInt(v)
+	enc.buf.WriteByte('"')
+}
+
+func (enc *Encoder) encodeUint64(v uint64) {
+	enc.buf.WriteByte('"')
+	enc.encodeUint(v)
+	enc.buf.WriteByte('"')
+}
+
+func (enc *Encoder) encodeFloat(v float64, bits int) {
+	switch {
+	case math.IsInf(v, 1):
+		enc.buf.WriteString(`"Infinity"`)
+	case math.IsInf(v, -1):
+		enc.buf.WriteString(`"-Infinity"`)
+	case math.IsNaN(v):
+		enc.buf.WriteString(`"NaN"`)
+	default:
+		enc.buf.WriteString(strconv.FormatFloat(v, 'g', -1, bits))
+	}
+}
+
+func (enc *Encoder) encodeBytes(b []byte, a annotations) {
+	switch {
+	case a.base64:
+		encodeString(&enc.buf, base64.StdEncoding.EncodeToString(b))
+	case a.hex:
+		encodeString(&enc.buf, hex.EncodeToString(b))
+	default:
+		enc.buf.WriteByte('[')
+		for i, c := range b {
+			if i > 0 {
+				enc.buf.WriteByte(',')
+			}
+			enc.encodeUint(uint64(c))
+		}
+		enc.buf.WriteByte(']')
+	}
+}
+
+func (enc *Encoder) encodeEnum(typeID uint64, v uint16) error {
+	n, err := enc.findNode(typeID, schema.Node_Which_enum)
+	if err != nil {
+		return err
+	}
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return err
+	}
+	if int(v) >= enums.Len() {
+		enc.encodeUint(uint64(v))
+		return nil
+	}
+	e := enums.At(int(v))
+	name, err := e.Name()
+	if err != nil {
+		return err
+	}
+	anns, _ := e.Annotations()
+	a, err := parseAnnotations(anns)
+	if err != nil {
+		return err
+	}
+	if a.hasName {
+		name = a.name
+	}
+	encodeString(&enc.buf, name)
+	return nil
+}
+
+func codeOrderFields(s schema.Node_structNode) []schema.Field {
+	list, _ := s.Fields()
+	n := list.Len()
+	fields := make([]schema.Field, n)
+	for i := 0; i < n; i++ {
+		f := list.At(i)
+		fields[f.CodeOrder()] = f
+	}
+	return fields
+}
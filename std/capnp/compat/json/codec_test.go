@@ -0,0 +1,394 @@
+package json_test
+
+import (
+	stdjson "encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/schemas"
+	"capnproto.org/go/capnp/v3/std/capnp/compat/json"
+	"capnproto.org/go/capnp/v3/std/capnp/schema"
+)
+
+// IDs of the test schema, which is built by hand since it is not worth
+// a generated package:
+//
+//	enum Color { red @0; green @1 $Json.name("GREEN"); }
+//	struct Inner { x @0 :Int32; }
+//	struct Outer {
+//	  id @0 :UInt64;
+//	  label @1 :Text $Json.name("the_label");
+//	  color @2 :Color;
+//	  blob @3 :Data $Json.base64;
+//	  hexed @4 :Data $Json.hex;
+//	  raw @5 :Data;
+//	  inner @6 :Inner $Json.flatten(prefix = "in_");
+//	  ratio @7 :Float64;
+//	  shape :union $Json.discriminator(name = "kind") {
+//	    circle @8 :Inner $Json.flatten();
+//	    none @9 :Void;
+//	  }
+//	  opt @10 :Text;
+//	}
+const (
+	colorID = 0xd5a1c0f3a7e90001
+	innerID = 0xd5a1c0f3a7e90002
+	outerID = 0xd5a1c0f3a7e90003
+	shapeID = 0xd5a1c0f3a7e90004
+)
+
+var outerSize = capnp.ObjectSize{DataSize: 24, PointerCount: 7}
+
+var (
+	registerOnce sync.Once
+	registerErr  error
+)
+
+func testRegistry(t *testing.T) *schemas.Registry {
+	t.Helper()
+	data := buildTestSchema(t)
+	reg := new(schemas.Registry)
+	require.NoError(t, reg.Register(&schemas.Schema{
+		Bytes: data,
+		Nodes: []uint64{colorID, innerID, outerID, shapeID},
+	}))
+	registerOnce.Do(func() {
+		registerErr = schemas.DefaultRegistry.Register(&schemas.Schema{
+			Bytes: data,
+			Nodes: []uint64{colorID, innerID, outerID, shapeID},
+		})
+	})
+	require.NoError(t, registerErr)
+	return reg
+}
+
+func buildTestSchema(t *testing.T) []byte {
+	t.Helper()
+	msg, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	req, err := schema.NewRootCodeGeneratorRequest(seg)
+	require.NoError(t, err)
+	nodes, err := req.NewNodes(4)
+	require.NoError(t, err)
+
+	annotate := func(list schema.Annotation_List, i int, id uint64) schema.Value {
+		a := list.At(i)
+		a.SetId(id)
+		v, err := a.NewValue()
+		require.NoError(t, err)
+		return v
+	}
+	name := func(list schema.Annotation_List, i int, s string) {
+		require.NoError(t, annotate(list, i, json.Name_).SetText(s))
+	}
+	slot := func(f schema.Field, order uint16, fname string, offset uint32) schema.Type {
+		require.NoError(t, f.SetName(fname))
+		f.SetCodeOrder(order)
+		f.SetDiscriminantValue(schema.Field_noDiscriminant)
+		f.SetSlot()
+		f.Slot().SetOffset(offset)
+		typ, err := f.Slot().NewType()
+		require.NoError(t, err)
+		return typ
+	}
+
+	color := nodes.At(0)
+	color.SetId(colorID)
+	require.NoError(t, color.SetDisplayName("test.capnp:Color"))
+	color.SetEnum()
+	enums, err := color.Enum().NewEnumerants(2)
+	require.NoError(t, err)
+	require.NoError(t, enums.At(0).SetName("red"))
+	require.NoError(t, enums.At(1).SetName("green"))
+	enums.At(1).SetCodeOrder(1)
+	anns, err := enums.At(1).NewAnnotations(1)
+	require.NoError(t, err)
+	name(anns, 0, "GREEN")
+
+	inner := nodes.At(1)
+	inner.SetId(innerID)
+	require.NoError(t, inner.SetDisplayName("test.capnp:Inner"))
+	inner.SetStructNode()
+	inner.StructNode().SetDataWordCount(1)
+	fields, err := inner.StructNode().NewFields(1)
+	require.NoError(t, err)
+	slot(fields.At(0), 0, "x", 0).SetInt32()
+
+	outer := nodes.At(2)
+	outer.SetId(outerID)
+	require.NoError(t, outer.SetDisplayName("test.capnp:Outer"))
+	outer.SetStructNode()
+	outer.StructNode().SetDataWordCount(3)
+	outer.StructNode().SetPointerCount(7)
+	fields, err = outer.StructNode().NewFields(11)
+	require.NoError(t, err)
+	slot(fields.At(0), 0, "id", 0).SetUint64()
+	slot(fields.At(1), 1, "label", 0).SetText()
+	anns, err = fields.At(1).NewAnnotations(1)
+	require.NoError(t, err)
+	name(anns, 0, "the_label")
+	typ := slot(fields.At(2), 2, "color", 4)
+	typ.SetEnum()
+	typ.Enum().SetTypeId(colorID)
+	slot(fields.At(3), 3, "blob", 1).SetData()
+	anns, err = fields.At(3).NewAnnotations(1)
+	require.NoError(t, err)
+	annotate(anns, 0, json.Base64_).SetVoid()
+	slot(fields.At(4), 4, "hexed", 2).SetData()
+	anns, err = fields.At(4).NewAnnotations(1)
+	require.NoError(t, err)
+	annotate(anns, 0, json.Hex_).SetVoid()
+	slot(fields.At(5), 5, "raw", 3).SetData()
+	typ = slot(fields.At(6), 6, "inner", 4)
+	typ.SetStructType()
+	typ.StructType().SetTypeId(innerID)
+	anns, err = fields.At(6).NewAnnotations(1)
+	require.NoError(t, err)
+	flatten, err := json.NewFlattenOptions(seg)
+	require.NoError(t, err)
+	require.NoError(t, flatten.SetPrefix("in_"))
+	require.NoError(t, annotate(anns, 0, json.Flatten_).SetStructValue(capnp.Struct(flatten).ToPtr()))
+	slot(fields.At(7), 7, "ratio", 2).SetFloat64()
+	shape := fields.At(8)
+	require.NoError(t, shape.SetName("shape"))
+	shape.SetCodeOrder(8)
+	shape.SetDiscriminantValue(schema.Field_noDiscriminant)
+	shape.SetGroup()
+	shape.Group().SetTypeId(shapeID)
+	anns, err = shape.NewAnnotations(1)
+	require.NoError(t, err)
+	disc, err := json.NewDiscriminatorOptions(seg)
+	require.NoError(t, err)
+	require.NoError(t, disc.SetName("kind"))
+	require.NoError(t, annotate(anns, 0, json.Discriminator_).SetStructValue(capnp.Struct(disc).ToPtr()))
+	slot(fields.At(9), 9, "opt", 6).SetText()
+	slot(fields.At(10), 10, "unused", 0).SetVoid()
+
+	group := nodes.At(3)
+	group.SetId(shapeID)
+	require.NoError(t, group.SetDisplayName("test.capnp:Outer.shape"))
+	group.SetStructNode()
+	group.StructNode().SetIsGroup(true)
+	group.StructNode().SetDataWordCount(3)
+	group.StructNode().SetPointerCount(7)
+	group.StructNode().SetDiscriminantCount(2)
+	group.StructNode().SetDiscriminantOffset(5)
+	fields, err = group.StructNode().NewFields(2)
+	require.NoError(t, err)
+	typ = slot(fields.At(0), 0, "circle", 5)
+	fields.At(0).SetDiscriminantValue(0)
+	typ.SetStructType()
+	typ.StructType().SetTypeId(innerID)
+	anns, err = fields.At(0).NewAnnotations(1)
+	require.NoError(t, err)
+	flatten, err = json.NewFlattenOptions(seg)
+	require.NoError(t, err)
+	require.NoError(t, annotate(anns, 0, json.Flatten_).SetStructValue(capnp.Struct(flatten).ToPtr()))
+	slot(fields.At(1), 1, "none", 0).SetVoid()
+	fields.At(1).SetDiscriminantValue(1)
+
+	// The compiler always fills in default values; Value and Type share
+	// their discriminant numbering, so a zero default is just the tag.
+	for i := 0; i < nodes.Len(); i++ {
+		if nodes.At(i).Which() != schema.Node_Which_structNode {
+			continue
+		}
+		fields, err := nodes.At(i).StructNode().Fields()
+		require.NoError(t, err)
+		for j := 0; j < fields.Len(); j++ {
+			f := fields.At(j)
+			if f.Which() != schema.Field_Which_slot {
+				continue
+			}
+			typ, err := f.Slot().Type()
+			require.NoError(t, err)
+			dv, err := f.Slot().NewDefaultValue()
+			require.NoError(t, err)
+			capnp.Struct(dv).SetUint16(0, uint16(typ.Which()))
+		}
+	}
+
+	data, err := msg.Marshal()
+	require.NoError(t, err)
+	return data
+}
+
+// newOuter returns an Outer with every field set and the circle member
+// of shape active.
+func newOuter(t *testing.T) capnp.Struct {
+	t.Helper()
+	_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+	require.NoError(t, err)
+	s, err := capnp.NewRootStruct(seg, outerSize)
+	require.NoError(t, err)
+	s.SetUint64(0, math.MaxUint64)
+	require.NoError(t, s.SetNewText(0, "hi"))
+	s.SetUint16(8, 1)
+	for i := uint16(1); i <= 3; i++ {
+		d, err := capnp.NewData(seg, []byte("hi"))
+		require.NoError(t, err)
+		require.NoError(t, s.SetPtr(i, d.ToPtr()))
+	}
+	in, err := capnp.NewStruct(seg, capnp.ObjectSize{DataSize: 8})
+	require.NoError(t, err)
+	in.SetUint32(0, 7)
+	require.NoError(t, s.SetPtr(4, in.ToPtr()))
+	s.SetUint64(16, math.Float64bits(0.5))
+	circle, err := capnp.NewStruct(seg, capnp.ObjectSize{DataSize: 8})
+	require.NoError(t, err)
+	circle.SetUint32(0, uint32(0xffffffff)) // -1
+	require.NoError(t, s.SetPtr(5, circle.ToPtr()))
+	return s
+}
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+	reg := testRegistry(t)
+
+	encode := func(s capnp.Struct) (string, error) {
+		var buf []byte
+		w := writerFunc(func(p []byte) (int, error) {
+			buf = append(buf, p...)
+			return len(p), nil
+		})
+		enc := json.NewEncoder(w)
+		enc.UseRegistry(reg)
+		err := enc.Encode(outerID, s)
+		return string(buf), err
+	}
+
+	t.Run("Annotations", func(t *testing.T) {
+		got, err := encode(newOuter(t))
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"18446744073709551615","the_label":"hi","color":"GREEN",`+
+			`"blob":"aGk=","hexed":"6869","raw":[104,105],"in_x":7,"ratio":0.5,`+
+			`"shape":{"kind":"circle","x":-1},"unused":null}`, got)
+	})
+
+	t.Run("VoidMemberAndDefaults", func(t *testing.T) {
+		_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+		require.NoError(t, err)
+		s, err := capnp.NewRootStruct(seg, outerSize)
+		require.NoError(t, err)
+		s.SetUint16(10, 1)
+		s.SetUint64(16, math.Float64bits(math.Inf(-1)))
+		require.NoError(t, s.SetNewText(6, "o"))
+		got, err := encode(s)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"0","color":"red","ratio":"-Infinity",`+
+			`"shape":{"kind":"none"},"opt":"o","unused":null}`, got)
+	})
+
+	t.Run("Marshaler", func(t *testing.T) {
+		got, err := stdjson.Marshal(map[string]any{
+			"outer": json.Marshaler{TypeID: outerID, Struct: newOuter(t)},
+		})
+		require.NoError(t, err)
+		var v struct {
+			Outer struct {
+				Label string `json:"the_label"`
+				InX   int    `json:"in_x"`
+			}
+		}
+		require.NoError(t, stdjson.Unmarshal(got, &v))
+		assert.Equal(t, "hi", v.Outer.Label)
+		assert.Equal(t, 7, v.Outer.InX)
+	})
+
+	t.Run("UnknownType", func(t *testing.T) {
+		_, err := json.Marshal(0x1234, newOuter(t))
+		assert.Error(t, err)
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+	reg := testRegistry(t)
+
+	decode := func(data string) (capnp.Struct, error) {
+		dec := json.NewDecoder(strings.NewReader(data))
+		dec.UseRegistry(reg)
+		msg, err := dec.DecodeMessage(outerID)
+		if err != nil {
+			return capnp.Struct{}, err
+		}
+		p, err := msg.Root()
+		return p.Struct(), err
+	}
+	roundTrip := func(t *testing.T, data string) string {
+		s, err := decode(data)
+		require.NoError(t, err)
+		var buf []byte
+		enc := json.NewEncoder(writerFunc(func(p []byte) (int, error) {
+			buf = append(buf, p...)
+			return len(p), nil
+		}))
+		enc.UseRegistry(reg)
+		require.NoError(t, enc.Encode(outerID, s))
+		return string(buf)
+	}
+
+	t.Run("Annotations", func(t *testing.T) {
+		const want = `{"id":"18446744073709551615","the_label":"hi","color":"GREEN",` +
+			`"blob":"aGk=","hexed":"6869","raw":[104,105],"in_x":7,"ratio":0.5,` +
+			`"shape":{"kind":"circle","x":-1},"unused":null}`
+		assert.Equal(t, want, roundTrip(t, want))
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		got := roundTrip(t, `{"ratio": "-Infinity", "shape": {"kind": "none"}, "opt": "o", "extra": [1]}`)
+		assert.Equal(t, `{"id":"0","color":"red","ratio":"-Infinity",`+
+			`"shape":{"kind":"none"},"opt":"o","unused":null}`, got)
+	})
+
+	t.Run("LenientNumbers", func(t *testing.T) {
+		s, err := decode(`{"id": 42, "color": 1, "ratio": "2.5"}`)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(42), s.Uint64(0))
+		assert.Equal(t, uint16(1), s.Uint16(8))
+		assert.Equal(t, 2.5, math.Float64frombits(s.Uint64(16)))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, data := range []string{
+			`[]`,
+			`{"id": -1}`,
+			`{"color": "blue"}`,
+			`{"the_label": 1}`,
+			`{"blob": "!"}`,
+			`{"shape": {"kind": "square"}}`,
+			`{"in_x": 1.5}`,
+		} {
+			_, err := decode(data)
+			assert.Error(t, err, "decoding %s", data)
+		}
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		_, seg, err := capnp.NewMessage(capnp.SingleSegment(nil))
+		require.NoError(t, err)
+		s, err := capnp.NewRootStruct(seg, outerSize)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(outerID, s, []byte(`{"the_label": "x"}`)))
+		assert.Equal(t, "x", mustText(t, s, 0))
+	})
+}
+
+func mustText(t *testing.T, s capnp.Struct, i uint16) string {
+	t.Helper()
+	p, err := s.Ptr(i)
+	require.NoError(t, err)
+	return p.Text()
+}
@@ -0,0 +1,870 @@
+package json
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/internal/nodemap"
+	"capnproto.org/go/capnp/v3/internal/schema"
+	"capnproto.org/go/capnp/v3/internal/str"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Unmarshal parses the JSON encoding of a struct of the given type and
+// stores it in s.  See Decoder for the accepted input.
+func Unmarshal(typeID uint64, s capnp.Struct, data []byte) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(typeID, s)
+}
+
+// UnmarshalMessage parses the JSON encoding of a struct of the given
+// type into the root of a new message.  Use the generated ReadRoot
+// function of the type to read it.
+func UnmarshalMessage(typeID uint64, data []byte) (*capnp.Message, error) {
+	return NewDecoder(bytes.NewReader(data)).DecodeMessage(typeID)
+}
+
+// A Decoder reads JSON values from an input stream into structs, using
+// their schemas.  It accepts the encoding written by Encoder, including
+// the json.capnp annotations, so that an HTTP gateway can turn a JSON
+// request into a struct to send over RPC.
+//
+// Fields that are missing from an object, or null, are left unset, so
+// they read as their default values.  Members of an object that are not
+// fields of the struct are ignored.  Integers and floats may be given
+// as numbers or as strings, and enumerants as names or numbers.  The
+// active member of a union is given by its discriminator if it has one,
+// and otherwise by which member is present.  Capabilities and
+// AnyPointer values can't be decoded, so they can only be null.
+type Decoder struct {
+	d     *stdjson.Decoder
+	nodes nodemap.Map
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := stdjson.NewDecoder(r)
+	d.UseNumber()
+	return &Decoder{d: d}
+}
+
+// UseRegistry changes the registry that the decoder consults for
+// schemas from the default registry.
+func (dec *Decoder) UseRegistry(reg *schemas.Registry) {
+	dec.nodes.UseRegistry(reg)
+}
+
+// DecodeMessage reads the next JSON value from the input stream and
+// stores the struct it represents in the root of a new message, sized
+// as the struct's schema says.
+func (dec *Decoder) DecodeMessage(typeID uint64) (*capnp.Message, error) {
+	size, err := dec.structSize(typeID)
+	if err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+	msg, seg, err := capnp.NewMessage(capnp.MultiSegment(nil))
+	if err != nil {
+		return nil, err
+	}
+	s, err := capnp.NewRootStruct(seg, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(typeID, s); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Decode reads the next JSON value from the input stream and stores the
+// struct it represents in s.  At the end of the stream, Decode returns
+// io.EOF.
+func (dec *Decoder) Decode(typeID uint64, s capnp.Struct) error {
+	var v any
+	if err := dec.d.Decode(&v); err != nil {
+		return err
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return errors.New("json: cannot use " + describe(v) + " as struct")
+	}
+	if err := dec.decodeStruct(typeID, s, obj); err != nil {
+		return fmt.Errorf("json: %v", err)
+	}
+	return nil
+}
+
+func (dec *Decoder) findNode(id uint64, which schema.Node_Which) (schema.Node, error) {
+	n, err := dec.nodes.Find(id)
+	if err != nil {
+		return schema.Node{}, err
+	}
+	if !n.IsValid() || n.Which() != which {
+		return schema.Node{}, fmt.Errorf("cannot find %v type %#x", which, id)
+	}
+	return n, nil
+}
+
+func (dec *Decoder) structSize(id uint64) (capnp.ObjectSize, error) {
+	n, err := dec.findNode(id, schema.Node_Which_structNode)
+	if err != nil {
+		return capnp.ObjectSize{}, err
+	}
+	return capnp.ObjectSize{
+		DataSize:     capnp.Size(n.StructNode().DataWordCount()) * 8,
+		PointerCount: n.StructNode().PointerCount(),
+	}, nil
+}
+
+func (dec *Decoder) decodeStruct(typeID uint64, s capnp.Struct, obj map[string]any) error {
+	n, err := dec.findNode(typeID, schema.Node_Which_structNode)
+	if err != nil {
+		return err
+	}
+	anns, _ := n.Annotations()
+	a, err := parseAnnotations(anns)
+	if err != nil {
+		return err
+	}
+	return dec.members(n, s, obj, "", a.discriminator, "")
+}
+
+// fieldName returns the name of f in JSON, along with its annotations.
+func fieldName(f schema.Field) (string, annotations, error) {
+	name, err := f.Name()
+	if err != nil {
+		return "", annotations{}, err
+	}
+	anns, _ := f.Annotations()
+	a, err := parseAnnotations(anns)
+	if err != nil {
+		return "", a, fmt.Errorf("field %s: %v", name, err)
+	}
+	if a.hasName {
+		name = a.name
+	}
+	return name, a, nil
+}
+
+// members reads the fields of n, a struct or group stored in s, from
+// the members of obj whose names start with prefix.  disc and unionName
+// are as for object.members.
+func (dec *Decoder) members(n schema.Node, s capnp.Struct, obj map[string]any, prefix string, disc *discriminator, unionName string) error {
+	fields := codeOrderFields(n.StructNode())
+	active := -1
+	if n.StructNode().DiscriminantCount() > 0 {
+		var err error
+		active, err = activeMember(fields, obj, prefix, disc, unionName)
+		if err != nil {
+			return err
+		}
+		if active >= 0 {
+			off := capnp.DataOffset(n.StructNode().DiscriminantOffset() * 2)
+			s.SetUint16(off, fields[active].DiscriminantValue())
+		}
+	}
+	for i, f := range fields {
+		inUnion := f.DiscriminantValue() != schema.Field_noDiscriminant
+		if inUnion && i != active {
+			continue
+		}
+		fname, a, err := fieldName(f)
+		if err != nil {
+			return err
+		}
+		name := prefix + fname
+		if inUnion && disc != nil {
+			if f.Which() == schema.Field_Which_slot {
+				if t, _ := f.Slot().Type(); t.Which() == schema.Type_Which_void {
+					continue
+				}
+			}
+			if disc.valueName != "" {
+				name = prefix + disc.valueName
+				a.flatten = false
+			}
+		}
+		if err := dec.field(s, f, obj, name, prefix, a, inUnion); err != nil {
+			return fmt.Errorf("field %s: %v", fname, err)
+		}
+	}
+	return nil
+}
+
+// activeMember returns the index in fields of the union member that obj
+// sets, or -1 if it sets none.
+func activeMember(fields []schema.Field, obj map[string]any, prefix string, disc *discriminator, unionName string) (int, error) {
+	if disc != nil {
+		tag := disc.name
+		if tag == "" {
+			tag = unionName
+		}
+		if tag == "" {
+			return -1, errors.New("discriminator of anonymous union needs a name")
+		}
+		v, ok := obj[prefix+tag]
+		if !ok || v == nil {
+			return -1, nil
+		}
+		member, ok := v.(string)
+		if !ok {
+			return -1, errors.New("cannot use " + describe(v) + " as discriminator " + tag)
+		}
+		for i, f := range fields {
+			if f.DiscriminantValue() == schema.Field_noDiscriminant {
+				continue
+			}
+			if name, _, _ := fieldName(f); name == member {
+				return i, nil
+			}
+		}
+		return -1, errors.New("unknown union member " + strconv.Quote(member))
+	}
+
+	active := -1
+	for i, f := range fields {
+		if f.DiscriminantValue() == schema.Field_noDiscriminant {
+			continue
+		}
+		name, _, err := fieldName(f)
+		if err != nil {
+			return -1, err
+		}
+		if _, ok := obj[prefix+name]; !ok {
+			continue
+		}
+		if active >= 0 {
+			prev, _, _ := fieldName(fields[active])
+			return -1, errors.New("union members " + prev + " and " + name + " are both set")
+		}
+		active = i
+	}
+	return active, nil
+}
+
+// field reads field f of s from the member of obj with the given name,
+// or from the members of obj if f is flattened.
+func (dec *Decoder) field(s capnp.Struct, f schema.Field, obj map[string]any, name, prefix string, a annotations, inUnion bool) error {
+	if f.Which() == schema.Field_Which_group {
+		gn, err := dec.findNode(f.Group().TypeId(), schema.Node_Which_structNode)
+		if err != nil {
+			return err
+		}
+		if a.flatten {
+			return dec.members(gn, s, obj, prefix+a.prefix, a.discriminator, name)
+		}
+		v := obj[name]
+		if v == nil {
+			return nil
+		}
+		g, ok := v.(map[string]any)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as group")
+		}
+		return dec.members(gn, s, g, "", a.discriminator, name)
+	}
+	if f.Which() != schema.Field_Which_slot {
+		return nil
+	}
+
+	typ, err := f.Slot().Type()
+	if err != nil {
+		return err
+	}
+	dv, err := f.Slot().DefaultValue()
+	if err != nil {
+		return err
+	}
+	if dv.IsValid() && int(typ.Which()) != int(dv.Which()) {
+		return errors.New("default value is a " + dv.Which().String() + ", want " + typ.Which().String())
+	}
+	off := f.Slot().Offset()
+	if a.flatten {
+		if typ.Which() != schema.Type_Which_structType {
+			return errors.New("cannot flatten a " + typ.Which().String())
+		}
+		id := typ.StructType().TypeId()
+		sn, err := dec.findNode(id, schema.Node_Which_structNode)
+		if err != nil {
+			return err
+		}
+		sanns, _ := sn.Annotations()
+		sa, err := parseAnnotations(sanns)
+		if err != nil {
+			return err
+		}
+		// Encoder leaves out null flattened structs, so only an active
+		// union member or a struct with members present is allocated.
+		if !inUnion && !dec.hasMembers(sn, obj, prefix+a.prefix, sa.discriminator, "") {
+			return nil
+		}
+		sz, err := dec.structSize(id)
+		if err != nil {
+			return err
+		}
+		ss, err := capnp.NewStruct(s.Segment(), sz)
+		if err != nil {
+			return err
+		}
+		if err := s.SetPtr(uint16(off), ss.ToPtr()); err != nil {
+			return err
+		}
+		return dec.members(sn, ss, obj, prefix+a.prefix, sa.discriminator, "")
+	}
+
+	v, ok := obj[name]
+	if !ok {
+		return nil
+	}
+	if typ.Which() == schema.Type_Which_data && v != nil {
+		b, err := parseBytes(v, a)
+		if err != nil {
+			return err
+		}
+		return s.SetData(uint16(off), b)
+	}
+	return dec.setField(s, typ, dv, off, v)
+}
+
+// hasMembers reports whether obj has any member that members would read
+// for n with the given prefix.
+func (dec *Decoder) hasMembers(n schema.Node, obj map[string]any, prefix string, disc *discriminator, unionName string) bool {
+	if disc != nil && n.StructNode().DiscriminantCount() > 0 {
+		tag := disc.name
+		if tag == "" {
+			tag = unionName
+		}
+		if _, ok := obj[prefix+tag]; ok {
+			return true
+		}
+	}
+	for _, f := range codeOrderFields(n.StructNode()) {
+		name, a, err := fieldName(f)
+		if err != nil {
+			continue
+		}
+		if _, ok := obj[prefix+name]; ok && !a.flatten {
+			return true
+		}
+		if !a.flatten {
+			continue
+		}
+		var id uint64
+		switch f.Which() {
+		case schema.Field_Which_group:
+			id = f.Group().TypeId()
+		case schema.Field_Which_slot:
+			typ, _ := f.Slot().Type()
+			if typ.Which() != schema.Type_Which_structType {
+				continue
+			}
+			id = typ.StructType().TypeId()
+		default:
+			continue
+		}
+		fn, err := dec.findNode(id, schema.Node_Which_structNode)
+		if err != nil {
+			continue
+		}
+		if f.Which() == schema.Field_Which_slot {
+			anns, _ := fn.Annotations()
+			sa, _ := parseAnnotations(anns)
+			a.discriminator, name = sa.discriminator, ""
+		}
+		if dec.hasMembers(fn, obj, prefix+a.prefix, a.discriminator, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// setField stores v in the field of type typ at offset off of s.
+func (dec *Decoder) setField(s capnp.Struct, typ schema.Type, dv schema.Value, off uint32, v any) error {
+	switch typ.Which() {
+	case schema.Type_Which_void:
+		if v != nil {
+			return errors.New("cannot use " + describe(v) + " as void")
+		}
+	case schema.Type_Which_bool:
+		b, ok := v.(bool)
+		if !ok {
+			return errors.New("cannot use " + describe(v) + " as bool")
+		}
+		s.SetBit(capnp.BitOffset(off), b != dv.Bool())
+	case schema.Type_Which_int8:
+		i, err := parseInt(v, 8)
+		if err != nil {
+			return err
+		}
+		s.SetUint8(capnp.DataOffset(off), uint8(int8(i)^dv.Int8()))
+	case schema.Type_Which_int16:
+		i, err := parseInt(v, 16)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), uint16(int16(i)^dv.Int16()))
+	case schema.Type_Which_int32:
+		i, err := parseInt(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), uint32(int32(i)^dv.Int32()))
+	case schema.Type_Which_int64:
+		i, err := parseInt(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), uint64(i^dv.Int64()))
+	case schema.Type_Which_uint8:
+		u, err := parseUint(v, 8)
+		if err != nil {
+			return err
+		}
+		s.SetUint8(capnp.DataOffset(off), uint8(u)^dv.Uint8())
+	case schema.Type_Which_uint16:
+		u, err := parseUint(v, 16)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), uint16(u)^dv.Uint16())
+	case schema.Type_Which_uint32:
+		u, err := parseUint(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), uint32(u)^dv.Uint32())
+	case schema.Type_Which_uint64:
+		u, err := parseUint(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), u^dv.Uint64())
+	case schema.Type_Which_float32:
+		x, err := parseFloat(v, 32)
+		if err != nil {
+			return err
+		}
+		s.SetUint32(capnp.DataOffset(off*4), math.Float32bits(float32(x))^math.Float32bits(dv.Float32()))
+	case schema.Type_Which_float64:
+		x, err := parseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		s.SetUint64(capnp.DataOffset(off*8), math.Float64bits(x)^math.Float64bits(dv.Float64()))
+	case schema.Type_Which_enum:
+		e, err := dec.parseEnum(typ.Enum().TypeId(), v)
+		if err != nil {
+			return err
+		}
+		s.SetUint16(capnp.DataOffset(off*2), e^dv.Enum())
+	default:
+		if v == nil {
+			return s.SetPtr(uint16(off), capnp.Ptr{})
+		}
+		p, err := dec.newPtr(s.Segment(), typ, v)
+		if err != nil {
+			return err
+		}
+		return s.SetPtr(uint16(off), p)
+	}
+	return nil
+}
+
+// newPtr allocates a value of type typ in seg and fills it from v,
+// which must not be null.
+func (dec *Decoder) newPtr(seg *capnp.Segment, typ schema.Type, v any) (capnp.Ptr, error) {
+	switch typ.Which() {
+	case schema.Type_Which_text:
+		t, ok := v.(string)
+		if !ok {
+			return capnp.Ptr{}, errors.New("cannot use " + describe(v) + " as text")
+		}
+		tv, err := capnp.NewText(seg, t)
+		return tv.ToPtr(), err
+	case schema.Type_Which_data:
+		b, err := parseBytes(v, annotations{})
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		d, err := capnp.NewData(seg, b)
+		return d.ToPtr(), err
+	case schema.Type_Which_structType:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return capnp.Ptr{}, errors.New("cannot use " + describe(v) + " as struct")
+		}
+		id := typ.StructType().TypeId()
+		sz, err := dec.structSize(id)
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		s, err := capnp.NewStruct(seg, sz)
+		if err != nil {
+			return capnp.Ptr{}, err
+		}
+		return s.ToPtr(), dec.decodeStruct(id, s, obj)
+	case schema.Type_Which_list:
+		l, err := dec.newList(seg, typ, v)
+		return l.ToPtr(), err
+	case schema.Type_Which_interface:
+		return capnp.Ptr{}, errors.New("cannot decode capability")
+	case schema.Type_Which_anyPointer:
+		return capnp.Ptr{}, errors.New("cannot decode AnyPointer")
+	default:
+		return capnp.Ptr{}, errors.New("unknown field type " + typ.Which().String())
+	}
+}
+
+// newList allocates a list of type typ in seg and fills it from v.
+func (dec *Decoder) newList(seg *capnp.Segment, typ schema.Type, v any) (capnp.List, error) {
+	elems, ok := v.([]any)
+	if !ok {
+		return capnp.List{}, errors.New("cannot use " + describe(v) + " as list")
+	}
+	elem, err := typ.List().ElementType()
+	if err != nil {
+		return capnp.List{}, err
+	}
+	n := int32(len(elems))
+	switch elem.Which() {
+	case schema.Type_Which_void:
+		return capnp.List(capnp.NewVoidList(seg, n)), nil
+	case schema.Type_Which_bool:
+		l, err := capnp.NewBitList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			b, ok := ev.(bool)
+			if !ok {
+				return capnp.List{}, errors.New("cannot use " + describe(ev) + " as bool")
+			}
+			l.Set(i, b)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_int8, schema.Type_Which_int16, schema.Type_Which_int32, schema.Type_Which_int64:
+		return newIntList(seg, elem.Which(), elems)
+	case schema.Type_Which_uint8, schema.Type_Which_uint16, schema.Type_Which_uint32, schema.Type_Which_uint64:
+		return newUintList(seg, elem.Which(), elems)
+	case schema.Type_Which_float32:
+		l, err := capnp.NewFloat32List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			x, err := parseFloat(ev, 32)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, float32(x))
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_float64:
+		l, err := capnp.NewFloat64List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			x, err := parseFloat(ev, 64)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, x)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_enum:
+		l, err := capnp.NewUInt16List(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			e, err := dec.parseEnum(elem.Enum().TypeId(), ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			l.Set(i, e)
+		}
+		return capnp.List(l), nil
+	case schema.Type_Which_structType:
+		id := elem.StructType().TypeId()
+		sz, err := dec.structSize(id)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		l, err := capnp.NewCompositeList(seg, sz, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			obj, ok := ev.(map[string]any)
+			if !ok {
+				return capnp.List{}, errors.New("cannot use " + describe(ev) + " as struct")
+			}
+			if err := dec.decodeStruct(id, l.Struct(i), obj); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return l, nil
+	default:
+		l, err := capnp.NewPointerList(seg, n)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		for i, ev := range elems {
+			if ev == nil {
+				continue
+			}
+			p, err := dec.newPtr(seg, elem, ev)
+			if err != nil {
+				return capnp.List{}, err
+			}
+			if err := l.Set(i, p); err != nil {
+				return capnp.List{}, err
+			}
+		}
+		return capnp.List(l), nil
+	}
+}
+
+func newIntList(seg *capnp.Segment, which schema.Type_Which, elems []any) (capnp.List, error) {
+	n := int32(len(elems))
+	var (
+		l   capnp.List
+		err error
+	)
+	bits := 0
+	switch which {
+	case schema.Type_Which_int8:
+		bits = 8
+		var il capnp.Int8List
+		il, err = capnp.NewInt8List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int16:
+		bits = 16
+		var il capnp.Int16List
+		il, err = capnp.NewInt16List(seg, n)
+		l = capnp.List(il)
+	case schema.Type_Which_int32:
+		bits = 32
+		var il capnp.Int32List
+		il, err = capnp.NewInt32List(seg, n)
+		l = capnp.List(il)
+	default:
+		bits = 64
+		var il capnp.Int64List
+		il, err = capnp.NewInt64List(seg, n)
+		l = capnp.List(il)
+	}
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i, ev := range elems {
+		x, err := parseInt(ev, bits)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		switch bits {
+		case 8:
+			capnp.Int8List(l).Set(i, int8(x))
+		case 16:
+			capnp.Int16List(l).Set(i, int16(x))
+		case 32:
+			capnp.Int32List(l).Set(i, int32(x))
+		default:
+			capnp.Int64List(l).Set(i, x)
+		}
+	}
+	return l, nil
+}
+
+func newUintList(seg *capnp.Segment, which schema.Type_Which, elems []any) (capnp.List, error) {
+	n := int32(len(elems))
+	var (
+		l   capnp.List
+		err error
+	)
+	bits := 0
+	switch which {
+	case schema.Type_Which_uint8:
+		bits = 8
+		var ul capnp.UInt8List
+		ul, err = capnp.NewUInt8List(seg, n)
+		l = capnp.List(ul)
+	case schema.Type_Which_uint16:
+		bits = 16
+		var ul capnp.UInt16List
+		ul, err = capnp.NewUInt16List(seg, n)
+		l = capnp.List(ul)
+	case schema.Type_Which_uint32:
+		bits = 32
+		var ul capnp.UInt32List
+		ul, err = capnp.NewUInt32List(seg, n)
+		l = capnp.List(ul)
+	default:
+		bits = 64
+		var ul capnp.UInt64List
+		ul, err = capnp.NewUInt64List(seg, n)
+		l = capnp.List(ul)
+	}
+	if err != nil {
+		return capnp.List{}, err
+	}
+	for i, ev := range elems {
+		x, err := parseUint(ev, bits)
+		if err != nil {
+			return capnp.List{}, err
+		}
+		switch bits {
+		case 8:
+			capnp.UInt8List(l).Set(i, uint8(x))
+		case 16:
+			capnp.UInt16List(l).Set(i, uint16(x))
+		case 32:
+			capnp.UInt32List(l).Set(i, uint32(x))
+		default:
+			capnp.UInt64List(l).Set(i, x)
+		}
+	}
+	return l, nil
+}
+
+func (dec *Decoder) parseEnum(typeID uint64, v any) (uint16, error) {
+	if _, ok := v.(stdjson.Number); ok {
+		u, err := parseUint(v, 16)
+		return uint16(u), err
+	}
+	name, ok := v.(string)
+	if !ok {
+		return 0, errors.New("cannot use " + describe(v) + " as enum")
+	}
+	n, err := dec.findNode(typeID, schema.Node_Which_enum)
+	if err != nil {
+		return 0, err
+	}
+	enums, err := n.Enum().Enumerants()
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.At(i)
+		ename, _ := e.Name()
+		anns, _ := e.Annotations()
+		if a, err := parseAnnotations(anns); err == nil && a.hasName {
+			ename = a.name
+		}
+		if ename == name {
+			return uint16(i), nil
+		}
+	}
+	return 0, errors.New("unknown enumerant " + strconv.Quote(name))
+}
+
+// number returns the text of v if it is a number or a string, which
+// the C++ implementation writes for 64-bit integers.
+func number(v any, what string) (string, error) {
+	switch v := v.(type) {
+	case stdjson.Number:
+		return string(v), nil
+	case string:
+		return v, nil
+	default:
+		return "", errors.New("cannot use " + describe(v) + " as " + what)
+	}
+}
+
+func parseInt(v any, bits int) (int64, error) {
+	s, err := number(v, "integer")
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(s, 10, bits)
+	if err != nil {
+		return 0, errors.New("invalid Int" + str.Itod(bits) + " " + s)
+	}
+	return i, nil
+}
+
+func parseUint(v any, bits int) (uint64, error) {
+	s, err := number(v, "integer")
+	if err != nil {
+		return 0, err
+	}
+	u, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return 0, errors.New("invalid UInt" + str.Itod(bits) + " " + s)
+	}
+	return u, nil
+}
+
+func parseFloat(v any, bits int) (float64, error) {
+	s, err := number(v, "float")
+	if err != nil {
+		return 0, err
+	}
+	switch s {
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	x, err := strconv.ParseFloat(s, bits)
+	if err != nil {
+		return 0, errors.New("invalid Float" + str.Itod(bits) + " " + s)
+	}
+	return x, nil
+}
+
+// parseBytes returns the Data encoded in v: a string if a field has
+// $base64 or $hex, and an array of bytes otherwise.
+func parseBytes(v any, a annotations) ([]byte, error) {
+	if s, ok := v.(string); ok {
+		switch {
+		case a.base64:
+			return base64.StdEncoding.DecodeString(s)
+		case a.hex:
+			return hex.DecodeString(s)
+		}
+	}
+	elems, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("cannot use " + describe(v) + " as data")
+	}
+	b := make([]byte, len(elems))
+	for i, ev := range elems {
+		u, err := parseUint(ev, 8)
+		if err != nil {
+			return nil, err
+		}
+		b[i] = byte(u)
+	}
+	return b, nil
+}
+
+// describe returns the kind of a decoded JSON value, for errors.
+func describe(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case stdjson.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
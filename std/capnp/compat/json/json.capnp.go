@@ -55,6 +55,20 @@ func (w Value_Which) String() string {
 	return "Value_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Value_Visitor handles every variant of the Value union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Value_Visitor interface {
+	VisitNull(Value) error
+	VisitBoolean(Value) error
+	VisitNumber(Value) error
+	VisitString_(Value) error
+	VisitArray(Value) error
+	VisitObject(Value) error
+	VisitCall(Value) error
+	VisitRaw(Value) error
+}
+
 // Value_TypeID is the unique identifier for the type Value.
 const Value_TypeID = 0xa3fa7845f919dd83
 
@@ -93,6 +107,30 @@ func (s Value) ToPtr() capnp.Ptr {
 func (s Value) Which() Value_Which {
 	return Value_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Value) Visit(v Value_Visitor) error {
+	switch w := s.Which(); w {
+	case Value_Which_null:
+		return v.VisitNull(s)
+	case Value_Which_boolean:
+		return v.VisitBoolean(s)
+	case Value_Which_number:
+		return v.VisitNumber(s)
+	case Value_Which_string_:
+		return v.VisitString_(s)
+	case Value_Which_array:
+		return v.VisitArray(s)
+	case Value_Which_object:
+		return v.VisitObject(s)
+	case Value_Which_call:
+		return v.VisitCall(s)
+	case Value_Which_raw:
+		return v.VisitRaw(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Value) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -0,0 +1,252 @@
+package json
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// Encode returns the JSON text of v.  Values of the call variant are
+// written as a function name followed by its parameters in parentheses,
+// as by the C++ implementation, so the result is only valid JSON if v
+// contains no calls.  Raw values are written as they are.
+func Encode(v Value) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v Value) error {
+	switch v.Which() {
+	case Value_Which_null:
+		buf.WriteString("null")
+	case Value_Which_boolean:
+		buf.WriteString(strconv.FormatBool(v.Boolean()))
+	case Value_Which_number:
+		n := v.Number()
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return fmt.Errorf("json: cannot encode number %v", n)
+		}
+		buf.WriteString(strconv.FormatFloat(n, 'g', -1, 64))
+	case Value_Which_string_:
+		s, err := v.String_()
+		if err != nil {
+			return err
+		}
+		return encodeString(buf, s)
+	case Value_Which_array:
+		l, err := v.Array()
+		if err != nil {
+			return err
+		}
+		return encodeList(buf, '[', l, ']')
+	case Value_Which_object:
+		fields, err := v.Object()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('{')
+		for i := 0; i < fields.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			f := fields.At(i)
+			name, err := f.Name()
+			if err != nil {
+				return err
+			}
+			if err := encodeString(buf, name); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			fv, err := f.Value()
+			if err != nil {
+				return err
+			}
+			if err := encode(buf, fv); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case Value_Which_call:
+		call, err := v.Call()
+		if err != nil {
+			return err
+		}
+		fn, err := call.Function()
+		if err != nil {
+			return err
+		}
+		params, err := call.Params()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(fn)
+		return encodeList(buf, '(', params, ')')
+	case Value_Which_raw:
+		raw, err := v.Raw()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(raw)
+	default:
+		return fmt.Errorf("json: unknown value type %v", v.Which())
+	}
+	return nil
+}
+
+func encodeString(buf *bytes.Buffer, s string) error {
+	b, err := stdjson.Marshal(s)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+func encodeList(buf *bytes.Buffer, open byte, l Value_List, close byte) error {
+	buf.WriteByte(open)
+	for i := 0; i < l.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := encode(buf, l.At(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(close)
+	return nil
+}
+
+// Decode parses the JSON text data into a new Value allocated in seg.
+// Numbers are stored as float64, so integers beyond 2^53 lose
+// precision.
+func Decode(seg *capnp.Segment, data []byte) (Value, error) {
+	v, err := NewValue(seg)
+	if err != nil {
+		return Value{}, err
+	}
+	dec := stdjson.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := decode(dec, v); err != nil {
+		return Value{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Value{}, errors.New("json: unexpected data after value")
+	}
+	return v, nil
+}
+
+func decode(dec *stdjson.Decoder, v Value) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case nil:
+		v.SetNull()
+	case bool:
+		v.SetBoolean(tok)
+	case stdjson.Number:
+		n, err := tok.Float64()
+		if err != nil {
+			return err
+		}
+		v.SetNumber(n)
+	case string:
+		return v.SetString_(tok)
+	case stdjson.Delim:
+		switch tok {
+		case '[':
+			return decodeArray(dec, v)
+		case '{':
+			return decodeObject(dec, v)
+		}
+		return fmt.Errorf("json: unexpected %v", tok)
+	}
+	return nil
+}
+
+// decodeArray decodes the elements of an array whose opening bracket
+// has been read.  The length of a list is fixed when it is allocated,
+// so the elements are decoded into a temporary message first.
+func decodeArray(dec *stdjson.Decoder, v Value) error {
+	_, tmp := capnp.NewSingleSegmentMessage(nil)
+	var elems []Value
+	for dec.More() {
+		e, err := NewValue(tmp)
+		if err != nil {
+			return err
+		}
+		if err := decode(dec, e); err != nil {
+			return err
+		}
+		elems = append(elems, e)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	l, err := v.NewArray(int32(len(elems)))
+	if err != nil {
+		return err
+	}
+	for i, e := range elems {
+		if err := l.Set(i, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeObject decodes the fields of an object whose opening brace has
+// been read.
+func decodeObject(dec *stdjson.Decoder, v Value) error {
+	_, tmp := capnp.NewSingleSegmentMessage(nil)
+	var fields []Value_Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("json: unexpected %v in object", tok)
+		}
+		f, err := NewValue_Field(tmp)
+		if err != nil {
+			return err
+		}
+		if err := f.SetName(name); err != nil {
+			return err
+		}
+		fv, err := f.NewValue()
+		if err != nil {
+			return err
+		}
+		if err := decode(dec, fv); err != nil {
+			return err
+		}
+		fields = append(fields, f)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	l, err := v.NewObject(int32(len(fields)))
+	if err != nil {
+		return err
+	}
+	for i, f := range fields {
+		if err := l.Set(i, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/std/capnp/compat/json"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		`null`,
+		`true`,
+		`-1.5`,
+		`"a \"quoted\" string\n"`,
+		`[]`,
+		`{}`,
+		`[1,"two",[3],{"four":4}]`,
+		`{"name":"capnp","tags":["rpc","serialization"],"stars":1e+06,"meta":{"archived":false,"license":null}}`,
+	}
+	for _, text := range tests {
+		_, seg := capnp.NewSingleSegmentMessage(nil)
+		v, err := json.Decode(seg, []byte(text))
+		require.NoError(t, err, "Decode(%s)", text)
+		out, err := json.Encode(v)
+		require.NoError(t, err, "Encode(Decode(%s))", text)
+		assert.JSONEq(t, text, string(out))
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{``, `[1,`, `{"a"}`, `1 2`} {
+		_, seg := capnp.NewSingleSegmentMessage(nil)
+		_, err := json.Decode(seg, []byte(text))
+		assert.Error(t, err, "Decode(%q)", text)
+	}
+}
+
+func TestEncodeCallAndRaw(t *testing.T) {
+	t.Parallel()
+
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	v, err := json.NewValue(seg)
+	require.NoError(t, err)
+	call, err := v.NewCall()
+	require.NoError(t, err)
+	require.NoError(t, call.SetFunction("Date"))
+	params, err := call.NewParams(2)
+	require.NoError(t, err)
+	params.At(0).SetNumber(2024)
+	require.NoError(t, params.At(1).SetRaw(`"raw"`))
+
+	out, err := json.Encode(v)
+	require.NoError(t, err)
+	assert.Equal(t, `Date(2024,"raw")`, string(out))
+}
@@ -18,6 +18,10 @@ type Persistent capnp.Client
 // Persistent_TypeID is the unique identifier for the type Persistent.
 const Persistent_TypeID = 0xc8cb212fcd9f5691
 
+// Persistent_Fingerprint is a hash of the schema of Persistent's methods,
+// used to detect peers built from a different version of the schema.
+const Persistent_Fingerprint = 0x020e5975f23f630f
+
 func (c Persistent) Save(ctx context.Context, params func(Persistent_SaveParams) error) (Persistent_SaveResults_Future, capnp.ReleaseFunc) {
 
 	s := capnp.Send{
@@ -114,6 +118,16 @@ type Persistent_Server interface {
 	Save(context.Context, Persistent_save) error
 }
 
+// Persistent_UnimplementedServer can be embedded in an implementation of
+// Persistent_Server, including those of interfaces that extend Persistent, to fail
+// the methods it does not define with an unimplemented exception.
+type Persistent_UnimplementedServer struct{}
+
+// Save returns an unimplemented exception.
+func (Persistent_UnimplementedServer) Save(context.Context, Persistent_save) error {
+	return capnp.Unimplemented("unimplemented method persistent.capnp:Persistent.save")
+}
+
 // Persistent_NewServer creates a new Server from an implementation of Persistent_Server.
 func Persistent_NewServer(s Persistent_Server) *server.Server {
 	c, _ := s.(server.Shutdowner)
@@ -126,6 +140,19 @@ func Persistent_ServerToClient(s Persistent_Server) Persistent {
 	return Persistent(capnp.NewClient(Persistent_NewServer(s)))
 }
 
+// Persistent_NewServerWithOptions is like Persistent_NewServer, but configures the Server with opts.
+// If opts is nil, server.DefaultOptions are used.
+func Persistent_NewServerWithOptions(s Persistent_Server, opts *server.Options) *server.Server {
+	c, _ := s.(server.Shutdowner)
+	return server.NewWithOptions(Persistent_Methods(nil, s), s, c, opts)
+}
+
+// Persistent_ServerToClientWithOptions is like Persistent_ServerToClient, but configures the Server with opts.
+// The caller is responsible for calling Release on the returned Client.
+func Persistent_ServerToClientWithOptions(s Persistent_Server, opts *server.Options) Persistent {
+	return Persistent(capnp.NewClient(Persistent_NewServerWithOptions(s, opts)))
+}
+
 // Persistent_Methods appends Methods to a slice that invoke the methods on s.
 // This can be used to create a more complicated Server.
 func Persistent_Methods(methods []server.Method, s Persistent_Server) []server.Method {
@@ -168,12 +195,29 @@ func (c Persistent_save) AllocResults() (Persistent_SaveResults, error) {
 // Persistent_List is a list of Persistent.
 type Persistent_List = capnp.CapList[Persistent]
 
-// NewPersistent creates a new list of Persistent.
+// NewPersistent_List creates a new list of Persistent.
 func NewPersistent_List(s *capnp.Segment, sz int32) (Persistent_List, error) {
 	l, err := capnp.NewPointerList(s, sz)
 	return capnp.CapList[Persistent](l), err
 }
 
+func init() {
+	capnp.RegisterMethods(
+		capnp.MethodInfo{
+			Method: capnp.Method{
+				InterfaceID:   0xc8cb212fcd9f5691,
+				MethodID:      0,
+				InterfaceName: "persistent.capnp:Persistent",
+				MethodName:    "save",
+			},
+			ParamsTypeID:  0xf76fba59183073a5,
+			ResultsTypeID: 0xb76848c18c40efbf,
+			ResultsSize:   capnp.ObjectSize{DataSize: 0, PointerCount: 1},
+		},
+	)
+	capnp.RegisterFingerprint(Persistent_TypeID, Persistent_Fingerprint)
+}
+
 type Persistent_SaveParams capnp.Struct
 
 // Persistent_SaveParams_TypeID is the unique identifier for the type Persistent_SaveParams.
@@ -0,0 +1,113 @@
+package persistent
+
+import (
+	"context"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// Save asks c for a SturdyRef that can later be used to restore it,
+// sealed for the owner built by sealFor, if it is not nil.  The
+// SturdyRef points into the call's results, so it is only valid until
+// the returned ReleaseFunc is called.  c must implement Persistent.
+func Save(ctx context.Context, c capnp.Client, sealFor func(*capnp.Segment) (capnp.Ptr, error)) (capnp.Ptr, capnp.ReleaseFunc, error) {
+	var params func(Persistent_SaveParams) error
+	if sealFor != nil {
+		params = func(p Persistent_SaveParams) error {
+			owner, err := sealFor(p.Segment())
+			if err != nil {
+				return err
+			}
+			return p.SetSealFor(owner)
+		}
+	}
+	ans, release := Persistent(c).Save(ctx, params)
+	res, err := ans.Struct()
+	if err != nil {
+		release()
+		return capnp.Ptr{}, func() {}, err
+	}
+	ref, err := res.SturdyRef()
+	if err != nil {
+		release()
+		return capnp.Ptr{}, func() {}, err
+	}
+	return ref, release, nil
+}
+
+// A SaveFunc saves a capability.  It is passed the owner the
+// SturdyRef is to be sealed for, which may be null, and the segment of
+// the results, and returns the SturdyRef.  Allocating the SturdyRef in
+// seg avoids copying it into the results.
+type SaveFunc func(ctx context.Context, sealFor capnp.Ptr, seg *capnp.Segment) (capnp.Ptr, error)
+
+// Attach returns a client that serves the Persistent interface with
+// save and forwards calls to any other interface to c.  This makes any
+// capability persistent without changing its implementation:
+//
+//	c = persistent.Attach(c, func(ctx context.Context, sealFor capnp.Ptr, seg *capnp.Segment) (capnp.Ptr, error) {
+//		token, err := capnp.NewData(seg, store.Save(id))
+//		return token.ToPtr(), err
+//	})
+//
+// Attach takes ownership of c.
+func Attach(c capnp.Client, save SaveFunc) capnp.Client {
+	return capnp.NewClient(&attached{
+		target: c,
+		saver:  capnp.Client(Persistent_ServerToClient(saver(save))),
+	})
+}
+
+// saver adapts a SaveFunc to Persistent_Server.
+type saver SaveFunc
+
+func (s saver) Save(ctx context.Context, call Persistent_save) error {
+	sealFor, err := call.Args().SealFor()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	ref, err := s(ctx, sealFor, res.Segment())
+	if err != nil {
+		return err
+	}
+	return res.SetSturdyRef(ref)
+}
+
+// attached is the capnp.ClientHook behind a client returned by Attach.
+type attached struct {
+	target capnp.Client
+	saver  capnp.Client
+}
+
+// route returns the client that handles calls to m.
+func (a *attached) route(m capnp.Method) capnp.Client {
+	if m.InterfaceID == Persistent_TypeID {
+		return a.saver
+	}
+	return a.target
+}
+
+func (a *attached) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	return a.route(s.Method).SendCall(ctx, s)
+}
+
+func (a *attached) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	return a.route(r.Method).RecvCall(ctx, r)
+}
+
+func (a *attached) Brand() capnp.Brand {
+	return capnp.Brand{Value: a}
+}
+
+func (a *attached) Shutdown() {
+	a.target.Release()
+	a.saver.Release()
+}
+
+func (a *attached) String() string {
+	return "persistent.Attach(" + a.target.String() + ")"
+}
@@ -0,0 +1,83 @@
+package persistent_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/std/capnp/persistent"
+)
+
+type echoer struct{}
+
+func (echoer) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in)
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	boot := persistent.Attach(capnp.Client(air.Echo_ServerToClient(echoer{})),
+		func(ctx context.Context, sealFor capnp.Ptr, seg *capnp.Segment) (capnp.Ptr, error) {
+			owner := "anyone"
+			if sealFor.IsValid() {
+				owner = sealFor.Text()
+			}
+			ref, err := capnp.NewText(seg, "token-for-"+owner)
+			return ref.ToPtr(), err
+		})
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{BootstrapClient: boot}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	client := clientConn.Bootstrap(ctx)
+	defer client.Release()
+
+	// Calls to other interfaces are forwarded.
+	ans, release := air.Echo(client).Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn("hi")
+	})
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	out, err := res.Out()
+	require.NoError(t, err)
+	assert.Equal(t, "hi", out)
+
+	ref, release, err := persistent.Save(ctx, client, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "token-for-anyone", ref.Text())
+	release()
+
+	ref, release, err = persistent.Save(ctx, client, func(seg *capnp.Segment) (capnp.Ptr, error) {
+		owner, err := capnp.NewText(seg, "alice")
+		return owner.ToPtr(), err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "token-for-alice", ref.Text())
+	release()
+}
+
+func TestSaveUnimplemented(t *testing.T) {
+	t.Parallel()
+
+	c := capnp.Client(air.Echo_ServerToClient(echoer{}))
+	defer c.Release()
+	_, release, err := persistent.Save(context.Background(), c, nil)
+	defer release()
+	assert.Error(t, err)
+}
@@ -65,6 +65,26 @@ func (w Message_Which) String() string {
 	return "Message_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Message_Visitor handles every variant of the Message union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Message_Visitor interface {
+	VisitUnimplemented(Message) error
+	VisitAbort(Message) error
+	VisitBootstrap(Message) error
+	VisitCall(Message) error
+	VisitReturn(Message) error
+	VisitFinish(Message) error
+	VisitResolve(Message) error
+	VisitRelease(Message) error
+	VisitDisembargo(Message) error
+	VisitObsoleteSave(Message) error
+	VisitObsoleteDelete(Message) error
+	VisitProvide(Message) error
+	VisitAccept(Message) error
+	VisitJoin(Message) error
+}
+
 // Message_TypeID is the unique identifier for the type Message.
 const Message_TypeID = 0x91b79f1f808db032
 
@@ -103,6 +123,42 @@ func (s Message) ToPtr() capnp.Ptr {
 func (s Message) Which() Message_Which {
 	return Message_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Message) Visit(v Message_Visitor) error {
+	switch w := s.Which(); w {
+	case Message_Which_unimplemented:
+		return v.VisitUnimplemented(s)
+	case Message_Which_abort:
+		return v.VisitAbort(s)
+	case Message_Which_bootstrap:
+		return v.VisitBootstrap(s)
+	case Message_Which_call:
+		return v.VisitCall(s)
+	case Message_Which_return:
+		return v.VisitReturn(s)
+	case Message_Which_finish:
+		return v.VisitFinish(s)
+	case Message_Which_resolve:
+		return v.VisitResolve(s)
+	case Message_Which_release:
+		return v.VisitRelease(s)
+	case Message_Which_disembargo:
+		return v.VisitDisembargo(s)
+	case Message_Which_obsoleteSave:
+		return v.VisitObsoleteSave(s)
+	case Message_Which_obsoleteDelete:
+		return v.VisitObsoleteDelete(s)
+	case Message_Which_provide:
+		return v.VisitProvide(s)
+	case Message_Which_accept:
+		return v.VisitAccept(s)
+	case Message_Which_join:
+		return v.VisitJoin(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Message) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -704,6 +760,15 @@ func (w Call_sendResultsTo_Which) String() string {
 	return "Call_sendResultsTo_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Call_sendResultsTo_Visitor handles every variant of the Call_sendResultsTo union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Call_sendResultsTo_Visitor interface {
+	VisitCaller(Call_sendResultsTo) error
+	VisitYourself(Call_sendResultsTo) error
+	VisitThirdParty(Call_sendResultsTo) error
+}
+
 // Call_TypeID is the unique identifier for the type Call.
 const Call_TypeID = 0x836a53ce789d4cd4
 
@@ -850,6 +915,20 @@ func (s Call) SendResultsTo() Call_sendResultsTo { return Call_sendResultsTo(s)
 func (s Call_sendResultsTo) Which() Call_sendResultsTo_Which {
 	return Call_sendResultsTo_Which(capnp.Struct(s).Uint16(6))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Call_sendResultsTo) Visit(v Call_sendResultsTo_Visitor) error {
+	switch w := s.Which(); w {
+	case Call_sendResultsTo_Which_caller:
+		return v.VisitCaller(s)
+	case Call_sendResultsTo_Which_yourself:
+		return v.VisitYourself(s)
+	case Call_sendResultsTo_Which_thirdParty:
+		return v.VisitThirdParty(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Call_sendResultsTo) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -959,6 +1038,18 @@ func (w Return_Which) String() string {
 	return "Return_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Return_Visitor handles every variant of the Return union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Return_Visitor interface {
+	VisitResults(Return) error
+	VisitException(Return) error
+	VisitCanceled(Return) error
+	VisitResultsSentElsewhere(Return) error
+	VisitTakeFromOtherQuestion(Return) error
+	VisitAcceptFromThirdParty(Return) error
+}
+
 // Return_TypeID is the unique identifier for the type Return.
 const Return_TypeID = 0x9e19b28d3db3573a
 
@@ -997,6 +1088,26 @@ func (s Return) ToPtr() capnp.Ptr {
 func (s Return) Which() Return_Which {
 	return Return_Which(capnp.Struct(s).Uint16(6))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Return) Visit(v Return_Visitor) error {
+	switch w := s.Which(); w {
+	case Return_Which_results:
+		return v.VisitResults(s)
+	case Return_Which_exception:
+		return v.VisitException(s)
+	case Return_Which_canceled:
+		return v.VisitCanceled(s)
+	case Return_Which_resultsSentElsewhere:
+		return v.VisitResultsSentElsewhere(s)
+	case Return_Which_takeFromOtherQuestion:
+		return v.VisitTakeFromOtherQuestion(s)
+	case Return_Which_acceptFromThirdParty:
+		return v.VisitAcceptFromThirdParty(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Return) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -1271,6 +1382,14 @@ func (w Resolve_Which) String() string {
 	return "Resolve_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Resolve_Visitor handles every variant of the Resolve union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Resolve_Visitor interface {
+	VisitCap(Resolve) error
+	VisitException(Resolve) error
+}
+
 // Resolve_TypeID is the unique identifier for the type Resolve.
 const Resolve_TypeID = 0xbbc29655fa89086e
 
@@ -1309,6 +1428,18 @@ func (s Resolve) ToPtr() capnp.Ptr {
 func (s Resolve) Which() Resolve_Which {
 	return Resolve_Which(capnp.Struct(s).Uint16(4))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Resolve) Visit(v Resolve_Visitor) error {
+	switch w := s.Which(); w {
+	case Resolve_Which_cap:
+		return v.VisitCap(s)
+	case Resolve_Which_exception:
+		return v.VisitException(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Resolve) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -1522,6 +1653,16 @@ func (w Disembargo_context_Which) String() string {
 	return "Disembargo_context_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// Disembargo_context_Visitor handles every variant of the Disembargo_context union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type Disembargo_context_Visitor interface {
+	VisitSenderLoopback(Disembargo_context) error
+	VisitReceiverLoopback(Disembargo_context) error
+	VisitAccept(Disembargo_context) error
+	VisitProvide(Disembargo_context) error
+}
+
 // Disembargo_TypeID is the unique identifier for the type Disembargo.
 const Disembargo_TypeID = 0xf964368b0fbd3711
 
@@ -1596,6 +1737,22 @@ func (s Disembargo) Context() Disembargo_context { return Disembargo_context(s)
 func (s Disembargo_context) Which() Disembargo_context_Which {
 	return Disembargo_context_Which(capnp.Struct(s).Uint16(4))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s Disembargo_context) Visit(v Disembargo_context_Visitor) error {
+	switch w := s.Which(); w {
+	case Disembargo_context_Which_senderLoopback:
+		return v.VisitSenderLoopback(s)
+	case Disembargo_context_Which_receiverLoopback:
+		return v.VisitReceiverLoopback(s)
+	case Disembargo_context_Which_accept:
+		return v.VisitAccept(s)
+	case Disembargo_context_Which_provide:
+		return v.VisitProvide(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s Disembargo_context) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2021,6 +2178,14 @@ func (w MessageTarget_Which) String() string {
 	return "MessageTarget_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// MessageTarget_Visitor handles every variant of the MessageTarget union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type MessageTarget_Visitor interface {
+	VisitImportedCap(MessageTarget) error
+	VisitPromisedAnswer(MessageTarget) error
+}
+
 // MessageTarget_TypeID is the unique identifier for the type MessageTarget.
 const MessageTarget_TypeID = 0x95bc14545813fbc1
 
@@ -2059,6 +2224,18 @@ func (s MessageTarget) ToPtr() capnp.Ptr {
 func (s MessageTarget) Which() MessageTarget_Which {
 	return MessageTarget_Which(capnp.Struct(s).Uint16(4))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s MessageTarget) Visit(v MessageTarget_Visitor) error {
+	switch w := s.Which(); w {
+	case MessageTarget_Which_importedCap:
+		return v.VisitImportedCap(s)
+	case MessageTarget_Which_promisedAnswer:
+		return v.VisitPromisedAnswer(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s MessageTarget) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2268,6 +2445,18 @@ func (w CapDescriptor_Which) String() string {
 	return "CapDescriptor_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// CapDescriptor_Visitor handles every variant of the CapDescriptor union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type CapDescriptor_Visitor interface {
+	VisitNone(CapDescriptor) error
+	VisitSenderHosted(CapDescriptor) error
+	VisitSenderPromise(CapDescriptor) error
+	VisitReceiverHosted(CapDescriptor) error
+	VisitReceiverAnswer(CapDescriptor) error
+	VisitThirdPartyHosted(CapDescriptor) error
+}
+
 // CapDescriptor_TypeID is the unique identifier for the type CapDescriptor.
 const CapDescriptor_TypeID = 0x8523ddc40b86b8b0
 
@@ -2306,6 +2495,26 @@ func (s CapDescriptor) ToPtr() capnp.Ptr {
 func (s CapDescriptor) Which() CapDescriptor_Which {
 	return CapDescriptor_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s CapDescriptor) Visit(v CapDescriptor_Visitor) error {
+	switch w := s.Which(); w {
+	case CapDescriptor_Which_none:
+		return v.VisitNone(s)
+	case CapDescriptor_Which_senderHosted:
+		return v.VisitSenderHosted(s)
+	case CapDescriptor_Which_senderPromise:
+		return v.VisitSenderPromise(s)
+	case CapDescriptor_Which_receiverHosted:
+		return v.VisitReceiverHosted(s)
+	case CapDescriptor_Which_receiverAnswer:
+		return v.VisitReceiverAnswer(s)
+	case CapDescriptor_Which_thirdPartyHosted:
+		return v.VisitThirdPartyHosted(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s CapDescriptor) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
@@ -2569,6 +2778,14 @@ func (w PromisedAnswer_Op_Which) String() string {
 	return "PromisedAnswer_Op_Which(" + strconv.FormatUint(uint64(w), 10) + ")"
 }
 
+// PromisedAnswer_Op_Visitor handles every variant of the PromisedAnswer_Op union.
+// Adding a variant to the schema adds a method to this interface, so
+// implementations fail to compile until they handle it.
+type PromisedAnswer_Op_Visitor interface {
+	VisitNoop(PromisedAnswer_Op) error
+	VisitGetPointerField(PromisedAnswer_Op) error
+}
+
 // PromisedAnswer_Op_TypeID is the unique identifier for the type PromisedAnswer_Op.
 const PromisedAnswer_Op_TypeID = 0xf316944415569081
 
@@ -2607,6 +2824,18 @@ func (s PromisedAnswer_Op) ToPtr() capnp.Ptr {
 func (s PromisedAnswer_Op) Which() PromisedAnswer_Op_Which {
 	return PromisedAnswer_Op_Which(capnp.Struct(s).Uint16(0))
 }
+
+// Visit calls the method of v that corresponds to s.Which().
+func (s PromisedAnswer_Op) Visit(v PromisedAnswer_Op_Visitor) error {
+	switch w := s.Which(); w {
+	case PromisedAnswer_Op_Which_noop:
+		return v.VisitNoop(s)
+	case PromisedAnswer_Op_Which_getPointerField:
+		return v.VisitGetPointerField(s)
+	default:
+		return capnp.Unimplemented("unhandled " + w.String())
+	}
+}
 func (s PromisedAnswer_Op) IsValid() bool {
 	return capnp.Struct(s).IsValid()
 }
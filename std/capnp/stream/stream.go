@@ -0,0 +1,9 @@
+package stream
+
+import "capnproto.org/go/capnp/v3"
+
+// IsStreaming reports whether m is a streaming method, that is, one
+// declared with "-> stream", whose results are StreamResult.
+func IsStreaming(m capnp.MethodInfo) bool {
+	return m.ResultsTypeID == StreamResult_TypeID
+}
@@ -0,0 +1,25 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/std/capnp/stream"
+	"capnproto.org/go/capnp/v3/std/health"
+)
+
+func TestIsStreaming(t *testing.T) {
+	t.Parallel()
+
+	echo, ok := capnp.LookupMethod(air.Echo_TypeID, 0)
+	require.True(t, ok)
+	assert.False(t, stream.IsStreaming(echo))
+
+	ms := capnp.InterfaceMethods(health.Health_Watcher_TypeID)
+	require.Len(t, ms, 1)
+	assert.True(t, stream.IsStreaming(ms[0]), "%v is declared -> stream", ms[0].String())
+}
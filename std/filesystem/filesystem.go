@@ -0,0 +1,427 @@
+// Package filesystem shares read-only file trees over RPC.
+//
+// NewDirectory serves an fs.FS as a Directory capability, and FS wraps a
+// Directory, local or imported from another vat, as an fs.FS.  Together
+// they let a vat read another vat's assets or configuration with the
+// standard io/fs APIs, such as fs.ReadFile, fs.WalkDir or
+// http.FileServer, while the exporting vat decides exactly which tree
+// is visible.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// defaultChunkSize is the size of the chunks a File streams its
+// contents in, unless the reader asks for another size.
+const defaultChunkSize = 32 << 10
+
+// NewDirectory returns a Directory that serves the files in fsys.  The
+// caller is responsible for calling Release on the returned client.
+func NewDirectory(fsys fs.FS) Directory {
+	return Directory_ServerToClient(dirServer{fsys})
+}
+
+// dirServer is the Directory_Server returned by NewDirectory.
+type dirServer struct {
+	fsys fs.FS
+}
+
+func (d dirServer) Open(ctx context.Context, call Directory_open) error {
+	name, err := call.Args().Name()
+	if err != nil {
+		return err
+	}
+	f, err := d.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return res.SetFile(File_ServerToClient(&fileServer{f: f, fsys: d.fsys, name: name}))
+}
+
+func (d dirServer) Stat(ctx context.Context, call Directory_stat) error {
+	name, err := call.Args().Name()
+	if err != nil {
+		return err
+	}
+	info, err := fs.Stat(d.fsys, name)
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	st, err := res.NewStat()
+	if err != nil {
+		return err
+	}
+	return setStat(st, info)
+}
+
+func (d dirServer) ReadDir(ctx context.Context, call Directory_readDir) error {
+	name, err := call.Args().Name()
+	if err != nil {
+		return err
+	}
+	entries, err := fs.ReadDir(d.fsys, name)
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	list, err := res.NewEntries(int32(len(entries)))
+	if err != nil {
+		return err
+	}
+	for i, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		if err := setStat(list.At(i), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileServer is the File_Server for a file opened through a dirServer.
+// The file is closed when the File capability is released.
+type fileServer struct {
+	f    fs.File
+	fsys fs.FS
+	name string
+}
+
+func (s *fileServer) Stat(ctx context.Context, call File_stat) error {
+	info, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	st, err := res.NewStat()
+	if err != nil {
+		return err
+	}
+	return setStat(st, info)
+}
+
+func (s *fileServer) Read(ctx context.Context, call File_read) error {
+	sink := call.Args().Sink().AddRef()
+	defer sink.Release()
+	size := int(call.Args().ChunkSize())
+	if size == 0 {
+		size = defaultChunkSize
+	}
+	// Open the file again, so that concurrent reads each start at the
+	// beginning.  Streaming may take a while, so let other calls run
+	// in the meantime.
+	f, err := s.fsys.Open(s.name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	call.Go()
+
+	buf := make([]byte, size)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			werr := sink.Write(ctx, func(p Sink_write_Params) error {
+				return p.SetData(buf[:n])
+			})
+			if werr != nil {
+				return werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return sink.WaitStreaming()
+}
+
+func (s *fileServer) Shutdown() {
+	s.f.Close()
+}
+
+func setStat(st Stat, info fs.FileInfo) error {
+	st.SetSize(uint64(info.Size()))
+	st.SetModTime(info.ModTime().UnixNano())
+	st.SetMode(uint32(info.Mode()))
+	return st.SetName(info.Name())
+}
+
+// FS returns an fs.FS that reads files from d, making calls with ctx.
+// The returned file system also implements fs.StatFS and fs.ReadDirFS.
+// FS does not take ownership of d: the caller must keep d alive while
+// using the file system, and release it afterwards.
+//
+// Errors reported by the remote vat for missing files wrap
+// fs.ErrNotExist.  Opened files hold a reference to the remote File
+// until they are closed.
+func FS(ctx context.Context, d Directory) fs.FS {
+	return remoteFS{ctx: ctx, d: d}
+}
+
+type remoteFS struct {
+	ctx context.Context
+	d   Directory
+}
+
+func (r remoteFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	info, err := r.Stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.Unwrap(err)}
+	}
+	if info.IsDir() {
+		entries, err := r.ReadDir(name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: errors.Unwrap(err)}
+		}
+		return &remoteDir{info: info, entries: entries}, nil
+	}
+
+	ans, release := r.d.Open(r.ctx, func(p Directory_open_Params) error {
+		return p.SetName(name)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return &remoteFile{ctx: r.ctx, f: res.File().AddRef(), info: info}, nil
+}
+
+func (r remoteFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	ans, release := r.d.Stat(r.ctx, func(p Directory_stat_Params) error {
+		return p.SetName(name)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	st, err := res.Stat()
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	info, err := readStat(st)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return info, nil
+}
+
+func (r remoteFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	ans, release := r.d.ReadDir(r.ctx, func(p Directory_readDir_Params) error {
+		return p.SetName(name)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	list, err := res.Entries()
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	entries := make([]fs.DirEntry, list.Len())
+	for i := range entries {
+		info, err := readStat(list.At(i))
+		if err != nil {
+			return nil, pathError("readdir", name, err)
+		}
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+// pathError wraps an error returned by the remote vat.  The identity of
+// errors is lost on the wire, so fs.ErrNotExist is recognized by its
+// message.
+func pathError(op, name string, err error) error {
+	if strings.Contains(err.Error(), fs.ErrNotExist.Error()) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fileInfo is the fs.FileInfo of a Stat.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func readStat(st Stat) (fileInfo, error) {
+	name, err := st.Name()
+	if err != nil {
+		return fileInfo{}, err
+	}
+	return fileInfo{
+		name:    name,
+		size:    int64(st.Size()),
+		mode:    fs.FileMode(st.Mode()),
+		modTime: time.Unix(0, st.ModTime()),
+	}, nil
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi fileInfo) Sys() any           { return nil }
+
+// remoteFile is an fs.File backed by a remote File.  Its contents are
+// streamed from the remote vat on the first call to Read.
+type remoteFile struct {
+	ctx  context.Context
+	f    File
+	info fs.FileInfo
+
+	r      *io.PipeReader // nil until the first Read
+	closed bool
+}
+
+func (f *remoteFile) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	return f.info, nil
+}
+
+func (f *remoteFile) Read(b []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	if f.r == nil {
+		f.startRead()
+	}
+	return f.r.Read(b)
+}
+
+// startRead starts streaming the file's contents into f.r.
+func (f *remoteFile) startRead() {
+	r, w := io.Pipe()
+	f.r = r
+	sink := Sink_ServerToClient(pipeSink{w})
+	ans, release := f.f.Read(f.ctx, func(p File_read_Params) error {
+		return p.SetSink(sink)
+	})
+	go func() {
+		defer release()
+		_, err := ans.Struct()
+		if err == nil {
+			err = io.EOF
+		}
+		// The server waits for its writes to return before it
+		// returns, so all of the data has been written to w by now.
+		w.CloseWithError(err)
+	}()
+}
+
+func (f *remoteFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.info.Name(), Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.r != nil {
+		f.r.Close()
+	}
+	f.f.Release()
+	return nil
+}
+
+// pipeSink is a Sink_Server that writes the chunks it receives to a
+// pipe.  Writes block until the reader consumes them, which holds up
+// the stream and thus throttles the remote vat.
+type pipeSink struct {
+	w *io.PipeWriter
+}
+
+func (s pipeSink) Write(ctx context.Context, call Sink_write) error {
+	data, err := call.Args().Data()
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(data)
+	return err
+}
+
+// remoteDir is an fs.ReadDirFile for a remote directory.  Its entries
+// are listed when it is opened.
+type remoteDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	closed  bool
+}
+
+func (d *remoteDir) Stat() (fs.FileInfo, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "stat", Path: d.info.Name(), Err: fs.ErrClosed}
+	}
+	return d.info, nil
+}
+
+func (d *remoteDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: errors.New("is a directory")}
+}
+
+func (d *remoteDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.info.Name(), Err: fs.ErrClosed}
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (d *remoteDir) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.info.Name(), Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,90 @@
+package filesystem_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/std/filesystem"
+)
+
+var testFiles = fstest.MapFS{
+	"hello.txt":        {Data: []byte("hello, world\n"), ModTime: time.Unix(1000, 0)},
+	"assets/big.bin":   {Data: []byte(strings.Repeat("0123456789", 10000)), Mode: 0o600},
+	"assets/style.css": {Data: []byte("body {}\n")},
+	"assets/img/a.png": {Data: []byte("\x89PNG")},
+	"empty/.keep":      {},
+}
+
+// dial serves fsys over a pair of Conns and returns the imported
+// Directory.
+func dial(t *testing.T, fsys fs.FS) filesystem.Directory {
+	server, client := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(filesystem.NewDirectory(fsys)),
+	}, nil)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return filesystem.Directory(client.Bootstrap(context.Background()))
+}
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+
+	d := dial(t, testFiles)
+	defer d.Release()
+	fsys := filesystem.FS(context.Background(), d)
+
+	require.NoError(t, fstest.TestFS(fsys,
+		"hello.txt", "assets/big.bin", "assets/style.css", "assets/img/a.png", "empty/.keep"))
+
+	b, err := fs.ReadFile(fsys, "assets/big.bin")
+	require.NoError(t, err)
+	assert.Equal(t, testFiles["assets/big.bin"].Data, b)
+
+	info, err := fs.Stat(fsys, "hello.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), info.Size())
+	assert.True(t, info.ModTime().Equal(time.Unix(1000, 0)))
+}
+
+func TestFSNotExist(t *testing.T) {
+	t.Parallel()
+
+	d := dial(t, testFiles)
+	defer d.Release()
+	fsys := filesystem.FS(context.Background(), d)
+
+	_, err := fsys.Open("missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "error = %v", err)
+	_, err = fsys.Open("../etc/passwd")
+	assert.True(t, errors.Is(err, fs.ErrInvalid), "error = %v", err)
+}
+
+func TestFSCloseDuringRead(t *testing.T) {
+	t.Parallel()
+
+	d := filesystem.NewDirectory(testFiles)
+	defer d.Release()
+	fsys := filesystem.FS(context.Background(), d)
+
+	f, err := fsys.Open("assets/big.bin")
+	require.NoError(t, err)
+	buf := make([]byte, 10)
+	_, err = f.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(buf))
+	require.NoError(t, f.Close())
+	_, err = f.Read(buf)
+	assert.ErrorIs(t, err, fs.ErrClosed)
+}
@@ -13,37 +13,60 @@ const Tag_ = uint64(0xa574b41924caefc7)
 const Notag_ = uint64(0xc8768679ec52e012)
 const Customtype_ = uint64(0xfa10659ae02f2093)
 const Name_ = uint64(0xc2b96012172f8df1)
-const schema_d12a1c51fedd6c88 = "x\xda\x12\x98\xe8\xc0b\xc8{\x9c\x89\x81)P\x81\x95" +
-	"\xed\xff\xf1\xf7\xa7T$\xb7\x94,e\x08\xe4d\xe5\xf8" +
-	"\xdf\x91s\xf7_\xa0\x8c\xd6E\x06\x06FaO\xc6," +
-	"a_Fv\x06\x86`\x0fFfF\x06\xc6\xff\x0f\xb4" +
-	"+\x95\x05\xeaW\xee\x03)eDQj\xc9\xd8%\xec" +
-	"\x08Vj\x03Q\xfa\xb1W_\\(a\xe7!\x86\x8b" +
-	"\x9c\xac\xff\xf8Q\xd4\xea2\x16\x09\x1b\x82\xd5\xea@\xd4" +
-	"\xc6M\x9e\x1f\xb8\xf7Z\xd7Q\x90\xb1&(Je\x19" +
-	"\xb3\x84\x15\xc1Je J\x85\x1e\x04\xbd\xa9l+;" +
-	"\x81\xe9X^\xc6*aA\xb0R\x1e\x88\xd2\xad.|" +
-	"j\x8c\xdb\x0c\x1eb8V\xf0o\x930#He\xe0" +
-	"\x1f\xb0\xc2\xc9\x0a\xfa\x0ff\xa5\x0a\xfc\x02)T@Q" +
-	"\xf8v\x91\xe0W\x90\xba\x0f\xcc\x8c\x0c<\xff\xd3\xf3\xf5" +
-	"\x92\x13\x0b\xf2\x0a\x18\xf8\xadJ\x12\xd3\x03\x18\x19\x19y" +
-	"\x18\x98\xe0\xa2\x8cV\x05\x89\xc9\xd9\x89\xe9\xa9\x0c\x0c\xe8" +
-	"R\x0c\xf2Vy\x89\xb9\xa9\x18\xc2\xfcV)\xf9\xc9\x18" +
-	"\xa2\xf6Vy\xf9\x10\xf3\x19\x98\x91L\xcf\xcc-\xc8/" +
-	"*a\xc0\xb46\xb9\xb4\xb8$?\xb7\x84\xbd\xb2\x00f" +
-	"\x03 \x00\x00\xff\xff\xb5C\x8e\x03"
+const Min_ = uint64(0xeef9cfe81ddeca5e)
+const Max_ = uint64(0xe1f93203db42ac8b)
+const MaxLen_ = uint64(0x8baa1a3595b98165)
+const Pattern_ = uint64(0xcffe29b68470b6e0)
+const TestVector_ = uint64(0xfc8894fd77b086d5)
+const Http_ = uint64(0x826c92a22f743ec1)
+const schema_d12a1c51fedd6c88 = "x\xdal\xd1?h\x13Q\x1c\x07\xf0\xdf\xef\xa5g\x1a" +
+	"LM\xec\x1b\xa4RiE\x11\xabhT\x14\xca\x81\x7f" +
+	"\x10\xc7\x0e\x9e\x07\x8e\xc5\xe3|\xc4\xc6\xde\x1f\xaeOm" +
+	"\\\xc4 %\xa68h-\x88 B\xd0\xa1\xe2\xe0\xa0" +
+	"\xc1\x0e\x15\x14D)J\xc9\xa0\x0eZ\xb9n\x0eU\xe9" +
+	"\x18isr\xf7@\xbc{]\xbf\xf7\xe1\xfb\xbb//" +
+	"\xdf:\xd1q\xb0\xebK\x07\x10mP\xd9\x10\xbc>\xc6" +
+	"\x0b\xf5;\xa3\x15\xb8\x9dQHP\x1d]lk\xbd{" +
+	"\x9a\x00H5\xe2\x01\xeaC$\x85\x80\x01\xbb>;}" +
+	"d\xeb\x93I\xd02J\x7f\x8c\x1d%\x15@}P\xb0" +
+	"w\xbf\xe7w\xf6<\xe7\x8fC\xd6\x19c\x03\xa4D\xf7" +
+	"\x914\x80\xbe[P\x7foyG\xfe\xda\xcc\xab\x90b" +
+	"\x8c\xf6\x90\x1a\xdd\x1e\xd1^AWn\x15\xb6t\x9f\x9b" +
+	"}\x03\xcd\x8c\xd2\xce\xc5l\x17\xf1\xe8\xe6\xc8f\x85\x1d" +
+	"\x9ez\xa8\xcd}\xae\xbd\x0dk\x0f\xc7\xe8\x1a\x96(F" +
+	"t\x15#\xda\xed\x9fY.O\\~/\xff\xecO\xbc" +
+	"JW0\xa4\xcb\x82\xfa\x0d\xf7Fc\xa0\xbd \xcf_" +
+	"\xc4\x1a\xa0\xfeM\xb0\x17\xa76\xed\xc2\xc6\x81%y\xd3" +
+	"G\xac\xd0f\xd4\xf8A\xd0\xc9\xa7'\xbf\xa6\x0e\xb5\x96" +
+	"\xe4\xc69,\x01\xea/\x05\x1b\x9e\xff\xbe\xed\xc7B\xeb" +
+	"\x97\xccf\"\xf6H\xb0\xa9\xfe\x82\x7f\x9f\xe5\xff\xc8l" +
+	"\x1a\xeb\xf4At\xf8\x9e\xa0\x9f&\x9e]Y\xbb[]" +
+	"\x05-\xf9\xe07\xb1\x0e\xa8WC\x96\x0d\x8a\xce~\xd3" +
+	"pm\x17\xfa\xd4\x0b\x9c\xbb\x98\x05\xf2/C\xd52\xc6" +
+	"\x87\x98\x0d\xd8\xf9_\x0a9\x95\x1b\xc5\xd3\x88\x09\xeb\x1a" +
+	"\xe6E\xa3\xc8\x00\x92\x9f\xa0O\xb5\x0d\x8bIqN=" +
+	"\xef\x98Rz\\\xb5\x1d\xd1\x0f\xa9X;\xe7\xcc\xb3\x01" +
+	"\x12WG,\xd7\xf18\xacSn\x19\xe3\xb81\x11\x8d" +
+	"\xd8\xb1\x08U\xf3\xd2\x18w,\x9e.\xbbL^\xc4\xd9" +
+	"\x18?\xcb\xcc4w<H\xfd\x1d\x00y:\x0ag"
 
 func RegisterSchema(reg *schemas.Registry) {
 	reg.Register(&schemas.Schema{
 		String: schema_d12a1c51fedd6c88,
 		Nodes: []uint64{
+			0x826c92a22f743ec1,
+			0x8baa1a3595b98165,
 			0xa574b41924caefc7,
 			0xbea97f1023792be0,
 			0xc2b96012172f8df1,
 			0xc58ad6bd519f935e,
 			0xc8768679ec52e012,
+			0xcffe29b68470b6e0,
 			0xe130b601260e44b5,
+			0xe1f93203db42ac8b,
+			0xeef9cfe81ddeca5e,
 			0xfa10659ae02f2093,
+			0xfc8894fd77b086d5,
 		},
 		Compressed: true,
 	})
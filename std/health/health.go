@@ -0,0 +1,218 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Server is an implementation of Health whose statuses are set by the
+// application with SetStatus.  The status of the server as a whole,
+// identified by the empty service name, starts as Status_serving; other
+// services are unknown until their status is set.  A Server is safe to
+// use from multiple goroutines.
+type Server struct {
+	mu       sync.Mutex
+	statuses map[string]Status
+	watches  map[*watch]struct{}
+}
+
+// NewServer returns a new Server.
+func NewServer() *Server {
+	return &Server{
+		statuses: map[string]Status{"": Status_serving},
+		watches:  make(map[*watch]struct{}),
+	}
+}
+
+// Client returns a Health client backed by s.  The caller is
+// responsible for calling Release on the returned client.
+func (s *Server) Client() Health {
+	return Health_ServerToClient(s)
+}
+
+// SetStatus sets the status of service and notifies its watchers.
+func (s *Server) SetStatus(service string, status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[service] = status
+	for w := range s.watches {
+		if w.service == service {
+			w.notify(status)
+		}
+	}
+}
+
+// ClearStatus removes the status of service, so that it becomes
+// unknown, and notifies its watchers.
+func (s *Server) ClearStatus(service string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.statuses, service)
+	for w := range s.watches {
+		if w.service == service {
+			w.notify(Status_serviceUnknown)
+		}
+	}
+}
+
+// status returns the status of service.  The caller must hold s.mu.
+func (s *Server) status(service string) Status {
+	if st, ok := s.statuses[service]; ok {
+		return st
+	}
+	return Status_serviceUnknown
+}
+
+// Check implements Health_Server.
+func (s *Server) Check(ctx context.Context, call Health_check) error {
+	service, err := call.Args().Service()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	res.SetStatus(s.status(service))
+	s.mu.Unlock()
+	return nil
+}
+
+// Watch implements Health_Server.
+func (s *Server) Watch(ctx context.Context, call Health_watch) error {
+	service, err := call.Args().Service()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+
+	w := &watch{
+		srv:     s,
+		service: service,
+		watcher: call.Args().Watcher().AddRef(),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.watches[w] = struct{}{}
+	w.notify(s.status(service))
+	s.mu.Unlock()
+	go w.run()
+
+	if err := res.SetHandle(Health_Handle_ServerToClient(w)); err != nil {
+		w.stop()
+		return err
+	}
+	return nil
+}
+
+// A watch streams the status of a service to a watcher.  It is also
+// the server for the watch's Handle: the watch stops when the handle
+// is shut down.
+type watch struct {
+	srv     *Server
+	service string
+	watcher Health_Watcher
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	// latest is protected by srv.mu.
+	latest Status
+}
+
+// notify records status as the latest status and wakes up w.run.
+// The caller must hold w.srv.mu.
+func (w *watch) notify(status Status) {
+	w.latest = status
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watch) run() {
+	defer w.stop()
+	defer w.watcher.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-w.done
+		cancel()
+	}()
+
+	sent, first := Status_unknown, true
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+		w.srv.mu.Lock()
+		status := w.latest
+		w.srv.mu.Unlock()
+		if !first && status == sent {
+			continue
+		}
+		err := w.watcher.Update(ctx, func(p Health_Watcher_update_Params) error {
+			p.SetStatus(status)
+			return nil
+		})
+		if err != nil {
+			return
+		}
+		sent, first = status, false
+	}
+}
+
+// stop stops the watch.  It is safe to call more than once.
+func (w *watch) stop() {
+	w.once.Do(func() {
+		w.srv.mu.Lock()
+		delete(w.srv.watches, w)
+		w.srv.mu.Unlock()
+		close(w.done)
+	})
+}
+
+// Shutdown is called when the watch's Handle is released.
+func (w *watch) Shutdown() {
+	w.stop()
+}
+
+// Check asks h for the status of service.  The empty service name
+// refers to the server as a whole.
+func Check(ctx context.Context, h Health, service string) (Status, error) {
+	ans, release := h.Check(ctx, func(p Health_check_Params) error {
+		return p.SetService(service)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return Status_unknown, err
+	}
+	return res.Status(), nil
+}
+
+// Ready returns nil if h reports that service is serving, or an error
+// otherwise.  Unlike a successful connection, a nil error shows that
+// the remote vat is able to handle requests, which makes Ready suitable
+// for load balancer probes and for verifying a connection after it is
+// established.
+func Ready(ctx context.Context, h Health, service string) error {
+	status, err := Check(ctx, h, service)
+	if err != nil {
+		return err
+	}
+	if status != Status_serving {
+		return fmt.Errorf("health: service %q is %v", service, status)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package health_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/std/health"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv := health.NewServer()
+	h := srv.Client()
+	defer h.Release()
+
+	st, err := health.Check(ctx, h, "")
+	require.NoError(t, err)
+	assert.Equal(t, health.Status_serving, st)
+	st, err = health.Check(ctx, h, "db")
+	require.NoError(t, err)
+	assert.Equal(t, health.Status_serviceUnknown, st)
+	assert.Error(t, health.Ready(ctx, h, "db"))
+
+	srv.SetStatus("db", health.Status_serving)
+	assert.NoError(t, health.Ready(ctx, h, "db"))
+	srv.SetStatus("db", health.Status_notServing)
+	assert.ErrorContains(t, health.Ready(ctx, h, "db"), "notServing")
+	srv.ClearStatus("db")
+	st, err = health.Check(ctx, h, "db")
+	require.NoError(t, err)
+	assert.Equal(t, health.Status_serviceUnknown, st)
+}
+
+func TestCheckOverConn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv := health.NewServer()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(srv.Client()),
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	h := health.Health(clientConn.Bootstrap(ctx))
+	defer h.Release()
+	assert.NoError(t, health.Ready(ctx, h, ""))
+	srv.SetStatus("", health.Status_notServing)
+	assert.Error(t, health.Ready(ctx, h, ""))
+}
+
+type watcher chan health.Status
+
+func (w watcher) Update(ctx context.Context, call health.Health_Watcher_update) error {
+	w <- call.Args().Status()
+	return nil
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv := health.NewServer()
+	h := srv.Client()
+	defer h.Release()
+
+	updates := make(watcher, 10)
+	ans, release := h.Watch(ctx, func(p health.Health_watch_Params) error {
+		if err := p.SetService("db"); err != nil {
+			return err
+		}
+		return p.SetWatcher(health.Health_Watcher_ServerToClient(updates))
+	})
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+
+	recv := func() health.Status {
+		select {
+		case st := <-updates:
+			return st
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for update")
+			return 0
+		}
+	}
+	assert.Equal(t, health.Status_serviceUnknown, recv(), "current status should be sent first")
+	srv.SetStatus("db", health.Status_serving)
+	assert.Equal(t, health.Status_serving, recv())
+	srv.SetStatus("other", health.Status_notServing)
+	srv.SetStatus("db", health.Status_notServing)
+	assert.Equal(t, health.Status_notServing, recv())
+
+	// Releasing the handle stops the updates.
+	res.Handle().Release()
+	release()
+	time.Sleep(10 * time.Millisecond)
+	srv.SetStatus("db", health.Status_serving)
+	select {
+	case st := <-updates:
+		t.Errorf("got update %v after handle was released", st)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,246 @@
+// Package pubsub implements the publish/subscribe pattern on top of
+// the interfaces in pubsub.capnp.
+//
+// A Topic fans out published messages to its subscribers.  Each
+// subscriber gets its own queue and its own flow control window, so a
+// slow subscriber does not hold up the others; one that falls too far
+// behind, or whose push calls fail, is dropped and released instead of
+// piling up messages.  Subscribers are also dropped as soon as they
+// release their Subscription, so a topic holds no references to
+// subscribers that went away.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/flowcontrol"
+)
+
+// ErrClosed is the cause of the error returned when subscribing to or
+// publishing on a closed Topic.
+var ErrClosed = errors.New("topic closed")
+
+// Options configures a Topic.
+type Options struct {
+	// Buffer is the number of messages that may be queued for a
+	// subscriber.  A subscriber whose queue is full when a message is
+	// published is dropped.  If zero, 64 is used.
+	Buffer int
+
+	// Window is the number of push calls that may be in flight to a
+	// subscriber at once.  Messages wait in the subscriber's queue
+	// while its window is full.  If zero, 8 is used.
+	Window int64
+}
+
+// A Topic delivers published messages to its subscribers.  It
+// implements Publisher_Server.  A Topic is safe to use from multiple
+// goroutines.
+type Topic struct {
+	buffer int
+	window int64
+
+	mu     sync.Mutex
+	subs   map[*subscription]struct{}
+	closed bool
+}
+
+// NewTopic returns a new Topic.  If opts is nil, the defaults are used.
+func NewTopic(opts *Options) *Topic {
+	t := &Topic{
+		buffer: 64,
+		window: 8,
+		subs:   make(map[*subscription]struct{}),
+	}
+	if opts != nil && opts.Buffer > 0 {
+		t.buffer = opts.Buffer
+	}
+	if opts != nil && opts.Window > 0 {
+		t.window = opts.Window
+	}
+	return t
+}
+
+// Publisher returns a Publisher client backed by t.  The caller is
+// responsible for calling Release on the returned client.
+func (t *Topic) Publisher() Publisher {
+	return Publisher_ServerToClient(t)
+}
+
+// Len returns the number of subscribers.
+func (t *Topic) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.subs)
+}
+
+// Publish queues a copy of payload for delivery to every subscriber.
+// It does not wait for delivery.  Subscribers whose queue is full are
+// dropped.
+func (t *Topic) Publish(payload capnp.Ptr) error {
+	msg, _ := capnp.NewSingleSegmentMessage(nil)
+	if err := msg.SetRoot(payload); err != nil {
+		return exc.WrapError("publish", err)
+	}
+	b, err := msg.Marshal()
+	if err != nil {
+		return exc.WrapError("publish", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return exc.WrapError("publish", ErrClosed)
+	}
+	for s := range t.subs {
+		select {
+		case s.queue <- b:
+		default:
+			s.stopLocked()
+		}
+	}
+	return nil
+}
+
+// Close drops all subscribers.  Later calls to Publish and subscribe
+// fail with ErrClosed.
+func (t *Topic) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for s := range t.subs {
+		s.stopLocked()
+	}
+}
+
+// Subscribe implements Publisher_Server.
+func (t *Topic) Subscribe(ctx context.Context, call Publisher_subscribe) error {
+	sub := call.Args().Subscriber()
+	if !sub.IsValid() {
+		return errors.New("subscribe: null subscriber")
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+
+	s := &subscription{
+		topic: t,
+		sub:   sub.AddRef(),
+		queue: make(chan []byte, t.buffer),
+		done:  make(chan struct{}),
+	}
+	s.sub.SetFlowLimiter(flowcontrol.NewMaxInflightLimiter(t.window))
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		s.sub.Release()
+		return exc.WrapError("subscribe", ErrClosed)
+	}
+	t.subs[s] = struct{}{}
+	t.mu.Unlock()
+	go s.run()
+
+	return res.SetSubscription(Subscription_ServerToClient(s))
+}
+
+// A subscription delivers a Topic's messages to one subscriber.  It is
+// also the server for the subscriber's Subscription: the subscriber is
+// dropped when the Subscription is shut down.
+type subscription struct {
+	topic *Topic
+	sub   Subscriber
+	queue chan []byte   // marshaled messages
+	done  chan struct{} // closed once dropped
+}
+
+func (s *subscription) run() {
+	defer s.sub.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.done
+		cancel()
+	}()
+
+	for {
+		var b []byte
+		select {
+		case b = <-s.queue:
+		case <-s.done:
+			return
+		}
+		msg, err := capnp.Unmarshal(b)
+		if err != nil {
+			s.stop()
+			return
+		}
+		payload, err := msg.Root()
+		if err != nil {
+			s.stop()
+			return
+		}
+		err = s.sub.Push(ctx, func(p Subscriber_push_Params) error {
+			return p.SetPayload(payload)
+		})
+		if err != nil {
+			s.stop()
+			return
+		}
+	}
+}
+
+// stop drops s from its topic.  It is safe to call more than once.
+func (s *subscription) stop() {
+	s.topic.mu.Lock()
+	defer s.topic.mu.Unlock()
+	s.stopLocked()
+}
+
+// stopLocked is like stop, but the caller must hold s.topic.mu.
+func (s *subscription) stopLocked() {
+	if _, ok := s.topic.subs[s]; !ok {
+		return
+	}
+	delete(s.topic.subs, s)
+	close(s.done)
+}
+
+// Shutdown is called when the Subscription is released.
+func (s *subscription) Shutdown() {
+	s.stop()
+}
+
+// A SubscriberFunc is a Subscriber_Server that calls the function for
+// each pushed message.  The payload is only valid until the function
+// returns.  Returning an error unsubscribes.
+type SubscriberFunc func(ctx context.Context, payload capnp.Ptr) error
+
+// Push implements Subscriber_Server.
+func (f SubscriberFunc) Push(ctx context.Context, call Subscriber_push) error {
+	payload, err := call.Args().Payload()
+	if err != nil {
+		return err
+	}
+	return f(ctx, payload)
+}
+
+// Subscribe subscribes f to p, and returns the Subscription.  The
+// caller is responsible for releasing the Subscription, which
+// unsubscribes.
+func Subscribe(ctx context.Context, p Publisher, f SubscriberFunc) (Subscription, error) {
+	ans, release := p.Subscribe(ctx, func(params Publisher_subscribe_Params) error {
+		return params.SetSubscriber(Subscriber_ServerToClient(f))
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return Subscription{}, err
+	}
+	return res.Subscription().AddRef(), nil
+}
@@ -0,0 +1,118 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/std/pubsub"
+)
+
+func publish(t *testing.T, topic *pubsub.Topic, s string) {
+	_, seg := capnp.NewSingleSegmentMessage(nil)
+	text, err := capnp.NewText(seg, s)
+	require.NoError(t, err)
+	require.NoError(t, topic.Publish(text.ToPtr()))
+}
+
+// collect returns a SubscriberFunc that sends each payload's text to
+// out.
+func collect(out chan<- string) pubsub.SubscriberFunc {
+	return func(ctx context.Context, payload capnp.Ptr) error {
+		select {
+		case out <- payload.Text():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestTopic(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	topic := pubsub.NewTopic(nil)
+	defer topic.Close()
+	pub := topic.Publisher()
+	defer pub.Release()
+
+	out1, out2 := make(chan string, 10), make(chan string, 10)
+	sub1, err := pubsub.Subscribe(ctx, pub, collect(out1))
+	require.NoError(t, err)
+	defer sub1.Release()
+	sub2, err := pubsub.Subscribe(ctx, pub, collect(out2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, topic.Len())
+
+	publish(t, topic, "foo")
+	publish(t, topic, "bar")
+	for _, out := range []chan string{out1, out2} {
+		assert.Equal(t, "foo", <-out)
+		assert.Equal(t, "bar", <-out)
+	}
+
+	sub2.Release()
+	assert.Eventually(t, func() bool { return topic.Len() == 1 }, 5*time.Second, time.Millisecond,
+		"releasing the subscription should unsubscribe")
+	publish(t, topic, "baz")
+	assert.Equal(t, "baz", <-out1)
+}
+
+func TestTopicDropsSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	topic := pubsub.NewTopic(&pubsub.Options{Buffer: 1, Window: 1})
+	defer topic.Close()
+	pub := topic.Publisher()
+	defer pub.Release()
+
+	slow := make(chan string) // never read
+	sub, err := pubsub.Subscribe(ctx, pub, collect(slow))
+	require.NoError(t, err)
+	defer sub.Release()
+	fast := make(chan string, 10)
+	sub2, err := pubsub.Subscribe(ctx, pub, collect(fast))
+	require.NoError(t, err)
+	defer sub2.Release()
+
+	for _, s := range []string{"a", "b", "c", "d"} {
+		publish(t, topic, s)
+		assert.Equal(t, s, <-fast, "fast subscriber should not be held up")
+	}
+	assert.Equal(t, 1, topic.Len(), "slow subscriber should be dropped")
+}
+
+func TestTopicOverConn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	topic := pubsub.NewTopic(nil)
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(topic.Publisher()),
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	pub := pubsub.Publisher(clientConn.Bootstrap(ctx))
+	defer pub.Release()
+	out := make(chan string, 10)
+	sub, err := pubsub.Subscribe(ctx, pub, collect(out))
+	require.NoError(t, err)
+	defer sub.Release()
+
+	publish(t, topic, "foo")
+	assert.Equal(t, "foo", <-out)
+
+	topic.Close()
+	assert.Equal(t, 0, topic.Len())
+	assert.ErrorIs(t, topic.Publish(capnp.Ptr{}), pubsub.ErrClosed)
+	_, err = pubsub.Subscribe(ctx, pub, collect(out))
+	assert.ErrorContains(t, err, pubsub.ErrClosed.Error())
+}
@@ -0,0 +1,245 @@
+package reflection
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/schemas"
+)
+
+// Server is an implementation of Reflection that serves schemas from a
+// schemas.Registry.  A Server is safe to use from multiple goroutines,
+// as long as no schemas are registered concurrently.
+type Server struct {
+	reg    *schemas.Registry
+	ifaces []uint64
+}
+
+// NewServer returns a Server that reports ifaces as the interfaces
+// served by the vat, and serves schemas from reg.  If reg is nil,
+// schemas.DefaultRegistry is used.  The IDs of generated interfaces are
+// available as the TypeID constants of the generated code.
+func NewServer(reg *schemas.Registry, ifaces ...uint64) *Server {
+	if reg == nil {
+		reg = schemas.DefaultRegistry
+	}
+	return &Server{
+		reg:    reg,
+		ifaces: append([]uint64(nil), ifaces...),
+	}
+}
+
+// Client returns a Reflection client backed by s.  The caller is
+// responsible for calling Release on the returned client.
+func (s *Server) Client() Reflection {
+	return Reflection_ServerToClient(s)
+}
+
+// ListInterfaces implements Reflection_Server.
+func (s *Server) ListInterfaces(ctx context.Context, call Reflection_listInterfaces) error {
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	ids, err := res.NewInterfaceIds(int32(len(s.ifaces)))
+	if err != nil {
+		return err
+	}
+	for i, id := range s.ifaces {
+		ids.Set(i, id)
+	}
+	return nil
+}
+
+// GetSchema implements Reflection_Server.
+func (s *Server) GetSchema(ctx context.Context, call Reflection_getSchema) error {
+	data, err := s.reg.Find(call.Args().Id())
+	if err != nil {
+		return exc.WrapError("reflection", err)
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetSchema(data)
+}
+
+// GetFingerprints implements Reflection_Server.  It reports the
+// fingerprints in the process-wide method registry; see
+// capnp.RegisterFingerprint.
+func (s *Server) GetFingerprints(ctx context.Context, call Reflection_getFingerprints) error {
+	ids, err := call.Args().Ids()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	fps, err := res.NewFingerprints(int32(ids.Len()))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < ids.Len(); i++ {
+		fp, _ := capnp.LookupFingerprint(ids.At(i))
+		fps.Set(i, fp)
+	}
+	return nil
+}
+
+// A FingerprintMismatch reports an interface whose schema fingerprint
+// differs between two vats.
+type FingerprintMismatch struct {
+	InterfaceID uint64
+	Local       uint64
+	Remote      uint64 // zero if the remote vat does not know the interface
+}
+
+// A MismatchError is returned by CheckFingerprints when the remote vat
+// was built from a different version of the schema of some interfaces.
+type MismatchError struct {
+	Mismatches []FingerprintMismatch
+}
+
+func (e *MismatchError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("reflection: schema mismatch with remote vat for ")
+	for i, m := range e.Mismatches {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(interfaceName(m.InterfaceID))
+		if m.Remote == 0 {
+			sb.WriteString(" (unknown to remote)")
+		}
+	}
+	return sb.String()
+}
+
+// interfaceName returns the name of an interface, if its methods are
+// registered, or its ID.
+func interfaceName(id uint64) string {
+	if ms := capnp.InterfaceMethods(id); len(ms) > 0 {
+		return ms[0].InterfaceName
+	}
+	return "@0x" + strconv.FormatUint(id, 16)
+}
+
+// CheckFingerprints asks r for the schema fingerprints of the interfaces
+// with the given IDs, and compares them with the ones registered in this
+// process.  If any differ, it returns a *MismatchError.  Interfaces with
+// no fingerprint registered locally are not checked.
+//
+// Calling CheckFingerprints once a connection is set up catches skew
+// between independently deployed services before any other call is
+// made.  The remote vat must serve Reflection, for example with Attach:
+//
+//	boot := conn.Bootstrap(ctx)
+//	err := reflection.CheckFingerprints(ctx, reflection.Reflection(boot.AddRef()), foo.Foo_TypeID)
+//
+// CheckFingerprints takes ownership of r.
+func CheckFingerprints(ctx context.Context, r Reflection, ids ...uint64) error {
+	defer r.Release()
+	type local struct{ id, fp uint64 }
+	var want []local
+	for _, id := range ids {
+		if fp, ok := capnp.LookupFingerprint(id); ok {
+			want = append(want, local{id, fp})
+		}
+	}
+	if len(want) == 0 {
+		return nil
+	}
+
+	ans, release := r.GetFingerprints(ctx, func(p Reflection_getFingerprints_Params) error {
+		l, err := p.NewIds(int32(len(want)))
+		if err != nil {
+			return err
+		}
+		for i, w := range want {
+			l.Set(i, w.id)
+		}
+		return nil
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return err
+	}
+	fps, err := res.Fingerprints()
+	if err != nil {
+		return err
+	}
+	var mismatches []FingerprintMismatch
+	for i, w := range want {
+		var remote uint64
+		if i < fps.Len() {
+			remote = fps.At(i)
+		}
+		if remote != w.fp {
+			mismatches = append(mismatches, FingerprintMismatch{
+				InterfaceID: w.id,
+				Local:       w.fp,
+				Remote:      remote,
+			})
+		}
+	}
+	if len(mismatches) > 0 {
+		return &MismatchError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// Attach returns a client that serves the Reflection interface with
+// r and forwards calls to any other interface to c.  This allows a vat
+// to offer reflection on its bootstrap capability:
+//
+//	opts := &rpc.Options{
+//		BootstrapClient: reflection.Attach(boot, reflection.NewServer(nil, Foo_TypeID)),
+//	}
+//
+// Attach takes ownership of c.
+func Attach(c capnp.Client, r *Server) capnp.Client {
+	return capnp.NewClient(&attached{
+		target: c,
+		refl:   capnp.Client(r.Client()),
+	})
+}
+
+// attached is the capnp.ClientHook behind a client returned by Attach.
+type attached struct {
+	target capnp.Client
+	refl   capnp.Client
+}
+
+// route returns the client that handles calls to m.
+func (a *attached) route(m capnp.Method) capnp.Client {
+	if m.InterfaceID == Reflection_TypeID {
+		return a.refl
+	}
+	return a.target
+}
+
+func (a *attached) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	return a.route(s.Method).SendCall(ctx, s)
+}
+
+func (a *attached) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	return a.route(r.Method).RecvCall(ctx, r)
+}
+
+func (a *attached) Brand() capnp.Brand {
+	return capnp.Brand{Value: a}
+}
+
+func (a *attached) Shutdown() {
+	a.target.Release()
+	a.refl.Release()
+}
+
+func (a *attached) String() string {
+	return "reflection.Attach(" + a.target.String() + ")"
+}
@@ -0,0 +1,169 @@
+package reflection_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/schemas"
+	"capnproto.org/go/capnp/v3/std/capnp/schema"
+	"capnproto.org/go/capnp/v3/std/reflection"
+)
+
+type echoImpl struct{}
+
+func (echoImpl) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in + in)
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	reg := new(schemas.Registry)
+	air.RegisterSchema(reg)
+
+	boot := reflection.Attach(
+		capnp.Client(air.Echo_ServerToClient(echoImpl{})),
+		reflection.NewServer(reg, air.Echo_TypeID),
+	)
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: boot,
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	client := clientConn.Bootstrap(ctx)
+	defer client.Release()
+
+	// Calls to the bootstrap interface are forwarded.
+	echo := air.Echo(client)
+	ans, release := echo.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn("foo")
+	})
+	defer release()
+	res, err := ans.Struct()
+	require.NoError(t, err)
+	out, err := res.Out()
+	require.NoError(t, err)
+	assert.Equal(t, "foofoo", out)
+
+	refl := reflection.Reflection(client)
+	lans, release := refl.ListInterfaces(ctx, nil)
+	defer release()
+	lres, err := lans.Struct()
+	require.NoError(t, err)
+	ids, err := lres.InterfaceIds()
+	require.NoError(t, err)
+	require.Equal(t, 1, ids.Len())
+	assert.Equal(t, uint64(air.Echo_TypeID), ids.At(0))
+
+	sans, release := refl.GetSchema(ctx, func(p reflection.Reflection_getSchema_Params) error {
+		p.SetId(air.Echo_TypeID)
+		return nil
+	})
+	defer release()
+	sres, err := sans.Struct()
+	require.NoError(t, err)
+	data, err := sres.Schema()
+	require.NoError(t, err)
+	msg, err := capnp.Unmarshal(data)
+	require.NoError(t, err)
+	req, err := schema.ReadRootCodeGeneratorRequest(msg)
+	require.NoError(t, err)
+	nodes, err := req.Nodes()
+	require.NoError(t, err)
+	found := false
+	for i := 0; i < nodes.Len(); i++ {
+		if nodes.At(i).Id() == air.Echo_TypeID {
+			found = true
+			assert.Equal(t, schema.Node_Which_interface, nodes.At(i).Which())
+		}
+	}
+	assert.True(t, found, "schema should contain the Echo interface")
+
+	uans, release := refl.GetSchema(ctx, func(p reflection.Reflection_getSchema_Params) error {
+		p.SetId(0x1234)
+		return nil
+	})
+	defer release()
+	_, err = uans.Struct()
+	assert.Error(t, err, "unknown schema should fail")
+}
+
+func TestCheckFingerprints(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	boot := reflection.Attach(
+		capnp.Client(air.Echo_ServerToClient(echoImpl{})),
+		reflection.NewServer(nil, air.Echo_TypeID),
+	)
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: boot,
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	client := clientConn.Bootstrap(ctx)
+	defer client.Release()
+
+	err := reflection.CheckFingerprints(ctx, reflection.Reflection(client.AddRef()),
+		air.Echo_TypeID, air.CallSequence_TypeID)
+	assert.NoError(t, err, "both vats use the same schema")
+
+	err = reflection.CheckFingerprints(ctx, reflection.Reflection(client.AddRef()), 0x1234)
+	assert.NoError(t, err, "interfaces without a local fingerprint are not checked")
+
+	// Both vats share this process's registry, so use a server that
+	// reports other fingerprints.
+	stale := reflection.Reflection_ServerToClient(staleReflection{})
+	err = reflection.CheckFingerprints(ctx, stale, air.Echo_TypeID, air.CallSequence_TypeID)
+	var merr *reflection.MismatchError
+	require.ErrorAs(t, err, &merr)
+	assert.Equal(t, []reflection.FingerprintMismatch{
+		{InterfaceID: air.Echo_TypeID, Local: air.Echo_Fingerprint, Remote: 1},
+		{InterfaceID: air.CallSequence_TypeID, Local: air.CallSequence_Fingerprint, Remote: 0},
+	}, merr.Mismatches)
+	assert.Contains(t, err.Error(), "aircraft.capnp:Echo, aircraft.capnp:CallSequence (unknown to remote)")
+}
+
+// staleReflection reports a fingerprint of 1 for Echo and knows no other
+// interface.
+type staleReflection struct {
+	reflection.Reflection_UnimplementedServer
+}
+
+func (staleReflection) GetFingerprints(ctx context.Context, call reflection.Reflection_getFingerprints) error {
+	ids, err := call.Args().Ids()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	fps, err := res.NewFingerprints(int32(ids.Len()))
+	if err != nil {
+		return err
+	}
+	for i := 0; i < ids.Len(); i++ {
+		if ids.At(i) == air.Echo_TypeID {
+			fps.Set(i, 1)
+		}
+	}
+	return nil
+}
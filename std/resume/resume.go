@@ -0,0 +1,369 @@
+// Package resume lets clients resume sessions after reconnecting.
+//
+// A server binds capabilities that a client has set up into a Session
+// with Store.NewSession, and hands the Session to the client.  The
+// client saves the Session to get a resumption token.  After losing
+// the connection, the client presents the token to the server's
+// Resumer to get equivalent capabilities back.
+package resume
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exc"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/server"
+	"capnproto.org/go/capnp/v3/std/capnp/persistent"
+)
+
+// ErrUnknownToken is the cause of the error returned when resuming a
+// session with a token that is unknown, revoked or expired.
+var ErrUnknownToken = errors.New("unknown or expired resumption token")
+
+// tokenSize is the size of a resumption token, in bytes.
+const tokenSize = 16
+
+// Options configures a Store.
+type Options struct {
+	// TTL is how long a session may go without being saved or resumed
+	// before it expires.  If zero, sessions expire only when revoked.
+	TTL time.Duration
+
+	// Clock is used to expire sessions.  If nil, clock.System is used.
+	Clock clock.Clock
+}
+
+// A Store holds the sessions issued by a server, indexed by their
+// resumption tokens.  A Store is safe to use from multiple goroutines.
+type Store struct {
+	opts Options
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// NewStore returns an empty Store.  If opts is nil, the defaults are
+// used.
+func NewStore(opts *Options) *Store {
+	s := &Store{sessions: make(map[string]*session)}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Clock == nil {
+		s.opts.Clock = clock.System
+	}
+	return s
+}
+
+// A session is a set of named capabilities bound to a resumption token.
+// The capabilities are owned by the Store, not by the Session clients,
+// so that they outlive the connections the clients were exported on.
+type session struct {
+	store *Store
+	token string
+
+	// lastUsed is protected by store.mu.
+	lastUsed time.Time
+
+	mu       sync.Mutex
+	exports  map[string]capnp.Client
+	released bool
+}
+
+// NewSession binds exports into a new session, and returns a Session
+// client for it.  NewSession takes ownership of the clients in exports,
+// which are released when the session is revoked or expires.  The
+// caller is responsible for calling Release on the returned client.
+func (s *Store) NewSession(exports map[string]capnp.Client) (Session, error) {
+	var tok [tokenSize]byte
+	if _, err := rand.Read(tok[:]); err != nil {
+		for _, c := range exports {
+			c.Release()
+		}
+		return Session{}, exc.WrapError("resume: new token", err)
+	}
+	sess := &session{
+		store:   s,
+		token:   string(tok[:]),
+		exports: make(map[string]capnp.Client, len(exports)),
+	}
+	for name, c := range exports {
+		sess.exports[name] = c
+	}
+
+	s.mu.Lock()
+	sess.lastUsed = s.opts.Clock.Now()
+	s.sessions[sess.token] = sess
+	expired := s.expire()
+	s.mu.Unlock()
+
+	release(expired)
+	return sess.client(), nil
+}
+
+// Revoke revokes the session that token was issued for, releasing its
+// capabilities.  Capabilities that clients already got from the session
+// keep working, but the session can no longer be saved or resumed.  Revoke reports whether token was known.
+func (s *Store) Revoke(token []byte) bool {
+	s.mu.Lock()
+	sess, ok := s.sessions[string(token)]
+	delete(s.sessions, string(token))
+	s.mu.Unlock()
+
+	if ok {
+		release([]*session{sess})
+	}
+	return ok
+}
+
+// Len returns the number of sessions in the store, including ones that
+// have expired but not yet been released.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// Close revokes all sessions.
+func (s *Store) Close() {
+	s.mu.Lock()
+	var all []*session
+	for _, sess := range s.sessions {
+		all = append(all, sess)
+	}
+	s.sessions = make(map[string]*session)
+	s.mu.Unlock()
+
+	release(all)
+}
+
+// Resumer returns a Resumer client backed by s, suitable for exporting
+// from a vat's bootstrap capability.  The caller is responsible for
+// calling Release on the returned client.
+func (s *Store) Resumer() Resumer {
+	return Resumer_ServerToClient(resumer{s})
+}
+
+// lookup returns the session that token was issued for, and marks it
+// as used.
+func (s *Store) lookup(token []byte) (*session, error) {
+	s.mu.Lock()
+	expired := s.expire()
+	sess, ok := s.sessions[string(token)]
+	if ok {
+		sess.lastUsed = s.opts.Clock.Now()
+	}
+	s.mu.Unlock()
+
+	release(expired)
+	if !ok {
+		return nil, exc.WrapError("resume", ErrUnknownToken)
+	}
+	return sess, nil
+}
+
+// touch marks sess as used, unless it has been revoked or has expired.
+func (s *Store) touch(sess *session) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions[sess.token] != sess {
+		return false
+	}
+	sess.lastUsed = s.opts.Clock.Now()
+	return true
+}
+
+// expire removes expired sessions from s and returns them, so that the
+// caller can release them after unlocking.  The caller must hold s.mu.
+func (s *Store) expire() []*session {
+	if s.opts.TTL <= 0 {
+		return nil
+	}
+	now := s.opts.Clock.Now()
+	var expired []*session
+	for tok, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) >= s.opts.TTL {
+			delete(s.sessions, tok)
+			expired = append(expired, sess)
+		}
+	}
+	return expired
+}
+
+// release releases the capabilities of sessions that have been removed
+// from their Store.
+func release(sessions []*session) {
+	for _, sess := range sessions {
+		sess.mu.Lock()
+		exports := sess.exports
+		sess.exports = nil
+		sess.released = true
+		sess.mu.Unlock()
+
+		for _, c := range exports {
+			c.Release()
+		}
+	}
+}
+
+// addRefs returns new references to the capabilities of sess, or false
+// if they have been released.
+func (sess *session) addRefs() (map[string]capnp.Client, bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.released {
+		return nil, false
+	}
+	caps := make(map[string]capnp.Client, len(sess.exports))
+	for name, c := range sess.exports {
+		caps[name] = c.AddRef()
+	}
+	return caps, true
+}
+
+// client returns a new Session client for sess, which also implements
+// persistent.Persistent.
+func (sess *session) client() Session {
+	srv := sessionServer{sess}
+	methods := Session_Methods(nil, srv)
+	methods = persistent.Persistent_Methods(methods, srv)
+	return Session(capnp.NewClient(server.New(methods, srv, nil)))
+}
+
+// sessionServer serves Session and Persistent for a session.
+type sessionServer struct {
+	sess *session
+}
+
+// Exports implements Session_Server.
+func (ss sessionServer) Exports(ctx context.Context, call Session_exports) error {
+	caps, ok := ss.sess.addRefs()
+	if !ok {
+		return exc.WrapError("resume", ErrUnknownToken)
+	}
+	defer func() {
+		for _, c := range caps {
+			c.Release()
+		}
+	}()
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	l, err := res.NewExports(int32(len(caps)))
+	if err != nil {
+		return err
+	}
+	i := 0
+	for name, c := range caps {
+		e := l.At(i)
+		if err := e.SetName(name); err != nil {
+			return err
+		}
+		if err := e.SetCap(c.AddRef()); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
+
+// Save implements persistent.Persistent_Server.  The SturdyRef is the
+// session's resumption token, as Data.
+func (ss sessionServer) Save(ctx context.Context, call persistent.Persistent_save) error {
+	if !ss.sess.store.touch(ss.sess) {
+		return exc.WrapError("resume", ErrUnknownToken)
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	ref, err := capnp.NewData(res.Segment(), []byte(ss.sess.token))
+	if err != nil {
+		return err
+	}
+	return res.SetSturdyRef(ref.ToPtr())
+}
+
+// resumer serves Resumer for a Store.
+type resumer struct {
+	store *Store
+}
+
+// Resume implements Resumer_Server.
+func (r resumer) Resume(ctx context.Context, call Resumer_resume) error {
+	token, err := call.Args().Token()
+	if err != nil {
+		return err
+	}
+	sess, err := r.store.lookup(token)
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetSession(sess.client())
+}
+
+// Save returns the resumption token of sess.
+func Save(ctx context.Context, sess Session) ([]byte, error) {
+	p := persistent.Persistent(sess)
+	ans, release := p.Save(ctx, nil)
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return nil, err
+	}
+	ref, err := res.SturdyRef()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), ref.Data()...), nil
+}
+
+// Exports returns the capabilities bound into sess, by name.  The
+// caller is responsible for releasing the returned clients.
+func Exports(ctx context.Context, sess Session) (map[string]capnp.Client, error) {
+	ans, release := sess.Exports(ctx, nil)
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return nil, err
+	}
+	l, err := res.Exports()
+	if err != nil {
+		return nil, err
+	}
+	caps := make(map[string]capnp.Client, l.Len())
+	for i := 0; i < l.Len(); i++ {
+		e := l.At(i)
+		name, err := e.Name()
+		if err != nil {
+			for _, c := range caps {
+				c.Release()
+			}
+			return nil, err
+		}
+		caps[name] = e.Cap().AddRef()
+	}
+	return caps, nil
+}
+
+// Resume presents token to r and returns the capabilities of the
+// resumed session, by name.  The caller is responsible for releasing
+// the returned clients.
+func Resume(ctx context.Context, r Resumer, token []byte) (map[string]capnp.Client, error) {
+	ans, release := r.Resume(ctx, func(p Resumer_resume_Params) error {
+		return p.SetToken(token)
+	})
+	defer release()
+	sess := ans.Session()
+	return Exports(ctx, sess)
+}
@@ -0,0 +1,116 @@
+package resume_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	"capnproto.org/go/capnp/v3/exp/clock"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/server"
+	"capnproto.org/go/capnp/v3/std/health"
+	"capnproto.org/go/capnp/v3/std/resume"
+)
+
+// connect returns a Resumer bootstrapped over a new connection to store.
+func connect(t *testing.T, store *resume.Store) (resume.Resumer, func()) {
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(store.Resumer()),
+	}, nil)
+	r := resume.Resumer(clientConn.Bootstrap(context.Background()))
+	return r, func() {
+		r.Release()
+		assert.NoError(t, clientConn.Close())
+		<-serverConn.Done()
+	}
+}
+
+func TestResume(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	store := resume.NewStore(nil)
+	defer store.Close()
+	hs := health.NewServer()
+	hs.SetStatus("db", health.Status_notServing)
+
+	// The server sets up a session; the client saves it.
+	sess, err := store.NewSession(map[string]capnp.Client{
+		"health": capnp.Client(hs.Client()),
+	})
+	require.NoError(t, err)
+	token, err := resume.Save(ctx, sess)
+	sess.Release()
+	require.NoError(t, err)
+	assert.Len(t, token, 16)
+
+	// After reconnecting, the client gets the same capabilities back.
+	for i := 0; i < 2; i++ {
+		r, disconnect := connect(t, store)
+		caps, err := resume.Resume(ctx, r, token)
+		require.NoError(t, err)
+		require.Contains(t, caps, "health")
+		st, err := health.Check(ctx, health.Health(caps["health"]), "db")
+		assert.NoError(t, err)
+		assert.Equal(t, health.Status_notServing, st)
+		for _, c := range caps {
+			c.Release()
+		}
+		disconnect()
+	}
+
+	r, disconnect := connect(t, store)
+	defer disconnect()
+	_, err = resume.Resume(ctx, r, []byte("bogus"))
+	assert.ErrorContains(t, err, resume.ErrUnknownToken.Error())
+
+	assert.True(t, store.Revoke(token))
+	assert.False(t, store.Revoke(token))
+	_, err = resume.Resume(ctx, r, token)
+	assert.ErrorContains(t, err, resume.ErrUnknownToken.Error())
+}
+
+// shutdownRecorder is a capability that records when it is shut down.
+type shutdownRecorder chan struct{}
+
+func (s shutdownRecorder) Shutdown() { close(s) }
+
+func TestResumeExpiry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	clk := clock.NewManual(time.Unix(0, 0))
+	store := resume.NewStore(&resume.Options{TTL: time.Minute, Clock: clk})
+	defer store.Close()
+	shut := make(shutdownRecorder)
+	sess, err := store.NewSession(map[string]capnp.Client{
+		"c": capnp.NewClient(server.New(nil, nil, shut)),
+	})
+	require.NoError(t, err)
+	defer sess.Release()
+	token, err := resume.Save(ctx, sess)
+	require.NoError(t, err)
+
+	// Resuming keeps the session alive.
+	r := store.Resumer()
+	defer r.Release()
+	clk.Advance(50 * time.Second)
+	caps, err := resume.Resume(ctx, r, token)
+	require.NoError(t, err)
+	caps["c"].Release()
+	clk.Advance(50 * time.Second)
+	_, err = resume.Save(ctx, sess)
+	require.NoError(t, err)
+
+	clk.Advance(time.Minute)
+	_, err = resume.Resume(ctx, r, token)
+	assert.ErrorContains(t, err, resume.ErrUnknownToken.Error())
+	<-shut
+	assert.Equal(t, 0, store.Len())
+	_, err = resume.Save(ctx, sess)
+	assert.Error(t, err, "expired sessions cannot be saved")
+}
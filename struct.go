@@ -83,6 +83,15 @@ func (p Struct) IsValid() bool {
 	return p.seg != nil
 }
 
+// WithDepthLimit returns p with its depth limit set to limit: reading
+// pointers from the result fails once limit levels of pointers have been
+// followed.  This bounds the work of traversing a struct that was read
+// with a looser limit, such as one received from an untrusted source.
+func (p Struct) WithDepthLimit(limit uint) Struct {
+	p.depthLimit = limit
+	return p
+}
+
 // Size returns the size of the struct.
 func (p Struct) Size() ObjectSize {
 	return p.size
@@ -99,6 +108,19 @@ func (p Struct) CopyFrom(other Struct) error {
 	return nil
 }
 
+// DataSection returns the raw bytes of the struct's data section.
+// The returned slice aliases the message's segment: it must not be
+// modified, and it is only valid for as long as the message is.
+// Its length may be shorter than the data section declared by the
+// schema if the struct was written with an older version of the schema.
+func (p Struct) DataSection() []byte {
+	if p.seg == nil {
+		return nil
+	}
+	end := p.off.addSizeUnchecked(p.size.DataSize)
+	return p.seg.data[p.off:end:end]
+}
+
 // readSize returns the struct's size for the purposes of read limit
 // accounting.
 func (p Struct) readSize() Size {
@@ -0,0 +1,80 @@
+// Package testvector checks that Go code encodes and decodes Cap'n Proto
+// values the same way the schema compiler does.
+//
+// capnpc-go, when run with -testvectors, writes a test for each schema
+// file that declares struct constants annotated with $Go.testVector.
+// The test holds the canonical encoding of each constant as computed at
+// generation time from the schema compiler's output, and calls Check to
+// compare it against the Go value.  Since the canonical form does not
+// depend on how a message happens to be laid out, the same vectors can
+// be shared with implementations in other languages.
+package testvector
+
+import (
+	"bytes"
+	"errors"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// A Vector is a single wire-compatibility test vector.
+type Vector struct {
+	// Name identifies the vector in error messages.  Generated tests use
+	// the name of the constant in the schema.
+	Name string
+
+	// Value is the value as seen by Go code.
+	Value capnp.Struct
+
+	// Canonical is the expected canonical encoding of Value: a
+	// single-segment message without a segment table.
+	Canonical []byte
+}
+
+// Verify reports whether v round-trips.  Value must canonicalize to
+// Canonical, and Canonical, once decoded, must canonicalize to itself.
+func (v Vector) Verify() error {
+	got, err := capnp.Canonicalize(v.Value)
+	if err != nil {
+		return errors.New(v.Name + ": canonicalize value: " + err.Error())
+	}
+	if !bytes.Equal(got, v.Canonical) {
+		return errors.New(v.Name + ": canonical encoding of value does not match vector")
+	}
+
+	// Decode from a copy, so that Canonical is left as is.
+	buf := make([]byte, len(v.Canonical))
+	copy(buf, v.Canonical)
+	msg, _, err := capnp.NewMessage(capnp.SingleSegment(buf))
+	if err != nil {
+		return errors.New(v.Name + ": decode vector: " + err.Error())
+	}
+	p, err := msg.Root()
+	if err != nil {
+		return errors.New(v.Name + ": decode vector: " + err.Error())
+	}
+	again, err := capnp.Canonicalize(p.Struct())
+	if err != nil {
+		return errors.New(v.Name + ": canonicalize decoded vector: " + err.Error())
+	}
+	if !bytes.Equal(again, v.Canonical) {
+		return errors.New(v.Name + ": decoded vector does not re-encode identically")
+	}
+	return nil
+}
+
+// TB is the subset of testing.TB used by Check.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Check verifies each vector, reporting failures to t.
+func Check(t TB, vectors []Vector) {
+	t.Helper()
+	for _, v := range vectors {
+		if err := v.Verify(); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+}
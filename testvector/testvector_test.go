@@ -0,0 +1,80 @@
+package testvector_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/testvector"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	canonical, err := capnp.Canonicalize(capnp.Struct(air.ConstDate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := testvector.Vector{
+		Name:      "constDate",
+		Value:     capnp.Struct(air.ConstDate),
+		Canonical: canonical,
+	}
+	if err := v.Verify(); err != nil {
+		t.Error("Verify:", err)
+	}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		_, seg := capnp.NewSingleSegmentMessage(nil)
+		d, err := air.NewRootZdate(seg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.SetYear(2015)
+		d.SetMonth(8)
+		d.SetDay(28)
+		bad := v
+		bad.Value = capnp.Struct(d)
+		err = bad.Verify()
+		if err == nil || !strings.HasPrefix(err.Error(), "constDate: ") {
+			t.Errorf("Verify = %v; want error for constDate", err)
+		}
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		bad := v
+		bad.Value = capnp.Struct{}
+		bad.Canonical = canonical[:8]
+		if err := bad.Verify(); err == nil {
+			t.Error("Verify succeeded for a truncated vector")
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	canonical, err := capnp.Canonicalize(capnp.Struct(air.ConstDate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := new(recorder)
+	testvector.Check(rec, []testvector.Vector{
+		{Name: "good", Value: capnp.Struct(air.ConstDate), Canonical: canonical},
+		{Name: "bad", Value: capnp.Struct(air.ConstDate), Canonical: canonical[:len(canonical)-8]},
+	})
+	if len(rec.errs) != 1 || !strings.HasPrefix(rec.errs[0], "bad: ") {
+		t.Errorf("Check reported %q; want one error for bad", rec.errs)
+	}
+}
+
+type recorder struct {
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,130 @@
+// Package timeout bounds how long calls may take, per method.
+//
+// A client returned by Client makes every call with a context derived
+// from the caller's, whose deadline is set by a Policy.  When the
+// deadline passes, the call fails with an error wrapping
+// context.DeadlineExceeded.  For calls made over a Conn, the Conn also
+// sends a Finish message to the remote vat, which cancels the call's
+// context on the remote side, so abandoned calls do not keep running.
+// This lets operators enforce latency objectives on all outbound calls
+// in one place, instead of at every call site:
+//
+//	c := timeout.Client(capnp.Client(client), timeout.Policy{
+//		Default: 5 * time.Second,
+//		Methods: map[timeout.Method]time.Duration{
+//			{InterfaceID: foo.Foo_TypeID, MethodID: 1}: time.Minute,
+//		},
+//	})
+package timeout // import "capnproto.org/go/capnp/v3/timeout"
+
+import (
+	"context"
+	"time"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// Method identifies a method in a Policy.
+type Method struct {
+	InterfaceID uint64
+	MethodID    uint16
+}
+
+// A Policy sets the timeouts of calls.
+type Policy struct {
+	// Default is the timeout of calls to methods that are not in
+	// Methods.  If zero, such calls have no timeout.
+	Default time.Duration
+
+	// Methods overrides Default for specific methods.  A zero duration
+	// exempts a method from the default timeout.
+	Methods map[Method]time.Duration
+}
+
+// Timeout returns the timeout for calls to m, or zero if they have none.
+func (p Policy) Timeout(m capnp.Method) time.Duration {
+	if d, ok := p.Methods[Method{InterfaceID: m.InterfaceID, MethodID: m.MethodID}]; ok {
+		return d
+	}
+	return p.Default
+}
+
+// Client returns a client that forwards calls to c with the timeouts
+// set by p.  A caller's own deadline still applies if it is earlier.
+// Client takes ownership of c: it is released when the returned client
+// is shut down.  Client copies p, so later changes to p.Methods have no
+// effect.
+//
+// Timeouts apply to calls made on the returned client, and to calls
+// that a Conn delivers to it when it is exported.  Pipelined calls on a
+// call's results are not covered, as they are made on the results
+// rather than on the client.
+func Client(c capnp.Client, p Policy) capnp.Client {
+	methods := make(map[Method]time.Duration, len(p.Methods))
+	for m, d := range p.Methods {
+		methods[m] = d
+	}
+	p.Methods = methods
+	return capnp.NewClient(&hook{target: c, policy: p})
+}
+
+// A hook is the capnp.ClientHook behind a client returned by Client.
+type hook struct {
+	target capnp.Client
+	policy Policy
+}
+
+func (h *hook) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	d := h.policy.Timeout(s.Method)
+	if d <= 0 {
+		return h.target.SendCall(ctx, s)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	ans, release := h.target.SendCall(ctx, s)
+
+	// Stop the timer as soon as the call returns.
+	select {
+	case <-ans.Done():
+		cancel()
+	default:
+		go func() {
+			<-ans.Done()
+			cancel()
+		}()
+	}
+	return ans, release
+}
+
+func (h *hook) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	d := h.policy.Timeout(r.Method)
+	if d <= 0 {
+		return h.target.RecvCall(ctx, r)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	r.Returner = &cancelingReturner{Returner: r.Returner, cancel: cancel}
+	return h.target.RecvCall(ctx, r)
+}
+
+func (h *hook) Brand() capnp.Brand {
+	return capnp.Brand{Value: h}
+}
+
+func (h *hook) Shutdown() {
+	h.target.Release()
+}
+
+func (h *hook) String() string {
+	return "timeout(" + h.target.String() + ")"
+}
+
+// cancelingReturner cancels the context of a received call once it has
+// returned.
+type cancelingReturner struct {
+	capnp.Returner
+	cancel context.CancelFunc
+}
+
+func (cr *cancelingReturner) Return() {
+	cr.Returner.Return()
+	cr.cancel()
+}
@@ -0,0 +1,104 @@
+package timeout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/timeout"
+)
+
+// slowEcho echoes after delay, or fails once its context is canceled.
+type slowEcho struct {
+	delay    time.Duration
+	canceled chan struct{}
+}
+
+func (e slowEcho) Echo(ctx context.Context, call air.Echo_echo) error {
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		close(e.canceled)
+		return ctx.Err()
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut("done")
+}
+
+func echo(ctx context.Context, e air.Echo) error {
+	ans, release := e.Echo(ctx, nil)
+	defer release()
+	_, err := ans.Struct()
+	return err
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	canceled := make(chan struct{})
+	srv := air.Echo_ServerToClient(slowEcho{delay: time.Minute, canceled: canceled})
+	e := air.Echo(timeout.Client(capnp.Client(srv), timeout.Policy{
+		Default: 10 * time.Millisecond,
+	}))
+	defer e.Release()
+
+	err := echo(context.Background(), e)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	<-canceled
+}
+
+func TestClientOverConn(t *testing.T) {
+	t.Parallel()
+
+	// The timeout is applied by the calling vat, which must tell the
+	// remote vat to stop working on the call.
+	canceled := make(chan struct{})
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: capnp.Client(air.Echo_ServerToClient(slowEcho{delay: time.Minute, canceled: canceled})),
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	e := air.Echo(timeout.Client(clientConn.Bootstrap(context.Background()), timeout.Policy{
+		Default: 10 * time.Millisecond,
+	}))
+	defer e.Release()
+
+	err := echo(context.Background(), e)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("remote call was not canceled")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	echoMethod := timeout.Method{InterfaceID: air.Echo_TypeID, MethodID: 0}
+	srv := air.Echo_ServerToClient(slowEcho{delay: 50 * time.Millisecond, canceled: make(chan struct{})})
+	e := air.Echo(timeout.Client(capnp.Client(srv), timeout.Policy{
+		Default: time.Millisecond,
+		Methods: map[timeout.Method]time.Duration{echoMethod: 0},
+	}))
+	defer e.Release()
+
+	require.NoError(t, echo(context.Background(), e), "exempt method should not time out")
+
+	p := timeout.Policy{
+		Default: time.Second,
+		Methods: map[timeout.Method]time.Duration{echoMethod: time.Minute},
+	}
+	assert.Equal(t, time.Minute, p.Timeout(capnp.Method{InterfaceID: air.Echo_TypeID, MethodID: 0}))
+	assert.Equal(t, time.Second, p.Timeout(capnp.Method{InterfaceID: air.Echo_TypeID, MethodID: 1}))
+}
@@ -0,0 +1,157 @@
+package capnp
+
+import (
+	"errors"
+	"strconv"
+
+	"capnproto.org/go/capnp/v3/internal/str"
+)
+
+// A ValidationError describes the first invalid pointer found by
+// Message.Validate.
+type ValidationError struct {
+	// Segment and Offset locate the invalid pointer word: Offset is
+	// its byte offset within the segment.
+	Segment SegmentID
+	Offset  uint32
+
+	// Path is the path to the pointer from the message's root.
+	Path PtrPath
+
+	// Err describes what is wrong with the pointer.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return "validate message: " + e.Path.String() +
+		" (segment " + str.Utod(e.Segment) +
+		", offset " + strconv.FormatUint(uint64(e.Offset), 10) + "): " +
+		e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+var errValidateLimit = errors.New("message exceeds traversal limit")
+
+// Validate walks every object reachable from the message's root and
+// reports the first invalid pointer as a *ValidationError.  It checks
+// that objects are within the bounds of their segments, that far
+// pointers lead to valid landing pads, that the elements of composite
+// lists fit in the space the list pointer declares, that the message
+// is no deeper than DepthLimit, and that reading the whole message
+// would not exceed TraverseLimit.
+//
+// Reads of a message check these lazily, as each object is reached,
+// so an invalid message can be read successfully up to the point where
+// an accessor fails.  Validating a message when it is received lets a
+// service reject hostile messages up front instead.  Validate does not
+// charge the message's read limit, nor its ReadBudget.  It does not
+// check that interface pointers refer to entries in the capability
+// table.
+func (m *Message) Validate() error {
+	s, err := m.Segment(0)
+	if err != nil {
+		return &ValidationError{Err: err}
+	}
+	if len(s.Data()) < int(wordSize) {
+		return &ValidationError{Err: errors.New("message does not contain root pointer")}
+	}
+	v := validator{limit: m.TraverseLimit}
+	if v.limit == 0 {
+		v.limit = defaultTraverseLimit
+	}
+	return v.ptr(s, 0, m.depthLimit(), nil)
+}
+
+// validator holds the state of Message.Validate.
+type validator struct {
+	limit uint64 // bytes left to traverse
+}
+
+// ptr validates the pointer at paddr in s and the object it refers to.
+func (v *validator) ptr(s *Segment, paddr address, depth uint, path PtrPath) error {
+	fail := func(err error) error {
+		return &ValidationError{
+			Segment: s.ID(),
+			Offset:  uint32(paddr),
+			Path:    append(PtrPath(nil), path...),
+			Err:     err,
+		}
+	}
+	p, err := s.readPtrUnaccounted(paddr, depth)
+	if err != nil {
+		return fail(err)
+	}
+	if !p.IsValid() {
+		return nil
+	}
+	sz := uint64(p.readSize())
+	if sz > v.limit {
+		return fail(errValidateLimit)
+	}
+	v.limit -= sz
+
+	switch p.flags.ptrType() {
+	case structPtrType:
+		return v.structPtrs(p.Struct(), path)
+	case listPtrType:
+		l := p.List()
+		if l.flags&isCompositeList != 0 {
+			if err := checkCompositeSize(s, paddr, l); err != nil {
+				return fail(err)
+			}
+		}
+		return v.list(l, path)
+	default:
+		return nil
+	}
+}
+
+// checkCompositeSize reports an error if the elements of l, a composite
+// list read from the pointer at paddr in s, take up more words than the
+// list pointer declares.
+func checkCompositeSize(s *Segment, paddr address, l List) error {
+	_, _, val, err := s.resolveFarPointer(paddr)
+	if err != nil {
+		return err
+	}
+	declared, _ := val.totalListSize()
+	size, _ := l.size.totalSize().times(l.length)
+	if declared < wordSize || size > declared-wordSize {
+		return errors.New("composite list pointer: elements overrun the list's word count")
+	}
+	return nil
+}
+
+func (v *validator) structPtrs(st Struct, path PtrPath) error {
+	for i := uint16(0); i < st.size.PointerCount; i++ {
+		err := v.ptr(st.seg, st.pointerAddress(i), st.depthLimit, append(path, PathStep{Index: int(i)}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) list(l List, path PtrPath) error {
+	if l.size.PointerCount == 0 {
+		return nil
+	}
+	for i := 0; i < l.Len(); i++ {
+		elemPath := append(path, PathStep{Elem: true, Index: i})
+		addr, _ := l.off.element(int32(i), l.size.totalSize())
+		if l.flags&isCompositeList != 0 {
+			st := Struct{seg: l.seg, off: addr, size: l.size, depthLimit: l.depthLimit}
+			if err := v.structPtrs(st, elemPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := v.ptr(l.seg, addr, l.depthLimit, elemPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
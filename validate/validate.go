@@ -0,0 +1,166 @@
+// Package validate checks the results of calls before the application
+// sees them.
+//
+// A client returned by Client runs a Func on the results of every call
+// made through it.  Results that fail the check are never exposed: the
+// call fails with an *Error instead, so code that handles answers only
+// deals with results that passed validation, and a misbehaving peer is
+// reported at the boundary rather than deep inside the application.
+//
+// Generated struct types whose fields carry constraint annotations have
+// a Validate method, which Generated turns into a Func:
+//
+//	c := validate.Client(capnp.Client(client), validate.Chain(
+//		validate.Generated[foo.Foo_bar_Results](foo.Foo_TypeID, 0),
+//		validate.Generated[foo.Foo_baz_Results](foo.Foo_TypeID, 1),
+//	))
+//	client = foo.Foo(c)
+package validate // import "capnproto.org/go/capnp/v3/validate"
+
+import (
+	"context"
+
+	"capnproto.org/go/capnp/v3"
+)
+
+// A Func checks the results of a call to m.  A non-nil error fails the
+// call.  A Func must be safe to call from multiple goroutines, and must
+// not retain results after returning.
+type Func func(m capnp.Method, results capnp.Struct) error
+
+// Generated returns a Func that checks the results of calls to the
+// given method with their Validate method, and accepts the results of
+// calls to other methods.
+func Generated[R interface {
+	~capnp.StructKind
+	Validate() error
+}](interfaceID uint64, methodID uint16) Func {
+	return func(m capnp.Method, results capnp.Struct) error {
+		if m.InterfaceID != interfaceID || m.MethodID != methodID {
+			return nil
+		}
+		return R(results).Validate()
+	}
+}
+
+// Chain returns a Func that runs fs in order, and fails with the first
+// error.
+func Chain(fs ...Func) Func {
+	return func(m capnp.Method, results capnp.Struct) error {
+		for _, f := range fs {
+			if err := f(m, results); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// An Error reports results that failed validation.
+type Error struct {
+	Method capnp.Method
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return "invalid results from " + e.Method.String() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Client returns a client that forwards calls to c and checks their
+// results with f.  Client takes ownership of c: it is released when the
+// returned client is shut down.
+//
+// Pipelined calls made on a call's results before they are validated
+// are forwarded right away, so they may reach capabilities in results
+// that later fail validation.  Callers that must not act on invalid
+// results should wait for the answer first.
+func Client(c capnp.Client, f Func) capnp.Client {
+	return capnp.NewClient(&hook{target: c, check: f})
+}
+
+// A hook is the capnp.ClientHook behind a client returned by Client.
+type hook struct {
+	target capnp.Client
+	check  Func
+}
+
+// validate runs the check on results, wrapping a failure in an *Error.
+func (h *hook) validate(m capnp.Method, results capnp.Struct) error {
+	if err := h.check(m, results); err != nil {
+		return &Error{Method: m, Err: err}
+	}
+	return nil
+}
+
+func (h *hook) Send(ctx context.Context, s capnp.Send) (*capnp.Answer, capnp.ReleaseFunc) {
+	ans, release := h.target.SendCall(ctx, s)
+	p := capnp.NewPromise(s.Method, ans, nil)
+	resolve := func() {
+		res, err := ans.Struct()
+		if err == nil {
+			err = h.validate(s.Method, res)
+		}
+		if err != nil {
+			p.Reject(err)
+			return
+		}
+		p.Fulfill(res.ToPtr())
+	}
+	select {
+	case <-ans.Done():
+		resolve()
+	default:
+		go func() {
+			<-ans.Done()
+			resolve()
+		}()
+	}
+	return p.Answer(), func() {
+		<-p.Answer().Done()
+		p.ReleaseClients()
+		release()
+	}
+}
+
+func (h *hook) Recv(ctx context.Context, r capnp.Recv) capnp.PipelineCaller {
+	r.Returner = &validatingReturner{Returner: r.Returner, hook: h, method: r.Method}
+	return h.target.RecvCall(ctx, r)
+}
+
+func (h *hook) Brand() capnp.Brand {
+	return capnp.Brand{Value: h}
+}
+
+func (h *hook) Shutdown() {
+	h.target.Release()
+}
+
+func (h *hook) String() string {
+	return "validate(" + h.target.String() + ")"
+}
+
+// validatingReturner checks the results of a call received by a hook
+// before they are returned.
+type validatingReturner struct {
+	capnp.Returner
+	hook    *hook
+	method  capnp.Method
+	results capnp.Struct
+}
+
+func (vr *validatingReturner) AllocResults(sz capnp.ObjectSize) (capnp.Struct, error) {
+	s, err := vr.Returner.AllocResults(sz)
+	vr.results = s
+	return s, err
+}
+
+func (vr *validatingReturner) PrepareReturn(e error) {
+	if e == nil {
+		e = vr.hook.validate(vr.method, vr.results)
+	}
+	vr.Returner.PrepareReturn(e)
+}
@@ -0,0 +1,119 @@
+package validate_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"capnproto.org/go/capnp/v3"
+	air "capnproto.org/go/capnp/v3/internal/aircraftlib"
+	"capnproto.org/go/capnp/v3/rpc"
+	"capnproto.org/go/capnp/v3/validate"
+)
+
+type echoer struct{}
+
+func (echoer) Echo(ctx context.Context, call air.Echo_echo) error {
+	in, err := call.Args().In()
+	if err != nil {
+		return err
+	}
+	res, err := call.AllocResults()
+	if err != nil {
+		return err
+	}
+	return res.SetOut(in)
+}
+
+var errShouting = errors.New("no shouting")
+
+// quiet rejects echoes in upper case.
+func quiet(m capnp.Method, results capnp.Struct) error {
+	out, err := air.Echo_echo_Results(results).Out()
+	if err != nil {
+		return err
+	}
+	if out != strings.ToLower(out) {
+		return errShouting
+	}
+	return nil
+}
+
+func echo(ctx context.Context, e air.Echo, in string) (string, error) {
+	ans, release := e.Echo(ctx, func(p air.Echo_echo_Params) error {
+		return p.SetIn(in)
+	})
+	defer release()
+	res, err := ans.Struct()
+	if err != nil {
+		return "", err
+	}
+	return res.Out()
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	e := air.Echo(validate.Client(capnp.Client(air.Echo_ServerToClient(echoer{})), quiet))
+	defer e.Release()
+
+	out, err := echo(ctx, e, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", out)
+
+	_, err = echo(ctx, e, "HELLO")
+	var verr *validate.Error
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, uint64(air.Echo_TypeID), verr.Method.InterfaceID)
+	assert.ErrorIs(t, err, errShouting)
+}
+
+func TestClientOverConn(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	serverConn, clientConn := rpc.NewLocalPair(&rpc.Options{
+		BootstrapClient: validate.Client(capnp.Client(air.Echo_ServerToClient(echoer{})), quiet),
+	}, nil)
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	e := air.Echo(clientConn.Bootstrap(ctx))
+	defer e.Release()
+
+	out, err := echo(ctx, e, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", out)
+
+	_, err = echo(ctx, e, "HELLO")
+	assert.ErrorContains(t, err, "invalid results from")
+	assert.ErrorContains(t, err, errShouting.Error())
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	errFirst := errors.New("first")
+	var calls []string
+	f := validate.Chain(
+		func(capnp.Method, capnp.Struct) error {
+			calls = append(calls, "a")
+			return nil
+		},
+		func(capnp.Method, capnp.Struct) error {
+			calls = append(calls, "b")
+			return errFirst
+		},
+		func(capnp.Method, capnp.Struct) error {
+			calls = append(calls, "c")
+			return nil
+		},
+	)
+	assert.Equal(t, errFirst, f(capnp.Method{}, capnp.Struct{}))
+	assert.Equal(t, []string{"a", "b"}, calls)
+}
@@ -0,0 +1,197 @@
+package capnp
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	// rawSegment returns a segment holding words.
+	rawSegment := func(words ...rawPointer) []byte {
+		b := make([]byte, 0, len(words)*int(wordSize))
+		for _, w := range words {
+			b = binary.LittleEndian.AppendUint64(b, uint64(w))
+		}
+		return b
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		msg, seg, err := NewMessage(SingleSegment(nil))
+		require.NoError(t, err)
+		root, err := NewRootStruct(seg, ObjectSize{DataSize: 8, PointerCount: 3})
+		require.NoError(t, err)
+		require.NoError(t, root.SetNewText(0, "hello"))
+		cl, err := NewCompositeList(seg, ObjectSize{DataSize: 8, PointerCount: 1}, 3)
+		require.NoError(t, err)
+		for i := 0; i < cl.Len(); i++ {
+			require.NoError(t, cl.Struct(i).SetNewText(0, "elem"))
+		}
+		require.NoError(t, root.SetPtr(1, cl.ToPtr()))
+		tl, err := NewTextList(seg, 2)
+		require.NoError(t, err)
+		require.NoError(t, tl.Set(1, "x"))
+		require.NoError(t, root.SetPtr(2, tl.ToPtr()))
+
+		data, err := msg.Marshal()
+		require.NoError(t, err)
+		msg, err = Unmarshal(data)
+		require.NoError(t, err)
+		assert.NoError(t, msg.Validate())
+
+		// The root is in another segment, behind a far pointer.
+		msg = &Message{Arena: MultiSegment([][]byte{
+			rawSegment(rawFarPointer(1, 0)),
+			rawSegment(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawListPointer(0, byte1List, 8),
+				0,
+			),
+		})}
+		assert.NoError(t, msg.Validate())
+	})
+
+	tests := []struct {
+		name    string
+		segs    [][]byte
+		depth   uint
+		limit   uint64
+		path    string
+		segment SegmentID
+		offset  uint32
+		errMsg  string
+	}{
+		{
+			name:   "RootOutOfBounds",
+			segs:   [][]byte{rawSegment(rawStructPointer(10, ObjectSize{DataSize: 8}))},
+			path:   "root",
+			errMsg: "struct pointer: invalid address",
+		},
+		{
+			name: "FieldOutOfBounds",
+			segs: [][]byte{rawSegment(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawStructPointer(5, ObjectSize{DataSize: 8}),
+			)},
+			path:   "root.p0",
+			offset: 8,
+			errMsg: "struct pointer: invalid address",
+		},
+		{
+			name: "ListElementOutOfBounds",
+			segs: [][]byte{rawSegment(
+				rawListPointer(0, pointerList, 2),
+				0,
+				rawListPointer(3, byte1List, 100),
+			)},
+			path:   "root[1]",
+			offset: 16,
+			errMsg: "list pointer: address out of bounds",
+		},
+		{
+			name: "CompositeOverrun",
+			segs: [][]byte{rawSegment(
+				// The list declares only its tag word, but the tag
+				// says it has two one-word elements.
+				rawListPointer(0, compositeList, 1),
+				rawStructPointer(2, ObjectSize{DataSize: 8}),
+				0,
+				0,
+			)},
+			path:   "root",
+			errMsg: "composite list pointer: elements overrun",
+		},
+		{
+			name: "FarPointerToMissingSegment",
+			segs: [][]byte{rawSegment(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawFarPointer(3, 0),
+			)},
+			path:   "root.p0",
+			offset: 8,
+			errMsg: "far pointer: segment 3 out of bounds",
+		},
+		{
+			name: "FarPointerInSecondSegment",
+			segs: [][]byte{
+				rawSegment(rawFarPointer(1, 0)),
+				rawSegment(
+					rawStructPointer(0, ObjectSize{PointerCount: 1}),
+					rawFarPointer(1, 800),
+				),
+			},
+			path:    "root.p0",
+			segment: 1,
+			offset:  8,
+			errMsg:  "far pointer: address out of bounds",
+		},
+		{
+			name: "TooDeep",
+			segs: [][]byte{rawSegment(
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				rawStructPointer(0, ObjectSize{PointerCount: 1}),
+				0,
+			)},
+			depth:  2,
+			path:   "root.p0.p0",
+			offset: 16,
+			errMsg: "depth limit reached",
+		},
+		{
+			name: "TraversalLimit",
+			segs: [][]byte{rawSegment(
+				rawListPointer(0, pointerList, 2),
+				rawListPointer(1, byte8List, 2),
+				rawListPointer(0, byte8List, 2),
+				0,
+				0,
+			)},
+			limit:  40,
+			path:   "root[1]",
+			offset: 16,
+			errMsg: "exceeds traversal limit",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			msg := &Message{
+				Arena:         MultiSegment(tt.segs),
+				DepthLimit:    tt.depth,
+				TraverseLimit: tt.limit,
+			}
+			err := msg.Validate()
+			var verr *ValidationError
+			require.True(t, errors.As(err, &verr), "got %v; want *ValidationError", err)
+			assert.Equal(t, tt.path, verr.Path.String())
+			assert.Equal(t, tt.segment, verr.Segment)
+			assert.Equal(t, tt.offset, verr.Offset)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+
+	t.Run("DoesNotChargeReads", func(t *testing.T) {
+		t.Parallel()
+
+		msg := &Message{
+			Arena: MultiSegment([][]byte{rawSegment(
+				rawStructPointer(0, ObjectSize{DataSize: 8}),
+				0,
+			)}),
+			TraverseLimit: 8,
+		}
+		require.NoError(t, msg.Validate())
+		_, err := msg.Root()
+		assert.NoError(t, err)
+	})
+}
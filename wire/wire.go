@@ -0,0 +1,319 @@
+// Package wire exposes the low-level layout of Cap'n Proto pointers as
+// described at https://capnproto.org/encoding.html.
+//
+// Most applications should use the capnp package instead.  This package
+// is intended for tooling that needs to examine raw message words
+// directly, such as disk format inspectors, fuzzers and converters.
+package wire // import "capnproto.org/go/capnp/v3/wire"
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+// WordSize is the number of bytes in a Cap'n Proto word.
+const WordSize = 8
+
+// MaxSegmentWords is the maximum number of words that a pointer offset
+// can address within a single segment.
+const MaxSegmentWords = 1 << 29
+
+// Word returns the i-th little-endian word of b.  It panics if b does
+// not contain at least (i+1)*WordSize bytes.
+func Word(b []byte, i int) uint64 {
+	return binary.LittleEndian.Uint64(b[i*WordSize:])
+}
+
+// PutWord stores w as the i-th little-endian word of b.  It panics if b
+// does not contain at least (i+1)*WordSize bytes.
+func PutWord(b []byte, i int, w uint64) {
+	binary.LittleEndian.PutUint64(b[i*WordSize:], w)
+}
+
+// PointerKind is the kind of a pointer, stored in its lowest two bits.
+type PointerKind uint8
+
+// Pointer kinds.
+const (
+	StructKind PointerKind = 0
+	ListKind   PointerKind = 1
+	FarKind    PointerKind = 2
+	OtherKind  PointerKind = 3
+)
+
+// String returns the kind's name.
+func (k PointerKind) String() string {
+	switch k {
+	case StructKind:
+		return "struct"
+	case ListKind:
+		return "list"
+	case FarKind:
+		return "far"
+	case OtherKind:
+		return "other"
+	default:
+		return "PointerKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// ElementSize is the element size of a list pointer, stored in bits
+// 32-34 of the pointer.
+type ElementSize uint8
+
+// List element sizes.
+const (
+	Void            ElementSize = 0
+	Bit             ElementSize = 1
+	Byte            ElementSize = 2
+	TwoBytes        ElementSize = 3
+	FourBytes       ElementSize = 4
+	EightBytes      ElementSize = 5
+	PointerSize     ElementSize = 6
+	InlineComposite ElementSize = 7
+)
+
+// String returns the element size's name.
+func (es ElementSize) String() string {
+	switch es {
+	case Void:
+		return "void"
+	case Bit:
+		return "bit"
+	case Byte:
+		return "byte"
+	case TwoBytes:
+		return "twoBytes"
+	case FourBytes:
+		return "fourBytes"
+	case EightBytes:
+		return "eightBytes"
+	case PointerSize:
+		return "pointer"
+	case InlineComposite:
+		return "inlineComposite"
+	default:
+		return "ElementSize(" + strconv.Itoa(int(es)) + ")"
+	}
+}
+
+// BitsPerElement returns the number of bits each element occupies in
+// the list's content.  It returns 0 for InlineComposite, since the size
+// of composite elements is given by the list's tag word.
+func (es ElementSize) BitsPerElement() int {
+	switch es {
+	case Bit:
+		return 1
+	case Byte:
+		return 8
+	case TwoBytes:
+		return 16
+	case FourBytes:
+		return 32
+	case EightBytes, PointerSize:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// Pointer is a single encoded pointer word.
+type Pointer uint64
+
+// StructPointer returns a struct pointer.  off is the signed number of
+// words from the end of the pointer to the start of the struct.
+func StructPointer(off int32, dataWords, ptrCount uint16) Pointer {
+	return Pointer(StructKind) |
+		Pointer(uint32(off)<<2) |
+		Pointer(dataWords)<<32 |
+		Pointer(ptrCount)<<48
+}
+
+// ListPointer returns a list pointer.  off is the signed number of words
+// from the end of the pointer to the start of the list.  If es is
+// InlineComposite, n is the number of words in the list content
+// (excluding the tag word), otherwise it is the number of elements.
+func ListPointer(off int32, es ElementSize, n uint32) Pointer {
+	return Pointer(ListKind) |
+		Pointer(uint32(off)<<2) |
+		Pointer(es&7)<<32 |
+		Pointer(n&(MaxSegmentWords-1))<<35
+}
+
+// FarPointer returns a far pointer to the landing pad located at word
+// offset off of segment seg.  If double is true, the landing pad is
+// itself a far pointer followed by a tag word.
+func FarPointer(seg uint32, off uint32, double bool) Pointer {
+	p := Pointer(FarKind) | Pointer(off&(MaxSegmentWords-1))<<3 | Pointer(seg)<<32
+	if double {
+		p |= 4
+	}
+	return p
+}
+
+// CapabilityPointer returns a capability pointer that references the
+// given index of the message's capability table.
+func CapabilityPointer(index uint32) Pointer {
+	return Pointer(OtherKind) | Pointer(index)<<32
+}
+
+// IsNull reports whether p is the null pointer.
+func (p Pointer) IsNull() bool {
+	return p == 0
+}
+
+// Kind returns the pointer's kind.
+func (p Pointer) Kind() PointerKind {
+	return PointerKind(p & 3)
+}
+
+// Offset returns the signed word offset of a struct or list pointer,
+// relative to the end of the pointer.
+func (p Pointer) Offset() int32 {
+	return int32(p) >> 2
+}
+
+// Target returns the word index that a struct or list pointer located
+// at word index at refers to.
+func (p Pointer) Target(at int64) int64 {
+	return at + 1 + int64(p.Offset())
+}
+
+// StructDataWords returns the size of a struct pointer's data section
+// in words.
+func (p Pointer) StructDataWords() uint16 {
+	return uint16(p >> 32)
+}
+
+// StructPointerCount returns the number of pointers in a struct
+// pointer's pointer section.
+func (p Pointer) StructPointerCount() uint16 {
+	return uint16(p >> 48)
+}
+
+// StructWords returns the total size of the struct referenced by a
+// struct pointer in words.
+func (p Pointer) StructWords() int64 {
+	return int64(p.StructDataWords()) + int64(p.StructPointerCount())
+}
+
+// ListElementSize returns the element size of a list pointer.
+func (p Pointer) ListElementSize() ElementSize {
+	return ElementSize((p >> 32) & 7)
+}
+
+// ListCount returns the element count of a list pointer, or the number
+// of content words for an InlineComposite list.
+func (p Pointer) ListCount() uint32 {
+	return uint32(p >> 35)
+}
+
+// ListWords returns the number of words occupied by the content of the
+// list referenced by a list pointer, including the tag word of an
+// InlineComposite list.
+func (p Pointer) ListWords() int64 {
+	n := int64(p.ListCount())
+	if p.ListElementSize() == InlineComposite {
+		return n + 1
+	}
+	bits := n * int64(p.ListElementSize().BitsPerElement())
+	return (bits + 63) / 64
+}
+
+// IsDoubleFar reports whether a far pointer's landing pad is a
+// double-far landing pad.
+func (p Pointer) IsDoubleFar() bool {
+	return p&4 != 0
+}
+
+// FarSegment returns the segment ID referenced by a far pointer.
+func (p Pointer) FarSegment() uint32 {
+	return uint32(p >> 32)
+}
+
+// FarOffset returns the word offset of a far pointer's landing pad from
+// the start of its segment.
+func (p Pointer) FarOffset() uint32 {
+	return uint32(p) >> 3
+}
+
+// OtherType returns the type of an "other" pointer.  Zero denotes a
+// capability pointer; other values are reserved.
+func (p Pointer) OtherType() uint32 {
+	return uint32(p) >> 2
+}
+
+// IsCapability reports whether p is a capability pointer.
+func (p Pointer) IsCapability() bool {
+	return p.Kind() == OtherKind && p.OtherType() == 0
+}
+
+// CapabilityIndex returns the capability table index of a capability
+// pointer.
+func (p Pointer) CapabilityIndex() uint32 {
+	return uint32(p >> 32)
+}
+
+// String returns a human-readable description of the pointer.
+func (p Pointer) String() string {
+	if p.IsNull() {
+		return "null"
+	}
+	switch p.Kind() {
+	case StructKind:
+		return "struct(off=" + strconv.Itoa(int(p.Offset())) +
+			", data=" + strconv.Itoa(int(p.StructDataWords())) +
+			", ptrs=" + strconv.Itoa(int(p.StructPointerCount())) + ")"
+	case ListKind:
+		return "list(off=" + strconv.Itoa(int(p.Offset())) +
+			", size=" + p.ListElementSize().String() +
+			", n=" + strconv.FormatUint(uint64(p.ListCount()), 10) + ")"
+	case FarKind:
+		name := "far"
+		if p.IsDoubleFar() {
+			name = "doubleFar"
+		}
+		return name + "(seg=" + strconv.FormatUint(uint64(p.FarSegment()), 10) +
+			", off=" + strconv.FormatUint(uint64(p.FarOffset()), 10) + ")"
+	default:
+		if !p.IsCapability() {
+			return "other(" + strconv.FormatUint(uint64(p), 16) + ")"
+		}
+		return "capability(" + strconv.FormatUint(uint64(p.CapabilityIndex()), 10) + ")"
+	}
+}
+
+// ErrShortTag is returned by ReadCompositeTag when the list content is
+// too short to hold a tag word.
+var ErrShortTag = errors.New("wire: composite list tag out of bounds")
+
+// CompositeTag describes the tag word of an InlineComposite list.
+type CompositeTag struct {
+	// Count is the number of elements in the list.
+	Count uint32
+
+	// DataWords and PointerCount describe the size of each element.
+	DataWords    uint16
+	PointerCount uint16
+}
+
+// ElementWords returns the size of a single element in words.
+func (t CompositeTag) ElementWords() int64 {
+	return int64(t.DataWords) + int64(t.PointerCount)
+}
+
+// ReadCompositeTag decodes the tag word found at word index at of seg.
+// The tag word has the same layout as a struct pointer, with the offset
+// holding the element count.
+func ReadCompositeTag(seg []byte, at int64) (CompositeTag, error) {
+	if at < 0 || (at+1)*WordSize > int64(len(seg)) {
+		return CompositeTag{}, ErrShortTag
+	}
+	p := Pointer(Word(seg, int(at)))
+	return CompositeTag{
+		Count:        uint32(p.Offset()),
+		DataWords:    p.StructDataWords(),
+		PointerCount: p.StructPointerCount(),
+	}, nil
+}
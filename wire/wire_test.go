@@ -0,0 +1,189 @@
+package wire
+
+import (
+	"testing"
+)
+
+func TestStructPointer(t *testing.T) {
+	tests := []struct {
+		ptr       Pointer
+		offset    int32
+		dataWords uint16
+		ptrCount  uint16
+	}{
+		{0x0000000000000000, 0, 0, 0},
+		{0x0000000000000004, 1, 0, 0},
+		{0x0403020100000000, 0, 0x0201, 0x0403},
+		{0xfffffffffffffff8, -2, 0xffff, 0xffff},
+		{0x00000000fffffffc, -1, 0, 0},
+		{0x04030201fffffffc, -1, 0x0201, 0x0403},
+	}
+	for _, test := range tests {
+		if k := test.ptr.Kind(); k != StructKind {
+			t.Errorf("Pointer(%#016x).Kind() = %v; want %v", uint64(test.ptr), k, StructKind)
+		}
+		if off := test.ptr.Offset(); off != test.offset {
+			t.Errorf("Pointer(%#016x).Offset() = %d; want %d", uint64(test.ptr), off, test.offset)
+		}
+		if n := test.ptr.StructDataWords(); n != test.dataWords {
+			t.Errorf("Pointer(%#016x).StructDataWords() = %d; want %d", uint64(test.ptr), n, test.dataWords)
+		}
+		if n := test.ptr.StructPointerCount(); n != test.ptrCount {
+			t.Errorf("Pointer(%#016x).StructPointerCount() = %d; want %d", uint64(test.ptr), n, test.ptrCount)
+		}
+		if p := StructPointer(test.offset, test.dataWords, test.ptrCount); p != test.ptr {
+			t.Errorf("StructPointer(%d, %d, %d) = %#016x; want %#016x", test.offset, test.dataWords, test.ptrCount, uint64(p), uint64(test.ptr))
+		}
+	}
+}
+
+func TestListPointer(t *testing.T) {
+	tests := []struct {
+		ptr    Pointer
+		offset int32
+		es     ElementSize
+		n      uint32
+		words  int64
+	}{
+		{0x0000000000000001, 0, Void, 0, 0},
+		{0x0000000100000001, 0, Bit, 0, 0},
+		{0x0000001500000009, 2, EightBytes, 2, 2},
+		{0x000000170000002d, 11, InlineComposite, 2, 3},
+		{0xfffffff9fffffffd, -1, Bit, 0x1fffffff, 0x800000},
+		{0xfffffffefffffffd, -1, PointerSize, 0x1fffffff, 0x1fffffff},
+	}
+	for _, test := range tests {
+		if k := test.ptr.Kind(); k != ListKind {
+			t.Errorf("Pointer(%#016x).Kind() = %v; want %v", uint64(test.ptr), k, ListKind)
+		}
+		if off := test.ptr.Offset(); off != test.offset {
+			t.Errorf("Pointer(%#016x).Offset() = %d; want %d", uint64(test.ptr), off, test.offset)
+		}
+		if es := test.ptr.ListElementSize(); es != test.es {
+			t.Errorf("Pointer(%#016x).ListElementSize() = %v; want %v", uint64(test.ptr), es, test.es)
+		}
+		if n := test.ptr.ListCount(); n != test.n {
+			t.Errorf("Pointer(%#016x).ListCount() = %d; want %d", uint64(test.ptr), n, test.n)
+		}
+		if w := test.ptr.ListWords(); w != test.words {
+			t.Errorf("Pointer(%#016x).ListWords() = %d; want %d", uint64(test.ptr), w, test.words)
+		}
+		if p := ListPointer(test.offset, test.es, test.n); p != test.ptr {
+			t.Errorf("ListPointer(%d, %v, %d) = %#016x; want %#016x", test.offset, test.es, test.n, uint64(p), uint64(test.ptr))
+		}
+	}
+}
+
+func TestFarPointer(t *testing.T) {
+	tests := []struct {
+		ptr    Pointer
+		double bool
+		off    uint32
+		seg    uint32
+	}{
+		{0x0000000000000002, false, 0, 0},
+		{0x0000000000000006, true, 0, 0},
+		{0x000000000000000a, false, 1, 0},
+		{0x000000000000000e, true, 1, 0},
+		{0xfffffffffffffffa, false, 0x1fffffff, 0xffffffff},
+		{0xfffffffffffffffe, true, 0x1fffffff, 0xffffffff},
+	}
+	for _, test := range tests {
+		if k := test.ptr.Kind(); k != FarKind {
+			t.Errorf("Pointer(%#016x).Kind() = %v; want %v", uint64(test.ptr), k, FarKind)
+		}
+		if d := test.ptr.IsDoubleFar(); d != test.double {
+			t.Errorf("Pointer(%#016x).IsDoubleFar() = %t; want %t", uint64(test.ptr), d, test.double)
+		}
+		if off := test.ptr.FarOffset(); off != test.off {
+			t.Errorf("Pointer(%#016x).FarOffset() = %d; want %d", uint64(test.ptr), off, test.off)
+		}
+		if seg := test.ptr.FarSegment(); seg != test.seg {
+			t.Errorf("Pointer(%#016x).FarSegment() = %d; want %d", uint64(test.ptr), seg, test.seg)
+		}
+		if p := FarPointer(test.seg, test.off, test.double); p != test.ptr {
+			t.Errorf("FarPointer(%d, %d, %t) = %#016x; want %#016x", test.seg, test.off, test.double, uint64(p), uint64(test.ptr))
+		}
+	}
+}
+
+func TestOtherPointer(t *testing.T) {
+	tests := []struct {
+		ptr   Pointer
+		typ   uint32
+		index uint32
+	}{
+		{0x0000000000000003, 0, 0},
+		{0x0000000000000007, 1, 0},
+		{0xffffffff00000003, 0, 0xffffffff},
+		{0xffffffffffffffff, 0x3fffffff, 0xffffffff},
+	}
+	for _, test := range tests {
+		if k := test.ptr.Kind(); k != OtherKind {
+			t.Errorf("Pointer(%#016x).Kind() = %v; want %v", uint64(test.ptr), k, OtherKind)
+		}
+		if typ := test.ptr.OtherType(); typ != test.typ {
+			t.Errorf("Pointer(%#016x).OtherType() = %d; want %d", uint64(test.ptr), typ, test.typ)
+		}
+		if c := test.ptr.IsCapability(); c != (test.typ == 0) {
+			t.Errorf("Pointer(%#016x).IsCapability() = %t; want %t", uint64(test.ptr), c, test.typ == 0)
+		}
+		if i := test.ptr.CapabilityIndex(); i != test.index {
+			t.Errorf("Pointer(%#016x).CapabilityIndex() = %d; want %d", uint64(test.ptr), i, test.index)
+		}
+		if test.typ != 0 {
+			continue
+		}
+		if p := CapabilityPointer(test.index); p != test.ptr {
+			t.Errorf("CapabilityPointer(%d) = %#016x; want %#016x", test.index, uint64(p), uint64(test.ptr))
+		}
+	}
+}
+
+func TestTarget(t *testing.T) {
+	p := StructPointer(2, 1, 0)
+	if got := p.Target(0); got != 3 {
+		t.Errorf("%v.Target(0) = %d; want 3", p, got)
+	}
+	p = StructPointer(-1, 1, 0)
+	if got := p.Target(4); got != 4 {
+		t.Errorf("%v.Target(4) = %d; want 4", p, got)
+	}
+}
+
+func TestReadCompositeTag(t *testing.T) {
+	seg := make([]byte, 2*WordSize)
+	PutWord(seg, 1, uint64(StructPointer(3, 2, 1)))
+	tag, err := ReadCompositeTag(seg, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag != (CompositeTag{Count: 3, DataWords: 2, PointerCount: 1}) {
+		t.Errorf("ReadCompositeTag(...) = %+v", tag)
+	}
+	if tag.ElementWords() != 3 {
+		t.Errorf("ElementWords() = %d; want 3", tag.ElementWords())
+	}
+	if _, err := ReadCompositeTag(seg, 2); err != ErrShortTag {
+		t.Errorf("ReadCompositeTag out of bounds error = %v; want %v", err, ErrShortTag)
+	}
+}
+
+func TestPointerString(t *testing.T) {
+	tests := []struct {
+		ptr  Pointer
+		want string
+	}{
+		{0, "null"},
+		{StructPointer(1, 2, 3), "struct(off=1, data=2, ptrs=3)"},
+		{ListPointer(-1, Byte, 5), "list(off=-1, size=byte, n=5)"},
+		{FarPointer(2, 4, false), "far(seg=2, off=4)"},
+		{FarPointer(2, 4, true), "doubleFar(seg=2, off=4)"},
+		{CapabilityPointer(7), "capability(7)"},
+	}
+	for _, test := range tests {
+		if got := test.ptr.String(); got != test.want {
+			t.Errorf("Pointer(%#016x).String() = %q; want %q", uint64(test.ptr), got, test.want)
+		}
+	}
+}